@@ -0,0 +1,427 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Access request status
+	ACCESS_REQUEST_STATUS_PENDING  = "pending"
+	ACCESS_REQUEST_STATUS_APPROVED = "approved"
+	ACCESS_REQUEST_STATUS_REJECTED = "rejected"
+)
+
+// TYPE DEFINITIONS
+
+// Request of a user to be a member of a group during Duration seconds. Requester, Org and Group keep the names
+// they had when the request was created. ReviewAt is nil until the request is approved or rejected, and
+// ExpireAt is the expiration of the membership created when it's approved.
+type AccessRequest struct {
+	ID            string     `json:"id, omitempty"`
+	UserID        string     `json:"-"`
+	GroupID       string     `json:"-"`
+	Requester     string     `json:"requester, omitempty"`
+	Org           string     `json:"org, omitempty"`
+	Group         string     `json:"group, omitempty"`
+	Justification string     `json:"justification, omitempty"`
+	Duration      int64      `json:"duration, omitempty"`
+	Status        string     `json:"status, omitempty"`
+	Reviewer      string     `json:"reviewer, omitempty"`
+	CreateAt      time.Time  `json:"createAt, omitempty"`
+	ReviewAt      *time.Time `json:"reviewAt, omitempty"`
+	ExpireAt      *time.Time `json:"expireAt, omitempty"`
+}
+
+func (r AccessRequest) String() string {
+	return fmt.Sprintf("[id: %v, requester: %v, org: %v, group: %v, duration: %v, status: %v, reviewer: %v, createAt: %v]",
+		r.ID, r.Requester, r.Org, r.Group, r.Duration, r.Status, r.Reviewer, r.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// ACCESS REQUEST API IMPLEMENTATION
+
+func (api AuthAPI) AddAccessRequest(requestInfo RequestInfo, org string, name string, justification string, duration int64) (*AccessRequest, error) {
+	// Validate fields
+	if len(justification) < 1 || len(justification) > MAX_JUSTIFICATION_LENGTH {
+		return nil, &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: justification length %v, it must be between 1 and %v",
+				len(justification), MAX_JUSTIFICATION_LENGTH),
+		}
+	}
+	if duration < 1 || duration > MAX_ACCESS_REQUEST_DURATION {
+		return nil, &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: duration %v, it must be between 1 and %v seconds",
+				duration, MAX_ACCESS_REQUEST_DURATION),
+		}
+	}
+
+	// Retrieve group, requesters don't need permissions over it
+	group, err := api.getAccessRequestGroup(org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve requester, only users can request access
+	user, err := api.getAccessRequestRequester(requestInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user is already a member of the group
+	isMember, err := api.GroupRepo.IsMemberOfGroup(user.ID, group.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	if isMember {
+		return nil, &Error{
+			Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+			Message: fmt.Sprintf("User: %v is already a member of Group: %v", user.ExternalID, group.Name),
+		}
+	}
+
+	// Check if user has already a pending request for the group
+	pendingRequests, err := api.AccessRequestRepo.GetAccessRequestsByGroupID(group.ID, ACCESS_REQUEST_STATUS_PENDING)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	for _, pendingRequest := range pendingRequests {
+		if pendingRequest.UserID == user.ID {
+			return nil, &Error{
+				Code: ACCESS_REQUEST_ALREADY_EXIST,
+				Message: fmt.Sprintf("User: %v has already a pending access request %v for Group: %v",
+					user.ExternalID, pendingRequest.ID, group.Name),
+			}
+		}
+	}
+
+	// Create access request
+	createdRequest, err := api.AccessRequestRepo.AddAccessRequest(createAccessRequest(*user, *group, justification, duration))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, ACCESS_REQUEST_ACTION_CREATE_ACCESS_REQUEST, group.Urn, nil, createdRequest); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request created %+v", createdRequest))
+	return createdRequest, nil
+}
+
+func (api AuthAPI) ListAccessRequests(requestInfo RequestInfo, org string, name string, status string) ([]AccessRequest, error) {
+	// Validate fields
+	if len(status) > 0 && !isValidAccessRequestStatus(status) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: status %v", status),
+		}
+	}
+
+	// Retrieve group
+	group, err := api.getAccessRequestGroup(org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Suspended users can't retrieve even their own requests, requests done by the worker aren't checked
+	if !requestInfo.Admin {
+		if _, err := api.getAccessRequestRequester(requestInfo); err != nil {
+			return nil, err
+		}
+	}
+
+	// Call repo to retrieve the access requests
+	requests, err := api.AccessRequestRepo.GetAccessRequestsByGroupID(group.ID, status)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Approvers retrieve all requests, other users only their own requests
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST, []Group{*group})
+	if err != nil && err.(*Error).Code != UNAUTHORIZED_RESOURCES_ERROR {
+		return nil, err
+	}
+	if len(groupsFiltered) > 0 {
+		return requests, nil
+	}
+
+	ownRequests := []AccessRequest{}
+	for _, r := range requests {
+		if r.Requester == requestInfo.Identifier {
+			ownRequests = append(ownRequests, r)
+		}
+	}
+	return ownRequests, nil
+}
+
+func (api AuthAPI) ApproveAccessRequest(requestInfo RequestInfo, org string, name string, id string) (*AccessRequest, error) {
+	// Retrieve pending request checking that user is allowed to review it
+	group, request, err := api.getAccessRequestForReview(requestInfo, org, name, id)
+	if err != nil {
+		return nil, err
+	}
+	pendingRequest := *request
+
+	// Check if requester is already a member of the group
+	isMember, err := api.GroupRepo.IsMemberOfGroup(request.UserID, request.GroupID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	if isMember {
+		return nil, &Error{
+			Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+			Message: fmt.Sprintf("User: %v is already a member of Group: %v", request.Requester, request.Group),
+		}
+	}
+
+	// Requester is added to group until access expires along with the review, it's removed by the expiration job afterwards
+	reviewAt := time.Now().UTC()
+	expireAt := reviewAt.Add(time.Duration(request.Duration) * time.Second)
+	request.Status = ACCESS_REQUEST_STATUS_APPROVED
+	request.Reviewer = requestInfo.Identifier
+	request.ReviewAt = &reviewAt
+	request.ExpireAt = &expireAt
+	reviewedRequest, err := api.reviewAccessRequest(*request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST, group.Urn, pendingRequest, reviewedRequest); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request approved %+v, member added to group until %v",
+		reviewedRequest, expireAt.Format("2006-01-02 15:04:05 MST")))
+	return reviewedRequest, nil
+}
+
+func (api AuthAPI) RejectAccessRequest(requestInfo RequestInfo, org string, name string, id string) (*AccessRequest, error) {
+	// Retrieve pending request checking that user is allowed to review it
+	group, request, err := api.getAccessRequestForReview(requestInfo, org, name, id)
+	if err != nil {
+		return nil, err
+	}
+	pendingRequest := *request
+
+	reviewAt := time.Now().UTC()
+	request.Status = ACCESS_REQUEST_STATUS_REJECTED
+	request.Reviewer = requestInfo.Identifier
+	request.ReviewAt = &reviewAt
+	reviewedRequest, err := api.reviewAccessRequest(*request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, ACCESS_REQUEST_ACTION_REJECT_ACCESS_REQUEST, group.Urn, pendingRequest, reviewedRequest); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request rejected %+v", reviewedRequest))
+	return reviewedRequest, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve user that made the request, checking that it isn't suspended
+func (api AuthAPI) getAccessRequestRequester(requestInfo RequestInfo) (*User, error) {
+	user, err := api.UserRepo.GetUserByExternalID(requestInfo.Identifier)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: fmt.Sprintf("Authenticated user with externalId %v not found, only users can request access", requestInfo.Identifier),
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if err := checkUserIsNotSuspended(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Retrieve group of access requests without checking permissions over it
+func (api AuthAPI) getAccessRequestGroup(org string, name string) (*Group, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Call repo to retrieve the group
+	group, err := api.GroupRepo.GetGroupByName(org, name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.GROUP_NOT_FOUND:
+			return nil, &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return group, nil
+}
+
+// Retrieve a pending access request of the group checking that user is an approver of the group and
+// isn't the requester
+func (api AuthAPI) getAccessRequestForReview(requestInfo RequestInfo, org string, name string, id string) (*Group, *AccessRequest, error) {
+	group, err := api.getAccessRequestGroup(org, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST, []Group{*group})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Call repo to retrieve the access request
+	request, err := api.AccessRequestRepo.GetAccessRequestByID(id)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ACCESS_REQUEST_NOT_FOUND:
+			return nil, nil, &Error{
+				Code:    ACCESS_REQUEST_BY_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	if request.GroupID != group.ID {
+		return nil, nil, &Error{
+			Code:    ACCESS_REQUEST_BY_ID_NOT_FOUND,
+			Message: fmt.Sprintf("Access request with id %v not found in group with org %v and name %v", id, org, name),
+		}
+	}
+
+	// Requesters can't review their own requests
+	if request.Requester == requestInfo.Identifier {
+		return nil, nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to review its own access request %v",
+				requestInfo.Identifier, id),
+		}
+	}
+
+	if request.Status != ACCESS_REQUEST_STATUS_PENDING {
+		return nil, nil, &Error{
+			Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+			Message: fmt.Sprintf("Access request with id %v is already %v", id, request.Status),
+		}
+	}
+
+	return group, request, nil
+}
+
+// Store review of access request, that fails if it was reviewed by another user in the meantime
+func (api AuthAPI) reviewAccessRequest(request AccessRequest) (*AccessRequest, error) {
+	reviewedRequest, err := api.AccessRequestRepo.ReviewAccessRequest(request)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ACCESS_REQUEST_NOT_FOUND:
+			return nil, &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return reviewedRequest, nil
+}
+
+func createAccessRequest(user User, group Group, justification string, duration int64) AccessRequest {
+	return AccessRequest{
+		ID:            uuid.NewV4().String(),
+		UserID:        user.ID,
+		GroupID:       group.ID,
+		Requester:     user.ExternalID,
+		Org:           group.Org,
+		Group:         group.Name,
+		Justification: justification,
+		Duration:      duration,
+		Status:        ACCESS_REQUEST_STATUS_PENDING,
+		CreateAt:      time.Now().UTC(),
+	}
+}
+
+func isValidAccessRequestStatus(status string) bool {
+	switch status {
+	case ACCESS_REQUEST_STATUS_PENDING, ACCESS_REQUEST_STATUS_APPROVED, ACCESS_REQUEST_STATUS_REJECTED:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,1129 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo   RequestInfo
+		org           string
+		groupName     string
+		justification string
+		duration      int64
+		// Expected result
+		expectedResponse *AccessRequest
+		wantError        error
+		// Manager Results
+		getGroupByNameResult             *Group
+		getUserByExternalIDResult        *User
+		isMemberOfGroupResult            bool
+		getAccessRequestsByGroupIDResult []AccessRequest
+		addAccessRequestResult           *AccessRequest
+		// Manager Errors
+		getGroupByNameMethodErr             error
+		getUserByExternalIDMethodErr        error
+		isMemberOfGroupMethodErr            error
+		getAccessRequestsByGroupIDMethodErr error
+		addAccessRequestMethodErr           error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			expectedResponse: &AccessRequest{
+				ID:            "REQUEST-ID",
+				UserID:        "USER-ID",
+				GroupID:       "GROUP-ID",
+				Requester:     "1234",
+				Org:           "org1",
+				Group:         "group1",
+				Justification: "Incident 42",
+				Duration:      3600,
+				Status:        ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getAccessRequestsByGroupIDResult: []AccessRequest{
+				{
+					ID:     "OTHER-REQUEST-ID",
+					UserID: "OTHER-USER-ID",
+					Status: ACCESS_REQUEST_STATUS_PENDING,
+				},
+			},
+			addAccessRequestResult: &AccessRequest{
+				ID:            "REQUEST-ID",
+				UserID:        "USER-ID",
+				GroupID:       "GROUP-ID",
+				Requester:     "1234",
+				Org:           "org1",
+				Group:         "group1",
+				Justification: "Incident 42",
+				Duration:      3600,
+				Status:        ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+		},
+		"ErrorCaseInvalidJustification": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:       "org1",
+			groupName: "group1",
+			duration:  3600,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: justification length 0, it must be between 1 and 1024",
+			},
+		},
+		"ErrorCaseInvalidDuration": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      MAX_ACCESS_REQUEST_DURATION + 1,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: duration 604801, it must be between 1 and 604800 seconds",
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "invalid*",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name invalid*",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseRequesterNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Authenticated user with externalId admin not found, only users can request access",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseRequesterSuspended": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 1234 is suspended",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+		},
+		"ErrorCaseAlreadyMember": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User: 1234 is already a member of Group: group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseAlreadyPending": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_EXIST,
+				Message: "User: 1234 has already a pending access request PENDING-REQUEST-ID for Group: group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+			},
+			getAccessRequestsByGroupIDResult: []AccessRequest{
+				{
+					ID:     "PENDING-REQUEST-ID",
+					UserID: "USER-ID",
+					Status: ACCESS_REQUEST_STATUS_PENDING,
+				},
+			},
+		},
+		"ErrorCaseIsMemberOfGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+			},
+			isMemberOfGroupMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseGetAccessRequestsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+			},
+			getAccessRequestsByGroupIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseAddAccessRequestDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      3600,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+			},
+			addAccessRequestMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
+		testRepo.ArgsOut[GetAccessRequestsByGroupIDMethod][0] = testcase.getAccessRequestsByGroupIDResult
+		testRepo.ArgsOut[GetAccessRequestsByGroupIDMethod][1] = testcase.getAccessRequestsByGroupIDMethodErr
+		testRepo.ArgsOut[AddAccessRequestMethod][0] = testcase.addAccessRequestResult
+		testRepo.ArgsOut[AddAccessRequestMethod][1] = testcase.addAccessRequestMethodErr
+
+		request, err := testAPI.AddAccessRequest(testcase.requestInfo, testcase.org, testcase.groupName,
+			testcase.justification, testcase.duration)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, request)
+
+		// Check stored access request
+		if testcase.wantError == nil {
+			stored := testRepo.ArgsIn[AddAccessRequestMethod][0].(AccessRequest)
+			stored.ID = ""
+			stored.CreateAt = time.Time{}
+			expectedStored := AccessRequest{
+				UserID:        testcase.getUserByExternalIDResult.ID,
+				GroupID:       testcase.getGroupByNameResult.ID,
+				Requester:     testcase.getUserByExternalIDResult.ExternalID,
+				Org:           testcase.getGroupByNameResult.Org,
+				Group:         testcase.getGroupByNameResult.Name,
+				Justification: testcase.justification,
+				Duration:      testcase.duration,
+				Status:        ACCESS_REQUEST_STATUS_PENDING,
+			}
+			if diff := pretty.Compare(stored, expectedStored); diff != "" {
+				t.Errorf("Test %v failed. Received different stored access request (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_ListAccessRequests(t *testing.T) {
+	requests := []AccessRequest{
+		{
+			ID:        "REQUEST1",
+			Requester: "1234",
+			Org:       "org1",
+			Group:     "group1",
+			Status:    ACCESS_REQUEST_STATUS_PENDING,
+		},
+		{
+			ID:        "REQUEST2",
+			Requester: "5678",
+			Org:       "org1",
+			Group:     "group1",
+			Status:    ACCESS_REQUEST_STATUS_PENDING,
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		status      string
+		// Expected result
+		expectedResponse []AccessRequest
+		wantError        error
+		// Manager Results
+		getGroupByNameResult             *Group
+		getUserByExternalIDResult        *User
+		getGroupsByUserIDResult          []Group
+		getAttachedPoliciesResult        []GroupPolicy
+		getAccessRequestsByGroupIDResult []AccessRequest
+		// Manager Errors
+		getGroupByNameMethodErr             error
+		getUserByExternalIDMethodErr        error
+		getAccessRequestsByGroupIDMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:              "org1",
+			groupName:        "group1",
+			status:           ACCESS_REQUEST_STATUS_PENDING,
+			expectedResponse: requests,
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getAccessRequestsByGroupIDResult: requests,
+		},
+		"OkCaseApprover": {
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			org:              "org1",
+			groupName:        "group1",
+			expectedResponse: requests,
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "APPROVERS-ID",
+					Name: "approvers",
+					Org:  "org1",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-APPROVER-ID",
+						Name: "policyApprover",
+						Org:  "org1",
+						Path: "/path/",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getAccessRequestsByGroupIDResult: requests,
+		},
+		"OkCaseRequester": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			org:       "org1",
+			groupName: "group1",
+			expectedResponse: []AccessRequest{
+				requests[0],
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getAccessRequestsByGroupIDResult: requests,
+		},
+		"ErrorCaseInvalidStatus": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			status:    "expired",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: status expired",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseRequesterNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Authenticated user with externalId 1234 not found, only users can request access",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseRequesterSuspended": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+			},
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 1234 is suspended",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			getAccessRequestsByGroupIDResult: requests,
+		},
+		"ErrorCaseGetAccessRequestsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestsByGroupIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetAccessRequestsByGroupIDMethod][0] = testcase.getAccessRequestsByGroupIDResult
+		testRepo.ArgsOut[GetAccessRequestsByGroupIDMethod][1] = testcase.getAccessRequestsByGroupIDMethodErr
+
+		response, err := testAPI.ListAccessRequests(testcase.requestInfo, testcase.org, testcase.groupName, testcase.status)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+
+		// Check status filter
+		if testcase.wantError == nil && testRepo.ArgsIn[GetAccessRequestsByGroupIDMethod][1] != testcase.status {
+			t.Errorf("Test %v failed. Received different status (wanted:%v / received:%v)", x,
+				testcase.status, testRepo.ArgsIn[GetAccessRequestsByGroupIDMethod][1])
+		}
+	}
+}
+
+func TestAuthAPI_ApproveAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		id          string
+		// Expected result
+		expectedResponse *AccessRequest
+		wantError        error
+		// Manager Results
+		getGroupByNameResult       *Group
+		getUserByExternalIDResult  *User
+		getGroupsByUserIDResult    []Group
+		getAttachedPoliciesResult  []GroupPolicy
+		getAccessRequestByIDResult *AccessRequest
+		isMemberOfGroupResult      bool
+		reviewAccessRequestResult  *AccessRequest
+		// Manager Errors
+		getAccessRequestByIDMethodErr error
+		isMemberOfGroupMethodErr      error
+		reviewAccessRequestMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			expectedResponse: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Duration:  3600,
+				Status:    ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:  "9999",
+				ReviewAt:  &now,
+				ExpireAt:  &now,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "APPROVERS-ID",
+					Name: "approvers",
+					Org:  "org1",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-APPROVER-ID",
+						Name: "policyApprover",
+						Org:  "org1",
+						Path: "/path/",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Duration:  3600,
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			reviewAccessRequestResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Duration:  3600,
+				Status:    ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:  "9999",
+				ReviewAt:  &now,
+				ExpireAt:  &now,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 9999 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+		},
+		"ErrorCaseAccessRequestNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_BY_ID_NOT_FOUND,
+				Message: "Access request with id REQUEST-ID not found",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDMethodErr: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id REQUEST-ID not found",
+			},
+		},
+		"ErrorCaseAccessRequestOfOtherGroup": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_BY_ID_NOT_FOUND,
+				Message: "Access request with id REQUEST-ID not found in group with org org1 and name group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "OTHER-GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+		},
+		"ErrorCaseOwnAccessRequest": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to review its own access request REQUEST-ID",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request with id REQUEST-ID is already rejected",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_REJECTED,
+			},
+		},
+		"ErrorCaseAlreadyMember": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User: 1234 is already a member of Group: group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Group:     "group1",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseReviewDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			reviewAccessRequestMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseReviewedMeanwhile": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Pending access request with id REQUEST-ID not found",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			reviewAccessRequestMethodErr: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Pending access request with id REQUEST-ID not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][0] = testcase.getAccessRequestByIDResult
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][1] = testcase.getAccessRequestByIDMethodErr
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
+		testRepo.ArgsOut[ReviewAccessRequestMethod][0] = testcase.reviewAccessRequestResult
+		testRepo.ArgsOut[ReviewAccessRequestMethod][1] = testcase.reviewAccessRequestMethodErr
+
+		response, err := testAPI.ApproveAccessRequest(testcase.requestInfo, testcase.org, testcase.groupName, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+
+		// Check time-bound membership and review
+		if testcase.wantError == nil {
+			reviewed := testRepo.ArgsIn[ReviewAccessRequestMethod][0].(AccessRequest)
+			if reviewed.UserID != testcase.getAccessRequestByIDResult.UserID ||
+				reviewed.GroupID != testcase.getAccessRequestByIDResult.GroupID {
+				t.Errorf("Test %v failed. Received different member (received user:%v, group:%v)", x,
+					reviewed.UserID, reviewed.GroupID)
+				continue
+			}
+			if testRepo.ArgsIn[AddMemberMethod][0] != nil {
+				t.Errorf("Test %v failed. Unexpected member added outside review %v", x, testRepo.ArgsIn[AddMemberMethod][0])
+				continue
+			}
+			expireAt := reviewed.ExpireAt
+			if wantExpireAt := reviewed.ReviewAt.Add(time.Duration(testcase.getAccessRequestByIDResult.Duration) * time.Second); !expireAt.Equal(wantExpireAt) {
+				t.Errorf("Test %v failed. Received different expiration (wanted:%v / received:%v)", x, wantExpireAt, expireAt)
+				continue
+			}
+			if reviewed.Status != ACCESS_REQUEST_STATUS_APPROVED || reviewed.Reviewer != testcase.requestInfo.Identifier {
+				t.Errorf("Test %v failed. Received different review %v", x, reviewed)
+				continue
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RejectAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		id          string
+		// Expected result
+		expectedResponse *AccessRequest
+		wantError        error
+		// Manager Results
+		getGroupByNameResult       *Group
+		getAccessRequestByIDResult *AccessRequest
+		reviewAccessRequestResult  *AccessRequest
+		// Manager Errors
+		getGroupByNameMethodErr      error
+		reviewAccessRequestMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			expectedResponse: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer:  "admin",
+				ReviewAt:  &now,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			reviewAccessRequestResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer:  "admin",
+				ReviewAt:  &now,
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request with id REQUEST-ID is already approved",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_APPROVED,
+			},
+		},
+		"ErrorCaseReviewDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			id:        "REQUEST-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "REQUEST-ID",
+				UserID:    "USER-ID",
+				GroupID:   "GROUP-ID",
+				Requester: "1234",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			reviewAccessRequestMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][0] = testcase.getAccessRequestByIDResult
+		testRepo.ArgsOut[ReviewAccessRequestMethod][0] = testcase.reviewAccessRequestResult
+		testRepo.ArgsOut[ReviewAccessRequestMethod][1] = testcase.reviewAccessRequestMethodErr
+
+		response, err := testAPI.RejectAccessRequest(testcase.requestInfo, testcase.org, testcase.groupName, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+
+		// Check review without membership
+		if testcase.wantError == nil {
+			if testRepo.ArgsIn[AddMemberMethod][0] != nil {
+				t.Errorf("Test %v failed. Unexpected member added %v", x, testRepo.ArgsIn[AddMemberMethod][0])
+				continue
+			}
+			reviewed := testRepo.ArgsIn[ReviewAccessRequestMethod][0].(AccessRequest)
+			if reviewed.Status != ACCESS_REQUEST_STATUS_REJECTED || reviewed.Reviewer != testcase.requestInfo.Identifier ||
+				reviewed.ExpireAt != nil {
+				t.Errorf("Test %v failed. Received different review %v", x, reviewed)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Admin group and policy created by the bootstrap of the first admin
+	ADMIN_PATH   = "/admin/"
+	ADMIN_ACTION = "iam:*"
+	ADMIN_URN    = "urn:iws:iam:*"
+)
+
+// TYPE DEFINITIONS
+
+// User with admin credentials, credentials are never returned
+type AdminIdentity struct {
+	User string `json:"user, omitempty"`
+}
+
+// ADMIN API IMPLEMENTATION
+
+func (api AuthAPI) AddAdmin(requestInfo RequestInfo, externalId string, password string) (*User, error) {
+	// Validate fields
+	if len(password) < 1 || len(password) > MAX_PASSWORD_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: password, it must have between 1 and %v characters", MAX_PASSWORD_LENGTH),
+		}
+	}
+
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_ADD_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store admin credentials
+	if err := api.AdminRepo.SetAdminCredentials(user.ID, hash); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_ADD_ADMIN, user.Urn, nil, AdminIdentity{User: user.ExternalID}); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Admin credentials stored for user %+v", user))
+	return user, nil
+}
+
+func (api AuthAPI) ListAdmins(requestInfo RequestInfo) ([]string, error) {
+	// Call repo to retrieve the users with admin credentials
+	users, err := api.AdminRepo.GetAdmins()
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	urnPrefix := GetUrnPrefix("", RESOURCE_USER, "/")
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, urnPrefix, USER_ACTION_LIST_ADMINS, users)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return user IDs
+	externalIds := []string{}
+	for _, u := range usersFiltered {
+		externalIds = append(externalIds, u.ExternalID)
+	}
+
+	return externalIds, nil
+}
+
+func (api AuthAPI) RemoveAdmin(requestInfo RequestInfo, externalId string) error {
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_REMOVE_ADMIN)
+	if err != nil {
+		return err
+	}
+
+	// Remove admin credentials
+	if err := api.AdminRepo.RemoveAdminCredentials(user.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ADMIN_NOT_FOUND:
+			return &Error{
+				Code:    ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: fmt.Sprintf("Admin with externalId %v not found", externalId),
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_REMOVE_ADMIN, user.Urn, AdminIdentity{User: user.ExternalID}, nil); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Admin credentials removed for user %+v", user))
+	return nil
+}
+
+// Authenticate user with its admin password. Users without admin credentials get ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+// so callers can try other credentials without checking the password again. Admin passwords are locked out
+// like local passwords.
+func (api AuthAPI) AuthenticateAdmin(username string, password string) (*User, error) {
+	notFound := &Error{
+		Code:    ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+		Message: "Invalid admin credentials",
+	}
+
+	// Call repo to retrieve the user, deleted users can't authenticate
+	user, err := api.UserRepo.GetUserByExternalID(username)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, notFound
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Call repo to retrieve the hash of its password with its failed attempts
+	credentials, err := api.AdminRepo.GetAdminCredentials(user.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ADMIN_NOT_FOUND:
+			return nil, notFound
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	invalidCredentials := &Error{
+		Code:    INVALID_ADMIN_CREDENTIALS,
+		Message: "Invalid admin credentials",
+	}
+	if err := api.checkPasswordWithLockout(username, password, credentials, invalidCredentials,
+		api.AdminRepo.IncrementAdminCredentialsAttempts, api.AdminRepo.UpdateAdminCredentialsAttempts); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Create the first admin when there aren't users with admin credentials. The admin is a member of the admin
+// group, whose policy allows all IAM actions, so its permissions can be changed like the ones of other users.
+// Existing group, policy and user are reused. Changes are done by the worker without authorization and they
+// are recorded as done by the new admin. Throw error if there aren't admins and username or password are empty.
+func (api AuthAPI) BootstrapAdmin(org string, groupName string, username string, password string) error {
+	admins, err := api.AdminRepo.GetAdmins()
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	if len(admins) > 0 {
+		api.Logger.Infof("Admin bootstrap skipped, there are %v admins", len(admins))
+		return nil
+	}
+	if username == "" || password == "" {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "There aren't admins, admin username and password are needed to create the first one",
+		}
+	}
+
+	requestInfo := RequestInfo{
+		Identifier: username,
+		Admin:      true,
+		RequestID:  uuid.NewV4().String(),
+	}
+
+	_, err = api.AddGroup(requestInfo, org, groupName, ADMIN_PATH, "Administrators", "Administrators of IAM resources")
+	if err = ignoreErrorCode(err, GROUP_ALREADY_EXIST); err != nil {
+		return err
+	}
+	statements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{ADMIN_ACTION},
+			Resources: []string{ADMIN_URN},
+		},
+	}
+	_, err = api.AddPolicy(requestInfo, groupName, ADMIN_PATH, org, "Administrators", "Allow all IAM actions", statements)
+	if err = ignoreErrorCode(err, POLICY_ALREADY_EXIST); err != nil {
+		return err
+	}
+	err = api.AttachPolicyToGroup(requestInfo, org, groupName, groupName, nil, nil)
+	if err = ignoreErrorCode(err, POLICY_IS_ALREADY_ATTACHED_TO_GROUP); err != nil {
+		return err
+	}
+	_, err = api.AddUser(requestInfo, username, ADMIN_PATH, "", "")
+	if err = ignoreErrorCode(err, USER_ALREADY_EXIST); err != nil {
+		return err
+	}
+	err = api.AddMember(requestInfo, username, groupName, org, nil)
+	if err = ignoreErrorCode(err, USER_IS_ALREADY_A_MEMBER_OF_GROUP); err != nil {
+		return err
+	}
+	if _, err := api.AddAdmin(requestInfo, username, password); err != nil {
+		return err
+	}
+
+	api.Logger.Infof("Admin %v created as member of group %v in organization %v", username, groupName, org)
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Return nil if the error is an API error with the given code
+func ignoreErrorCode(err error, code string) error {
+	if apiError, ok := err.(*Error); ok && apiError.Code == code {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,487 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddAdmin(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		password    string
+		// Expected result
+		expectedResponse *User
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		// Manager Errors
+		setAdminCredentialsMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   "password",
+			expectedResponse: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"ErrorCaseEmptyPassword": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: password, it must have between 1 and 72 characters",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "user",
+				Admin:      false,
+			},
+			externalId: "user",
+			password:   "password",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId user is not allowed to access to resource " +
+					CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"ErrorCaseSetAdminCredentialsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   "password",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			setAdminCredentialsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{}
+		testRepo.ArgsOut[SetAdminCredentialsMethod][0] = testcase.setAdminCredentialsMethodErr
+
+		user, err := testAPI.AddAdmin(testcase.requestInfo, testcase.externalId, testcase.password)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, user)
+		if testcase.wantError == nil {
+			// Check that password is stored hashed
+			hash, _ := testRepo.ArgsIn[SetAdminCredentialsMethod][1].(string)
+			if hash == testcase.password || !checkPassword(testcase.password, hash) {
+				t.Errorf("Test %v failed. Received unexpected hash %v", x, hash)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_ListAdmins(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResponse []string
+		wantError        error
+		// Manager Results
+		getAdminsResult []User
+		// Manager Errors
+		getAdminsMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			expectedResponse: []string{"admin", "user"},
+			getAdminsResult: []User{
+				{
+					ID:         "ADMIN-ID",
+					ExternalID: "admin",
+					Path:       "/admin/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "admin"),
+				},
+				{
+					ID:         "USER-ID",
+					ExternalID: "user",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				},
+			},
+		},
+		"ErrorCaseGetAdminsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAdminsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAdminsMethod][0] = testcase.getAdminsResult
+		testRepo.ArgsOut[GetAdminsMethod][1] = testcase.getAdminsMethodErr
+
+		admins, err := testAPI.ListAdmins(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, admins)
+	}
+}
+
+func TestAuthAPI_RemoveAdmin(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult *User
+		// Manager Errors
+		removeAdminCredentialsMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"ErrorCaseAdminNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			wantError: &Error{
+				Code:    ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Admin with externalId user not found",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			removeAdminCredentialsMethodErr: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id USER-ID not found",
+			},
+		},
+		"ErrorCaseRemoveAdminCredentialsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			removeAdminCredentialsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{}
+		testRepo.ArgsOut[RemoveAdminCredentialsMethod][0] = testcase.removeAdminCredentialsMethodErr
+
+		err := testAPI.RemoveAdmin(testcase.requestInfo, testcase.externalId)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil && testRepo.ArgsIn[RemoveAdminCredentialsMethod][0] != "USER-ID" {
+			t.Errorf("Test %v failed. Received unexpected user %v", x, testRepo.ArgsIn[RemoveAdminCredentialsMethod][0])
+		}
+	}
+}
+
+func TestAuthAPI_AuthenticateAdmin(t *testing.T) {
+	hash, err := hashPassword("password")
+	if err != nil {
+		t.Fatalf("Unexpected error hashing password: %v", err)
+	}
+	lockedUntil := time.Now().UTC().Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		username string
+		password string
+		// Expected result
+		expectedResponse    *User
+		wantError           error
+		expectedIncremented bool
+		expectedCleared     bool
+		// Manager Results
+		getUserByExternalIDResult            *User
+		getAdminCredentialsResult            *UserCredentials
+		incrementAdminCredentialsAttemptsRes *UserCredentials
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getAdminCredentialsMethodErr error
+	}{
+		"OkCase": {
+			username: "admin",
+			password: "password",
+			expectedResponse: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getAdminCredentialsResult: &UserCredentials{
+				UserID: "ADMIN-ID",
+				Hash:   hash,
+			},
+		},
+		"OkCaseFailedAttemptsCleared": {
+			username: "admin",
+			password: "password",
+			expectedResponse: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			expectedCleared: true,
+			getUserByExternalIDResult: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getAdminCredentialsResult: &UserCredentials{
+				UserID:         "ADMIN-ID",
+				Hash:           hash,
+				FailedAttempts: 3,
+			},
+		},
+		"ErrorCaseWrongPassword": {
+			username: "admin",
+			password: "wrong",
+			wantError: &Error{
+				Code:    INVALID_ADMIN_CREDENTIALS,
+				Message: "Invalid admin credentials",
+			},
+			expectedIncremented: true,
+			getUserByExternalIDResult: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getAdminCredentialsResult: &UserCredentials{
+				UserID: "ADMIN-ID",
+				Hash:   hash,
+			},
+			incrementAdminCredentialsAttemptsRes: &UserCredentials{
+				UserID:         "ADMIN-ID",
+				Hash:           hash,
+				FailedAttempts: 1,
+			},
+		},
+		"ErrorCaseLockedOut": {
+			username: "admin",
+			password: "password",
+			wantError: &Error{
+				Code:    USER_LOCKED_OUT,
+				Message: "User admin is locked out until " + lockedUntil.Format(time.RFC3339),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getAdminCredentialsResult: &UserCredentials{
+				UserID:      "ADMIN-ID",
+				Hash:        hash,
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			username: "admin",
+			password: "password",
+			wantError: &Error{
+				Code:    ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Invalid admin credentials",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User with externalId admin not found",
+			},
+		},
+		"ErrorCaseUserWithoutAdminCredentials": {
+			username: "user",
+			password: "password",
+			wantError: &Error{
+				Code:    ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Invalid admin credentials",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+			},
+			getAdminCredentialsMethodErr: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id USER-ID not found",
+			},
+		},
+		"ErrorCaseGetAdminCredentialsDBErr": {
+			username: "admin",
+			password: "password",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "ADMIN-ID",
+				ExternalID: "admin",
+			},
+			getAdminCredentialsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetAdminCredentialsMethod][0] = testcase.getAdminCredentialsResult
+		testRepo.ArgsOut[GetAdminCredentialsMethod][1] = testcase.getAdminCredentialsMethodErr
+		testRepo.ArgsOut[IncrementAdminCredentialsAttemptsMethod][0] = testcase.incrementAdminCredentialsAttemptsRes
+
+		user, err := testAPI.AuthenticateAdmin(testcase.username, testcase.password)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, user)
+
+		// Check admin failed attempts are increased and cleared like the ones of local passwords
+		if incremented := testRepo.ArgsIn[IncrementAdminCredentialsAttemptsMethod][0] != nil; incremented != testcase.expectedIncremented {
+			t.Errorf("Test %v failed. Received different increment of failed attempts %v", x, incremented)
+		}
+		if cleared := testRepo.ArgsIn[UpdateAdminCredentialsAttemptsMethod][1] == 0; cleared != testcase.expectedCleared {
+			t.Errorf("Test %v failed. Received different clearing of failed attempts %v", x, cleared)
+		}
+		if testRepo.ArgsIn[UpdateUserCredentialsAttemptsMethod][0] != nil || testRepo.ArgsIn[IncrementUserCredentialsAttemptsMethod][0] != nil {
+			t.Errorf("Test %v failed. Received unexpected update of local credentials", x)
+		}
+	}
+}
+
+func TestAuthAPI_BootstrapAdmin(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		username string
+		password string
+		// Expected result
+		wantError error
+		// Manager Results
+		getAdminsResult []User
+		// Manager Errors
+		getAdminsMethodErr error
+	}{
+		"OkCaseAdminsAlreadyExist": {
+			getAdminsResult: []User{
+				{
+					ID:         "ADMIN-ID",
+					ExternalID: "admin",
+				},
+			},
+		},
+		"ErrorCaseMissingCredentials": {
+			username: "admin",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "There aren't admins, admin username and password are needed to create the first one",
+			},
+			getAdminsResult: []User{},
+		},
+		"ErrorCaseGetAdminsDBErr": {
+			username: "admin",
+			password: "password",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAdminsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAdminsMethod][0] = testcase.getAdminsResult
+		testRepo.ArgsOut[GetAdminsMethod][1] = testcase.getAdminsMethodErr
+
+		err := testAPI.BootstrapAdmin("foulkon", "Admins", testcase.username, testcase.password)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil && testRepo.ArgsIn[SetAdminCredentialsMethod][0] != nil {
+			t.Errorf("Test %v failed. Unexpected admin credentials stored", x)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Secret keys are this prefix followed by the hex encoding of random bytes. Prefix of an API key is
+	// the beginning of its secret key, so it can be identified without the secret.
+	API_KEY_PREFIX        = "fk_"
+	API_KEY_RANDOM_BYTES  = 32
+	API_KEY_PREFIX_LENGTH = 8
+)
+
+// TYPE DEFINITIONS
+
+// API key of a service account. ExpireAt is nil if the key never expires and UpdateAt is the last rotation.
+type ApiKey struct {
+	ID       string     `json:"id, omitempty"`
+	Name     string     `json:"name, omitempty"`
+	Prefix   string     `json:"prefix, omitempty"`
+	UserID   string     `json:"-"`
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+	CreateAt time.Time  `json:"createAt, omitempty"`
+	UpdateAt time.Time  `json:"updateAt, omitempty"`
+}
+
+func (k ApiKey) String() string {
+	return fmt.Sprintf("[id: %v, name: %v, prefix: %v, userID: %v, createAt: %v, updateAt: %v]",
+		k.ID, k.Name, k.Prefix, k.UserID, k.CreateAt.Format("2006-01-02 15:04:05 MST"), k.UpdateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// API key with its secret key, it's only returned when the secret key is generated
+type ApiKeySecret struct {
+	ApiKey
+	Key string `json:"key, omitempty"`
+}
+
+// API KEY API IMPLEMENTATION
+
+func (api AuthAPI) AddApiKey(requestInfo RequestInfo, externalId string, name string, expireAt *time.Time) (*ApiKeySecret, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if err := validateApiKeyExpiration(expireAt); err != nil {
+		return nil, err
+	}
+
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_CREATE_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+	if !user.ServiceAccount {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v, user isn't a service account", externalId),
+		}
+	}
+
+	key, err := createApiKey(user.ID, name, expireAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store API key
+	createdKey, err := api.ApiKeyRepo.AddApiKey(key.ApiKey, hashApiKey(key.Key))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_CREATE_API_KEY, user.Urn, nil, createdKey); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key created %+v", createdKey))
+	return &ApiKeySecret{ApiKey: *createdKey, Key: key.Key}, nil
+}
+
+func (api AuthAPI) ListApiKeys(requestInfo RequestInfo, externalId string) ([]ApiKey, error) {
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_LIST_API_KEYS)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the API keys
+	keys, err := api.ApiKeyRepo.GetApiKeysByUserID(user.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return keys, nil
+}
+
+func (api AuthAPI) RotateApiKey(requestInfo RequestInfo, externalId string, id string, expireAt *time.Time) (*ApiKeySecret, error) {
+	// Validate fields
+	if err := validateApiKeyExpiration(expireAt); err != nil {
+		return nil, err
+	}
+
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_ROTATE_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+	oldKey, err := api.getUserApiKey(*user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := createApiKey(user.ID, oldKey.Name, expireAt)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = oldKey.ID
+	key.CreateAt = oldKey.CreateAt
+
+	// Update API key
+	updatedKey, err := api.ApiKeyRepo.UpdateApiKey(key.ApiKey, hashApiKey(key.Key))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_ROTATE_API_KEY, user.Urn, oldKey, updatedKey); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key rotated from %+v to %+v", oldKey, updatedKey))
+	return &ApiKeySecret{ApiKey: *updatedKey, Key: key.Key}, nil
+}
+
+func (api AuthAPI) RevokeApiKey(requestInfo RequestInfo, externalId string, id string) error {
+	user, err := api.getUserForAction(requestInfo, externalId, USER_ACTION_REVOKE_API_KEY)
+	if err != nil {
+		return err
+	}
+	key, err := api.getUserApiKey(*user, id)
+	if err != nil {
+		return err
+	}
+
+	// Remove API key
+	if err := api.ApiKeyRepo.RemoveApiKey(key.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_REVOKE_API_KEY, user.Urn, key, nil); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key revoked %+v", key))
+	return nil
+}
+
+func (api AuthAPI) AuthenticateApiKey(key string) (*User, error) {
+	if !strings.HasPrefix(key, API_KEY_PREFIX) {
+		return nil, &Error{
+			Code:    INVALID_API_KEY,
+			Message: "Invalid API key",
+		}
+	}
+
+	// Call repo to retrieve the API key by the hash of its secret key
+	apiKey, err := api.ApiKeyRepo.GetApiKeyByHash(hashApiKey(key))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.API_KEY_NOT_FOUND:
+			return nil, &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Invalid API key",
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	if apiKey.ExpireAt != nil && !apiKey.ExpireAt.After(time.Now().UTC()) {
+		return nil, &Error{
+			Code:    INVALID_API_KEY,
+			Message: fmt.Sprintf("API key %v expired at %v", apiKey.Prefix, apiKey.ExpireAt.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	// Call repo to retrieve the owner, deleted users can't authenticate
+	user, err := api.UserRepo.GetUserByID(apiKey.UserID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    INVALID_API_KEY,
+				Message: fmt.Sprintf("Owner of API key %v not found", apiKey.Prefix),
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	// Keys of users that aren't service accounts anymore can't authenticate
+	if !user.ServiceAccount {
+		return nil, &Error{
+			Code:    INVALID_API_KEY,
+			Message: fmt.Sprintf("Owner of API key %v isn't a service account", apiKey.Prefix),
+		}
+	}
+
+	return user, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve API key checking that it belongs to the user
+func (api AuthAPI) getUserApiKey(user User, id string) (*ApiKey, error) {
+	key, err := api.ApiKeyRepo.GetApiKeyByID(id)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.API_KEY_NOT_FOUND:
+			return nil, &Error{
+				Code:    API_KEY_BY_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	if key.UserID != user.ID {
+		return nil, &Error{
+			Code:    API_KEY_BY_ID_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found for user with externalId %v", id, user.ExternalID),
+		}
+	}
+
+	return key, nil
+}
+
+// Expiration time is optional, but it can't be in the past
+func validateApiKeyExpiration(expireAt *time.Time) error {
+	if expireAt != nil && !expireAt.After(time.Now().UTC()) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: expireAt %v, it must be in the future", expireAt.UTC().Format(time.RFC3339)),
+		}
+	}
+	return nil
+}
+
+// Create API key with a new random secret key
+func createApiKey(userID string, name string, expireAt *time.Time) (*ApiKeySecret, error) {
+	random := make([]byte, API_KEY_RANDOM_BYTES)
+	if _, err := rand.Read(random); err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Unable to generate API key: %v", err),
+		}
+	}
+	secret := API_KEY_PREFIX + hex.EncodeToString(random)
+
+	now := time.Now().UTC()
+	key := &ApiKeySecret{
+		ApiKey: ApiKey{
+			ID:       uuid.NewV4().String(),
+			Name:     name,
+			Prefix:   secret[:len(API_KEY_PREFIX)+API_KEY_PREFIX_LENGTH],
+			UserID:   userID,
+			ExpireAt: expireAt,
+			CreateAt: now,
+			UpdateAt: now,
+		},
+		Key: secret,
+	}
+
+	return key, nil
+}
+
+// Secret keys are stored as their hex SHA-256 hash
+func hashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,660 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		name        string
+		expireAt    *time.Time
+		// Expected result
+		expectedResponse *ApiKey
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		addApiKeyResult           *ApiKey
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		addApiKeyMethodErr           error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			name:       "deploy",
+			expireAt:   &future,
+			expectedResponse: &ApiKey{
+				ID:       "KEY-ID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "USER-ID",
+				ExpireAt: &future,
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			addApiKeyResult: &ApiKey{
+				ID:       "KEY-ID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "USER-ID",
+				ExpireAt: &future,
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			name:       "*deploy",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *deploy",
+			},
+		},
+		"ErrorCaseExpireAtInThePast": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			name:       "deploy",
+			expireAt:   &past,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: expireAt " + past.Format(time.RFC3339) + ", it must be in the future",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			name:       "deploy",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseNotServiceAccount": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "123456",
+			name:       "deploy",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId 123456, user isn't a service account",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseAddApiKeyDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			name:       "deploy",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			addApiKeyMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[AddApiKeyMethod][0] = testcase.addApiKeyResult
+		testRepo.ArgsOut[AddApiKeyMethod][1] = testcase.addApiKeyMethodErr
+
+		key, err := testAPI.AddApiKey(testcase.requestInfo, testcase.externalId, testcase.name, testcase.expireAt)
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, key)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed: %v", x, err)
+			continue
+		}
+		if diff := pretty.Compare(key.ApiKey, *testcase.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", x, diff)
+			continue
+		}
+
+		// Check that only the hash of the secret key is stored
+		stored := testRepo.ArgsIn[AddApiKeyMethod][0].(ApiKey)
+		hash := testRepo.ArgsIn[AddApiKeyMethod][1].(string)
+		if !strings.HasPrefix(key.Key, stored.Prefix) || !strings.HasPrefix(stored.Prefix, API_KEY_PREFIX) ||
+			len(key.Key) != len(API_KEY_PREFIX)+2*API_KEY_RANDOM_BYTES {
+			t.Errorf("Test %v failed. Received unexpected key %v with prefix %v", x, key.Key, stored.Prefix)
+		}
+		if hash != hashApiKey(key.Key) || strings.Contains(hash, key.Key) {
+			t.Errorf("Test %v failed. Received unexpected stored hash %v", x, hash)
+		}
+		if stored.UserID != testcase.getUserByExternalIDResult.ID {
+			t.Errorf("Test %v failed. Received unexpected stored user %v", x, stored.UserID)
+		}
+	}
+}
+
+func TestAuthAPI_ListApiKeys(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		// Expected result
+		expectedResponse []ApiKey
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getApiKeysByUserIDResult  []ApiKey
+		// Manager Errors
+		getApiKeysByUserIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			expectedResponse: []ApiKey{
+				{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_12345678",
+					UserID:   "USER-ID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeysByUserIDResult: []ApiKey{
+				{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_12345678",
+					UserID:   "USER-ID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+		},
+		"ErrorCaseGetApiKeysDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeysByUserIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetApiKeysByUserIDMethod][0] = testcase.getApiKeysByUserIDResult
+		testRepo.ArgsOut[GetApiKeysByUserIDMethod][1] = testcase.getApiKeysByUserIDMethodErr
+
+		keys, err := testAPI.ListApiKeys(testcase.requestInfo, testcase.externalId)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, keys)
+	}
+}
+
+func TestAuthAPI_RotateApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		id          string
+		expireAt    *time.Time
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getApiKeyByIDResult       *ApiKey
+		// Manager Errors
+		getApiKeyByIDMethodErr error
+		updateApiKeyMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			expireAt:   &future,
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDResult: &ApiKey{
+				ID:       "KEY-ID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "USER-ID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+		"ErrorCaseApiKeyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			wantError: &Error{
+				Code:    API_KEY_BY_ID_NOT_FOUND,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDMethodErr: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseApiKeyOfOtherUser": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			wantError: &Error{
+				Code:    API_KEY_BY_ID_NOT_FOUND,
+				Message: "API key with id KEY-ID not found for user with externalId service",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDResult: &ApiKey{
+				ID:     "KEY-ID",
+				Name:   "deploy",
+				UserID: "OTHER-USER-ID",
+			},
+		},
+		"ErrorCaseUpdateApiKeyDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDResult: &ApiKey{
+				ID:     "KEY-ID",
+				Name:   "deploy",
+				UserID: "USER-ID",
+			},
+			updateApiKeyMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetApiKeyByIDMethod][0] = testcase.getApiKeyByIDResult
+		testRepo.ArgsOut[GetApiKeyByIDMethod][1] = testcase.getApiKeyByIDMethodErr
+		testRepo.ArgsOut[UpdateApiKeyMethod][1] = testcase.updateApiKeyMethodErr
+		if testcase.updateApiKeyMethodErr == nil {
+			// Repo returns the updated key
+			updated := ApiKey{ID: "KEY-ID", Name: "deploy", UserID: "USER-ID", ExpireAt: testcase.expireAt}
+			testRepo.ArgsOut[UpdateApiKeyMethod][0] = &updated
+		}
+
+		key, err := testAPI.RotateApiKey(testcase.requestInfo, testcase.externalId, testcase.id, testcase.expireAt)
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, key)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed: %v", x, err)
+			continue
+		}
+
+		// Check that a new secret key is generated keeping the API key identity
+		stored := testRepo.ArgsIn[UpdateApiKeyMethod][0].(ApiKey)
+		hash := testRepo.ArgsIn[UpdateApiKeyMethod][1].(string)
+		if stored.ID != testcase.id || stored.Name != testcase.getApiKeyByIDResult.Name ||
+			stored.Prefix == testcase.getApiKeyByIDResult.Prefix || !strings.HasPrefix(key.Key, stored.Prefix) ||
+			stored.ExpireAt != testcase.expireAt || !stored.CreateAt.Equal(testcase.getApiKeyByIDResult.CreateAt) {
+			t.Errorf("Test %v failed. Received unexpected stored API key %v", x, stored)
+		}
+		if hash != hashApiKey(key.Key) {
+			t.Errorf("Test %v failed. Received unexpected stored hash %v", x, hash)
+		}
+	}
+}
+
+func TestAuthAPI_RevokeApiKey(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		id          string
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getApiKeyByIDResult       *ApiKey
+		// Manager Errors
+		removeApiKeyMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDResult: &ApiKey{
+				ID:     "KEY-ID",
+				Name:   "deploy",
+				UserID: "USER-ID",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "service",
+				Admin:      false,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId service is not allowed to access to resource " +
+					CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+		},
+		"ErrorCaseRemoveApiKeyDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			id:         "KEY-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				ServiceAccount: true,
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+			},
+			getApiKeyByIDResult: &ApiKey{
+				ID:     "KEY-ID",
+				Name:   "deploy",
+				UserID: "USER-ID",
+			},
+			removeApiKeyMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{}
+		testRepo.ArgsOut[GetApiKeyByIDMethod][0] = testcase.getApiKeyByIDResult
+		testRepo.ArgsOut[RemoveApiKeyMethod][0] = testcase.removeApiKeyMethodErr
+
+		err := testAPI.RevokeApiKey(testcase.requestInfo, testcase.externalId, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil && testRepo.ArgsIn[RemoveApiKeyMethod][0] != testcase.id {
+			t.Errorf("Test %v failed. Received unexpected removed API key %v", x, testRepo.ArgsIn[RemoveApiKeyMethod][0])
+		}
+	}
+}
+
+func TestAuthAPI_AuthenticateApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		key string
+		// Expected result
+		expectedResponse *User
+		wantError        error
+		// Manager Results
+		getApiKeyByHashResult *ApiKey
+		getUserByIDResult     *User
+		// Manager Errors
+		getApiKeyByHashMethodErr error
+		getUserByIDMethodErr     error
+	}{
+		"OkCase": {
+			key: "fk_1234567890",
+			expectedResponse: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				ServiceAccount: true,
+			},
+			getApiKeyByHashResult: &ApiKey{
+				ID:       "KEY-ID",
+				Prefix:   "fk_12345678",
+				UserID:   "USER-ID",
+				ExpireAt: &future,
+			},
+			getUserByIDResult: &User{
+				ID:             "USER-ID",
+				ExternalID:     "service",
+				ServiceAccount: true,
+			},
+		},
+		"ErrorCaseInvalidPrefix": {
+			key: "1234567890",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Invalid API key",
+			},
+		},
+		"ErrorCaseUnknownKey": {
+			key: "fk_1234567890",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Invalid API key",
+			},
+			getApiKeyByHashMethodErr: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseExpiredKey": {
+			key: "fk_1234567890",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "API key fk_12345678 expired at " + past.Format(time.RFC3339),
+			},
+			getApiKeyByHashResult: &ApiKey{
+				ID:       "KEY-ID",
+				Prefix:   "fk_12345678",
+				UserID:   "USER-ID",
+				ExpireAt: &past,
+			},
+		},
+		"ErrorCaseOwnerNotFound": {
+			key: "fk_1234567890",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Owner of API key fk_12345678 not found",
+			},
+			getApiKeyByHashResult: &ApiKey{
+				ID:     "KEY-ID",
+				Prefix: "fk_12345678",
+				UserID: "USER-ID",
+			},
+			getUserByIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseOwnerNotServiceAccount": {
+			key: "fk_1234567890",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Owner of API key fk_12345678 isn't a service account",
+			},
+			getApiKeyByHashResult: &ApiKey{
+				ID:     "KEY-ID",
+				Prefix: "fk_12345678",
+				UserID: "USER-ID",
+			},
+			getUserByIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+			},
+		},
+		"ErrorCaseGetApiKeyByHashDBErr": {
+			key: "fk_1234567890",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getApiKeyByHashMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetApiKeyByHashMethod][0] = testcase.getApiKeyByHashResult
+		testRepo.ArgsOut[GetApiKeyByHashMethod][1] = testcase.getApiKeyByHashMethodErr
+		testRepo.ArgsOut[GetUserByIDMethod][0] = testcase.getUserByIDResult
+		testRepo.ArgsOut[GetUserByIDMethod][1] = testcase.getUserByIDMethodErr
+
+		user, err := testAPI.AuthenticateApiKey(testcase.key)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, user)
+		if testcase.getApiKeyByHashResult != nil && testRepo.ArgsIn[GetApiKeyByHashMethod][0] != hashApiKey(testcase.key) {
+			t.Errorf("Test %v failed. Received unexpected hash %v", x, testRepo.ArgsIn[GetApiKeyByHashMethod][0])
+		}
+	}
+}
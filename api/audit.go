@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Audit event outcomes
+	AUDIT_OUTCOME_SUCCESS = "success"
+	AUDIT_OUTCOME_FAILURE = "failure"
+)
+
+// TYPE DEFINITIONS
+
+// Record of a mutation requested by a user, successful or not. Urn is the target of the mutation, and Before
+// and After are JSON snapshots of the target before and after it. Request holds the JSON body of the request
+// and Error the reason of a failure.
+type AuditEvent struct {
+	ID        string          `json:"id, omitempty"`
+	RequestID string          `json:"requestId, omitempty"`
+	User      string          `json:"user, omitempty"`
+	Action    string          `json:"action, omitempty"`
+	Urn       string          `json:"urn, omitempty"`
+	Request   json.RawMessage `json:"request, omitempty"`
+	Outcome   string          `json:"outcome, omitempty"`
+	Error     *Error          `json:"error, omitempty"`
+	Before    json.RawMessage `json:"before, omitempty"`
+	After     json.RawMessage `json:"after, omitempty"`
+	CreateAt  time.Time       `json:"createAt, omitempty"`
+}
+
+func (e AuditEvent) String() string {
+	return fmt.Sprintf("[id: %v, requestID: %v, user: %v, action: %v, urn: %v, outcome: %v, createAt: %v]",
+		e.ID, e.RequestID, e.User, e.Action, e.Urn, e.Outcome, e.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (e AuditEvent) GetUrn() string {
+	return e.Urn
+}
+
+// Filter of audit events. Empty fields and nil times don't filter.
+type AuditFilter struct {
+	User      string
+	Action    string
+	UrnPrefix string
+	From      *time.Time
+	To        *time.Time
+}
+
+// AUDIT API IMPLEMENTATION
+
+func (api AuthAPI) AddAuditEvent(event AuditEvent) (*AuditEvent, error) {
+	event.ID = uuid.NewV4().String()
+
+	// Store audit event
+	createdEvent, err := api.AuditRepo.AddAuditEvent(event)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return createdEvent, nil
+}
+
+func (api AuthAPI) ListAuditEvents(requestInfo RequestInfo, filter AuditFilter) ([]AuditEvent, error) {
+	// Validate fields
+	if len(filter.User) > 0 && !IsValidUserExternalID(filter.User) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: user %v", filter.User),
+		}
+	}
+	if len(filter.Action) > 0 {
+		if err := AreValidActions([]string{filter.Action}); err != nil {
+			// Transform to API error
+			apiError := err.(*Error)
+			return nil, &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: apiError.Message,
+			}
+		}
+	}
+	if len(filter.UrnPrefix) > 0 && (!strings.HasPrefix(filter.UrnPrefix, "urn:") || strings.Contains(filter.UrnPrefix, "*")) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: urnPrefix %v", filter.UrnPrefix),
+		}
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: to %v, it must be after from %v",
+				filter.To.Format(time.RFC3339), filter.From.Format(time.RFC3339)),
+		}
+	}
+
+	// Call repo to retrieve the audit events
+	events, err := api.AuditRepo.GetAuditEventsFiltered(filter)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions, events are authorized by the urn of their target
+	resourceUrn := "urn:*"
+	if len(filter.UrnPrefix) > 0 {
+		resourceUrn = filter.UrnPrefix + "*"
+	}
+	eventsToAuthorize := []Resource{}
+	for _, event := range events {
+		eventsToAuthorize = append(eventsToAuthorize, event)
+	}
+	authorizedEvents, err := api.getAuthorizedResources(requestInfo, resourceUrn, AUDIT_ACTION_READ_AUDIT_LOG, eventsToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsFiltered := []AuditEvent{}
+	for _, event := range authorizedEvents {
+		eventsFiltered = append(eventsFiltered, event.(AuditEvent))
+	}
+
+	return eventsFiltered, nil
+}
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddAuditEvent(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		event AuditEvent
+		// Expected result
+		expectedResponse *AuditEvent
+		wantError        error
+		// Manager Results
+		addAuditEventResult *AuditEvent
+		// Manager Errors
+		addAuditEventMethodErr error
+	}{
+		"OkCase": {
+			event: AuditEvent{
+				RequestID: "REQUEST-ID",
+				User:      "123",
+				Action:    USER_ACTION_CREATE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "456"),
+				Outcome:   AUDIT_OUTCOME_SUCCESS,
+				CreateAt:  now,
+			},
+			expectedResponse: &AuditEvent{
+				ID:        "EVENT-ID",
+				RequestID: "REQUEST-ID",
+				User:      "123",
+				Action:    USER_ACTION_CREATE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "456"),
+				Outcome:   AUDIT_OUTCOME_SUCCESS,
+				CreateAt:  now,
+			},
+			addAuditEventResult: &AuditEvent{
+				ID:        "EVENT-ID",
+				RequestID: "REQUEST-ID",
+				User:      "123",
+				Action:    USER_ACTION_CREATE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "456"),
+				Outcome:   AUDIT_OUTCOME_SUCCESS,
+				CreateAt:  now,
+			},
+		},
+		"ErrorCaseAddAuditEventDBErr": {
+			event: AuditEvent{
+				RequestID: "REQUEST-ID",
+				User:      "123",
+				Action:    USER_ACTION_CREATE_USER,
+				Outcome:   AUDIT_OUTCOME_FAILURE,
+				CreateAt:  now,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			addAuditEventMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddAuditEventMethod][0] = testcase.addAuditEventResult
+		testRepo.ArgsOut[AddAuditEventMethod][1] = testcase.addAuditEventMethodErr
+
+		response, err := testAPI.AddAuditEvent(testcase.event)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+
+		// Check stored event has an ID
+		storedEvent := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+		if storedEvent.ID == "" {
+			t.Errorf("Test %v failed. Stored event without ID", x)
+		}
+	}
+}
+
+func TestAuthAPI_ListAuditEvents(t *testing.T) {
+	from := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []AuditEvent{
+		{
+			ID:      "EVENT1",
+			User:    "123",
+			Action:  GROUP_ACTION_CREATE_GROUP,
+			Urn:     CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			Outcome: AUDIT_OUTCOME_SUCCESS,
+		},
+		{
+			ID:      "EVENT2",
+			User:    "123",
+			Action:  GROUP_ACTION_CREATE_GROUP,
+			Urn:     CreateUrn("org2", RESOURCE_GROUP, "/path/", "group2"),
+			Outcome: AUDIT_OUTCOME_SUCCESS,
+		},
+		{
+			ID:      "EVENT3",
+			User:    "123",
+			Action:  GROUP_ACTION_CREATE_GROUP,
+			Outcome: AUDIT_OUTCOME_FAILURE,
+		},
+		{
+			ID:      "EVENT4",
+			User:    "123",
+			Action:  GROUP_ACTION_DELETE_GROUP,
+			Urn:     CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			Outcome: AUDIT_OUTCOME_FAILURE,
+			Error: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		filter      AuditFilter
+		// Expected result
+		expectedResponse []AuditEvent
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult    *User
+		getGroupsByUserIDResult      []Group
+		getAttachedPoliciesResult    []GroupPolicy
+		getAuditEventsFilteredResult []AuditEvent
+		// Manager Errors
+		getAuditEventsFilteredMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			filter: AuditFilter{
+				User:   "123",
+				Action: GROUP_ACTION_CREATE_GROUP,
+			},
+			expectedResponse:             events,
+			getAuditEventsFilteredResult: events,
+		},
+		"OkCaseAuditor": {
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			filter: AuditFilter{
+				UrnPrefix: "urn:iws:iam:org1:group/",
+			},
+			expectedResponse: []AuditEvent{
+				events[0],
+				events[3],
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "AUDITOR-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "AUDITORS-ID",
+					Name: "auditors",
+					Org:  "org1",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-AUDITOR-ID",
+						Name: "policyAuditor",
+						Org:  "org1",
+						Path: "/path/",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									AUDIT_ACTION_READ_AUDIT_LOG,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getAuditEventsFilteredResult: events,
+		},
+		"OkCaseAuditorFailedEvents": {
+			// Failed events keep their target, so they are authorized like successful ones
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			filter: AuditFilter{
+				User: "123",
+			},
+			expectedResponse: []AuditEvent{
+				events[3],
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "AUDITOR-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "AUDITORS-ID",
+					Name: "auditors",
+					Org:  "org1",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-AUDITOR-ID",
+						Name: "policyAuditor",
+						Org:  "org1",
+						Path: "/path/",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									AUDIT_ACTION_READ_AUDIT_LOG,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getAuditEventsFilteredResult: []AuditEvent{
+				events[2],
+				events[3],
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:*",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getAuditEventsFilteredResult: events,
+		},
+		"ErrorCaseInvalidUser": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			filter: AuditFilter{
+				User: "*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: user *",
+			},
+		},
+		"ErrorCaseInvalidAction": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			filter: AuditFilter{
+				Action: "iam:Read Log",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "No regex match in action: iam:Read Log",
+			},
+		},
+		"ErrorCaseInvalidUrnPrefix": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			filter: AuditFilter{
+				UrnPrefix: "urn:iws:iam:*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: urnPrefix urn:iws:iam:*",
+			},
+		},
+		"ErrorCaseInvalidTimeRange": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			filter: AuditFilter{
+				From: &from,
+				To:   &to,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: to 2030-01-01T00:00:00Z, it must be after from 2030-01-02T00:00:00Z",
+			},
+		},
+		"ErrorCaseGetAuditEventsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAuditEventsFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][0] = testcase.getAuditEventsFilteredResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][1] = testcase.getAuditEventsFilteredMethodErr
+
+		response, err := testAPI.ListAuditEvents(testcase.requestInfo, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+	}
+}
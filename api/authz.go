@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tecsisa/foulkon/database"
 )
@@ -12,8 +13,13 @@ import (
 
 type RequestInfo struct {
 	Identifier string
-	Admin      bool
-	RequestID  string
+	// Request done by the worker itself, like the bootstrap of the first admin or the provisioning of
+	// users, that isn't authorized.
+	// Administrators are users authorized by the policies of their groups.
+	Admin     bool
+	RequestID string
+	// Audit event of the request, nil if request isn't audited
+	Audit *AuditEvent
 }
 
 type EffectRestriction struct {
@@ -89,6 +95,23 @@ func (api AuthAPI) GetAuthorizedPolicies(requestInfo RequestInfo, resourceUrn st
 	return policiesFiltered, nil
 }
 
+// Return authorized organizations for specified user combined with resource+action
+func (api AuthAPI) GetAuthorizedOrganizations(requestInfo RequestInfo, resourceUrn string, action string, orgs []Organization) ([]Organization, error) {
+	resourcesToAuthorize := []Resource{}
+	for _, org := range orgs {
+		resourcesToAuthorize = append(resourcesToAuthorize, org)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, resourceUrn, action, resourcesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	orgsFiltered := []Organization{}
+	for _, res := range resources {
+		orgsFiltered = append(orgsFiltered, res.(Organization))
+	}
+	return orgsFiltered, nil
+}
+
 // Get the resources where the specified user has the action granted
 func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error) {
 	// Validate parameters
@@ -148,14 +171,25 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 
 // This method retrieves filtered resources where the authenticated user has permissions
 func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource) ([]Resource, error) {
-	// If user is an admin return all resources without restriction
+	start := time.Now()
+
+	// Target of an audited mutation is known once it's authorized, keep it even if the mutation fails
+	if requestInfo.Audit != nil && requestInfo.Audit.Action == action && !strings.Contains(resourceUrn, "*") {
+		requestInfo.Audit.Urn = resourceUrn
+	}
+
+	// If request is done by the worker return all resources without restriction
 	if requestInfo.Admin {
+		api.logDecision(requestInfo, resourceUrn, action, resources, resources, nil, start)
 		return resources, nil
 	}
 
 	// Check authorization for this user
-	restrictions, err := api.getRestrictions(requestInfo.Identifier, action, resourceUrn)
+	restrictions, statements, err := api.getRestrictions(requestInfo.Identifier, action, resourceUrn)
 	if err != nil {
+		if err.(*Error).Code == UNAUTHORIZED_RESOURCES_ERROR {
+			api.logDecision(requestInfo, resourceUrn, action, resources, nil, nil, start)
+		}
 		return nil, err
 	}
 
@@ -163,6 +197,7 @@ func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn s
 
 	// Check if there are some restrictions for this urn resource
 	if len(restrictions.AllowedFullUrns) < 1 && len(restrictions.AllowedUrnPrefixes) < 1 {
+		api.logDecision(requestInfo, resourceUrn, action, resources, nil, statements, start)
 		return nil, &Error{
 			Code:    UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v", requestInfo.Identifier, resourceUrn),
@@ -171,12 +206,14 @@ func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn s
 
 	// Filter resources
 	resourcesFiltered := filterResources(resources, restrictions)
+	api.logDecision(requestInfo, resourceUrn, action, resources, resourcesFiltered, statements, start)
 
 	return resourcesFiltered, nil
 }
 
-// Get restrictions for this action and full resource or prefix resource, attached to this authenticated user
-func (api AuthAPI) getRestrictions(externalID string, action string, resource string) (*Restrictions, error) {
+// Get restrictions for this action and full resource or prefix resource, attached to this authenticated user.
+// It also returns the statements that match the action and resource.
+func (api AuthAPI) getRestrictions(externalID string, action string, resource string) (*Restrictions, []Statement, error) {
 	// Get user if exists
 	user, err := api.UserRepo.GetUserByExternalID(externalID)
 
@@ -186,26 +223,30 @@ func (api AuthAPI) getRestrictions(externalID string, action string, resource st
 		dbError := err.(*database.Error)
 		switch dbError.Code {
 		case database.USER_NOT_FOUND:
-			return nil, &Error{
+			return nil, nil, &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: fmt.Sprintf("Authenticated user with externalId %v not found. Unable to retrieve permissions.", externalID),
 			}
 		default:
-			return nil, &Error{
+			return nil, nil, &Error{
 				Code:    UNKNOWN_API_ERROR,
 				Message: dbError.Message,
 			}
 		}
 	}
 
+	if err := checkUserIsNotSuspended(user); err != nil {
+		return nil, nil, err
+	}
+
 	groups, err := api.getGroupsByUser(user.ID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	policies, err := api.getPoliciesByGroups(groups)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Retrieve valid statements
@@ -215,7 +256,18 @@ func (api AuthAPI) getRestrictions(externalID string, action string, resource st
 	var authResources *Restrictions
 	authResources = getRestrictions(statements, resource, isFullUrn(resource))
 
-	return authResources, nil
+	return authResources, getStatementsByResource(statements, resource, isFullUrn(resource)), nil
+}
+
+// Suspended users aren't allowed to do anything, whatever the policies of their groups are
+func checkUserIsNotSuspended(user *User) error {
+	if user.Status == USER_STATUS_SUSPENDED {
+		return &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("Authenticated user with externalId %v is suspended", user.ExternalID),
+		}
+	}
+	return nil
 }
 
 func (api AuthAPI) getGroupsByUser(userID string) ([]Group, error) {
@@ -240,6 +292,7 @@ func (api AuthAPI) getPoliciesByGroups(groups []Group) ([]Policy, error) {
 
 	// Create an empty slice
 	policies := []Policy{}
+	now := time.Now().UTC()
 
 	// Retrieve per each group its attached policies
 	for _, group := range groups {
@@ -254,14 +307,28 @@ func (api AuthAPI) getPoliciesByGroups(groups []Group) ([]Policy, error) {
 			}
 		}
 
-		for _, policy := range policiesAttached {
-			policies = append(policies, policy)
+		// Ignore attachments that aren't effective now
+		for _, attachment := range policiesAttached {
+			if isAttachmentEffective(attachment, now) {
+				policies = append(policies, attachment.Policy)
+			}
 		}
 	}
 
 	return policies, nil
 }
 
+// Check if a policy attachment is effective at the given time
+func isAttachmentEffective(attachment GroupPolicy, now time.Time) bool {
+	if attachment.NotBefore != nil && now.Before(*attachment.NotBefore) {
+		return false
+	}
+	if attachment.NotAfter != nil && !now.Before(*attachment.NotAfter) {
+		return false
+	}
+	return true
+}
+
 // Filter a slice of statements for a specified action
 func getStatementsByRequestedAction(policies []Policy, requestedAction string) []Statement {
 	// Check received policies
@@ -458,15 +525,8 @@ func getRestrictions(statements []Statement, resource string, resourceIsFullUrn
 				statementIsFullUrn := isFullUrn(statementResource)
 				statementIsAllow := statement.Effect == "allow"
 
-				if !resourceIsFullUrn {
-					if isContainedOrEqual(statementResource, resource) || isContainedOrEqual(resource, statementResource) {
-						restrictions.insertRestriction(statementIsAllow, statementIsFullUrn, statementResource)
-					}
-				} else {
-					// Insert restriction if resource is contained in statements
-					if isContainedOrEqual(resource, statementResource) {
-						restrictions.insertRestriction(statementIsAllow, statementIsFullUrn, statementResource)
-					}
+				if isResourceMatched(statementResource, resource, resourceIsFullUrn) {
+					restrictions.insertRestriction(statementIsAllow, statementIsFullUrn, statementResource)
 				}
 			}
 		}
@@ -475,6 +535,29 @@ func getRestrictions(statements []Statement, resource string, resourceIsFullUrn
 	return restrictions
 }
 
+// Returns true if a statement resource applies to a resource. A full urn resource must be contained in the
+// statement resource, a prefix resource may also contain it.
+func isResourceMatched(statementResource string, resource string, resourceIsFullUrn bool) bool {
+	if !resourceIsFullUrn {
+		return isContainedOrEqual(statementResource, resource) || isContainedOrEqual(resource, statementResource)
+	}
+	return isContainedOrEqual(resource, statementResource)
+}
+
+// Filter a slice of statements, keeping the ones with some resource that applies to the specified resource
+func getStatementsByResource(statements []Statement, resource string, resourceIsFullUrn bool) []Statement {
+	matchedStatements := []Statement{}
+	for _, statement := range statements {
+		for _, statementResource := range statement.Resources {
+			if isResourceMatched(statementResource, resource, resourceIsFullUrn) {
+				matchedStatements = append(matchedStatements, statement)
+				break
+			}
+		}
+	}
+	return matchedStatements
+}
+
 // Remove resources that are not allowed by the restrictions
 func filterResources(resources []Resource, restrictions *Restrictions) []Resource {
 	filteredResource := []Resource{}
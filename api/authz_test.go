@@ -289,7 +289,7 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 		getGroupsByUserIDResult []Group
 		getGroupsByUserIDError  error
 		// GetAttachedPolicies Method Out Arguments
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getAttachedPoliciesError  error
 	}{
 		"ErrortestCaseInvalidAction": {
@@ -391,18 +391,20 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -431,28 +433,30 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/path2"),
-								GetUrnPrefix("example2", RESOURCE_POLICY, "/path/path2"),
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/path2"),
+									GetUrnPrefix("example2", RESOURCE_POLICY, "/path/path2"),
+								},
 							},
 						},
 					},
@@ -485,33 +489,35 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								"product:DoAction",
-							},
-							Resources: []string{
-								"urn:ews:product:instance:resource/path1/resourceAllow",
-								"urn:ews:product:instance:resource/path2/resourceAllow",
-								"urn:ews:product:instance:resource/path1*",
-								"urn:ews:product:instance:resource/path2*",
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								"product:DoAction",
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									"product:DoAction",
+								},
+								Resources: []string{
+									"urn:ews:product:instance:resource/path1/resourceAllow",
+									"urn:ews:product:instance:resource/path2/resourceAllow",
+									"urn:ews:product:instance:resource/path1*",
+									"urn:ews:product:instance:resource/path2*",
+								},
 							},
-							Resources: []string{
-								"urn:ews:product:instance:resource/path1/resourceDeny",
-								"urn:ews:product:instance:resource/path2/resourceDeny",
-								"urn:ews:product:instance:resource/path3*",
-								"urn:ews:product:instance:resource/path4*",
+							{
+								Effect: "deny",
+								Actions: []string{
+									"product:DoAction",
+								},
+								Resources: []string{
+									"urn:ews:product:instance:resource/path1/resourceDeny",
+									"urn:ews:product:instance:resource/path2/resourceDeny",
+									"urn:ews:product:instance:resource/path3*",
+									"urn:ews:product:instance:resource/path4*",
+								},
 							},
 						},
 					},
@@ -561,6 +567,8 @@ func TestGetAuthorizedResources(t *testing.T) {
 		resourcesToAuthorize []Resource
 		// Resources authorized by method
 		resourcesAuthorized []Resource
+		// Urn of the audit event of the request after authorization
+		expectedAuditUrn string
 		// Error to compare when we expect an error
 		wantError error
 		// GetUserByExternalID Method Out Arguments
@@ -570,7 +578,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 		getGroupsByUserIDResult []Group
 		getGroupsByUserIDError  error
 		// GetAttachedPolicies Method Out Arguments
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getAttachedPoliciesError  error
 	}{
 		"OKtestCaseAdmin": {
@@ -649,6 +657,34 @@ func TestGetAuthorizedResources(t *testing.T) {
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:example:group/path/group1",
 			},
 		},
+		"ErrortestCaseNotAllowedAuditedMutation": {
+			// Failed mutation keeps the urn of its target in its audit event
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+				Audit: &AuditEvent{
+					Action: GROUP_ACTION_DELETE_GROUP,
+					Urn:    CreateUrn("example", RESOURCE_GROUP, "/", "group1"),
+				},
+			},
+			resourceUrn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			action:      GROUP_ACTION_DELETE_GROUP,
+			resourcesToAuthorize: []Resource{
+				Group{
+					ID:  "654321",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			expectedAuditUrn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:example:group/path/group1",
+			},
+		},
 		"OKtestCaseResourcesFiltered": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -682,18 +718,20 @@ func TestGetAuthorizedResources(t *testing.T) {
 					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+								},
 							},
 						},
 					},
@@ -730,18 +768,20 @@ func TestGetAuthorizedResources(t *testing.T) {
 					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
+								},
 							},
 						},
 					},
@@ -766,6 +806,11 @@ func TestGetAuthorizedResources(t *testing.T) {
 
 		authorizedResources, err := testAPI.getAuthorizedResources(test.requestInfo, test.resourceUrn, test.action, test.resourcesToAuthorize)
 		checkMethodResponse(t, n, test.wantError, err, test.resourcesAuthorized, authorizedResources)
+		if test.requestInfo.Audit != nil && test.requestInfo.Audit.Urn != test.expectedAuditUrn {
+			t.Errorf("Test %v failed. Received different audit urn (wanted:%v / received:%v)",
+				n, test.expectedAuditUrn, test.requestInfo.Audit.Urn)
+			continue
+		}
 		if !test.requestInfo.Admin {
 			// Check received authenticated user in method GetUserByExternalID
 			if testRepo.ArgsIn[GetUserByExternalIDMethod][0] != test.requestInfo.Identifier {
@@ -796,7 +841,7 @@ func TestGetRestrictions(t *testing.T) {
 		getGroupsByUserIDResult []Group
 		getGroupsByUserIDError  error
 		// GetAttachedPolicies Method Out Arguments
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getAttachedPoliciesError  error
 	}{
 		"ErrortestCaseGetUserAuthenticatedNotFound": {
@@ -822,6 +867,20 @@ func TestGetRestrictions(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"ErrortestCaseGetUserAuthenticatedSuspended": {
+			authUserID:  "Suspended",
+			resourceUrn: "urn:resource",
+			action:      USER_ACTION_GET_USER,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId Suspended is suspended",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "UserID",
+				ExternalID: "Suspended",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+		},
 		"ErrortestCaseGetGroupsError": {
 			authUserID:  "InternalError",
 			resourceUrn: "urn:resource",
@@ -902,33 +961,35 @@ func TestGetRestrictions(t *testing.T) {
 					ID: "GROUP-USER-ID",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupAllow"),
-								CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupAllow"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupAllow"),
+									CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupAllow"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
+								},
 							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupDeny"),
-								CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupDeny"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path3/"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path4/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupDeny"),
+									CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupDeny"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path3/"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path4/"),
+								},
 							},
 						},
 					},
@@ -961,33 +1022,35 @@ func TestGetRestrictions(t *testing.T) {
 					ID: "GROUP-USER-ID",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:  "POLICY-USER-ID",
-					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
+					Policy: Policy{
+						ID:  "POLICY-USER-ID",
+						Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupAllow"),
+									CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupAllow"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
+								},
 							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupAllow"),
-								CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupAllow"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupDeny"),
-								CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupDeny"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path3/"),
-								GetUrnPrefix("example", RESOURCE_GROUP, "/path4/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_GROUP, "/path1/", "groupDeny"),
+									CreateUrn("example", RESOURCE_GROUP, "/path2/", "groupDeny"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path3/"),
+									GetUrnPrefix("example", RESOURCE_GROUP, "/path4/"),
+								},
 							},
 						},
 					},
@@ -1009,7 +1072,7 @@ func TestGetRestrictions(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
 
-		restrictions, err := testAPI.getRestrictions(test.authUserID, test.action, test.resourceUrn)
+		restrictions, _, err := testAPI.getRestrictions(test.authUserID, test.action, test.resourceUrn)
 		checkMethodResponse(t, n, test.wantError, err, test.expectedRestrictions, restrictions)
 		if test.wantError == nil && testRepo.ArgsIn[GetUserByExternalIDMethod][0] != test.authUserID {
 			t.Errorf("Test %v failed. Received different user identifiers (wanted:%v / received:%v)",
@@ -1099,7 +1162,7 @@ func TestGetPoliciesByGroups(t *testing.T) {
 		// Error to compare when we expect an error
 		wantError error
 		// GetAttachedPolicies Method Out Arguments
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getAttachedPoliciesError  error
 	}{
 		"OktestCaseEmptyGroups": {
@@ -1135,9 +1198,11 @@ func TestGetPoliciesByGroups(t *testing.T) {
 					ID: "PolicyID",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID: "PolicyID",
+					Policy: Policy{
+						ID: "PolicyID",
+					},
 				},
 			},
 		},
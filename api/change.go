@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Entry of the change log. Seq is assigned when the change is stored, and it's monotonically increasing so
+// clients can resume from the last one they read. Type is the action of the mutation, Urn the mutated resource
+// and Before and After are JSON snapshots of the resource before and after the mutation.
+type Change struct {
+	Seq      int64           `json:"seq, omitempty"`
+	Type     string          `json:"type, omitempty"`
+	Urn      string          `json:"urn, omitempty"`
+	Before   json.RawMessage `json:"before, omitempty"`
+	After    json.RawMessage `json:"after, omitempty"`
+	CreateAt time.Time       `json:"createAt, omitempty"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("[seq: %v, type: %v, urn: %v, createAt: %v]",
+		c.Seq, c.Type, c.Urn, c.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (c Change) GetUrn() string {
+	return c.Urn
+}
+
+// Page of the change log. LastSeq is the sequence number of the last change read, authorized or not, or the
+// requested one if there aren't new changes, so it's where the next page starts.
+type ChangeFeed struct {
+	Changes []Change `json:"changes, omitempty"`
+	LastSeq int64    `json:"lastSeq, omitempty"`
+}
+
+// CHANGE API IMPLEMENTATION
+
+func (api AuthAPI) ListChanges(requestInfo RequestInfo, since int64, limit int) (*ChangeFeed, error) {
+	// Validate fields
+	if since < 0 {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: since %v, it can't be negative", since),
+		}
+	}
+	if limit < 1 || limit > MAX_CHANGES_LIMIT {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: limit %v, it must be between 1 and %v", limit, MAX_CHANGES_LIMIT),
+		}
+	}
+
+	// Call repo to retrieve the changes
+	changes, err := api.ChangeRepo.GetChanges(since, limit)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	feed := &ChangeFeed{
+		Changes: []Change{},
+		LastSeq: since,
+	}
+	if len(changes) < 1 {
+		return feed, nil
+	}
+	feed.LastSeq = changes[len(changes)-1].Seq
+
+	// Check restrictions, changes are authorized by the urn of the mutated resource
+	changesToAuthorize := []Resource{}
+	for _, change := range changes {
+		changesToAuthorize = append(changesToAuthorize, change)
+	}
+	authorizedChanges, err := api.getAuthorizedResources(requestInfo, "urn:*", CHANGE_ACTION_READ_CHANGES, changesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range authorizedChanges {
+		feed.Changes = append(feed.Changes, change.(Change))
+	}
+
+	return feed, nil
+}
+
+// CHANGE RECORDING
+
+// Record a successful mutation of a resource done by the action. Before and after are the states of the
+// mutated resource, nil if it didn't exist. The change is stored in the audit event of the request, if the
+// request is audited, appended to the change log and notified to the webhooks subscribed to the action.
+// Mutation is already done, so webhooks are notified even if the change can't be appended, but the error is
+// returned to let the caller know that the change log misses it.
+func (api AuthAPI) recordChange(requestInfo RequestInfo, action string, urn string, before interface{}, after interface{}) error {
+	beforeSnapshot := changeSnapshot(before)
+	afterSnapshot := changeSnapshot(after)
+
+	if requestInfo.Audit != nil {
+		requestInfo.Audit.Urn = urn
+		requestInfo.Audit.Before = beforeSnapshot
+		requestInfo.Audit.After = afterSnapshot
+	}
+
+	now := time.Now().UTC()
+	change := Change{
+		Type:     action,
+		Urn:      urn,
+		Before:   beforeSnapshot,
+		After:    afterSnapshot,
+		CreateAt: now,
+	}
+	_, err := api.ChangeRepo.AddChange(change)
+
+	api.notifyWebhooks(WebhookEvent{
+		ID:        uuid.NewV4().String(),
+		Type:      action,
+		RequestID: requestInfo.RequestID,
+		User:      requestInfo.Identifier,
+		Urn:       urn,
+		Before:    beforeSnapshot,
+		After:     afterSnapshot,
+		CreateAt:  now,
+	})
+
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Serialize resource state as a JSON snapshot, nil resources have no snapshot
+func changeSnapshot(resource interface{}) json.RawMessage {
+	if resource == nil {
+		return nil
+	}
+	snapshot, err := json.Marshal(resource)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
@@ -0,0 +1,383 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ListChanges(t *testing.T) {
+	changes := []Change{
+		{
+			Seq:   11,
+			Type:  GROUP_ACTION_CREATE_GROUP,
+			Urn:   CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			After: json.RawMessage(`{"name":"group1"}`),
+		},
+		{
+			Seq:   12,
+			Type:  GROUP_ACTION_CREATE_GROUP,
+			Urn:   CreateUrn("org2", RESOURCE_GROUP, "/path/", "group2"),
+			After: json.RawMessage(`{"name":"group2"}`),
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		since       int64
+		limit       int
+		// Expected result
+		expectedResponse *ChangeFeed
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		getChangesResult          []Change
+		// Manager Errors
+		getChangesMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			since: 10,
+			limit: 100,
+			expectedResponse: &ChangeFeed{
+				Changes: changes,
+				LastSeq: 12,
+			},
+			getChangesResult: changes,
+		},
+		"OkCaseNoChanges": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			since: 12,
+			limit: 100,
+			expectedResponse: &ChangeFeed{
+				Changes: []Change{},
+				LastSeq: 12,
+			},
+		},
+		"OkCaseFilteredByOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "9999",
+				Admin:      false,
+			},
+			since: 10,
+			limit: 100,
+			expectedResponse: &ChangeFeed{
+				Changes: []Change{
+					changes[0],
+				},
+				LastSeq: 12,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "CACHE-ID",
+				ExternalID: "9999",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "CACHES-ID",
+					Name: "caches",
+					Org:  "org1",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-CACHE-ID",
+						Name: "policyCache",
+						Org:  "org1",
+						Path: "/path/",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									CHANGE_ACTION_READ_CHANGES,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getChangesResult: changes,
+		},
+		"ErrorCaseInvalidSince": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			since: -1,
+			limit: 100,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: since -1, it can't be negative",
+			},
+		},
+		"ErrorCaseInvalidLimit": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			limit: MAX_CHANGES_LIMIT + 1,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: limit 1001, it must be between 1 and 1000",
+			},
+		},
+		"ErrorCaseGetChangesDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			limit: 100,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getChangesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetChangesMethod][0] = testcase.getChangesResult
+		testRepo.ArgsOut[GetChangesMethod][1] = testcase.getChangesMethodErr
+
+		feed, err := testAPI.ListChanges(testcase.requestInfo, testcase.since, testcase.limit)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, feed)
+	}
+}
+
+func TestAuthAPI_recordChange(t *testing.T) {
+	user := &User{
+		ID:         "USER-ID",
+		ExternalID: "123",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123"),
+	}
+	userSnapshot, _ := json.Marshal(user)
+	creator := &User{
+		ID:         "CREATOR-ID",
+		ExternalID: "9999",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "9999"),
+	}
+	creatorGroups := []Group{
+		{
+			ID:   "GROUP-ID",
+			Name: "group1",
+			Org:  "org1",
+			Path: "/path/",
+		},
+	}
+	readUserChangesPolicies := []GroupPolicy{
+		{
+			Policy: Policy{
+				ID:   "POLICY-ID",
+				Name: "policy1",
+				Org:  "org1",
+				Path: "/path/",
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							CHANGE_ACTION_READ_CHANGES,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// Method args
+		audit  *AuditEvent
+		action string
+		urn    string
+		before interface{}
+		after  interface{}
+		// Expected result
+		expectedAudit *AuditEvent
+		wantError     error
+		// Manager Results
+		getWebhooksResult         []Webhook
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		// Manager Errors
+		addChangeMethodErr error
+		// Expected deliveries
+		expectedDeliveries int
+	}{
+		"OkCaseCreate": {
+			audit: &AuditEvent{
+				Action: USER_ACTION_CREATE_USER,
+			},
+			action: USER_ACTION_CREATE_USER,
+			urn:    user.Urn,
+			after:  user,
+			expectedAudit: &AuditEvent{
+				Action: USER_ACTION_CREATE_USER,
+				Urn:    user.Urn,
+				After:  userSnapshot,
+			},
+		},
+		"OkCaseDelete": {
+			audit: &AuditEvent{
+				Action: USER_ACTION_DELETE_USER,
+			},
+			action: USER_ACTION_DELETE_USER,
+			urn:    user.Urn,
+			before: user,
+			expectedAudit: &AuditEvent{
+				Action: USER_ACTION_DELETE_USER,
+				Urn:    user.Urn,
+				Before: userSnapshot,
+			},
+		},
+		"OkCaseNotAudited": {
+			action: USER_ACTION_CREATE_USER,
+			urn:    user.Urn,
+			after:  user,
+		},
+		"OkCaseWebhookSubscribed": {
+			action: USER_ACTION_CREATE_USER,
+			urn:    user.Urn,
+			after:  user,
+			getWebhooksResult: []Webhook{
+				{
+					ID:         "WEBHOOK1",
+					EventTypes: []string{USER_ACTION_CREATE_USER},
+					CreatedBy:  creator.ExternalID,
+				},
+				{
+					ID:         "WEBHOOK2",
+					EventTypes: []string{USER_ACTION_DELETE_USER},
+					CreatedBy:  creator.ExternalID,
+				},
+			},
+			getUserByExternalIDResult: creator,
+			getGroupsByUserIDResult:   creatorGroups,
+			getAttachedPoliciesResult: readUserChangesPolicies,
+			expectedDeliveries:        1,
+		},
+		"OkCaseWebhookCreatorNotAllowed": {
+			action: USER_ACTION_CREATE_USER,
+			urn:    user.Urn,
+			after:  user,
+			getWebhooksResult: []Webhook{
+				{
+					ID:         "WEBHOOK1",
+					EventTypes: []string{USER_ACTION_CREATE_USER},
+					CreatedBy:  creator.ExternalID,
+				},
+			},
+			getUserByExternalIDResult: creator,
+			getGroupsByUserIDResult:   creatorGroups,
+		},
+		"ErrorCaseAddChangeDBErr": {
+			action: USER_ACTION_CREATE_USER,
+			urn:    user.Urn,
+			after:  user,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhooksResult: []Webhook{
+				{
+					ID:         "WEBHOOK1",
+					EventTypes: []string{USER_ACTION_CREATE_USER},
+					CreatedBy:  creator.ExternalID,
+				},
+			},
+			getUserByExternalIDResult: creator,
+			getGroupsByUserIDResult:   creatorGroups,
+			getAttachedPoliciesResult: readUserChangesPolicies,
+			addChangeMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+			expectedDeliveries: 1,
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhooksMethod][0] = testcase.getWebhooksResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[AddChangeMethod][1] = testcase.addChangeMethodErr
+
+		err := testAPI.recordChange(RequestInfo{Identifier: "admin", Audit: testcase.audit}, testcase.action,
+			testcase.urn, testcase.before, testcase.after)
+		if diff := pretty.Compare(err, testcase.wantError); diff != "" {
+			t.Errorf("Test %v failed. Received different errors (received/wanted) %v", x, diff)
+			continue
+		}
+		if diff := pretty.Compare(testcase.audit, testcase.expectedAudit); diff != "" {
+			t.Errorf("Test %v failed. Received different audit events (received/wanted) %v", x, diff)
+			continue
+		}
+
+		// Check appended change
+		change := testRepo.ArgsIn[AddChangeMethod][0].(Change)
+		if change.CreateAt.IsZero() {
+			t.Errorf("Test %v failed. Change without creation time", x)
+			continue
+		}
+		change.CreateAt = time.Time{}
+		expectedChange := Change{
+			Type:   testcase.action,
+			Urn:    testcase.urn,
+			Before: changeSnapshot(testcase.before),
+			After:  changeSnapshot(testcase.after),
+		}
+		if diff := pretty.Compare(change, expectedChange); diff != "" {
+			t.Errorf("Test %v failed. Received different changes (received/wanted) %v", x, diff)
+			continue
+		}
+
+		var deliveries []WebhookDelivery
+		if testRepo.ArgsIn[AddWebhookDeliveriesMethod][0] != nil {
+			deliveries = testRepo.ArgsIn[AddWebhookDeliveriesMethod][0].([]WebhookDelivery)
+		}
+		if len(deliveries) != testcase.expectedDeliveries {
+			t.Errorf("Test %v failed. Received different deliveries (received/wanted) %v/%v", x,
+				len(deliveries), testcase.expectedDeliveries)
+			continue
+		}
+		for _, delivery := range deliveries {
+			if delivery.Event.Type != testcase.action || delivery.Event.Urn != testcase.urn ||
+				delivery.Event.User != "admin" || delivery.Status != WEBHOOK_DELIVERY_STATUS_PENDING {
+				t.Errorf("Test %v failed. Received unexpected delivery %v", x, delivery)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+// TYPE DEFINITIONS
+
+// Authorization decision taken over a set of requested resources. Statements are the statements of the
+// user's policies that match the action and resource urn, Latency is the time spent in the decision.
+type AuthzDecision struct {
+	ID          string        `json:"id, omitempty"`
+	RequestID   string        `json:"requestId, omitempty"`
+	User        string        `json:"user, omitempty"`
+	Admin       bool          `json:"admin, omitempty"`
+	Action      string        `json:"action, omitempty"`
+	ResourceUrn string        `json:"resourceUrn, omitempty"`
+	Requested   []string      `json:"requested, omitempty"`
+	Allowed     []string      `json:"allowed, omitempty"`
+	Statements  []Statement   `json:"statements, omitempty"`
+	Latency     time.Duration `json:"latency, omitempty"`
+	CreateAt    time.Time     `json:"createAt, omitempty"`
+}
+
+func (d AuthzDecision) String() string {
+	return fmt.Sprintf("[id: %v, requestID: %v, user: %v, action: %v, resourceUrn: %v, requested: %v, allowed: %v, latency: %v]",
+		d.ID, d.RequestID, d.User, d.Action, d.ResourceUrn, len(d.Requested), len(d.Allowed), d.Latency)
+}
+
+// Log of authorization decisions. Only a fraction of the decisions given by Sampling, between 0 and 1,
+// is stored in each one of the sinks.
+type DecisionLog struct {
+	Sinks    []DecisionSink
+	Sampling float64
+}
+
+// Check if a decision must be logged according to the sampling
+func (l *DecisionLog) isSampled() bool {
+	return l.Sampling >= 1 || rand.Float64() < l.Sampling
+}
+
+// PRIVATE HELPER METHODS
+
+// Store authorization decision in the sinks of the decision log, if there is a decision log and the decision is sampled
+func (api AuthAPI) logDecision(requestInfo RequestInfo, resourceUrn string, action string, requested []Resource,
+	allowed []Resource, statements []Statement, start time.Time) {
+	if api.DecisionLog == nil || len(api.DecisionLog.Sinks) < 1 || !api.DecisionLog.isSampled() {
+		return
+	}
+
+	decision := AuthzDecision{
+		ID:          uuid.NewV4().String(),
+		RequestID:   requestInfo.RequestID,
+		User:        requestInfo.Identifier,
+		Admin:       requestInfo.Admin,
+		Action:      action,
+		ResourceUrn: resourceUrn,
+		Requested:   getResourceUrns(requested),
+		Allowed:     getResourceUrns(allowed),
+		Statements:  statements,
+		Latency:     time.Since(start),
+		CreateAt:    start.UTC(),
+	}
+	for _, sink := range api.DecisionLog.Sinks {
+		if err := sink.AddAuthzDecision(decision); err != nil {
+			api.Logger.Errorf("Unable to log authorization decision %v: %v", decision, err)
+		}
+	}
+}
+
+// Retrieve urns of a slice of resources
+func getResourceUrns(resources []Resource) []string {
+	urns := []string{}
+	for _, resource := range resources {
+		urns = append(urns, resource.GetUrn())
+	}
+	return urns
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// Decision sink that keeps decisions in memory
+type testDecisionSink struct {
+	decisions []AuthzDecision
+}
+
+func (s *testDecisionSink) AddAuthzDecision(decision AuthzDecision) error {
+	s.decisions = append(s.decisions, decision)
+	return nil
+}
+
+func TestLogDecision(t *testing.T) {
+	allowStatement := Statement{
+		Effect: "allow",
+		Actions: []string{
+			GROUP_ACTION_GET_GROUP,
+		},
+		Resources: []string{
+			GetUrnPrefix("example", RESOURCE_GROUP, "/path/"),
+		},
+	}
+	otherStatement := Statement{
+		Effect: "allow",
+		Actions: []string{
+			GROUP_ACTION_GET_GROUP,
+		},
+		Resources: []string{
+			GetUrnPrefix("other", RESOURCE_GROUP, "/"),
+		},
+	}
+	groups := []Resource{
+		Group{
+			ID:  "GROUP1",
+			Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+		},
+		Group{
+			ID:  "GROUP2",
+			Urn: CreateUrn("example", RESOURCE_GROUP, "/other/", "group2"),
+		},
+	}
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// Resource urn that user wants to access
+		resourceUrn string
+		// Decision log sampling
+		sampling float64
+		// Expected decisions, without ID, latency and creation time
+		expectedDecisions []AuthzDecision
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		// Manager Errors
+		getUserByExternalIDError error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+			sampling:    1,
+			expectedDecisions: []AuthzDecision{
+				{
+					RequestID:   "REQUEST-ID",
+					User:        "admin",
+					Admin:       true,
+					Action:      GROUP_ACTION_GET_GROUP,
+					ResourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+					Requested:   []string{groups[0].GetUrn(), groups[1].GetUrn()},
+					Allowed:     []string{groups[0].GetUrn(), groups[1].GetUrn()},
+				},
+			},
+		},
+		"OkCaseAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+			sampling:    1,
+			expectedDecisions: []AuthzDecision{
+				{
+					RequestID:   "REQUEST-ID",
+					User:        "123456",
+					Action:      GROUP_ACTION_GET_GROUP,
+					ResourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+					Requested:   []string{groups[0].GetUrn(), groups[1].GetUrn()},
+					Allowed:     []string{groups[0].GetUrn()},
+					Statements:  []Statement{allowStatement},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-ID",
+					Name: "group",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:         "POLICY-ID",
+						Name:       "policy",
+						Statements: &[]Statement{allowStatement, otherStatement},
+					},
+				},
+			},
+		},
+		"OkCaseDenied": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+			sampling:    1,
+			expectedDecisions: []AuthzDecision{
+				{
+					RequestID:   "REQUEST-ID",
+					User:        "123456",
+					Action:      GROUP_ACTION_GET_GROUP,
+					ResourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+					Requested:   []string{groups[0].GetUrn(), groups[1].GetUrn()},
+					Allowed:     []string{},
+					Statements:  []Statement{},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+		},
+		"OkCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+			sampling:    1,
+			expectedDecisions: []AuthzDecision{
+				{
+					RequestID:   "REQUEST-ID",
+					User:        "123456",
+					Action:      GROUP_ACTION_GET_GROUP,
+					ResourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+					Requested:   []string{groups[0].GetUrn(), groups[1].GetUrn()},
+					Allowed:     []string{},
+				},
+			},
+			getUserByExternalIDError: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"OkCaseNotSampled": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/"),
+			sampling:    0,
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		sink := &testDecisionSink{}
+		testAPI.DecisionLog = &DecisionLog{
+			Sinks:    []DecisionSink{sink},
+			Sampling: test.sampling,
+		}
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+
+		testAPI.getAuthorizedResources(test.requestInfo, test.resourceUrn, GROUP_ACTION_GET_GROUP, groups)
+
+		for i := range sink.decisions {
+			if sink.decisions[i].ID == "" || sink.decisions[i].CreateAt.IsZero() {
+				t.Errorf("Test %v failed. Decision without ID or creation time: %v", n, sink.decisions[i])
+			}
+			sink.decisions[i].ID = ""
+			sink.decisions[i].Latency = 0
+			sink.decisions[i].CreateAt = time.Time{}
+		}
+		if diff := pretty.Compare(sink.decisions, test.expectedDecisions); diff != "" {
+			t.Errorf("Test %v failed. Received different decisions (received/wanted) %v", n, diff)
+		}
+	}
+}
@@ -11,6 +11,7 @@ const (
 	UNKNOWN_API_ERROR            = "UnknownApiError"
 	INVALID_PARAMETER_ERROR      = "InvalidParameterError"
 	UNAUTHORIZED_RESOURCES_ERROR = "UnauthorizedResourcesError"
+	VERSION_MISMATCH_ERROR       = "VersionMismatchError"
 
 	// User API error codes
 	USER_BY_EXTERNAL_ID_NOT_FOUND = "UserWithExternalIDNotFound"
@@ -32,6 +33,35 @@ const (
 	POLICY_ALREADY_EXIST             = "PolicyAlreadyExist"
 	POLICY_BY_ORG_AND_NAME_NOT_FOUND = "PolicyWithOrgAndNameNotFound"
 
+	// Organization API error codes
+	ORGANIZATION_ALREADY_EXIST     = "OrganizationAlreadyExist"
+	ORGANIZATION_BY_NAME_NOT_FOUND = "OrganizationWithNameNotFound"
+
+	// Access request API error codes
+	ACCESS_REQUEST_BY_ID_NOT_FOUND  = "AccessRequestWithIDNotFound"
+	ACCESS_REQUEST_ALREADY_EXIST    = "AccessRequestAlreadyExist"
+	ACCESS_REQUEST_ALREADY_REVIEWED = "AccessRequestAlreadyReviewed"
+
+	// Webhook API error codes
+	WEBHOOK_BY_ID_NOT_FOUND = "WebhookWithIDNotFound"
+
+	// Api key API error codes
+	API_KEY_BY_ID_NOT_FOUND = "ApiKeyWithIDNotFound"
+	INVALID_API_KEY         = "InvalidApiKey"
+
+	// Admin API error codes
+	ADMIN_BY_EXTERNAL_ID_NOT_FOUND = "AdminWithExternalIDNotFound"
+	INVALID_ADMIN_CREDENTIALS      = "InvalidAdminCredentials"
+
+	// Password API error codes
+	INVALID_USER_CREDENTIALS = "InvalidUserCredentials"
+	USER_LOCKED_OUT          = "UserLockedOut"
+	INVALID_SESSION_TOKEN    = "InvalidSessionToken"
+
+	// Group mapping API error codes
+	GROUP_MAPPING_BY_ID_NOT_FOUND = "GroupMappingWithIDNotFound"
+	GROUP_MAPPING_ALREADY_EXIST   = "GroupMappingAlreadyExist"
+
 	// Regex error
 	REGEX_NO_MATCH = "RegexNoMatch"
 )
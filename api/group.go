@@ -12,17 +12,24 @@ import (
 
 // Group domain
 type Group struct {
-	ID       string    `json:"id, omitempty"`
-	Name     string    `json:"name, omitempty"`
-	Path     string    `json:"path, omitempty"`
-	Org      string    `json:"org, omitempty"`
-	Urn      string    `json:"urn, omitempty"`
-	CreateAt time.Time `json:"createAt, omitempty"`
+	ID          string    `json:"id, omitempty"`
+	Name        string    `json:"name, omitempty"`
+	Path        string    `json:"path, omitempty"`
+	Org         string    `json:"org, omitempty"`
+	DisplayName string    `json:"displayName, omitempty"`
+	Description string    `json:"description, omitempty"`
+	Urn         string    `json:"urn, omitempty"`
+	CreateAt    time.Time `json:"createAt, omitempty"`
+	UpdateAt    time.Time `json:"updatedAt, omitempty"`
+	CreatedBy   string    `json:"createdBy, omitempty"`
+	UpdatedBy   string    `json:"updatedBy, omitempty"`
+	Version     int64     `json:"version, omitempty"`
 }
 
 func (g Group) String() string {
-	return fmt.Sprintf("[id: %v, name: %v, path: %v, org: %v, urn: %v, createAt: %v]",
-		g.ID, g.Name, g.Path, g.Org, g.Urn, g.CreateAt.Format("2006-01-02 15:04:05 MST"))
+	return fmt.Sprintf("[id: %v, name: %v, path: %v, org: %v, displayName: %v, urn: %v, createAt: %v, updatedAt: %v, version: %v]",
+		g.ID, g.Name, g.Path, g.Org, g.DisplayName, g.Urn, g.CreateAt.Format("2006-01-02 15:04:05 MST"),
+		g.UpdateAt.Format("2006-01-02 15:04:05 MST"), g.Version)
 }
 
 func (g Group) GetUrn() string {
@@ -39,9 +46,57 @@ type GroupMembers struct {
 	Users []User `json:"users, omitempty"`
 }
 
+// Member of a group. ExpireAt is nil if the membership never expires
+type GroupMember struct {
+	User     User
+	ExpireAt *time.Time
+}
+
+// Identifier of a group member and when its membership expires
+type GroupMemberIdentity struct {
+	User     string     `json:"user, omitempty"`
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+// Policy attached to a group. The attachment is only effective between NotBefore and NotAfter,
+// any of them is nil if the attachment isn't bounded on that side
+type GroupPolicy struct {
+	Policy    Policy
+	NotBefore *time.Time
+	NotAfter  *time.Time
+}
+
+// Identifier of a policy attached to a group and when the attachment is effective
+type GroupPolicyIdentity struct {
+	Policy    string     `json:"policy, omitempty"`
+	NotBefore *time.Time `json:"notBefore, omitempty"`
+	NotAfter  *time.Time `json:"notAfter, omitempty"`
+}
+
+// Membership of a user in a group removed because it expired
+type ExpiredMember struct {
+	User     string
+	Org      string
+	Group    string
+	ExpireAt time.Time
+}
+
+func (e ExpiredMember) String() string {
+	return fmt.Sprintf("[user: %v, org: %v, group: %v, expireAt: %v]",
+		e.User, e.Org, e.Group, e.ExpireAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// Result of an item in a bulk operation over group relationships
+type BulkItemResult struct {
+	Item  string `json:"item, omitempty"`
+	Done  bool   `json:"done"`
+	Error *Error `json:"error, omitempty"`
+}
+
 // GROUP API IMPLEMENTATION
 
-func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, path string) (*Group, error) {
+func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, path string, displayName string,
+	description string) (*Group, error) {
 	// Validate fields
 	if !IsValidName(name) {
 		return nil, &Error{
@@ -61,8 +116,15 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 			Message: fmt.Sprintf("Invalid parameter: path %v", path),
 		}
 	}
+	if err := AreValidDescriptiveAttributes(displayName, description); err != nil {
+		return nil, err
+	}
 
 	group := createGroup(org, name, path)
+	group.DisplayName = displayName
+	group.Description = description
+	group.CreatedBy = requestInfo.Identifier
+	group.UpdatedBy = requestInfo.Identifier
 
 	// Check restrictions
 	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_CREATE_GROUP, []Group{group})
@@ -87,6 +149,11 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 		switch dbError.Code {
 		// Group doesn't exist in DB, so we can create it
 		case database.GROUP_NOT_FOUND:
+			// Check if group is deleted but not purged yet
+			if err := api.checkDeletedGroup(org, name); err != nil {
+				return nil, err
+			}
+
 			// Create group
 			createdGroup, err := api.GroupRepo.AddGroup(group)
 
@@ -99,6 +166,9 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 					Message: dbError.Message,
 				}
 			}
+			if err := api.recordChange(requestInfo, GROUP_ACTION_CREATE_GROUP, createdGroup.Urn, nil, createdGroup); err != nil {
+				return nil, err
+			}
 			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group created %+v", createdGroup))
 			return createdGroup, nil
 		default: // Unexpected error
@@ -229,7 +299,8 @@ func (api AuthAPI) ListGroups(requestInfo RequestInfo, org string, pathPrefix st
 	return groupIDs, nil
 }
 
-func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string, newName string, newPath string) (*Group, error) {
+func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string, newName string, newPath string,
+	newDisplayName string, newDescription string, version int64) (*Group, error) {
 	// Validate fields
 	if !IsValidName(newName) {
 		return nil, &Error{
@@ -243,6 +314,9 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 			Message: fmt.Sprintf("Invalid parameter: new path %v", newPath),
 		}
 	}
+	if err := AreValidDescriptiveAttributes(newDisplayName, newDescription); err != nil {
+		return nil, err
+	}
 
 	// Call repo to retrieve the group
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -264,6 +338,15 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 		}
 	}
 
+	// Check version precondition
+	if !IsExpectedVersion(version, group.Version) {
+		return nil, &Error{
+			Code: VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("Group with organization %v and name %v is in version %v, not in expected version %v",
+				org, name, group.Version, version),
+		}
+	}
+
 	// Check if a group with "newName" already exists
 	newGroup, err := api.GetGroupByName(requestInfo, org, newName)
 
@@ -298,24 +381,36 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 	}
 
 	// Update group
-	group, err = api.GroupRepo.UpdateGroup(*group, newName, newPath, groupToUpdate.Urn)
+	group, err = api.GroupRepo.UpdateGroup(*group, newName, newPath, groupToUpdate.Urn, newDisplayName, newDescription,
+		requestInfo.Identifier)
 
-	// Check unexpected DB error
+	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return nil, &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
 
+	if err := api.recordChange(requestInfo, GROUP_ACTION_UPDATE_GROUP, group.Urn, oldGroup, group); err != nil {
+		return nil, err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group updated from %+v to %+v", oldGroup, group))
 	return group, nil
 
 }
 
-func (api AuthAPI) RemoveGroup(requestInfo RequestInfo, org string, name string) error {
+func (api AuthAPI) RemoveGroup(requestInfo RequestInfo, org string, name string, version int64) error {
 
 	// Call repo to retrieve the group
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -336,24 +431,188 @@ func (api AuthAPI) RemoveGroup(requestInfo RequestInfo, org string, name string)
 		}
 	}
 
+	// Check version precondition
+	if !IsExpectedVersion(version, group.Version) {
+		return &Error{
+			Code: VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("Group with organization %v and name %v is in version %v, not in expected version %v",
+				org, name, group.Version, version),
+		}
+	}
+
 	// Remove group with given org and name
-	err = api.GroupRepo.RemoveGroup(group.ID)
+	err = api.GroupRepo.RemoveGroup(group.ID, group.Version)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
 
+	if err := api.recordChange(requestInfo, GROUP_ACTION_DELETE_GROUP, group.Urn, group, nil); err != nil {
+		return err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group deleted %+v", group))
 	return nil
 }
 
-func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name string, org string) error {
+func (api AuthAPI) ListDeletedGroups(requestInfo RequestInfo, org string, pathPrefix string) ([]GroupIdentity, error) {
+	// Validate fields
+	if len(org) > 0 && !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
+		}
+	}
+
+	if len(pathPrefix) == 0 {
+		pathPrefix = "/"
+	}
+
+	// Call repo to retrieve the deleted groups
+	groups, err := api.GroupRepo.GetDeletedGroups(org, pathPrefix)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions to list
+	var urnPrefix string
+	if len(org) == 0 {
+		urnPrefix = "*"
+	} else {
+		urnPrefix = GetUrnPrefix(org, RESOURCE_GROUP, pathPrefix)
+	}
+	filteredGroups, err := api.GetAuthorizedGroups(requestInfo, urnPrefix, GROUP_ACTION_LIST_DELETED_GROUPS, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transform to identifiers
+	groupIDs := []GroupIdentity{}
+	for _, g := range filteredGroups {
+		groupIDs = append(groupIDs, GroupIdentity{
+			Org:  g.Org,
+			Name: g.Name,
+		})
+	}
+
+	return groupIDs, nil
+}
+
+func (api AuthAPI) RestoreGroup(requestInfo RequestInfo, org string, name string) (*Group, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Call repo to retrieve the deleted group
+	group, err := api.GroupRepo.GetDeletedGroupByName(org, name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.GROUP_NOT_FOUND:
+			return nil, &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_RESTORE_GROUP, []Group{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Check that no other group took its name meanwhile
+	_, err = api.GroupRepo.GetGroupByName(org, name)
+	if err == nil {
+		return nil, &Error{
+			Code:    GROUP_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to restore group, group with org %v and name %v already exists", org, name),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.GROUP_NOT_FOUND {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Restore group with its relationships
+	if err := api.GroupRepo.RestoreGroup(group.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_RESTORE_GROUP, group.Urn, nil, group); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group restored %+v", group))
+	return group, nil
+}
+
+func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name string, org string, expireAt *time.Time) error {
+	// Validate fields
+	if expireAt != nil && !expireAt.After(time.Now().UTC()) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: expireAt %v, it must be in the future", expireAt.UTC().Format(time.RFC3339)),
+		}
+	}
 
 	// Call repo to retrieve the group
 	groupDB, err := api.GetGroupByName(requestInfo, org, name)
@@ -400,7 +659,7 @@ func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name st
 	}
 
 	// Add Member
-	err = api.GroupRepo.AddMember(userDB.ID, groupDB.ID)
+	err = api.GroupRepo.AddMember(userDB.ID, groupDB.ID, expireAt)
 
 	// Check if there is an unexpected error in DB
 	if err != nil {
@@ -411,6 +670,15 @@ func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name st
 			Message: dbError.Message,
 		}
 	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_ADD_MEMBER, groupDB.Urn, nil, GroupMemberIdentity{User: userDB.ExternalID, ExpireAt: expireAt}); err != nil {
+		return err
+	}
+	if expireAt != nil {
+		LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v added to group %+v until %v", userDB, groupDB,
+			expireAt.UTC().Format("2006-01-02 15:04:05 MST")))
+		return nil
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v added to group %+v", userDB, groupDB))
 	return nil
 }
@@ -474,11 +742,14 @@ func (api AuthAPI) RemoveMember(requestInfo RequestInfo, externalId string, name
 		}
 	}
 
+	if err := api.recordChange(requestInfo, GROUP_ACTION_REMOVE_MEMBER, groupDB.Urn, GroupMemberIdentity{User: userDB.ExternalID}, nil); err != nil {
+		return err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v removed from group %+v", userDB, groupDB))
 	return nil
 }
 
-func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string) ([]string, error) {
+func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string) ([]GroupMemberIdentity, error) {
 
 	// Call repo to retrieve the group
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -512,15 +783,33 @@ func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string)
 		}
 	}
 
-	externalIDs := []string{}
+	memberIdentities := []GroupMemberIdentity{}
 	for _, m := range members {
-		externalIDs = append(externalIDs, m.ExternalID)
+		memberIdentities = append(memberIdentities, GroupMemberIdentity{
+			User:     m.User.ExternalID,
+			ExpireAt: m.ExpireAt,
+		})
 	}
 
-	return externalIDs, nil
+	return memberIdentities, nil
 }
 
-func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) error {
+func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string,
+	notBefore *time.Time, notAfter *time.Time) error {
+	// Validate fields
+	if notAfter != nil && !notAfter.After(time.Now().UTC()) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: notAfter %v, it must be in the future", notAfter.UTC().Format(time.RFC3339)),
+		}
+	}
+	if notBefore != nil && notAfter != nil && !notAfter.After(*notBefore) {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: notAfter %v, it must be after notBefore %v",
+				notAfter.UTC().Format(time.RFC3339), notBefore.UTC().Format(time.RFC3339)),
+		}
+	}
 
 	// Check if group exists
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -566,7 +855,7 @@ func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name
 	}
 
 	// Attach Policy to Group
-	err = api.GroupRepo.AttachPolicy(group.ID, policy.ID)
+	err = api.GroupRepo.AttachPolicy(group.ID, policy.ID, notBefore, notAfter)
 
 	if err != nil {
 		dbError := err.(*database.Error)
@@ -576,7 +865,11 @@ func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name
 		}
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v attached to group %+v", policy, group))
+	if err := api.recordChange(requestInfo, GROUP_ACTION_ATTACH_GROUP_POLICY, group.Urn, nil, GroupPolicyIdentity{Policy: policy.Name, NotBefore: notBefore, NotAfter: notAfter}); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v attached to group %+v%v", policy, group,
+		attachmentWindowToString(notBefore, notAfter)))
 	return nil
 }
 
@@ -637,11 +930,14 @@ func (api AuthAPI) DetachPolicyToGroup(requestInfo RequestInfo, org string, name
 		}
 	}
 
+	if err := api.recordChange(requestInfo, GROUP_ACTION_DETACH_GROUP_POLICY, group.Urn, GroupPolicyIdentity{Policy: policy.Name}, nil); err != nil {
+		return err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v detached from group %+v", policy, group))
 	return nil
 }
 
-func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string, name string) ([]string, error) {
+func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string, name string) ([]GroupPolicyIdentity, error) {
 
 	// Check if group exists
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -675,25 +971,369 @@ func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string
 		}
 	}
 
-	policyIDs := []string{}
+	policyIDs := []GroupPolicyIdentity{}
 	for _, p := range attachedPolicies {
-		policyIDs = append(policyIDs, p.Name)
+		policyIDs = append(policyIDs, GroupPolicyIdentity{
+			Policy:    p.Policy.Name,
+			NotBefore: p.NotBefore,
+			NotAfter:  p.NotAfter,
+		})
 	}
 	return policyIDs, nil
 }
 
+func (api AuthAPI) AddMembers(requestInfo RequestInfo, org string, name string, externalIds []string) ([]BulkItemResult, error) {
+	// Check if group exists and user is allowed to add members
+	groupDB, err := api.getGroupForBulkOperation(requestInfo, org, name, GROUP_ACTION_ADD_MEMBER)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runBulkOperation(externalIds,
+		func(externalId string) (string, error) {
+			// Call repo to retrieve the user
+			userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+			if err != nil {
+				return "", err
+			}
+
+			// Call repo to retrieve the GroupUserRelation
+			isMember, err := api.GroupRepo.IsMemberOfGroup(userDB.ID, groupDB.ID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return "", &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			if isMember {
+				return "", &Error{
+					Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+					Message: fmt.Sprintf("User: %v is already a member of Group: %v", externalId, name),
+				}
+			}
+
+			return userDB.ID, nil
+		},
+		func(userIDs []string) error {
+			return api.GroupRepo.AddMembers(userIDs, groupDB.ID)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_ADD_MEMBER, groupDB.Urn, nil, results); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Members %v added to group %+v", doneItems(results), groupDB))
+	return results, nil
+}
+
+func (api AuthAPI) RemoveMembers(requestInfo RequestInfo, org string, name string, externalIds []string) ([]BulkItemResult, error) {
+	// Check if group exists and user is allowed to remove members
+	groupDB, err := api.getGroupForBulkOperation(requestInfo, org, name, GROUP_ACTION_REMOVE_MEMBER)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runBulkOperation(externalIds,
+		func(externalId string) (string, error) {
+			// Call repo to retrieve the user
+			userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+			if err != nil {
+				return "", err
+			}
+
+			// Call repo to check if user is a member of group
+			isMember, err := api.GroupRepo.IsMemberOfGroup(userDB.ID, groupDB.ID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return "", &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			if !isMember {
+				return "", &Error{
+					Code: USER_IS_NOT_A_MEMBER_OF_GROUP,
+					Message: fmt.Sprintf("User with externalId %v is not a member of group with org %v and name %v",
+						userDB.ExternalID, groupDB.Org, groupDB.Name),
+				}
+			}
+
+			return userDB.ID, nil
+		},
+		func(userIDs []string) error {
+			return api.GroupRepo.RemoveMembers(userIDs, groupDB.ID)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_REMOVE_MEMBER, groupDB.Urn, results, nil); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Members %v removed from group %+v", doneItems(results), groupDB))
+	return results, nil
+}
+
+func (api AuthAPI) AttachPoliciesToGroup(requestInfo RequestInfo, org string, name string, policyNames []string) ([]BulkItemResult, error) {
+	// Check if group exists and user is allowed to attach policies
+	group, err := api.getGroupForBulkOperation(requestInfo, org, name, GROUP_ACTION_ATTACH_GROUP_POLICY)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runBulkOperation(policyNames,
+		func(policyName string) (string, error) {
+			// Check if policy exists
+			policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+			if err != nil {
+				return "", err
+			}
+
+			// Check existing relationship
+			isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
+			if err != nil {
+				dbError := err.(*database.Error)
+				return "", &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			if isAttached {
+				return "", &Error{
+					Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+					Message: fmt.Sprintf("Policy: %v is already attached to Group: %v", policy.Name, group.Name),
+				}
+			}
+
+			return policy.ID, nil
+		},
+		func(policyIDs []string) error {
+			return api.GroupRepo.AttachPolicies(group.ID, policyIDs)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_ATTACH_GROUP_POLICY, group.Urn, nil, results); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policies %v attached to group %+v", doneItems(results), group))
+	return results, nil
+}
+
+func (api AuthAPI) DetachPoliciesToGroup(requestInfo RequestInfo, org string, name string, policyNames []string) ([]BulkItemResult, error) {
+	// Check if group exists and user is allowed to detach policies
+	group, err := api.getGroupForBulkOperation(requestInfo, org, name, GROUP_ACTION_DETACH_GROUP_POLICY)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runBulkOperation(policyNames,
+		func(policyName string) (string, error) {
+			// Check if policy exists
+			policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+			if err != nil {
+				return "", err
+			}
+
+			// Check existing relationship
+			isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
+			if err != nil {
+				dbError := err.(*database.Error)
+				return "", &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			if !isAttached {
+				return "", &Error{
+					Code: POLICY_IS_NOT_ATTACHED_TO_GROUP,
+					Message: fmt.Sprintf("Policy with org %v and name %v is not attached to group with org %v and name %v",
+						policy.Org, policy.Name, group.Org, group.Name),
+				}
+			}
+
+			return policy.ID, nil
+		},
+		func(policyIDs []string) error {
+			return api.GroupRepo.DetachPolicies(group.ID, policyIDs)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_ACTION_DETACH_GROUP_POLICY, group.Urn, results, nil); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policies %v detached from group %+v", doneItems(results), group))
+	return results, nil
+}
+
+// Remove memberships that expired before expiredBefore, logging each one of them.
+// It isn't exposed in any API because it's executed periodically by the worker, not by users.
+func (api AuthAPI) RemoveExpiredMembers(expiredBefore time.Time) error {
+	expiredMembers, err := api.GroupRepo.RemoveExpiredMembers(expiredBefore)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	for _, expiredMember := range expiredMembers {
+		api.Logger.Infof("Expired member removed %v", expiredMember)
+	}
+	return nil
+}
+
 // PRIVATE HELPER METHODS
 
+// Retrieve group checking that requester is allowed to do the given action over it
+func (api AuthAPI) getGroupForBulkOperation(requestInfo RequestInfo, org string, name string, action string) (*Group, error) {
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, action, []Group{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	return group, nil
+}
+
+// Deleted groups keep their name until they are purged, so it can't be reused before
+func (api AuthAPI) checkDeletedGroup(org string, name string) error {
+	_, err := api.GroupRepo.GetDeletedGroupByName(org, name)
+	if err == nil {
+		return &Error{
+			Code: GROUP_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create group, group with org %v and name %v is deleted and must be restored or purged first",
+				org, name),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.GROUP_NOT_FOUND {
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return nil
+}
+
 func createGroup(org string, name string, path string) Group {
 	urn := CreateUrn(org, RESOURCE_GROUP, path, name)
+	now := time.Now().UTC()
 	group := Group{
 		ID:       uuid.NewV4().String(),
 		Name:     name,
 		Path:     path,
-		CreateAt: time.Now().UTC(),
+		CreateAt: now,
+		UpdateAt: now,
 		Urn:      urn,
 		Org:      org,
 	}
 
 	return group
 }
+
+// Run a bulk operation over items. Function check validates an item and returns the ID of its entity,
+// items that fail are reported in its result unless the error is unexpected, which aborts the operation.
+// Function apply receives the IDs of all valid items and must store them at once.
+func runBulkOperation(items []string, check func(item string) (string, error),
+	apply func(ids []string) error) ([]BulkItemResult, error) {
+	if len(items) < 1 || len(items) > MAX_BULK_ITEMS {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: number of items %v, it must be between 1 and %v", len(items), MAX_BULK_ITEMS),
+		}
+	}
+
+	results := make([]BulkItemResult, len(items))
+	ids := []string{}
+	pending := []int{}
+	processed := map[string]bool{}
+	for i, item := range items {
+		results[i].Item = item
+		if processed[item] {
+			results[i].Error = &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: item %v is duplicated", item),
+			}
+			continue
+		}
+		processed[item] = true
+
+		id, err := check(item)
+		if err != nil {
+			apiError := err.(*Error)
+			if apiError.Code == UNKNOWN_API_ERROR {
+				return nil, apiError
+			}
+			results[i].Error = apiError
+			continue
+		}
+		ids = append(ids, id)
+		pending = append(pending, i)
+	}
+
+	if len(ids) > 0 {
+		if err := apply(ids); err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	for _, i := range pending {
+		results[i].Done = true
+	}
+
+	return results, nil
+}
+
+// Describe the window in which a policy attachment is effective, empty if it isn't bounded
+func attachmentWindowToString(notBefore *time.Time, notAfter *time.Time) string {
+	window := ""
+	if notBefore != nil {
+		window += fmt.Sprintf(" from %v", notBefore.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+	if notAfter != nil {
+		window += fmt.Sprintf(" until %v", notAfter.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+	return window
+}
+
+// Retrieve items done in a bulk operation
+func doneItems(results []BulkItemResult) []string {
+	items := []string{}
+	for _, result := range results {
+		if result.Done {
+			items = append(items, result.Item)
+		}
+	}
+	return items
+}
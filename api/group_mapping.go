@@ -0,0 +1,407 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Mapping of a claim value of tokens of an identity provider to a group. Users whose tokens of the issuer have
+// the value in the claim are members of the group, and members of mapped groups without the value are removed.
+type GroupMapping struct {
+	ID        string    `json:"id, omitempty"`
+	Issuer    string    `json:"issuer, omitempty"`
+	Claim     string    `json:"claim, omitempty"`
+	Value     string    `json:"value, omitempty"`
+	Org       string    `json:"org, omitempty"`
+	Group     string    `json:"group, omitempty"`
+	Urn       string    `json:"urn, omitempty"`
+	CreateAt  time.Time `json:"createAt, omitempty"`
+	CreatedBy string    `json:"createdBy, omitempty"`
+}
+
+func (m GroupMapping) String() string {
+	return fmt.Sprintf("[id: %v, issuer: %v, claim: %v, value: %v, org: %v, group: %v, urn: %v, createAt: %v, createdBy: %v]",
+		m.ID, m.Issuer, m.Claim, m.Value, m.Org, m.Group, m.Urn, m.CreateAt.Format("2006-01-02 15:04:05 MST"), m.CreatedBy)
+}
+
+func (m GroupMapping) GetUrn() string {
+	return m.Urn
+}
+
+// GROUP MAPPING API IMPLEMENTATION
+
+func (api AuthAPI) AddGroupMapping(requestInfo RequestInfo, issuer string, claim string, value string, org string,
+	groupName string) (*GroupMapping, error) {
+	// Validate fields
+	if err := validateGroupMapping(issuer, claim, value, org, groupName); err != nil {
+		return nil, err
+	}
+
+	mapping := createGroupMapping(issuer, claim, value, org, groupName)
+	mapping.CreatedBy = requestInfo.Identifier
+
+	// Check restrictions
+	mappingsFiltered, err := api.getAuthorizedGroupMappings(requestInfo, mapping.Urn, GROUP_MAPPING_ACTION_CREATE_GROUP_MAPPING,
+		[]GroupMapping{mapping})
+	if err != nil {
+		return nil, err
+	}
+	if len(mappingsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, mapping.Urn),
+		}
+	}
+
+	// Mapping adds members to the group, so user must be allowed to do it
+	if _, err := api.getGroupForBulkOperation(requestInfo, org, groupName, GROUP_ACTION_ADD_MEMBER); err != nil {
+		return nil, err
+	}
+
+	// Check if mapping already exists
+	mappings, err := api.GroupMappingRepo.GetGroupMappings(org)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	for _, m := range mappings {
+		if m.Issuer == issuer && m.Claim == claim && m.Value == value && m.Group == groupName {
+			return nil, &Error{
+				Code: GROUP_MAPPING_ALREADY_EXIST,
+				Message: fmt.Sprintf("Unable to create group mapping, claim %v with value %v of issuer %v is already mapped to group with org %v and name %v",
+					claim, value, issuer, org, groupName),
+			}
+		}
+	}
+
+	// Create group mapping
+	createdMapping, err := api.GroupMappingRepo.AddGroupMapping(mapping)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_MAPPING_ACTION_CREATE_GROUP_MAPPING, createdMapping.Urn, nil, createdMapping); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group mapping created %+v", createdMapping))
+	return createdMapping, nil
+}
+
+func (api AuthAPI) GetGroupMappingByID(requestInfo RequestInfo, id string) (*GroupMapping, error) {
+	return api.getGroupMappingForAction(requestInfo, id, GROUP_MAPPING_ACTION_GET_GROUP_MAPPING)
+}
+
+func (api AuthAPI) ListGroupMappings(requestInfo RequestInfo, org string) ([]GroupMapping, error) {
+	// Validate fields
+	if len(org) > 0 && !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Call repo to retrieve the group mappings
+	mappings, err := api.GroupMappingRepo.GetGroupMappings(org)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions to list
+	var urnPrefix string
+	if len(org) == 0 {
+		urnPrefix = "*"
+	} else {
+		urnPrefix = GetUrnPrefix(org, RESOURCE_GROUP_MAPPING, "/")
+	}
+	return api.getAuthorizedGroupMappings(requestInfo, urnPrefix, GROUP_MAPPING_ACTION_LIST_GROUP_MAPPINGS, mappings)
+}
+
+func (api AuthAPI) RemoveGroupMapping(requestInfo RequestInfo, id string) error {
+	mapping, err := api.getGroupMappingForAction(requestInfo, id, GROUP_MAPPING_ACTION_DELETE_GROUP_MAPPING)
+	if err != nil {
+		return err
+	}
+
+	// Remove group mapping, members added by it are kept until they're removed explicitly
+	if err := api.GroupMappingRepo.RemoveGroupMapping(mapping.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, GROUP_MAPPING_ACTION_DELETE_GROUP_MAPPING, mapping.Urn, mapping, nil); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group mapping deleted %+v", mapping))
+	return nil
+}
+
+func (api AuthAPI) ApplyGroupMappings(requestID string, externalId string, issuer string, claims map[string][]string) error {
+	mappings, err := api.GroupMappingRepo.GetGroupMappings("")
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Groups of mappings of the issuer, true if user has to be a member. Claims of other issuers aren't trusted.
+	mappedGroups := map[GroupIdentity]bool{}
+	for _, mapping := range mappings {
+		if mapping.Issuer != issuer {
+			continue
+		}
+		group := GroupIdentity{Org: mapping.Org, Name: mapping.Group}
+		mappedGroups[group] = mappedGroups[group] || isClaimValueContained(mapping.Value, claims[mapping.Claim])
+	}
+	if len(mappedGroups) < 1 {
+		return nil
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.UserRepo.GetUserByExternalID(externalId)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Call repo to retrieve the current groups of user
+	groups, err := api.UserRepo.GetGroupsByUserID(user.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	memberOf := map[GroupIdentity]bool{}
+	for _, group := range groups {
+		memberOf[GroupIdentity{Org: group.Org, Name: group.Name}] = true
+	}
+
+	// Call repo to retrieve the groups whose membership was added by group mappings, only these are removed
+	mappedGroupsOfUser, err := api.GroupRepo.GetMappedGroupsByUserID(user.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	mappedMemberOf := map[GroupIdentity]Group{}
+	for _, group := range mappedGroupsOfUser {
+		mappedMemberOf[GroupIdentity{Org: group.Org, Name: group.Name}] = group
+	}
+
+	// Memberships are changed by the user itself without authorization
+	requestInfo := RequestInfo{
+		Identifier: externalId,
+		Admin:      true,
+		RequestID:  requestID,
+	}
+	for groupIdentity, member := range mappedGroups {
+		group, isMappedMember := mappedMemberOf[groupIdentity]
+		switch {
+		case member && !memberOf[groupIdentity]:
+			groupDB, err := api.GroupRepo.GetGroupByName(groupIdentity.Org, groupIdentity.Name)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				if dbError.Code == database.GROUP_NOT_FOUND {
+					// Mapping of a group that doesn't exist anymore
+					api.Logger.Warnf("Group with org %v and name %v of group mappings not found", groupIdentity.Org, groupIdentity.Name)
+					continue
+				}
+				return &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			if err := api.GroupRepo.AddMappedMember(user.ID, groupDB.ID); err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			if err := api.recordChange(requestInfo, GROUP_ACTION_ADD_MEMBER, groupDB.Urn, nil, GroupMemberIdentity{User: user.ExternalID}); err != nil {
+				return err
+			}
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v added to group %+v by group mappings", user, groupDB))
+		case !member && isMappedMember:
+			if err := api.GroupRepo.RemoveMember(user.ID, group.ID); err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			if err := api.recordChange(requestInfo, GROUP_ACTION_REMOVE_MEMBER, group.Urn, GroupMemberIdentity{User: user.ExternalID}, nil); err != nil {
+				return err
+			}
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v removed from group %+v by group mappings", user, group))
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve group mapping checking that user is allowed to do the given action over it
+func (api AuthAPI) getGroupMappingForAction(requestInfo RequestInfo, id string, action string) (*GroupMapping, error) {
+	// Call repo to retrieve the group mapping
+	mapping, err := api.GroupMappingRepo.GetGroupMappingByID(id)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.GROUP_MAPPING_NOT_FOUND:
+			return nil, &Error{
+				Code:    GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	mappingsFiltered, err := api.getAuthorizedGroupMappings(requestInfo, mapping.Urn, action, []GroupMapping{*mapping})
+	if err != nil {
+		return nil, err
+	}
+	if len(mappingsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, mapping.Urn),
+		}
+	}
+
+	return mapping, nil
+}
+
+// Return authorized group mappings for specified resource+action
+func (api AuthAPI) getAuthorizedGroupMappings(requestInfo RequestInfo, resourceUrn string, action string,
+	mappings []GroupMapping) ([]GroupMapping, error) {
+	resourcesToAuthorize := []Resource{}
+	for _, mapping := range mappings {
+		resourcesToAuthorize = append(resourcesToAuthorize, mapping)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, resourceUrn, action, resourcesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	mappingsFiltered := []GroupMapping{}
+	for _, res := range resources {
+		mappingsFiltered = append(mappingsFiltered, res.(GroupMapping))
+	}
+	return mappingsFiltered, nil
+}
+
+// Validate fields of a group mapping
+func validateGroupMapping(issuer string, claim string, value string, org string, groupName string) error {
+	if len(issuer) < 1 || len(issuer) > MAX_URL_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: issuer length %v, it must be between 1 and %v",
+				len(issuer), MAX_URL_LENGTH),
+		}
+	}
+	if len(claim) < 1 || len(claim) > MAX_CLAIM_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: claim length %v, it must be between 1 and %v",
+				len(claim), MAX_CLAIM_LENGTH),
+		}
+	}
+	if len(value) < 1 || len(value) > MAX_CLAIM_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: value length %v, it must be between 1 and %v",
+				len(value), MAX_CLAIM_LENGTH),
+		}
+	}
+	if !IsValidOrg(org) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if !IsValidName(groupName) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: group %v", groupName),
+		}
+	}
+	return nil
+}
+
+// Returns true if a value is contained in the values of a claim
+func isClaimValueContained(value string, claimValues []string) bool {
+	for _, v := range claimValues {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func createGroupMapping(issuer string, claim string, value string, org string, groupName string) GroupMapping {
+	id := uuid.NewV4().String()
+	return GroupMapping{
+		ID:       id,
+		Issuer:   issuer,
+		Claim:    claim,
+		Value:    value,
+		Org:      org,
+		Group:    groupName,
+		Urn:      CreateUrn(org, RESOURCE_GROUP_MAPPING, "/", id),
+		CreateAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,666 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddGroupMapping(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		issuer      string
+		claim       string
+		value       string
+		org         string
+		group       string
+		// Expected result
+		expectedResponse *GroupMapping
+		wantError        error
+		// Manager Results
+		getGroupByNameResult   *Group
+		getGroupMappingsResult []GroupMapping
+		addGroupMappingResult  *GroupMapping
+		// Manager Errors
+		getGroupByNameMethodErr  error
+		addGroupMappingMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			claim:  "groups",
+			value:  "developers",
+			org:    "example",
+			group:  "group1",
+			expectedResponse: &GroupMapping{
+				ID:        "MAPPING-ID",
+				Issuer:    "https://issuer1",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "example",
+				Group:     "group1",
+				Urn:       CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "example",
+				Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			addGroupMappingResult: &GroupMapping{
+				ID:        "MAPPING-ID",
+				Issuer:    "https://issuer1",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "example",
+				Group:     "group1",
+				Urn:       CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+		},
+		"ErrorCaseEmptyClaim": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			value:  "developers",
+			org:    "example",
+			group:  "group1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: claim length 0, it must be between 1 and 256",
+			},
+		},
+		"ErrorCaseEmptyIssuer": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			claim: "groups",
+			value: "developers",
+			org:   "example",
+			group: "group1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: issuer length 0, it must be between 1 and 2048",
+			},
+		},
+		"ErrorCaseInvalidGroup": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			claim:  "groups",
+			value:  "developers",
+			org:    "example",
+			group:  "*group1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: group *group1",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			claim:  "groups",
+			value:  "developers",
+			org:    "example",
+			group:  "group1",
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseGroupMappingAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			claim:  "groups",
+			value:  "developers",
+			org:    "example",
+			group:  "group1",
+			wantError: &Error{
+				Code:    GROUP_MAPPING_ALREADY_EXIST,
+				Message: "Unable to create group mapping, claim groups with value developers of issuer https://issuer1 is already mapped to group with org example and name group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "example",
+				Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					ID:     "MAPPING-ID",
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+		},
+		"ErrorCaseAddGroupMappingDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			issuer: "https://issuer1",
+			claim:  "groups",
+			value:  "developers",
+			org:    "example",
+			group:  "group1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "example",
+				Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			addGroupMappingMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetGroupMappingsMethod][0] = testcase.getGroupMappingsResult
+		testRepo.ArgsOut[AddGroupMappingMethod][0] = testcase.addGroupMappingResult
+		testRepo.ArgsOut[AddGroupMappingMethod][1] = testcase.addGroupMappingMethodErr
+
+		mapping, err := testAPI.AddGroupMapping(testcase.requestInfo, testcase.issuer, testcase.claim, testcase.value,
+			testcase.org, testcase.group)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, mapping)
+
+		// Check stored group mapping
+		if testcase.wantError == nil {
+			stored := testRepo.ArgsIn[AddGroupMappingMethod][0].(GroupMapping)
+			if stored.CreatedBy != testcase.requestInfo.Identifier || stored.Issuer != testcase.issuer ||
+				stored.Urn != CreateUrn(testcase.org, RESOURCE_GROUP_MAPPING, "/", stored.ID) {
+				t.Errorf("Test %v failed. Received unexpected stored group mapping %v", x, stored)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_GetGroupMappingByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		// Expected result
+		expectedResponse *GroupMapping
+		wantError        error
+		// Manager Results
+		getGroupMappingByIDResult *GroupMapping
+		// Manager Errors
+		getGroupMappingByIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			expectedResponse: &GroupMapping{
+				ID:  "MAPPING-ID",
+				Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+			},
+			getGroupMappingByIDResult: &GroupMapping{
+				ID:  "MAPPING-ID",
+				Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+			},
+		},
+		"ErrorCaseGroupMappingNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			wantError: &Error{
+				Code:    GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping with id MAPPING-ID not found",
+			},
+			getGroupMappingByIDMethodErr: &database.Error{
+				Code:    database.GROUP_MAPPING_NOT_FOUND,
+				Message: "Group mapping with id MAPPING-ID not found",
+			},
+		},
+		"ErrorCaseGetGroupMappingDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupMappingByIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupMappingByIDMethod][0] = testcase.getGroupMappingByIDResult
+		testRepo.ArgsOut[GetGroupMappingByIDMethod][1] = testcase.getGroupMappingByIDMethodErr
+
+		mapping, err := testAPI.GetGroupMappingByID(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, mapping)
+	}
+}
+
+func TestAuthAPI_ListGroupMappings(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		// Expected result
+		expectedResponse []GroupMapping
+		wantError        error
+		// Manager Results
+		getGroupMappingsResult []GroupMapping
+		// Manager Errors
+		getGroupMappingsMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org: "example",
+			expectedResponse: []GroupMapping{
+				{
+					ID:  "MAPPING-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				},
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					ID:  "MAPPING-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				},
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org: "*org",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org *org",
+			},
+		},
+		"ErrorCaseGetGroupMappingsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupMappingsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupMappingsMethod][0] = testcase.getGroupMappingsResult
+		testRepo.ArgsOut[GetGroupMappingsMethod][1] = testcase.getGroupMappingsMethodErr
+
+		mappings, err := testAPI.ListGroupMappings(testcase.requestInfo, testcase.org)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, mappings)
+	}
+}
+
+func TestAuthAPI_RemoveGroupMapping(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		// Expected result
+		wantError error
+		// Manager Results
+		getGroupMappingByIDResult *GroupMapping
+		// Manager Errors
+		getGroupMappingByIDMethodErr error
+		removeGroupMappingMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			getGroupMappingByIDResult: &GroupMapping{
+				ID:  "MAPPING-ID",
+				Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+			},
+		},
+		"ErrorCaseGroupMappingNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			wantError: &Error{
+				Code:    GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping with id MAPPING-ID not found",
+			},
+			getGroupMappingByIDMethodErr: &database.Error{
+				Code:    database.GROUP_MAPPING_NOT_FOUND,
+				Message: "Group mapping with id MAPPING-ID not found",
+			},
+		},
+		"ErrorCaseRemoveGroupMappingDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "MAPPING-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupMappingByIDResult: &GroupMapping{
+				ID:  "MAPPING-ID",
+				Urn: CreateUrn("example", RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+			},
+			removeGroupMappingMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupMappingByIDMethod][0] = testcase.getGroupMappingByIDResult
+		testRepo.ArgsOut[GetGroupMappingByIDMethod][1] = testcase.getGroupMappingByIDMethodErr
+		testRepo.ArgsOut[RemoveGroupMappingMethod][0] = testcase.removeGroupMappingMethodErr
+
+		err := testAPI.RemoveGroupMapping(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_ApplyGroupMappings(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		issuer     string
+		claims     map[string][]string
+		// Expected result
+		wantError       error
+		expectedAdded   string
+		expectedRemoved string
+		// Manager Results
+		getGroupMappingsResult    []GroupMapping
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getMappedGroupsResult     []Group
+		getGroupByNameResult      *Group
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupByNameMethodErr      error
+	}{
+		"OkCaseAddMember": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			claims: map[string][]string{
+				"groups": {"developers", "testers"},
+			},
+			expectedAdded: "GROUP-ID",
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "example",
+			},
+		},
+		"OkCaseRemoveMember": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			claims: map[string][]string{
+				"groups": {"testers"},
+			},
+			expectedRemoved: "GROUP-ID",
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-ID",
+					Name: "group1",
+					Org:  "example",
+				},
+			},
+			getMappedGroupsResult: []Group{
+				{
+					ID:   "GROUP-ID",
+					Name: "group1",
+					Org:  "example",
+				},
+			},
+		},
+		"OkCaseMemberNotAddedByMappingsKept": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			claims: map[string][]string{
+				"groups": {"testers"},
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-ID",
+					Name: "group1",
+					Org:  "example",
+				},
+			},
+		},
+		"OkCaseAlreadyMember": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			claims: map[string][]string{
+				"groups": {"developers"},
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-ID",
+					Name: "group1",
+					Org:  "example",
+				},
+			},
+		},
+		"OkCaseMappedGroupNotFound": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			claims: map[string][]string{
+				"groups": {"developers"},
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"OkCaseMappingOfOtherIssuer": {
+			// Token of issuer2 with the claim value mapped for issuer1 isn't mapped to the group
+			externalID: "user1",
+			issuer:     "https://issuer2",
+			claims: map[string][]string{
+				"groups": {"developers"},
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "example",
+			},
+		},
+		"OkCaseWithoutMappings": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+		},
+		"ErrorCaseUserNotFound": {
+			externalID: "user1",
+			issuer:     "https://issuer1",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			getGroupMappingsResult: []GroupMapping{
+				{
+					Issuer: "https://issuer1",
+					Claim:  "groups",
+					Value:  "developers",
+					Org:    "example",
+					Group:  "group1",
+				},
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupMappingsMethod][0] = testcase.getGroupMappingsResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetMappedGroupsMethod][0] = testcase.getMappedGroupsResult
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+
+		err := testAPI.ApplyGroupMappings("request", testcase.externalID, testcase.issuer, testcase.claims)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+
+		// Check changed memberships
+		if added, _ := testRepo.ArgsIn[AddMappedMemberMethod][1].(string); added != testcase.expectedAdded {
+			t.Errorf("Test %v failed. Received different added group (received/wanted) %v/%v", x, added, testcase.expectedAdded)
+		}
+		if removed, _ := testRepo.ArgsIn[RemoveMemberMethod][1].(string); removed != testcase.expectedRemoved {
+			t.Errorf("Test %v failed. Received different removed group (received/wanted) %v/%v", x, removed, testcase.expectedRemoved)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/tecsisa/foulkon/database"
 )
 
@@ -13,19 +17,23 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 		name        string
 		org         string
 		path        string
+		displayName string
+		description string
 		// Expected results
 		expectedGroup *Group
 		wantError     error
 		// Manager Results
 		getUserByExternalIDResult *User
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getGroupByName            *Group
 		addMemberMethodResult     *Group
+		getDeletedGroupByName     *Group
 		// Manager Errors
-		getGroupByNameMethodErr      error
-		getUserByExternalIDMethodErr error
-		addGroupMethodErr            error
+		getGroupByNameMethodErr        error
+		getUserByExternalIDMethodErr   error
+		addGroupMethodErr              error
+		getDeletedGroupByNameMethodErr error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
@@ -73,20 +81,22 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_CREATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/example/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_CREATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/example/"),
+								},
 							},
 						},
 					},
@@ -123,6 +133,17 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 				Message: "Invalid parameter: path /**%%/*123",
 			},
 		},
+		"ErrorCaseInvalidDisplayName": {
+			name:        "group1",
+			org:         "org1",
+			path:        "/example/",
+			displayName: strings.Repeat("a", MAX_DISPLAY_NAME_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: displayName length %v, it can't be greater than %v",
+					MAX_DISPLAY_NAME_LENGTH+1, MAX_DISPLAY_NAME_LENGTH),
+			},
+		},
 		"ErrorCaseGroupAlreadyExists": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -189,29 +210,31 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_CREATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_CREATE_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_CREATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/test/asd"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_CREATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/test/asd"),
+								},
 							},
 						},
 					},
@@ -247,13 +270,15 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Path:       "/path/",
-					Urn:        CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Path:       "/path/",
+						Urn:        CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getGroupByNameMethodErr: &database.Error{
@@ -293,12 +318,57 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"ErrorCaseGroupDeleted": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "group1",
+			org:  "org1",
+			path: "/example/",
+			wantError: &Error{
+				Code:    GROUP_ALREADY_EXIST,
+				Message: "Unable to create group, group with org org1 and name group1 is deleted and must be restored or purged first",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+		},
+		"ErrorCaseGetDeletedGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "group1",
+			org:  "org1",
+			path: "/example/",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+			getDeletedGroupByNameMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
 		testRepo := makeTestRepo()
 		testAPI := makeTestAPI(testRepo)
 
+		if testcase.getDeletedGroupByName != nil || testcase.getDeletedGroupByNameMethodErr != nil {
+			testRepo.ArgsOut[GetDeletedGroupByNameMethod][0] = testcase.getDeletedGroupByName
+			testRepo.ArgsOut[GetDeletedGroupByNameMethod][1] = testcase.getDeletedGroupByNameMethodErr
+		}
 		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByName
 		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
@@ -308,7 +378,8 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 		testRepo.ArgsOut[AddGroupMethod][0] = testcase.expectedGroup
 		testRepo.ArgsOut[AddGroupMethod][1] = testcase.addGroupMethodErr
 
-		group, err := testAPI.AddGroup(testcase.requestInfo, testcase.org, testcase.name, testcase.path)
+		group, err := testAPI.AddGroup(testcase.requestInfo, testcase.org, testcase.name, testcase.path, testcase.displayName,
+			testcase.description)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroup, group)
 	}
 }
@@ -326,7 +397,7 @@ func TestAuthAPI_GetGroupByName(t *testing.T) {
 		// Manager Results
 		getUserByExternalIDResult  *User
 		getGroupsByUserIDResult    []Group
-		getAttachedPoliciesResult  []Policy
+		getAttachedPoliciesResult  []GroupPolicy
 		getGroupByNameMethodResult *Group
 		// Manager Errors
 		getUserByExternalIDMethodErr error
@@ -382,20 +453,22 @@ func TestAuthAPI_GetGroupByName(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
+								},
 							},
 						},
 					},
@@ -504,29 +577,31 @@ func TestAuthAPI_GetGroupByName(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/test/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/test/asd"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/test/asd"),
+								},
 							},
 						},
 					},
@@ -573,13 +648,15 @@ func TestAuthAPI_GetGroupByName(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Path:       "/path/",
-					Urn:        CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Path:       "/path/",
+						Urn:        CreateUrn("example", RESOURCE_GROUP, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getGroupByNameMethodResult: &Group{
@@ -620,7 +697,7 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 		// Manager Results
 		getGroupsFilteredMethodResult []Group
 		getGroupsByUserIDResult       []Group
-		getAttachedPoliciesResult     []Policy
+		getAttachedPoliciesResult     []GroupPolicy
 		getUserByExternalIDResult     *User
 		// Manager Errors
 		getUserByExternalIDMethodErr error
@@ -713,21 +790,23 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_GROUPS,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -821,30 +900,32 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_GROUPS,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_LIST_GROUPS,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_LIST_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -889,14 +970,16 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -926,19 +1009,22 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 
 func TestAuthAPI_UpdateGroup(t *testing.T) {
 	testcases := map[string]struct {
-		requestInfo  RequestInfo
-		org          string
-		groupName    string
-		newGroupName string
-		newPath      string
+		requestInfo    RequestInfo
+		org            string
+		groupName      string
+		newGroupName   string
+		newPath        string
+		newDisplayName string
+		newDescription string
+		version        int64
 		// Expected result
 		expectedGroup *Group
 		wantError     error
 		// Manager Results
 		getGroupByNameResult            *Group
-		getGroupMembersResult           []User
+		getGroupMembersResult           []GroupMember
 		getGroupsByUserIDResult         []Group
-		getAttachedPoliciesResult       []Policy
+		getAttachedPoliciesResult       []GroupPolicy
 		getUserByExternalIDResult       *User
 		updateGroupResult               *Group
 		getGroupByNameMethodSpecialFunc func(string, string) (*Group, error)
@@ -1016,22 +1102,24 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_UPDATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -1061,6 +1149,17 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 				Message: "Invalid parameter: new path /$",
 			},
 		},
+		"ErrorCaseInvalidDescription": {
+			org:            "123",
+			newGroupName:   "group1",
+			newPath:        "/example/",
+			newDescription: strings.Repeat("a", MAX_DESCRIPTION_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: description length %v, it can't be greater than %v",
+					MAX_DESCRIPTION_LENGTH+1, MAX_DESCRIPTION_LENGTH),
+			},
+		},
 		"ErrorCaseInvalidOrg": {
 			org:          "$^**!",
 			groupName:    "group1",
@@ -1196,21 +1295,23 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -1251,31 +1352,33 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_UPDATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_UPDATE_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path"),
+								},
 							},
 						},
 					},
@@ -1324,22 +1427,24 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/new/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_UPDATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+								},
 							},
 						},
 					},
@@ -1388,31 +1493,33 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/new/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_UPDATE_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_UPDATE_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, "/new/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_UPDATE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, "/new/"),
+								},
 							},
 						},
 					},
@@ -1453,14 +1560,16 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -1493,6 +1602,88 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			groupName:    "group1",
+			newGroupName: "newName",
+			newPath:      "/new/",
+			version:      3,
+			expectedGroup: &Group{
+				ID:      "12345",
+				Name:    "newName",
+				Org:     "123",
+				Path:    "/new/",
+				Urn:     CreateUrn("123", RESOURCE_GROUP, "/new/", "newName"),
+				Version: 4,
+			},
+			getGroupByNameResult: &Group{
+				ID:      "12345",
+				Name:    "group1",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Version: 3,
+			},
+			updateGroupResult: &Group{
+				ID:      "12345",
+				Name:    "newName",
+				Org:     "123",
+				Path:    "/new/",
+				Urn:     CreateUrn("123", RESOURCE_GROUP, "/new/", "newName"),
+				Version: 4,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			groupName:    "group1",
+			newGroupName: "newName",
+			newPath:      "/new/",
+			version:      2,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Group with organization 123 and name group1 is in version 3, not in expected version 2",
+			},
+			getGroupByNameResult: &Group{
+				ID:      "12345",
+				Name:    "group1",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Version: 3,
+			},
+		},
+		"ErrorCaseUpdateGroupDBVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			groupName:    "group1",
+			newGroupName: "newName",
+			newPath:      "/new/",
+			wantError: &Error{
+				Code: VERSION_MISMATCH_ERROR,
+			},
+			getGroupByNameResult: &Group{
+				ID:      "12345",
+				Name:    "group1",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Version: 3,
+			},
+			updateGroupMethodErr: &database.Error{
+				Code: database.VERSION_MISMATCH,
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -1509,7 +1700,8 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 
-		group, err := testAPI.UpdateGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.newGroupName, testcase.newPath)
+		group, err := testAPI.UpdateGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.newGroupName, testcase.newPath,
+			testcase.newDisplayName, testcase.newDescription, testcase.version)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroup, group)
 	}
 }
@@ -1520,12 +1712,13 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 		requestInfo RequestInfo
 		name        string
 		org         string
+		version     int64
 		// Expected result
 		wantError error
 		// Manager Results
 		getUserByExternalIDResult  *User
 		getGroupsByUserIDResult    []Group
-		getAttachedPoliciesResult  []Policy
+		getAttachedPoliciesResult  []GroupPolicy
 		getGroupByNameMethodResult *Group
 		// API Errors
 		getUserByExternalIDMethodErr error
@@ -1573,22 +1766,24 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("org1", RESOURCE_USER, "/example/", "123456"),
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_DELETE_GROUP,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_DELETE_GROUP,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -1685,21 +1880,23 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -1739,31 +1936,33 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_DELETE_GROUP,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_DELETE_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_DELETE_GROUP,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/example/group1"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_DELETE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/example/group1"),
+								},
 							},
 						},
 					},
@@ -1803,14 +2002,16 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/example/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/example/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 		},
@@ -1840,6 +2041,66 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:    "group1",
+			org:     "org1",
+			version: 3,
+			getGroupByNameMethodResult: &Group{
+				ID:      "543210",
+				Name:    "group1",
+				Org:     "org1",
+				Path:    "/example/",
+				Version: 3,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:    "group1",
+			org:     "org1",
+			version: 2,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Group with organization org1 and name group1 is in version 3, not in expected version 2",
+			},
+			getGroupByNameMethodResult: &Group{
+				ID:      "543210",
+				Name:    "group1",
+				Org:     "org1",
+				Path:    "/example/",
+				Version: 3,
+			},
+		},
+		"ErrorCaseModifiedMeanwhile": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:    "group1",
+			org:     "org1",
+			version: 3,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Group with id 543210 isn't in version 3",
+			},
+			getGroupByNameMethodResult: &Group{
+				ID:      "543210",
+				Name:    "group1",
+				Org:     "org1",
+				Path:    "/example/",
+				Version: 3,
+			},
+			removeGroupMethodErr: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Group with id 543210 isn't in version 3",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -1855,93 +2116,551 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[RemoveGroupMethod][0] = testcase.removeGroupMethodErr
 
-		err := testAPI.RemoveGroup(testcase.requestInfo, testcase.org, testcase.name)
+		err := testAPI.RemoveGroup(testcase.requestInfo, testcase.org, testcase.name, testcase.version)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		// Check group is removed in the version that was retrieved
+		if testcase.wantError == nil && testRepo.ArgsIn[RemoveGroupMethod][1] != testcase.getGroupByNameMethodResult.Version {
+			t.Errorf("Test %v failed. Received different version %v", x, testRepo.ArgsIn[RemoveGroupMethod][1])
+		}
 	}
 }
 
-func TestAuthAPI_AddMember(t *testing.T) {
+func TestAuthAPI_ListDeletedGroups(t *testing.T) {
 	testcases := map[string]struct {
-		// API Method args
+		// API method args
 		requestInfo RequestInfo
-		userID      string
 		org         string
-		groupName   string
+		pathPrefix  string
 		// Expected result
-		wantError error
+		expectedResult []GroupIdentity
+		wantError      error
 		// Manager Results
-		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
-		getUserByExternalIDResult *User
-		getGroupByNameResult      *Group
-		isMemberOfGroupResult     bool
+		getUserByExternalIDResult    *User
+		getGroupsByUserIDResult      []Group
+		getAttachedPoliciesResult    []GroupPolicy
+		getDeletedGroupsMethodResult []Group
 		// Manager Errors
-		getUserByExternalIDMethodErr error
-		getGroupByNameMethodErr      error
-		addMemberMethodErr           error
-		isMemberOfGroupMethodErr     error
+		getDeletedGroupsMethodErr error
 	}{
-		"OkCaseAdmin": {
+		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "12345",
-				Path:       "/test/asd/",
+			org:        "org1",
+			pathPrefix: "/example/",
+			expectedResult: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
 			},
-			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/test/asd/",
+			getDeletedGroupsMethodResult: []Group{
+				{
+					ID:   "GROUP-ID1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/example/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+				},
 			},
-			isMemberOfGroupResult: false,
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
+			org: "org1",
+			expectedResult: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
 			getGroupsByUserIDResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
-					Org:  "org1",
 					Path: "/path/",
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								"iam:*",
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-								GetUrnPrefix("", RESOURCE_USER, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_DELETED_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/example/"),
+								},
 							},
 						},
 					},
 				},
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
+			getDeletedGroupsMethodResult: []Group{
+				{
+					ID:   "GROUP-ID1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/example/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+				},
+				{
+					ID:   "GROUP-ID2",
+					Name: "group2",
+					Org:  "org1",
+					Path: "/other/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/other/", "group2"),
+				},
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			org: "!*^**~$%&/()",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()",
+			},
+		},
+		"ErrorCaseInvalidPath": {
+			org:        "org1",
+			pathPrefix: "/example/das~",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: PathPrefix /example/das~",
+			},
+		},
+		"ErrorCaseGetDeletedGroupsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedGroupsMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetDeletedGroupsMethod][0] = testcase.getDeletedGroupsMethodResult
+		testRepo.ArgsOut[GetDeletedGroupsMethod][1] = testcase.getDeletedGroupsMethodErr
+		groups, err := testAPI.ListDeletedGroups(testcase.requestInfo, testcase.org, testcase.pathPrefix)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, groups)
+	}
+}
+
+func TestAuthAPI_RestoreGroup(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		name        string
+		// Expected result
+		expectedGroup *Group
+		wantError     error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		getDeletedGroupByName     *Group
+		getGroupByName            *Group
+		// Manager Errors
+		getDeletedGroupByNameMethodErr error
+		getGroupByNameMethodErr        error
+		restoreGroupMethodErr          error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			expectedGroup: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:  "org1",
+			name: "group1",
+			expectedGroup: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_RESTORE_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/example/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidName": {
+			org:  "org1",
+			name: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			org:  "!*^**~$%&/()",
+			name: "group1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()",
+			},
+		},
+		"ErrorCaseDeletedGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Deleted group with organization org1 and name group1 not found",
+			},
+			getDeletedGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Deleted group with organization org1 and name group1 not found",
+			},
+		},
+		"ErrorCaseGetDeletedGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedGroupByNameMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/example/group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+		},
+		"ErrorCaseGroupAlreadyExists": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code:    GROUP_ALREADY_EXIST,
+				Message: "Unable to restore group, group with org org1 and name group1 already exists",
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getGroupByName: &Group{
+				ID:   "OTHER-GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/other/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/other/", "group1"),
+			},
+		},
+		"ErrorCaseGetGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseRestoreGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedGroupByName: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+			restoreGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetDeletedGroupByNameMethod][0] = testcase.getDeletedGroupByName
+		testRepo.ArgsOut[GetDeletedGroupByNameMethod][1] = testcase.getDeletedGroupByNameMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByName
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[RestoreGroupMethod][0] = testcase.restoreGroupMethodErr
+		group, err := testAPI.RestoreGroup(testcase.requestInfo, testcase.org, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroup, group)
+	}
+}
+
+func TestAuthAPI_AddMember(t *testing.T) {
+	expireAt := time.Now().UTC().Add(time.Hour)
+	expiredAt := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		userID      string
+		org         string
+		groupName   string
+		expireAt    *time.Time
+		// Expected result
+		wantError error
+		// Manager Results
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		getUserByExternalIDResult *User
+		getGroupByNameResult      *Group
+		isMemberOfGroupResult     bool
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupByNameMethodErr      error
+		addMemberMethodErr           error
+		isMemberOfGroupMethodErr     error
+	}{
+		"OkCaseExpireAt": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			expireAt:  &expireAt,
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+		},
+		"ErrorCaseExpireAtInPast": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			expireAt:  &expiredAt,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: expireAt 2016-01-01T00:00:00Z, it must be in the future",
+			},
+		},
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			isMemberOfGroupResult: false,
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									"iam:*",
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
 				ExternalID: "1234",
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
@@ -2047,21 +2766,23 @@ func TestAuthAPI_AddMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -2102,31 +2823,33 @@ func TestAuthAPI_AddMember(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_ADD_MEMBER,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								"iam:*",
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_ADD_MEMBER,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-								GetUrnPrefix("", RESOURCE_USER, ""),
+							{
+								Effect: "allow",
+								Actions: []string{
+									"iam:*",
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
 							},
 						},
 					},
@@ -2167,14 +2890,16 @@ func TestAuthAPI_AddMember(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -2306,8 +3031,12 @@ func TestAuthAPI_AddMember(t *testing.T) {
 		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
 		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
 
-		err := testAPI.AddMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
+		err := testAPI.AddMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org, testcase.expireAt)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if err == nil && testRepo.ArgsIn[AddMemberMethod][2] != testcase.expireAt {
+			t.Errorf("Test %v failed. Received different expiration (wanted:%v / received:%v)", x, testcase.expireAt,
+				testRepo.ArgsIn[AddMemberMethod][2])
+		}
 	}
 }
 
@@ -2324,7 +3053,7 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 		getGroupByNameResult      *Group
 		getUserByExternalIDResult *User
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		isMemberOfGroupResult     bool
 		// Manager Errors
 		getGroupByNameMethodErr      error
@@ -2376,31 +3105,33 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_REMOVE_MEMBER,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_REMOVE_MEMBER,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
 							},
 						},
 					},
@@ -2512,21 +3243,23 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -2566,31 +3299,33 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_REMOVE_MEMBER,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_GET_USER,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_REMOVE_MEMBER,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
 							},
 						},
 					},
@@ -2630,31 +3365,33 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_REMOVE_MEMBER,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_REMOVE_MEMBER,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_REMOVE_MEMBER,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_REMOVE_MEMBER,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
 							},
 						},
 					},
@@ -2694,14 +3431,16 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -2829,29 +3568,417 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
 		testRepo.ArgsOut[RemoveMemberMethod][0] = testcase.removeMemberMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
-		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+
+		err := testAPI.RemoveMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_AddMembers(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		externalIds []string
+		// Expected result
+		wantResponse []BulkItemResult
+		wantUserIDs  []string
+		wantError    error
+		// Manager Results
+		getGroupByNameResult  *Group
+		users                 map[string]*User
+		isMemberOfGroupResult bool
+		// Manager Errors
+		getGroupByNameMethodErr  error
+		isMemberOfGroupMethodErr error
+		addMembersMethodErr      error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1", "user2", "user1", "unknown"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "user1",
+					Done: true,
+				},
+				{
+					Item: "user2",
+					Done: true,
+				},
+				{
+					Item: "user1",
+					Error: &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: "Invalid parameter: item user1 is duplicated",
+					},
+				},
+				{
+					Item: "unknown",
+					Error: &Error{
+						Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+						Message: "User with externalId unknown not found",
+					},
+				},
+			},
+			wantUserIDs: []string{"UserID1", "UserID2"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			users: map[string]*User{
+				"user1": {
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+				"user2": {
+					ID:         "UserID2",
+					ExternalID: "user2",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user2"),
+				},
+			},
+		},
+		"OkCaseAlreadyMembers": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "user1",
+					Error: &Error{
+						Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+						Message: "User: user1 is already a member of Group: group1",
+					},
+				},
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			users: map[string]*User{
+				"user1": {
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseNoItems": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: number of items 0, it must be between 1 and 1000",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+			wantError: &Error{
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCaseNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			users: map[string]*User{
+				"123456": {
+					ID:         "RequesterID",
+					ExternalID: "123456",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+				},
+			},
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+					"123456", CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1")),
+			},
+		},
+		"ErrorCaseIsMemberOfGroupDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			users: map[string]*User{
+				"user1": {
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			isMemberOfGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+		},
+		"ErrorCaseAddMembersDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			users: map[string]*User{
+				"user1": {
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			addMembersMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		users := testcase.users
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: fmt.Sprintf("User with externalId %v not found", id),
+			}
+		}
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
+		testRepo.ArgsOut[AddMembersMethod][0] = testcase.addMembersMethodErr
+
+		results, err := testAPI.AddMembers(testcase.requestInfo, testcase.org, testcase.groupName, testcase.externalIds)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.wantResponse, results)
+		if testcase.wantUserIDs != nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[AddMembersMethod][0], testcase.wantUserIDs); diff != "" {
+				t.Errorf("Test %v failed. Received different user IDs (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RemoveMembers(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		externalIds []string
+		// Expected result
+		wantResponse []BulkItemResult
+		wantError    error
+		// Manager Results
+		getGroupByNameResult      *Group
+		getUserByExternalIDResult *User
+		isMemberOfGroupResult     bool
+		// Manager Errors
+		removeMembersMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "user1",
+					Done: true,
+				},
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "UserID1",
+				ExternalID: "user1",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			isMemberOfGroupResult: true,
+		},
+		"OkCaseNotMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "user1",
+					Error: &Error{
+						Code:    USER_IS_NOT_A_MEMBER_OF_GROUP,
+						Message: "User with externalId user1 is not a member of group with org org1 and name group1",
+					},
+				},
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "UserID1",
+				ExternalID: "user1",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+		},
+		"ErrorCaseRemoveMembersDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			externalIds: []string{"user1"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "UserID1",
+				ExternalID: "user1",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			isMemberOfGroupResult: true,
+			removeMembersMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[RemoveMembersMethod][0] = testcase.removeMembersMethodErr
 
-		err := testAPI.RemoveMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
-		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		results, err := testAPI.RemoveMembers(testcase.requestInfo, testcase.org, testcase.groupName, testcase.externalIds)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.wantResponse, results)
 	}
 }
 
 func TestAuthAPI_ListMembers(t *testing.T) {
+	expireAt := time.Now().UTC().Add(time.Hour)
 	testcases := map[string]struct {
 		// API Method args
 		requestInfo RequestInfo
 		org         string
 		groupName   string
 		// Expected result
-		expectedMembers []string
+		expectedMembers []GroupMemberIdentity
 		wantError       error
 		// Manager Results
 		getGroupByNameResult      *Group
-		getGroupMembersResult     []User
+		getGroupMembersResult     []GroupMember
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getUserByExternalIDResult *User
 		// API Errors
 		getGroupByNameMethodErr      error
@@ -2865,9 +3992,14 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 			},
 			org:       "org1",
 			groupName: "group1",
-			expectedMembers: []string{
-				"member1",
-				"member2",
+			expectedMembers: []GroupMemberIdentity{
+				{
+					User: "member1",
+				},
+				{
+					User:     "member2",
+					ExpireAt: &expireAt,
+				},
 			},
 			getGroupByNameResult: &Group{
 				ID:   "543210",
@@ -2875,16 +4007,21 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 				Org:  "org1",
 				Path: "/test/",
 			},
-			getGroupMembersResult: []User{
+			getGroupMembersResult: []GroupMember{
 				{
-					ID:         "12345",
-					ExternalID: "member1",
-					Path:       "/test/",
+					User: User{
+						ID:         "12345",
+						ExternalID: "member1",
+						Path:       "/test/",
+					},
 				},
 				{
-					ID:         "123456",
-					ExternalID: "member2",
-					Path:       "/test/",
+					User: User{
+						ID:         "123456",
+						ExternalID: "member2",
+						Path:       "/test/",
+					},
+					ExpireAt: &expireAt,
 				},
 			},
 		},
@@ -2895,9 +4032,13 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 			},
 			org:       "org1",
 			groupName: "group1",
-			expectedMembers: []string{
-				"member1",
-				"member2",
+			expectedMembers: []GroupMemberIdentity{
+				{
+					User: "member1",
+				},
+				{
+					User: "member2",
+				},
 			},
 			getGroupByNameResult: &Group{
 				ID:   "GROUP-USER-ID",
@@ -2906,16 +4047,20 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 				Path: "/path/1/",
 				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
-			getGroupMembersResult: []User{
+			getGroupMembersResult: []GroupMember{
 				{
-					ID:         "12345",
-					ExternalID: "member1",
-					Path:       "/test/",
+					User: User{
+						ID:         "12345",
+						ExternalID: "member1",
+						Path:       "/test/",
+					},
 				},
 				{
-					ID:         "123456",
-					ExternalID: "member2",
-					Path:       "/test/",
+					User: User{
+						ID:         "123456",
+						ExternalID: "member2",
+						Path:       "/test/",
+					},
 				},
 			},
 			getGroupsByUserIDResult: []Group{
@@ -2926,22 +4071,24 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_MEMBERS,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
 							},
 						},
 					},
@@ -3027,21 +4174,23 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -3080,31 +4229,33 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
-								GROUP_ACTION_GET_GROUP,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_LIST_MEMBERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_MEMBERS,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -3143,14 +4294,16 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 					Path: "/path/1/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -3201,11 +4354,16 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 }
 
 func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
+	notBefore := time.Now().UTC().Add(time.Hour)
+	notAfter := time.Now().UTC().Add(2 * time.Hour)
+	expiredAt := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
 		requestInfo RequestInfo
 		org         string
 		groupName   string
 		policyName  string
+		notBefore   *time.Time
+		notAfter    *time.Time
 		// Expected result
 		wantError error
 		// Manager Results
@@ -3213,7 +4371,7 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 		getPolicyByNameResult     *Policy
 		getUserByExternalIDResult *User
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		isAttachedToGroupResult   bool
 		// API Errors
 		getGroupByNameMethodErr      error
@@ -3222,6 +4380,62 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 		isAttachedToGroupMethodErr   error
 		attachPolicyMethodErr        error
 	}{
+		"OkCaseWindow": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notBefore:  &notBefore,
+			notAfter:   &notAfter,
+			getGroupByNameResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "test"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "test",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+			},
+			isAttachedToGroupResult: false,
+		},
+		"ErrorCaseNotAfterInPast": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notAfter:   &expiredAt,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: notAfter 2016-01-01T00:00:00Z, it must be in the future",
+			},
+		},
+		"ErrorCaseNotAfterBeforeNotBefore": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notBefore:  &notAfter,
+			notAfter:   &notBefore,
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: notAfter %v, it must be after notBefore %v",
+					notBefore.Format(time.RFC3339), notAfter.Format(time.RFC3339)),
+			},
+		},
 		"OkCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -3298,31 +4512,33 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 					Path: "/path/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_ATTACH_GROUP_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_ATTACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -3440,21 +4656,23 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 					Path: "/path/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -3494,31 +4712,33 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 					Path: "/path/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_ATTACH_GROUP_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_ATTACH_GROUP_POLICY,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_ATTACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_ATTACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+								},
 							},
 						},
 					},
@@ -3558,14 +4778,16 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 					Path: "/path/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "123",
-					Path:       "/path/",
-					Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "123",
+						Path:       "/path/",
+						Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			getUserByExternalIDResult: &User{
@@ -3735,7 +4957,8 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 		testRepo.ArgsOut[IsAttachedToGroupMethod][1] = testcase.isAttachedToGroupMethodErr
 		testRepo.ArgsOut[AttachPolicyMethod][0] = testcase.attachPolicyMethodErr
 
-		err := testAPI.AttachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName)
+		err := testAPI.AttachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName,
+			testcase.notBefore, testcase.notAfter)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
 	}
 }
@@ -3753,7 +4976,7 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 		getPolicyByNameResult     *Policy
 		getUserByExternalIDResult *User
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		isAttachedToGroupResult   bool
 		// API Errors
 		getGroupByNameMethodErr      error
@@ -3838,31 +5061,33 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 					Path: "/path/",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_DETACH_GROUP_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_DETACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, ""),
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, ""),
+								},
 							},
 						},
 					},
@@ -3926,36 +5151,225 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 		"ErrorCaseNotAuthenticatedUser": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      false,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+			},
+			getGroupByNameResult: &Group{
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseUnauthorizedToDetach": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "group1",
+					Org:  "123",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCaseDenyToDetach": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "group1",
+					Org:  "123",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "123",
+						Path: "/path/",
+						Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+									GROUP_ACTION_DETACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, ""),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_DETACH_GROUP_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "group1",
+					Org:  "123",
+					Path: "/path/",
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "123",
+						Path:       "/path/",
+						Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
 			},
 			org:        "123",
 			groupName:  "group1",
 			policyName: "policy1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
 			},
 			getGroupByNameResult: &Group{
+				ID:   "12345",
 				Name: "group1",
-				Org:  "org1",
+				Org:  "123",
 				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
 			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.USER_NOT_FOUND,
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
 			},
 		},
-		"ErrorCaseUnauthorizedToDetach": {
+		"ErrorCaseIsAttachedDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
 			org:        "123",
 			groupName:  "group1",
 			policyName: "policy1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+				Code: UNKNOWN_API_ERROR,
 			},
 			getGroupByNameResult: &Group{
 				ID:   "12345",
@@ -3964,52 +5378,39 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 				Path: "/path/",
 				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
 			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "group1",
-					Org:  "123",
-					Path: "/path/",
-				},
-			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
-							},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "test",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
 						},
 					},
 				},
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			isAttachedToGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
 			},
 		},
-		"ErrorCaseDenyToDetach": {
+		"ErrorCasePolicyIsNotAttached": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
 			org:        "123",
 			groupName:  "group1",
 			policyName: "policy1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+				Code:    POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Policy with org 123 and name test is not attached to group with org 123 and name group1",
 			},
 			getGroupByNameResult: &Group{
 				ID:   "12345",
@@ -4018,62 +5419,36 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 				Path: "/path/",
 				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
 			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "group1",
-					Org:  "123",
-					Path: "/path/",
-				},
-			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "123",
-					Path: "/path/",
-					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_DETACH_GROUP_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, ""),
-							},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "test",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
 						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_DETACH_GROUP_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_GROUP, "/path/"),
-							},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
 						},
 					},
 				},
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-			},
+			isAttachedToGroupResult: false,
 		},
-		"ErrorCaseNoPermissions": {
+		"ErrorCaseDetachPolicyDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
 			org:        "123",
 			groupName:  "group1",
 			policyName: "policy1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:group/path/group1",
+				Code: UNKNOWN_API_ERROR,
 			},
 			getGroupByNameResult: &Group{
 				ID:   "12345",
@@ -4082,172 +5457,377 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 				Path: "/path/",
 				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
 			},
-			getGroupsByUserIDResult: []Group{
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "test",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			isAttachedToGroupResult: true,
+			detachPolicyMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = testcase.isAttachedToGroupResult
+		testRepo.ArgsOut[IsAttachedToGroupMethod][1] = testcase.isAttachedToGroupMethodErr
+		testRepo.ArgsOut[DetachPolicyMethod][0] = testcase.detachPolicyMethodErr
+
+		err := testAPI.DetachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_AttachPoliciesToGroup(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		policyNames []string
+		// Expected result
+		wantResponse  []BulkItemResult
+		wantPolicyIDs []string
+		wantError     error
+		// Manager Results
+		getGroupByNameResult    *Group
+		policies                map[string]*Policy
+		isAttachedToGroupResult bool
+		// Manager Errors
+		isAttachedToGroupMethodErr error
+		attachPoliciesMethodErr    error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1", "unknown", "policy2"},
+			wantResponse: []BulkItemResult{
 				{
-					ID:   "GROUP-USER-ID",
-					Name: "group1",
-					Org:  "123",
+					Item: "policy1",
+					Done: true,
+				},
+				{
+					Item: "unknown",
+					Error: &Error{
+						Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+						Message: "Policy with organization org1 and name unknown not found",
+					},
+				},
+				{
+					Item: "policy2",
+					Done: true,
+				},
+			},
+			wantPolicyIDs: []string{"PolicyID1", "PolicyID2"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			policies: map[string]*Policy{
+				"policy1": {
+					ID:   "PolicyID1",
+					Name: "policy1",
+					Org:  "org1",
 					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
 				},
+				"policy2": {
+					ID:   "PolicyID2",
+					Name: "policy2",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy2"),
+				},
+			},
+		},
+		"OkCaseAlreadyAttached": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
 			},
-			getAttachedPoliciesResult: []Policy{
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
+			wantResponse: []BulkItemResult{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "123",
-					Path:       "/path/",
-					Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Item: "policy1",
+					Error: &Error{
+						Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+						Message: "Policy: policy1 is already attached to Group: group1",
+					},
 				},
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			policies: map[string]*Policy{
+				"policy1": {
+					ID:   "PolicyID1",
+					Name: "policy1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+				},
 			},
+			isAttachedToGroupResult: true,
 		},
-		"ErrorCasePolicyNotFound": {
+		"ErrorCaseTooManyItems": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			groupName:  "group1",
-			policyName: "policy1",
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: make([]string, MAX_BULK_ITEMS+1),
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
 			wantError: &Error{
-				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: number of items 1001, it must be between 1 and 1000",
+			},
+		},
+		"ErrorCaseIsAttachedToGroupDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
 			},
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
 			getGroupByNameResult: &Group{
-				ID:   "12345",
+				ID:   "GroupID",
 				Name: "group1",
-				Org:  "123",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			policies: map[string]*Policy{
+				"policy1": {
+					ID:   "PolicyID1",
+					Name: "policy1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+				},
+			},
+			isAttachedToGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+		},
+		"ErrorCaseAttachPoliciesDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			policies: map[string]*Policy{
+				"policy1": {
+					ID:   "PolicyID1",
+					Name: "policy1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+				},
 			},
-			getPolicyByNameMethodErr: &database.Error{
-				Code: database.POLICY_NOT_FOUND,
+			attachPoliciesMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
 			},
 		},
-		"ErrorCaseIsAttachedDBErr": {
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		policies := testcase.policies
+		testRepo.SpecialFuncs[GetPolicyByNameMethod] = func(org string, name string) (*Policy, error) {
+			if policy, ok := policies[name]; ok {
+				return policy, nil
+			}
+			return nil, &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: fmt.Sprintf("Policy with organization %v and name %v not found", org, name),
+			}
+		}
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = testcase.isAttachedToGroupResult
+		testRepo.ArgsOut[IsAttachedToGroupMethod][1] = testcase.isAttachedToGroupMethodErr
+		testRepo.ArgsOut[AttachPoliciesMethod][0] = testcase.attachPoliciesMethodErr
+
+		results, err := testAPI.AttachPoliciesToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyNames)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.wantResponse, results)
+		if testcase.wantPolicyIDs != nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[AttachPoliciesMethod][1], testcase.wantPolicyIDs); diff != "" {
+				t.Errorf("Test %v failed. Received different policy IDs (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_DetachPoliciesToGroup(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		groupName   string
+		policyNames []string
+		// Expected result
+		wantResponse []BulkItemResult
+		wantError    error
+		// Manager Results
+		getGroupByNameResult    *Group
+		getPolicyByNameResult   *Policy
+		isAttachedToGroupResult bool
+		// Manager Errors
+		detachPoliciesMethodErr error
+	}{
+		"OkCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			groupName:  "group1",
-			policyName: "policy1",
-			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "policy1",
+					Done: true,
+				},
 			},
 			getGroupByNameResult: &Group{
-				ID:   "12345",
+				ID:   "GroupID",
 				Name: "group1",
-				Org:  "123",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 			},
 			getPolicyByNameResult: &Policy{
-				ID:   "test1",
-				Name: "test",
-				Org:  "123",
+				ID:   "PolicyID1",
+				Name: "policy1",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
-				Statements: &[]Statement{
-					{
-						Effect: "allow",
-						Actions: []string{
-							USER_ACTION_GET_USER,
-						},
-						Resources: []string{
-							GetUrnPrefix("", RESOURCE_USER, "/path/"),
-						},
-					},
-				},
-			},
-			isAttachedToGroupMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
+				Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
 			},
+			isAttachedToGroupResult: true,
 		},
-		"ErrorCasePolicyIsNotAttached": {
+		"OkCaseNotAttached": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			groupName:  "group1",
-			policyName: "policy1",
-			wantError: &Error{
-				Code:    POLICY_IS_NOT_ATTACHED_TO_GROUP,
-				Message: "Policy with org 123 and name test is not attached to group with org 123 and name group1",
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
+			wantResponse: []BulkItemResult{
+				{
+					Item: "policy1",
+					Error: &Error{
+						Code:    POLICY_IS_NOT_ATTACHED_TO_GROUP,
+						Message: "Policy with org org1 and name policy1 is not attached to group with org org1 and name group1",
+					},
+				},
 			},
 			getGroupByNameResult: &Group{
-				ID:   "12345",
+				ID:   "GroupID",
 				Name: "group1",
-				Org:  "123",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 			},
 			getPolicyByNameResult: &Policy{
-				ID:   "test1",
-				Name: "test",
-				Org:  "123",
+				ID:   "PolicyID1",
+				Name: "policy1",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
-				Statements: &[]Statement{
-					{
-						Effect: "allow",
-						Actions: []string{
-							USER_ACTION_GET_USER,
-						},
-						Resources: []string{
-							GetUrnPrefix("", RESOURCE_USER, "/path/"),
-						},
-					},
-				},
+				Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
 			},
-			isAttachedToGroupResult: false,
 		},
-		"ErrorCaseDetachPolicyDBErr": {
+		"ErrorCaseDetachPoliciesDBError": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			groupName:  "group1",
-			policyName: "policy1",
-			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
-			},
+			org:         "org1",
+			groupName:   "group1",
+			policyNames: []string{"policy1"},
 			getGroupByNameResult: &Group{
-				ID:   "12345",
+				ID:   "GroupID",
 				Name: "group1",
-				Org:  "123",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 			},
 			getPolicyByNameResult: &Policy{
-				ID:   "test1",
-				Name: "test",
-				Org:  "123",
+				ID:   "PolicyID1",
+				Name: "policy1",
+				Org:  "org1",
 				Path: "/path/",
-				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
-				Statements: &[]Statement{
-					{
-						Effect: "allow",
-						Actions: []string{
-							USER_ACTION_GET_USER,
-						},
-						Resources: []string{
-							GetUrnPrefix("", RESOURCE_USER, "/path/"),
-						},
-					},
-				},
+				Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
 			},
 			isAttachedToGroupResult: true,
-			detachPolicyMethodErr: &database.Error{
+			detachPoliciesMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
 		},
 	}
 
@@ -4256,35 +5836,30 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 		testAPI := makeTestAPI(testRepo)
 
 		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
-		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
 		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameResult
-		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
-		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
-		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = testcase.isAttachedToGroupResult
-		testRepo.ArgsOut[IsAttachedToGroupMethod][1] = testcase.isAttachedToGroupMethodErr
-		testRepo.ArgsOut[DetachPolicyMethod][0] = testcase.detachPolicyMethodErr
+		testRepo.ArgsOut[DetachPoliciesMethod][0] = testcase.detachPoliciesMethodErr
 
-		err := testAPI.DetachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName)
-		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		results, err := testAPI.DetachPoliciesToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyNames)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.wantResponse, results)
 	}
 }
 
 func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
+	notBefore := time.Now().UTC().Add(-time.Hour)
+	notAfter := time.Now().UTC().Add(time.Hour)
 	testcases := map[string]struct {
 		//API method args
 		requestInfo RequestInfo
 		name        string
 		org         string
 		// Expected result
-		expectedPolicies []string
+		expectedPolicies []GroupPolicyIdentity
 		wantError        error
 		// Manager Results
 		getUserByExternalIDResult  *User
 		getGroupsByUserIDResult    []Group
-		getAttachedPoliciesResult  []Policy
+		getAttachedPoliciesResult  []GroupPolicy
 		getGroupByNameMethodResult *Group
 		// API Errors
 		getUserByExternalIDMethodErr error
@@ -4310,7 +5885,7 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
 			},
-			expectedPolicies: []string{},
+			expectedPolicies: []GroupPolicyIdentity{},
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
@@ -4332,25 +5907,148 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("org1", RESOURCE_USER, "/example/", "123456"),
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
+							},
+						},
+					},
+				},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
 					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
-								GROUP_ACTION_GET_GROUP,
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
+				},
+			},
+			expectedPolicies: []GroupPolicyIdentity{
+				{
+					Policy: "policyUser",
+				},
+			},
+		},
+		"OKCaseWindow": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "group1",
+			org:  "org1",
+			getGroupByNameMethodResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("org1", RESOURCE_USER, "/example/", "123456"),
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+						},
+					},
+					NotBefore: &notBefore,
+					NotAfter:  &notAfter,
+				},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/example/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
+				},
+			},
+			expectedPolicies: []GroupPolicyIdentity{
+				{
+					Policy:    "policyUser",
+					NotBefore: &notBefore,
+					NotAfter:  &notAfter,
+				},
+			},
+		},
+		"ErrorCaseAttachmentNotEffective": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "group1",
+			org:  "org1",
+			getGroupByNameMethodResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("org1", RESOURCE_USER, "/example/", "123456"),
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
+					NotBefore: &notAfter,
 				},
 			},
 			getGroupsByUserIDResult: []Group{
@@ -4362,7 +6060,10 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
 				},
 			},
-			expectedPolicies: []string{"policyUser"},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/example/group1",
+			},
 		},
 		"ErrorCaseInvalidName": {
 			name: "invalid*",
@@ -4440,21 +6141,23 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
 						},
 					},
@@ -4494,31 +6197,33 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/example/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+									GROUP_ACTION_GET_GROUP,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/example/group1"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_GROUP, "/example/group1"),
+								},
 							},
 						},
 					},
@@ -4558,14 +6263,16 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/example/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Org:        "org1",
+						Path:       "/example/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/example/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 			wantError: &Error{
@@ -4626,3 +6333,55 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedPolicies, policies)
 	}
 }
+
+func TestAuthAPI_RemoveExpiredMembers(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API Method args
+		expiredBefore time.Time
+		// Expected result
+		wantError error
+		// Manager Results
+		removeExpiredMembersResult []ExpiredMember
+		// Manager Errors
+		removeExpiredMembersMethodErr error
+	}{
+		"OkCase": {
+			expiredBefore: now,
+			removeExpiredMembersResult: []ExpiredMember{
+				{
+					User:     "user1",
+					Org:      "org1",
+					Group:    "group1",
+					ExpireAt: now.Add(-time.Hour),
+				},
+			},
+		},
+		"ErrorCaseInternalError": {
+			expiredBefore: now,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			removeExpiredMembersMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[RemoveExpiredMembersMethod][0] = testcase.removeExpiredMembersResult
+		testRepo.ArgsOut[RemoveExpiredMembersMethod][1] = testcase.removeExpiredMembersMethodErr
+
+		err := testAPI.RemoveExpiredMembers(testcase.expiredBefore)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testRepo.ArgsIn[RemoveExpiredMembersMethod][0] != testcase.expiredBefore {
+			t.Errorf("Test %v failed. Received different time (wanted:%v / received:%v)", x, testcase.expiredBefore,
+				testRepo.ArgsIn[RemoveExpiredMembersMethod][0])
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
-import log "github.com/Sirupsen/logrus"
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
 
 // TYPE DEFINITIONS
 
@@ -12,18 +16,39 @@ type Resource interface {
 
 // Foulkon API that implements API interfaces using repositories
 type AuthAPI struct {
-	UserRepo   UserRepo
-	GroupRepo  GroupRepo
-	PolicyRepo PolicyRepo
-	Logger     *log.Logger
+	UserRepo          UserRepo
+	GroupRepo         GroupRepo
+	PolicyRepo        PolicyRepo
+	OrganizationRepo  OrganizationRepo
+	SyncRepo          SyncRepo
+	AccessRequestRepo AccessRequestRepo
+	AuditRepo         AuditRepo
+	WebhookRepo       WebhookRepo
+	ChangeRepo        ChangeRepo
+	ApiKeyRepo        ApiKeyRepo
+	AdminRepo         AdminRepo
+	GroupMappingRepo  GroupMappingRepo
+	PasswordRepo      PasswordRepo
+	PasswordConfig    PasswordConfig
+	DecisionLog       *DecisionLog
+	Logger            *log.Logger
 }
 
 // API INTERFACES WITH AUTHORIZATION
 
 type UserAPI interface {
-	// Store user in database. Throw error when parameters are invalid,
+	// Store user in database with optional display name and description. Throw error when parameters are invalid,
 	// user already exists or unexpected error happen.
-	AddUser(requestInfo RequestInfo, externalId string, path string) (*User, error)
+	AddUser(requestInfo RequestInfo, externalId string, path string, displayName string, description string) (*User, error)
+
+	// Store service account in database, it's a user that authenticates with API keys. Throw error when
+	// parameters are invalid, user already exists or unexpected error happen.
+	AddServiceAccount(requestInfo RequestInfo, externalId string, path string, displayName string, description string) (*User, error)
+
+	// Retrieve user authenticated by an auth connector, storing it in database if it doesn't exist. It isn't
+	// authorized because it's used by auth connectors before the user exists. Throw error when parameters are
+	// invalid, user is deleted or unexpected error happen.
+	ProvisionUser(requestID string, externalId string, path string) (*User, error)
 
 	// Retrieve user from database. Throw error when parameter is invalid,
 	// user doesn't exist or unexpected error happen.
@@ -33,23 +58,51 @@ type UserAPI interface {
 	// if pathPrefix is invalid or unexpected error happen.
 	ListUsers(requestInfo RequestInfo, pathPrefix string) ([]string, error)
 
-	// Update user stored in database with new pathPrefix. Throw error if the input parameters
-	// are invalid, user doesn't exist or unexpected error happen.
-	UpdateUser(requestInfo RequestInfo, externalId string, newPath string) (*User, error)
+	// Update user stored in database with new pathPrefix, display name and description if it's in the given
+	// version (0 for any version). Throw error if the input parameters are invalid, user doesn't exist, user
+	// isn't in the given version or unexpected error happen.
+	UpdateUser(requestInfo RequestInfo, externalId string, newPath string, newDisplayName string, newDescription string,
+		version int64) (*User, error)
 
-	// Remove user stored in database with its group relationships.
-	// Throw error if externalId parameter is invalid, user doesn't exist or unexpected error happen.
-	RemoveUser(requestInfo RequestInfo, externalId string) error
+	// Mark user as deleted keeping its group relationships, that are ignored until user is restored. User must
+	// be in the given version (0 for any version). Throw error if externalId parameter is invalid, user doesn't
+	// exist, user isn't in the given version or unexpected error happen.
+	RemoveUser(requestInfo RequestInfo, externalId string, version int64) error
+
+	// Retrieve identifiers of deleted users that aren't purged yet filtered by pathPrefix (optional parameter).
+	// Throw error if pathPrefix is invalid or unexpected error happen.
+	ListDeletedUsers(requestInfo RequestInfo, pathPrefix string) ([]string, error)
+
+	// Restore deleted user with its group relationships. Throw error if externalId parameter is invalid,
+	// deleted user doesn't exist or unexpected error happen.
+	RestoreUser(requestInfo RequestInfo, externalId string) (*User, error)
 
 	// Retrieve groups that belongs to the user. Throw error if externalId parameter is invalid, user
 	// doesn't exist or unexpected error happen.
 	ListGroupsByUser(requestInfo RequestInfo, externalId string) ([]GroupIdentity, error)
+
+	// Change externalId of user, regenerating its urn and keeping its relationships. Throw error if
+	// parameters are invalid, user doesn't exist, new externalId is in use or unexpected error happen.
+	RenameUser(requestInfo RequestInfo, externalId string, newExternalId string, version int64) (*User, error)
+
+	// Move group memberships and access requests of source user to target user and remove source user,
+	// returning target user. Throw error if users are the same, any of them doesn't exist or unexpected error happen.
+	MergeUsers(requestInfo RequestInfo, sourceExternalId string, targetExternalId string) (*User, error)
+
+	// Suspend user keeping its relationships, so all its requests are denied until it's reactivated. Users
+	// can't suspend themselves. Throw error if externalId parameter is invalid, user doesn't exist or
+	// unexpected error happen.
+	SuspendUser(requestInfo RequestInfo, externalId string) (*User, error)
+
+	// Reactivate suspended user, so its requests are authorized again by the policies of its groups. Throw
+	// error if externalId parameter is invalid, user doesn't exist or unexpected error happen.
+	ReactivateUser(requestInfo RequestInfo, externalId string) (*User, error)
 }
 
 type GroupAPI interface {
-	// Store group in database. Throw error when the input parameters are invalid,
-	// the group already exist or unexpected error happen.
-	AddGroup(requestInfo RequestInfo, org string, name string, path string) (*Group, error)
+	// Store group in database with optional display name and description. Throw error when the input parameters
+	// are invalid, the group already exist or unexpected error happen.
+	AddGroup(requestInfo RequestInfo, org string, name string, path string, displayName string, description string) (*Group, error)
 
 	// Retrieve group from database. Throw error when the input parameters are invalid,
 	// group doesn't exist or unexpected error happen.
@@ -59,44 +112,78 @@ type GroupAPI interface {
 	// Throw error if the input parameters are invalid or unexpected error happen.
 	ListGroups(requestInfo RequestInfo, org string, pathPrefix string) ([]GroupIdentity, error)
 
-	// Update group stored in database with new name and pathPrefix.
-	// Throw error if the input parameters are invalid, group to update doesn't exist,
-	// target group already exist or unexpected error happen.
-	UpdateGroup(requestInfo RequestInfo, org string, groupName string, newName string, newPath string) (*Group, error)
+	// Update group stored in database with new name, pathPrefix, display name and description if it's in the given
+	// version (0 for any version). Throw error if the input parameters are invalid, group to update doesn't exist,
+	// group isn't in the given version, target group already exist or unexpected error happen.
+	UpdateGroup(requestInfo RequestInfo, org string, groupName string, newName string, newPath string,
+		newDisplayName string, newDescription string, version int64) (*Group, error)
 
-	// Remove group stored in database with its user and policy relationships.
-	// Throw error if the input parameters are invalid, the group doesn't exist or unexpected error happen.
-	RemoveGroup(requestInfo RequestInfo, org string, name string) error
+	// Mark group as deleted keeping its user and policy relationships, that are ignored until group is restored.
+	// Group must be in the given version (0 for any version). Throw error if the input parameters are invalid,
+	// the group doesn't exist, group isn't in the given version or unexpected error happen.
+	RemoveGroup(requestInfo RequestInfo, org string, name string, version int64) error
+
+	// Retrieve identifiers of deleted groups that aren't purged yet filtered by org and pathPrefix parameters.
+	// Throw error if the input parameters are invalid or unexpected error happen.
+	ListDeletedGroups(requestInfo RequestInfo, org string, pathPrefix string) ([]GroupIdentity, error)
 
-	// Add new member to group. Throw error if the input parameters are invalid, user doesn't exist,
-	// group doesn't exist, user is already a member of the group or unexpected error happen.
-	AddMember(requestInfo RequestInfo, externalId string, groupName string, org string) error
+	// Restore deleted group with its user and policy relationships. Throw error if the input parameters are invalid,
+	// deleted group doesn't exist, a group with the same name already exists or unexpected error happen.
+	RestoreGroup(requestInfo RequestInfo, org string, name string) (*Group, error)
+
+	// Add new member to group. Membership expires at expireAt if it isn't nil. Throw error if the input
+	// parameters are invalid, user doesn't exist, group doesn't exist, user is already a member of the group
+	// or unexpected error happen.
+	AddMember(requestInfo RequestInfo, externalId string, groupName string, org string, expireAt *time.Time) error
 
 	// Remove member from group. Throw error if the input parameters are invalid, user doesn't exist,
 	// group doesn't exist, user isn't a member of the group or unexpected error happen.
 	RemoveMember(requestInfo RequestInfo, externalId string, groupName string, org string) error
 
+	// Add new members to group at once, reporting the result of each user. Users that don't exist or
+	// are already members of the group are reported as failed without aborting the operation. Throw error
+	// if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	AddMembers(requestInfo RequestInfo, org string, groupName string, externalIds []string) ([]BulkItemResult, error)
+
+	// Remove members from group at once, reporting the result of each user. Users that don't exist or
+	// aren't members of the group are reported as failed without aborting the operation. Throw error
+	// if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	RemoveMembers(requestInfo RequestInfo, org string, groupName string, externalIds []string) ([]BulkItemResult, error)
+
 	// List user identifiers that belong to the group. Throw error if the input parameters are invalid,
 	// group doesn't exist or unexpected error happen.
-	ListMembers(requestInfo RequestInfo, org string, groupName string) ([]string, error)
+	ListMembers(requestInfo RequestInfo, org string, groupName string) ([]GroupMemberIdentity, error)
 
-	// Attach policy to group. Throw error if the input parameters are invalid, policy doesn't exist,
-	// group doesn't exist, policy is already attached to the group or unexpected error happen.
-	AttachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string) error
+	// Attach policy to group. Attachment is only effective after notBefore and before notAfter if they
+	// aren't nil. Throw error if the input parameters are invalid, policy doesn't exist, group doesn't
+	// exist, policy is already attached to the group or unexpected error happen.
+	AttachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string,
+		notBefore *time.Time, notAfter *time.Time) error
 
 	// Detach policy from group. Throw error if the input parameters are invalid, policy doesn't exist,
 	// group doesn't exist, policy isn't attached to the group or unexpected error happen.
 	DetachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string) error
 
-	// Retrieve name of policies that are attached to the group. Throw error if the input parameters are invalid,
-	// group doesn't exist or unexpected error happen.
-	ListAttachedGroupPolicies(requestInfo RequestInfo, org string, groupName string) ([]string, error)
+	// Attach policies to group at once, reporting the result of each policy. Policies that don't exist or
+	// are already attached to the group are reported as failed without aborting the operation. Throw error
+	// if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	AttachPoliciesToGroup(requestInfo RequestInfo, org string, groupName string, policyNames []string) ([]BulkItemResult, error)
+
+	// Detach policies from group at once, reporting the result of each policy. Policies that don't exist or
+	// aren't attached to the group are reported as failed without aborting the operation. Throw error
+	// if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	DetachPoliciesToGroup(requestInfo RequestInfo, org string, groupName string, policyNames []string) ([]BulkItemResult, error)
+
+	// Retrieve name of policies that are attached to the group with the window in which each attachment is
+	// effective. Throw error if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	ListAttachedGroupPolicies(requestInfo RequestInfo, org string, groupName string) ([]GroupPolicyIdentity, error)
 }
 
 type PolicyAPI interface {
-	// Store policy in database. Throw error when the input parameters are invalid,
-	// the policy already exist or unexpected error happen.
-	AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error)
+	// Store policy in database with optional display name and description. Throw error when the input parameters
+	// are invalid, the policy already exist or unexpected error happen.
+	AddPolicy(requestInfo RequestInfo, name string, path string, org string, displayName string, description string,
+		statements []Statement) (*Policy, error)
 
 	// Retrieve policy from database. Throw error when the input parameters are invalid,
 	// policy doesn't exist or unexpected error happen.
@@ -106,21 +193,210 @@ type PolicyAPI interface {
 	// Throw error if the input parameters are invalid or unexpected error happen.
 	ListPolicies(requestInfo RequestInfo, org string, pathPrefix string) ([]PolicyIdentity, error)
 
-	// Update policy stored in database with new name, new pathPrefix and new statements.
-	// It overrides older statements. Throw error if the input parameters are invalid,
-	// policy to update doesn't exist, target policy already exist or unexpected error happen.
+	// Update policy stored in database with new name, new pathPrefix, new display name, new description and new
+	// statements if it's in the given version (0 for any version). It overrides older statements. Throw error if
+	// the input parameters are invalid, policy to update doesn't exist, policy isn't in the given version, target
+	// policy already exist or unexpected error happen.
 	UpdatePolicy(requestInfo RequestInfo, org string, name string, newName string, newPath string,
-		newStatements []Statement) (*Policy, error)
+		newDisplayName string, newDescription string, newStatements []Statement, version int64) (*Policy, error)
+
+	// Mark policy as deleted keeping its groups relationships, that are ignored until policy is restored.
+	// Policy must be in the given version (0 for any version). Throw error if the input parameters are invalid,
+	// the policy doesn't exist, policy isn't in the given version or unexpected error happen.
+	RemovePolicy(requestInfo RequestInfo, org string, name string, version int64) error
 
-	// Remove policy stored in database with its groups relationships.
-	// Throw error if the input parameters are invalid, the policy doesn't exist or unexpected error happen.
-	RemovePolicy(requestInfo RequestInfo, org string, name string) error
+	// Retrieve identifiers of deleted policies that aren't purged yet filtered by org and pathPrefix parameters.
+	// Throw error if the input parameters are invalid or unexpected error happen.
+	ListDeletedPolicies(requestInfo RequestInfo, org string, pathPrefix string) ([]PolicyIdentity, error)
+
+	// Restore deleted policy with its groups relationships. Throw error if the input parameters are invalid,
+	// deleted policy doesn't exist, a policy with the same name already exists or unexpected error happen.
+	RestorePolicy(requestInfo RequestInfo, org string, name string) (*Policy, error)
 
 	// Retrieve name of groups that are attached to the policy. Throw error if the input parameters are invalid,
 	// policy doesn't exist or unexpected error happen.
 	ListAttachedGroups(requestInfo RequestInfo, org string, name string) ([]string, error)
 }
 
+type OrganizationAPI interface {
+	// Store organization in database. Throw error when the name is invalid,
+	// the organization already exist or unexpected error happen.
+	AddOrganization(requestInfo RequestInfo, name string) (*Organization, error)
+
+	// Retrieve organization from database. Throw error when the name is invalid,
+	// organization doesn't exist or unexpected error happen.
+	GetOrganizationByName(requestInfo RequestInfo, name string) (*Organization, error)
+
+	// Retrieve organization names from database. Throw error if unexpected error happen.
+	ListOrganizations(requestInfo RequestInfo) ([]string, error)
+
+	// Remove organization stored in database with its groups, policies and their relationships.
+	// Throw error if the name is invalid, the organization doesn't exist or unexpected error happen.
+	RemoveOrganization(requestInfo RequestInfo, name string) error
+}
+
+type AccessRequestAPI interface {
+	// Store request of authenticated user to be a member of the group during duration seconds. Throw error
+	// if the input parameters are invalid, group doesn't exist, authenticated user isn't a user, it's already
+	// a member of the group, it has already a pending request for the group or unexpected error happen.
+	AddAccessRequest(requestInfo RequestInfo, org string, groupName string, justification string, duration int64) (*AccessRequest, error)
+
+	// Retrieve access requests for the group filtered by status (optional parameter). Approvers of the group
+	// retrieve all requests, other users only their own requests. Throw error if the input parameters are
+	// invalid, group doesn't exist or unexpected error happen.
+	ListAccessRequests(requestInfo RequestInfo, org string, groupName string, status string) ([]AccessRequest, error)
+
+	// Approve pending access request adding the requester to the group until access expires. Throw error
+	// if the input parameters are invalid, group or request doesn't exist, user isn't an approver of the group,
+	// user is the requester, request isn't pending, requester is already a member or unexpected error happen.
+	ApproveAccessRequest(requestInfo RequestInfo, org string, groupName string, id string) (*AccessRequest, error)
+
+	// Reject pending access request. Throw error if the input parameters are invalid, group or request
+	// doesn't exist, user isn't an approver of the group, user is the requester, request isn't pending
+	// or unexpected error happen.
+	RejectAccessRequest(requestInfo RequestInfo, org string, groupName string, id string) (*AccessRequest, error)
+}
+
+type AuditAPI interface {
+	// Store audit event of a mutation. It isn't authorized, the event is recorded on behalf of the
+	// user who requested the mutation. Throw error if unexpected error happen.
+	AddAuditEvent(event AuditEvent) (*AuditEvent, error)
+
+	// Retrieve audit events filtered by user, action, urn prefix and time range (optional parameters)
+	// whose target urn is allowed for action iam:ReadAuditLog. Throw error if the input parameters are
+	// invalid or unexpected error happen.
+	ListAuditEvents(requestInfo RequestInfo, filter AuditFilter) ([]AuditEvent, error)
+}
+
+type WebhookAPI interface {
+	// Store webhook subscribed to the event types in database. Throw error when parameters are invalid,
+	// user isn't allowed or unexpected error happen.
+	AddWebhook(requestInfo RequestInfo, url string, eventTypes []string, secret string) (*Webhook, error)
+
+	// Retrieve webhook from database. Throw error when webhook doesn't exist, user isn't allowed
+	// or unexpected error happen.
+	GetWebhookByID(requestInfo RequestInfo, id string) (*Webhook, error)
+
+	// Retrieve webhooks that user is allowed to list. Throw error if unexpected error happen.
+	ListWebhooks(requestInfo RequestInfo) ([]Webhook, error)
+
+	// Update webhook stored in database, an empty secret keeps the current one. Throw error when parameters
+	// are invalid, webhook doesn't exist, user isn't allowed or unexpected error happen.
+	UpdateWebhook(requestInfo RequestInfo, id string, url string, eventTypes []string, secret string) (*Webhook, error)
+
+	// Remove webhook with its deliveries. Throw error when webhook doesn't exist, user isn't allowed
+	// or unexpected error happen.
+	RemoveWebhook(requestInfo RequestInfo, id string) error
+
+	// Retrieve deliveries of a webhook filtered by status (optional parameter). Throw error when
+	// parameters are invalid, webhook doesn't exist, user isn't allowed or unexpected error happen.
+	ListWebhookDeliveries(requestInfo RequestInfo, id string, status string) ([]WebhookDelivery, error)
+}
+
+type ApiKeyAPI interface {
+	// Create API key for a service account with optional expiration time, returning its secret key that
+	// isn't retrievable later. Throw error when parameters are invalid, user doesn't exist or isn't a service
+	// account, user isn't allowed or unexpected error happen.
+	AddApiKey(requestInfo RequestInfo, externalId string, name string, expireAt *time.Time) (*ApiKeySecret, error)
+
+	// Retrieve API keys of a user without their secret keys. Throw error when user doesn't exist, user
+	// isn't allowed or unexpected error happen.
+	ListApiKeys(requestInfo RequestInfo, externalId string) ([]ApiKey, error)
+
+	// Replace secret key and expiration time of an API key, returning the new secret key. Previous key stops
+	// working immediately. Throw error when parameters are invalid, user or API key don't exist, user isn't
+	// allowed or unexpected error happen.
+	RotateApiKey(requestInfo RequestInfo, externalId string, id string, expireAt *time.Time) (*ApiKeySecret, error)
+
+	// Remove API key of a user. Throw error when user or API key don't exist, user isn't allowed
+	// or unexpected error happen.
+	RevokeApiKey(requestInfo RequestInfo, externalId string, id string) error
+
+	// Retrieve owner of a secret key. It isn't authorized because it's used by auth connectors to identify
+	// the requester. Throw error when key is unknown or expired, its owner doesn't exist or unexpected error happen.
+	AuthenticateApiKey(key string) (*User, error)
+}
+
+type AdminAPI interface {
+	// Store admin credentials of a user, replacing previous ones. Admin credentials only authenticate the user,
+	// permissions come from the policies of its groups. Throw error when parameters are invalid, user doesn't
+	// exist, user isn't allowed or unexpected error happen.
+	AddAdmin(requestInfo RequestInfo, externalId string, password string) (*User, error)
+
+	// Retrieve identifiers of users with admin credentials. Throw error when unexpected error happen.
+	ListAdmins(requestInfo RequestInfo) ([]string, error)
+
+	// Remove admin credentials of a user. Throw error when user doesn't exist or doesn't have admin credentials,
+	// user isn't allowed or unexpected error happen.
+	RemoveAdmin(requestInfo RequestInfo, externalId string) error
+
+	// Retrieve user of admin credentials. It isn't authorized because it's used by the authenticator to identify
+	// the requester. Throw error when user doesn't have admin credentials, credentials are invalid, user is
+	// locked out or unexpected error happen.
+	AuthenticateAdmin(username string, password string) (*User, error)
+}
+
+type PasswordAPI interface {
+	// Store local password of a user, replacing previous one and removing its sessions. Throw error when
+	// parameters are invalid, user doesn't exist or is a service account, user isn't allowed or unexpected
+	// error happen.
+	SetUserPassword(requestInfo RequestInfo, externalId string, password string) (*User, error)
+
+	// Replace local password of a user with a temporary password that is returned, unlocking the user and
+	// removing its sessions. Throw error when user doesn't exist or is a service account, user isn't allowed
+	// or unexpected error happen.
+	ResetUserPassword(requestInfo RequestInfo, externalId string) (*TemporaryPassword, error)
+
+	// Retrieve user of local credentials, counting failed attempts to lock out the user. It isn't authorized
+	// because it's used by auth connectors to identify the requester. Throw error when credentials are invalid,
+	// user is locked out or unexpected error happen.
+	AuthenticateUserPassword(username string, password string) (*User, error)
+
+	// Create session for the user of local credentials, returning its token that isn't retrievable later.
+	// It isn't authorized because the requester isn't identified yet. Throw error when credentials are invalid,
+	// user is locked out or unexpected error happen.
+	Login(username string, password string) (*SessionToken, error)
+
+	// Retrieve owner of a session token. It isn't authorized because it's used by auth connectors to identify
+	// the requester. Throw error when token is unknown or expired, its owner doesn't exist or unexpected error happen.
+	AuthenticateSession(token string) (*User, error)
+
+	// Remove session of a token, so it stops working. Throw error when token is unknown or unexpected error happen.
+	Logout(token string) error
+}
+
+type GroupMappingAPI interface {
+	// Store mapping of a claim value of tokens of an issuer to a group in database. Throw error when parameters
+	// are invalid, group doesn't exist, mapping already exists, user isn't allowed to create mappings or to add
+	// members to the group or unexpected error happen.
+	AddGroupMapping(requestInfo RequestInfo, issuer string, claim string, value string, org string, groupName string) (*GroupMapping, error)
+
+	// Retrieve group mapping from database. Throw error when group mapping doesn't exist, user isn't allowed
+	// or unexpected error happen.
+	GetGroupMappingByID(requestInfo RequestInfo, id string) (*GroupMapping, error)
+
+	// Retrieve group mappings of an organization (optional parameter) that user is allowed to list. Throw error
+	// when parameters are invalid or unexpected error happen.
+	ListGroupMappings(requestInfo RequestInfo, org string) ([]GroupMapping, error)
+
+	// Remove group mapping from database. Throw error when group mapping doesn't exist, user isn't allowed
+	// or unexpected error happen.
+	RemoveGroupMapping(requestInfo RequestInfo, id string) error
+
+	// Add user to the groups mapped for the issuer of its token whose claim values are in the claims of the token,
+	// and remove it from the other groups mapped for the issuer only when it was added by group mappings. Mappings
+	// of other issuers are ignored. It isn't authorized because it's used by auth connectors. Throw error when user
+	// doesn't exist or unexpected error happen.
+	ApplyGroupMappings(requestID string, externalId string, issuer string, claims map[string][]string) error
+}
+
+type ChangeAPI interface {
+	// Retrieve up to limit changes with sequence number greater than since, sorted by sequence number, whose
+	// urn is allowed for action iam:ReadChanges. Throw error if the input parameters are invalid or unexpected
+	// error happen.
+	ListChanges(requestInfo RequestInfo, since int64, limit int) (*ChangeFeed, error)
+}
+
 type AuthzAPI interface {
 	// Retrieve list of authorized user resources filtered according to the input parameters. Throw error
 	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
@@ -134,11 +410,26 @@ type AuthzAPI interface {
 	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
 	GetAuthorizedPolicies(requestInfo RequestInfo, resourceUrn string, action string, policies []Policy) ([]Policy, error)
 
+	// Retrieve list of authorized organizations filtered according to the input parameters. Throw error
+	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
+	GetAuthorizedOrganizations(requestInfo RequestInfo, resourceUrn string, action string, orgs []Organization) ([]Organization, error)
+
 	// Retrieve list of authorized external resources filtered according to the input parameters. Throw error
 	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
 	GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error)
 }
 
+type SyncAPI interface {
+	// Compute changes needed to reach the desired state without storing them. Groups and policies
+	// not present in the document are deleted permanently unless keepUnmanaged is true. Throw error if the
+	// document is invalid, user isn't allowed to do any change or unexpected error happen.
+	PlanSync(requestInfo RequestInfo, state DesiredState, keepUnmanaged bool) (*SyncPlan, error)
+
+	// Compute and apply changes needed to reach the desired state in the same transaction.
+	// Throw error if the document is invalid, user isn't allowed to do any change or unexpected error happen.
+	ApplySync(requestInfo RequestInfo, state DesiredState, keepUnmanaged bool) (*SyncPlan, error)
+}
+
 // REPOSITORY INTERFACES
 
 // User repository that contains all database operations
@@ -149,21 +440,54 @@ type UserRepo interface {
 	// Retrieve user from database if it exists. Otherwise it throws an error.
 	GetUserByExternalID(id string) (*User, error)
 
+	// Retrieve user from database by its identifier if it exists and it isn't deleted. Otherwise it throws an error.
+	GetUserByID(id string) (*User, error)
+
 	// Retrieve user list from database filtered by pathPrefix optional parameter. Throw error
 	// if there are problems with database.
 	GetUsersFiltered(pathPrefix string) ([]User, error)
 
-	// Update user stored in database with new pathPrefix. Throw error if the database restrictions
-	// are not satisfied or unexpected error happen.
-	UpdateUser(user User, newPath string, newUrn string) (*User, error)
+	// Update user stored in database with new pathPrefix, display name and description, increasing its version
+	// and recording who updated it. Update only happens if stored user is still in the version of given user.
+	// Throw error if the database restrictions are not satisfied, the version doesn't match or unexpected error happen.
+	UpdateUser(user User, newPath string, newUrn string, newDisplayName string, newDescription string,
+		updatedBy string) (*User, error)
 
-	// Remove user stored in database with its group relationships.
-	// Throw error if there are problems during transactions.
-	RemoveUser(id string) error
+	// Mark user stored in database as deleted keeping its group relationships, only if it's still in the given
+	// version. Throw error if the version doesn't match or there are problems with database.
+	RemoveUser(id string, version int64) error
 
-	// Retrieve groups that belong to the user. Throw error
+	// Retrieve deleted user from database if it exists. Otherwise it throws an error.
+	GetDeletedUserByExternalID(id string) (*User, error)
+
+	// Retrieve deleted user list from database filtered by pathPrefix optional parameter. Throw error
+	// if there are problems with database.
+	GetDeletedUsers(pathPrefix string) ([]User, error)
+
+	// Clear deletion mark of user. Throw error if there are problems with database.
+	RestoreUser(id string) error
+
+	// Remove permanently users deleted before the given time with their group relationships,
+	// returning the number of purged users. Throw error if there are problems during transactions.
+	PurgeUsers(deletedBefore time.Time) (int64, error)
+
+	// Retrieve groups that belong to the user, ignoring expired memberships. Throw error
 	// if there are problems with database.
 	GetGroupsByUserID(id string) ([]Group, error)
+
+	// Update externalId and urn of user only if it's in the same version, keeping its relationships and
+	// updating the externalId stored in its access requests. Throw error if there are problems during transactions.
+	RenameUser(user User, newExternalId string, newUrn string, updatedBy string) (*User, error)
+
+	// Move group memberships and access requests of source user to target user and delete permanently
+	// source user. When both are members of a group, the longest membership is kept.
+	// Throw error if there are problems during transactions.
+	MergeUsers(source User, target User) error
+
+	// Update status of user, increasing its version and recording who updated it. Update only happens if
+	// stored user is still in the version of given user. Throw error if the version doesn't match or
+	// unexpected error happen.
+	UpdateUserStatus(user User, status string, updatedBy string) (*User, error)
 }
 
 // Group repository that contains all database operations
@@ -178,43 +502,90 @@ type GroupRepo interface {
 	// if there are problems with database.
 	GetGroupsFiltered(org string, pathPrefix string) ([]Group, error)
 
-	// Update group stored in database with new name and pathPrefix.
-	// Throw error if there are problems with database.
-	UpdateGroup(group Group, newName string, newPath string, newUrn string) (*Group, error)
+	// Update group stored in database with new name, pathPrefix, display name and description, increasing its
+	// version and recording who updated it. Update only happens if stored group is still in the version of given
+	// group. Throw error if the version doesn't match or there are problems with database.
+	UpdateGroup(group Group, newName string, newPath string, newUrn string, newDisplayName string,
+		newDescription string, updatedBy string) (*Group, error)
 
-	// Remove group stored in database with its user and policy relationships.
-	// Throw error if there are problems during transactions.
-	RemoveGroup(groupID string) error
+	// Mark group stored in database as deleted keeping its user and policy relationships, only if it's still in
+	// the given version. Throw error if the version doesn't match or there are problems with database.
+	RemoveGroup(groupID string, version int64) error
 
-	// Add new member to group. It doesn't check restrictions about existence of group or user. It throws
-	// errors if there are problems with database.
-	AddMember(userID string, groupID string) error
+	// Retrieve deleted group from database if it exists. Otherwise it throws an error.
+	GetDeletedGroupByName(org string, name string) (*Group, error)
+
+	// Retrieve deleted groups from database filtered by org and pathPrefix optional parameters. Throw error
+	// if there are problems with database.
+	GetDeletedGroups(org string, pathPrefix string) ([]Group, error)
+
+	// Clear deletion mark of group. Throw error if there are problems with database.
+	RestoreGroup(groupID string) error
+
+	// Remove permanently groups deleted before the given time with their user and policy relationships,
+	// returning the number of purged groups. Throw error if there are problems during transactions.
+	PurgeGroups(deletedBefore time.Time) (int64, error)
+
+	// Add new member to group. Membership expires at expireAt if it isn't nil. It doesn't check restrictions
+	// about existence of group or user. It throws errors if there are problems with database.
+	AddMember(userID string, groupID string, expireAt *time.Time) error
 
 	// Remove member from group. It doesn't check restrictions about existence of group or user. It throws
 	// errors if there are problems with database.
 	RemoveMember(userID string, groupID string) error
 
-	// Check if user is member of group. It returns true if at least one relation exists. It throws
-	// errors if there are problems with database.
+	// Add new member to group recording that the membership comes from group mappings. It doesn't check
+	// restrictions about existence of group or user. It throws errors if there are problems with database.
+	AddMappedMember(userID string, groupID string) error
+
+	// Retrieve groups whose membership of the user comes from group mappings, ignoring deleted groups.
+	// Throw error if there are problems with database.
+	GetMappedGroupsByUserID(userID string) ([]Group, error)
+
+	// Add new members to group in a single transaction. It doesn't check restrictions about existence of
+	// group or users. It throws errors if there are problems with database, and then no member is added.
+	AddMembers(userIDs []string, groupID string) error
+
+	// Remove members from group in a single transaction. It doesn't check restrictions about existence of
+	// group or users. It throws errors if there are problems with database, and then no member is removed.
+	RemoveMembers(userIDs []string, groupID string) error
+
+	// Check if user is member of group. It returns true if at least one relation that isn't expired exists.
+	// It throws errors if there are problems with database.
 	IsMemberOfGroup(userID string, groupID string) (bool, error)
 
-	// Retrieve users that belong to the group. Throw error if there are problems with database.
-	GetGroupMembers(groupID string) ([]User, error)
+	// Retrieve users that belong to the group with the expiration of their memberships, ignoring expired ones.
+	// Throw error if there are problems with database.
+	GetGroupMembers(groupID string) ([]GroupMember, error)
 
-	// Attach policy to group. It doesn't check restrictions about existence of group or policy. It throws
-	// errors if there are problems with database.
-	AttachPolicy(groupID string, policyID string) error
+	// Remove memberships that expired before the given time, returning the removed ones.
+	// Throw error if there are problems during transactions.
+	RemoveExpiredMembers(expiredBefore time.Time) ([]ExpiredMember, error)
+
+	// Attach policy to group. Attachment is only effective after notBefore and before notAfter if they
+	// aren't nil. It doesn't check restrictions about existence of group or policy. It throws errors if
+	// there are problems with database.
+	AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error
 
 	// Detach policy from group. It doesn't check restrictions about existence of group or policy. It throws
 	// errors if there are problems with database.
 	DetachPolicy(groupID string, policyID string) error
 
+	// Attach policies to group in a single transaction. It doesn't check restrictions about existence of
+	// group or policies. It throws errors if there are problems with database, and then no policy is attached.
+	AttachPolicies(groupID string, policyIDs []string) error
+
+	// Detach policies from group in a single transaction. It doesn't check restrictions about existence of
+	// group or policies. It throws errors if there are problems with database, and then no policy is detached.
+	DetachPolicies(groupID string, policyIDs []string) error
+
 	// Check if policy is attached to group. It returns true if at least one relation exists. It throws
 	// errors if there are problems with database.
 	IsAttachedToGroup(groupID string, policyID string) (bool, error)
 
-	// Retrieve policies that are attached to the group. Throw error if there are problems with database.
-	GetAttachedPolicies(groupID string) ([]Policy, error)
+	// Retrieve policies that are attached to the group with the window in which each attachment is effective,
+	// including attachments that aren't effective now. Throw error if there are problems with database.
+	GetAttachedPolicies(groupID string) ([]GroupPolicy, error)
 }
 
 // Policy repository that contains all database operations
@@ -229,14 +600,228 @@ type PolicyRepo interface {
 	// if there are problems with database.
 	GetPoliciesFiltered(org string, pathPrefix string) ([]Policy, error)
 
-	// Update policy stored in database with new name and pathPrefix. Also it overrides statements.
-	// Throw error if there are problems with database.
-	UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement) (*Policy, error)
+	// Update policy stored in database with new name, pathPrefix, display name and description, increasing its
+	// version and recording who updated it. Also it overrides statements. Update only happens if stored policy
+	// is still in the version of given policy. Throw error if the version doesn't match or there are problems
+	// with database.
+	UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newDisplayName string,
+		newDescription string, updatedBy string, newStatements []Statement) (*Policy, error)
 
-	// Remove policy stored in database with its groups relationships.
-	// Throw error if there are problems during transactions.
-	RemovePolicy(id string) error
+	// Mark policy stored in database as deleted keeping its statements and groups relationships, only if it's
+	// still in the given version. Throw error if the version doesn't match or there are problems with database.
+	RemovePolicy(id string, version int64) error
+
+	// Retrieve deleted policy from database if it exists. Otherwise it throws an error.
+	GetDeletedPolicyByName(org string, name string) (*Policy, error)
+
+	// Retrieve deleted policies from database filtered by org and pathPrefix optional parameters. Throw error
+	// if there are problems with database.
+	GetDeletedPolicies(org string, pathPrefix string) ([]Policy, error)
+
+	// Clear deletion mark of policy. Throw error if there are problems with database.
+	RestorePolicy(id string) error
+
+	// Remove permanently policies deleted before the given time with their statements and groups relationships,
+	// returning the number of purged policies. Throw error if there are problems during transactions.
+	PurgePolicies(deletedBefore time.Time) (int64, error)
 
 	// Retrieve groups that are attached to the policy. Throw error if there are problems with database.
 	GetAttachedGroups(policyID string) ([]Group, error)
 }
+
+// Organization repository that contains all database operations
+type OrganizationRepo interface {
+	// Store organization in database if there aren't errors.
+	AddOrganization(org Organization) (*Organization, error)
+
+	// Retrieve organization from database if it exists. Otherwise it throws an error.
+	GetOrganizationByName(name string) (*Organization, error)
+
+	// Retrieve all organizations from database. Throw error if there are problems with database.
+	GetOrganizations() ([]Organization, error)
+
+	// Remove organization stored in database with its groups, policies, statements and relationships
+	// in the same transaction. Throw error if there are problems during transaction.
+	RemoveOrganization(name string) error
+}
+
+// Access request repository that contains all database operations
+type AccessRequestRepo interface {
+	// Store access request in database if there aren't errors.
+	AddAccessRequest(request AccessRequest) (*AccessRequest, error)
+
+	// Retrieve access request from database if it exists. Otherwise it throws an error.
+	GetAccessRequestByID(id string) (*AccessRequest, error)
+
+	// Retrieve access requests for the group from database filtered by status optional parameter.
+	// Throw error if there are problems with database.
+	GetAccessRequestsByGroupID(groupID string, status string) ([]AccessRequest, error)
+
+	// Store status, reviewer and times of a reviewed access request. Review only happens if stored request
+	// is still pending, and an approved request adds the requester to the group until its expiration time
+	// in the same transaction. Throw error if it isn't pending or there are problems with database.
+	ReviewAccessRequest(request AccessRequest) (*AccessRequest, error)
+}
+
+// Audit repository that contains all database operations
+type AuditRepo interface {
+	// Store audit event in database if there aren't errors.
+	AddAuditEvent(event AuditEvent) (*AuditEvent, error)
+
+	// Retrieve audit events from database sorted by creation time and filtered by the filter fields.
+	// Throw error if there are problems with database.
+	GetAuditEventsFiltered(filter AuditFilter) ([]AuditEvent, error)
+}
+
+// Webhook repository that contains all database operations
+type WebhookRepo interface {
+	// Store webhook in database if there aren't errors.
+	AddWebhook(webhook Webhook) (*Webhook, error)
+
+	// Retrieve webhook from database if it exists. Otherwise it throws an error.
+	GetWebhookByID(id string) (*Webhook, error)
+
+	// Retrieve all webhooks from database. Throw error if there are problems with database.
+	GetWebhooks() ([]Webhook, error)
+
+	// Update webhook stored in database with new fields. Throw error if there are problems with database.
+	UpdateWebhook(webhook Webhook) (*Webhook, error)
+
+	// Remove webhook and its deliveries from database. Throw error if there are problems with database.
+	RemoveWebhook(id string) error
+
+	// Store webhook deliveries in database in a transaction. Throw error if there are problems with database.
+	AddWebhookDeliveries(deliveries []WebhookDelivery) error
+
+	// Retrieve deliveries of a webhook sorted by creation time and filtered by status (optional parameter).
+	// Throw error if there are problems with database.
+	GetWebhookDeliveries(webhookID string, status string) ([]WebhookDelivery, error)
+
+	// Retrieve pending deliveries whose next attempt is due before the given time, sorted by creation time.
+	// Throw error if there are problems with database.
+	GetPendingWebhookDeliveries(dueBefore time.Time) ([]WebhookDelivery, error)
+
+	// Update webhook delivery stored in database. Throw error if there are problems with database.
+	UpdateWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error)
+}
+
+// Api key repository that contains all database operations. Secret keys aren't stored, only their hashes.
+type ApiKeyRepo interface {
+	// Store API key with the hash of its secret key in database if there aren't errors.
+	AddApiKey(key ApiKey, hash string) (*ApiKey, error)
+
+	// Retrieve API keys of a user sorted by creation time. Throw error if there are problems with database.
+	GetApiKeysByUserID(userID string) ([]ApiKey, error)
+
+	// Retrieve API key from database if it exists. Otherwise it throws an error.
+	GetApiKeyByID(id string) (*ApiKey, error)
+
+	// Retrieve API key whose secret key has the given hash if it exists. Otherwise it throws an error.
+	GetApiKeyByHash(hash string) (*ApiKey, error)
+
+	// Update API key stored in database with new fields and hash. Throw error if there are problems with database.
+	UpdateApiKey(key ApiKey, hash string) (*ApiKey, error)
+
+	// Remove API key from database. Throw error if there are problems with database.
+	RemoveApiKey(id string) error
+}
+
+// Admin repository that contains all database operations of admin credentials
+type AdminRepo interface {
+	// Store hash of admin password of a user in database, replacing previous one. Throw error if there are
+	// problems with database.
+	SetAdminCredentials(userID string, hash string) error
+
+	// Retrieve hash of admin password of a user with its failed attempts if it exists. Otherwise it throws an error.
+	GetAdminCredentials(userID string) (*UserCredentials, error)
+
+	// Increase failed attempts of admin credentials of a user in a single update. When they reach maxFailedAttempts,
+	// if it's greater than 0, user is locked out until lockedUntil and failed attempts are cleared. It returns the
+	// updated credentials. Throw error if they don't exist or there are problems with database.
+	IncrementAdminCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*UserCredentials, error)
+
+	// Store failed attempts and lockout time of admin credentials of a user. Throw error if they don't exist or
+	// there are problems with database.
+	UpdateAdminCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error
+
+	// Retrieve users with admin credentials that aren't deleted sorted by externalId. Throw error if there
+	// are problems with database.
+	GetAdmins() ([]User, error)
+
+	// Remove admin credentials of a user from database. Throw error if they don't exist or there are
+	// problems with database.
+	RemoveAdminCredentials(userID string) error
+}
+
+// Password repository that contains all database operations of local credentials and sessions. Passwords and
+// session tokens aren't stored, only their hashes.
+type PasswordRepo interface {
+	// Store hash of local password of a user in database, replacing previous one, clearing its failed attempts
+	// and removing its sessions in the same transaction. Throw error if there are problems with database.
+	SetUserCredentials(userID string, hash string) error
+
+	// Retrieve local credentials of a user if they exist. Otherwise it throws an error.
+	GetUserCredentials(userID string) (*UserCredentials, error)
+
+	// Increase failed attempts of local credentials of a user in a single update. When they reach maxFailedAttempts,
+	// if it's greater than 0, user is locked out until lockedUntil and failed attempts are cleared. It returns the
+	// updated credentials. Throw error if they don't exist or there are problems with database.
+	IncrementUserCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*UserCredentials, error)
+
+	// Store failed attempts and lockout time of local credentials of a user. Throw error if there are
+	// problems with database.
+	UpdateUserCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error
+
+	// Store session with the hash of its token in database if there aren't errors.
+	AddSession(session Session, hash string) (*Session, error)
+
+	// Retrieve session whose token has the given hash if it exists. Otherwise it throws an error.
+	GetSessionByHash(hash string) (*Session, error)
+
+	// Remove session from database. Throw error if it doesn't exist or there are problems with database.
+	RemoveSession(id string) error
+
+	// Remove sessions that expired before the given time, returning the number of removed sessions.
+	// Throw error if there are problems with database.
+	RemoveExpiredSessions(expiredBefore time.Time) (int64, error)
+}
+
+// Group mapping repository that contains all database operations
+type GroupMappingRepo interface {
+	// Store group mapping in database. Throw error if there are problems with database.
+	AddGroupMapping(mapping GroupMapping) (*GroupMapping, error)
+
+	// Retrieve group mapping from database if it exists. Otherwise it throws an error.
+	GetGroupMappingByID(id string) (*GroupMapping, error)
+
+	// Retrieve group mappings of an organization, all of them if org is empty, sorted by creation date.
+	// Throw error if there are problems with database.
+	GetGroupMappings(org string) ([]GroupMapping, error)
+
+	// Remove group mapping from database. Throw error if there are problems with database.
+	RemoveGroupMapping(id string) error
+}
+
+// Change log repository that contains all database operations
+type ChangeRepo interface {
+	// Append change to the change log assigning its sequence number. Changes must be visible in the order
+	// of their sequence numbers. Throw error if there are problems with database.
+	AddChange(change Change) (*Change, error)
+
+	// Retrieve up to limit changes with sequence number greater than since, sorted by sequence number.
+	// Throw error if there are problems with database.
+	GetChanges(since int64, limit int) ([]Change, error)
+}
+
+// DecisionSink interface that all authorization decision sinks must implement
+type DecisionSink interface {
+	// Store authorization decision. Throw error if decision couldn't be stored.
+	AddAuthzDecision(decision AuthzDecision) error
+}
+
+// Sync repository that applies a set of changes over groups, policies and their relationships
+type SyncRepo interface {
+	// Apply all changes in order using a single transaction, so none of them is stored if one fails.
+	// Throw error if there are problems with database.
+	ApplySyncPlan(changes []SyncChange) error
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Organization domain
+type Organization struct {
+	ID       string    `json:"id, omitempty"`
+	Name     string    `json:"name, omitempty"`
+	Urn      string    `json:"urn, omitempty"`
+	CreateAt time.Time `json:"createAt, omitempty"`
+}
+
+func (o Organization) String() string {
+	return fmt.Sprintf("[id: %v, name: %v, urn: %v, createAt: %v]",
+		o.ID, o.Name, o.Urn, o.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (o Organization) GetUrn() string {
+	return o.Urn
+}
+
+// ORGANIZATION API IMPLEMENTATION
+
+func (api AuthAPI) AddOrganization(requestInfo RequestInfo, name string) (*Organization, error) {
+	// Validate fields
+	if !IsValidOrg(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+
+	org := createOrganization(name)
+
+	// Check restrictions
+	orgsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, org.Urn, ORGANIZATION_ACTION_CREATE_ORGANIZATION, []Organization{org})
+	if err != nil {
+		return nil, err
+	}
+	if len(orgsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, org.Urn),
+		}
+	}
+
+	// Check if organization already exists
+	_, err = api.OrganizationRepo.GetOrganizationByName(name)
+
+	// Check if organization could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		// Organization doesn't exist in DB, so we can create it
+		case database.ORGANIZATION_NOT_FOUND:
+			// Create organization
+			createdOrg, err := api.OrganizationRepo.AddOrganization(org)
+
+			// Check if there is an unexpected error in DB
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			if err := api.recordChange(requestInfo, ORGANIZATION_ACTION_CREATE_ORGANIZATION, createdOrg.Urn, nil, createdOrg); err != nil {
+				return nil, err
+			}
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization created %+v", createdOrg))
+			return createdOrg, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else {
+		return nil, &Error{
+			Code:    ORGANIZATION_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create organization, organization with name %v already exists", name),
+		}
+	}
+}
+
+func (api AuthAPI) GetOrganizationByName(requestInfo RequestInfo, name string) (*Organization, error) {
+	// Validate fields
+	if !IsValidOrg(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+
+	// Call repo to retrieve the organization
+	org, err := api.OrganizationRepo.GetOrganizationByName(name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		// Organization doesn't exist in DB
+		switch dbError.Code {
+		case database.ORGANIZATION_NOT_FOUND:
+			return nil, &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	orgsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, org.Urn, ORGANIZATION_ACTION_GET_ORGANIZATION, []Organization{*org})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if we have our user authorized
+	if len(orgsFiltered) > 0 {
+		orgFiltered := orgsFiltered[0]
+		return &orgFiltered, nil
+	} else {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, org.Urn),
+		}
+	}
+}
+
+func (api AuthAPI) ListOrganizations(requestInfo RequestInfo) ([]string, error) {
+	// Call repo to retrieve the organizations
+	orgs, err := api.OrganizationRepo.GetOrganizations()
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions to list
+	filteredOrgs, err := api.GetAuthorizedOrganizations(requestInfo, "*", ORGANIZATION_ACTION_LIST_ORGANIZATIONS, orgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transform to names
+	orgNames := []string{}
+	for _, o := range filteredOrgs {
+		orgNames = append(orgNames, o.Name)
+	}
+
+	return orgNames, nil
+}
+
+func (api AuthAPI) RemoveOrganization(requestInfo RequestInfo, name string) error {
+	// Call repo to retrieve the organization
+	org, err := api.GetOrganizationByName(requestInfo, name)
+	if err != nil {
+		return err
+	}
+
+	// Check restrictions
+	orgsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, org.Urn, ORGANIZATION_ACTION_DELETE_ORGANIZATION, []Organization{*org})
+	if err != nil {
+		return err
+	}
+	if len(orgsFiltered) < 1 {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, org.Urn),
+		}
+	}
+
+	// Remove organization with its groups, policies and their relationships
+	err = api.OrganizationRepo.RemoveOrganization(org.Name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, ORGANIZATION_ACTION_DELETE_ORGANIZATION, org.Urn, org, nil); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization deleted %+v", org))
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+func createOrganization(name string) Organization {
+	urn := CreateUrn(name, RESOURCE_ORGANIZATION, "/", name)
+	org := Organization{
+		ID:       uuid.NewV4().String(),
+		Name:     name,
+		CreateAt: time.Now().UTC(),
+		Urn:      urn,
+	}
+
+	return org
+}
@@ -0,0 +1,524 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		name        string
+		// Expected results
+		expectedOrg *Organization
+		wantError   error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		getOrganizationByName     *Organization
+		// Manager Errors
+		getOrganizationByNameMethodErr error
+		addOrganizationMethodErr       error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			expectedOrg: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			expectedOrg: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									ORGANIZATION_ACTION_CREATE_ORGANIZATION,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_ORGANIZATION, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidName": {
+			name: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseOrganizationAlreadyExists": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_ALREADY_EXIST,
+				Message: "Unable to create organization, organization with name org1 already exists",
+			},
+			getOrganizationByName: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource " +
+					CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseAddOrganizationDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+			addOrganizationMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseGetOrganizationDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByName
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[AddOrganizationMethod][0] = testcase.expectedOrg
+		testRepo.ArgsOut[AddOrganizationMethod][1] = testcase.addOrganizationMethodErr
+
+		org, err := testAPI.AddOrganization(testcase.requestInfo, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedOrg, org)
+	}
+}
+
+func TestAuthAPI_GetOrganizationByName(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		name        string
+		// Expected results
+		expectedOrg *Organization
+		wantError   error
+		// Manager Results
+		getUserByExternalIDResult *User
+		// Manager Errors
+		getOrganizationByNameMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			expectedOrg: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseInvalidName": {
+			name: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			expectedOrg: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource " +
+					CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseGetOrganizationDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.expectedOrg
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+
+		org, err := testAPI.GetOrganizationByName(testcase.requestInfo, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedOrg, org)
+	}
+}
+
+func TestAuthAPI_ListOrganizations(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		// Expected results
+		expectedOrgs []string
+		wantError    error
+		// Manager Results
+		getOrganizationsResult    []Organization
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []GroupPolicy
+		// Manager Errors
+		getOrganizationsMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedOrgs: []string{"org1", "org2"},
+			getOrganizationsResult: []Organization{
+				{
+					ID:   "ORG1",
+					Name: "org1",
+					Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+				},
+				{
+					ID:   "ORG2",
+					Name: "org2",
+					Urn:  CreateUrn("org2", RESOURCE_ORGANIZATION, "/", "org2"),
+				},
+			},
+		},
+		"OKCaseFiltered": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			expectedOrgs: []string{"org2"},
+			getOrganizationsResult: []Organization{
+				{
+					ID:   "ORG1",
+					Name: "org1",
+					Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+				},
+				{
+					ID:   "ORG2",
+					Name: "org2",
+					Urn:  CreateUrn("org2", RESOURCE_ORGANIZATION, "/", "org2"),
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									ORGANIZATION_ACTION_LIST_ORGANIZATIONS,
+								},
+								Resources: []string{
+									GetUrnPrefix("org2", RESOURCE_ORGANIZATION, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseGetOrganizationsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getOrganizationsMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationsMethod][0] = testcase.getOrganizationsResult
+		testRepo.ArgsOut[GetOrganizationsMethod][1] = testcase.getOrganizationsMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+
+		orgs, err := testAPI.ListOrganizations(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedOrgs, orgs)
+	}
+}
+
+func TestAuthAPI_RemoveOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		name        string
+		// Expected results
+		wantError error
+		// Manager Results
+		getOrganizationByNameResult *Organization
+		getUserByExternalIDResult   *User
+		getGroupsByUserIDResult     []Group
+		getAttachedPoliciesResult   []GroupPolicy
+		// Manager Errors
+		getOrganizationByNameMethodErr error
+		removeOrganizationMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			getOrganizationByNameResult: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+		"ErrorCaseUnauthorizedDelete": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource " +
+					CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getOrganizationByNameResult: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									ORGANIZATION_ACTION_GET_ORGANIZATION,
+								},
+								Resources: []string{
+									GetUrnPrefix("org1", RESOURCE_ORGANIZATION, "/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseRemoveOrganizationDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getOrganizationByNameResult: &Organization{
+				ID:   "543210",
+				Name: "org1",
+				Urn:  CreateUrn("org1", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			removeOrganizationMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByNameResult
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[RemoveOrganizationMethod][0] = testcase.removeOrganizationMethodErr
+
+		err := testAPI.RemoveOrganization(testcase.requestInfo, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
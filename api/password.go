@@ -0,0 +1,446 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Passwords are stored as bcrypt hashes, which include their salt and cost
+	PASSWORD_BCRYPT_COST = bcrypt.DefaultCost
+
+	// Temporary passwords created by a reset are the hex encoding of random bytes
+	TEMPORARY_PASSWORD_RANDOM_BYTES = 12
+
+	// Session tokens are this prefix followed by the hex encoding of random bytes
+	SESSION_TOKEN_PREFIX       = "fs_"
+	SESSION_TOKEN_RANDOM_BYTES = 32
+
+	// Default lockout and session settings of local passwords
+	DEFAULT_MAX_FAILED_ATTEMPTS = 5
+	DEFAULT_LOCKOUT_DURATION    = 15 * time.Minute
+	DEFAULT_SESSION_DURATION    = 12 * time.Hour
+)
+
+// TYPE DEFINITIONS
+
+// Lockout and session settings of local passwords. Users are locked out during LockoutDuration after
+// MaxFailedAttempts consecutive failed attempts, 0 disables lockout.
+type PasswordConfig struct {
+	MaxFailedAttempts int
+	LockoutDuration   time.Duration
+	SessionDuration   time.Duration
+}
+
+// Local credentials of a user. LockedUntil is nil if the user isn't locked out.
+type UserCredentials struct {
+	UserID         string
+	Hash           string
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// User with a local password, passwords are never returned
+type PasswordIdentity struct {
+	User string `json:"user, omitempty"`
+}
+
+// Password created by a reset, it's only returned when it's generated
+type TemporaryPassword struct {
+	User     string `json:"user, omitempty"`
+	Password string `json:"password, omitempty"`
+}
+
+// Session of a user authenticated with its local password
+type Session struct {
+	ID       string    `json:"id, omitempty"`
+	UserID   string    `json:"-"`
+	ExpireAt time.Time `json:"expireAt, omitempty"`
+	CreateAt time.Time `json:"createAt, omitempty"`
+}
+
+func (s Session) String() string {
+	return fmt.Sprintf("[id: %v, userID: %v, expireAt: %v, createAt: %v]",
+		s.ID, s.UserID, s.ExpireAt.Format("2006-01-02 15:04:05 MST"), s.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// Session with its token, it's only returned when the user logs in
+type SessionToken struct {
+	Session
+	Token string `json:"token, omitempty"`
+}
+
+// PASSWORD API IMPLEMENTATION
+
+func (api AuthAPI) SetUserPassword(requestInfo RequestInfo, externalId string, password string) (*User, error) {
+	// Validate fields
+	if len(password) < MIN_USER_PASSWORD_LENGTH || len(password) > MAX_PASSWORD_LENGTH {
+		return nil, &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: password, it must have between %v and %v characters",
+				MIN_USER_PASSWORD_LENGTH, MAX_PASSWORD_LENGTH),
+		}
+	}
+
+	user, err := api.getUserForPassword(requestInfo, externalId, USER_ACTION_SET_USER_PASSWORD)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.setUserCredentials(*user, password); err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_SET_USER_PASSWORD, user.Urn, nil, PasswordIdentity{User: user.ExternalID}); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Password stored for user %+v", user))
+	return user, nil
+}
+
+func (api AuthAPI) ResetUserPassword(requestInfo RequestInfo, externalId string) (*TemporaryPassword, error) {
+	user, err := api.getUserForPassword(requestInfo, externalId, USER_ACTION_RESET_USER_PASSWORD)
+	if err != nil {
+		return nil, err
+	}
+
+	random := make([]byte, TEMPORARY_PASSWORD_RANDOM_BYTES)
+	if _, err := rand.Read(random); err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Unable to generate temporary password: %v", err),
+		}
+	}
+	password := hex.EncodeToString(random)
+
+	if err := api.setUserCredentials(*user, password); err != nil {
+		return nil, err
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_RESET_USER_PASSWORD, user.Urn, nil, PasswordIdentity{User: user.ExternalID}); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Password reset for user %+v", user))
+	return &TemporaryPassword{User: user.ExternalID, Password: password}, nil
+}
+
+func (api AuthAPI) AuthenticateUserPassword(username string, password string) (*User, error) {
+	invalidCredentials := &Error{
+		Code:    INVALID_USER_CREDENTIALS,
+		Message: "Invalid user credentials",
+	}
+
+	// Call repo to retrieve the user, deleted users can't authenticate
+	user, err := api.UserRepo.GetUserByExternalID(username)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, invalidCredentials
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Call repo to retrieve the hash of its password with its failed attempts
+	credentials, err := api.PasswordRepo.GetUserCredentials(user.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_CREDENTIALS_NOT_FOUND:
+			return nil, invalidCredentials
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if err := api.checkPasswordWithLockout(username, password, credentials, invalidCredentials,
+		api.PasswordRepo.IncrementUserCredentialsAttempts, api.PasswordRepo.UpdateUserCredentialsAttempts); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (api AuthAPI) Login(username string, password string) (*SessionToken, error) {
+	user, err := api.AuthenticateUserPassword(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := createSession(user.ID, api.PasswordConfig.SessionDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store session
+	createdSession, err := api.PasswordRepo.AddSession(session.Session, hashSessionToken(session.Token))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	api.Logger.Infof("Session %v created for user %v", createdSession, user.ExternalID)
+	return &SessionToken{Session: *createdSession, Token: session.Token}, nil
+}
+
+func (api AuthAPI) AuthenticateSession(token string) (*User, error) {
+	session, err := api.getSession(token)
+	if err != nil {
+		return nil, err
+	}
+	if !session.ExpireAt.After(time.Now().UTC()) {
+		return nil, &Error{
+			Code:    INVALID_SESSION_TOKEN,
+			Message: fmt.Sprintf("Session %v expired at %v", session.ID, session.ExpireAt.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	// Call repo to retrieve the owner, deleted users can't authenticate
+	user, err := api.UserRepo.GetUserByID(session.UserID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: fmt.Sprintf("Owner of session %v not found", session.ID),
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return user, nil
+}
+
+func (api AuthAPI) Logout(token string) error {
+	session, err := api.getSession(token)
+	if err != nil {
+		return err
+	}
+
+	// Remove session
+	if err := api.PasswordRepo.RemoveSession(session.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.SESSION_NOT_FOUND:
+			return &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	api.Logger.Infof("Session %v removed", session)
+	return nil
+}
+
+// Remove sessions that expired before expiredBefore. It isn't exposed in any API because it's
+// executed periodically by the worker, not by users.
+func (api AuthAPI) RemoveExpiredSessions(expiredBefore time.Time) error {
+	removed, err := api.PasswordRepo.RemoveExpiredSessions(expiredBefore)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if removed > 0 {
+		api.Logger.Infof("Expired sessions removed: %v", removed)
+	}
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve user checking that requester is allowed to do the action and that user isn't a service account,
+// service accounts authenticate with API keys
+func (api AuthAPI) getUserForPassword(requestInfo RequestInfo, externalId string, action string) (*User, error) {
+	user, err := api.getUserForAction(requestInfo, externalId, action)
+	if err != nil {
+		return nil, err
+	}
+	if user.ServiceAccount {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v, user is a service account", externalId),
+		}
+	}
+	return user, nil
+}
+
+// Store hash of password, so previous password and sessions of user stop working
+func (api AuthAPI) setUserCredentials(user User, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := api.PasswordRepo.SetUserCredentials(user.ID, hash); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return nil
+}
+
+// Check password of credentials, it's shared by local and admin passwords with their repo methods. Locked out
+// users can't authenticate even with a valid password. Failed attempts are increased in a single update, so
+// concurrent attempts can't exceed the maximum, and they are cleared by a successful authentication.
+func (api AuthAPI) checkPasswordWithLockout(username string, password string, credentials *UserCredentials,
+	invalidCredentials *Error, incrementAttempts func(string, int, time.Time) (*UserCredentials, error),
+	updateAttempts func(string, int, *time.Time) error) error {
+	now := time.Now().UTC()
+	if credentials.LockedUntil != nil && credentials.LockedUntil.After(now) {
+		return &Error{
+			Code:    USER_LOCKED_OUT,
+			Message: fmt.Sprintf("User %v is locked out until %v", username, credentials.LockedUntil.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	if !checkPassword(password, credentials.Hash) {
+		// User is locked out when it reaches the maximum, and then failed attempts are counted again
+		updated, err := incrementAttempts(credentials.UserID, api.PasswordConfig.MaxFailedAttempts,
+			now.Add(api.PasswordConfig.LockoutDuration))
+		if err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		if api.PasswordConfig.MaxFailedAttempts > 0 && updated.FailedAttempts == 0 && updated.LockedUntil != nil {
+			api.Logger.Warnf("User %v locked out until %v after %v failed attempts", username,
+				updated.LockedUntil.UTC().Format(time.RFC3339), api.PasswordConfig.MaxFailedAttempts)
+		}
+		return invalidCredentials
+	}
+
+	// Successful authentication clears failed attempts
+	if credentials.FailedAttempts > 0 || credentials.LockedUntil != nil {
+		if err := updateAttempts(credentials.UserID, 0, nil); err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return nil
+}
+
+// Retrieve session of a token
+func (api AuthAPI) getSession(token string) (*Session, error) {
+	invalidToken := &Error{
+		Code:    INVALID_SESSION_TOKEN,
+		Message: "Invalid session token",
+	}
+	if !strings.HasPrefix(token, SESSION_TOKEN_PREFIX) {
+		return nil, invalidToken
+	}
+
+	// Call repo to retrieve the session by the hash of its token
+	session, err := api.PasswordRepo.GetSessionByHash(hashSessionToken(token))
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.SESSION_NOT_FOUND:
+			return nil, invalidToken
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return session, nil
+}
+
+// Create session with a new random token
+func createSession(userID string, duration time.Duration) (*SessionToken, error) {
+	random := make([]byte, SESSION_TOKEN_RANDOM_BYTES)
+	if _, err := rand.Read(random); err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Unable to generate session token: %v", err),
+		}
+	}
+
+	now := time.Now().UTC()
+	session := &SessionToken{
+		Session: Session{
+			ID:       uuid.NewV4().String(),
+			UserID:   userID,
+			ExpireAt: now.Add(duration),
+			CreateAt: now,
+		},
+		Token: SESSION_TOKEN_PREFIX + hex.EncodeToString(random),
+	}
+
+	return session, nil
+}
+
+// Session tokens are stored as their hex SHA-256 hash
+func hashSessionToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Hash password with a new random salt
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PASSWORD_BCRYPT_COST)
+	if err != nil {
+		return "", &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Unable to hash password: %v", err),
+		}
+	}
+
+	return string(hash), nil
+}
+
+// Check password against a hash created by hashPassword, comparing in constant time
+func checkPassword(password string, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
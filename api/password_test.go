@@ -0,0 +1,581 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_SetUserPassword(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalId  string
+		password    string
+		// Expected result
+		expectedResponse *User
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		// Manager Errors
+		setUserCredentialsMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   "password",
+			expectedResponse: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"ErrorCaseShortPassword": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   "pass",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: password, it must have between 8 and 72 characters",
+			},
+		},
+		"ErrorCaseLongPassword": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   strings.Repeat("p", MAX_PASSWORD_LENGTH+1),
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: password, it must have between 8 and 72 characters",
+			},
+		},
+		"ErrorCaseServiceAccount": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "service",
+			password:   "password",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId service, user is a service account",
+			},
+			getUserByExternalIDResult: &User{
+				ID:             "SERVICE-ID",
+				ExternalID:     "service",
+				Path:           "/path/",
+				Urn:            CreateUrn("", RESOURCE_USER, "/path/", "service"),
+				ServiceAccount: true,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "user",
+				Admin:      false,
+			},
+			externalId: "user",
+			password:   "password",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId user is not allowed to access to resource " +
+					CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"ErrorCaseSetUserCredentialsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalId: "user",
+			password:   "password",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+			setUserCredentialsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{}
+		testRepo.ArgsOut[SetUserCredentialsMethod][0] = testcase.setUserCredentialsMethodErr
+
+		user, err := testAPI.SetUserPassword(testcase.requestInfo, testcase.externalId, testcase.password)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, user)
+		if testcase.wantError == nil {
+			// Check that password is stored hashed
+			hash, _ := testRepo.ArgsIn[SetUserCredentialsMethod][1].(string)
+			if hash == testcase.password || !checkPassword(testcase.password, hash) {
+				t.Errorf("Test %v failed. Received unexpected hash %v", x, hash)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_ResetUserPassword(t *testing.T) {
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+	testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{
+		ID:         "USER-ID",
+		ExternalID: "user",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+	}
+
+	password, err := testAPI.ResetUserPassword(RequestInfo{Identifier: "admin", Admin: true}, "user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if password.User != "user" || len(password.Password) != 2*TEMPORARY_PASSWORD_RANDOM_BYTES {
+		t.Errorf("Received unexpected temporary password %v", password)
+	}
+	// Check that temporary password is stored hashed
+	if hash, _ := testRepo.ArgsIn[SetUserCredentialsMethod][1].(string); !checkPassword(password.Password, hash) {
+		t.Errorf("Received unexpected hash %v", hash)
+	}
+}
+
+func TestAuthAPI_AuthenticateUserPassword(t *testing.T) {
+	hash, err := hashPassword("password")
+	if err != nil {
+		t.Fatalf("Unexpected error hashing password: %v", err)
+	}
+	lockedUntil := time.Now().UTC().Add(time.Hour)
+	user := &User{
+		ID:         "USER-ID",
+		ExternalID: "user",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+	}
+	testcases := map[string]struct {
+		// API method args
+		username string
+		password string
+		// Expected result
+		expectedResponse    *User
+		wantError           error
+		expectedIncremented bool
+		expectedCleared     bool
+		// Manager Results
+		getUserByExternalIDResult           *User
+		getUserCredentialsResult            *UserCredentials
+		incrementUserCredentialsAttemptsRes *UserCredentials
+		// Manager Errors
+		getUserByExternalIDMethodErr              error
+		getUserCredentialsMethodErr               error
+		incrementUserCredentialsAttemptsMethodErr error
+	}{
+		"OkCase": {
+			username:                  "user",
+			password:                  "password",
+			expectedResponse:          user,
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID: "USER-ID",
+				Hash:   hash,
+			},
+		},
+		"OkCaseFailedAttemptsCleared": {
+			username:                  "user",
+			password:                  "password",
+			expectedResponse:          user,
+			expectedCleared:           true,
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID:         "USER-ID",
+				Hash:           hash,
+				FailedAttempts: 2,
+			},
+		},
+		"ErrorCaseInvalidPassword": {
+			username: "user",
+			password: "invalid",
+			wantError: &Error{
+				Code:    INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+			expectedIncremented:       true,
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID:         "USER-ID",
+				Hash:           hash,
+				FailedAttempts: 1,
+			},
+			incrementUserCredentialsAttemptsRes: &UserCredentials{
+				UserID:         "USER-ID",
+				Hash:           hash,
+				FailedAttempts: 2,
+			},
+		},
+		"ErrorCaseMaxFailedAttempts": {
+			username: "user",
+			password: "invalid",
+			wantError: &Error{
+				Code:    INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+			expectedIncremented:       true,
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID:         "USER-ID",
+				Hash:           hash,
+				FailedAttempts: DEFAULT_MAX_FAILED_ATTEMPTS - 1,
+			},
+			incrementUserCredentialsAttemptsRes: &UserCredentials{
+				UserID:      "USER-ID",
+				Hash:        hash,
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"ErrorCaseIncrementFailedAttemptsDBErr": {
+			username: "user",
+			password: "invalid",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			expectedIncremented:       true,
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID: "USER-ID",
+				Hash:   hash,
+			},
+			incrementUserCredentialsAttemptsMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseLockedOut": {
+			username: "user",
+			password: "password",
+			wantError: &Error{
+				Code:    USER_LOCKED_OUT,
+				Message: "User user is locked out until " + lockedUntil.Format(time.RFC3339),
+			},
+			getUserByExternalIDResult: user,
+			getUserCredentialsResult: &UserCredentials{
+				UserID:      "USER-ID",
+				Hash:        hash,
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			username: "unknown",
+			password: "password",
+			wantError: &Error{
+				Code:    INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseWithoutCredentials": {
+			username: "user",
+			password: "password",
+			wantError: &Error{
+				Code:    INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+			getUserByExternalIDResult: user,
+			getUserCredentialsMethodErr: &database.Error{
+				Code: database.USER_CREDENTIALS_NOT_FOUND,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetUserCredentialsMethod][0] = testcase.getUserCredentialsResult
+		testRepo.ArgsOut[GetUserCredentialsMethod][1] = testcase.getUserCredentialsMethodErr
+		testRepo.ArgsOut[IncrementUserCredentialsAttemptsMethod][0] = testcase.incrementUserCredentialsAttemptsRes
+		testRepo.ArgsOut[IncrementUserCredentialsAttemptsMethod][1] = testcase.incrementUserCredentialsAttemptsMethodErr
+
+		response, err := testAPI.AuthenticateUserPassword(testcase.username, testcase.password)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+
+		// Check failed attempts are increased in repo with the lockout settings
+		if incremented := testRepo.ArgsIn[IncrementUserCredentialsAttemptsMethod][0] != nil; incremented != testcase.expectedIncremented {
+			t.Errorf("Test %v failed. Received different increment of failed attempts %v", x, incremented)
+		}
+		if testcase.expectedIncremented {
+			if diff := pretty.Compare(testRepo.ArgsIn[IncrementUserCredentialsAttemptsMethod][1], DEFAULT_MAX_FAILED_ATTEMPTS); diff != "" {
+				t.Errorf("Test %v failed. Received different max failed attempts (received/wanted) %v", x, diff)
+			}
+			lockedUntil, _ := testRepo.ArgsIn[IncrementUserCredentialsAttemptsMethod][2].(time.Time)
+			if !lockedUntil.After(time.Now().UTC()) {
+				t.Errorf("Test %v failed. Received lockout in the past %v", x, lockedUntil)
+			}
+		}
+
+		// Check failed attempts are cleared
+		if cleared := testRepo.ArgsIn[UpdateUserCredentialsAttemptsMethod][1] == 0; cleared != testcase.expectedCleared {
+			t.Errorf("Test %v failed. Received different clearing of failed attempts %v", x, cleared)
+		}
+	}
+}
+
+func TestAuthAPI_Login(t *testing.T) {
+	hash, err := hashPassword("password")
+	if err != nil {
+		t.Fatalf("Unexpected error hashing password: %v", err)
+	}
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+	testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{
+		ID:         "USER-ID",
+		ExternalID: "user",
+	}
+	testRepo.ArgsOut[GetUserCredentialsMethod][0] = &UserCredentials{
+		UserID: "USER-ID",
+		Hash:   hash,
+	}
+	testRepo.ArgsOut[AddSessionMethod][0] = &Session{
+		ID:       "SESSION-ID",
+		UserID:   "USER-ID",
+		ExpireAt: time.Now().UTC().Add(DEFAULT_SESSION_DURATION),
+	}
+
+	session, err := testAPI.Login("user", "password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(session.Token, SESSION_TOKEN_PREFIX) || session.ID != "SESSION-ID" {
+		t.Errorf("Received unexpected session %v", session)
+	}
+	// Check that token is stored hashed with the session duration
+	if hash, _ := testRepo.ArgsIn[AddSessionMethod][1].(string); hash != hashSessionToken(session.Token) {
+		t.Errorf("Received unexpected hash %v", hash)
+	}
+	stored, _ := testRepo.ArgsIn[AddSessionMethod][0].(Session)
+	if stored.ExpireAt.Sub(stored.CreateAt) != DEFAULT_SESSION_DURATION {
+		t.Errorf("Received unexpected session duration %v", stored.ExpireAt.Sub(stored.CreateAt))
+	}
+}
+
+func TestAuthAPI_AuthenticateSession(t *testing.T) {
+	expireAt := time.Now().UTC().Add(-time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		token string
+		// Expected result
+		expectedResponse *User
+		wantError        error
+		// Manager Results
+		getSessionByHashResult *Session
+		getUserByIDResult      *User
+		// Manager Errors
+		getSessionByHashMethodErr error
+		getUserByIDMethodErr      error
+	}{
+		"OkCase": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			expectedResponse: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+			},
+			getSessionByHashResult: &Session{
+				ID:       "SESSION-ID",
+				UserID:   "USER-ID",
+				ExpireAt: time.Now().UTC().Add(time.Hour),
+			},
+			getUserByIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+			},
+		},
+		"ErrorCaseInvalidPrefix": {
+			token: "token",
+			wantError: &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			},
+		},
+		"ErrorCaseSessionNotFound": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			wantError: &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			},
+			getSessionByHashMethodErr: &database.Error{
+				Code: database.SESSION_NOT_FOUND,
+			},
+		},
+		"ErrorCaseExpiredSession": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			wantError: &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Session SESSION-ID expired at " + expireAt.Format(time.RFC3339),
+			},
+			getSessionByHashResult: &Session{
+				ID:       "SESSION-ID",
+				UserID:   "USER-ID",
+				ExpireAt: expireAt,
+			},
+		},
+		"ErrorCaseOwnerNotFound": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			wantError: &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Owner of session SESSION-ID not found",
+			},
+			getSessionByHashResult: &Session{
+				ID:       "SESSION-ID",
+				UserID:   "USER-ID",
+				ExpireAt: time.Now().UTC().Add(time.Hour),
+			},
+			getUserByIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetSessionByHashMethod][0] = testcase.getSessionByHashResult
+		testRepo.ArgsOut[GetSessionByHashMethod][1] = testcase.getSessionByHashMethodErr
+		testRepo.ArgsOut[GetUserByIDMethod][0] = testcase.getUserByIDResult
+		testRepo.ArgsOut[GetUserByIDMethod][1] = testcase.getUserByIDMethodErr
+
+		response, err := testAPI.AuthenticateSession(testcase.token)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+	}
+}
+
+func TestAuthAPI_Logout(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		token string
+		// Expected result
+		wantError error
+		// Manager Results
+		getSessionByHashResult *Session
+		// Manager Errors
+		getSessionByHashMethodErr error
+		removeSessionMethodErr    error
+	}{
+		"OkCase": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			getSessionByHashResult: &Session{
+				ID:     "SESSION-ID",
+				UserID: "USER-ID",
+			},
+		},
+		"ErrorCaseSessionNotFound": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			wantError: &Error{
+				Code:    INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			},
+			getSessionByHashMethodErr: &database.Error{
+				Code: database.SESSION_NOT_FOUND,
+			},
+		},
+		"ErrorCaseRemoveSessionDBErr": {
+			token: SESSION_TOKEN_PREFIX + "token",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getSessionByHashResult: &Session{
+				ID:     "SESSION-ID",
+				UserID: "USER-ID",
+			},
+			removeSessionMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetSessionByHashMethod][0] = testcase.getSessionByHashResult
+		testRepo.ArgsOut[GetSessionByHashMethod][1] = testcase.getSessionByHashMethodErr
+		testRepo.ArgsOut[RemoveSessionMethod][0] = testcase.removeSessionMethodErr
+
+		err := testAPI.Logout(testcase.token)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("passwd")
+	if err != nil {
+		t.Fatalf("Unexpected error hashing password: %v", err)
+	}
+	testcases := map[string]struct {
+		password string
+		hash     string
+		expected bool
+	}{
+		"OkCase": {
+			password: "passwd",
+			hash:     hash,
+			expected: true,
+		},
+		"ErrorCaseWrongPassword": {
+			password: "Passwd",
+			hash:     hash,
+		},
+		"ErrorCaseOtherScheme": {
+			password: "passwd",
+			hash:     "pbkdf2-sha256$1$73616c74$55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc",
+		},
+		"ErrorCaseInvalidHash": {
+			password: "passwd",
+			hash:     hash[:len(hash)-1],
+		},
+	}
+
+	for x, testcase := range testcases {
+		if checked := checkPassword(testcase.password, testcase.hash); checked != testcase.expected {
+			t.Errorf("Test %v failed. Received different check of password (wanted:%v / received:%v)", x, testcase.expected, checked)
+		}
+	}
+}
@@ -12,18 +12,25 @@ import (
 
 // Policy domain
 type Policy struct {
-	ID         string       `json:"id, omitempty"`
-	Name       string       `json:"name, omitempty"`
-	Path       string       `json:"path, omitempty"`
-	Org        string       `json:"org, omitempty"`
-	Urn        string       `json:"urn, omitempty"`
-	CreateAt   time.Time    `json:"createAt, omitempty"`
-	Statements *[]Statement `json:"statements, omitempty"`
+	ID          string       `json:"id, omitempty"`
+	Name        string       `json:"name, omitempty"`
+	Path        string       `json:"path, omitempty"`
+	Org         string       `json:"org, omitempty"`
+	DisplayName string       `json:"displayName, omitempty"`
+	Description string       `json:"description, omitempty"`
+	Urn         string       `json:"urn, omitempty"`
+	CreateAt    time.Time    `json:"createAt, omitempty"`
+	UpdateAt    time.Time    `json:"updatedAt, omitempty"`
+	CreatedBy   string       `json:"createdBy, omitempty"`
+	UpdatedBy   string       `json:"updatedBy, omitempty"`
+	Version     int64        `json:"version, omitempty"`
+	Statements  *[]Statement `json:"statements, omitempty"`
 }
 
 func (p Policy) String() string {
-	return fmt.Sprintf("[id: %v, name: %v, path: %v, org: %v, urn: %v, createAt: %v, statements: %v]",
-		p.ID, p.Name, p.Path, p.Org, p.Urn, p.CreateAt.Format("2006-01-02 15:04:05 MST"), p.Statements)
+	return fmt.Sprintf("[id: %v, name: %v, path: %v, org: %v, displayName: %v, urn: %v, createAt: %v, updatedAt: %v, version: %v, statements: %v]",
+		p.ID, p.Name, p.Path, p.Org, p.DisplayName, p.Urn, p.CreateAt.Format("2006-01-02 15:04:05 MST"),
+		p.UpdateAt.Format("2006-01-02 15:04:05 MST"), p.Version, p.Statements)
 }
 
 func (p Policy) GetUrn() string {
@@ -48,7 +55,8 @@ func (s Statement) String() string {
 
 // POLICY API IMPLEMENTATION
 
-func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error) {
+func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string, org string, displayName string,
+	description string, statements []Statement) (*Policy, error) {
 	// Validate fields
 	if !IsValidName(name) {
 		return nil, &Error{
@@ -69,6 +77,9 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 		}
 
 	}
+	if err := AreValidDescriptiveAttributes(displayName, description); err != nil {
+		return nil, err
+	}
 	err := AreValidStatements(&statements)
 	if err != nil {
 		apiError := err.(*Error)
@@ -80,6 +91,10 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 	}
 
 	policy := createPolicy(name, path, org, &statements)
+	policy.DisplayName = displayName
+	policy.Description = description
+	policy.CreatedBy = requestInfo.Identifier
+	policy.UpdatedBy = requestInfo.Identifier
 
 	// Check restrictions
 	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_CREATE_POLICY, []Policy{policy})
@@ -104,6 +119,11 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 		switch dbError.Code {
 		// Policy doesn't exist in DB
 		case database.POLICY_NOT_FOUND:
+			// Check if policy is deleted but not purged yet
+			if err := api.checkDeletedPolicy(org, name); err != nil {
+				return nil, err
+			}
+
 			// Create policy
 			createdPolicy, err := api.PolicyRepo.AddPolicy(policy)
 
@@ -117,6 +137,9 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 				}
 			}
 
+			if err := api.recordChange(requestInfo, POLICY_ACTION_CREATE_POLICY, createdPolicy.Urn, nil, createdPolicy); err != nil {
+				return nil, err
+			}
 			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy created %+v", createdPolicy))
 			return createdPolicy, nil
 		default: // Unexpected error
@@ -242,7 +265,7 @@ func (api AuthAPI) ListPolicies(requestInfo RequestInfo, org string, pathPrefix
 }
 
 func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName string, newName string, newPath string,
-	newStatements []Statement) (*Policy, error) {
+	newDisplayName string, newDescription string, newStatements []Statement, version int64) (*Policy, error) {
 	// Validate fields
 	if !IsValidName(newName) {
 		return nil, &Error{
@@ -257,6 +280,9 @@ func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName
 		}
 
 	}
+	if err := AreValidDescriptiveAttributes(newDisplayName, newDescription); err != nil {
+		return nil, err
+	}
 	err := AreValidStatements(&newStatements)
 	if err != nil {
 		apiError := err.(*Error)
@@ -286,6 +312,15 @@ func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName
 		}
 	}
 
+	// Check version precondition
+	if !IsExpectedVersion(version, policyDB.Version) {
+		return nil, &Error{
+			Code: VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("Policy with organization %v and name %v is in version %v, not in expected version %v",
+				org, policyName, policyDB.Version, version),
+		}
+	}
+
 	// Check if policy with "newName" exists
 	targetPolicy, err := api.GetPolicyByName(requestInfo, org, newName)
 
@@ -319,23 +354,35 @@ func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName
 	}
 
 	// Update policy
-	policy, err := api.PolicyRepo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newStatements)
+	policy, err := api.PolicyRepo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newDisplayName, newDescription,
+		requestInfo.Identifier, newStatements)
 
-	// Check unexpected DB error
+	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return nil, &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
 
+	if err := api.recordChange(requestInfo, POLICY_ACTION_UPDATE_POLICY, policy.Urn, policyDB, policy); err != nil {
+		return nil, err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy updated from %+v to %+v", policyDB, policy))
 	return policy, nil
 }
 
-func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string) error {
+func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string, version int64) error {
 
 	// Call repo to retrieve the policy
 	policy, err := api.GetPolicyByName(requestInfo, org, name)
@@ -356,20 +403,175 @@ func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string
 		}
 	}
 
-	err = api.PolicyRepo.RemovePolicy(policy.ID)
+	// Check version precondition
+	if !IsExpectedVersion(version, policy.Version) {
+		return &Error{
+			Code: VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("Policy with organization %v and name %v is in version %v, not in expected version %v",
+				org, name, policy.Version, version),
+		}
+	}
+
+	err = api.PolicyRepo.RemovePolicy(policy.ID, policy.Version)
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
 
+	if err := api.recordChange(requestInfo, POLICY_ACTION_DELETE_POLICY, policy.Urn, policy, nil); err != nil {
+		return err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy deleted %+v", policy))
 	return nil
 }
 
+func (api AuthAPI) ListDeletedPolicies(requestInfo RequestInfo, org string, pathPrefix string) ([]PolicyIdentity, error) {
+	// Validate fields
+	if len(org) > 0 && !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
+		}
+	}
+	if len(pathPrefix) == 0 {
+		pathPrefix = "/"
+	}
+
+	// Call repo to retrieve the deleted policies
+	policies, err := api.PolicyRepo.GetDeletedPolicies(org, pathPrefix)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions to list
+	var urnPrefix string
+	if len(org) == 0 {
+		urnPrefix = "*"
+	} else {
+		urnPrefix = GetUrnPrefix(org, RESOURCE_POLICY, pathPrefix)
+	}
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, urnPrefix, POLICY_ACTION_LIST_DELETED_POLICIES, policies)
+	if err != nil {
+		return nil, err
+	}
+
+	policyIDs := []PolicyIdentity{}
+	for _, p := range policiesFiltered {
+		policyIDs = append(policyIDs, PolicyIdentity{
+			Org:  p.Org,
+			Name: p.Name,
+		})
+	}
+
+	return policyIDs, nil
+}
+
+func (api AuthAPI) RestorePolicy(requestInfo RequestInfo, org string, name string) (*Policy, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Call repo to retrieve the deleted policy
+	policy, err := api.PolicyRepo.GetDeletedPolicyByName(org, name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.POLICY_NOT_FOUND:
+			return nil, &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_RESTORE_POLICY, []Policy{*policy})
+	if err != nil {
+		return nil, err
+	}
+	if len(policiesFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, policy.Urn),
+		}
+	}
+
+	// Check that no other policy took its name meanwhile
+	_, err = api.PolicyRepo.GetPolicyByName(org, name)
+	if err == nil {
+		return nil, &Error{
+			Code:    POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to restore policy, policy with org %v and name %v already exist", org, name),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.POLICY_NOT_FOUND {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Restore policy with its relationships
+	if err := api.PolicyRepo.RestorePolicy(policy.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, POLICY_ACTION_RESTORE_POLICY, policy.Urn, nil, policy); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy restored %+v", policy))
+	return policy, nil
+}
+
 func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name string) ([]string, error) {
 
 	// Call repo to retrieve the policy
@@ -414,15 +616,36 @@ func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name
 
 // PRIVATE HELPER METHODS
 
+// Deleted policies keep their name until they are purged, so it can't be reused before
+func (api AuthAPI) checkDeletedPolicy(org string, name string) error {
+	_, err := api.PolicyRepo.GetDeletedPolicyByName(org, name)
+	if err == nil {
+		return &Error{
+			Code: POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create policy, policy with org %v and name %v is deleted and must be restored or purged first",
+				org, name),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.POLICY_NOT_FOUND {
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return nil
+}
+
 func createPolicy(name string, path string, org string, statements *[]Statement) Policy {
 	urn := CreateUrn(org, RESOURCE_POLICY, path, name)
+	now := time.Now().UTC()
 	policy := Policy{
 		ID:         uuid.NewV4().String(),
 		Name:       name,
 		Path:       path,
 		Org:        org,
 		Urn:        urn,
-		CreateAt:   time.Now().UTC(),
+		CreateAt:   now,
+		UpdateAt:   now,
 		Statements: statements,
 	}
 
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/tecsisa/foulkon/database"
@@ -12,10 +14,12 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 		org         string
 		policyName  string
 		path        string
+		displayName string
+		description string
 		statements  []Statement
 
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getUserByExternalIDResult *User
 
 		addPolicyMethodResult       *Policy
@@ -224,6 +228,21 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 				Message: "Invalid parameter: path /**!^#~path/",
 			},
 		},
+		"ErrorCaseBadDisplayName": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:         "123",
+			policyName:  "test",
+			path:        "/path/",
+			displayName: strings.Repeat("a", MAX_DISPLAY_NAME_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: displayName length %v, it can't be greater than %v",
+					MAX_DISPLAY_NAME_LENGTH+1, MAX_DISPLAY_NAME_LENGTH),
+			},
+		},
 		"ErrorCaseBadStatement": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -322,39 +341,41 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policy",
-					Org:  "example",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_CREATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policy",
+						Org:  "example",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_CREATE_POLICY,
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_CREATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_POLICY, "/path/", "test"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_CREATE_POLICY,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_POLICY, "/path/", "test"),
+								},
 							},
 						},
 					},
@@ -433,7 +454,8 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		policy, err := testAPI.AddPolicy(testcase.requestInfo, testcase.policyName, testcase.path, testcase.org, testcase.statements)
+		policy, err := testAPI.AddPolicy(testcase.requestInfo, testcase.policyName, testcase.path, testcase.org, testcase.displayName,
+			testcase.description, testcase.statements)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.addPolicyMethodResult, policy)
 	}
 }
@@ -445,7 +467,7 @@ func TestAuthAPI_GetPolicyByName(t *testing.T) {
 		policyName  string
 
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getUserByExternalIDResult *User
 
 		getPolicyByNameMethodResult *Policy
@@ -614,30 +636,32 @@ func TestAuthAPI_GetPolicyByName(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "example",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "example",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
-							Resources: []string{
-								CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+								},
 							},
 						},
 					},
@@ -674,7 +698,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 		expectedPolicies []PolicyIdentity
 
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getUserByExternalIDResult *User
 		getUserByExternalIDErr    error
 
@@ -837,30 +861,32 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "example",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_LIST_POLICIES,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_LIST_POLICIES,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "example",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_LIST_POLICIES,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path2/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_LIST_POLICIES,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path2/"),
+								},
 							},
 						},
 					},
@@ -960,18 +986,21 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 
 func TestAuthAPI_UpdatePolicy(t *testing.T) {
 	testcases := map[string]struct {
-		requestInfo   RequestInfo
-		org           string
-		policyName    string
-		path          string
-		newPolicyName string
-		newPath       string
-		statements    []Statement
-		newStatements []Statement
+		requestInfo    RequestInfo
+		org            string
+		policyName     string
+		path           string
+		newPolicyName  string
+		newPath        string
+		newDisplayName string
+		newDescription string
+		statements     []Statement
+		newStatements  []Statement
+		version        int64
 
 		getPolicyByNameMethodResult *Policy
 		getGroupsByUserIDResult     []Group
-		getAttachedPoliciesResult   []Policy
+		getAttachedPoliciesResult   []GroupPolicy
 		getUserByExternalIDResult   *User
 		updatePolicyMethodResult    *Policy
 
@@ -1200,6 +1229,22 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 				Message: "Invalid parameter: new path /**~#!/",
 			},
 		},
+		"ErrorCaseInvalidNewDescription": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:            "123",
+			policyName:     "test",
+			newPolicyName:  "test2",
+			newPath:        "/path2/",
+			newDescription: strings.Repeat("a", MAX_DESCRIPTION_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: description length %v, it can't be greater than %v",
+					MAX_DESCRIPTION_LENGTH+1, MAX_DESCRIPTION_LENGTH),
+			},
+		},
 		"ErrorCaseInvalidNewStatements": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -1455,38 +1500,40 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
-							Resources: []string{
-								CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+								},
 							},
 						},
 					},
@@ -1561,20 +1608,22 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -1672,29 +1721,31 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -1792,37 +1843,41 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -1905,37 +1960,41 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -2018,88 +2077,98 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								CreateUrn("123", RESOURCE_POLICY, "/path2/", "test2"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									CreateUrn("123", RESOURCE_POLICY, "/path2/", "test2"),
+								},
 							},
 						},
 					},
@@ -2182,71 +2251,79 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+								},
 							},
 						},
 					},
 				},
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_UPDATE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_UPDATE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("123", RESOURCE_POLICY, "/path2/"),
+								},
 							},
 						},
 					},
@@ -2259,6 +2336,157 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 				Code: UNKNOWN_API_ERROR,
 			},
 		},
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:           "123",
+			policyName:    "test",
+			newPolicyName: "test",
+			newPath:       "/path/",
+			newStatements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			version: 3,
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			updatePolicyMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 4,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:           "123",
+			policyName:    "test",
+			newPolicyName: "test",
+			newPath:       "/path/",
+			newStatements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			version: 2,
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization 123 and name test is in version 3, not in expected version 2",
+			},
+		},
+		"ErrorCaseUpdatePolicyDBVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:           "123",
+			policyName:    "test",
+			newPolicyName: "test",
+			newPath:       "/path/",
+			newStatements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			updatePolicyMethodErr: &database.Error{
+				Code: database.VERSION_MISMATCH,
+			},
+			wantError: &Error{
+				Code: VERSION_MISMATCH_ERROR,
+			},
+		},
 	}
 
 	testRepo := makeTestRepo()
@@ -2274,7 +2502,8 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		policy, err := testAPI.UpdatePolicy(testcase.requestInfo, testcase.org, testcase.policyName, testcase.newPolicyName, testcase.newPath, testcase.newStatements)
+		policy, err := testAPI.UpdatePolicy(testcase.requestInfo, testcase.org, testcase.policyName, testcase.newPolicyName, testcase.newPath,
+			testcase.newDisplayName, testcase.newDescription, testcase.newStatements, testcase.version)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.updatePolicyMethodResult, policy)
 	}
 }
@@ -2284,11 +2513,12 @@ func TestAuthAPI_RemovePolicy(t *testing.T) {
 		requestInfo RequestInfo
 		org         string
 		name        string
+		version     int64
 
 		getPolicyByNameMethodResult *Policy
 		getPolicyByNameMethodErr    error
 		getGroupsByUserIDResult     []Group
-		getAttachedPoliciesResult   []Policy
+		getAttachedPoliciesResult   []GroupPolicy
 		getUserByExternalIDResult   *User
 		getUserByExternalIDErr      error
 		deletePolicyErr             error
@@ -2398,18 +2628,20 @@ func TestAuthAPI_RemovePolicy(t *testing.T) {
 					Name: "groupUser",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
 						},
 					},
@@ -2455,36 +2687,38 @@ func TestAuthAPI_RemovePolicy(t *testing.T) {
 					Name: "groupUser",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_DELETE_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_DELETE_POLICY,
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_DELETE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_DELETE_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -2523,6 +2757,91 @@ func TestAuthAPI_RemovePolicy(t *testing.T) {
 				Code: UNKNOWN_API_ERROR,
 			},
 		},
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:     "123",
+			name:    "test",
+			version: 3,
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:     "123",
+			name:    "test",
+			version: 2,
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+				Statements: &[]Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization 123 and name test is in version 3, not in expected version 2",
+			},
+		},
+		"ErrorCaseModifiedMeanwhile": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:     "123",
+			name:    "test",
+			version: 3,
+			getPolicyByNameMethodResult: &Policy{
+				ID:      "test1",
+				Name:    "test",
+				Org:     "123",
+				Path:    "/path/",
+				Urn:     CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Version: 3,
+			},
+			deletePolicyErr: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Policy with id test1 isn't in version 3",
+			},
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "Policy with id test1 isn't in version 3",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -2537,8 +2856,12 @@ func TestAuthAPI_RemovePolicy(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		err := testAPI.RemovePolicy(testcase.requestInfo, testcase.org, testcase.name)
+		err := testAPI.RemovePolicy(testcase.requestInfo, testcase.org, testcase.name, testcase.version)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		// Check policy is removed in the version that was retrieved
+		if testcase.wantError == nil && testRepo.ArgsIn[RemovePolicyMethod][1] != testcase.getPolicyByNameMethodResult.Version {
+			t.Errorf("Test %v failed. Received different version %v", x, testRepo.ArgsIn[RemovePolicyMethod][1])
+		}
 	}
 }
 
@@ -2550,7 +2873,7 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 		expectedGroups []string
 
 		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesResult []GroupPolicy
 		getUserByExternalIDResult *User
 
 		getAttachedGroupsResult []Group
@@ -2677,18 +3000,20 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 					Name: "groupUser",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
 						},
 					},
@@ -2734,36 +3059,38 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 					Name: "groupUser",
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_GET_POLICY,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								POLICY_ACTION_LIST_ATTACHED_GROUPS,
-							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_GET_POLICY,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								POLICY_ACTION_LIST_ATTACHED_GROUPS,
+							{
+								Effect: "allow",
+								Actions: []string{
+									POLICY_ACTION_LIST_ATTACHED_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/"),
+								},
 							},
-							Resources: []string{
-								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							{
+								Effect: "deny",
+								Actions: []string{
+									POLICY_ACTION_LIST_ATTACHED_GROUPS,
+								},
+								Resources: []string{
+									GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+								},
 							},
 						},
 					},
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// PURGE IMPLEMENTATION
+
+// Remove permanently users, groups and policies deleted before deletedBefore, with all their relationships.
+// It isn't exposed in any API because it's executed periodically by the worker, not by users.
+func (api AuthAPI) PurgeDeleted(deletedBefore time.Time) error {
+	users, err := api.UserRepo.PurgeUsers(deletedBefore)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	groups, err := api.GroupRepo.PurgeGroups(deletedBefore)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	policies, err := api.PolicyRepo.PurgePolicies(deletedBefore)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if users+groups+policies > 0 {
+		api.Logger.Infof("Purged %v users, %v groups and %v policies deleted before %v",
+			users, groups, policies, deletedBefore.Format("2006-01-02 15:04:05 MST"))
+	}
+	return nil
+}
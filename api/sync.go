@@ -0,0 +1,549 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Sync operations
+	SYNC_OPERATION_CREATE = "create"
+	SYNC_OPERATION_UPDATE = "update"
+	SYNC_OPERATION_DELETE = "delete"
+
+	// Sync entities, besides groups and policies
+	SYNC_ENTITY_MEMBER     = "member"
+	SYNC_ENTITY_ATTACHMENT = "attachment"
+)
+
+// TYPE DEFINITIONS
+
+// Desired state document with the IAM entities that have to exist per organization
+type DesiredState struct {
+	Organizations []OrganizationState `json:"organizations, omitempty"`
+}
+
+// Groups and policies that have to exist in an organization
+type OrganizationState struct {
+	Org      string        `json:"org, omitempty"`
+	Groups   []GroupState  `json:"groups, omitempty"`
+	Policies []PolicyState `json:"policies, omitempty"`
+}
+
+// Group with its members (user external identifiers) and attached policy names
+type GroupState struct {
+	Name     string   `json:"name, omitempty"`
+	Path     string   `json:"path, omitempty"`
+	Members  []string `json:"members, omitempty"`
+	Policies []string `json:"policies, omitempty"`
+}
+
+type PolicyState struct {
+	Name       string      `json:"name, omitempty"`
+	Path       string      `json:"path, omitempty"`
+	Statements []Statement `json:"statements, omitempty"`
+}
+
+// Single change needed to reach the desired state. Group, Policy and User hold the entities used to apply it.
+type SyncChange struct {
+	Operation string `json:"operation, omitempty"`
+	Entity    string `json:"entity, omitempty"`
+	Org       string `json:"org, omitempty"`
+	Name      string `json:"name, omitempty"`
+	Target    string `json:"target, omitempty"`
+
+	Group  *Group  `json:"-"`
+	Policy *Policy `json:"-"`
+	User   *User   `json:"-"`
+}
+
+func (c SyncChange) String() string {
+	return fmt.Sprintf("[operation: %v, entity: %v, org: %v, name: %v, target: %v]",
+		c.Operation, c.Entity, c.Org, c.Name, c.Target)
+}
+
+// Ordered list of changes. Applied is true when the changes have been stored in database.
+type SyncPlan struct {
+	Changes []SyncChange `json:"changes"`
+	Applied bool         `json:"applied"`
+}
+
+// SYNC API IMPLEMENTATION
+
+func (api AuthAPI) PlanSync(requestInfo RequestInfo, state DesiredState, keepUnmanaged bool) (*SyncPlan, error) {
+	changes, err := api.getSyncChanges(requestInfo, state, keepUnmanaged)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyncPlan{
+		Changes: changes,
+		Applied: false,
+	}, nil
+}
+
+func (api AuthAPI) ApplySync(requestInfo RequestInfo, state DesiredState, keepUnmanaged bool) (*SyncPlan, error) {
+	changes, err := api.getSyncChanges(requestInfo, state, keepUnmanaged)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		setSyncAuditAttributes(requestInfo, changes)
+
+		// Apply all changes in the same transaction
+		if err := api.SyncRepo.ApplySyncPlan(changes); err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		if err := api.recordChange(requestInfo, SYNC_ACTION_APPLY_SYNC, "", nil, changes); err != nil {
+			return nil, err
+		}
+		LogOperation(api.Logger, requestInfo, fmt.Sprintf("Sync plan applied %v", changes))
+	}
+
+	return &SyncPlan{
+		Changes: changes,
+		Applied: true,
+	}, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Compute the ordered changes for all organizations in the document, checking authorization for every one
+func (api AuthAPI) getSyncChanges(requestInfo RequestInfo, state DesiredState, keepUnmanaged bool) ([]SyncChange, error) {
+	if err := validateDesiredState(state); err != nil {
+		return nil, err
+	}
+
+	// Entity creations and updates go first, then relations and finally entity deletions
+	entityChanges := []SyncChange{}
+	relationChanges := []SyncChange{}
+	deleteChanges := []SyncChange{}
+	for _, orgState := range state.Organizations {
+		entities, relations, deletes, err := api.getOrgSyncChanges(orgState, keepUnmanaged)
+		if err != nil {
+			return nil, err
+		}
+		entityChanges = append(entityChanges, entities...)
+		relationChanges = append(relationChanges, relations...)
+		deleteChanges = append(deleteChanges, deletes...)
+	}
+
+	changes := append(append(entityChanges, relationChanges...), deleteChanges...)
+
+	// Check restrictions
+	for _, change := range changes {
+		if err := api.authorizeSyncChange(requestInfo, change); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+func (api AuthAPI) getOrgSyncChanges(orgState OrganizationState, keepUnmanaged bool) ([]SyncChange, []SyncChange, []SyncChange, error) {
+	org := orgState.Org
+	entityChanges := []SyncChange{}
+	relationChanges := []SyncChange{}
+	deleteChanges := []SyncChange{}
+
+	// Retrieve current state of organization
+	currentGroups, err := api.GroupRepo.GetGroupsFiltered(org, "")
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, nil, nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	currentPolicies, err := api.PolicyRepo.GetPoliciesFiltered(org, "")
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, nil, nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	groupsByName := map[string]Group{}
+	for _, g := range currentGroups {
+		groupsByName[g.Name] = g
+	}
+	policiesByName := map[string]Policy{}
+	for _, p := range currentPolicies {
+		policiesByName[p.Name] = p
+	}
+
+	// Policies
+	desiredPolicies := map[string]Policy{}
+	for _, policyState := range orgState.Policies {
+		statements := policyState.Statements
+		current, exists := policiesByName[policyState.Name]
+		switch {
+		case !exists:
+			policy := createPolicy(policyState.Name, policyState.Path, org, &statements)
+			entityChanges = append(entityChanges, newSyncPolicyChange(SYNC_OPERATION_CREATE, policy))
+			desiredPolicies[policy.Name] = policy
+		case current.Path != policyState.Path || current.Statements == nil || !reflect.DeepEqual(*current.Statements, statements):
+			policy := current
+			policy.Path = policyState.Path
+			policy.Urn = CreateUrn(org, RESOURCE_POLICY, policyState.Path, policyState.Name)
+			policy.Statements = &statements
+			entityChanges = append(entityChanges, newSyncPolicyChange(SYNC_OPERATION_UPDATE, policy))
+			desiredPolicies[policy.Name] = policy
+		default:
+			desiredPolicies[current.Name] = current
+		}
+	}
+
+	// Groups
+	desiredGroups := map[string]bool{}
+	for _, groupState := range orgState.Groups {
+		current, exists := groupsByName[groupState.Name]
+		group := current
+		switch {
+		case !exists:
+			group = createGroup(org, groupState.Name, groupState.Path)
+			entityChanges = append(entityChanges, newSyncGroupChange(SYNC_OPERATION_CREATE, group))
+		case current.Path != groupState.Path:
+			group.Path = groupState.Path
+			group.Urn = CreateUrn(org, RESOURCE_GROUP, groupState.Path, groupState.Name)
+			entityChanges = append(entityChanges, newSyncGroupChange(SYNC_OPERATION_UPDATE, group))
+		}
+		desiredGroups[group.Name] = true
+
+		// Current relations of group
+		members := []GroupMember{}
+		attachedPolicies := []GroupPolicy{}
+		if exists {
+			members, err = api.GroupRepo.GetGroupMembers(group.ID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, nil, nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			attachedPolicies, err = api.GroupRepo.GetAttachedPolicies(group.ID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, nil, nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+		}
+		currentMembers := map[string]bool{}
+		for _, m := range members {
+			currentMembers[m.User.ExternalID] = true
+		}
+		currentAttachments := map[string]bool{}
+		for _, p := range attachedPolicies {
+			currentAttachments[p.Policy.Name] = true
+		}
+
+		// Members
+		desiredMembers := map[string]bool{}
+		for _, externalID := range groupState.Members {
+			desiredMembers[externalID] = true
+			if currentMembers[externalID] {
+				continue
+			}
+			user, err := api.UserRepo.GetUserByExternalID(externalID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				if dbError.Code == database.USER_NOT_FOUND {
+					return nil, nil, nil, &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid parameter: member %v of group %v doesn't exist", externalID, group.Name),
+					}
+				}
+				return nil, nil, nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			relationChanges = append(relationChanges, newSyncMemberChange(SYNC_OPERATION_CREATE, group, *user))
+		}
+		if !keepUnmanaged {
+			for _, member := range members {
+				if !desiredMembers[member.User.ExternalID] {
+					relationChanges = append(relationChanges, newSyncMemberChange(SYNC_OPERATION_DELETE, group, member.User))
+				}
+			}
+		}
+
+		// Attachments. Policies not present in the document only survive if unmanaged entities are kept
+		desiredAttachments := map[string]bool{}
+		for _, policyName := range groupState.Policies {
+			desiredAttachments[policyName] = true
+			policy, ok := desiredPolicies[policyName]
+			if !ok && keepUnmanaged {
+				policy, ok = policiesByName[policyName]
+			}
+			if !ok {
+				return nil, nil, nil, &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy %v attached to group %v doesn't exist", policyName, group.Name),
+				}
+			}
+			if currentAttachments[policyName] {
+				continue
+			}
+			relationChanges = append(relationChanges, newSyncAttachmentChange(SYNC_OPERATION_CREATE, group, policy))
+		}
+		if !keepUnmanaged {
+			for _, attachment := range attachedPolicies {
+				if !desiredAttachments[attachment.Policy.Name] {
+					relationChanges = append(relationChanges, newSyncAttachmentChange(SYNC_OPERATION_DELETE, group, attachment.Policy))
+				}
+			}
+		}
+	}
+
+	// Unmanaged entities
+	if !keepUnmanaged {
+		for _, g := range currentGroups {
+			if !desiredGroups[g.Name] {
+				deleteChanges = append(deleteChanges, newSyncGroupChange(SYNC_OPERATION_DELETE, g))
+			}
+		}
+		for _, p := range currentPolicies {
+			if _, ok := desiredPolicies[p.Name]; !ok {
+				deleteChanges = append(deleteChanges, newSyncPolicyChange(SYNC_OPERATION_DELETE, p))
+			}
+		}
+	}
+
+	return entityChanges, relationChanges, deleteChanges, nil
+}
+
+// Check if user is allowed to apply a change over its group or policy
+func (api AuthAPI) authorizeSyncChange(requestInfo RequestInfo, change SyncChange) error {
+	var action string
+	switch change.Entity {
+	case RESOURCE_GROUP:
+		action = map[string]string{
+			SYNC_OPERATION_CREATE: GROUP_ACTION_CREATE_GROUP,
+			SYNC_OPERATION_UPDATE: GROUP_ACTION_UPDATE_GROUP,
+			SYNC_OPERATION_DELETE: GROUP_ACTION_DELETE_GROUP,
+		}[change.Operation]
+	case SYNC_ENTITY_MEMBER:
+		action = map[string]string{
+			SYNC_OPERATION_CREATE: GROUP_ACTION_ADD_MEMBER,
+			SYNC_OPERATION_DELETE: GROUP_ACTION_REMOVE_MEMBER,
+		}[change.Operation]
+	case SYNC_ENTITY_ATTACHMENT:
+		action = map[string]string{
+			SYNC_OPERATION_CREATE: GROUP_ACTION_ATTACH_GROUP_POLICY,
+			SYNC_OPERATION_DELETE: GROUP_ACTION_DETACH_GROUP_POLICY,
+		}[change.Operation]
+	case RESOURCE_POLICY:
+		action = map[string]string{
+			SYNC_OPERATION_CREATE: POLICY_ACTION_CREATE_POLICY,
+			SYNC_OPERATION_UPDATE: POLICY_ACTION_UPDATE_POLICY,
+			SYNC_OPERATION_DELETE: POLICY_ACTION_DELETE_POLICY,
+		}[change.Operation]
+	}
+
+	var urn string
+	var allowed bool
+	if change.Entity == RESOURCE_POLICY {
+		urn = change.Policy.Urn
+		policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, urn, action, []Policy{*change.Policy})
+		if err != nil {
+			return err
+		}
+		allowed = len(policiesFiltered) > 0
+	} else {
+		urn = change.Group.Urn
+		groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, urn, action, []Group{*change.Group})
+		if err != nil {
+			return err
+		}
+		allowed = len(groupsFiltered) > 0
+	}
+
+	if !allowed {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, urn),
+		}
+	}
+	return nil
+}
+
+func validateDesiredState(state DesiredState) error {
+	orgs := map[string]bool{}
+	for _, orgState := range state.Organizations {
+		if !IsValidOrg(orgState.Org) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: org %v", orgState.Org),
+			}
+		}
+		if orgs[orgState.Org] {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: org %v is duplicated", orgState.Org),
+			}
+		}
+		orgs[orgState.Org] = true
+
+		groups := map[string]bool{}
+		for _, g := range orgState.Groups {
+			if !IsValidName(g.Name) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: group name %v", g.Name),
+				}
+			}
+			if !IsValidPath(g.Path) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: group path %v", g.Path),
+				}
+			}
+			if groups[g.Name] {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: group %v is duplicated in org %v", g.Name, orgState.Org),
+				}
+			}
+			groups[g.Name] = true
+			for _, m := range g.Members {
+				if !IsValidUserExternalID(m) {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid parameter: member %v", m),
+					}
+				}
+			}
+			for _, p := range g.Policies {
+				if !IsValidName(p) {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid parameter: policy name %v", p),
+					}
+				}
+			}
+		}
+
+		policies := map[string]bool{}
+		for _, p := range orgState.Policies {
+			if !IsValidName(p.Name) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy name %v", p.Name),
+				}
+			}
+			if !IsValidPath(p.Path) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy path %v", p.Path),
+				}
+			}
+			if policies[p.Name] {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy %v is duplicated in org %v", p.Name, orgState.Org),
+				}
+			}
+			policies[p.Name] = true
+			statements := p.Statements
+			if err := AreValidStatements(&statements); err != nil {
+				apiError := err.(*Error)
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: apiError.Message,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Record who creates or updates the groups and policies of the changes
+func setSyncAuditAttributes(requestInfo RequestInfo, changes []SyncChange) {
+	now := time.Now().UTC()
+	for _, change := range changes {
+		if change.Operation == SYNC_OPERATION_DELETE {
+			continue
+		}
+		// Only entity changes, relation changes keep the entities as they are
+		switch change.Entity {
+		case RESOURCE_GROUP:
+			if change.Operation == SYNC_OPERATION_CREATE {
+				change.Group.CreatedBy = requestInfo.Identifier
+			} else {
+				change.Group.UpdateAt = now
+			}
+			change.Group.UpdatedBy = requestInfo.Identifier
+		case RESOURCE_POLICY:
+			if change.Operation == SYNC_OPERATION_CREATE {
+				change.Policy.CreatedBy = requestInfo.Identifier
+			} else {
+				change.Policy.UpdateAt = now
+			}
+			change.Policy.UpdatedBy = requestInfo.Identifier
+		}
+	}
+}
+
+func newSyncGroupChange(operation string, group Group) SyncChange {
+	return SyncChange{
+		Operation: operation,
+		Entity:    RESOURCE_GROUP,
+		Org:       group.Org,
+		Name:      group.Name,
+		Group:     &group,
+	}
+}
+
+func newSyncPolicyChange(operation string, policy Policy) SyncChange {
+	return SyncChange{
+		Operation: operation,
+		Entity:    RESOURCE_POLICY,
+		Org:       policy.Org,
+		Name:      policy.Name,
+		Policy:    &policy,
+	}
+}
+
+func newSyncMemberChange(operation string, group Group, user User) SyncChange {
+	return SyncChange{
+		Operation: operation,
+		Entity:    SYNC_ENTITY_MEMBER,
+		Org:       group.Org,
+		Name:      group.Name,
+		Target:    user.ExternalID,
+		Group:     &group,
+		User:      &user,
+	}
+}
+
+func newSyncAttachmentChange(operation string, group Group, policy Policy) SyncChange {
+	return SyncChange{
+		Operation: operation,
+		Entity:    SYNC_ENTITY_ATTACHMENT,
+		Org:       group.Org,
+		Name:      group.Name,
+		Target:    policy.Name,
+		Group:     &group,
+		Policy:    &policy,
+	}
+}
@@ -0,0 +1,627 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ApplySync(t *testing.T) {
+	statements := []Statement{
+		{
+			Effect: "allow",
+			Actions: []string{
+				USER_ACTION_GET_USER,
+			},
+			Resources: []string{
+				GetUrnPrefix("", RESOURCE_USER, "/path/"),
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo   RequestInfo
+		state         DesiredState
+		keepUnmanaged bool
+		dryRun        bool
+		// Expected results
+		expectedChanges []SyncChange
+		expectedApplied bool
+		wantError       error
+		// Manager Results
+		getGroupsFilteredResult   []Group
+		getPoliciesFilteredResult []Policy
+		getGroupMembersResult     []GroupMember
+		getAttachedPoliciesResult []GroupPolicy
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		// Manager Errors
+		getGroupsFilteredMethodErr   error
+		getUserByExternalIDMethodErr error
+		applySyncPlanMethodErr       error
+	}{
+		"OKCaseCreateAll": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name:     "group1",
+								Path:     "/path/",
+								Members:  []string{"user1"},
+								Policies: []string{"policy1"},
+							},
+						},
+						Policies: []PolicyState{
+							{
+								Name:       "policy1",
+								Path:       "/path/",
+								Statements: statements,
+							},
+						},
+					},
+				},
+			},
+			expectedChanges: []SyncChange{
+				{
+					Operation: SYNC_OPERATION_CREATE,
+					Entity:    RESOURCE_POLICY,
+					Org:       "org1",
+					Name:      "policy1",
+				},
+				{
+					Operation: SYNC_OPERATION_CREATE,
+					Entity:    RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group1",
+				},
+				{
+					Operation: SYNC_OPERATION_CREATE,
+					Entity:    SYNC_ENTITY_MEMBER,
+					Org:       "org1",
+					Name:      "group1",
+					Target:    "user1",
+				},
+				{
+					Operation: SYNC_OPERATION_CREATE,
+					Entity:    SYNC_ENTITY_ATTACHMENT,
+					Org:       "org1",
+					Name:      "group1",
+					Target:    "policy1",
+				},
+			},
+			expectedApplied: true,
+			getUserByExternalIDResult: &User{
+				ID:         "USER1",
+				ExternalID: "user1",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+		},
+		"OKCaseDryRun": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name: "group1",
+								Path: "/newpath/",
+							},
+						},
+					},
+				},
+			},
+			dryRun: true,
+			expectedChanges: []SyncChange{
+				{
+					Operation: SYNC_OPERATION_UPDATE,
+					Entity:    RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group1",
+				},
+			},
+			expectedApplied: false,
+			getGroupsFilteredResult: []Group{
+				{
+					ID:   "GROUP1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+		},
+		"OKCaseNoChanges": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name:     "group1",
+								Path:     "/path/",
+								Members:  []string{"user1"},
+								Policies: []string{"policy1"},
+							},
+						},
+						Policies: []PolicyState{
+							{
+								Name:       "policy1",
+								Path:       "/path/",
+								Statements: statements,
+							},
+						},
+					},
+				},
+			},
+			expectedChanges: []SyncChange{},
+			expectedApplied: true,
+			getGroupsFilteredResult: []Group{
+				{
+					ID:   "GROUP1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			getPoliciesFilteredResult: []Policy{
+				{
+					ID:         "POLICY1",
+					Name:       "policy1",
+					Org:        "org1",
+					Path:       "/path/",
+					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+					Statements: &statements,
+				},
+			},
+			getGroupMembersResult: []GroupMember{
+				{
+					User: User{
+						ID:         "USER1",
+						ExternalID: "user1",
+						Path:       "/path/",
+						Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:         "POLICY1",
+						Name:       "policy1",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+						Statements: &statements,
+					},
+				},
+			},
+		},
+		"OKCaseDeleteUnmanaged": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name: "group1",
+								Path: "/path/",
+							},
+						},
+					},
+				},
+			},
+			expectedChanges: []SyncChange{
+				{
+					Operation: SYNC_OPERATION_DELETE,
+					Entity:    SYNC_ENTITY_MEMBER,
+					Org:       "org1",
+					Name:      "group1",
+					Target:    "user1",
+				},
+				{
+					Operation: SYNC_OPERATION_DELETE,
+					Entity:    SYNC_ENTITY_ATTACHMENT,
+					Org:       "org1",
+					Name:      "group1",
+					Target:    "policy1",
+				},
+				{
+					Operation: SYNC_OPERATION_DELETE,
+					Entity:    RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group2",
+				},
+				{
+					Operation: SYNC_OPERATION_DELETE,
+					Entity:    RESOURCE_POLICY,
+					Org:       "org1",
+					Name:      "policy1",
+				},
+			},
+			expectedApplied: true,
+			getGroupsFilteredResult: []Group{
+				{
+					ID:   "GROUP1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+				{
+					ID:   "GROUP2",
+					Name: "group2",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group2"),
+				},
+			},
+			getPoliciesFilteredResult: []Policy{
+				{
+					ID:         "POLICY1",
+					Name:       "policy1",
+					Org:        "org1",
+					Path:       "/path/",
+					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+					Statements: &statements,
+				},
+			},
+			getGroupMembersResult: []GroupMember{
+				{
+					User: User{
+						ID:         "USER1",
+						ExternalID: "user1",
+						Path:       "/path/",
+						Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:         "POLICY1",
+						Name:       "policy1",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+						Statements: &statements,
+					},
+				},
+			},
+		},
+		"OKCaseKeepUnmanaged": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name:     "group1",
+								Path:     "/path/",
+								Policies: []string{"policy1"},
+							},
+						},
+					},
+				},
+			},
+			keepUnmanaged:   true,
+			expectedChanges: []SyncChange{},
+			expectedApplied: true,
+			getGroupsFilteredResult: []Group{
+				{
+					ID:   "GROUP1",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+				{
+					ID:   "GROUP2",
+					Name: "group2",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group2"),
+				},
+			},
+			getPoliciesFilteredResult: []Policy{
+				{
+					ID:         "POLICY1",
+					Name:       "policy1",
+					Org:        "org1",
+					Path:       "/path/",
+					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+					Statements: &statements,
+				},
+			},
+			getGroupMembersResult: []GroupMember{
+				{
+					User: User{
+						ID:         "USER1",
+						ExternalID: "user1",
+						Path:       "/path/",
+						Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:         "POLICY1",
+						Name:       "policy1",
+						Org:        "org1",
+						Path:       "/path/",
+						Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+						Statements: &statements,
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "*%~#@|",
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org *%~#@|",
+			},
+		},
+		"ErrorCaseDuplicatedGroup": {
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name: "group1",
+								Path: "/path/",
+							},
+							{
+								Name: "group1",
+								Path: "/path2/",
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: group group1 is duplicated in org org1",
+			},
+		},
+		"ErrorCaseInvalidStatement": {
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Policies: []PolicyState{
+							{
+								Name: "policy1",
+								Path: "/path/",
+								Statements: []Statement{
+									{
+										Effect: "idontexist",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid effect: idontexist - Only 'allow' and 'deny' accepted",
+			},
+		},
+		"ErrorCaseMemberNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name:    "group1",
+								Path:    "/path/",
+								Members: []string{"user1"},
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: member user1 of group group1 doesn't exist",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseAttachedPolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name:     "group1",
+								Path:     "/path/",
+								Policies: []string{"policy1"},
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: policy policy1 attached to group group1 doesn't exist",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name: "group1",
+								Path: "/path/",
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource " +
+					CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseGetGroupsFilteredDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+					},
+				},
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getGroupsFilteredMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseApplySyncPlanDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			state: DesiredState{
+				Organizations: []OrganizationState{
+					{
+						Org: "org1",
+						Groups: []GroupState{
+							{
+								Name: "group1",
+								Path: "/path/",
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			applySyncPlanMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = testcase.getGroupsFilteredResult
+		testRepo.ArgsOut[GetGroupsFilteredMethod][1] = testcase.getGroupsFilteredMethodErr
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = testcase.getPoliciesFilteredResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = testcase.getGroupMembersResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[ApplySyncPlanMethod][0] = testcase.applySyncPlanMethodErr
+
+		var plan *SyncPlan
+		var err error
+		if testcase.dryRun {
+			plan, err = testAPI.PlanSync(testcase.requestInfo, testcase.state, testcase.keepUnmanaged)
+		} else {
+			plan, err = testAPI.ApplySync(testcase.requestInfo, testcase.state, testcase.keepUnmanaged)
+		}
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed: %v", x, err)
+			continue
+		}
+
+		// Entities are not compared because created ones have random identifiers
+		changes := []SyncChange{}
+		for _, c := range plan.Changes {
+			changes = append(changes, SyncChange{
+				Operation: c.Operation,
+				Entity:    c.Entity,
+				Org:       c.Org,
+				Name:      c.Name,
+				Target:    c.Target,
+			})
+		}
+		if diff := pretty.Compare(changes, testcase.expectedChanges); diff != "" {
+			t.Errorf("Test %v failed. Received different changes (received/wanted) %v", x, diff)
+			continue
+		}
+		if plan.Applied != testcase.expectedApplied {
+			t.Errorf("Test %v failed. Received different applied flag (wanted:%v / received:%v)", x, testcase.expectedApplied, plan.Applied)
+			continue
+		}
+
+		// Check that repository is only called when there are changes to apply
+		applied := testRepo.ArgsIn[ApplySyncPlanMethod][0] != nil
+		if applied != (testcase.expectedApplied && len(testcase.expectedChanges) > 0) {
+			t.Errorf("Test %v failed. Unexpected call to ApplySyncPlan: %v", x, applied)
+			continue
+		}
+	}
+}
@@ -3,12 +3,15 @@ package api
 import (
 	"github.com/Sirupsen/logrus"
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 const (
 	GetUserByExternalIDMethod = "GetUserByExternalID"
+	GetUserByIDMethod         = "GetUserByID"
 	AddUserMethod             = "AddUser"
 	UpdateUserMethod          = "UpdateUser"
 	GetUsersFilteredMethod    = "GetUsersFiltered"
@@ -24,15 +27,93 @@ const (
 	AddGroupMethod            = "AddGroup"
 	AddMemberMethod           = "AddMember"
 	RemoveMemberMethod        = "RemoveMember"
+	AddMappedMemberMethod     = "AddMappedMember"
+	GetMappedGroupsMethod     = "GetMappedGroupsByUserID"
+	AddMembersMethod          = "AddMembers"
+	RemoveMembersMethod       = "RemoveMembers"
 	UpdateGroupMethod         = "UpdateGroup"
 	AttachPolicyMethod        = "AttachPolicy"
 	DetachPolicyMethod        = "DetachPolicy"
+	AttachPoliciesMethod      = "AttachPolicies"
+	DetachPoliciesMethod      = "DetachPolicies"
 	GetPolicyByNameMethod     = "GetPolicyByName"
 	AddPolicyMethod           = "AddPolicy"
 	UpdatePolicyMethod        = "UpdatePolicy"
 	RemovePolicyMethod        = "RemovePolicy"
 	GetPoliciesFilteredMethod = "GetPoliciesFiltered"
 	GetAttachedGroupsMethod   = "GetAttachedGroups"
+	ApplySyncPlanMethod       = "ApplySyncPlan"
+
+	AddOrganizationMethod       = "AddOrganization"
+	GetOrganizationByNameMethod = "GetOrganizationByName"
+	GetOrganizationsMethod      = "GetOrganizations"
+	RemoveOrganizationMethod    = "RemoveOrganization"
+
+	GetDeletedUserByExternalIDMethod = "GetDeletedUserByExternalID"
+	GetDeletedUsersMethod            = "GetDeletedUsers"
+	RestoreUserMethod                = "RestoreUser"
+	PurgeUsersMethod                 = "PurgeUsers"
+	RenameUserMethod                 = "RenameUser"
+	MergeUsersMethod                 = "MergeUsers"
+	UpdateUserStatusMethod           = "UpdateUserStatus"
+	GetDeletedGroupByNameMethod      = "GetDeletedGroupByName"
+	GetDeletedGroupsMethod           = "GetDeletedGroups"
+	RestoreGroupMethod               = "RestoreGroup"
+	PurgeGroupsMethod                = "PurgeGroups"
+	RemoveExpiredMembersMethod       = "RemoveExpiredMembers"
+	GetDeletedPolicyByNameMethod     = "GetDeletedPolicyByName"
+	GetDeletedPoliciesMethod         = "GetDeletedPolicies"
+	RestorePolicyMethod              = "RestorePolicy"
+	PurgePoliciesMethod              = "PurgePolicies"
+
+	AddAccessRequestMethod           = "AddAccessRequest"
+	GetAccessRequestByIDMethod       = "GetAccessRequestByID"
+	GetAccessRequestsByGroupIDMethod = "GetAccessRequestsByGroupID"
+	ReviewAccessRequestMethod        = "ReviewAccessRequest"
+
+	AddAuditEventMethod          = "AddAuditEvent"
+	GetAuditEventsFilteredMethod = "GetAuditEventsFiltered"
+
+	AddWebhookMethod                  = "AddWebhook"
+	GetWebhookByIDMethod              = "GetWebhookByID"
+	GetWebhooksMethod                 = "GetWebhooks"
+	UpdateWebhookMethod               = "UpdateWebhook"
+	RemoveWebhookMethod               = "RemoveWebhook"
+	AddWebhookDeliveriesMethod        = "AddWebhookDeliveries"
+	GetWebhookDeliveriesMethod        = "GetWebhookDeliveries"
+	GetPendingWebhookDeliveriesMethod = "GetPendingWebhookDeliveries"
+	UpdateWebhookDeliveryMethod       = "UpdateWebhookDelivery"
+
+	AddChangeMethod  = "AddChange"
+	GetChangesMethod = "GetChanges"
+
+	AddApiKeyMethod          = "AddApiKey"
+	GetApiKeysByUserIDMethod = "GetApiKeysByUserID"
+	GetApiKeyByIDMethod      = "GetApiKeyByID"
+	GetApiKeyByHashMethod    = "GetApiKeyByHash"
+	UpdateApiKeyMethod       = "UpdateApiKey"
+	RemoveApiKeyMethod       = "RemoveApiKey"
+
+	SetAdminCredentialsMethod               = "SetAdminCredentials"
+	GetAdminCredentialsMethod               = "GetAdminCredentials"
+	IncrementAdminCredentialsAttemptsMethod = "IncrementAdminCredentialsAttempts"
+	UpdateAdminCredentialsAttemptsMethod    = "UpdateAdminCredentialsAttempts"
+	GetAdminsMethod                         = "GetAdmins"
+	RemoveAdminCredentialsMethod            = "RemoveAdminCredentials"
+
+	AddGroupMappingMethod     = "AddGroupMapping"
+	GetGroupMappingByIDMethod = "GetGroupMappingByID"
+	GetGroupMappingsMethod    = "GetGroupMappings"
+	RemoveGroupMappingMethod  = "RemoveGroupMapping"
+
+	SetUserCredentialsMethod               = "SetUserCredentials"
+	GetUserCredentialsMethod               = "GetUserCredentials"
+	IncrementUserCredentialsAttemptsMethod = "IncrementUserCredentialsAttempts"
+	UpdateUserCredentialsAttemptsMethod    = "UpdateUserCredentialsAttempts"
+	AddSessionMethod                       = "AddSession"
+	GetSessionByHashMethod                 = "GetSessionByHash"
+	RemoveSessionMethod                    = "RemoveSession"
+	RemoveExpiredSessionsMethod            = "RemoveExpiredSessions"
 )
 
 // TestRepo that implements all repo manager interfaces
@@ -50,32 +131,108 @@ func makeTestRepo() *TestRepo {
 		SpecialFuncs: make(map[string]interface{}),
 	}
 	testRepo.ArgsIn[GetUserByExternalIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetUserByIDMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[AddUserMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[UpdateUserMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[UpdateUserMethod] = make([]interface{}, 6)
 	testRepo.ArgsIn[GetUsersFilteredMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[GetGroupsByUserIDMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[RemoveUserMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveUserMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetGroupByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[IsMemberOfGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetGroupMembersMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[IsAttachedToGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetAttachedPoliciesMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[GetGroupsFilteredMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[RemoveGroupMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[AddGroupMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[AddMemberMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddMemberMethod] = make([]interface{}, 3)
 	testRepo.ArgsIn[RemoveMemberMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[UpdateGroupMethod] = make([]interface{}, 4)
-	testRepo.ArgsIn[AttachPolicyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddMappedMemberMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetMappedGroupsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddMembersMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RemoveMembersMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdateGroupMethod] = make([]interface{}, 7)
+	testRepo.ArgsIn[AttachPolicyMethod] = make([]interface{}, 4)
 	testRepo.ArgsIn[DetachPolicyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AttachPoliciesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[DetachPoliciesMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetPolicyByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[AddPolicyMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 5)
-	testRepo.ArgsIn[RemovePolicyMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 8)
+	testRepo.ArgsIn[RemovePolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetPoliciesFilteredMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetAttachedGroupsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[ApplySyncPlanMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddOrganizationMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetOrganizationByNameMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetOrganizationsMethod] = make([]interface{}, 0)
+	testRepo.ArgsIn[RemoveOrganizationMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddAccessRequestMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAccessRequestByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAccessRequestsByGroupIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[ReviewAccessRequestMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[AddAuditEventMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAuditEventsFilteredMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[AddWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhookByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhooksMethod] = make([]interface{}, 0)
+	testRepo.ArgsIn[UpdateWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddWebhookDeliveriesMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhookDeliveriesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetPendingWebhookDeliveriesMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateWebhookDeliveryMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[AddApiKeyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetApiKeysByUserIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetApiKeyByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetApiKeyByHashMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateApiKeyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RemoveApiKeyMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[SetAdminCredentialsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetAdminCredentialsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[IncrementAdminCredentialsAttemptsMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[UpdateAdminCredentialsAttemptsMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[RemoveAdminCredentialsMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[AddGroupMappingMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetGroupMappingByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetGroupMappingsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveGroupMappingMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[SetUserCredentialsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetUserCredentialsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[IncrementUserCredentialsAttemptsMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[UpdateUserCredentialsAttemptsMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[AddSessionMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetSessionByHashMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveSessionMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveExpiredSessionsMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsIn[AddChangeMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetChangesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetDeletedUserByExternalIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetDeletedUsersMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RestoreUserMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[PurgeUsersMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RenameUserMethod] = make([]interface{}, 4)
+	testRepo.ArgsIn[MergeUsersMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdateUserStatusMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[GetDeletedGroupByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetDeletedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RestoreGroupMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[PurgeGroupsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveExpiredMembersMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetDeletedPolicyByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetDeletedPoliciesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RestorePolicyMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[PurgePoliciesMethod] = make([]interface{}, 1)
 
 	testRepo.ArgsOut[GetUserByExternalIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetUserByIDMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddUserMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[UpdateUserMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[GetUsersFilteredMethod] = make([]interface{}, 2)
@@ -91,25 +248,120 @@ func makeTestRepo() *TestRepo {
 	testRepo.ArgsOut[AddGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddMemberMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[RemoveMemberMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddMappedMemberMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetMappedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[AddMembersMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[RemoveMembersMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[UpdateGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AttachPolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[DetachPolicyMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AttachPoliciesMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[DetachPoliciesMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[GetPolicyByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddPolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[GetPoliciesFilteredMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[GetAttachedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[ApplySyncPlanMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddOrganizationMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetOrganizationByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetOrganizationsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveOrganizationMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddAccessRequestMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAccessRequestByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAccessRequestsByGroupIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[ReviewAccessRequestMethod] = make([]interface{}, 2)
+
+	testRepo.ArgsOut[AddAuditEventMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAuditEventsFilteredMethod] = make([]interface{}, 2)
+
+	testRepo.ArgsOut[AddWebhookMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetWebhooksMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateWebhookMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddWebhookDeliveriesMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetWebhookDeliveriesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetPendingWebhookDeliveriesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateWebhookDeliveryMethod] = make([]interface{}, 2)
+
+	testRepo.ArgsOut[AddApiKeyMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetApiKeysByUserIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetApiKeyByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetApiKeyByHashMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateApiKeyMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveApiKeyMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsOut[SetAdminCredentialsMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetAdminCredentialsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[IncrementAdminCredentialsAttemptsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateAdminCredentialsAttemptsMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetAdminsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveAdminCredentialsMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsOut[AddGroupMappingMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetGroupMappingByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetGroupMappingsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveGroupMappingMethod] = make([]interface{}, 1)
+
+	testRepo.ArgsOut[SetUserCredentialsMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetUserCredentialsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[IncrementUserCredentialsAttemptsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateUserCredentialsAttemptsMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddSessionMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetSessionByHashMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveSessionMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[RemoveExpiredSessionsMethod] = make([]interface{}, 2)
+
+	testRepo.ArgsOut[AddChangeMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetChangesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedUserByExternalIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedUsersMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RestoreUserMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[PurgeUsersMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RenameUserMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[MergeUsersMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[UpdateUserStatusMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedGroupByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RestoreGroupMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[PurgeGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveExpiredMembersMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedPolicyByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetDeletedPoliciesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RestorePolicyMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[PurgePoliciesMethod] = make([]interface{}, 2)
+
+	// By default there aren't deleted entities
+	testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][1] = &database.Error{Code: database.USER_NOT_FOUND}
+	testRepo.ArgsOut[GetDeletedGroupByNameMethod][1] = &database.Error{Code: database.GROUP_NOT_FOUND}
+	testRepo.ArgsOut[GetDeletedPolicyByNameMethod][1] = &database.Error{Code: database.POLICY_NOT_FOUND}
 
 	return testRepo
 }
 
 func makeTestAPI(testRepo *TestRepo) *AuthAPI {
 	api := &AuthAPI{
-		UserRepo:   testRepo,
-		GroupRepo:  testRepo,
-		PolicyRepo: testRepo,
-		Logger:     logrus.StandardLogger(),
+		UserRepo:          testRepo,
+		GroupRepo:         testRepo,
+		PolicyRepo:        testRepo,
+		OrganizationRepo:  testRepo,
+		SyncRepo:          testRepo,
+		AccessRequestRepo: testRepo,
+		AuditRepo:         testRepo,
+		WebhookRepo:       testRepo,
+		ChangeRepo:        testRepo,
+		ApiKeyRepo:        testRepo,
+		AdminRepo:         testRepo,
+		GroupMappingRepo:  testRepo,
+		PasswordRepo:      testRepo,
+		PasswordConfig: PasswordConfig{
+			MaxFailedAttempts: DEFAULT_MAX_FAILED_ATTEMPTS,
+			LockoutDuration:   DEFAULT_LOCKOUT_DURATION,
+			SessionDuration:   DEFAULT_SESSION_DURATION,
+		},
+		Logger: logrus.StandardLogger(),
 	}
 	return api
 }
@@ -134,6 +386,19 @@ func (t TestRepo) GetUserByExternalID(id string) (*User, error) {
 	return user, err
 }
 
+func (t TestRepo) GetUserByID(id string) (*User, error) {
+	t.ArgsIn[GetUserByIDMethod][0] = id
+	var user *User
+	if t.ArgsOut[GetUserByIDMethod][0] != nil {
+		user = t.ArgsOut[GetUserByIDMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[GetUserByIDMethod][1] != nil {
+		err = t.ArgsOut[GetUserByIDMethod][1].(error)
+	}
+	return user, err
+}
+
 func (t TestRepo) AddUser(user User) (*User, error) {
 	t.ArgsIn[AddUserMethod][0] = user
 	var created *User
@@ -147,10 +412,14 @@ func (t TestRepo) AddUser(user User) (*User, error) {
 	return created, err
 }
 
-func (t TestRepo) UpdateUser(user User, newPath string, newUrn string) (*User, error) {
+func (t TestRepo) UpdateUser(user User, newPath string, newUrn string, newDisplayName string, newDescription string,
+	updatedBy string) (*User, error) {
 	t.ArgsIn[UpdateUserMethod][0] = user
 	t.ArgsIn[UpdateUserMethod][1] = newPath
 	t.ArgsIn[UpdateUserMethod][2] = newUrn
+	t.ArgsIn[UpdateUserMethod][3] = newDisplayName
+	t.ArgsIn[UpdateUserMethod][4] = newDescription
+	t.ArgsIn[UpdateUserMethod][5] = updatedBy
 	var updated *User
 	if t.ArgsOut[UpdateUserMethod][0] != nil {
 		updated = t.ArgsOut[UpdateUserMethod][0].(*User)
@@ -188,8 +457,9 @@ func (t TestRepo) GetGroupsByUserID(id string) ([]Group, error) {
 	return groups, err
 }
 
-func (t TestRepo) RemoveUser(id string) error {
+func (t TestRepo) RemoveUser(id string, version int64) error {
 	t.ArgsIn[RemoveUserMethod][0] = id
+	t.ArgsIn[RemoveUserMethod][1] = version
 	var err error
 	if t.ArgsOut[RemoveUserMethod][0] != nil {
 		err = t.ArgsOut[RemoveUserMethod][0].(error)
@@ -197,6 +467,95 @@ func (t TestRepo) RemoveUser(id string) error {
 	return err
 }
 
+func (t TestRepo) GetDeletedUserByExternalID(id string) (*User, error) {
+	t.ArgsIn[GetDeletedUserByExternalIDMethod][0] = id
+	var user *User
+	if t.ArgsOut[GetDeletedUserByExternalIDMethod][0] != nil {
+		user = t.ArgsOut[GetDeletedUserByExternalIDMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedUserByExternalIDMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedUserByExternalIDMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestRepo) GetDeletedUsers(pathPrefix string) ([]User, error) {
+	t.ArgsIn[GetDeletedUsersMethod][0] = pathPrefix
+	var users []User
+	if t.ArgsOut[GetDeletedUsersMethod][0] != nil {
+		users = t.ArgsOut[GetDeletedUsersMethod][0].([]User)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedUsersMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedUsersMethod][1].(error)
+	}
+	return users, err
+}
+
+func (t TestRepo) RestoreUser(id string) error {
+	t.ArgsIn[RestoreUserMethod][0] = id
+	var err error
+	if t.ArgsOut[RestoreUserMethod][0] != nil {
+		err = t.ArgsOut[RestoreUserMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) PurgeUsers(deletedBefore time.Time) (int64, error) {
+	t.ArgsIn[PurgeUsersMethod][0] = deletedBefore
+	var purged int64
+	if t.ArgsOut[PurgeUsersMethod][0] != nil {
+		purged = t.ArgsOut[PurgeUsersMethod][0].(int64)
+	}
+	var err error
+	if t.ArgsOut[PurgeUsersMethod][1] != nil {
+		err = t.ArgsOut[PurgeUsersMethod][1].(error)
+	}
+	return purged, err
+}
+
+func (t TestRepo) RenameUser(user User, newExternalId string, newUrn string, updatedBy string) (*User, error) {
+	t.ArgsIn[RenameUserMethod][0] = user
+	t.ArgsIn[RenameUserMethod][1] = newExternalId
+	t.ArgsIn[RenameUserMethod][2] = newUrn
+	t.ArgsIn[RenameUserMethod][3] = updatedBy
+	var renamed *User
+	if t.ArgsOut[RenameUserMethod][0] != nil {
+		renamed = t.ArgsOut[RenameUserMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[RenameUserMethod][1] != nil {
+		err = t.ArgsOut[RenameUserMethod][1].(error)
+	}
+	return renamed, err
+}
+
+func (t TestRepo) MergeUsers(source User, target User) error {
+	t.ArgsIn[MergeUsersMethod][0] = source
+	t.ArgsIn[MergeUsersMethod][1] = target
+	var err error
+	if t.ArgsOut[MergeUsersMethod][0] != nil {
+		err = t.ArgsOut[MergeUsersMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) UpdateUserStatus(user User, status string, updatedBy string) (*User, error) {
+	t.ArgsIn[UpdateUserStatusMethod][0] = user
+	t.ArgsIn[UpdateUserStatusMethod][1] = status
+	t.ArgsIn[UpdateUserStatusMethod][2] = updatedBy
+	var updated *User
+	if t.ArgsOut[UpdateUserStatusMethod][0] != nil {
+		updated = t.ArgsOut[UpdateUserStatusMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[UpdateUserStatusMethod][1] != nil {
+		err = t.ArgsOut[UpdateUserStatusMethod][1].(error)
+	}
+	return updated, err
+}
+
 //////////////////
 // Group repo
 //////////////////
@@ -232,11 +591,11 @@ func (t TestRepo) IsMemberOfGroup(userID string, groupID string) (bool, error) {
 	return isMember, err
 }
 
-func (t TestRepo) GetGroupMembers(groupID string) ([]User, error) {
+func (t TestRepo) GetGroupMembers(groupID string) ([]GroupMember, error) {
 	t.ArgsIn[GetGroupMembersMethod][0] = groupID
-	var members []User
+	var members []GroupMember
 	if t.ArgsOut[GetGroupMembersMethod][0] != nil {
-		members = t.ArgsOut[GetGroupMembersMethod][0].([]User)
+		members = t.ArgsOut[GetGroupMembersMethod][0].([]GroupMember)
 	}
 	var err error
 	if t.ArgsOut[GetGroupMembersMethod][1] != nil {
@@ -259,11 +618,11 @@ func (t TestRepo) IsAttachedToGroup(groupID string, policyID string) (bool, erro
 	return isAttached, err
 }
 
-func (t TestRepo) GetAttachedPolicies(groupID string) ([]Policy, error) {
+func (t TestRepo) GetAttachedPolicies(groupID string) ([]GroupPolicy, error) {
 	t.ArgsIn[GetAttachedPoliciesMethod][0] = groupID
-	var policies []Policy
+	var policies []GroupPolicy
 	if t.ArgsOut[GetAttachedPoliciesMethod][0] != nil {
-		policies = t.ArgsOut[GetAttachedPoliciesMethod][0].([]Policy)
+		policies = t.ArgsOut[GetAttachedPoliciesMethod][0].([]GroupPolicy)
 	}
 	var err error
 	if t.ArgsOut[GetAttachedPoliciesMethod][1] != nil {
@@ -286,8 +645,9 @@ func (t TestRepo) GetGroupsFiltered(org string, pathPrefix string) ([]Group, err
 	}
 	return groups, err
 }
-func (t TestRepo) RemoveGroup(id string) error {
+func (t TestRepo) RemoveGroup(id string, version int64) error {
 	t.ArgsIn[RemoveGroupMethod][0] = id
+	t.ArgsIn[RemoveGroupMethod][1] = version
 	var err error
 	if t.ArgsOut[RemoveGroupMethod][0] != nil {
 		err = t.ArgsOut[RemoveGroupMethod][0].(error)
@@ -308,9 +668,10 @@ func (t TestRepo) AddGroup(group Group) (*Group, error) {
 	return created, err
 }
 
-func (t TestRepo) AddMember(userID string, groupID string) error {
+func (t TestRepo) AddMember(userID string, groupID string, expireAt *time.Time) error {
 	t.ArgsIn[AddMemberMethod][0] = userID
 	t.ArgsIn[AddMemberMethod][1] = groupID
+	t.ArgsIn[AddMemberMethod][2] = expireAt
 	var err error
 	if t.ArgsOut[AddMemberMethod][0] != nil {
 		err = t.ArgsOut[AddMemberMethod][0].(error)
@@ -328,11 +689,58 @@ func (t TestRepo) RemoveMember(userID string, groupID string) error {
 	return err
 }
 
-func (t TestRepo) UpdateGroup(group Group, newName string, newPath string, newUrn string) (*Group, error) {
+func (t TestRepo) AddMappedMember(userID string, groupID string) error {
+	t.ArgsIn[AddMappedMemberMethod][0] = userID
+	t.ArgsIn[AddMappedMemberMethod][1] = groupID
+	var err error
+	if t.ArgsOut[AddMappedMemberMethod][0] != nil {
+		err = t.ArgsOut[AddMappedMemberMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetMappedGroupsByUserID(userID string) ([]Group, error) {
+	t.ArgsIn[GetMappedGroupsMethod][0] = userID
+	var groups []Group
+	if t.ArgsOut[GetMappedGroupsMethod][0] != nil {
+		groups = t.ArgsOut[GetMappedGroupsMethod][0].([]Group)
+	}
+	var err error
+	if t.ArgsOut[GetMappedGroupsMethod][1] != nil {
+		err = t.ArgsOut[GetMappedGroupsMethod][1].(error)
+	}
+	return groups, err
+}
+
+func (t TestRepo) AddMembers(userIDs []string, groupID string) error {
+	t.ArgsIn[AddMembersMethod][0] = userIDs
+	t.ArgsIn[AddMembersMethod][1] = groupID
+	var err error
+	if t.ArgsOut[AddMembersMethod][0] != nil {
+		err = t.ArgsOut[AddMembersMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) RemoveMembers(userIDs []string, groupID string) error {
+	t.ArgsIn[RemoveMembersMethod][0] = userIDs
+	t.ArgsIn[RemoveMembersMethod][1] = groupID
+	var err error
+	if t.ArgsOut[RemoveMembersMethod][0] != nil {
+		err = t.ArgsOut[RemoveMembersMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) UpdateGroup(group Group, newName string, newPath string, newUrn string, newDisplayName string,
+	newDescription string, updatedBy string) (*Group, error) {
 	t.ArgsIn[UpdateGroupMethod][0] = group
 	t.ArgsIn[UpdateGroupMethod][1] = newName
 	t.ArgsIn[UpdateGroupMethod][2] = newPath
 	t.ArgsIn[UpdateGroupMethod][3] = newUrn
+	t.ArgsIn[UpdateGroupMethod][4] = newDisplayName
+	t.ArgsIn[UpdateGroupMethod][5] = newDescription
+	t.ArgsIn[UpdateGroupMethod][6] = updatedBy
 
 	var updated *Group
 	if t.ArgsOut[UpdateGroupMethod][0] != nil {
@@ -345,9 +753,11 @@ func (t TestRepo) UpdateGroup(group Group, newName string, newPath string, newUr
 	return updated, err
 }
 
-func (t TestRepo) AttachPolicy(groupID string, policyID string) error {
+func (t TestRepo) AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error {
 	t.ArgsIn[AttachPolicyMethod][0] = groupID
 	t.ArgsIn[AttachPolicyMethod][1] = policyID
+	t.ArgsIn[AttachPolicyMethod][2] = notBefore
+	t.ArgsIn[AttachPolicyMethod][3] = notAfter
 	var err error
 	if t.ArgsOut[AttachPolicyMethod][0] != nil {
 		err = t.ArgsOut[AttachPolicyMethod][0].(error)
@@ -364,6 +774,89 @@ func (t TestRepo) DetachPolicy(groupID string, policyID string) error {
 	return err
 }
 
+func (t TestRepo) AttachPolicies(groupID string, policyIDs []string) error {
+	t.ArgsIn[AttachPoliciesMethod][0] = groupID
+	t.ArgsIn[AttachPoliciesMethod][1] = policyIDs
+	var err error
+	if t.ArgsOut[AttachPoliciesMethod][0] != nil {
+		err = t.ArgsOut[AttachPoliciesMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) DetachPolicies(groupID string, policyIDs []string) error {
+	t.ArgsIn[DetachPoliciesMethod][0] = groupID
+	t.ArgsIn[DetachPoliciesMethod][1] = policyIDs
+	var err error
+	if t.ArgsOut[DetachPoliciesMethod][0] != nil {
+		err = t.ArgsOut[DetachPoliciesMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetDeletedGroupByName(org string, name string) (*Group, error) {
+	t.ArgsIn[GetDeletedGroupByNameMethod][0] = org
+	t.ArgsIn[GetDeletedGroupByNameMethod][1] = name
+	var group *Group
+	if t.ArgsOut[GetDeletedGroupByNameMethod][0] != nil {
+		group = t.ArgsOut[GetDeletedGroupByNameMethod][0].(*Group)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedGroupByNameMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedGroupByNameMethod][1].(error)
+	}
+	return group, err
+}
+
+func (t TestRepo) GetDeletedGroups(org string, pathPrefix string) ([]Group, error) {
+	t.ArgsIn[GetDeletedGroupsMethod][0] = org
+	t.ArgsIn[GetDeletedGroupsMethod][1] = pathPrefix
+	var groups []Group
+	if t.ArgsOut[GetDeletedGroupsMethod][0] != nil {
+		groups = t.ArgsOut[GetDeletedGroupsMethod][0].([]Group)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedGroupsMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedGroupsMethod][1].(error)
+	}
+	return groups, err
+}
+
+func (t TestRepo) RestoreGroup(groupID string) error {
+	t.ArgsIn[RestoreGroupMethod][0] = groupID
+	var err error
+	if t.ArgsOut[RestoreGroupMethod][0] != nil {
+		err = t.ArgsOut[RestoreGroupMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) PurgeGroups(deletedBefore time.Time) (int64, error) {
+	t.ArgsIn[PurgeGroupsMethod][0] = deletedBefore
+	var purged int64
+	if t.ArgsOut[PurgeGroupsMethod][0] != nil {
+		purged = t.ArgsOut[PurgeGroupsMethod][0].(int64)
+	}
+	var err error
+	if t.ArgsOut[PurgeGroupsMethod][1] != nil {
+		err = t.ArgsOut[PurgeGroupsMethod][1].(error)
+	}
+	return purged, err
+}
+
+func (t TestRepo) RemoveExpiredMembers(expiredBefore time.Time) ([]ExpiredMember, error) {
+	t.ArgsIn[RemoveExpiredMembersMethod][0] = expiredBefore
+	var expiredMembers []ExpiredMember
+	if t.ArgsOut[RemoveExpiredMembersMethod][0] != nil {
+		expiredMembers = t.ArgsOut[RemoveExpiredMembersMethod][0].([]ExpiredMember)
+	}
+	var err error
+	if t.ArgsOut[RemoveExpiredMembersMethod][1] != nil {
+		err = t.ArgsOut[RemoveExpiredMembersMethod][1].(error)
+	}
+	return expiredMembers, err
+}
+
 //////////////////
 // Policy repo
 //////////////////
@@ -398,12 +891,16 @@ func (t TestRepo) AddPolicy(policy Policy) (*Policy, error) {
 	return created, err
 }
 
-func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement) (*Policy, error) {
+func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newDisplayName string,
+	newDescription string, updatedBy string, newStatements []Statement) (*Policy, error) {
 	t.ArgsIn[UpdatePolicyMethod][0] = policy
 	t.ArgsIn[UpdatePolicyMethod][1] = newName
 	t.ArgsIn[UpdatePolicyMethod][2] = newPath
 	t.ArgsIn[UpdatePolicyMethod][3] = newUrn
 	t.ArgsIn[UpdatePolicyMethod][4] = newStatements
+	t.ArgsIn[UpdatePolicyMethod][5] = newDisplayName
+	t.ArgsIn[UpdatePolicyMethod][6] = newDescription
+	t.ArgsIn[UpdatePolicyMethod][7] = updatedBy
 
 	var updated *Policy
 	if t.ArgsOut[UpdatePolicyMethod][0] != nil {
@@ -416,8 +913,9 @@ func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, ne
 	return updated, err
 }
 
-func (t TestRepo) RemovePolicy(id string) error {
+func (t TestRepo) RemovePolicy(id string, version int64) error {
 	t.ArgsIn[RemovePolicyMethod][0] = id
+	t.ArgsIn[RemovePolicyMethod][1] = version
 	var err error
 	if t.ArgsOut[RemovePolicyMethod][0] != nil {
 		err = t.ArgsOut[RemovePolicyMethod][0].(error)
@@ -454,6 +952,659 @@ func (t TestRepo) GetAttachedGroups(policyID string) ([]Group, error) {
 	return groups, err
 }
 
+func (t TestRepo) GetDeletedPolicyByName(org string, name string) (*Policy, error) {
+	t.ArgsIn[GetDeletedPolicyByNameMethod][0] = org
+	t.ArgsIn[GetDeletedPolicyByNameMethod][1] = name
+	var policy *Policy
+	if t.ArgsOut[GetDeletedPolicyByNameMethod][0] != nil {
+		policy = t.ArgsOut[GetDeletedPolicyByNameMethod][0].(*Policy)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedPolicyByNameMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedPolicyByNameMethod][1].(error)
+	}
+	return policy, err
+}
+
+func (t TestRepo) GetDeletedPolicies(org string, pathPrefix string) ([]Policy, error) {
+	t.ArgsIn[GetDeletedPoliciesMethod][0] = org
+	t.ArgsIn[GetDeletedPoliciesMethod][1] = pathPrefix
+	var policies []Policy
+	if t.ArgsOut[GetDeletedPoliciesMethod][0] != nil {
+		policies = t.ArgsOut[GetDeletedPoliciesMethod][0].([]Policy)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedPoliciesMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedPoliciesMethod][1].(error)
+	}
+	return policies, err
+}
+
+func (t TestRepo) RestorePolicy(id string) error {
+	t.ArgsIn[RestorePolicyMethod][0] = id
+	var err error
+	if t.ArgsOut[RestorePolicyMethod][0] != nil {
+		err = t.ArgsOut[RestorePolicyMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) PurgePolicies(deletedBefore time.Time) (int64, error) {
+	t.ArgsIn[PurgePoliciesMethod][0] = deletedBefore
+	var purged int64
+	if t.ArgsOut[PurgePoliciesMethod][0] != nil {
+		purged = t.ArgsOut[PurgePoliciesMethod][0].(int64)
+	}
+	var err error
+	if t.ArgsOut[PurgePoliciesMethod][1] != nil {
+		err = t.ArgsOut[PurgePoliciesMethod][1].(error)
+	}
+	return purged, err
+}
+
+//////////////////
+// Organization repo
+//////////////////
+
+func (t TestRepo) AddOrganization(org Organization) (*Organization, error) {
+	t.ArgsIn[AddOrganizationMethod][0] = org
+	var created *Organization
+	if t.ArgsOut[AddOrganizationMethod][0] != nil {
+		created = t.ArgsOut[AddOrganizationMethod][0].(*Organization)
+	}
+	var err error
+	if t.ArgsOut[AddOrganizationMethod][1] != nil {
+		err = t.ArgsOut[AddOrganizationMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetOrganizationByName(name string) (*Organization, error) {
+	t.ArgsIn[GetOrganizationByNameMethod][0] = name
+	var org *Organization
+	if t.ArgsOut[GetOrganizationByNameMethod][0] != nil {
+		org = t.ArgsOut[GetOrganizationByNameMethod][0].(*Organization)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationByNameMethod][1] != nil {
+		err = t.ArgsOut[GetOrganizationByNameMethod][1].(error)
+	}
+	return org, err
+}
+
+func (t TestRepo) GetOrganizations() ([]Organization, error) {
+	var orgs []Organization
+	if t.ArgsOut[GetOrganizationsMethod][0] != nil {
+		orgs = t.ArgsOut[GetOrganizationsMethod][0].([]Organization)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationsMethod][1] != nil {
+		err = t.ArgsOut[GetOrganizationsMethod][1].(error)
+	}
+	return orgs, err
+}
+
+func (t TestRepo) RemoveOrganization(name string) error {
+	t.ArgsIn[RemoveOrganizationMethod][0] = name
+	var err error
+	if t.ArgsOut[RemoveOrganizationMethod][0] != nil {
+		err = t.ArgsOut[RemoveOrganizationMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Sync repo
+//////////////////
+
+func (t TestRepo) ApplySyncPlan(changes []SyncChange) error {
+	t.ArgsIn[ApplySyncPlanMethod][0] = changes
+	var err error
+	if t.ArgsOut[ApplySyncPlanMethod][0] != nil {
+		err = t.ArgsOut[ApplySyncPlanMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Access request repo
+//////////////////
+
+func (t TestRepo) AddAccessRequest(request AccessRequest) (*AccessRequest, error) {
+	t.ArgsIn[AddAccessRequestMethod][0] = request
+	var created *AccessRequest
+	if t.ArgsOut[AddAccessRequestMethod][0] != nil {
+		created = t.ArgsOut[AddAccessRequestMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[AddAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[AddAccessRequestMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetAccessRequestByID(id string) (*AccessRequest, error) {
+	t.ArgsIn[GetAccessRequestByIDMethod][0] = id
+	var request *AccessRequest
+	if t.ArgsOut[GetAccessRequestByIDMethod][0] != nil {
+		request = t.ArgsOut[GetAccessRequestByIDMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[GetAccessRequestByIDMethod][1] != nil {
+		err = t.ArgsOut[GetAccessRequestByIDMethod][1].(error)
+	}
+	return request, err
+}
+
+func (t TestRepo) GetAccessRequestsByGroupID(groupID string, status string) ([]AccessRequest, error) {
+	t.ArgsIn[GetAccessRequestsByGroupIDMethod][0] = groupID
+	t.ArgsIn[GetAccessRequestsByGroupIDMethod][1] = status
+	var requests []AccessRequest
+	if t.ArgsOut[GetAccessRequestsByGroupIDMethod][0] != nil {
+		requests = t.ArgsOut[GetAccessRequestsByGroupIDMethod][0].([]AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[GetAccessRequestsByGroupIDMethod][1] != nil {
+		err = t.ArgsOut[GetAccessRequestsByGroupIDMethod][1].(error)
+	}
+	return requests, err
+}
+
+func (t TestRepo) ReviewAccessRequest(request AccessRequest) (*AccessRequest, error) {
+	t.ArgsIn[ReviewAccessRequestMethod][0] = request
+	var reviewed *AccessRequest
+	if t.ArgsOut[ReviewAccessRequestMethod][0] != nil {
+		reviewed = t.ArgsOut[ReviewAccessRequestMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[ReviewAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[ReviewAccessRequestMethod][1].(error)
+	}
+	return reviewed, err
+}
+
+//////////////////
+// Audit repo
+//////////////////
+
+func (t TestRepo) AddAuditEvent(event AuditEvent) (*AuditEvent, error) {
+	t.ArgsIn[AddAuditEventMethod][0] = event
+	var created *AuditEvent
+	if t.ArgsOut[AddAuditEventMethod][0] != nil {
+		created = t.ArgsOut[AddAuditEventMethod][0].(*AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[AddAuditEventMethod][1] != nil {
+		err = t.ArgsOut[AddAuditEventMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetAuditEventsFiltered(filter AuditFilter) ([]AuditEvent, error) {
+	t.ArgsIn[GetAuditEventsFilteredMethod][0] = filter
+	var events []AuditEvent
+	if t.ArgsOut[GetAuditEventsFilteredMethod][0] != nil {
+		events = t.ArgsOut[GetAuditEventsFilteredMethod][0].([]AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[GetAuditEventsFilteredMethod][1] != nil {
+		err = t.ArgsOut[GetAuditEventsFilteredMethod][1].(error)
+	}
+	return events, err
+}
+
+//////////////////
+// Webhook repo
+//////////////////
+
+func (t TestRepo) AddWebhook(webhook Webhook) (*Webhook, error) {
+	t.ArgsIn[AddWebhookMethod][0] = webhook
+	var created *Webhook
+	if t.ArgsOut[AddWebhookMethod][0] != nil {
+		created = t.ArgsOut[AddWebhookMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[AddWebhookMethod][1] != nil {
+		err = t.ArgsOut[AddWebhookMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetWebhookByID(id string) (*Webhook, error) {
+	t.ArgsIn[GetWebhookByIDMethod][0] = id
+	var webhook *Webhook
+	if t.ArgsOut[GetWebhookByIDMethod][0] != nil {
+		webhook = t.ArgsOut[GetWebhookByIDMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookByIDMethod][1] != nil {
+		err = t.ArgsOut[GetWebhookByIDMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestRepo) GetWebhooks() ([]Webhook, error) {
+	var webhooks []Webhook
+	if t.ArgsOut[GetWebhooksMethod][0] != nil {
+		webhooks = t.ArgsOut[GetWebhooksMethod][0].([]Webhook)
+	}
+	var err error
+	if t.ArgsOut[GetWebhooksMethod][1] != nil {
+		err = t.ArgsOut[GetWebhooksMethod][1].(error)
+	}
+	return webhooks, err
+}
+
+func (t TestRepo) UpdateWebhook(webhook Webhook) (*Webhook, error) {
+	t.ArgsIn[UpdateWebhookMethod][0] = webhook
+	var updated *Webhook
+	if t.ArgsOut[UpdateWebhookMethod][0] != nil {
+		updated = t.ArgsOut[UpdateWebhookMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) RemoveWebhook(id string) error {
+	t.ArgsIn[RemoveWebhookMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveWebhookMethod][0] != nil {
+		err = t.ArgsOut[RemoveWebhookMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) AddWebhookDeliveries(deliveries []WebhookDelivery) error {
+	t.ArgsIn[AddWebhookDeliveriesMethod][0] = deliveries
+	var err error
+	if t.ArgsOut[AddWebhookDeliveriesMethod][0] != nil {
+		err = t.ArgsOut[AddWebhookDeliveriesMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetWebhookDeliveries(webhookID string, status string) ([]WebhookDelivery, error) {
+	t.ArgsIn[GetWebhookDeliveriesMethod][0] = webhookID
+	t.ArgsIn[GetWebhookDeliveriesMethod][1] = status
+	var deliveries []WebhookDelivery
+	if t.ArgsOut[GetWebhookDeliveriesMethod][0] != nil {
+		deliveries = t.ArgsOut[GetWebhookDeliveriesMethod][0].([]WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookDeliveriesMethod][1] != nil {
+		err = t.ArgsOut[GetWebhookDeliveriesMethod][1].(error)
+	}
+	return deliveries, err
+}
+
+func (t TestRepo) GetPendingWebhookDeliveries(dueBefore time.Time) ([]WebhookDelivery, error) {
+	t.ArgsIn[GetPendingWebhookDeliveriesMethod][0] = dueBefore
+	var deliveries []WebhookDelivery
+	if t.ArgsOut[GetPendingWebhookDeliveriesMethod][0] != nil {
+		deliveries = t.ArgsOut[GetPendingWebhookDeliveriesMethod][0].([]WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[GetPendingWebhookDeliveriesMethod][1] != nil {
+		err = t.ArgsOut[GetPendingWebhookDeliveriesMethod][1].(error)
+	}
+	return deliveries, err
+}
+
+func (t TestRepo) UpdateWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error) {
+	t.ArgsIn[UpdateWebhookDeliveryMethod][0] = delivery
+	var updated *WebhookDelivery
+	if t.ArgsOut[UpdateWebhookDeliveryMethod][0] != nil {
+		updated = t.ArgsOut[UpdateWebhookDeliveryMethod][0].(*WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookDeliveryMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookDeliveryMethod][1].(error)
+	}
+	return updated, err
+}
+
+//////////////////
+// Change repo
+//////////////////
+
+func (t TestRepo) AddChange(change Change) (*Change, error) {
+	t.ArgsIn[AddChangeMethod][0] = change
+	var created *Change
+	if t.ArgsOut[AddChangeMethod][0] != nil {
+		created = t.ArgsOut[AddChangeMethod][0].(*Change)
+	}
+	var err error
+	if t.ArgsOut[AddChangeMethod][1] != nil {
+		err = t.ArgsOut[AddChangeMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetChanges(since int64, limit int) ([]Change, error) {
+	t.ArgsIn[GetChangesMethod][0] = since
+	t.ArgsIn[GetChangesMethod][1] = limit
+	var changes []Change
+	if t.ArgsOut[GetChangesMethod][0] != nil {
+		changes = t.ArgsOut[GetChangesMethod][0].([]Change)
+	}
+	var err error
+	if t.ArgsOut[GetChangesMethod][1] != nil {
+		err = t.ArgsOut[GetChangesMethod][1].(error)
+	}
+	return changes, err
+}
+
+//////////////////
+// Api key repo
+//////////////////
+
+func (t TestRepo) AddApiKey(key ApiKey, hash string) (*ApiKey, error) {
+	t.ArgsIn[AddApiKeyMethod][0] = key
+	t.ArgsIn[AddApiKeyMethod][1] = hash
+	var created *ApiKey
+	if t.ArgsOut[AddApiKeyMethod][0] != nil {
+		created = t.ArgsOut[AddApiKeyMethod][0].(*ApiKey)
+	}
+	var err error
+	if t.ArgsOut[AddApiKeyMethod][1] != nil {
+		err = t.ArgsOut[AddApiKeyMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetApiKeysByUserID(userID string) ([]ApiKey, error) {
+	t.ArgsIn[GetApiKeysByUserIDMethod][0] = userID
+	var keys []ApiKey
+	if t.ArgsOut[GetApiKeysByUserIDMethod][0] != nil {
+		keys = t.ArgsOut[GetApiKeysByUserIDMethod][0].([]ApiKey)
+	}
+	var err error
+	if t.ArgsOut[GetApiKeysByUserIDMethod][1] != nil {
+		err = t.ArgsOut[GetApiKeysByUserIDMethod][1].(error)
+	}
+	return keys, err
+}
+
+func (t TestRepo) GetApiKeyByID(id string) (*ApiKey, error) {
+	t.ArgsIn[GetApiKeyByIDMethod][0] = id
+	var key *ApiKey
+	if t.ArgsOut[GetApiKeyByIDMethod][0] != nil {
+		key = t.ArgsOut[GetApiKeyByIDMethod][0].(*ApiKey)
+	}
+	var err error
+	if t.ArgsOut[GetApiKeyByIDMethod][1] != nil {
+		err = t.ArgsOut[GetApiKeyByIDMethod][1].(error)
+	}
+	return key, err
+}
+
+func (t TestRepo) GetApiKeyByHash(hash string) (*ApiKey, error) {
+	t.ArgsIn[GetApiKeyByHashMethod][0] = hash
+	var key *ApiKey
+	if t.ArgsOut[GetApiKeyByHashMethod][0] != nil {
+		key = t.ArgsOut[GetApiKeyByHashMethod][0].(*ApiKey)
+	}
+	var err error
+	if t.ArgsOut[GetApiKeyByHashMethod][1] != nil {
+		err = t.ArgsOut[GetApiKeyByHashMethod][1].(error)
+	}
+	return key, err
+}
+
+func (t TestRepo) UpdateApiKey(key ApiKey, hash string) (*ApiKey, error) {
+	t.ArgsIn[UpdateApiKeyMethod][0] = key
+	t.ArgsIn[UpdateApiKeyMethod][1] = hash
+	var updated *ApiKey
+	if t.ArgsOut[UpdateApiKeyMethod][0] != nil {
+		updated = t.ArgsOut[UpdateApiKeyMethod][0].(*ApiKey)
+	}
+	var err error
+	if t.ArgsOut[UpdateApiKeyMethod][1] != nil {
+		err = t.ArgsOut[UpdateApiKeyMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) RemoveApiKey(id string) error {
+	t.ArgsIn[RemoveApiKeyMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveApiKeyMethod][0] != nil {
+		err = t.ArgsOut[RemoveApiKeyMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Admin repo
+//////////////////
+
+func (t TestRepo) SetAdminCredentials(userID string, hash string) error {
+	t.ArgsIn[SetAdminCredentialsMethod][0] = userID
+	t.ArgsIn[SetAdminCredentialsMethod][1] = hash
+	var err error
+	if t.ArgsOut[SetAdminCredentialsMethod][0] != nil {
+		err = t.ArgsOut[SetAdminCredentialsMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetAdminCredentials(userID string) (*UserCredentials, error) {
+	t.ArgsIn[GetAdminCredentialsMethod][0] = userID
+	var credentials *UserCredentials
+	if t.ArgsOut[GetAdminCredentialsMethod][0] != nil {
+		credentials = t.ArgsOut[GetAdminCredentialsMethod][0].(*UserCredentials)
+	}
+	var err error
+	if t.ArgsOut[GetAdminCredentialsMethod][1] != nil {
+		err = t.ArgsOut[GetAdminCredentialsMethod][1].(error)
+	}
+	return credentials, err
+}
+
+func (t TestRepo) IncrementAdminCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*UserCredentials, error) {
+	t.ArgsIn[IncrementAdminCredentialsAttemptsMethod][0] = userID
+	t.ArgsIn[IncrementAdminCredentialsAttemptsMethod][1] = maxFailedAttempts
+	t.ArgsIn[IncrementAdminCredentialsAttemptsMethod][2] = lockedUntil
+	var credentials *UserCredentials
+	if t.ArgsOut[IncrementAdminCredentialsAttemptsMethod][0] != nil {
+		credentials = t.ArgsOut[IncrementAdminCredentialsAttemptsMethod][0].(*UserCredentials)
+	}
+	var err error
+	if t.ArgsOut[IncrementAdminCredentialsAttemptsMethod][1] != nil {
+		err = t.ArgsOut[IncrementAdminCredentialsAttemptsMethod][1].(error)
+	}
+	return credentials, err
+}
+
+func (t TestRepo) UpdateAdminCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error {
+	t.ArgsIn[UpdateAdminCredentialsAttemptsMethod][0] = userID
+	t.ArgsIn[UpdateAdminCredentialsAttemptsMethod][1] = failedAttempts
+	t.ArgsIn[UpdateAdminCredentialsAttemptsMethod][2] = lockedUntil
+	var err error
+	if t.ArgsOut[UpdateAdminCredentialsAttemptsMethod][0] != nil {
+		err = t.ArgsOut[UpdateAdminCredentialsAttemptsMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetAdmins() ([]User, error) {
+	var users []User
+	if t.ArgsOut[GetAdminsMethod][0] != nil {
+		users = t.ArgsOut[GetAdminsMethod][0].([]User)
+	}
+	var err error
+	if t.ArgsOut[GetAdminsMethod][1] != nil {
+		err = t.ArgsOut[GetAdminsMethod][1].(error)
+	}
+	return users, err
+}
+
+func (t TestRepo) RemoveAdminCredentials(userID string) error {
+	t.ArgsIn[RemoveAdminCredentialsMethod][0] = userID
+	var err error
+	if t.ArgsOut[RemoveAdminCredentialsMethod][0] != nil {
+		err = t.ArgsOut[RemoveAdminCredentialsMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Group mapping repo
+//////////////////
+
+func (t TestRepo) AddGroupMapping(mapping GroupMapping) (*GroupMapping, error) {
+	t.ArgsIn[AddGroupMappingMethod][0] = mapping
+	var created *GroupMapping
+	if t.ArgsOut[AddGroupMappingMethod][0] != nil {
+		created = t.ArgsOut[AddGroupMappingMethod][0].(*GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[AddGroupMappingMethod][1] != nil {
+		err = t.ArgsOut[AddGroupMappingMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetGroupMappingByID(id string) (*GroupMapping, error) {
+	t.ArgsIn[GetGroupMappingByIDMethod][0] = id
+	var mapping *GroupMapping
+	if t.ArgsOut[GetGroupMappingByIDMethod][0] != nil {
+		mapping = t.ArgsOut[GetGroupMappingByIDMethod][0].(*GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[GetGroupMappingByIDMethod][1] != nil {
+		err = t.ArgsOut[GetGroupMappingByIDMethod][1].(error)
+	}
+	return mapping, err
+}
+
+func (t TestRepo) GetGroupMappings(org string) ([]GroupMapping, error) {
+	t.ArgsIn[GetGroupMappingsMethod][0] = org
+	var mappings []GroupMapping
+	if t.ArgsOut[GetGroupMappingsMethod][0] != nil {
+		mappings = t.ArgsOut[GetGroupMappingsMethod][0].([]GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[GetGroupMappingsMethod][1] != nil {
+		err = t.ArgsOut[GetGroupMappingsMethod][1].(error)
+	}
+	return mappings, err
+}
+
+func (t TestRepo) RemoveGroupMapping(id string) error {
+	t.ArgsIn[RemoveGroupMappingMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveGroupMappingMethod][0] != nil {
+		err = t.ArgsOut[RemoveGroupMappingMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Password repo
+//////////////////
+
+func (t TestRepo) SetUserCredentials(userID string, hash string) error {
+	t.ArgsIn[SetUserCredentialsMethod][0] = userID
+	t.ArgsIn[SetUserCredentialsMethod][1] = hash
+	var err error
+	if t.ArgsOut[SetUserCredentialsMethod][0] != nil {
+		err = t.ArgsOut[SetUserCredentialsMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetUserCredentials(userID string) (*UserCredentials, error) {
+	t.ArgsIn[GetUserCredentialsMethod][0] = userID
+	var credentials *UserCredentials
+	if t.ArgsOut[GetUserCredentialsMethod][0] != nil {
+		credentials = t.ArgsOut[GetUserCredentialsMethod][0].(*UserCredentials)
+	}
+	var err error
+	if t.ArgsOut[GetUserCredentialsMethod][1] != nil {
+		err = t.ArgsOut[GetUserCredentialsMethod][1].(error)
+	}
+	return credentials, err
+}
+
+func (t TestRepo) IncrementUserCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*UserCredentials, error) {
+	t.ArgsIn[IncrementUserCredentialsAttemptsMethod][0] = userID
+	t.ArgsIn[IncrementUserCredentialsAttemptsMethod][1] = maxFailedAttempts
+	t.ArgsIn[IncrementUserCredentialsAttemptsMethod][2] = lockedUntil
+	var credentials *UserCredentials
+	if t.ArgsOut[IncrementUserCredentialsAttemptsMethod][0] != nil {
+		credentials = t.ArgsOut[IncrementUserCredentialsAttemptsMethod][0].(*UserCredentials)
+	}
+	var err error
+	if t.ArgsOut[IncrementUserCredentialsAttemptsMethod][1] != nil {
+		err = t.ArgsOut[IncrementUserCredentialsAttemptsMethod][1].(error)
+	}
+	return credentials, err
+}
+
+func (t TestRepo) UpdateUserCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error {
+	t.ArgsIn[UpdateUserCredentialsAttemptsMethod][0] = userID
+	t.ArgsIn[UpdateUserCredentialsAttemptsMethod][1] = failedAttempts
+	t.ArgsIn[UpdateUserCredentialsAttemptsMethod][2] = lockedUntil
+	var err error
+	if t.ArgsOut[UpdateUserCredentialsAttemptsMethod][0] != nil {
+		err = t.ArgsOut[UpdateUserCredentialsAttemptsMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) AddSession(session Session, hash string) (*Session, error) {
+	t.ArgsIn[AddSessionMethod][0] = session
+	t.ArgsIn[AddSessionMethod][1] = hash
+	var created *Session
+	if t.ArgsOut[AddSessionMethod][0] != nil {
+		created = t.ArgsOut[AddSessionMethod][0].(*Session)
+	}
+	var err error
+	if t.ArgsOut[AddSessionMethod][1] != nil {
+		err = t.ArgsOut[AddSessionMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetSessionByHash(hash string) (*Session, error) {
+	t.ArgsIn[GetSessionByHashMethod][0] = hash
+	var session *Session
+	if t.ArgsOut[GetSessionByHashMethod][0] != nil {
+		session = t.ArgsOut[GetSessionByHashMethod][0].(*Session)
+	}
+	var err error
+	if t.ArgsOut[GetSessionByHashMethod][1] != nil {
+		err = t.ArgsOut[GetSessionByHashMethod][1].(error)
+	}
+	return session, err
+}
+
+func (t TestRepo) RemoveSession(id string) error {
+	t.ArgsIn[RemoveSessionMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveSessionMethod][0] != nil {
+		err = t.ArgsOut[RemoveSessionMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) RemoveExpiredSessions(expiredBefore time.Time) (int64, error) {
+	t.ArgsIn[RemoveExpiredSessionsMethod][0] = expiredBefore
+	var removed int64
+	if t.ArgsOut[RemoveExpiredSessionsMethod][0] != nil {
+		removed = t.ArgsOut[RemoveExpiredSessionsMethod][0].(int64)
+	}
+	var err error
+	if t.ArgsOut[RemoveExpiredSessionsMethod][1] != nil {
+		err = t.ArgsOut[RemoveExpiredSessionsMethod][1].(error)
+	}
+	return removed, err
+}
+
 // Private helper methods
 
 func GetRandomString(runeValue []rune, n int) string {
@@ -8,20 +8,36 @@ import (
 	"github.com/tecsisa/foulkon/database"
 )
 
+const (
+	// User status
+	USER_STATUS_ACTIVE    = "active"
+	USER_STATUS_SUSPENDED = "suspended"
+)
+
 // TYPE DEFINITIONS
 
-// User domain
+// User domain. Service accounts are users for applications, that authenticate with API keys.
+// Suspended users keep their relationships, but all their requests are denied.
 type User struct {
-	ID         string    `json:"id, omitempty"`
-	ExternalID string    `json:"externalId, omitempty"`
-	Path       string    `json:"path, omitempty"`
-	Urn        string    `json:"urn, omitempty"`
-	CreateAt   time.Time `json:"createAt, omitempty"`
+	ID             string    `json:"id, omitempty"`
+	ExternalID     string    `json:"externalId, omitempty"`
+	Path           string    `json:"path, omitempty"`
+	DisplayName    string    `json:"displayName, omitempty"`
+	Description    string    `json:"description, omitempty"`
+	ServiceAccount bool      `json:"serviceAccount, omitempty"`
+	Status         string    `json:"status, omitempty"`
+	Urn            string    `json:"urn, omitempty"`
+	CreateAt       time.Time `json:"createAt, omitempty"`
+	UpdateAt       time.Time `json:"updatedAt, omitempty"`
+	CreatedBy      string    `json:"createdBy, omitempty"`
+	UpdatedBy      string    `json:"updatedBy, omitempty"`
+	Version        int64     `json:"version, omitempty"`
 }
 
 func (u User) String() string {
-	return fmt.Sprintf("[id: %v, externalId: %v, path: %v, urn: %v, createAt: %v]",
-		u.ID, u.ExternalID, u.Path, u.Urn, u.CreateAt.Format("2006-01-02 15:04:05 MST"))
+	return fmt.Sprintf("[id: %v, externalId: %v, path: %v, displayName: %v, serviceAccount: %v, status: %v, urn: %v, createAt: %v, updatedAt: %v, version: %v]",
+		u.ID, u.ExternalID, u.Path, u.DisplayName, u.ServiceAccount, u.Status, u.Urn, u.CreateAt.Format("2006-01-02 15:04:05 MST"),
+		u.UpdateAt.Format("2006-01-02 15:04:05 MST"), u.Version)
 }
 
 func (u User) GetUrn() string {
@@ -30,71 +46,36 @@ func (u User) GetUrn() string {
 
 // USER API IMPLEMENTATION
 
-func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path string) (*User, error) {
-	// Validate fields
-	if !IsValidUserExternalID(externalId) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: externalId %v", externalId),
-		}
-	}
-	if !IsValidPath(path) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: path %v", path),
-		}
-	}
+func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path string, displayName string,
+	description string) (*User, error) {
+	return api.addUser(requestInfo, externalId, path, displayName, description, false)
+}
 
-	user := createUser(externalId, path)
+func (api AuthAPI) AddServiceAccount(requestInfo RequestInfo, externalId string, path string, displayName string,
+	description string) (*User, error) {
+	return api.addUser(requestInfo, externalId, path, displayName, description, true)
+}
 
-	// Check restrictions
-	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_CREATE_USER, []User{user})
-	if err != nil {
-		return nil, err
+func (api AuthAPI) ProvisionUser(requestID string, externalId string, path string) (*User, error) {
+	// Return user if it already exists
+	user, err := api.UserRepo.GetUserByExternalID(externalId)
+	if err == nil {
+		return user, nil
 	}
-	if len(usersFiltered) < 1 {
+	if dbError := err.(*database.Error); dbError.Code != database.USER_NOT_FOUND {
 		return nil, &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, user.Urn),
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
 		}
 	}
 
-	// Check if user already exists
-	_, err = api.UserRepo.GetUserByExternalID(externalId)
-
-	if err != nil {
-		// Transform to DB error
-		dbError := err.(*database.Error)
-		// User doesn't exist in DB
-		switch dbError.Code {
-		case database.USER_NOT_FOUND:
-			// Create user
-			createdUser, err := api.UserRepo.AddUser(user)
-
-			// Check unexpected DB error
-			if err != nil {
-				//Transform to DB error
-				dbError := err.(*database.Error)
-				return nil, &Error{
-					Code:    UNKNOWN_API_ERROR,
-					Message: dbError.Message,
-				}
-			}
-			LogOperation(api.Logger, requestInfo, fmt.Sprintf("User created %+v", createdUser))
-			return createdUser, nil
-		default: // Unexpected error
-			return nil, &Error{
-				Code:    UNKNOWN_API_ERROR,
-				Message: dbError.Message,
-			}
-		}
-	} else {
-		return nil, &Error{
-			Code:    USER_ALREADY_EXIST,
-			Message: fmt.Sprintf("Unable to create user, user with externalId %v already exist", externalId),
-		}
+	// User is created by itself without authorization
+	requestInfo := RequestInfo{
+		Identifier: externalId,
+		Admin:      true,
+		RequestID:  requestID,
 	}
+	return api.addUser(requestInfo, externalId, path, "", "", false)
 }
 
 func (api AuthAPI) GetUserByExternalID(requestInfo RequestInfo, externalId string) (*User, error) {
@@ -185,13 +166,17 @@ func (api AuthAPI) ListUsers(requestInfo RequestInfo, pathPrefix string) ([]stri
 	return externalIds, nil
 }
 
-func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPath string) (*User, error) {
+func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPath string, newDisplayName string,
+	newDescription string, version int64) (*User, error) {
 	if !IsValidPath(newPath) {
 		return nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: path %v", newPath),
 		}
 	}
+	if err := AreValidDescriptiveAttributes(newDisplayName, newDescription); err != nil {
+		return nil, err
+	}
 
 	// Call repo to retrieve the user
 	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
@@ -212,6 +197,14 @@ func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPat
 		}
 	}
 
+	// Check version precondition
+	if !IsExpectedVersion(version, userDB.Version) {
+		return nil, &Error{
+			Code:    VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is in version %v, not in expected version %v", externalId, userDB.Version, version),
+		}
+	}
+
 	userToUpdate := createUser(externalId, newPath)
 
 	// Check restrictions
@@ -227,24 +220,36 @@ func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPat
 		}
 	}
 
-	user, err := api.UserRepo.UpdateUser(*userDB, newPath, userToUpdate.Urn)
+	user, err := api.UserRepo.UpdateUser(*userDB, newPath, userToUpdate.Urn, newDisplayName, newDescription,
+		requestInfo.Identifier)
 
-	// Check unexpected DB error
+	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return nil, &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
 
+	if err := api.recordChange(requestInfo, USER_ACTION_UPDATE_USER, user.Urn, userDB, user); err != nil {
+		return nil, err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User updated from %+v to %+v", userDB, user))
 	return user, nil
 
 }
 
-func (api AuthAPI) RemoveUser(requestInfo RequestInfo, externalId string) error {
+func (api AuthAPI) RemoveUser(requestInfo RequestInfo, externalId string, version int64) error {
 	// Call repo to retrieve the user
 	user, err := api.GetUserByExternalID(requestInfo, externalId)
 	if err != nil {
@@ -264,21 +269,141 @@ func (api AuthAPI) RemoveUser(requestInfo RequestInfo, externalId string) error
 		}
 	}
 
-	err = api.UserRepo.RemoveUser(user.ID)
+	// Check version precondition
+	if !IsExpectedVersion(version, user.Version) {
+		return &Error{
+			Code:    VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is in version %v, not in expected version %v", externalId, user.Version, version),
+		}
+	}
+
+	err = api.UserRepo.RemoveUser(user.ID, user.Version)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
 		}
 	}
+	if err := api.recordChange(requestInfo, USER_ACTION_DELETE_USER, user.Urn, user, nil); err != nil {
+		return err
+	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User deleted %+v", user))
 	return nil
 }
 
+func (api AuthAPI) ListDeletedUsers(requestInfo RequestInfo, pathPrefix string) ([]string, error) {
+	// Check parameters
+	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
+		}
+	}
+
+	if len(pathPrefix) == 0 {
+		pathPrefix = "/"
+	}
+
+	// Retrieve deleted users with specified path prefix
+	users, err := api.UserRepo.GetDeletedUsers(pathPrefix)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	urnPrefix := GetUrnPrefix("", RESOURCE_USER, pathPrefix)
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, urnPrefix, USER_ACTION_LIST_DELETED_USERS, users)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return user IDs
+	externalIds := []string{}
+	for _, u := range usersFiltered {
+		externalIds = append(externalIds, u.ExternalID)
+	}
+
+	return externalIds, nil
+}
+
+func (api AuthAPI) RestoreUser(requestInfo RequestInfo, externalId string) (*User, error) {
+	if !IsValidUserExternalID(externalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v", externalId),
+		}
+	}
+
+	// Retrieve deleted user from DB
+	user, err := api.UserRepo.GetDeletedUserByExternalID(externalId)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_RESTORE_USER, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	// Restore user with its relationships
+	if err := api.UserRepo.RestoreUser(user.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_RESTORE_USER, user.Urn, nil, user); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User restored %+v", user))
+	return user, nil
+}
+
 func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string) ([]GroupIdentity, error) {
 	// Call repo to retrieve the user
 	user, err := api.GetUserByExternalID(requestInfo, externalId)
@@ -324,17 +449,363 @@ func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string)
 	return groupIDs, nil
 }
 
+func (api AuthAPI) RenameUser(requestInfo RequestInfo, externalId string, newExternalId string, version int64) (*User, error) {
+	if !IsValidUserExternalID(newExternalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: newExternalId %v", newExternalId),
+		}
+	}
+
+	// Call repo to retrieve the user
+	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, userDB.Urn, USER_ACTION_RENAME_USER, []User{*userDB})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, userDB.Urn),
+		}
+	}
+
+	// Check version precondition
+	if !IsExpectedVersion(version, userDB.Version) {
+		return nil, &Error{
+			Code:    VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is in version %v, not in expected version %v", externalId, userDB.Version, version),
+		}
+	}
+
+	userToUpdate := createUser(newExternalId, userDB.Path)
+
+	// Check restrictions
+	usersFiltered, err = api.GetAuthorizedUsers(requestInfo, userToUpdate.Urn, USER_ACTION_GET_USER, []User{userToUpdate})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, userToUpdate.Urn),
+		}
+	}
+
+	// Check if new externalId is already in use
+	if err := api.checkFreeUserExternalID(newExternalId); err != nil {
+		return nil, err
+	}
+
+	user, err := api.UserRepo.RenameUser(*userDB, newExternalId, userToUpdate.Urn, requestInfo.Identifier)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return nil, &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_RENAME_USER, user.Urn, userDB, user); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User renamed from %+v to %+v", userDB, user))
+	return user, nil
+}
+
+func (api AuthAPI) MergeUsers(requestInfo RequestInfo, sourceExternalId string, targetExternalId string) (*User, error) {
+	if sourceExternalId == targetExternalId {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: sourceExternalId %v, it can't be merged into itself", sourceExternalId),
+		}
+	}
+
+	// Call repo to retrieve both users
+	source, err := api.GetUserByExternalID(requestInfo, sourceExternalId)
+	if err != nil {
+		return nil, err
+	}
+	target, err := api.GetUserByExternalID(requestInfo, targetExternalId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	for _, user := range []*User{source, target} {
+		usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_MERGE_USERS, []User{*user})
+		if err != nil {
+			return nil, err
+		}
+		if len(usersFiltered) < 1 {
+			return nil, &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+					requestInfo.Identifier, user.Urn),
+			}
+		}
+	}
+
+	// Move relations of source user to target user and remove source user
+	if err := api.UserRepo.MergeUsers(*source, *target); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, USER_ACTION_MERGE_USERS, source.Urn, source, target); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User %+v merged into %+v", source, target))
+	return target, nil
+}
+
+func (api AuthAPI) SuspendUser(requestInfo RequestInfo, externalId string) (*User, error) {
+	// Suspended users can't do anything, so users can't suspend themselves
+	if externalId == requestInfo.Identifier {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v, users can't suspend themselves", externalId),
+		}
+	}
+	return api.updateUserStatus(requestInfo, externalId, USER_STATUS_SUSPENDED, USER_ACTION_SUSPEND_USER)
+}
+
+func (api AuthAPI) ReactivateUser(requestInfo RequestInfo, externalId string) (*User, error) {
+	return api.updateUserStatus(requestInfo, externalId, USER_STATUS_ACTIVE, USER_ACTION_REACTIVATE_USER)
+}
+
 // PRIVATE HELPER METHODS
 
+// Create user or service account, both are authorized with action iam:CreateUser
+func (api AuthAPI) addUser(requestInfo RequestInfo, externalId string, path string, displayName string,
+	description string, serviceAccount bool) (*User, error) {
+	// Validate fields
+	if !IsValidUserExternalID(externalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v", externalId),
+		}
+	}
+	if !IsValidPath(path) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: path %v", path),
+		}
+	}
+	if err := AreValidDescriptiveAttributes(displayName, description); err != nil {
+		return nil, err
+	}
+
+	user := createUser(externalId, path)
+	user.DisplayName = displayName
+	user.Description = description
+	user.ServiceAccount = serviceAccount
+	user.CreatedBy = requestInfo.Identifier
+	user.UpdatedBy = requestInfo.Identifier
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_CREATE_USER, []User{user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	// Check if user already exists
+	_, err = api.UserRepo.GetUserByExternalID(externalId)
+
+	if err != nil {
+		// Transform to DB error
+		dbError := err.(*database.Error)
+		// User doesn't exist in DB
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			// Check if user is deleted but not purged yet
+			if err := api.checkDeletedUser(externalId); err != nil {
+				return nil, err
+			}
+
+			// Create user
+			createdUser, err := api.UserRepo.AddUser(user)
+
+			// Check unexpected DB error
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			if err := api.recordChange(requestInfo, USER_ACTION_CREATE_USER, createdUser.Urn, nil, createdUser); err != nil {
+				return nil, err
+			}
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("User created %+v", createdUser))
+			return createdUser, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else {
+		return nil, &Error{
+			Code:    USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create user, user with externalId %v already exist", externalId),
+		}
+	}
+}
+
+// Change status of user if it isn't already in that status, that doesn't change anything
+func (api AuthAPI) updateUserStatus(requestInfo RequestInfo, externalId string, status string, action string) (*User, error) {
+	userDB, err := api.getUserForAction(requestInfo, externalId, action)
+	if err != nil {
+		return nil, err
+	}
+	if userDB.Status == status {
+		return userDB, nil
+	}
+
+	user, err := api.UserRepo.UpdateUserStatus(*userDB, status, requestInfo.Identifier)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.VERSION_MISMATCH:
+			return nil, &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if err := api.recordChange(requestInfo, action, user.Urn, userDB, user); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User status changed from %+v to %+v", userDB, user))
+	return user, nil
+}
+
+// Deleted users keep their externalId until they are purged, so it can't be reused before
+func (api AuthAPI) checkDeletedUser(externalId string) error {
+	_, err := api.UserRepo.GetDeletedUserByExternalID(externalId)
+	if err == nil {
+		return &Error{
+			Code: USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create user, user with externalId %v is deleted and must be restored or purged first",
+				externalId),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.USER_NOT_FOUND {
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return nil
+}
+
+// Check that externalId isn't used by an active user nor by a deleted user not purged yet
+func (api AuthAPI) checkFreeUserExternalID(externalId string) error {
+	_, err := api.UserRepo.GetUserByExternalID(externalId)
+	if err == nil {
+		return &Error{
+			Code:    USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to rename user, user with externalId %v already exist", externalId),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.USER_NOT_FOUND {
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	_, err = api.UserRepo.GetDeletedUserByExternalID(externalId)
+	if err == nil {
+		return &Error{
+			Code: USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to rename user, user with externalId %v is deleted and must be restored or purged first",
+				externalId),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.USER_NOT_FOUND {
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return nil
+}
+
 func createUser(externalId string, path string) User {
 	urn := CreateUrn("", RESOURCE_USER, path, externalId)
+	now := time.Now().UTC()
 	user := User{
 		ID:         uuid.NewV4().String(),
 		ExternalID: externalId,
 		Path:       path,
-		CreateAt:   time.Now().UTC(),
+		Status:     USER_STATUS_ACTIVE,
+		CreateAt:   now,
+		UpdateAt:   now,
 		Urn:        urn,
 	}
 
 	return user
 }
+
+// Retrieve user checking that requester is allowed to do the given action over it
+func (api AuthAPI) getUserForAction(requestInfo RequestInfo, externalId string, action string) (*User, error) {
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, action, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	return user, nil
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/tecsisa/foulkon/database"
@@ -12,30 +14,42 @@ func TestAuthAPI_AddUser(t *testing.T) {
 		requestInfo RequestInfo
 		externalID  string
 		path        string
+		displayName string
+		description string
 		// Expected result
-		expectedUser *User
-		wantError    error
+		expectedUser      *User
+		expectedCreatedBy string
+		wantError         error
 		// Manager Results
 		getUserByExternalIDMethodResult      *User
 		getUserByExternalIDMethodSpecialFunc func(string) (*User, error)
 		getGroupsByUserIDResult              []Group
-		getAttachedPoliciesResult            []Policy
+		getAttachedPoliciesResult            []GroupPolicy
+		getDeletedUserByExternalIDResult     *User
 		// API Errors
-		addUserMethodErr             error
-		getUserByExternalIDMethodErr error
+		addUserMethodErr                    error
+		getUserByExternalIDMethodErr        error
+		getDeletedUserByExternalIDMethodErr error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			externalID: "1234",
-			path:       "/example/",
+			externalID:  "1234",
+			path:        "/example/",
+			displayName: "Example user",
+			description: "User of the example department",
 			expectedUser: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/example/",
-			},
+				ID:          "543210",
+				ExternalID:  "1234",
+				Path:        "/example/",
+				DisplayName: "Example user",
+				Description: "User of the example department",
+				CreatedBy:   "123456",
+				UpdatedBy:   "123456",
+			},
+			expectedCreatedBy: "123456",
 			getUserByExternalIDMethodErr: &database.Error{
 				Code:    database.USER_NOT_FOUND,
 				Message: "User not found",
@@ -77,20 +91,22 @@ func TestAuthAPI_AddUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_CREATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/example/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_CREATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/"),
+								},
 							},
 						},
 					},
@@ -110,6 +126,26 @@ func TestAuthAPI_AddUser(t *testing.T) {
 				Message: "Invalid parameter: externalId ",
 			},
 		},
+		"ErrorCaseInvalidDisplayName": {
+			externalID:  "012",
+			path:        "/example/",
+			displayName: strings.Repeat("a", MAX_DISPLAY_NAME_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: displayName length %v, it can't be greater than %v",
+					MAX_DISPLAY_NAME_LENGTH+1, MAX_DISPLAY_NAME_LENGTH),
+			},
+		},
+		"ErrorCaseInvalidDescription": {
+			externalID:  "012",
+			path:        "/example/",
+			description: strings.Repeat("a", MAX_DESCRIPTION_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: description length %v, it can't be greater than %v",
+					MAX_DESCRIPTION_LENGTH+1, MAX_DESCRIPTION_LENGTH),
+			},
+		},
 		"ErrorCaseInvalidPath": {
 			externalID: "012",
 			path:       "/**%%/*123",
@@ -166,29 +202,31 @@ func TestAuthAPI_AddUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_CREATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/test/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_CREATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/test/asd"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_CREATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/test/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_CREATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/test/asd"),
+								},
 							},
 						},
 					},
@@ -220,13 +258,15 @@ func TestAuthAPI_AddUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Path:       "/path/",
-					Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Path:       "/path/",
+						Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 		},
@@ -288,6 +328,44 @@ func TestAuthAPI_AddUser(t *testing.T) {
 				Message: "Unable to create user, user with externalId 1234 already exist",
 			},
 		},
+		"ErrorCaseUserDeleted": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			path:       "/example/",
+			wantError: &Error{
+				Code:    USER_ALREADY_EXIST,
+				Message: "Unable to create user, user with externalId 1234 is deleted and must be restored or purged first",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+		},
+		"ErrorCaseGetDeletedUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			path:       "/example/",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+			getDeletedUserByExternalIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -301,10 +379,169 @@ func TestAuthAPI_AddUser(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[AddUserMethod][0] = testcase.expectedUser
 		testRepo.ArgsOut[AddUserMethod][1] = testcase.addUserMethodErr
-		user, err := testAPI.AddUser(testcase.requestInfo, testcase.externalID, testcase.path)
+		if testcase.getDeletedUserByExternalIDResult != nil || testcase.getDeletedUserByExternalIDMethodErr != nil {
+			testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][0] = testcase.getDeletedUserByExternalIDResult
+			testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][1] = testcase.getDeletedUserByExternalIDMethodErr
+		}
+		user, err := testAPI.AddUser(testcase.requestInfo, testcase.externalID, testcase.path, testcase.displayName,
+			testcase.description)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+		if testcase.expectedCreatedBy != "" {
+			if userIn := testRepo.ArgsIn[AddUserMethod][0].(User); userIn.CreatedBy != testcase.expectedCreatedBy {
+				t.Errorf("Test %v failed. Expected creator %v, received %v", x, testcase.expectedCreatedBy, userIn.CreatedBy)
+			}
+		}
+	}
+
+}
+
+func TestAuthAPI_AddServiceAccount(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalID  string
+		path        string
+		// Expected result
+		expectedUser *User
+		wantError    error
+		// API Errors
+		getUserByExternalIDMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "deployer",
+			path:       "/services/",
+			expectedUser: &User{
+				ID:             "543210",
+				ExternalID:     "deployer",
+				Path:           "/services/",
+				ServiceAccount: true,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUserAlreadyExists": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "deployer",
+			path:       "/services/",
+			wantError: &Error{
+				Code:    USER_ALREADY_EXIST,
+				Message: "Unable to create user, user with externalId deployer already exist",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[AddUserMethod][0] = testcase.expectedUser
+		user, err := testAPI.AddServiceAccount(testcase.requestInfo, testcase.externalID, testcase.path, "", "")
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+		if testcase.wantError == nil {
+			if userIn := testRepo.ArgsIn[AddUserMethod][0].(User); !userIn.ServiceAccount {
+				t.Errorf("Test %v failed. Expected service account, received user %v", x, userIn)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_ProvisionUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		path       string
+		// Expected result
+		expectedUser *User
+		wantError    error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		addUserMethodResult             *User
+		// API Errors
+		getUserByExternalIDMethodErr error
+	}{
+		"OKCaseUserExists": {
+			externalID: "123",
+			path:       "/example/",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "123",
+				Path:       "/users/",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "123",
+				Path:       "/users/",
+			},
+		},
+		"OKCaseUserCreated": {
+			externalID: "123",
+			path:       "/example/",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "123",
+				Path:       "/example/",
+			},
+			addUserMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "123",
+				Path:       "/example/",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseInvalidPath": {
+			externalID: "123",
+			path:       "/invalid path/",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: path /invalid path/",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseInternalError": {
+			externalID: "123",
+			path:       "/example/",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
 	}
 
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[AddUserMethod][0] = testcase.addUserMethodResult
+		user, err := testAPI.ProvisionUser("request", testcase.externalID, testcase.path)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+		if testcase.addUserMethodResult != nil {
+			if userIn := testRepo.ArgsIn[AddUserMethod][0].(User); userIn.CreatedBy != testcase.externalID {
+				t.Errorf("Test %v failed. Expected user created by itself, received user %v", x, userIn)
+			}
+		}
+	}
 }
 
 func TestAuthAPI_GetUserByExternalID(t *testing.T) {
@@ -317,7 +554,7 @@ func TestAuthAPI_GetUserByExternalID(t *testing.T) {
 		wantError    error
 		// Manager Results
 		getGroupsByUserIDMethodResult   []Group
-		getAttachedPoliciesMethodResult []Policy
+		getAttachedPoliciesMethodResult []GroupPolicy
 		getUserByExternalIDMethodResult *User
 		// API Errors
 		getUserByExternalIDMethodErr error
@@ -367,20 +604,22 @@ func TestAuthAPI_GetUserByExternalID(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
 					},
@@ -461,29 +700,31 @@ func TestAuthAPI_GetUserByExternalID(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "000"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "000"),
+								},
 							},
 						},
 					},
@@ -514,13 +755,15 @@ func TestAuthAPI_GetUserByExternalID(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Path:       "/path/",
-					Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Path:       "/path/",
+						Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 		},
@@ -551,7 +794,7 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 		// Manager Results
 		getUsersFilteredMethodResult    []User
 		getGroupsByUserIDMethodResult   []Group
-		getAttachedPoliciesMethodResult []Policy
+		getAttachedPoliciesMethodResult []GroupPolicy
 		getUserByExternalIDMethodResult *User
 		// API Errors
 		GetUsersFilteredMethodErr    error
@@ -614,20 +857,22 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_USERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
 							},
 						},
 					},
@@ -669,29 +914,31 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_USERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_LIST_USERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/example/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_LIST_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/"),
+								},
 							},
 						},
 					},
@@ -774,13 +1021,15 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Path:       "/path/",
-					Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
+					Policy: Policy{
+						ID:         "POLICY-USER-ID",
+						Name:       "policyUser",
+						Path:       "/path/",
+						Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{},
+					},
 				},
 			},
 		},
@@ -816,29 +1065,31 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_USERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/example/test/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_LIST_USERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/example/test/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/test/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_LIST_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/test/"),
+								},
 							},
 						},
 					},
@@ -879,16 +1130,20 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 func TestAuthAPI_UpdateUser(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		requestInfo RequestInfo
-		externalID  string
-		newPath     string
+		requestInfo    RequestInfo
+		externalID     string
+		newPath        string
+		newDisplayName string
+		newDescription string
+		version        int64
 		// Expected result
-		expectedUser *User
-		wantError    error
+		expectedUser      *User
+		expectedUpdatedBy string
+		wantError         error
 		// Manager Results
 		getUserByExternalIDMethodResult *User
 		getGroupsByUserIDMethodResult   []Group
-		getAttachedPoliciesMethodResult []Policy
+		getAttachedPoliciesMethodResult []GroupPolicy
 		// API Errors
 		updateUserMethodErr          error
 		getUserByExternalIDMethodErr error
@@ -898,14 +1153,20 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			externalID: "1234",
-			newPath:    "/example2/",
+			externalID:     "1234",
+			newPath:        "/example2/",
+			newDisplayName: "Example user",
+			newDescription: "User of the example department",
 			expectedUser: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/example2/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
-			},
+				ID:          "543210",
+				ExternalID:  "1234",
+				Path:        "/example2/",
+				DisplayName: "Example user",
+				Description: "User of the example department",
+				UpdatedBy:   "123456",
+				Urn:         CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+			expectedUpdatedBy: "123456",
 			getUserByExternalIDMethodResult: &User{
 				ID:         "543210",
 				ExternalID: "1234",
@@ -940,21 +1201,23 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-								USER_ACTION_UPDATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, ""),
+								},
 							},
 						},
 					},
@@ -974,6 +1237,16 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 				Message: "Invalid parameter: path ",
 			},
 		},
+		"ErrorCaseInvalidDescription": {
+			externalID:     "1234",
+			newPath:        "/example/",
+			newDescription: strings.Repeat("a", MAX_DESCRIPTION_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: description length %v, it can't be greater than %v",
+					MAX_DESCRIPTION_LENGTH+1, MAX_DESCRIPTION_LENGTH),
+			},
+		},
 		"ErrorCaseInvalidExtID": {
 			externalID: "*%~#@|",
 			newPath:    "/example/",
@@ -1060,20 +1333,22 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
 					},
@@ -1105,38 +1380,40 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_UPDATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_UPDATE_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "000"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "000"),
+								},
 							},
 						},
 					},
@@ -1168,38 +1445,40 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_UPDATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/newpath/", "000"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/newpath/", "000"),
+								},
 							},
 						},
 					},
@@ -1231,47 +1510,49 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_UPDATE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/newpath/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/newpath/", "000"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/newpath/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/newpath/", "000"),
+								},
 							},
 						},
 					},
@@ -1298,35 +1579,105 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
-	}
-
-	for x, testcase := range testcases {
-		testRepo := makeTestRepo()
-		testAPI := makeTestAPI(testRepo)
-
-		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
-		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
-		testRepo.ArgsOut[UpdateUserMethod][0] = testcase.expectedUser
-		testRepo.ArgsOut[UpdateUserMethod][1] = testcase.updateUserMethodErr
-		user, err := testAPI.UpdateUser(testcase.requestInfo, testcase.externalID, testcase.newPath)
-		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
-	}
-
-}
-
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			version:    3,
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example2/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example2/", "1234"),
+				Version:    4,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				Version:    3,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			version:    2,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with externalId 1234 is in version 3, not in expected version 2",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				Version:    3,
+			},
+		},
+		"ErrorCaseUpdateUserDBVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			wantError: &Error{
+				Code: VERSION_MISMATCH_ERROR,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				Version:    3,
+			},
+			updateUserMethodErr: &database.Error{
+				Code: database.VERSION_MISMATCH,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[UpdateUserMethod][0] = testcase.expectedUser
+		testRepo.ArgsOut[UpdateUserMethod][1] = testcase.updateUserMethodErr
+		user, err := testAPI.UpdateUser(testcase.requestInfo, testcase.externalID, testcase.newPath, testcase.newDisplayName,
+			testcase.newDescription, testcase.version)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+		if testcase.expectedUpdatedBy != "" && testRepo.ArgsIn[UpdateUserMethod][5] != testcase.expectedUpdatedBy {
+			t.Errorf("Test %v failed. Expected updater %v, received %v", x, testcase.expectedUpdatedBy,
+				testRepo.ArgsIn[UpdateUserMethod][5])
+		}
+	}
+
+}
+
 func TestAuthAPI_RemoveUser(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		requestInfo RequestInfo
 		externalID  string
+		version     int64
 		// Expected result
 		wantError error
 		// Manager Results
 		getUserByExternalIDMethodResult *User
 		getGroupsByUserIDResult         []Group
-		getAttachedPoliciesResult       []Policy
+		getAttachedPoliciesResult       []GroupPolicy
 		// API Errors
 		getUserByExternalIDMethodErr error
 		removeUserMethodErr          error
@@ -1363,21 +1714,23 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-								USER_ACTION_DELETE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_DELETE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
 					},
@@ -1464,20 +1817,22 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
 					},
@@ -1508,38 +1863,40 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_DELETE_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
-							},
-						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_DELETE_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_DELETE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_DELETE_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+								},
 							},
 						},
 					},
@@ -1564,6 +1921,60 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"OKCaseExpectedVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			version:    3,
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Version:    3,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			version:    2,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with externalId 1234 is in version 3, not in expected version 2",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Version:    3,
+			},
+		},
+		"ErrorCaseModifiedMeanwhile": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			version:    3,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with id 543210 isn't in version 3",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Version:    3,
+			},
+			removeUserMethodErr: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with id 543210 isn't in version 3",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -1575,62 +1986,44 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[RemoveUserMethod][0] = testcase.removeUserMethodErr
-		err := testAPI.RemoveUser(testcase.requestInfo, testcase.externalID)
+		err := testAPI.RemoveUser(testcase.requestInfo, testcase.externalID, testcase.version)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		// Check user is removed in the version that was retrieved
+		if testcase.wantError == nil && testRepo.ArgsIn[RemoveUserMethod][1] != testcase.getUserByExternalIDMethodResult.Version {
+			t.Errorf("Test %v failed. Received different version %v", x, testRepo.ArgsIn[RemoveUserMethod][1])
+		}
 	}
 }
 
-func TestAuthAPI_ListGroupsByUser(t *testing.T) {
+func TestAuthAPI_ListDeletedUsers(t *testing.T) {
 	testcases := map[string]struct {
-		// API Method args
+		// API method args
 		requestInfo RequestInfo
-		externalID  string
-		wantError   error
+		pathPrefix  string
 		// Expected result
-		expectedResponse []GroupIdentity
+		expectedResult []string
+		wantError      error
 		// Manager Results
 		getUserByExternalIDMethodResult *User
-		getGroupsByUserIDMethodResult   []Group
-		getAttachedPoliciesMethodResult []Policy
-		// Manager Errors
-		getGroupsByUserIDMethodErr   error
-		getUserByExternalIDMethodErr error
+		getGroupsByUserIDResult         []Group
+		getAttachedPoliciesResult       []GroupPolicy
+		getDeletedUsersMethodResult     []User
+		// API Errors
+		getDeletedUsersMethodErr error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			externalID: "1234",
-			expectedResponse: []GroupIdentity{
-				{
-					Org:  "org1",
-					Name: "groupUser1",
-				},
-				{
-					Org:  "org2",
-					Name: "groupUser2",
-				},
-			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/example/",
-			},
-			getGroupsByUserIDMethodResult: []Group{
-				{
-					ID:   "GROUP1",
-					Org:  "org1",
-					Name: "groupUser1",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
-				},
+			pathPrefix:     "/example/",
+			expectedResult: []string{"1234"},
+			getDeletedUsersMethodResult: []User{
 				{
-					ID:   "GROUP2",
-					Org:  "org2",
-					Name: "groupUser2",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
 				},
 			},
 		},
@@ -1639,248 +2032,1673 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			externalID: "1234",
-			expectedResponse: []GroupIdentity{
-				{
-					Org:  "org1",
-					Name: "group1",
-				},
-				{
-					Org:  "org2",
-					Name: "group2",
-				},
-			},
+			expectedResult: []string{"1234"},
 			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
+				ID:         "123456",
+				ExternalID: "123456",
 				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
 			},
-			getGroupsByUserIDMethodResult: []Group{
-				{
-					ID:   "GROUP-USER-ID-1",
-					Name: "group1",
-					Path: "/path/1/",
-					Org:  "org1",
-					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
-				},
+			getGroupsByUserIDResult: []Group{
 				{
-					ID:   "GROUP-USER-ID-2",
-					Name: "group2",
-					Path: "/path/2/",
-					Org:  "org2",
-					Urn:  CreateUrn("org2", RESOURCE_GROUP, "/path/2/", "group2"),
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_GROUPS_FOR_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_DELETED_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/"),
+								},
 							},
 						},
 					},
 				},
 			},
-		},
-		"ErrorCaseNoAuth": {
-			requestInfo: RequestInfo{
-				Identifier: "1234",
-				Admin:      false,
-			},
-			externalID: "1234",
-			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/example/12345",
-			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "12345",
-				Path:       "/example/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "12345"),
-			},
-		},
-		"ErrorCaseUserNotFound": {
-			requestInfo: RequestInfo{
-				Identifier: "1234",
-				Admin:      false,
-			},
-			externalID: "1234",
-			wantError: &Error{
-				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
-			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.USER_NOT_FOUND,
+			getDeletedUsersMethodResult: []User{
+				{
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				},
+				{
+					ID:         "654321",
+					ExternalID: "4321",
+					Path:       "/other/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/other/", "4321"),
+				},
 			},
 		},
-		"ErrorCaseGetUserExtIDDBErr": {
+		"ErrorCaseInvalidPath": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			externalID: "1234",
+			pathPrefix: "/example/das~",
 			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
-			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: PathPrefix /example/das~",
 			},
 		},
-		"ErrorCaseGetGroupsDBErr": {
+		"ErrorCaseGetDeletedUsersDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			externalID: "1234",
+			pathPrefix: "/example/",
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/example/",
-			},
-			getGroupsByUserIDMethodErr: &database.Error{
+			getDeletedUsersMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
 		},
-		"ErrorCaseNoPermissions": {
-			requestInfo: RequestInfo{
-				Identifier: "1234",
-				Admin:      false,
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetDeletedUsersMethod][0] = testcase.getDeletedUsersMethodResult
+		testRepo.ArgsOut[GetDeletedUsersMethod][1] = testcase.getDeletedUsersMethodErr
+		users, err := testAPI.ListDeletedUsers(testcase.requestInfo, testcase.pathPrefix)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, users)
+	}
+}
+
+func TestAuthAPI_RestoreUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalID  string
+		// Expected result
+		expectedUser *User
+		wantError    error
+		// Manager Results
+		getUserByExternalIDMethodResult  *User
+		getGroupsByUserIDResult          []Group
+		getAttachedPoliciesResult        []GroupPolicy
+		getDeletedUserByExternalIDResult *User
+		// API Errors
+		getDeletedUserByExternalIDMethodErr error
+		restoreUserMethodErr                error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID: "1234",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_RESTORE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/example/"),
+								},
+							},
+						},
+					},
+				},
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+		},
+		"ErrorCaseInvalidExtID": {
+			externalID: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId *%~#@|",
+			},
+		},
+		"ErrorCaseDeletedUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Deleted user with externalId 1234 not found",
+			},
+			getDeletedUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "Deleted user with externalId 1234 not found",
+			},
+		},
+		"ErrorCaseGetDeletedUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedUserByExternalIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/example/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+		},
+		"ErrorCaseRestoreUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+			restoreUserMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][0] = testcase.getDeletedUserByExternalIDResult
+		testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][1] = testcase.getDeletedUserByExternalIDMethodErr
+		testRepo.ArgsOut[RestoreUserMethod][0] = testcase.restoreUserMethodErr
+		user, err := testAPI.RestoreUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+	}
+}
+
+func TestAuthAPI_ListGroupsByUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		externalID  string
+		wantError   error
+		// Expected result
+		expectedResponse []GroupIdentity
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []GroupPolicy
+		// Manager Errors
+		getGroupsByUserIDMethodErr   error
+		getUserByExternalIDMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			expectedResponse: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "groupUser1",
+				},
+				{
+					Org:  "org2",
+					Name: "groupUser2",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP1",
+					Org:  "org1",
+					Name: "groupUser1",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
+				},
+				{
+					ID:   "GROUP2",
+					Org:  "org2",
+					Name: "groupUser2",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
+				},
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID: "1234",
+			expectedResponse: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+				},
+				{
+					ID:   "GROUP-USER-ID-2",
+					Name: "group2",
+					Path: "/path/2/",
+					Org:  "org2",
+					Urn:  CreateUrn("org2", RESOURCE_GROUP, "/path/2/", "group2"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_GROUPS_FOR_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseNoAuth": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/example/12345",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "12345"),
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseGetUserExtIDDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseGetGroupsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+			},
+			getGroupsByUserIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/1/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_LIST_GROUPS_FOR_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "deny",
+								Actions: []string{
+									USER_ACTION_LIST_GROUPS_FOR_USER,
+								},
+								Resources: []string{
+									CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseUnauthorizedListGroups": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "12345",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/1/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDMethodErr
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		groups, err := testAPI.ListGroupsByUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, groups)
+	}
+
+}
+
+func TestAuthAPI_RenameUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo   RequestInfo
+		externalID    string
+		newExternalID string
+		version       int64
+		// Expected result
+		expectedUser   *User
+		expectedNewUrn string
+		wantError      error
+		// Manager Results
+		users                            map[string]*User
+		getGroupsByUserIDMethodResult    []Group
+		getAttachedPoliciesMethodResult  []GroupPolicy
+		getDeletedUserByExternalIDResult *User
+		// API Errors
+		renameUserMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			version:       3,
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "4321",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+				Version:    4,
+			},
+			expectedNewUrn: CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+					Version:    3,
+				},
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:    "000",
+			newExternalID: "001",
+			expectedUser: &User{
+				ID:         "000",
+				ExternalID: "001",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "001"),
+			},
+			expectedNewUrn: CreateUrn("", RESOURCE_USER, "/path/", "001"),
+			users: map[string]*User{
+				"123456": {
+					ID:         "123456",
+					ExternalID: "123456",
+					Path:       "/admin/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "123456"),
+				},
+				"000": {
+					ID:         "000",
+					ExternalID: "000",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "000"),
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_RENAME_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidNewExtID": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: newExternalId *%~#@|",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId 1234 not found",
+			},
+		},
+		"ErrorCaseRenameNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:    "000",
+			newExternalID: "001",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/path/000",
+			},
+			users: map[string]*User{
+				"123456": {
+					ID:         "123456",
+					ExternalID: "123456",
+					Path:       "/admin/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "123456"),
+				},
+				"000": {
+					ID:         "000",
+					ExternalID: "000",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "000"),
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_UPDATE_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			version:       2,
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with externalId 1234 is in version 3, not in expected version 2",
+			},
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+					Version:    3,
+				},
+			},
+		},
+		"ErrorCaseNewExtIDAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			wantError: &Error{
+				Code:    USER_ALREADY_EXIST,
+				Message: "Unable to rename user, user with externalId 4321 already exist",
+			},
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				},
+				"4321": {
+					ID:         "012345",
+					ExternalID: "4321",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+				},
+			},
+		},
+		"ErrorCaseNewExtIDDeleted": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			wantError: &Error{
+				Code:    USER_ALREADY_EXIST,
+				Message: "Unable to rename user, user with externalId 4321 is deleted and must be restored or purged first",
+			},
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				},
+			},
+			getDeletedUserByExternalIDResult: &User{
+				ID:         "012345",
+				ExternalID: "4321",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+			},
+		},
+		"ErrorCaseRenameUserDBVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with externalId 1234 isn't in version 3",
+			},
+			expectedNewUrn: CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+					Version:    3,
+				},
+			},
+			renameUserMethodErr: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with externalId 1234 isn't in version 3",
+			},
+		},
+		"ErrorCaseRenameUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "4321",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			expectedNewUrn: CreateUrn("", RESOURCE_USER, "/example/", "4321"),
+			users: map[string]*User{
+				"1234": {
+					ID:         "543210",
+					ExternalID: "1234",
+					Path:       "/example/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				},
+			},
+			renameUserMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		users := testcase.users
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: fmt.Sprintf("User with externalId %v not found", id),
+			}
+		}
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		if testcase.getDeletedUserByExternalIDResult != nil {
+			testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][0] = testcase.getDeletedUserByExternalIDResult
+			testRepo.ArgsOut[GetDeletedUserByExternalIDMethod][1] = nil
+		}
+		testRepo.ArgsOut[RenameUserMethod][0] = testcase.expectedUser
+		testRepo.ArgsOut[RenameUserMethod][1] = testcase.renameUserMethodErr
+		user, err := testAPI.RenameUser(testcase.requestInfo, testcase.externalID, testcase.newExternalID, testcase.version)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+
+		// Check new urn received by repo
+		if testcase.expectedNewUrn != "" && testRepo.ArgsIn[RenameUserMethod][2] != testcase.expectedNewUrn {
+			t.Errorf("Test %v failed. Received different urn (wanted:%v / received:%v)",
+				x, testcase.expectedNewUrn, testRepo.ArgsIn[RenameUserMethod][2])
+		}
+	}
+}
+
+func TestAuthAPI_MergeUsers(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo      RequestInfo
+		sourceExternalID string
+		targetExternalID string
+		// Expected result
+		expectedUser *User
+		wantError    error
+		// Manager Results
+		users                           map[string]*User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []GroupPolicy
+		// API Errors
+		mergeUsersMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "new",
+			expectedUser: &User{
+				ID:         "2",
+				ExternalID: "new",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "new"),
+			},
+			users: map[string]*User{
+				"old": {
+					ID:         "1",
+					ExternalID: "old",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "old"),
+				},
+				"new": {
+					ID:         "2",
+					ExternalID: "new",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "new"),
+				},
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "new",
+			expectedUser: &User{
+				ID:         "2",
+				ExternalID: "new",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "new"),
+			},
+			users: map[string]*User{
+				"123456": {
+					ID:         "123456",
+					ExternalID: "123456",
+					Path:       "/admin/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "123456"),
+				},
+				"old": {
+					ID:         "1",
+					ExternalID: "old",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "old"),
+				},
+				"new": {
+					ID:         "2",
+					ExternalID: "new",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "new"),
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_MERGE_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseSameUser": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "old",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: sourceExternalId old, it can't be merged into itself",
 			},
-			externalID: "1234",
+		},
+		"ErrorCaseTargetNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "new",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId new not found",
 			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			users: map[string]*User{
+				"old": {
+					ID:         "1",
+					ExternalID: "old",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "old"),
+				},
+			},
+		},
+		"ErrorCaseTargetNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "new",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/other/new",
+			},
+			users: map[string]*User{
+				"123456": {
+					ID:         "123456",
+					ExternalID: "123456",
+					Path:       "/admin/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "123456"),
+				},
+				"old": {
+					ID:         "1",
+					ExternalID: "old",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "old"),
+				},
+				"new": {
+					ID:         "2",
+					ExternalID: "new",
+					Path:       "/other/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/other/", "new"),
+				},
 			},
 			getGroupsByUserIDMethodResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
-					Path: "/path/1/",
+					Path: "/path/",
 					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/path/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_MERGE_USERS,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
+							},
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/other/"),
+								},
 							},
 						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_GROUPS_FOR_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+		},
+		"ErrorCaseMergeUsersDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			sourceExternalID: "old",
+			targetExternalID: "new",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			users: map[string]*User{
+				"old": {
+					ID:         "1",
+					ExternalID: "old",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "old"),
+				},
+				"new": {
+					ID:         "2",
+					ExternalID: "new",
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "new"),
+				},
+			},
+			mergeUsersMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		users := testcase.users
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: fmt.Sprintf("User with externalId %v not found", id),
+			}
+		}
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[MergeUsersMethod][0] = testcase.mergeUsersMethodErr
+		user, err := testAPI.MergeUsers(testcase.requestInfo, testcase.sourceExternalID, testcase.targetExternalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+
+		// Check users received by repo
+		if testcase.wantError == nil {
+			if source := testRepo.ArgsIn[MergeUsersMethod][0].(User); source.ID != users[testcase.sourceExternalID].ID {
+				t.Errorf("Test %v failed. Received different source user %v", x, source)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_SuspendUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalID  string
+		// Expected result
+		expectedUser   *User
+		wantError      error
+		expectedUpdate bool
+		// Manager Results
+		users                           map[string]*User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []GroupPolicy
+		updateUserStatusMethodResult    *User
+		// Manager Errors
+		updateUserStatusMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			expectedUser: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+			expectedUpdate: true,
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+					Version:    1,
+				},
+			},
+			updateUserStatusMethodResult: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+		},
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "requester",
+				Admin:      false,
+			},
+			externalID: "user",
+			expectedUser: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+			expectedUpdate: true,
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+					Version:    1,
+				},
+				"requester": {
+					ID:         "2",
+					ExternalID: "requester",
+					Path:       "/admin/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "requester"),
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/admin/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/admin/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/admin/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/admin/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+									USER_ACTION_SUSPEND_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_LIST_GROUPS_FOR_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+					},
+				},
+			},
+			updateUserStatusMethodResult: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+		},
+		"OkCaseAlreadySuspended": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			expectedUser: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_SUSPENDED,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+					Version:    2,
+				},
+			},
+		},
+		"ErrorCaseSuspendItself": {
+			requestInfo: RequestInfo{
+				Identifier: "user",
+				Admin:      false,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId user, users can't suspend themselves",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId user not found",
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "requester",
+				Admin:      false,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId requester is not allowed to access to resource urn:iws:iam::user/path/user",
+			},
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				},
+				"requester": {
+					ID:         "2",
+					ExternalID: "requester",
+					Path:       "/admin/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "requester"),
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/admin/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/admin/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []GroupPolicy{
+				{
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/admin/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/admin/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									USER_ACTION_GET_USER,
+								},
+								Resources: []string{
+									GetUrnPrefix("", RESOURCE_USER, "/path/"),
+								},
 							},
 						},
 					},
 				},
 			},
 		},
-		"ErrorCaseUnauthorizedListGroups": {
+		"ErrorCaseSuspendedRequester": {
 			requestInfo: RequestInfo{
-				Identifier: "1234",
+				Identifier: "requester",
 				Admin:      false,
 			},
-			externalID: "12345",
+			externalID: "user",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+				Message: "Authenticated user with externalId requester is suspended",
 			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				},
+				"requester": {
+					ID:         "2",
+					ExternalID: "requester",
+					Path:       "/admin/",
+					Status:     USER_STATUS_SUSPENDED,
+					Urn:        CreateUrn("", RESOURCE_USER, "/admin/", "requester"),
+				},
 			},
 			getGroupsByUserIDMethodResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
-					Path: "/path/1/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+					Path: "/admin/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/admin/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesMethodResult: []GroupPolicy{
 				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					Policy: Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Path: "/admin/",
+						Urn:  CreateUrn("example", RESOURCE_POLICY, "/admin/", "policyUser"),
+						Statements: &[]Statement{
+							{
+								Effect: "allow",
+								Actions: []string{
+									"iam:*",
+								},
+								Resources: []string{
+									"urn:*",
+								},
 							},
 						},
 					},
 				},
 			},
 		},
+		"ErrorCaseVersionMismatch": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    VERSION_MISMATCH_ERROR,
+				Message: "User with externalId user isn't in version 1",
+			},
+			expectedUpdate: true,
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+					Version:    1,
+				},
+			},
+			updateUserStatusMethodErr: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with externalId user isn't in version 1",
+			},
+		},
+		"ErrorCaseUpdateUserStatusDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			expectedUpdate: true,
+			users: map[string]*User{
+				"user": {
+					ID:         "1",
+					ExternalID: "user",
+					Path:       "/path/",
+					Status:     USER_STATUS_ACTIVE,
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+					Version:    1,
+				},
+			},
+			updateUserStatusMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
 		testRepo := makeTestRepo()
 		testAPI := makeTestAPI(testRepo)
 
-		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
-		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		users := testcase.users
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: fmt.Sprintf("User with externalId %v not found", id),
+			}
+		}
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDMethodErr
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
-		groups, err := testAPI.ListGroupsByUser(testcase.requestInfo, testcase.externalID)
-		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, groups)
+		testRepo.ArgsOut[UpdateUserStatusMethod][0] = testcase.updateUserStatusMethodResult
+		testRepo.ArgsOut[UpdateUserStatusMethod][1] = testcase.updateUserStatusMethodErr
+		user, err := testAPI.SuspendUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+
+		// Check status received by repo
+		status, updated := testRepo.ArgsIn[UpdateUserStatusMethod][1].(string)
+		if updated != testcase.expectedUpdate || (updated && status != USER_STATUS_SUSPENDED) {
+			t.Errorf("Test %v failed. Received different status update %v", x, testRepo.ArgsIn[UpdateUserStatusMethod])
+		}
+	}
+}
+
+func TestAuthAPI_ReactivateUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalID  string
+		// Expected result
+		expectedUser   *User
+		wantError      error
+		expectedUpdate bool
+		// Manager Results
+		getUserByExternalIDResult    *User
+		updateUserStatusMethodResult *User
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			expectedUser: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    3,
+			},
+			expectedUpdate: true,
+			getUserByExternalIDResult: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    2,
+			},
+			updateUserStatusMethodResult: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    3,
+			},
+		},
+		"OkCaseAlreadyActive": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			expectedUser: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    1,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "1",
+				ExternalID: "user",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user"),
+				Version:    1,
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "user",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId user not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User with externalId user not found",
+			},
+		},
 	}
 
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[UpdateUserStatusMethod][0] = testcase.updateUserStatusMethodResult
+		user, err := testAPI.ReactivateUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+
+		// Check status received by repo
+		status, updated := testRepo.ArgsIn[UpdateUserStatusMethod][1].(string)
+		if updated != testcase.expectedUpdate || (updated && status != USER_STATUS_ACTIVE) {
+			t.Errorf("Test %v failed. Received different status update %v", x, testRepo.ArgsIn[UpdateUserStatusMethod])
+		}
+	}
 }
@@ -14,11 +14,38 @@ const (
 	RESOURCE_USER   = "user"
 	RESOURCE_POLICY = "policy"
 
+	RESOURCE_ORGANIZATION  = "organization"
+	RESOURCE_WEBHOOK       = "webhook"
+	RESOURCE_GROUP_MAPPING = "groupmapping"
+
 	// Constraints
 	MAX_EXTERNAL_ID_LENGTH = 128
 	MAX_NAME_LENGTH        = 128
 	MAX_ACTION_LENGTH      = 128
 	MAX_PATH_LENGTH        = 512
+	MAX_BULK_ITEMS         = 1000
+
+	// Descriptive attributes constraints
+	MAX_DISPLAY_NAME_LENGTH = 256
+	MAX_DESCRIPTION_LENGTH  = 1024
+
+	// Access request constraints, duration in seconds
+	MAX_JUSTIFICATION_LENGTH    = 1024
+	MAX_ACCESS_REQUEST_DURATION = 7 * 24 * 60 * 60
+
+	// Webhook constraints
+	MAX_URL_LENGTH            = 2048
+	MAX_WEBHOOK_SECRET_LENGTH = 256
+
+	// Change feed constraints
+	MAX_CHANGES_LIMIT = 1000
+
+	// Admin credentials and local passwords constraints, bcrypt ignores bytes beyond 72
+	MAX_PASSWORD_LENGTH      = 72
+	MIN_USER_PASSWORD_LENGTH = 8
+
+	// Group mapping constraints
+	MAX_CLAIM_LENGTH = 256
 
 	// Actions
 
@@ -29,6 +56,21 @@ const (
 	USER_ACTION_LIST_USERS           = "iam:ListUsers"
 	USER_ACTION_UPDATE_USER          = "iam:UpdateUser"
 	USER_ACTION_LIST_GROUPS_FOR_USER = "iam:ListGroupsForUser"
+	USER_ACTION_LIST_DELETED_USERS   = "iam:ListDeletedUsers"
+	USER_ACTION_RESTORE_USER         = "iam:RestoreUser"
+	USER_ACTION_RENAME_USER          = "iam:RenameUser"
+	USER_ACTION_MERGE_USERS          = "iam:MergeUsers"
+	USER_ACTION_CREATE_API_KEY       = "iam:CreateApiKey"
+	USER_ACTION_LIST_API_KEYS        = "iam:ListApiKeys"
+	USER_ACTION_ROTATE_API_KEY       = "iam:RotateApiKey"
+	USER_ACTION_REVOKE_API_KEY       = "iam:RevokeApiKey"
+	USER_ACTION_ADD_ADMIN            = "iam:AddAdmin"
+	USER_ACTION_LIST_ADMINS          = "iam:ListAdmins"
+	USER_ACTION_REMOVE_ADMIN         = "iam:RemoveAdmin"
+	USER_ACTION_SET_USER_PASSWORD    = "iam:SetUserPassword"
+	USER_ACTION_RESET_USER_PASSWORD  = "iam:ResetUserPassword"
+	USER_ACTION_SUSPEND_USER         = "iam:SuspendUser"
+	USER_ACTION_REACTIVATE_USER      = "iam:ReactivateUser"
 
 	// Group actions
 	GROUP_ACTION_CREATE_GROUP                 = "iam:CreateGroup"
@@ -42,14 +84,52 @@ const (
 	GROUP_ACTION_ATTACH_GROUP_POLICY          = "iam:AttachGroupPolicy"
 	GROUP_ACTION_DETACH_GROUP_POLICY          = "iam:DetachGroupPolicy"
 	GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES = "iam:ListAttachedGroupPolicies"
+	GROUP_ACTION_LIST_DELETED_GROUPS          = "iam:ListDeletedGroups"
+	GROUP_ACTION_RESTORE_GROUP                = "iam:RestoreGroup"
 
 	// Policy actions
-	POLICY_ACTION_CREATE_POLICY        = "iam:CreatePolicy"
-	POLICY_ACTION_DELETE_POLICY        = "iam:DeletePolicy"
-	POLICY_ACTION_UPDATE_POLICY        = "iam:UpdatePolicy"
-	POLICY_ACTION_GET_POLICY           = "iam:GetPolicy"
-	POLICY_ACTION_LIST_ATTACHED_GROUPS = "iam:ListAttachedGroups"
-	POLICY_ACTION_LIST_POLICIES        = "iam:ListPolicies"
+	POLICY_ACTION_CREATE_POLICY         = "iam:CreatePolicy"
+	POLICY_ACTION_DELETE_POLICY         = "iam:DeletePolicy"
+	POLICY_ACTION_UPDATE_POLICY         = "iam:UpdatePolicy"
+	POLICY_ACTION_GET_POLICY            = "iam:GetPolicy"
+	POLICY_ACTION_LIST_ATTACHED_GROUPS  = "iam:ListAttachedGroups"
+	POLICY_ACTION_LIST_POLICIES         = "iam:ListPolicies"
+	POLICY_ACTION_LIST_DELETED_POLICIES = "iam:ListDeletedPolicies"
+	POLICY_ACTION_RESTORE_POLICY        = "iam:RestorePolicy"
+
+	// Organization actions
+	ORGANIZATION_ACTION_CREATE_ORGANIZATION = "iam:CreateOrganization"
+	ORGANIZATION_ACTION_DELETE_ORGANIZATION = "iam:DeleteOrganization"
+	ORGANIZATION_ACTION_GET_ORGANIZATION    = "iam:GetOrganization"
+	ORGANIZATION_ACTION_LIST_ORGANIZATIONS  = "iam:ListOrganizations"
+
+	// Access request actions
+	ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST = "iam:ApproveAccessRequest"
+
+	// Audit actions
+	AUDIT_ACTION_READ_AUDIT_LOG = "iam:ReadAuditLog"
+
+	// Change actions
+	CHANGE_ACTION_READ_CHANGES = "iam:ReadChanges"
+
+	// Webhook actions
+	WEBHOOK_ACTION_CREATE_WEBHOOK          = "iam:CreateWebhook"
+	WEBHOOK_ACTION_DELETE_WEBHOOK          = "iam:DeleteWebhook"
+	WEBHOOK_ACTION_GET_WEBHOOK             = "iam:GetWebhook"
+	WEBHOOK_ACTION_LIST_WEBHOOKS           = "iam:ListWebhooks"
+	WEBHOOK_ACTION_UPDATE_WEBHOOK          = "iam:UpdateWebhook"
+	WEBHOOK_ACTION_LIST_WEBHOOK_DELIVERIES = "iam:ListWebhookDeliveries"
+
+	// Group mapping actions
+	GROUP_MAPPING_ACTION_CREATE_GROUP_MAPPING = "iam:CreateGroupMapping"
+	GROUP_MAPPING_ACTION_DELETE_GROUP_MAPPING = "iam:DeleteGroupMapping"
+	GROUP_MAPPING_ACTION_GET_GROUP_MAPPING    = "iam:GetGroupMapping"
+	GROUP_MAPPING_ACTION_LIST_GROUP_MAPPINGS  = "iam:ListGroupMappings"
+
+	// Actions only recorded in audit log and webhook events, these operations are authorized with other actions
+	ACCESS_REQUEST_ACTION_CREATE_ACCESS_REQUEST = "iam:CreateAccessRequest"
+	ACCESS_REQUEST_ACTION_REJECT_ACCESS_REQUEST = "iam:RejectAccessRequest"
+	SYNC_ACTION_APPLY_SYNC                      = "iam:ApplySync"
 )
 
 var (
@@ -101,6 +181,25 @@ func IsValidPath(path string) bool {
 	return rPath.MatchString(path) && !rPathExclude.MatchString(path) && len(path) < MAX_PATH_LENGTH
 }
 
+// Display name and description are optional, so they are only limited in length
+func AreValidDescriptiveAttributes(displayName string, description string) error {
+	if len(displayName) > MAX_DISPLAY_NAME_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: displayName length %v, it can't be greater than %v",
+				len(displayName), MAX_DISPLAY_NAME_LENGTH),
+		}
+	}
+	if len(description) > MAX_DESCRIPTION_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description length %v, it can't be greater than %v",
+				len(description), MAX_DESCRIPTION_LENGTH),
+		}
+	}
+	return nil
+}
+
 func IsValidEffect(effect string) error {
 	if effect != "allow" && effect != "deny" {
 		return &Error{
@@ -225,6 +324,11 @@ func AreValidStatements(statements *[]Statement) error {
 	return nil
 }
 
+// Check if current version of a resource is the expected one. Version 0 means that any version is expected.
+func IsExpectedVersion(version int64, current int64) bool {
+	return version == 0 || version == current
+}
+
 func LogOperation(logger *logrus.Logger, requestInfo RequestInfo, message string) {
 	logger.WithFields(logrus.Fields{
 		"requestID": requestInfo.RequestID,
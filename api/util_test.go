@@ -273,6 +273,35 @@ func TestIsValidPath(t *testing.T) {
 	}
 }
 
+func TestIsExpectedVersion(t *testing.T) {
+	testcases := map[string]struct {
+		version int64
+		current int64
+		valid   bool
+	}{
+		"OkCaseAnyVersion": {
+			version: 0,
+			current: 3,
+			valid:   true,
+		},
+		"OkCaseSameVersion": {
+			version: 3,
+			current: 3,
+			valid:   true,
+		},
+		"OkCaseOldVersion": {
+			version: 2,
+			current: 3,
+			valid:   false,
+		},
+	}
+
+	for x, testcase := range testcases {
+		valid := IsExpectedVersion(testcase.version, testcase.current)
+		checkMethodResponse(t, x, nil, nil, testcase.valid, valid)
+	}
+}
+
 func TestIsValidEffect(t *testing.T) {
 	testcases := map[string]struct {
 		// Method args
@@ -0,0 +1,561 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Webhook delivery status
+	WEBHOOK_DELIVERY_STATUS_PENDING   = "pending"
+	WEBHOOK_DELIVERY_STATUS_DELIVERED = "delivered"
+	WEBHOOK_DELIVERY_STATUS_DEAD      = "dead"
+
+	// Headers of delivered events. Signature is the hex HMAC-SHA256 of the body with the webhook secret.
+	WEBHOOK_EVENT_HEADER     = "X-Foulkon-Event"
+	WEBHOOK_DELIVERY_HEADER  = "X-Foulkon-Delivery"
+	WEBHOOK_SIGNATURE_HEADER = "X-Foulkon-Signature"
+)
+
+// Event types that webhooks can subscribe to, they are the actions of the mutations
+var webhookEventTypes = []string{
+	USER_ACTION_CREATE_USER,
+	USER_ACTION_UPDATE_USER,
+	USER_ACTION_DELETE_USER,
+	USER_ACTION_RESTORE_USER,
+	USER_ACTION_RENAME_USER,
+	USER_ACTION_MERGE_USERS,
+	USER_ACTION_CREATE_API_KEY,
+	USER_ACTION_ROTATE_API_KEY,
+	USER_ACTION_REVOKE_API_KEY,
+	USER_ACTION_ADD_ADMIN,
+	USER_ACTION_REMOVE_ADMIN,
+	USER_ACTION_SET_USER_PASSWORD,
+	USER_ACTION_RESET_USER_PASSWORD,
+	USER_ACTION_SUSPEND_USER,
+	USER_ACTION_REACTIVATE_USER,
+	GROUP_ACTION_CREATE_GROUP,
+	GROUP_ACTION_UPDATE_GROUP,
+	GROUP_ACTION_DELETE_GROUP,
+	GROUP_ACTION_RESTORE_GROUP,
+	GROUP_ACTION_ADD_MEMBER,
+	GROUP_ACTION_REMOVE_MEMBER,
+	GROUP_ACTION_ATTACH_GROUP_POLICY,
+	GROUP_ACTION_DETACH_GROUP_POLICY,
+	POLICY_ACTION_CREATE_POLICY,
+	POLICY_ACTION_UPDATE_POLICY,
+	POLICY_ACTION_DELETE_POLICY,
+	POLICY_ACTION_RESTORE_POLICY,
+	ORGANIZATION_ACTION_CREATE_ORGANIZATION,
+	ORGANIZATION_ACTION_DELETE_ORGANIZATION,
+	ACCESS_REQUEST_ACTION_CREATE_ACCESS_REQUEST,
+	ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST,
+	ACCESS_REQUEST_ACTION_REJECT_ACCESS_REQUEST,
+	SYNC_ACTION_APPLY_SYNC,
+	GROUP_MAPPING_ACTION_CREATE_GROUP_MAPPING,
+	GROUP_MAPPING_ACTION_DELETE_GROUP_MAPPING,
+}
+
+// TYPE DEFINITIONS
+
+// Subscription of an URL to events. Secret signs the delivered events and it's never returned. Only events of
+// resources whose changes can be read by CreatedBy are delivered.
+type Webhook struct {
+	ID         string    `json:"id, omitempty"`
+	Url        string    `json:"url, omitempty"`
+	EventTypes []string  `json:"eventTypes, omitempty"`
+	Secret     string    `json:"-"`
+	Urn        string    `json:"urn, omitempty"`
+	CreatedBy  string    `json:"createdBy, omitempty"`
+	CreateAt   time.Time `json:"createAt, omitempty"`
+	UpdateAt   time.Time `json:"updateAt, omitempty"`
+}
+
+func (w Webhook) String() string {
+	return fmt.Sprintf("[id: %v, url: %v, eventTypes: %v, urn: %v, createdBy: %v, createAt: %v, updateAt: %v]",
+		w.ID, w.Url, w.EventTypes, w.Urn, w.CreatedBy, w.CreateAt.Format("2006-01-02 15:04:05 MST"), w.UpdateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (w Webhook) GetUrn() string {
+	return w.Urn
+}
+
+// Event of a successful mutation. Type is the action of the mutation, Urn the mutated resource and Before and
+// After are JSON snapshots of the resource before and after the mutation.
+type WebhookEvent struct {
+	ID        string          `json:"id, omitempty"`
+	Type      string          `json:"type, omitempty"`
+	RequestID string          `json:"requestId, omitempty"`
+	User      string          `json:"user, omitempty"`
+	Urn       string          `json:"urn, omitempty"`
+	Before    json.RawMessage `json:"before, omitempty"`
+	After     json.RawMessage `json:"after, omitempty"`
+	CreateAt  time.Time       `json:"createAt, omitempty"`
+}
+
+func (e WebhookEvent) String() string {
+	return fmt.Sprintf("[id: %v, type: %v, requestID: %v, user: %v, urn: %v, createAt: %v]",
+		e.ID, e.Type, e.RequestID, e.User, e.Urn, e.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (e WebhookEvent) GetUrn() string {
+	return e.Urn
+}
+
+// Delivery of an event to a webhook. Pending deliveries are attempted when NextAttemptAt is reached, and they
+// are dead when all attempts fail. LastStatusCode is 0 if there was no response.
+type WebhookDelivery struct {
+	ID             string       `json:"id, omitempty"`
+	WebhookID      string       `json:"webhookId, omitempty"`
+	Event          WebhookEvent `json:"event, omitempty"`
+	Status         string       `json:"status, omitempty"`
+	Attempts       int          `json:"attempts, omitempty"`
+	LastStatusCode int          `json:"lastStatusCode, omitempty"`
+	LastError      string       `json:"lastError, omitempty"`
+	NextAttemptAt  time.Time    `json:"nextAttemptAt, omitempty"`
+	CreateAt       time.Time    `json:"createAt, omitempty"`
+	UpdateAt       time.Time    `json:"updateAt, omitempty"`
+}
+
+func (d WebhookDelivery) String() string {
+	return fmt.Sprintf("[id: %v, webhookID: %v, eventID: %v, status: %v, attempts: %v, lastStatusCode: %v, lastError: %v]",
+		d.ID, d.WebhookID, d.Event.ID, d.Status, d.Attempts, d.LastStatusCode, d.LastError)
+}
+
+// WEBHOOK API IMPLEMENTATION
+
+func (api AuthAPI) AddWebhook(requestInfo RequestInfo, webhookUrl string, eventTypes []string, secret string) (*Webhook, error) {
+	// Validate fields
+	if err := validateWebhook(webhookUrl, eventTypes, secret); err != nil {
+		return nil, err
+	}
+
+	webhook := createWebhook(webhookUrl, eventTypes, secret, requestInfo.Identifier)
+
+	// Check restrictions
+	webhooksFiltered, err := api.getAuthorizedWebhooks(requestInfo, webhook.Urn, WEBHOOK_ACTION_CREATE_WEBHOOK, []Webhook{webhook})
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooksFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, webhook.Urn),
+		}
+	}
+
+	// Create webhook
+	createdWebhook, err := api.WebhookRepo.AddWebhook(webhook)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, WEBHOOK_ACTION_CREATE_WEBHOOK, createdWebhook.Urn, nil, createdWebhook); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook created %+v", createdWebhook))
+	return createdWebhook, nil
+}
+
+func (api AuthAPI) GetWebhookByID(requestInfo RequestInfo, id string) (*Webhook, error) {
+	return api.getWebhookForAction(requestInfo, id, WEBHOOK_ACTION_GET_WEBHOOK)
+}
+
+func (api AuthAPI) ListWebhooks(requestInfo RequestInfo) ([]Webhook, error) {
+	// Call repo to retrieve the webhooks
+	webhooks, err := api.WebhookRepo.GetWebhooks()
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	return api.getAuthorizedWebhooks(requestInfo, GetUrnPrefix("", RESOURCE_WEBHOOK, "/"), WEBHOOK_ACTION_LIST_WEBHOOKS, webhooks)
+}
+
+func (api AuthAPI) UpdateWebhook(requestInfo RequestInfo, id string, webhookUrl string, eventTypes []string, secret string) (*Webhook, error) {
+	// Empty secret keeps the current one
+	webhook, err := api.getWebhookForAction(requestInfo, id, WEBHOOK_ACTION_UPDATE_WEBHOOK)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) < 1 {
+		secret = webhook.Secret
+	}
+
+	// Validate fields
+	if err := validateWebhook(webhookUrl, eventTypes, secret); err != nil {
+		return nil, err
+	}
+
+	oldWebhook := *webhook
+	webhook.Url = webhookUrl
+	webhook.EventTypes = eventTypes
+	webhook.Secret = secret
+	webhook.UpdateAt = time.Now().UTC()
+
+	// Update webhook
+	updatedWebhook, err := api.WebhookRepo.UpdateWebhook(*webhook)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, WEBHOOK_ACTION_UPDATE_WEBHOOK, updatedWebhook.Urn, oldWebhook, updatedWebhook); err != nil {
+		return nil, err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook updated from %+v to %+v", oldWebhook, updatedWebhook))
+	return updatedWebhook, nil
+}
+
+func (api AuthAPI) RemoveWebhook(requestInfo RequestInfo, id string) error {
+	webhook, err := api.getWebhookForAction(requestInfo, id, WEBHOOK_ACTION_DELETE_WEBHOOK)
+	if err != nil {
+		return err
+	}
+
+	// Remove webhook with its deliveries
+	if err := api.WebhookRepo.RemoveWebhook(webhook.ID); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if err := api.recordChange(requestInfo, WEBHOOK_ACTION_DELETE_WEBHOOK, webhook.Urn, webhook, nil); err != nil {
+		return err
+	}
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook deleted %+v", webhook))
+	return nil
+}
+
+func (api AuthAPI) ListWebhookDeliveries(requestInfo RequestInfo, id string, status string) ([]WebhookDelivery, error) {
+	// Validate fields
+	if len(status) > 0 && !isValidWebhookDeliveryStatus(status) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: status %v", status),
+		}
+	}
+
+	webhook, err := api.getWebhookForAction(requestInfo, id, WEBHOOK_ACTION_LIST_WEBHOOK_DELIVERIES)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the deliveries
+	deliveries, err := api.WebhookRepo.GetWebhookDeliveries(webhook.ID, status)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return deliveries, nil
+}
+
+// Attempt the pending deliveries whose next attempt is due at now. Failed deliveries are retried with exponential
+// backoff and they are dead after maxAttempts. It isn't exposed in any API because it's executed periodically
+// by the worker, not by users.
+func (api AuthAPI) DeliverWebhooks(client *http.Client, maxAttempts int, backoff time.Duration, now time.Time) error {
+	deliveries, err := api.WebhookRepo.GetPendingWebhookDeliveries(now)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	webhooks := map[string]*Webhook{}
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = api.WebhookRepo.GetWebhookByID(delivery.WebhookID)
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		// Send event and schedule next attempt if it fails
+		statusCode, err := sendWebhookEvent(client, *webhook, delivery)
+		delivery.Attempts++
+		delivery.LastStatusCode = statusCode
+		delivery.UpdateAt = now
+		if err == nil {
+			delivery.Status = WEBHOOK_DELIVERY_STATUS_DELIVERED
+			delivery.LastError = ""
+		} else {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= maxAttempts {
+				delivery.Status = WEBHOOK_DELIVERY_STATUS_DEAD
+				api.Logger.Warnf("Webhook delivery %v is dead after %v attempts", delivery, delivery.Attempts)
+			} else {
+				delivery.NextAttemptAt = now.Add(backoff * time.Duration(1<<uint(delivery.Attempts-1)))
+			}
+		}
+
+		if _, err := api.WebhookRepo.UpdateWebhookDelivery(delivery); err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Create pending deliveries of an event for the webhooks subscribed to its type whose creator is allowed to
+// read the changes of the event resource, like in the change feed. Mutation is already done, so errors are
+// only logged.
+func (api AuthAPI) notifyWebhooks(event WebhookEvent) {
+	webhooks, err := api.WebhookRepo.GetWebhooks()
+	if err != nil {
+		api.Logger.Errorf("Unable to retrieve webhooks to notify event %v: %v", event, err)
+		return
+	}
+
+	deliveries := []WebhookDelivery{}
+	for _, webhook := range webhooks {
+		if !isEventTypeSubscribed(event.Type, webhook.EventTypes) {
+			continue
+		}
+		authorizedEvents, err := api.getAuthorizedResources(RequestInfo{Identifier: webhook.CreatedBy, RequestID: event.RequestID},
+			event.Urn, CHANGE_ACTION_READ_CHANGES, []Resource{event})
+		if err != nil {
+			if err.(*Error).Code != UNAUTHORIZED_RESOURCES_ERROR {
+				api.Logger.Errorf("Unable to authorize event %v for webhook %v: %v", event, webhook, err)
+			}
+			continue
+		}
+		if len(authorizedEvents) > 0 {
+			deliveries = append(deliveries, createWebhookDelivery(webhook, event))
+		}
+	}
+	if len(deliveries) < 1 {
+		return
+	}
+
+	if err := api.WebhookRepo.AddWebhookDeliveries(deliveries); err != nil {
+		api.Logger.Errorf("Unable to create webhook deliveries of event %v: %v", event, err)
+	}
+}
+
+// Retrieve webhook checking that user is allowed to do the given action over it
+func (api AuthAPI) getWebhookForAction(requestInfo RequestInfo, id string, action string) (*Webhook, error) {
+	// Call repo to retrieve the webhook
+	webhook, err := api.WebhookRepo.GetWebhookByID(id)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.WEBHOOK_NOT_FOUND:
+			return nil, &Error{
+				Code:    WEBHOOK_BY_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	webhooksFiltered, err := api.getAuthorizedWebhooks(requestInfo, webhook.Urn, action, []Webhook{*webhook})
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooksFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, webhook.Urn),
+		}
+	}
+
+	return webhook, nil
+}
+
+// Return authorized webhooks for specified resource+action
+func (api AuthAPI) getAuthorizedWebhooks(requestInfo RequestInfo, resourceUrn string, action string, webhooks []Webhook) ([]Webhook, error) {
+	resourcesToAuthorize := []Resource{}
+	for _, webhook := range webhooks {
+		resourcesToAuthorize = append(resourcesToAuthorize, webhook)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, resourceUrn, action, resourcesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	webhooksFiltered := []Webhook{}
+	for _, res := range resources {
+		webhooksFiltered = append(webhooksFiltered, res.(Webhook))
+	}
+	return webhooksFiltered, nil
+}
+
+// Validate fields of a webhook
+func validateWebhook(webhookUrl string, eventTypes []string, secret string) error {
+	if !isValidWebhookUrl(webhookUrl) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: url %v", webhookUrl),
+		}
+	}
+	if len(eventTypes) < 1 {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "Invalid parameter: eventTypes, they can't be empty",
+		}
+	}
+	for _, eventType := range eventTypes {
+		if !isEventTypeSubscribed(eventType, webhookEventTypes) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: eventType %v", eventType),
+			}
+		}
+	}
+	if len(secret) < 1 || len(secret) > MAX_WEBHOOK_SECRET_LENGTH {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: secret length %v, it must be between 1 and %v",
+				len(secret), MAX_WEBHOOK_SECRET_LENGTH),
+		}
+	}
+	return nil
+}
+
+func isValidWebhookUrl(webhookUrl string) bool {
+	if len(webhookUrl) > MAX_URL_LENGTH {
+		return false
+	}
+	u, err := url.Parse(webhookUrl)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
+
+func isValidWebhookDeliveryStatus(status string) bool {
+	return status == WEBHOOK_DELIVERY_STATUS_PENDING || status == WEBHOOK_DELIVERY_STATUS_DELIVERED ||
+		status == WEBHOOK_DELIVERY_STATUS_DEAD
+}
+
+// Returns true if an event type is contained in a slice of event types
+func isEventTypeSubscribed(eventType string, eventTypes []string) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func createWebhook(webhookUrl string, eventTypes []string, secret string, createdBy string) Webhook {
+	id := uuid.NewV4().String()
+	now := time.Now().UTC()
+	return Webhook{
+		ID:         id,
+		Url:        webhookUrl,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", id),
+		CreatedBy:  createdBy,
+		CreateAt:   now,
+		UpdateAt:   now,
+	}
+}
+
+func createWebhookDelivery(webhook Webhook, event WebhookEvent) WebhookDelivery {
+	return WebhookDelivery{
+		ID:            uuid.NewV4().String(),
+		WebhookID:     webhook.ID,
+		Event:         event,
+		Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+		NextAttemptAt: event.CreateAt,
+		CreateAt:      event.CreateAt,
+		UpdateAt:      event.CreateAt,
+	}
+}
+
+// Post event of the delivery signed with webhook secret. It returns the status code of the response, 0 if
+// there was no response, and an error if the status code isn't 2xx.
+func sendWebhookEvent(client *http.Client, webhook Webhook, delivery WebhookDelivery) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WEBHOOK_EVENT_HEADER, delivery.Event.Type)
+	req.Header.Set(WEBHOOK_DELIVERY_HEADER, delivery.ID)
+	req.Header.Set(WEBHOOK_SIGNATURE_HEADER, "sha256="+signWebhookBody(webhook.Secret, body))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("Unexpected response status %v", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Compute hex HMAC-SHA256 of a body with a secret
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
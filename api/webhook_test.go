@@ -0,0 +1,832 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		url         string
+		eventTypes  []string
+		secret      string
+		// Expected result
+		expectedResponse *Webhook
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		addWebhookResult          *Webhook
+		// Manager Errors
+		addWebhookMethodErr error
+		addChangeMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER, GROUP_ACTION_ADD_MEMBER},
+			secret:     "secret",
+			expectedResponse: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{USER_ACTION_CREATE_USER, GROUP_ACTION_ADD_MEMBER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			addWebhookResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{USER_ACTION_CREATE_USER, GROUP_ACTION_ADD_MEMBER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+		},
+		"ErrorCaseInvalidUrl": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "ftp://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER},
+			secret:     "secret",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: url ftp://example.com/hook",
+			},
+		},
+		"ErrorCaseEmptyEventTypes": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: eventTypes, they can't be empty",
+			},
+		},
+		"ErrorCaseInvalidEventType": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{WEBHOOK_ACTION_CREATE_WEBHOOK},
+			secret:     "secret",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: eventType iam:CreateWebhook",
+			},
+		},
+		"ErrorCaseEmptySecret": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: secret length 0, it must be between 1 and 256",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER},
+			secret:     "secret",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseAddWebhookDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER},
+			secret:     "secret",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			addWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseAddChangeDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:        "https://example.com/hook",
+			eventTypes: []string{USER_ACTION_CREATE_USER},
+			secret:     "secret",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			addWebhookResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{USER_ACTION_CREATE_USER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			addChangeMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[AddWebhookMethod][0] = testcase.addWebhookResult
+		testRepo.ArgsOut[AddWebhookMethod][1] = testcase.addWebhookMethodErr
+		testRepo.ArgsOut[AddChangeMethod][1] = testcase.addChangeMethodErr
+
+		webhook, err := testAPI.AddWebhook(testcase.requestInfo, testcase.url, testcase.eventTypes, testcase.secret)
+		if apiError, ok := err.(*Error); ok && testcase.wantError != nil &&
+			testcase.wantError.(*Error).Code == UNAUTHORIZED_RESOURCES_ERROR {
+			// Message contains random ID
+			if apiError.Code != UNAUTHORIZED_RESOURCES_ERROR {
+				t.Errorf("Test %v failed. Received different error codes (received/wanted) %v/%v", x,
+					apiError.Code, UNAUTHORIZED_RESOURCES_ERROR)
+			}
+			continue
+		}
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhook)
+
+		// Check stored webhook
+		if testcase.wantError == nil {
+			stored := testRepo.ArgsIn[AddWebhookMethod][0].(Webhook)
+			if stored.Secret != testcase.secret || stored.Url != testcase.url ||
+				stored.Urn != CreateUrn("", RESOURCE_WEBHOOK, "/", stored.ID) ||
+				stored.CreatedBy != testcase.requestInfo.Identifier {
+				t.Errorf("Test %v failed. Received unexpected stored webhook %v", x, stored)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_GetWebhookByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		// Expected result
+		expectedResponse *Webhook
+		wantError        error
+		// Manager Results
+		getWebhookByIDResult      *Webhook
+		getUserByExternalIDResult *User
+		// Manager Errors
+		getWebhookByIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			expectedResponse: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Url: "https://example.com/hook",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Url: "https://example.com/hook",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::webhook/WEBHOOK-ID",
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+		"ErrorCaseGetWebhookDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+
+		webhook, err := testAPI.GetWebhookByID(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhook)
+	}
+}
+
+func TestAuthAPI_ListWebhooks(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResponse []Webhook
+		wantError        error
+		// Manager Results
+		getWebhooksResult []Webhook
+		// Manager Errors
+		getWebhooksMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			expectedResponse: []Webhook{
+				{
+					ID:  "WEBHOOK-ID",
+					Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				},
+			},
+			getWebhooksResult: []Webhook{
+				{
+					ID:  "WEBHOOK-ID",
+					Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				},
+			},
+		},
+		"ErrorCaseGetWebhooksDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhooksMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhooksMethod][0] = testcase.getWebhooksResult
+		testRepo.ArgsOut[GetWebhooksMethod][1] = testcase.getWebhooksMethodErr
+
+		webhooks, err := testAPI.ListWebhooks(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhooks)
+	}
+}
+
+func TestAuthAPI_UpdateWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		url         string
+		eventTypes  []string
+		secret      string
+		// Expected result
+		expectedResponse *Webhook
+		expectedSecret   string
+		wantError        error
+		// Manager Results
+		getWebhookByIDResult *Webhook
+		updateWebhookResult  *Webhook
+		// Manager Errors
+		getWebhookByIDMethodErr error
+		updateWebhookMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:         "WEBHOOK-ID",
+			url:        "https://example.com/new",
+			eventTypes: []string{USER_ACTION_DELETE_USER},
+			secret:     "newsecret",
+			expectedResponse: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{USER_ACTION_DELETE_USER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			expectedSecret: "newsecret",
+			getWebhookByIDResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/old",
+				EventTypes: []string{USER_ACTION_CREATE_USER},
+				Secret:     "oldsecret",
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			updateWebhookResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{USER_ACTION_DELETE_USER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"OkCaseKeepSecret": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:         "WEBHOOK-ID",
+			url:        "https://example.com/new",
+			eventTypes: []string{USER_ACTION_DELETE_USER},
+			expectedResponse: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{USER_ACTION_DELETE_USER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			expectedSecret: "oldsecret",
+			getWebhookByIDResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/old",
+				EventTypes: []string{USER_ACTION_CREATE_USER},
+				Secret:     "oldsecret",
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			updateWebhookResult: &Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{USER_ACTION_DELETE_USER},
+				Urn:        CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"ErrorCaseInvalidUrl": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:         "WEBHOOK-ID",
+			url:        "invalid",
+			eventTypes: []string{USER_ACTION_DELETE_USER},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: url invalid",
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Secret: "oldsecret",
+				Urn:    CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:         "WEBHOOK-ID",
+			url:        "https://example.com/new",
+			eventTypes: []string{USER_ACTION_DELETE_USER},
+			wantError: &Error{
+				Code:    WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+		"ErrorCaseUpdateWebhookDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:         "WEBHOOK-ID",
+			url:        "https://example.com/new",
+			eventTypes: []string{USER_ACTION_DELETE_USER},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Secret: "oldsecret",
+				Urn:    CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			updateWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[UpdateWebhookMethod][0] = testcase.updateWebhookResult
+		testRepo.ArgsOut[UpdateWebhookMethod][1] = testcase.updateWebhookMethodErr
+
+		webhook, err := testAPI.UpdateWebhook(testcase.requestInfo, testcase.id, testcase.url, testcase.eventTypes,
+			testcase.secret)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhook)
+
+		// Check stored secret
+		if testcase.wantError == nil {
+			stored := testRepo.ArgsIn[UpdateWebhookMethod][0].(Webhook)
+			if stored.Secret != testcase.expectedSecret {
+				t.Errorf("Test %v failed. Received different secrets (received/wanted) %v/%v", x,
+					stored.Secret, testcase.expectedSecret)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RemoveWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		// Expected result
+		wantError error
+		// Manager Results
+		getWebhookByIDResult *Webhook
+		// Manager Errors
+		getWebhookByIDMethodErr error
+		removeWebhookMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+		"ErrorCaseRemoveWebhookDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			removeWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[RemoveWebhookMethod][0] = testcase.removeWebhookMethodErr
+
+		err := testAPI.RemoveWebhook(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_ListWebhookDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		status      string
+		// Expected result
+		expectedResponse []WebhookDelivery
+		wantError        error
+		// Manager Results
+		getWebhookByIDResult       *Webhook
+		getWebhookDeliveriesResult []WebhookDelivery
+		// Manager Errors
+		getWebhookDeliveriesMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:     "WEBHOOK-ID",
+			status: WEBHOOK_DELIVERY_STATUS_DEAD,
+			expectedResponse: []WebhookDelivery{
+				{
+					ID:        "DELIVERY-ID",
+					WebhookID: "WEBHOOK-ID",
+					Status:    WEBHOOK_DELIVERY_STATUS_DEAD,
+					Attempts:  5,
+					CreateAt:  now,
+				},
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			getWebhookDeliveriesResult: []WebhookDelivery{
+				{
+					ID:        "DELIVERY-ID",
+					WebhookID: "WEBHOOK-ID",
+					Status:    WEBHOOK_DELIVERY_STATUS_DEAD,
+					Attempts:  5,
+					CreateAt:  now,
+				},
+			},
+		},
+		"ErrorCaseInvalidStatus": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:     "WEBHOOK-ID",
+			status: "failed",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: status failed",
+			},
+		},
+		"ErrorCaseGetWebhookDeliveriesDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDResult: &Webhook{
+				ID:  "WEBHOOK-ID",
+				Urn: CreateUrn("", RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			getWebhookDeliveriesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDResult
+		testRepo.ArgsOut[GetWebhookDeliveriesMethod][0] = testcase.getWebhookDeliveriesResult
+		testRepo.ArgsOut[GetWebhookDeliveriesMethod][1] = testcase.getWebhookDeliveriesMethodErr
+
+		deliveries, err := testAPI.ListWebhookDeliveries(testcase.requestInfo, testcase.id, testcase.status)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, deliveries)
+	}
+}
+
+func TestAuthAPI_DeliverWebhooks(t *testing.T) {
+	now := time.Now().UTC()
+	event := WebhookEvent{
+		ID:       "EVENT-ID",
+		Type:     USER_ACTION_CREATE_USER,
+		Urn:      CreateUrn("", RESOURCE_USER, "/path/", "123"),
+		CreateAt: now,
+	}
+	body, _ := json.Marshal(event)
+	testcases := map[string]struct {
+		// API method args
+		maxAttempts int
+		// Webhook response
+		statusCode int
+		// Expected result
+		expectedDelivery *WebhookDelivery
+		wantError        error
+		// Manager Results
+		getPendingWebhookDeliveriesResult []WebhookDelivery
+		// Manager Errors
+		getPendingWebhookDeliveriesMethodErr error
+	}{
+		"OkCaseDelivered": {
+			maxAttempts: 3,
+			statusCode:  http.StatusOK,
+			expectedDelivery: &WebhookDelivery{
+				ID:             "DELIVERY-ID",
+				WebhookID:      "WEBHOOK-ID",
+				Event:          event,
+				Status:         WEBHOOK_DELIVERY_STATUS_DELIVERED,
+				Attempts:       1,
+				LastStatusCode: http.StatusOK,
+				NextAttemptAt:  now,
+				UpdateAt:       now,
+			},
+			getPendingWebhookDeliveriesResult: []WebhookDelivery{
+				{
+					ID:            "DELIVERY-ID",
+					WebhookID:     "WEBHOOK-ID",
+					Event:         event,
+					Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+					NextAttemptAt: now,
+				},
+			},
+		},
+		"OkCaseRetry": {
+			maxAttempts: 3,
+			statusCode:  http.StatusInternalServerError,
+			expectedDelivery: &WebhookDelivery{
+				ID:             "DELIVERY-ID",
+				WebhookID:      "WEBHOOK-ID",
+				Event:          event,
+				Status:         WEBHOOK_DELIVERY_STATUS_PENDING,
+				Attempts:       2,
+				LastStatusCode: http.StatusInternalServerError,
+				LastError:      "Unexpected response status 500",
+				NextAttemptAt:  now.Add(2 * time.Minute),
+				UpdateAt:       now,
+			},
+			getPendingWebhookDeliveriesResult: []WebhookDelivery{
+				{
+					ID:            "DELIVERY-ID",
+					WebhookID:     "WEBHOOK-ID",
+					Event:         event,
+					Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+					Attempts:      1,
+					NextAttemptAt: now,
+				},
+			},
+		},
+		"OkCaseDead": {
+			maxAttempts: 3,
+			statusCode:  http.StatusNotFound,
+			expectedDelivery: &WebhookDelivery{
+				ID:             "DELIVERY-ID",
+				WebhookID:      "WEBHOOK-ID",
+				Event:          event,
+				Status:         WEBHOOK_DELIVERY_STATUS_DEAD,
+				Attempts:       3,
+				LastStatusCode: http.StatusNotFound,
+				LastError:      "Unexpected response status 404",
+				NextAttemptAt:  now,
+				UpdateAt:       now,
+			},
+			getPendingWebhookDeliveriesResult: []WebhookDelivery{
+				{
+					ID:            "DELIVERY-ID",
+					WebhookID:     "WEBHOOK-ID",
+					Event:         event,
+					Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+					Attempts:      2,
+					NextAttemptAt: now,
+				},
+			},
+		},
+		"ErrorCaseGetPendingWebhookDeliveriesDBErr": {
+			maxAttempts: 3,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPendingWebhookDeliveriesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, _ := ioutil.ReadAll(r.Body)
+			if r.Header.Get(WEBHOOK_SIGNATURE_HEADER) != "sha256="+signWebhookBody("secret", received) {
+				t.Errorf("Test %v failed. Received invalid signature %v", x, r.Header.Get(WEBHOOK_SIGNATURE_HEADER))
+			}
+			if string(received) != string(body) {
+				t.Errorf("Test %v failed. Received different bodies (received/wanted) %v/%v", x,
+					string(received), string(body))
+			}
+			if r.Header.Get(WEBHOOK_EVENT_HEADER) != USER_ACTION_CREATE_USER ||
+				r.Header.Get(WEBHOOK_DELIVERY_HEADER) != "DELIVERY-ID" {
+				t.Errorf("Test %v failed. Received unexpected headers %v", x, r.Header)
+			}
+			w.WriteHeader(testcase.statusCode)
+		}))
+
+		testRepo.ArgsOut[GetPendingWebhookDeliveriesMethod][0] = testcase.getPendingWebhookDeliveriesResult
+		testRepo.ArgsOut[GetPendingWebhookDeliveriesMethod][1] = testcase.getPendingWebhookDeliveriesMethodErr
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = &Webhook{
+			ID:     "WEBHOOK-ID",
+			Url:    server.URL,
+			Secret: "secret",
+		}
+
+		err := testAPI.DeliverWebhooks(http.DefaultClient, testcase.maxAttempts, time.Minute, now)
+		server.Close()
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+
+		// Check updated delivery
+		if testcase.wantError == nil {
+			updated := testRepo.ArgsIn[UpdateWebhookDeliveryMethod][0].(WebhookDelivery)
+			if diff := pretty.Compare(updated, testcase.expectedDelivery); diff != "" {
+				t.Errorf("Test %v failed. Received different deliveries (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Interface that retrieves the owner of an API key, implemented by api.AuthAPI
+type ApiKeyAuthenticator interface {
+	AuthenticateApiKey(key string) (*api.User, error)
+}
+
+// This struct represents an API key connector that implements interface of auth connector. Requests with
+// an API key in a bearer Authorization header are authenticated as the service account that owns the key.
+type ApiKeyAuthConnector struct {
+	authenticator ApiKeyAuthenticator
+	logger        *log.Logger
+}
+
+func InitApiKeyConnector(logger *log.Logger, authenticator ApiKeyAuthenticator) (AuthConnector, error) {
+	return &ApiKeyAuthConnector{
+		authenticator: authenticator,
+		logger:        logger,
+	}, nil
+}
+
+// Only requests with an API key are authenticated by this connector
+func (c ApiKeyAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	_, ok := getApiKey(r)
+	return ok
+}
+
+// This method retrieves API key from request and checks that it belongs to a service account
+func (c ApiKeyAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := getApiKey(r)
+		if !ok {
+			http.Error(w, "Error API key not found", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := c.authenticator.AuthenticateApiKey(key)
+		if err != nil {
+			c.logger.WithFields(log.Fields{
+				"requestID": r.Header.Get("Request-ID"),
+			}).Error(err)
+			if apiError, ok := err.(*api.Error); ok && apiError.Code == api.INVALID_API_KEY {
+				http.Error(w, fmt.Sprintf("Error %v", apiError.Message), http.StatusUnauthorized)
+			} else {
+				http.Error(w, "Unexpected error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Header is replaced, so it can't be forged by clients
+		r.Header.Set(USER_ID_HEADER, user.ExternalID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Retrieve user from API key
+func (c ApiKeyAuthConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Retrieve API key from bearer Authorization header
+func getApiKey(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer "+api.API_KEY_PREFIX) {
+		return "", false
+	}
+	return strings.TrimPrefix(authorization, "Bearer "), true
+}
+
+// Retrieve bearer token from Authorization header that isn't an API key or a session token
+func getBearerToken(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") || strings.HasPrefix(authorization, "Bearer "+api.API_KEY_PREFIX) ||
+		strings.HasPrefix(authorization, "Bearer "+api.SESSION_TOKEN_PREFIX) {
+		return "", false
+	}
+	return strings.TrimPrefix(authorization, "Bearer "), true
+}
+
+// Returns true if request has a bearer token in JWT format
+func hasBearerJWT(r *http.Request) bool {
+	token, ok := getBearerToken(r)
+	return ok && len(strings.Split(token, ".")) == 3
+}
@@ -1,22 +1,49 @@
 package auth
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+const (
+	// Header with the name of the connector that authenticated the request
+	CONNECTOR_HEADER = "AUTH-CONNECTOR"
+
+	// Name of admin authentication with Basic Authentication scheme
+	ADMIN_CONNECTOR = "admin"
+)
+
+var (
+	invalidExternalIDChars = regexp.MustCompile(`[^\w+.@=\-]`)
 )
 
-// Authenticator system, with connector and basic admin authentication
+// Interface that retrieves the user of admin credentials, implemented by api.AuthAPI
+type AdminAuthenticator interface {
+	AuthenticateAdmin(username string, password string) (*api.User, error)
+}
+
+// Connector of the authentication chain, with the name that reports it authenticated a request
+type ChainedConnector struct {
+	Name      string
+	Connector AuthConnector
+}
+
+// Authenticator system, with a chain of connectors and basic authentication of admins stored in database
 type Authenticator struct {
-	Connector     AuthConnector
-	adminUser     string
-	adminPassword string
+	Connectors []ChainedConnector
+	admins     AdminAuthenticator
 }
 
-// Returns a configured Authenticator with associated connector
-func NewAuthenticator(connector AuthConnector, adminUser string, adminPassword string) *Authenticator {
+// Returns a configured Authenticator with associated connectors, that are tried in order
+func NewAuthenticator(connectors []ChainedConnector, admins AdminAuthenticator) *Authenticator {
 	return &Authenticator{
-		Connector:     connector,
-		adminUser:     adminUser,
-		adminPassword: adminPassword,
+		Connectors: connectors,
+		admins:     admins,
 	}
 }
 
@@ -26,33 +53,130 @@ type AuthConnector interface {
 	RetrieveUserID(r http.Request) string
 }
 
+// Interface of connectors that know if a request has their kind of credentials. Connectors that
+// don't implement it are tried with every request.
+type CredentialsRecognizer interface {
+	RecognizesCredentials(r *http.Request) bool
+}
+
 func (a *Authenticator) Authenticate(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var handler http.Handler
-		if isAdmin(r, a.adminUser, a.adminPassword) {
-			// Admin check
-			handler = h
-
-		} else {
-			// Connector
-			handler = a.Connector.Authenticate(h)
+		// Headers are replaced, so they can't be forged by clients
+		r.Header.Del(USER_ID_HEADER)
+		r.Header.Del(CONNECTOR_HEADER)
+
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			a.authenticateWithConnectors(h, w, r)
+			return
+		}
+
+		// Admin check
+		user, err := a.admins.AuthenticateAdmin(username, password)
+		if err != nil {
+			apiError, ok := err.(*api.Error)
+			switch {
+			case ok && apiError.Code == api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND:
+				// Credentials of users without admin credentials can be of connectors that recognize them, like
+				// local passwords. Admin passwords aren't checked, so a request never checks two password hashes.
+				if a.isRecognized(r) {
+					a.authenticateWithConnectors(h, w, r)
+					return
+				}
+				http.Error(w, fmt.Sprintf("Error %v", apiError.Message), http.StatusUnauthorized)
+			case ok && (apiError.Code == api.INVALID_ADMIN_CREDENTIALS || apiError.Code == api.USER_LOCKED_OUT):
+				http.Error(w, fmt.Sprintf("Error %v", apiError.Message), http.StatusUnauthorized)
+			default:
+				http.Error(w, "Unexpected error", http.StatusInternalServerError)
+			}
+			return
 		}
 
-		handler.ServeHTTP(w, r)
+		r.Header.Set(USER_ID_HEADER, user.ExternalID)
+		r.Header.Set(CONNECTOR_HEADER, ADMIN_CONNECTOR)
+		h.ServeHTTP(w, r)
 	})
 }
 
-// Retrieve user from request.
-func (a *Authenticator) GetAuthenticatedUser(r *http.Request) (string, bool) {
-	if isAdmin(r, a.adminUser, a.adminPassword) {
-		return a.adminUser, true
-	} else {
-		return a.Connector.RetrieveUserID(*r), false
+// Retrieve user from request and the name of the connector that authenticated it. Admins are authorized by
+// their policies like other users.
+func (a *Authenticator) GetAuthenticatedUser(r *http.Request) (string, string) {
+	return r.Header.Get(USER_ID_HEADER), r.Header.Get(CONNECTOR_HEADER)
+}
+
+// Try connectors that recognize credentials of request until one authenticates it. Responses of connectors that
+// reject the request are discarded, and the response of the last one is returned if none authenticates it.
+func (a *Authenticator) authenticateWithConnectors(h http.Handler, w http.ResponseWriter, r *http.Request) {
+	var rejected *connectorResponseWriter
+	for _, chained := range a.Connectors {
+		if recognizer, ok := chained.Connector.(CredentialsRecognizer); ok && !recognizer.RecognizesCredentials(r) {
+			continue
+		}
+
+		authenticated := false
+		cw := &connectorResponseWriter{header: http.Header{}, status: http.StatusOK}
+		chained.Connector.Authenticate(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			authenticated = true
+			// User is retrieved once, so handlers can get it as many times as they need
+			r.Header.Set(USER_ID_HEADER, chained.Connector.RetrieveUserID(*r))
+			r.Header.Set(CONNECTOR_HEADER, chained.Name)
+			h.ServeHTTP(w, r)
+		})).ServeHTTP(cw, r)
+		if authenticated {
+			return
+		}
+		rejected = cw
+	}
+
+	if rejected == nil {
+		http.Error(w, "Error credentials not found", http.StatusUnauthorized)
+		return
+	}
+	for key, values := range rejected.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rejected.status)
+	w.Write(rejected.body.Bytes())
+}
+
+// Returns the default prefix of external IDs of users authenticated by a type of connector, with the host of
+// issuer when it's an URL or the issuer itself, e.g. jwt.idp.example.com. for https://idp.example.com. Prefixes
+// keep users of different connectors and issuers apart from each other and from users stored in database.
+func DefaultExternalIDPrefix(connectorType string, issuer string) string {
+	if u, err := url.Parse(issuer); err == nil && u.Host != "" {
+		issuer = u.Host
+	}
+	if issuer == "" {
+		return connectorType + "."
+	}
+	return connectorType + "." + invalidExternalIDChars.ReplaceAllString(issuer, "_") + "."
+}
+
+// Returns true if a connector that knows its kind of credentials recognizes the request
+func (a *Authenticator) isRecognized(r *http.Request) bool {
+	for _, chained := range a.Connectors {
+		if recognizer, ok := chained.Connector.(CredentialsRecognizer); ok && recognizer.RecognizesCredentials(r) {
+			return true
+		}
 	}
+	return false
+}
+
+// Response writer that keeps the response of a connector that could reject the request
+type connectorResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (cw *connectorResponseWriter) Header() http.Header {
+	return cw.header
+}
+
+func (cw *connectorResponseWriter) WriteHeader(status int) {
+	cw.status = status
 }
 
-func isAdmin(r *http.Request, adminUser string, adminPassword string) bool {
-	username, password, ok := r.BasicAuth()
-	// Password is never stored in DB
-	return ok && username == adminUser && password == adminPassword
+func (cw *connectorResponseWriter) Write(b []byte) (int, error) {
+	return cw.body.Write(b)
 }
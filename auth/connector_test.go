@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Aux connector that authenticates requests with its token, or with its password if it has one
+type testConnector struct {
+	token      string
+	password   string
+	userID     string
+	recognizes bool
+}
+
+func (tc testConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, basicAuth := r.BasicAuth()
+		validPassword := basicAuth && tc.password != "" && password == tc.password
+		if r.Header.Get("Authorization") != "Bearer "+tc.token && !validPassword {
+			http.Error(w, "Error invalid token for "+tc.userID, http.StatusUnauthorized)
+			return
+		}
+		r.Header.Set(USER_ID_HEADER, tc.userID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (tc testConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Aux connector that only authenticates recognized requests
+type testRecognizerConnector struct {
+	testConnector
+}
+
+func (tc testRecognizerConnector) RecognizesCredentials(r *http.Request) bool {
+	return tc.recognizes
+}
+
+// Aux admin authenticator, where admin is the only user with admin credentials
+type testAdminAuthenticator struct{}
+
+func (ta testAdminAuthenticator) AuthenticateAdmin(username string, password string) (*api.User, error) {
+	switch {
+	case username == "locked":
+		return nil, &api.Error{
+			Code:    api.USER_LOCKED_OUT,
+			Message: "User locked is locked out",
+		}
+	case username == "error":
+		return nil, &api.Error{
+			Code:    api.UNKNOWN_API_ERROR,
+			Message: "Error",
+		}
+	case username != "admin":
+		return nil, &api.Error{
+			Code:    api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+			Message: "Invalid admin credentials",
+		}
+	case password != "password":
+		return nil, &api.Error{
+			Code:    api.INVALID_ADMIN_CREDENTIALS,
+			Message: "Invalid admin credentials",
+		}
+	}
+	return &api.User{ExternalID: username}, nil
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	testcases := map[string]struct {
+		// Authenticator args
+		connectors []ChainedConnector
+		// Request args
+		token         string
+		basicAuth     bool
+		username      string
+		password      string
+		forgedHeaders bool
+		// Expected result
+		expectedStatusCode int
+		expectedUserID     string
+		expectedConnector  string
+		expectedBody       string
+	}{
+		"OkCaseFirstConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+				{Name: "second", Connector: testConnector{token: "token2", userID: "user2"}},
+			},
+			token:              "token1",
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user1",
+			expectedConnector:  "first",
+		},
+		"OkCaseNextConnectorAfterRejection": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+				{Name: "second", Connector: testConnector{token: "token2", userID: "user2"}},
+			},
+			token:              "token2",
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user2",
+			expectedConnector:  "second",
+		},
+		"OkCaseUnrecognizedConnectorSkipped": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{token: "token1", userID: "user1", recognizes: false}}},
+				{Name: "second", Connector: testConnector{token: "token1", userID: "user2"}},
+			},
+			token:              "token1",
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user2",
+			expectedConnector:  "second",
+		},
+		"OkCaseAdmin": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+			},
+			basicAuth:          true,
+			password:           "password",
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "admin",
+			expectedConnector:  ADMIN_CONNECTOR,
+		},
+		"OkCaseBasicAuthOfRecognizedConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{password: "userpassword", userID: "user1", recognizes: true}}},
+			},
+			basicAuth:          true,
+			username:           "user1",
+			password:           "userpassword",
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user1",
+			expectedConnector:  "first",
+		},
+		"ErrorCaseForgedHeaders": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+			},
+			token:              "invalid",
+			forgedHeaders:      true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error invalid token for user1\n",
+		},
+		"ErrorCaseLastRejectionReturned": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+				{Name: "second", Connector: testConnector{token: "token2", userID: "user2"}},
+			},
+			token:              "invalid",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error invalid token for user2\n",
+		},
+		"ErrorCaseWithoutRecognizedConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{token: "token1", userID: "user1", recognizes: false}}},
+			},
+			token:              "token1",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error credentials not found\n",
+		},
+		"ErrorCaseInvalidBasicAuthOfRecognizedConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{password: "userpassword", userID: "user1", recognizes: true}}},
+			},
+			basicAuth:          true,
+			username:           "user1",
+			password:           "invalid",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error invalid token for user1\n",
+		},
+		"ErrorCaseInvalidAdminCredentials": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+			},
+			basicAuth:          true,
+			password:           "invalid",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid admin credentials\n",
+		},
+		"ErrorCaseInvalidAdminPasswordNotCheckedByConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{password: "userpassword", userID: "user1", recognizes: true}}},
+			},
+			basicAuth:          true,
+			password:           "userpassword",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid admin credentials\n",
+		},
+		"ErrorCaseAdminLockedOut": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{password: "password", userID: "user1", recognizes: true}}},
+			},
+			basicAuth:          true,
+			username:           "locked",
+			password:           "password",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error User locked is locked out\n",
+		},
+		"ErrorCaseNotAdminWithoutRecognizedConnector": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testConnector{token: "token1", userID: "user1"}},
+			},
+			basicAuth:          true,
+			username:           "user1",
+			password:           "password",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid admin credentials\n",
+		},
+		"ErrorCaseAdminUnexpectedError": {
+			connectors: []ChainedConnector{
+				{Name: "first", Connector: testRecognizerConnector{testConnector{password: "password", userID: "user1", recognizes: true}}},
+			},
+			basicAuth:          true,
+			username:           "error",
+			password:           "password",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "Unexpected error\n",
+		},
+	}
+
+	for n, test := range testcases {
+		authenticator := NewAuthenticator(test.connectors, testAdminAuthenticator{})
+
+		var userID, connector string
+		handler := authenticator.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, connector = authenticator.GetAuthenticatedUser(r)
+			// User can be retrieved several times
+			if secondUserID, _ := authenticator.GetAuthenticatedUser(r); secondUserID != userID {
+				t.Errorf("Test %v failed. Received different user in second retrieval: %v", n, secondUserID)
+			}
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.basicAuth {
+			username := test.username
+			if username == "" {
+				username = "admin"
+			}
+			req.SetBasicAuth(username, test.password)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		if test.forgedHeaders {
+			req.Header.Set(USER_ID_HEADER, "forged")
+			req.Header.Set(CONNECTOR_HEADER, "forged")
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		// Check result
+		if res.Code != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			continue
+		}
+		if userID != test.expectedUserID || connector != test.expectedConnector {
+			t.Errorf("Test %v failed. Received different user and connector (wanted:%v %v / received:%v %v)", n,
+				test.expectedUserID, test.expectedConnector, userID, connector)
+			continue
+		}
+		if test.expectedBody != "" && res.Body.String() != test.expectedBody {
+			t.Errorf("Test %v failed. Received different body (wanted:%v / received:%v)", n, test.expectedBody, res.Body.String())
+			continue
+		}
+		if test.forgedHeaders {
+			if forgedUserID, forgedConnector := authenticator.GetAuthenticatedUser(req); forgedUserID != "" || forgedConnector != "" {
+				t.Errorf("Test %v failed. Forged headers weren't removed: %v %v", n, forgedUserID, forgedConnector)
+				continue
+			}
+		}
+	}
+}
+
+func TestDefaultExternalIDPrefix(t *testing.T) {
+	testcases := map[string]struct {
+		// Method args
+		connectorType string
+		issuer        string
+		// Expected result
+		expectedPrefix string
+	}{
+		"OkCaseIssuerURL": {
+			connectorType:  "jwt",
+			issuer:         "https://idp.example.com",
+			expectedPrefix: "jwt.idp.example.com.",
+		},
+		"OkCaseEndpointWithPort": {
+			connectorType:  "introspection",
+			issuer:         "https://idp.example.com:8443/introspect",
+			expectedPrefix: "introspection.idp.example.com_8443.",
+		},
+		"OkCaseIssuerNotURL": {
+			connectorType:  "jwt",
+			issuer:         "example idp",
+			expectedPrefix: "jwt.example_idp.",
+		},
+		"OkCaseWithoutIssuer": {
+			connectorType:  "mtls",
+			expectedPrefix: "mtls.",
+		},
+	}
+
+	for n, test := range testcases {
+		prefix := DefaultExternalIDPrefix(test.connectorType, test.issuer)
+		if prefix != test.expectedPrefix {
+			t.Errorf("Test %v failed. Received different prefix (wanted:%v / received:%v)", n, test.expectedPrefix, prefix)
+			continue
+		}
+		if !api.IsValidUserExternalID(prefix) {
+			t.Errorf("Test %v failed. Received invalid prefix %v", n, prefix)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Fields of introspection responses that can be used as external ID
+const (
+	INTROSPECTION_USER_FIELD_SUB      = "sub"
+	INTROSPECTION_USER_FIELD_USERNAME = "username"
+)
+
+// Response of introspection endpoint, see RFC 7662
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Sub      string `json:"sub"`
+	Username string `json:"username"`
+	Exp      int64  `json:"exp"`
+}
+
+// User of an active token cached until its expiration
+type introspectionCacheEntry struct {
+	userID string
+	exp    time.Time
+}
+
+// This struct represents an OAuth2 token introspection connector that implements interface of auth connector.
+// Tokens are checked with the introspection endpoint, and active tokens are cached until they expire.
+type IntrospectionAuthConnector struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	userField    string
+	prefix       string
+	client       *http.Client
+	logger       *log.Logger
+
+	// Cache of active tokens by digest, so they aren't kept in memory
+	mutex sync.Mutex
+	cache map[string]introspectionCacheEntry
+}
+
+// Returns a connector that introspects tokens in endpoint authenticated with client credentials. External ID
+// of users is the prefix followed by the user field of responses, sub or username.
+func InitIntrospectionConnector(logger *log.Logger, client *http.Client, endpoint string, clientID string, clientSecret string,
+	userField string, prefix string) (AuthConnector, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid introspection endpoint %v", endpoint))
+	}
+	if clientID == "" {
+		return nil, errors.New("Introspection connector needs client ID")
+	}
+	if userField != INTROSPECTION_USER_FIELD_SUB && userField != INTROSPECTION_USER_FIELD_USERNAME {
+		return nil, errors.New(fmt.Sprintf("Invalid introspection user field %v", userField))
+	}
+	if !api.IsValidUserExternalID(prefix) {
+		return nil, errors.New(fmt.Sprintf("Invalid introspection connector prefix %v", prefix))
+	}
+	return &IntrospectionAuthConnector{
+		endpoint:     endpoint,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		userField:    userField,
+		prefix:       prefix,
+		client:       client,
+		logger:       logger,
+		cache:        map[string]introspectionCacheEntry{},
+	}, nil
+}
+
+// This method retrieves token from request and checks that it's active
+func (c *IntrospectionAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := getBearerToken(r)
+		if !ok {
+			http.Error(w, "Error token not found", http.StatusUnauthorized)
+			return
+		}
+
+		userID, ok := c.getCachedUser(token)
+		if !ok {
+			response, err := c.introspect(token)
+			if err != nil {
+				c.logger.WithFields(log.Fields{
+					"requestID": r.Header.Get("Request-ID"),
+				}).Errorf("Error introspecting token: %v", err)
+				http.Error(w, "Unexpected error", http.StatusInternalServerError)
+				return
+			}
+			if !response.Active || (response.Exp != 0 && time.Unix(response.Exp, 0).Before(time.Now())) {
+				http.Error(w, "Error token isn't active", http.StatusUnauthorized)
+				return
+			}
+			userID = response.Sub
+			if c.userField == INTROSPECTION_USER_FIELD_USERNAME {
+				userID = response.Username
+			}
+			if userID == "" {
+				http.Error(w, fmt.Sprintf("Error token without %v", c.userField), http.StatusUnauthorized)
+				return
+			}
+			// Tokens without expiration are introspected on every request
+			if response.Exp != 0 {
+				c.cacheUser(token, userID, time.Unix(response.Exp, 0))
+			}
+		}
+
+		// Header is replaced, so it can't be forged by clients
+		r.Header.Set(USER_ID_HEADER, c.prefix+userID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Only requests with a bearer token are authenticated by this connector
+func (c *IntrospectionAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	_, ok := getBearerToken(r)
+	return ok
+}
+
+// Retrieve user from introspected token
+func (c *IntrospectionAuthConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Call introspection endpoint with token
+func (c *IntrospectionAuthConnector) introspect(token string) (*introspectionResponse, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("Introspection endpoint returned status %v", res.StatusCode))
+	}
+
+	response := &introspectionResponse{}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Retrieve user of token if it's cached and it isn't expired
+func (c *IntrospectionAuthConnector) getCachedUser(token string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.cache[tokenDigest(token)]
+	if !ok || !entry.exp.After(time.Now()) {
+		return "", false
+	}
+	return entry.userID, true
+}
+
+// Store user of token until expiration, removing expired entries
+func (c *IntrospectionAuthConnector) cacheUser(token string, userID string, exp time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	for key, entry := range c.cache {
+		if !entry.exp.After(now) {
+			delete(c.cache, key)
+		}
+	}
+	c.cache[tokenDigest(token)] = introspectionCacheEntry{
+		userID: userID,
+		exp:    exp,
+	}
+}
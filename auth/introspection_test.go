@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func TestIntrospectionAuthConnector_Authenticate(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	testcases := map[string]struct {
+		// Connector args
+		userField string
+		// Request args
+		token    string
+		requests int
+		// Introspection endpoint response
+		endpointStatusCode int
+		endpointResponse   map[string]interface{}
+		// Expected result
+		expectedStatusCode int
+		expectedUserID     string
+		expectedCalls      int
+	}{
+		"OkCaseSub": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           1,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active":   true,
+				"sub":      "user1",
+				"username": "username1",
+				"exp":      exp,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "introspection.user1",
+			expectedCalls:      1,
+		},
+		"OkCaseUsername": {
+			userField:          INTROSPECTION_USER_FIELD_USERNAME,
+			token:              "token",
+			requests:           1,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active":   true,
+				"sub":      "user1",
+				"username": "username1",
+				"exp":      exp,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "introspection.username1",
+			expectedCalls:      1,
+		},
+		"OkCaseCachedToken": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           3,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active": true,
+				"sub":    "user1",
+				"exp":    exp,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "introspection.user1",
+			expectedCalls:      1,
+		},
+		"OkCaseTokenWithoutExpNotCached": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           2,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active": true,
+				"sub":    "user1",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "introspection.user1",
+			expectedCalls:      2,
+		},
+		"ErrorCaseTokenNotFound": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			requests:           1,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"ErrorCaseInactiveToken": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           2,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active": false,
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCalls:      2,
+		},
+		"ErrorCaseExpiredToken": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           1,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active": true,
+				"sub":    "user1",
+				"exp":    time.Now().Add(-time.Hour).Unix(),
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCalls:      1,
+		},
+		"ErrorCaseTokenWithoutUser": {
+			userField:          INTROSPECTION_USER_FIELD_USERNAME,
+			token:              "token",
+			requests:           1,
+			endpointStatusCode: http.StatusOK,
+			endpointResponse: map[string]interface{}{
+				"active": true,
+				"sub":    "user1",
+				"exp":    exp,
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCalls:      1,
+		},
+		"ErrorCaseEndpointError": {
+			userField:          INTROSPECTION_USER_FIELD_SUB,
+			token:              "token",
+			requests:           1,
+			endpointStatusCode: http.StatusUnauthorized,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCalls:      1,
+		},
+	}
+
+	for n, test := range testcases {
+		calls := 0
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			clientID, clientSecret, ok := r.BasicAuth()
+			if !ok || clientID != "client" || clientSecret != "secret" {
+				t.Errorf("Test %v failed. Received different client credentials: %v %v", n, clientID, clientSecret)
+			}
+			if r.PostFormValue("token") != test.token {
+				t.Errorf("Test %v failed. Received different token: %v", n, r.PostFormValue("token"))
+			}
+			w.WriteHeader(test.endpointStatusCode)
+			if test.endpointResponse != nil {
+				json.NewEncoder(w).Encode(test.endpointResponse)
+			}
+		}))
+
+		connector, err := InitIntrospectionConnector(log.New(), http.DefaultClient, endpoint.URL, "client", "secret", test.userField,
+			"introspection.")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			endpoint.Close()
+			continue
+		}
+
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		for i := 0; i < test.requests; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			// Check result
+			if res.Code != test.expectedStatusCode {
+				t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			}
+			if userID != test.expectedUserID {
+				t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			}
+		}
+		endpoint.Close()
+
+		if calls != test.expectedCalls {
+			t.Errorf("Test %v failed. Received different introspection calls (wanted:%v / received:%v)", n, test.expectedCalls, calls)
+		}
+	}
+}
+
+func TestInitIntrospectionConnector(t *testing.T) {
+	testcases := map[string]struct {
+		// Connector args
+		endpoint  string
+		clientID  string
+		userField string
+		prefix    string
+		// Expected result
+		expectedError string
+	}{
+		"OkCase": {
+			endpoint:  "https://idp.example.com/introspect",
+			clientID:  "client",
+			userField: INTROSPECTION_USER_FIELD_SUB,
+			prefix:    "introspection.idp.example.com.",
+		},
+		"ErrorCaseInvalidEndpoint": {
+			endpoint:      "introspect",
+			clientID:      "client",
+			userField:     INTROSPECTION_USER_FIELD_SUB,
+			expectedError: "Invalid introspection endpoint introspect",
+		},
+		"ErrorCaseWithoutClientID": {
+			endpoint:      "https://idp.example.com/introspect",
+			userField:     INTROSPECTION_USER_FIELD_SUB,
+			expectedError: "Introspection connector needs client ID",
+		},
+		"ErrorCaseInvalidUserField": {
+			endpoint:      "https://idp.example.com/introspect",
+			clientID:      "client",
+			userField:     "email",
+			expectedError: "Invalid introspection user field email",
+		},
+		"ErrorCaseWithoutPrefix": {
+			endpoint:      "https://idp.example.com/introspect",
+			clientID:      "client",
+			userField:     INTROSPECTION_USER_FIELD_SUB,
+			expectedError: "Invalid introspection connector prefix ",
+		},
+	}
+
+	for n, test := range testcases {
+		_, err := InitIntrospectionConnector(log.New(), http.DefaultClient, test.endpoint, test.clientID, "secret", test.userField,
+			test.prefix)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/square/go-jose"
+	"github.com/tecsisa/foulkon/api"
+)
+
+var (
+	// Only asymmetric algorithms are allowed, so public keys can't be used as HMAC secrets
+	jwtValidMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+// Public key used to verify token signatures, with the key ID of JWKS keys
+type jwtKey struct {
+	id  string
+	key interface{}
+}
+
+// This struct represents a JWT connector that implements interface of auth connector. Tokens are verified
+// offline with the public keys of JWKS or PEM files, that are reloaded when the files change.
+type JWTAuthConnector struct {
+	issuer    string
+	audiences []string
+	userClaim string
+	prefix    string
+	keyFiles  []string
+	logger    *log.Logger
+
+	// Keys loaded and modification time of files when they were loaded
+	mutex    sync.RWMutex
+	keys     []jwtKey
+	modTimes map[string]time.Time
+}
+
+// Returns a JWT connector that accepts tokens of issuer for any of the audiences, signed with a key of the key files.
+// External ID of users is the prefix followed by the user claim of tokens.
+func InitJWTConnector(logger *log.Logger, issuer string, audiences []string, userClaim string, prefix string,
+	keyFiles []string) (AuthConnector, error) {
+	if issuer == "" || len(audiences) == 0 || userClaim == "" || len(keyFiles) == 0 {
+		return nil, errors.New("JWT connector needs issuer, audiences, user claim and key files")
+	}
+	if !api.IsValidUserExternalID(prefix) {
+		return nil, errors.New(fmt.Sprintf("Invalid JWT connector prefix %v", prefix))
+	}
+	connector := &JWTAuthConnector{
+		issuer:    issuer,
+		audiences: audiences,
+		userClaim: userClaim,
+		prefix:    prefix,
+		keyFiles:  keyFiles,
+		logger:    logger,
+		modTimes:  map[string]time.Time{},
+	}
+	if err := connector.reloadKeys(); err != nil {
+		return nil, err
+	}
+	return connector, nil
+}
+
+// This method retrieves token from request and checks its signature and claims
+func (c *JWTAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := getBearerToken(r)
+		if !ok {
+			http.Error(w, "Error token not found", http.StatusUnauthorized)
+			return
+		}
+
+		// Keys are reloaded before validation if files changed, previous keys are used if they can't be loaded
+		if err := c.reloadKeys(); err != nil {
+			c.logger.WithFields(log.Fields{
+				"requestID": r.Header.Get("Request-ID"),
+			}).Errorf("Error reloading JWT keys: %v", err)
+		}
+
+		userID, err := c.validateToken(token)
+		if err != nil {
+			c.logger.WithFields(log.Fields{
+				"requestID": r.Header.Get("Request-ID"),
+			}).Error(err)
+			http.Error(w, fmt.Sprintf("Error %v", err), http.StatusUnauthorized)
+			return
+		}
+
+		// Header is replaced, so it can't be forged by clients
+		r.Header.Set(USER_ID_HEADER, c.prefix+userID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Only requests with a bearer JWT are authenticated by this connector
+func (c *JWTAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	return hasBearerJWT(r)
+}
+
+// Retrieve user from JWT token
+func (c *JWTAuthConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Check token signature with keys, its issuer, audience and validity dates. Returns the user of the token.
+func (c *JWTAuthConnector) validateToken(tokenString string) (string, error) {
+	// Retrieve key ID from header to select keys
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return "", errors.New("Malformed token")
+	}
+	headerBytes, err := jwt.DecodeSegment(parts[0])
+	if err != nil {
+		return "", errors.New("Malformed token")
+	}
+	header := map[string]interface{}{}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return "", errors.New("Malformed token")
+	}
+	kid, _ := header["kid"].(string)
+
+	parser := &jwt.Parser{
+		ValidMethods:         jwtValidMethods,
+		SkipClaimsValidation: true,
+	}
+	var token *jwt.Token
+	for _, key := range c.getKeys(kid) {
+		token, err = parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		})
+		if err == nil {
+			break
+		}
+		if verr, ok := err.(*jwt.ValidationError); ok && verr.Errors&jwt.ValidationErrorMalformed != 0 {
+			return "", errors.New("Malformed token")
+		}
+	}
+	if token == nil || err != nil {
+		return "", errors.New("Invalid token signature")
+	}
+
+	// Check claims
+	claims := token.Claims.(jwt.MapClaims)
+	now := time.Now().Unix()
+	if !claims.VerifyIssuer(c.issuer, true) {
+		return "", errors.New(fmt.Sprintf("Invalid token issuer %v", claims["iss"]))
+	}
+	if !c.isAudienceAllowed(getClaimValues(claims["aud"])) {
+		return "", errors.New(fmt.Sprintf("Invalid token audience %v", claims["aud"]))
+	}
+	if !claims.VerifyExpiresAt(now, true) {
+		return "", errors.New("Token is expired")
+	}
+	if !claims.VerifyNotBefore(now, false) {
+		return "", errors.New("Token is not valid yet")
+	}
+	userID, ok := claims[c.userClaim].(string)
+	if !ok || userID == "" {
+		return "", errors.New(fmt.Sprintf("Token without user claim %v", c.userClaim))
+	}
+	return userID, nil
+}
+
+// Returns true if any audience of token is allowed
+func (c *JWTAuthConnector) isAudienceAllowed(audiences []string) bool {
+	for _, audience := range c.audiences {
+		if isClaimValueContained(audience, audiences) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retrieve keys with key ID, or keys without ID if there aren't keys with that ID
+func (c *JWTAuthConnector) getKeys(kid string) []interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	keys := []interface{}{}
+	if kid != "" {
+		for _, key := range c.keys {
+			if key.id == kid {
+				keys = append(keys, key.key)
+			}
+		}
+		if len(keys) > 0 {
+			return keys
+		}
+	}
+	for _, key := range c.keys {
+		if kid == "" || key.id == "" {
+			keys = append(keys, key.key)
+		}
+	}
+	return keys
+}
+
+// Load keys of all files if any file changed since last load
+func (c *JWTAuthConnector) reloadKeys() error {
+	modTimes := map[string]time.Time{}
+	changed := false
+	c.mutex.RLock()
+	for _, file := range c.keyFiles {
+		info, err := os.Stat(file)
+		if err != nil {
+			c.mutex.RUnlock()
+			return err
+		}
+		modTimes[file] = info.ModTime()
+		if loaded, ok := c.modTimes[file]; !ok || !loaded.Equal(info.ModTime()) {
+			changed = true
+		}
+	}
+	c.mutex.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	keys := []jwtKey{}
+	for _, file := range c.keyFiles {
+		fileKeys, err := readKeyFile(file)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.keys = keys
+	for file, modTime := range modTimes {
+		c.modTimes[file] = modTime
+	}
+	c.logger.Infof("JWT connector loaded %v keys", len(keys))
+	return nil
+}
+
+// Read public keys of a JWKS file, or PEM file with public keys or certificates
+func readKeyFile(file string) ([]jwtKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	keys := []jwtKey{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		jwks := jose.JsonWebKeySet{}
+		if err := json.Unmarshal(data, &jwks); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid JWKS file %v: %v", file, err))
+		}
+		for _, jwk := range jwks.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			switch key := jwk.Key.(type) {
+			case *rsa.PublicKey, *ecdsa.PublicKey:
+				keys = append(keys, jwtKey{id: jwk.KeyID, key: key})
+			default:
+				return nil, errors.New(fmt.Sprintf("Invalid JWKS file %v: key %v isn't a RSA or EC public key", file, jwk.KeyID))
+			}
+		}
+	} else {
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			var key interface{}
+			switch block.Type {
+			case "PUBLIC KEY":
+				key, err = x509.ParsePKIXPublicKey(block.Bytes)
+			case "RSA PUBLIC KEY":
+				key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+			case "CERTIFICATE":
+				var cert *x509.Certificate
+				if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+					key = cert.PublicKey
+				}
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Invalid PEM file %v: %v", file, err))
+			}
+			switch key.(type) {
+			case *rsa.PublicKey, *ecdsa.PublicKey:
+				keys = append(keys, jwtKey{key: key})
+			default:
+				return nil, errors.New(fmt.Sprintf("Invalid PEM file %v: key isn't a RSA or EC public key", file))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(fmt.Sprintf("Key file %v without public keys", file))
+	}
+	return keys, nil
+}
@@ -0,0 +1,431 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/square/go-jose"
+)
+
+// Aux signing key of test tokens
+type testJWTKey struct {
+	method jwt.SigningMethod
+	kid    string
+	key    crypto.Signer
+}
+
+// Aux method that signs a token with the given claims
+func signTestToken(t *testing.T, key testJWTKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.kid != "" {
+		token.Header["kid"] = key.kid
+	}
+	signed, err := token.SignedString(key.key)
+	if err != nil {
+		t.Fatalf("Unexpected error signing token: %v", err)
+	}
+	return signed
+}
+
+// Aux method that writes a JWKS file with the public key of each key
+func writeJWKSFile(t *testing.T, file string, keys ...testJWTKey) {
+	jwks := jose.JsonWebKeySet{}
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, jose.JsonWebKey{
+			Key:   key.key.Public(),
+			KeyID: key.kid,
+			Use:   "sig",
+		})
+	}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding JWKS: %v", err)
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatalf("Unexpected error writing JWKS file: %v", err)
+	}
+}
+
+// Aux method that writes a PEM file with the public key
+func writePublicKeyFile(t *testing.T, file string, key testJWTKey) {
+	der, err := x509.MarshalPKIXPublicKey(key.key.Public())
+	if err != nil {
+		t.Fatalf("Unexpected error encoding public key: %v", err)
+	}
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Unexpected error writing PEM file: %v", err)
+	}
+}
+
+// Aux method that writes a PEM file with a self-signed certificate of the key
+func writeCertificateFile(t *testing.T, file string, key testJWTKey) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.key.Public(), key.key)
+	if err != nil {
+		t.Fatalf("Unexpected error creating certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Unexpected error writing PEM file: %v", err)
+	}
+}
+
+func generateRSAKey(t *testing.T, kid string) testJWTKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Unexpected error generating RSA key: %v", err)
+	}
+	return testJWTKey{method: jwt.SigningMethodRS256, kid: kid, key: key}
+}
+
+func generateECKey(t *testing.T, kid string) testJWTKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error generating EC key: %v", err)
+	}
+	return testJWTKey{method: jwt.SigningMethodES256, kid: kid, key: key}
+}
+
+func TestJWTAuthConnector_Authenticate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-jwt")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Keys of JWKS file, PEM public key file and PEM certificate file
+	jwksKey := generateRSAKey(t, "rsa1")
+	pemKey := generateECKey(t, "")
+	certKey := generateRSAKey(t, "")
+	unknownKey := generateRSAKey(t, "rsa1")
+	keyFiles := []string{
+		filepath.Join(dir, "jwks.json"),
+		filepath.Join(dir, "key.pem"),
+		filepath.Join(dir, "cert.pem"),
+	}
+	writeJWKSFile(t, keyFiles[0], jwksKey)
+	writePublicKeyFile(t, keyFiles[1], pemKey)
+	writeCertificateFile(t, keyFiles[2], certKey)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	validClaims := func(extra jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss": "https://idp.example.com",
+			"aud": "foulkon",
+			"sub": "user1",
+			"exp": exp,
+		}
+		for claim, value := range extra {
+			if value == nil {
+				delete(claims, claim)
+			} else {
+				claims[claim] = value
+			}
+		}
+		return claims
+	}
+
+	testcases := map[string]struct {
+		// Connector args
+		userClaim string
+		// Request args
+		authorization string
+		// Expected result
+		expectedStatusCode int
+		expectedUserID     string
+		expectedBody       string
+	}{
+		"OkCaseJWKSKey": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(nil)),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.user1",
+		},
+		"OkCasePEMPublicKey": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, pemKey, validClaims(nil)),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.user1",
+		},
+		"OkCasePEMCertificate": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, certKey, validClaims(nil)),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.user1",
+		},
+		"OkCaseAudienceList": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"aud": []string{"other", "client1"}})),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.user1",
+		},
+		"OkCaseUserClaim": {
+			userClaim:          "email",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"email": "user1@example.com"})),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.user1@example.com",
+		},
+		"OkCaseSubjectOfDatabaseUser": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"sub": "admin"})),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "jwt.idp.example.com.admin",
+		},
+		"ErrorCaseTokenNotFound": {
+			userClaim:          "sub",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error token not found\n",
+		},
+		"ErrorCaseMalformedToken": {
+			userClaim:          "sub",
+			authorization:      "Bearer a.b.c",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Malformed token\n",
+		},
+		"ErrorCaseUnknownKey": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, unknownKey, validClaims(nil)),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid token signature\n",
+		},
+		"ErrorCaseSymmetricAlgorithm": {
+			userClaim: "sub",
+			authorization: "Bearer " + func() string {
+				token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims(nil)).SignedString([]byte("secret"))
+				return token
+			}(),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid token signature\n",
+		},
+		"ErrorCaseInvalidIssuer": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"iss": "https://other.example.com"})),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid token issuer https://other.example.com\n",
+		},
+		"ErrorCaseInvalidAudience": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"aud": "other"})),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid token audience other\n",
+		},
+		"ErrorCaseExpiredToken": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Token is expired\n",
+		},
+		"ErrorCaseTokenWithoutExp": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"exp": nil})),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Token is expired\n",
+		},
+		"ErrorCaseTokenNotValidYet": {
+			userClaim:          "sub",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(jwt.MapClaims{"nbf": time.Now().Add(time.Hour).Unix()})),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Token is not valid yet\n",
+		},
+		"ErrorCaseTokenWithoutUserClaim": {
+			userClaim:          "email",
+			authorization:      "Bearer " + signTestToken(t, jwksKey, validClaims(nil)),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Token without user claim email\n",
+		},
+	}
+
+	for n, test := range testcases {
+		connector, err := InitJWTConnector(log.New(), "https://idp.example.com", []string{"foulkon", "client1"},
+			test.userClaim, "jwt.idp.example.com.", keyFiles)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		// Check result
+		if res.Code != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			continue
+		}
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+		if test.expectedBody != "" && res.Body.String() != test.expectedBody {
+			t.Errorf("Test %v failed. Received different body (wanted:%v / received:%v)", n, test.expectedBody, res.Body.String())
+			continue
+		}
+	}
+}
+
+func TestJWTAuthConnector_ReloadKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-jwt")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldKey := generateRSAKey(t, "old")
+	newKey := generateRSAKey(t, "new")
+	file := filepath.Join(dir, "jwks.json")
+	writeJWKSFile(t, file, oldKey)
+	connector, err := InitJWTConnector(log.New(), "https://idp.example.com", []string{"foulkon"}, "sub", "jwt.idp.example.com.", []string{file})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	authenticate := func(key testJWTKey) int {
+		token := signTestToken(t, key, jwt.MapClaims{
+			"iss": "https://idp.example.com",
+			"aud": "foulkon",
+			"sub": "user1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res := httptest.NewRecorder()
+		connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(res, req)
+		return res.Code
+	}
+	if code := authenticate(newKey); code != http.StatusUnauthorized {
+		t.Errorf("Received different http status code with key that isn't loaded yet: %v", code)
+	}
+
+	// Rotate keys with a later modification time, so the change is detected
+	writeJWKSFile(t, file, newKey)
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("Unexpected error changing modification time: %v", err)
+	}
+	if code := authenticate(newKey); code != http.StatusOK {
+		t.Errorf("Received different http status code with rotated key: %v", code)
+	}
+	if code := authenticate(oldKey); code != http.StatusUnauthorized {
+		t.Errorf("Received different http status code with removed key: %v", code)
+	}
+
+	// Previous keys are kept when the file is invalid
+	if err := ioutil.WriteFile(file, []byte("{invalid"), 0600); err != nil {
+		t.Fatalf("Unexpected error writing JWKS file: %v", err)
+	}
+	modTime = modTime.Add(time.Minute)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("Unexpected error changing modification time: %v", err)
+	}
+	if code := authenticate(newKey); code != http.StatusOK {
+		t.Errorf("Received different http status code with invalid key file: %v", code)
+	}
+}
+
+func TestInitJWTConnector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-jwt")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	validFile := filepath.Join(dir, "jwks.json")
+	writeJWKSFile(t, validFile, generateRSAKey(t, "rsa1"))
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalidFile, []byte("not a key"), 0600); err != nil {
+		t.Fatalf("Unexpected error writing key file: %v", err)
+	}
+
+	testcases := map[string]struct {
+		// Connector args
+		issuer    string
+		audiences []string
+		prefix    string
+		keyFiles  []string
+		// Expected result
+		expectedError string
+	}{
+		"OkCase": {
+			issuer:    "https://idp.example.com",
+			audiences: []string{"foulkon"},
+			prefix:    "jwt.idp.example.com.",
+			keyFiles:  []string{validFile},
+		},
+		"ErrorCaseWithoutIssuer": {
+			audiences:     []string{"foulkon"},
+			keyFiles:      []string{validFile},
+			expectedError: "JWT connector needs issuer, audiences, user claim and key files",
+		},
+		"ErrorCaseWithoutAudiences": {
+			issuer:        "https://idp.example.com",
+			keyFiles:      []string{validFile},
+			expectedError: "JWT connector needs issuer, audiences, user claim and key files",
+		},
+		"ErrorCaseWithoutPrefix": {
+			issuer:        "https://idp.example.com",
+			audiences:     []string{"foulkon"},
+			keyFiles:      []string{validFile},
+			expectedError: "Invalid JWT connector prefix ",
+		},
+		"ErrorCaseInvalidPrefix": {
+			issuer:        "https://idp.example.com",
+			audiences:     []string{"foulkon"},
+			prefix:        "jwt/idp.",
+			keyFiles:      []string{validFile},
+			expectedError: "Invalid JWT connector prefix jwt/idp.",
+		},
+		"ErrorCaseFileWithoutKeys": {
+			issuer:        "https://idp.example.com",
+			audiences:     []string{"foulkon"},
+			prefix:        "jwt.idp.example.com.",
+			keyFiles:      []string{invalidFile},
+			expectedError: "Key file " + invalidFile + " without public keys",
+		},
+		"ErrorCaseFileNotFound": {
+			issuer:        "https://idp.example.com",
+			audiences:     []string{"foulkon"},
+			prefix:        "jwt.idp.example.com.",
+			keyFiles:      []string{filepath.Join(dir, "notfound.json")},
+			expectedError: "stat " + filepath.Join(dir, "notfound.json") + ": no such file or directory",
+		},
+	}
+
+	for n, test := range testcases {
+		_, err := InitJWTConnector(log.New(), test.issuer, test.audiences, "sub", test.prefix, test.keyFiles)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+		}
+	}
+}
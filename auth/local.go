@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Interface that retrieves the user of local credentials or session tokens, implemented by api.AuthAPI
+type PasswordAuthenticator interface {
+	AuthenticateUserPassword(username string, password string) (*api.User, error)
+	AuthenticateSession(token string) (*api.User, error)
+}
+
+// This struct represents a local connector that implements interface of auth connector. Requests are
+// authenticated with local passwords using Basic Authentication scheme, or with session tokens issued by
+// the login endpoint in a bearer Authorization header.
+type LocalAuthConnector struct {
+	authenticator PasswordAuthenticator
+	logger        *log.Logger
+}
+
+func InitLocalConnector(logger *log.Logger, authenticator PasswordAuthenticator) (AuthConnector, error) {
+	return &LocalAuthConnector{
+		authenticator: authenticator,
+		logger:        logger,
+	}, nil
+}
+
+// Only requests with Basic Authentication or a session token are authenticated by this connector
+func (c LocalAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	if _, _, ok := r.BasicAuth(); ok {
+		return true
+	}
+	_, ok := getSessionToken(r)
+	return ok
+}
+
+// This method retrieves local credentials or session token from request and checks them
+func (c LocalAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user *api.User
+		var err error
+		if username, password, ok := r.BasicAuth(); ok {
+			user, err = c.authenticator.AuthenticateUserPassword(username, password)
+		} else if token, ok := getSessionToken(r); ok {
+			user, err = c.authenticator.AuthenticateSession(token)
+		} else {
+			http.Error(w, "Error credentials not found", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			c.logger.WithFields(log.Fields{
+				"requestID": r.Header.Get("Request-ID"),
+			}).Error(err)
+			if apiError, ok := err.(*api.Error); ok && (apiError.Code == api.INVALID_USER_CREDENTIALS ||
+				apiError.Code == api.USER_LOCKED_OUT || apiError.Code == api.INVALID_SESSION_TOKEN) {
+				http.Error(w, fmt.Sprintf("Error %v", apiError.Message), http.StatusUnauthorized)
+			} else {
+				http.Error(w, "Unexpected error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Header is replaced, so it can't be forged by clients
+		r.Header.Set(USER_ID_HEADER, user.ExternalID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Retrieve user from local credentials or session token
+func (c LocalAuthConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Retrieve session token from bearer Authorization header
+func getSessionToken(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer "+api.SESSION_TOKEN_PREFIX) {
+		return "", false
+	}
+	return strings.TrimPrefix(authorization, "Bearer "), true
+}
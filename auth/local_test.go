@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Aux password authenticator with a single user
+type testPasswordAuthenticator struct {
+	lockedOut bool
+}
+
+func (ta testPasswordAuthenticator) AuthenticateUserPassword(username string, password string) (*api.User, error) {
+	if ta.lockedOut {
+		return nil, &api.Error{
+			Code:    api.USER_LOCKED_OUT,
+			Message: "User user1 is locked out",
+		}
+	}
+	if username != "user1" || password != "password" {
+		return nil, &api.Error{
+			Code:    api.INVALID_USER_CREDENTIALS,
+			Message: "Invalid user credentials",
+		}
+	}
+	return &api.User{ExternalID: username}, nil
+}
+
+func (ta testPasswordAuthenticator) AuthenticateSession(token string) (*api.User, error) {
+	switch token {
+	case api.SESSION_TOKEN_PREFIX + "token":
+		return &api.User{ExternalID: "user1"}, nil
+	case api.SESSION_TOKEN_PREFIX + "error":
+		return nil, &api.Error{
+			Code:    api.UNKNOWN_API_ERROR,
+			Message: "Unexpected error",
+		}
+	default:
+		return nil, &api.Error{
+			Code:    api.INVALID_SESSION_TOKEN,
+			Message: "Invalid session token",
+		}
+	}
+}
+
+func TestLocalAuthConnector_Authenticate(t *testing.T) {
+	testcases := map[string]struct {
+		// Connector args
+		lockedOut bool
+		// Request args
+		username      string
+		password      string
+		authorization string
+		// Expected result
+		expectedRecognized bool
+		expectedStatusCode int
+		expectedUserID     string
+		expectedBody       string
+	}{
+		"OkCaseBasicAuth": {
+			username:           "user1",
+			password:           "password",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user1",
+		},
+		"OkCaseSessionToken": {
+			authorization:      "Bearer " + api.SESSION_TOKEN_PREFIX + "token",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "user1",
+		},
+		"ErrorCaseInvalidPassword": {
+			username:           "user1",
+			password:           "invalid",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid user credentials\n",
+		},
+		"ErrorCaseLockedOut": {
+			lockedOut:          true,
+			username:           "user1",
+			password:           "password",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error User user1 is locked out\n",
+		},
+		"ErrorCaseInvalidSessionToken": {
+			authorization:      "Bearer " + api.SESSION_TOKEN_PREFIX + "invalid",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error Invalid session token\n",
+		},
+		"ErrorCaseUnexpectedError": {
+			authorization:      "Bearer " + api.SESSION_TOKEN_PREFIX + "error",
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "Unexpected error\n",
+		},
+		"ErrorCaseOtherBearerToken": {
+			authorization:      "Bearer token",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error credentials not found\n",
+		},
+	}
+
+	for n, test := range testcases {
+		connector, err := InitLocalConnector(log.New(), testPasswordAuthenticator{lockedOut: test.lockedOut})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.username != "" {
+			req.SetBasicAuth(test.username, test.password)
+		} else {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		// Check result
+		if recognized := connector.(CredentialsRecognizer).RecognizesCredentials(req); recognized != test.expectedRecognized {
+			t.Errorf("Test %v failed. Received different recognition of credentials: %v", n, recognized)
+			continue
+		}
+		if res.Code != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			continue
+		}
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+		if test.expectedBody != "" && res.Body.String() != test.expectedBody {
+			t.Errorf("Test %v failed. Received different body (wanted:%v / received:%v)", n, test.expectedBody, res.Body.String())
+			continue
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Certificate fields that rules can match
+const (
+	CERT_FIELD_COMMON_NAME = "cn"
+	CERT_FIELD_SUBJECT     = "subject"
+	CERT_FIELD_SAN_DNS     = "san.dns"
+	CERT_FIELD_SAN_EMAIL   = "san.email"
+	CERT_FIELD_SAN_URI     = "san.uri"
+	CERT_FIELD_SAN_IP      = "san.ip"
+)
+
+// Rule that maps a field of client certificates to an external ID. External ID can reference
+// submatches of pattern like $1 or ${name}.
+type CertificateRule struct {
+	Field      string
+	Pattern    *regexp.Regexp
+	ExternalID string
+}
+
+// This struct represents an mTLS connector that implements interface of auth connector. Client certificates
+// are verified by the TLS listener, and the connector maps the certificate to a user with the first matching rule.
+type MTLSAuthConnector struct {
+	prefix string
+	rules  []CertificateRule
+	logger *log.Logger
+}
+
+// Returns an mTLS connector whose users have the prefix followed by the external ID of the matching rule
+func InitMTLSConnector(logger *log.Logger, prefix string, rules []CertificateRule) (AuthConnector, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("mTLS connector needs at least one rule")
+	}
+	for _, rule := range rules {
+		switch rule.Field {
+		case CERT_FIELD_COMMON_NAME, CERT_FIELD_SUBJECT, CERT_FIELD_SAN_DNS, CERT_FIELD_SAN_EMAIL, CERT_FIELD_SAN_URI, CERT_FIELD_SAN_IP:
+		default:
+			return nil, errors.New(fmt.Sprintf("Invalid mTLS rule field %v", rule.Field))
+		}
+		if rule.Pattern == nil || rule.ExternalID == "" {
+			return nil, errors.New(fmt.Sprintf("Invalid mTLS rule of field %v without pattern or external ID", rule.Field))
+		}
+	}
+	if !api.IsValidUserExternalID(prefix) {
+		return nil, errors.New(fmt.Sprintf("Invalid mTLS connector prefix %v", prefix))
+	}
+	return &MTLSAuthConnector{
+		prefix: prefix,
+		rules:  rules,
+		logger: logger,
+	}, nil
+}
+
+// This method retrieves verified client certificate from request and maps it to a user
+func (c MTLSAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			http.Error(w, "Error client certificate not found", http.StatusUnauthorized)
+			return
+		}
+
+		cert := r.TLS.VerifiedChains[0][0]
+		externalID, ok := c.getExternalID(cert)
+		if !ok {
+			c.logger.WithFields(log.Fields{
+				"requestID": r.Header.Get("Request-ID"),
+			}).Errorf("Client certificate %v doesn't match any rule", cert.Subject.String())
+			http.Error(w, "Error client certificate doesn't match any rule", http.StatusUnauthorized)
+			return
+		}
+
+		// Header is replaced, so it can't be forged by clients
+		r.Header.Set(USER_ID_HEADER, externalID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Only requests with a verified client certificate are authenticated by this connector
+func (c MTLSAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// Retrieve user from client certificate
+func (c MTLSAuthConnector) RetrieveUserID(r http.Request) string {
+	userID := r.Header.Get(USER_ID_HEADER)
+	r.Header.Del(USER_ID_HEADER)
+	return userID
+}
+
+// Map certificate to external ID with the first rule that matches any value of its field
+func (c MTLSAuthConnector) getExternalID(cert *x509.Certificate) (string, bool) {
+	for _, rule := range c.rules {
+		for _, value := range getCertificateValues(cert, rule.Field) {
+			match := rule.Pattern.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			externalID := c.prefix + string(rule.Pattern.ExpandString(nil, rule.ExternalID, value, match))
+			if api.IsValidUserExternalID(externalID) {
+				return externalID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Retrieve values of a certificate field as strings
+func getCertificateValues(cert *x509.Certificate, field string) []string {
+	values := []string{}
+	switch field {
+	case CERT_FIELD_COMMON_NAME:
+		if cert.Subject.CommonName != "" {
+			values = append(values, cert.Subject.CommonName)
+		}
+	case CERT_FIELD_SUBJECT:
+		values = append(values, cert.Subject.String())
+	case CERT_FIELD_SAN_DNS:
+		values = append(values, cert.DNSNames...)
+	case CERT_FIELD_SAN_EMAIL:
+		values = append(values, cert.EmailAddresses...)
+	case CERT_FIELD_SAN_URI:
+		for _, uri := range cert.URIs {
+			values = append(values, uri.String())
+		}
+	case CERT_FIELD_SAN_IP:
+		for _, ip := range cert.IPAddresses {
+			values = append(values, ip.String())
+		}
+	}
+	return values
+}
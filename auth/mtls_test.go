@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Aux method that generates a self-signed client certificate from template
+func generateTestCertificate(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error generating key: %v", err)
+	}
+	template.SerialNumber = big.NewInt(1)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing certificate: %v", err)
+	}
+	return cert
+}
+
+func TestMTLSAuthConnector_Authenticate(t *testing.T) {
+	uri, _ := url.Parse("spiffe://example.com/service1")
+	serviceCert := generateTestCertificate(t, &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "service1.svc.example.com", Organization: []string{"Example"}},
+		DNSNames:       []string{"service1.example.com"},
+		EmailAddresses: []string{"service1@example.com"},
+		URIs:           []*url.URL{uri},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	})
+	otherCert := generateTestCertificate(t, &x509.Certificate{
+		Subject: pkix.Name{CommonName: "other.example.com"},
+	})
+	invalidIDCert := generateTestCertificate(t, &x509.Certificate{
+		Subject: pkix.Name{CommonName: "invalid id.svc.example.com"},
+	})
+
+	testcases := map[string]struct {
+		// Connector args
+		rules []CertificateRule
+		// Request args
+		tlsState *tls.ConnectionState
+		// Expected result
+		expectedRecognized bool
+		expectedStatusCode int
+		expectedUserID     string
+		expectedBody       string
+	}{
+		"OkCaseCommonName": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.svc.service1",
+		},
+		"OkCaseSubject": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_SUBJECT, Pattern: regexp.MustCompile(`^CN=(\w+)\.svc\.example\.com,O=Example$`), ExternalID: "$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.service1",
+		},
+		"OkCaseSANDNS": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_SAN_DNS, Pattern: regexp.MustCompile(`^(?P<name>\w+)\.example\.com$`), ExternalID: "dns.${name}"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.dns.service1",
+		},
+		"OkCaseSANEmail": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_SAN_EMAIL, Pattern: regexp.MustCompile(`^.+@example\.com$`), ExternalID: "$0"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.service1@example.com",
+		},
+		"OkCaseSANURI": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_SAN_URI, Pattern: regexp.MustCompile(`^spiffe://example\.com/(\w+)$`), ExternalID: "spiffe.$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.spiffe.service1",
+		},
+		"OkCaseSANIP": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_SAN_IP, Pattern: regexp.MustCompile(`^10\.0\.0\.(\d+)$`), ExternalID: "host$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.host1",
+		},
+		"OkCaseFirstMatchingRule": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^other\.example\.com$`), ExternalID: "other"},
+				{Field: CERT_FIELD_SAN_DNS, Pattern: regexp.MustCompile(`^(\w+)\.example\.com$`), ExternalID: "dns.$1"},
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{serviceCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.dns.service1",
+		},
+		"ErrorCaseWithoutTLS": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error client certificate not found\n",
+		},
+		"ErrorCaseUnverifiedCertificate": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			tlsState:           &tls.ConnectionState{PeerCertificates: []*x509.Certificate{serviceCert}},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error client certificate not found\n",
+		},
+		"ErrorCaseCertificateWithoutMatchingRule": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{otherCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error client certificate doesn't match any rule\n",
+		},
+		"ErrorCaseInvalidExternalID": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(.+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+			},
+			tlsState:           &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{invalidIDCert}}},
+			expectedRecognized: true,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Error client certificate doesn't match any rule\n",
+		},
+	}
+
+	for n, test := range testcases {
+		connector, err := InitMTLSConnector(log.New(), "mtls.", test.rules)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.TLS = test.tlsState
+		// Forged header is ignored
+		req.Header.Set(USER_ID_HEADER, "admin")
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		// Check result
+		if recognized := connector.(CredentialsRecognizer).RecognizesCredentials(req); recognized != test.expectedRecognized {
+			t.Errorf("Test %v failed. Received different recognition of credentials: %v", n, recognized)
+			continue
+		}
+		if res.Code != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			continue
+		}
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+		if test.expectedBody != "" && res.Body.String() != test.expectedBody {
+			t.Errorf("Test %v failed. Received different body (wanted:%v / received:%v)", n, test.expectedBody, res.Body.String())
+			continue
+		}
+	}
+}
+
+func TestInitMTLSConnector(t *testing.T) {
+	testcases := map[string]struct {
+		// Connector args
+		prefix string
+		rules  []CertificateRule
+		// Expected result
+		expectedError string
+	}{
+		"OkCase": {
+			prefix: "mtls.",
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`.*`), ExternalID: "$0"},
+			},
+		},
+		"ErrorCaseWithoutRules": {
+			rules:         []CertificateRule{},
+			expectedError: "mTLS connector needs at least one rule",
+		},
+		"ErrorCaseWithoutPrefix": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`.*`), ExternalID: "$0"},
+			},
+			expectedError: "Invalid mTLS connector prefix ",
+		},
+		"ErrorCaseInvalidField": {
+			rules: []CertificateRule{
+				{Field: "issuer", Pattern: regexp.MustCompile(`.*`), ExternalID: "$0"},
+			},
+			expectedError: "Invalid mTLS rule field issuer",
+		},
+		"ErrorCaseWithoutPattern": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, ExternalID: "$0"},
+			},
+			expectedError: "Invalid mTLS rule of field cn without pattern or external ID",
+		},
+		"ErrorCaseWithoutExternalID": {
+			rules: []CertificateRule{
+				{Field: CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`.*`)},
+			},
+			expectedError: "Invalid mTLS rule of field cn without pattern or external ID",
+		},
+	}
+
+	for n, test := range testcases {
+		_, err := InitMTLSConnector(log.New(), test.prefix, test.rules)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+		}
+	}
+}
@@ -1,32 +1,101 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"fmt"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/emanoelxavier/openid2go/openid"
+	"github.com/tecsisa/foulkon/api"
 )
 
 const (
 	USER_ID_HEADER = "USER-ID"
 )
 
+var (
+	claimPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+	invalidPathChars = regexp.MustCompile(`[^\w\-]`)
+)
+
+// Interface that creates users authenticated for the first time, implemented by api.AuthAPI
+type UserProvisioner interface {
+	ProvisionUser(requestID string, externalId string, path string) (*api.User, error)
+}
+
+// Interface that updates the groups of users from their claims, implemented by api.AuthAPI
+type GroupMapper interface {
+	ApplyGroupMappings(requestID string, externalId string, issuer string, claims map[string][]string) error
+}
+
+// Provisioning configuration of users that don't exist yet. Path template can contain claims
+// between braces, like /{hd}/, that are replaced with the claim values of the token. Only tokens
+// of allowed issuers with all allowed claims are provisioned, empty values allow all tokens.
+type OIDCProvisioning struct {
+	Provisioner  UserProvisioner
+	PathTemplate string
+	Issuers      []string
+	Claims       map[string]string
+}
+
+// Issuer of tokens accepted by the OIDC connector with its allowed clients. Prefix is added to the
+// subject of its tokens to build the external ID of users, so subjects of different issuers don't collide.
+type OIDCIssuer struct {
+	Issuer    string
+	ClientIDs []string
+	Prefix    string
+}
+
 // This struct represents an OIDC connector that implements interface of auth connector
 type OIDCAuthConnector struct {
 	configuration openid.Configuration
+	prefixes      map[string]string
+	provisioning  *OIDCProvisioning
+	groupMapper   GroupMapper
+	mappedTokens  *mappedTokens
+	logger        *log.Logger
 }
 
-func InitOIDCConnector(logger *log.Logger, provider string, clientids []string) (AuthConnector, error) {
-	getProviders := func() ([]openid.Provider, error) {
-		provider, err := openid.NewProvider(provider, clientids)
+// Digests of tokens whose claims were already applied by the group mapper, with their expiration time
+type mappedTokens struct {
+	sync.Mutex
+	expirations map[string]time.Time
+}
 
-		if err != nil {
-			return nil, err
+// Returns an OIDC connector that accepts tokens of all issuers. Users are created on their first request if
+// provisioning isn't nil, and their groups are updated from their claims once per token if group mapper isn't nil.
+func InitOIDCConnector(logger *log.Logger, issuers []OIDCIssuer, provisioning *OIDCProvisioning,
+	groupMapper GroupMapper) (AuthConnector, error) {
+	if len(issuers) == 0 {
+		return nil, errors.New("OIDC connector needs at least one issuer")
+	}
+	prefixes := map[string]string{}
+	for _, issuer := range issuers {
+		if _, ok := prefixes[issuer.Issuer]; ok {
+			return nil, errors.New(fmt.Sprintf("Duplicated OIDC issuer %v", issuer.Issuer))
+		}
+		prefixes[issuer.Issuer] = issuer.Prefix
+	}
+	getProviders := func() ([]openid.Provider, error) {
+		providers := []openid.Provider{}
+		for _, issuer := range issuers {
+			provider, err := openid.NewProvider(issuer.Issuer, issuer.ClientIDs)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
 		}
 
-		return []openid.Provider{provider}, nil
+		return providers, nil
 	}
 	errorHandler := func(e error, rw http.ResponseWriter, r *http.Request) bool {
 		requestID := r.Header.Get("Request-ID")
@@ -47,6 +116,11 @@ func InitOIDCConnector(logger *log.Logger, provider string, clientids []string)
 	configuration, _ := openid.NewConfiguration(openid.ProvidersGetter(getProviders), openid.ErrorHandler(errorHandler))
 	return &OIDCAuthConnector{
 		configuration: *configuration,
+		prefixes:      prefixes,
+		provisioning:  provisioning,
+		groupMapper:   groupMapper,
+		mappedTokens:  &mappedTokens{expirations: map[string]time.Time{}},
+		logger:        logger,
 	}, nil
 
 }
@@ -54,15 +128,151 @@ func InitOIDCConnector(logger *log.Logger, provider string, clientids []string)
 // This method retrieves data from request an checks if user is correctly authenticated
 func (c OIDCAuthConnector) Authenticate(h http.Handler) http.Handler {
 	userHandler := func(u *openid.User, w http.ResponseWriter, r *http.Request) {
-		r.Header.Add(USER_ID_HEADER, u.ID)
+		externalID := c.prefixes[u.Issuer] + u.ID
+		if c.provisioning != nil && c.provisioning.isAllowed(u) {
+			_, err := c.provisioning.Provisioner.ProvisionUser(r.Header.Get("Request-ID"), externalID, c.provisioning.getPath(u))
+			if err != nil {
+				// Request continues, user won't be found when it's authorized
+				c.logger.WithFields(log.Fields{
+					"requestID": r.Header.Get("Request-ID"),
+				}).Errorf("Error provisioning user %v: %v", externalID, err)
+			}
+		}
+		// Claims of a token don't change, so they are only applied on its first request
+		token, _ := getBearerToken(r)
+		if c.groupMapper != nil && !c.mappedTokens.isMapped(token) {
+			claims := map[string][]string{}
+			for claim, value := range u.Claims {
+				claims[claim] = getClaimValues(value)
+			}
+			if err := c.groupMapper.ApplyGroupMappings(r.Header.Get("Request-ID"), externalID, u.Issuer, claims); err != nil {
+				// Request continues with current groups of user
+				c.logger.WithFields(log.Fields{
+					"requestID": r.Header.Get("Request-ID"),
+				}).Errorf("Error applying group mappings to user %v: %v", externalID, err)
+			} else {
+				c.mappedTokens.add(token, getExpiration(u))
+			}
+		}
+		r.Header.Add(USER_ID_HEADER, externalID)
 		h.ServeHTTP(w, r)
 	}
 	return openid.AuthenticateUser(&c.configuration, openid.UserHandlerFunc(userHandler))
 }
 
+// Only requests with a bearer JWT are authenticated by this connector
+func (c OIDCAuthConnector) RecognizesCredentials(r *http.Request) bool {
+	return hasBearerJWT(r)
+}
+
 // Retrieve user from OIDC token
 func (c OIDCAuthConnector) RetrieveUserID(r http.Request) string {
 	userID := r.Header.Get(USER_ID_HEADER)
 	r.Header.Del(USER_ID_HEADER)
 	return userID
 }
+
+// Check if claims of token were already applied and it hasn't expired yet
+func (m *mappedTokens) isMapped(token string) bool {
+	m.Lock()
+	defer m.Unlock()
+	expiration, ok := m.expirations[tokenDigest(token)]
+	return ok && time.Now().Before(expiration)
+}
+
+// Record that claims of token were applied until it expires, forgetting expired tokens. Tokens without
+// expiration aren't recorded, so their claims are applied on every request.
+func (m *mappedTokens) add(token string, expiration time.Time) {
+	if expiration.IsZero() {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	now := time.Now()
+	for digest, e := range m.expirations {
+		if !now.Before(e) {
+			delete(m.expirations, digest)
+		}
+	}
+	m.expirations[tokenDigest(token)] = expiration
+}
+
+// Digest of token to identify it without keeping it in memory
+func tokenDigest(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+// Retrieve expiration time of user token, zero time if it doesn't have exp claim
+func getExpiration(u *openid.User) time.Time {
+	exp, ok := u.Claims["exp"].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
+
+// Check if user token comes from an allowed issuer and has allowed claims
+func (p OIDCProvisioning) isAllowed(u *openid.User) bool {
+	if len(p.Issuers) > 0 {
+		allowed := false
+		for _, issuer := range p.Issuers {
+			if issuer == u.Issuer {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for claim, value := range p.Claims {
+		if !isClaimValueContained(value, getClaimValues(u.Claims[claim])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Render path template with claims of user token
+func (p OIDCProvisioning) getPath(u *openid.User) string {
+	rendered := claimPlaceholder.ReplaceAllStringFunc(p.PathTemplate, func(placeholder string) string {
+		value, ok := u.Claims[strings.Trim(placeholder, "{}")]
+		if !ok || value == nil {
+			return ""
+		}
+		return invalidPathChars.ReplaceAllString(fmt.Sprint(value), "_")
+	})
+	// Remove empty segments of missing claims
+	userPath := path.Clean("/" + rendered)
+	if userPath != "/" {
+		userPath += "/"
+	}
+	return userPath
+}
+
+// Retrieve values of a claim as strings, list claims have a value per element
+func getClaimValues(claim interface{}) []string {
+	switch c := claim.(type) {
+	case nil:
+		return []string{}
+	case []interface{}:
+		values := []string{}
+		for _, element := range c {
+			values = append(values, fmt.Sprint(element))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(c)}
+	}
+}
+
+// Returns true if a value is contained in the values of a claim
+func isClaimValueContained(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,449 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/emanoelxavier/openid2go/openid"
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/square/go-jose"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Aux provisioner that stores users in memory like api.AuthAPI, recording the users it creates
+type testUserProvisioner struct {
+	users   map[string]*api.User
+	created []string
+}
+
+func (tp *testUserProvisioner) ProvisionUser(requestID string, externalId string, path string) (*api.User, error) {
+	if externalId == "one.fail" {
+		return nil, errors.New("Error")
+	}
+	if user, ok := tp.users[externalId]; ok {
+		return user, nil
+	}
+	user := &api.User{ExternalID: externalId, Path: path}
+	tp.users[externalId] = user
+	tp.created = append(tp.created, externalId)
+	return user, nil
+}
+
+// Aux group mapper that records the issuers of the tokens whose claims it applies
+type testGroupMapper struct {
+	issuers map[string]string
+}
+
+func (tm *testGroupMapper) ApplyGroupMappings(requestID string, externalId string, issuer string, claims map[string][]string) error {
+	tm.issuers[externalId] = issuer
+	return nil
+}
+
+// Aux method that starts an OIDC issuer serving its discovery document and the JWKS with the public key
+func startTestIssuer(t *testing.T, key testJWTKey) *httptest.Server {
+	jwks := jose.JsonWebKeySet{
+		Keys: []jose.JsonWebKey{
+			{Key: key.key.Public(), KeyID: key.kid, Use: "sig"},
+		},
+	}
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL,
+			"jwks_uri": server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(jwks); err != nil {
+			t.Errorf("Unexpected error encoding JWKS: %v", err)
+		}
+	})
+	return server
+}
+
+func TestOIDCAuthConnector_Authenticate(t *testing.T) {
+	key1 := generateRSAKey(t, "key1")
+	key2 := generateRSAKey(t, "key2")
+	issuer1 := startTestIssuer(t, key1)
+	defer issuer1.Close()
+	issuer2 := startTestIssuer(t, key2)
+	defer issuer2.Close()
+
+	connector, err := InitOIDCConnector(log.New(), []OIDCIssuer{
+		{Issuer: issuer1.URL, ClientIDs: []string{"client1"}, Prefix: "one."},
+		{Issuer: issuer2.URL, ClientIDs: []string{"client2"}, Prefix: "two."},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Tokens rejected by issuer or audience are reported by openid2go as unknown validation errors
+	exp := time.Now().Add(time.Hour).Unix()
+	testcases := map[string]struct {
+		// Request args
+		authorization string
+		// Expected result
+		expectedStatusCode int
+		expectedUserID     string
+	}{
+		"OkCaseFirstIssuer": {
+			authorization: "Bearer " + signTestToken(t, key1, jwt.MapClaims{
+				"iss": issuer1.URL, "aud": "client1", "sub": "user1", "exp": exp,
+			}),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "one.user1",
+		},
+		"OkCaseSecondIssuerWithSameSubject": {
+			authorization: "Bearer " + signTestToken(t, key2, jwt.MapClaims{
+				"iss": issuer2.URL, "aud": "client2", "sub": "user1", "exp": exp,
+			}),
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "two.user1",
+		},
+		"ErrorCaseClientOfOtherIssuer": {
+			authorization: "Bearer " + signTestToken(t, key2, jwt.MapClaims{
+				"iss": issuer2.URL, "aud": "client1", "sub": "user1", "exp": exp,
+			}),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		"ErrorCaseSignedByOtherIssuer": {
+			authorization: "Bearer " + signTestToken(t, key1, jwt.MapClaims{
+				"iss": issuer2.URL, "aud": "client2", "sub": "user1", "exp": exp,
+			}),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		"ErrorCaseUnknownIssuer": {
+			authorization: "Bearer " + signTestToken(t, key1, jwt.MapClaims{
+				"iss": "https://idp.example.com", "aud": "client1", "sub": "user1", "exp": exp,
+			}),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for n, test := range testcases {
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", test.authorization)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		// Check result
+		if res.Code != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.Code)
+			continue
+		}
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+	}
+}
+
+func TestOIDCAuthConnector_AuthenticateWithProvisioning(t *testing.T) {
+	key1 := generateRSAKey(t, "key1")
+	key2 := generateRSAKey(t, "key2")
+	issuer1 := startTestIssuer(t, key1)
+	defer issuer1.Close()
+	issuer2 := startTestIssuer(t, key2)
+	defer issuer2.Close()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	token1 := func(sub string) string {
+		return signTestToken(t, key1, jwt.MapClaims{
+			"iss": issuer1.URL, "aud": "client1", "sub": sub, "hd": "example", "exp": exp,
+		})
+	}
+	testcases := map[string]struct {
+		// Previous data
+		users map[string]*api.User
+		// Request args, a request per token
+		tokens []string
+		// Expected result
+		expectedUserID  string
+		expectedCreated []string
+		expectedPath    string
+	}{
+		"OkCaseFirstRequestCreatesUser": {
+			users:           map[string]*api.User{},
+			tokens:          []string{token1("user1")},
+			expectedUserID:  "one.user1",
+			expectedCreated: []string{"one.user1"},
+			expectedPath:    "/oidc/example/",
+		},
+		"OkCaseUserCreatedOnce": {
+			users:           map[string]*api.User{},
+			tokens:          []string{token1("user1"), token1("user1")},
+			expectedUserID:  "one.user1",
+			expectedCreated: []string{"one.user1"},
+			expectedPath:    "/oidc/example/",
+		},
+		"OkCaseExistingUserReused": {
+			users: map[string]*api.User{
+				"one.user1": {ExternalID: "one.user1", Path: "/users/"},
+			},
+			tokens:         []string{token1("user1")},
+			expectedUserID: "one.user1",
+			expectedPath:   "/users/",
+		},
+		"OkCaseDisallowedIssuerNotProvisioned": {
+			users: map[string]*api.User{},
+			tokens: []string{signTestToken(t, key2, jwt.MapClaims{
+				"iss": issuer2.URL, "aud": "client2", "sub": "user1", "exp": exp,
+			})},
+			expectedUserID: "two.user1",
+		},
+		"OkCaseRequestContinuesWhenProvisioningFails": {
+			users:          map[string]*api.User{},
+			tokens:         []string{token1("fail")},
+			expectedUserID: "one.fail",
+		},
+	}
+
+	for n, test := range testcases {
+		provisioner := &testUserProvisioner{users: test.users}
+		connector, err := InitOIDCConnector(log.New(), []OIDCIssuer{
+			{Issuer: issuer1.URL, ClientIDs: []string{"client1"}, Prefix: "one."},
+			{Issuer: issuer2.URL, ClientIDs: []string{"client2"}, Prefix: "two."},
+		}, &OIDCProvisioning{
+			Provisioner:  provisioner,
+			PathTemplate: "/oidc/{hd}/",
+			Issuers:      []string{issuer1.URL},
+		}, nil)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		var userID string
+		handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		}))
+		for _, token := range test.tokens {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+			if res.Code != http.StatusOK {
+				t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, http.StatusOK, res.Code)
+			}
+		}
+
+		// Check result
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+		if diff := pretty.Compare(provisioner.created, test.expectedCreated); diff != "" {
+			t.Errorf("Test %v failed. Received different created users (received/wanted) %v", n, diff)
+			continue
+		}
+		if test.expectedPath != "" {
+			if user, ok := provisioner.users[test.expectedUserID]; !ok || user.Path != test.expectedPath {
+				t.Errorf("Test %v failed. Received different provisioned user %v", n, user)
+				continue
+			}
+		}
+	}
+}
+
+func TestOIDCAuthConnector_AuthenticateWithGroupMapper(t *testing.T) {
+	key1 := generateRSAKey(t, "key1")
+	key2 := generateRSAKey(t, "key2")
+	issuer1 := startTestIssuer(t, key1)
+	defer issuer1.Close()
+	issuer2 := startTestIssuer(t, key2)
+	defer issuer2.Close()
+
+	mapper := &testGroupMapper{issuers: map[string]string{}}
+	connector, err := InitOIDCConnector(log.New(), []OIDCIssuer{
+		{Issuer: issuer1.URL, ClientIDs: []string{"client1"}, Prefix: "one."},
+		{Issuer: issuer2.URL, ClientIDs: []string{"client2"}, Prefix: "two."},
+	}, nil, mapper)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Mappings are applied with the issuer of the token, so mappings of other issuers can be ignored
+	exp := time.Now().Add(time.Hour).Unix()
+	tokens := []string{
+		signTestToken(t, key1, jwt.MapClaims{
+			"iss": issuer1.URL, "aud": "client1", "sub": "user1", "groups": []string{"developers"}, "exp": exp,
+		}),
+		signTestToken(t, key2, jwt.MapClaims{
+			"iss": issuer2.URL, "aud": "client2", "sub": "user1", "groups": []string{"developers"}, "exp": exp,
+		}),
+	}
+	handler := connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, token := range tokens {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusOK {
+			t.Errorf("Received different http status code (wanted:%v / received:%v)", http.StatusOK, res.Code)
+		}
+	}
+
+	expectedIssuers := map[string]string{
+		"one.user1": issuer1.URL,
+		"two.user1": issuer2.URL,
+	}
+	if diff := pretty.Compare(mapper.issuers, expectedIssuers); diff != "" {
+		t.Errorf("Received different issuers of group mappings (received/wanted) %v", diff)
+	}
+}
+
+func TestInitOIDCConnector(t *testing.T) {
+	testcases := map[string]struct {
+		// Connector args
+		issuers []OIDCIssuer
+		// Expected result
+		expectedError string
+	}{
+		"OkCase": {
+			issuers: []OIDCIssuer{
+				{Issuer: "https://idp1.example.com", ClientIDs: []string{"client"}, Prefix: "one."},
+				{Issuer: "https://idp2.example.com", ClientIDs: []string{"client"}, Prefix: "two."},
+			},
+		},
+		"ErrorCaseWithoutIssuers": {
+			issuers:       []OIDCIssuer{},
+			expectedError: "OIDC connector needs at least one issuer",
+		},
+		"ErrorCaseDuplicatedIssuer": {
+			issuers: []OIDCIssuer{
+				{Issuer: "https://idp1.example.com", ClientIDs: []string{"client"}, Prefix: "one."},
+				{Issuer: "https://idp1.example.com", ClientIDs: []string{"other"}, Prefix: "two."},
+			},
+			expectedError: "Duplicated OIDC issuer https://idp1.example.com",
+		},
+	}
+
+	for n, test := range testcases {
+		_, err := InitOIDCConnector(log.New(), test.issuers, nil, nil)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+		}
+	}
+}
+
+func TestOIDCProvisioning_isAllowed(t *testing.T) {
+	testcases := map[string]struct {
+		// Provisioning args
+		issuers []string
+		claims  map[string]string
+		// User args
+		user *openid.User
+		// Expected result
+		expectedAllowed bool
+	}{
+		"OkCaseWithoutRestrictions": {
+			user:            &openid.User{Issuer: "https://idp1.example.com", ID: "user1"},
+			expectedAllowed: true,
+		},
+		"OkCaseAllowedIssuer": {
+			issuers:         []string{"https://idp1.example.com", "https://idp2.example.com"},
+			user:            &openid.User{Issuer: "https://idp2.example.com", ID: "user1"},
+			expectedAllowed: true,
+		},
+		"OkCaseAllowedClaims": {
+			claims: map[string]string{"hd": "example.com", "groups": "admins"},
+			user: &openid.User{
+				Issuer: "https://idp1.example.com",
+				ID:     "user1",
+				Claims: map[string]interface{}{
+					"hd":     "example.com",
+					"groups": []interface{}{"users", "admins"},
+				},
+			},
+			expectedAllowed: true,
+		},
+		"ErrorCaseDisallowedIssuer": {
+			issuers:         []string{"https://idp1.example.com"},
+			user:            &openid.User{Issuer: "https://idp2.example.com", ID: "user1"},
+			expectedAllowed: false,
+		},
+		"ErrorCaseDisallowedClaim": {
+			claims: map[string]string{"hd": "example.com"},
+			user: &openid.User{
+				Issuer: "https://idp1.example.com",
+				ID:     "user1",
+				Claims: map[string]interface{}{"hd": "other.com"},
+			},
+			expectedAllowed: false,
+		},
+		"ErrorCaseMissingClaim": {
+			claims: map[string]string{"groups": "admins"},
+			user: &openid.User{
+				Issuer: "https://idp1.example.com",
+				ID:     "user1",
+				Claims: map[string]interface{}{},
+			},
+			expectedAllowed: false,
+		},
+	}
+
+	for n, test := range testcases {
+		provisioning := OIDCProvisioning{Issuers: test.issuers, Claims: test.claims}
+		if allowed := provisioning.isAllowed(test.user); allowed != test.expectedAllowed {
+			t.Errorf("Test %v failed. Received different result (wanted:%v / received:%v)", n, test.expectedAllowed, allowed)
+		}
+	}
+}
+
+func TestOIDCProvisioning_getPath(t *testing.T) {
+	testcases := map[string]struct {
+		// Provisioning args
+		pathTemplate string
+		// User args
+		claims map[string]interface{}
+		// Expected result
+		expectedPath string
+	}{
+		"OkCaseWithoutTemplate": {
+			pathTemplate: "",
+			expectedPath: "/",
+		},
+		"OkCaseStaticTemplate": {
+			pathTemplate: "/oidc/",
+			expectedPath: "/oidc/",
+		},
+		"OkCaseClaims": {
+			pathTemplate: "/oidc/{hd}/{department}/",
+			claims:       map[string]interface{}{"hd": "example", "department": "sales"},
+			expectedPath: "/oidc/example/sales/",
+		},
+		"OkCaseMissingClaim": {
+			pathTemplate: "/oidc/{hd}/{department}/",
+			claims:       map[string]interface{}{"department": "sales"},
+			expectedPath: "/oidc/sales/",
+		},
+		"OkCaseInvalidCharsReplaced": {
+			pathTemplate: "/oidc/{hd}/",
+			claims:       map[string]interface{}{"hd": "example.com/../admin"},
+			expectedPath: "/oidc/example_com____admin/",
+		},
+	}
+
+	for n, test := range testcases {
+		provisioning := OIDCProvisioning{PathTemplate: test.pathTemplate}
+		user := &openid.User{Issuer: "https://idp1.example.com", ID: "user1", Claims: test.claims}
+		if userPath := provisioning.getPath(user); userPath != test.expectedPath {
+			t.Errorf("Test %v failed. Received different path (wanted:%v / received:%v)", n, test.expectedPath, userPath)
+		}
+	}
+}
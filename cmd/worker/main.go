@@ -60,7 +60,12 @@ func main() {
 
 	core.Logger.Infof("Server running in %v:%v", core.Host, core.Port)
 	if core.CertFile != "" && core.KeyFile != "" {
-		core.Logger.Error(http.ListenAndServeTLS(core.Host+":"+core.Port, core.CertFile, core.KeyFile, internalhttp.WorkerHandlerRouter(core)).Error())
+		server := &http.Server{
+			Addr:      core.Host + ":" + core.Port,
+			Handler:   internalhttp.WorkerHandlerRouter(core),
+			TLSConfig: core.TLSConfig,
+		}
+		core.Logger.Error(server.ListenAndServeTLS(core.CertFile, core.KeyFile).Error())
 	} else {
 		core.Logger.Error(http.ListenAndServe(core.Host+":"+core.Port, internalhttp.WorkerHandlerRouter(core)).Error())
 	}
@@ -6,6 +6,9 @@ const (
 	// Database
 	INTERNAL_ERROR = "InternalError"
 
+	// Concurrency Codes
+	VERSION_MISMATCH = "VersionMismatch"
+
 	// User Codes
 	USER_NOT_FOUND = "UserNotFound"
 
@@ -20,6 +23,28 @@ const (
 
 	// Policy Codes
 	POLICY_NOT_FOUND = "PolicyNotFound"
+
+	// Organization Codes
+	ORGANIZATION_NOT_FOUND = "OrganizationNotFound"
+
+	// Access Request Codes
+	ACCESS_REQUEST_NOT_FOUND = "AccessRequestNotFound"
+
+	// Webhook Codes
+	WEBHOOK_NOT_FOUND = "WebhookNotFound"
+
+	// Api Key Codes
+	API_KEY_NOT_FOUND = "ApiKeyNotFound"
+
+	// Admin Codes
+	ADMIN_NOT_FOUND = "AdminNotFound"
+
+	// Password Codes
+	USER_CREDENTIALS_NOT_FOUND = "UserCredentialsNotFound"
+	SESSION_NOT_FOUND          = "SessionNotFound"
+
+	// Group Mapping Codes
+	GROUP_MAPPING_NOT_FOUND = "GroupMappingNotFound"
 )
 
 type Error struct {
@@ -0,0 +1,176 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ACCESS REQUEST REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddAccessRequest(request api.AccessRequest) (*api.AccessRequest, error) {
+
+	// Create access request model
+	requestDB := &AccessRequest{
+		ID:            request.ID,
+		UserID:        request.UserID,
+		GroupID:       request.GroupID,
+		Requester:     request.Requester,
+		Org:           request.Org,
+		GroupName:     request.Group,
+		Justification: request.Justification,
+		Duration:      request.Duration,
+		Status:        request.Status,
+		CreateAt:      request.CreateAt.UnixNano(),
+	}
+
+	// Store access request
+	err := r.Dbmap.Create(requestDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAccessRequestToAPIAccessRequest(requestDB), nil
+}
+
+func (r PostgresRepo) GetAccessRequestByID(id string) (*api.AccessRequest, error) {
+	request := &AccessRequest{}
+	query := r.Dbmap.Where("id like ?", id).First(request)
+
+	// Check if access request exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ACCESS_REQUEST_NOT_FOUND,
+			Message: fmt.Sprintf("Access request with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAccessRequestToAPIAccessRequest(request), nil
+}
+
+func (r PostgresRepo) GetAccessRequestsByGroupID(groupID string, status string) ([]api.AccessRequest, error) {
+	requests := []AccessRequest{}
+	query := r.Dbmap.Where("group_id like ?", groupID)
+	if len(status) > 0 {
+		query = query.Where("status = ?", status)
+	}
+
+	// Error handling
+	if err := query.Order("create_at").Find(&requests).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform access requests for API
+	if requests != nil {
+		apiRequests := make([]api.AccessRequest, len(requests), cap(requests))
+		for i, request := range requests {
+			apiRequests[i] = *dbAccessRequestToAPIAccessRequest(&request)
+		}
+		return apiRequests, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (r PostgresRepo) ReviewAccessRequest(request api.AccessRequest) (*api.AccessRequest, error) {
+	review := map[string]interface{}{
+		"status":   request.Status,
+		"reviewer": request.Reviewer,
+	}
+	if request.ReviewAt != nil {
+		review["review_at"] = request.ReviewAt.UTC().UnixNano()
+	}
+	if request.ExpireAt != nil {
+		review["expire_at"] = request.ExpireAt.UTC().UnixNano()
+	}
+
+	transaction := r.Dbmap.Begin()
+
+	// Review access request only if it's still pending
+	query := transaction.Model(&AccessRequest{}).Where("id like ? AND status = ?", request.ID, api.ACCESS_REQUEST_STATUS_PENDING).
+		Updates(review)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if access request was reviewed meanwhile
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.ACCESS_REQUEST_NOT_FOUND,
+			Message: fmt.Sprintf("Pending access request with id %v not found", request.ID),
+		}
+	}
+
+	// Add requester to group until access expires only when this review approved the request
+	if request.Status == api.ACCESS_REQUEST_STATUS_APPROVED {
+		if err := removeExpiredMember(transaction, request.UserID, request.GroupID); err != nil {
+			transaction.Rollback()
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		relation := &GroupUserRelation{
+			UserID:   request.UserID,
+			GroupID:  request.GroupID,
+			ExpireAt: apiOptionalTimeToDBTime(request.ExpireAt),
+		}
+		if err := transaction.Create(relation).Error; err != nil {
+			transaction.Rollback()
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return r.GetAccessRequestByID(request.ID)
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an access request retrieved from db into an access request for API
+func dbAccessRequestToAPIAccessRequest(requestdb *AccessRequest) *api.AccessRequest {
+	return &api.AccessRequest{
+		ID:            requestdb.ID,
+		UserID:        requestdb.UserID,
+		GroupID:       requestdb.GroupID,
+		Requester:     requestdb.Requester,
+		Org:           requestdb.Org,
+		Group:         requestdb.GroupName,
+		Justification: requestdb.Justification,
+		Duration:      requestdb.Duration,
+		Status:        requestdb.Status,
+		Reviewer:      requestdb.Reviewer,
+		CreateAt:      time.Unix(0, requestdb.CreateAt).UTC(),
+		ReviewAt:      dbOptionalTimeToAPITime(requestdb.ReviewAt),
+		ExpireAt:      dbOptionalTimeToAPITime(requestdb.ExpireAt),
+	}
+}
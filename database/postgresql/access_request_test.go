@@ -0,0 +1,475 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousRequest *api.AccessRequest
+		// Postgres Repo Args
+		requestToCreate *api.AccessRequest
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			requestToCreate: &api.AccessRequest{
+				ID:            "RequestID",
+				UserID:        "UserID",
+				GroupID:       "GroupID",
+				Requester:     "Requester",
+				Org:           "Org",
+				Group:         "Group",
+				Justification: "Justification",
+				Duration:      3600,
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+			expectedResponse: &api.AccessRequest{
+				ID:            "RequestID",
+				UserID:        "UserID",
+				GroupID:       "GroupID",
+				Requester:     "Requester",
+				Org:           "Org",
+				Group:         "Group",
+				Justification: "Justification",
+				Duration:      3600,
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+		},
+		"ErrorCaseAccessRequestAlreadyExist": {
+			previousRequest: &api.AccessRequest{
+				ID:        "RequestID",
+				UserID:    "UserID",
+				GroupID:   "GroupID",
+				Requester: "Requester",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  now,
+			},
+			requestToCreate: &api.AccessRequest{
+				ID:        "RequestID",
+				UserID:    "UserID",
+				GroupID:   "GroupID",
+				Requester: "Requester",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"access_requests_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousRequest != nil {
+			if err := insertAccessRequest(*test.previousRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store access request
+		receivedRequest, err := repoDB.AddAccessRequest(*test.requestToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			requestNumber, err := getAccessRequestsCountFiltered(test.requestToCreate.ID, api.ACCESS_REQUEST_STATUS_PENDING)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting access requests: %v", n, err)
+				continue
+			}
+			if requestNumber != 1 {
+				t.Errorf("Test %v failed. Received different access request number: %v", n, requestNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAccessRequestByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousRequest *api.AccessRequest
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousRequest: &api.AccessRequest{
+				ID:        "RequestID",
+				UserID:    "UserID",
+				GroupID:   "GroupID",
+				Requester: "Requester",
+				Status:    api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:  "Reviewer",
+				CreateAt:  now,
+				ReviewAt:  &now,
+				ExpireAt:  &now,
+			},
+			id: "RequestID",
+			expectedResponse: &api.AccessRequest{
+				ID:        "RequestID",
+				UserID:    "UserID",
+				GroupID:   "GroupID",
+				Requester: "Requester",
+				Status:    api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:  "Reviewer",
+				CreateAt:  now,
+				ReviewAt:  &now,
+				ExpireAt:  &now,
+			},
+		},
+		"ErrorCaseAccessRequestNotFound": {
+			id: "RequestID",
+			expectedError: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id RequestID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousRequest != nil {
+			if err := insertAccessRequest(*test.previousRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get access request
+		receivedRequest, err := repoDB.GetAccessRequestByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAccessRequestsByGroupID(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Minute)
+	testcases := map[string]struct {
+		// Previous data
+		previousRequests []api.AccessRequest
+		// Postgres Repo Args
+		groupID string
+		status  string
+		// Expected result
+		expectedResponse []api.AccessRequest
+	}{
+		"OkCase": {
+			previousRequests: []api.AccessRequest{
+				{
+					ID:       "RequestID2",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+					CreateAt: later,
+				},
+				{
+					ID:       "RequestID1",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+				{
+					ID:       "RequestID3",
+					GroupID:  "OtherGroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+			},
+			groupID: "GroupID",
+			expectedResponse: []api.AccessRequest{
+				{
+					ID:       "RequestID1",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+				{
+					ID:       "RequestID2",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+					CreateAt: later,
+				},
+			},
+		},
+		"OkCaseStatusFilter": {
+			previousRequests: []api.AccessRequest{
+				{
+					ID:       "RequestID1",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+				{
+					ID:       "RequestID2",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+					CreateAt: later,
+				},
+			},
+			groupID: "GroupID",
+			status:  api.ACCESS_REQUEST_STATUS_REJECTED,
+			expectedResponse: []api.AccessRequest{
+				{
+					ID:       "RequestID2",
+					GroupID:  "GroupID",
+					Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+					CreateAt: later,
+				},
+			},
+		},
+		"OkCaseNoRequests": {
+			groupID:          "GroupID",
+			expectedResponse: []api.AccessRequest{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		for _, request := range test.previousRequests {
+			if err := insertAccessRequest(request); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get access requests
+		receivedRequests, err := repoDB.GetAccessRequestsByGroupID(test.groupID, test.status)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedRequests, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_ReviewAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousRequest *api.AccessRequest
+		// Postgres Repo Args
+		review *api.AccessRequest
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedMembers  int
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousRequest: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt: now,
+			},
+			review: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer: "Reviewer",
+				ReviewAt: &now,
+				ExpireAt: &now,
+			},
+			expectedResponse: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer: "Reviewer",
+				CreateAt: now,
+				ReviewAt: &now,
+				ExpireAt: &now,
+			},
+			expectedMembers: 1,
+		},
+		"OkCaseRejected": {
+			previousRequest: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt: now,
+			},
+			review: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "Reviewer",
+				ReviewAt: &now,
+			},
+			expectedResponse: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "Reviewer",
+				CreateAt: now,
+				ReviewAt: &now,
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			previousRequest: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "Reviewer",
+				CreateAt: now,
+				ReviewAt: &now,
+			},
+			review: &api.AccessRequest{
+				ID:       "RequestID",
+				UserID:   "UserID",
+				GroupID:  "GroupID",
+				Status:   api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer: "Reviewer",
+				ReviewAt: &now,
+				ExpireAt: &now,
+			},
+			expectedError: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Pending access request with id RequestID not found",
+			},
+		},
+		"ErrorCaseAccessRequestNotFound": {
+			review: &api.AccessRequest{
+				ID:       "RequestID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "Reviewer",
+				ReviewAt: &now,
+			},
+			expectedError: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Pending access request with id RequestID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request and group user relation database
+		cleanAccessRequestTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		if test.previousRequest != nil {
+			if err := insertAccessRequest(*test.previousRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to review access request
+		receivedRequest, err := repoDB.ReviewAccessRequest(*test.review)
+
+		// Check that requester is only a member when request was approved by this review
+		members, relErr := getGroupUserRelations("GroupID", "UserID")
+		if relErr != nil {
+			t.Errorf("Test %v failed. Unexpected error counting group user relations: %v", n, relErr)
+			continue
+		}
+		if members != test.expectedMembers {
+			t.Errorf("Test %v failed. Received different group user relation number: %v", n, members)
+			continue
+		}
+
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			requestNumber, err := getAccessRequestsCountFiltered(test.review.ID, api.ACCESS_REQUEST_STATUS_PENDING)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting access requests: %v", n, err)
+				continue
+			}
+			if requestNumber != 0 {
+				t.Errorf("Test %v failed. Received different pending access request number: %v", n, requestNumber)
+				continue
+			}
+		}
+	}
+}
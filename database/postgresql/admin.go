@@ -0,0 +1,165 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ADMIN REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) SetAdminCredentials(userID string, hash string) error {
+	now := time.Now().UTC().UnixNano()
+	transaction := r.Dbmap.Begin()
+
+	// Replace previous credentials clearing failed attempts
+	query := transaction.Model(&Admin{}).Where("user_id like ?", userID).Updates(map[string]interface{}{
+		"hash":            hash,
+		"failed_attempts": 0,
+		"locked_until":    0,
+		"update_at":       now,
+	})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Create credentials if user didn't have them
+	if query.RowsAffected == 0 {
+		adminDB := &Admin{
+			UserID:   userID,
+			Hash:     hash,
+			CreateAt: now,
+			UpdateAt: now,
+		}
+		if err := transaction.Create(adminDB).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r PostgresRepo) GetAdminCredentials(userID string) (*api.UserCredentials, error) {
+	admin := &Admin{}
+	query := r.Dbmap.Where("user_id like ?", userID).First(admin)
+
+	// Check if admin credentials exist
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ADMIN_NOT_FOUND,
+			Message: fmt.Sprintf("Admin credentials of user with id %v not found", userID),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return &api.UserCredentials{
+		UserID:         admin.UserID,
+		Hash:           admin.Hash,
+		FailedAttempts: admin.FailedAttempts,
+		LockedUntil:    dbOptionalTimeToAPITime(admin.LockedUntil),
+	}, nil
+}
+
+func (r PostgresRepo) IncrementAdminCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*api.UserCredentials, error) {
+	credentials, err := incrementFailedAttempts(r, Admin{}.TableName(), userID, maxFailedAttempts, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		return nil, &database.Error{
+			Code:    database.ADMIN_NOT_FOUND,
+			Message: fmt.Sprintf("Admin credentials of user with id %v not found", userID),
+		}
+	}
+	return credentials, nil
+}
+
+func (r PostgresRepo) UpdateAdminCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error {
+	update := map[string]interface{}{
+		"failed_attempts": failedAttempts,
+		"locked_until":    apiOptionalTimeToDBTime(lockedUntil),
+	}
+
+	// Update credentials
+	query := r.Dbmap.Model(&Admin{}).Where("user_id like ?", userID).Updates(update)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if admin credentials existed
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.ADMIN_NOT_FOUND,
+			Message: fmt.Sprintf("Admin credentials of user with id %v not found", userID),
+		}
+	}
+
+	return nil
+}
+
+func (r PostgresRepo) GetAdmins() ([]api.User, error) {
+	users := []User{}
+	query := r.Dbmap.Where("delete_at = 0 AND id IN (SELECT user_id FROM " + Admin{}.TableName() + ")")
+
+	// Error handling
+	if err := query.Order("external_id").Find(&users).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform users for API
+	apiusers := make([]api.User, len(users), cap(users))
+	for i, u := range users {
+		apiusers[i] = *dbUserToAPIUser(&u)
+	}
+
+	return apiusers, nil
+}
+
+func (r PostgresRepo) RemoveAdminCredentials(userID string) error {
+	// Delete admin credentials
+	query := r.Dbmap.Where("user_id like ?", userID).Delete(&Admin{})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if admin credentials existed
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.ADMIN_NOT_FOUND,
+			Message: fmt.Sprintf("Admin credentials of user with id %v not found", userID),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,430 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_SetAdminCredentials(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousHash string
+		// Postgres Repo Args
+		userID string
+		hash   string
+	}{
+		"OkCase": {
+			userID: "UserID",
+			hash:   "hash",
+		},
+		"OkCaseReplaceCredentials": {
+			previousHash: "oldHash",
+			userID:       "UserID",
+			hash:         "hash",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean admin database
+		cleanAdminTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetAdminCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store admin credentials
+		if err := repoDB.SetAdminCredentials(test.userID, test.hash); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		adminNumber, err := getAdminsCountFiltered(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting admins: %v", n, err)
+			continue
+		}
+		if adminNumber != 1 {
+			t.Errorf("Test %v failed. Received different admin number: %v", n, adminNumber)
+			continue
+		}
+		credentials, err := repoDB.GetAdminCredentials(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+			continue
+		}
+		if credentials.Hash != test.hash {
+			t.Errorf("Test %v failed. Received different hash: %v", n, credentials.Hash)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetAdminCredentials(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousHash string
+		// Postgres Repo Args
+		userID string
+		// Expected result
+		expectedResponse *api.UserCredentials
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousHash: "hash",
+			userID:       "UserID",
+			expectedResponse: &api.UserCredentials{
+				UserID: "UserID",
+				Hash:   "hash",
+			},
+		},
+		"ErrorCaseAdminNotExist": {
+			userID: "UserID",
+			expectedError: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean admin database
+		cleanAdminTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetAdminCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get admin credentials
+		credentials, err := repoDB.GetAdminCredentials(test.userID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(credentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_IncrementAdminCredentialsAttempts(t *testing.T) {
+	lockedUntil := time.Unix(0, time.Now().UTC().Add(time.Hour).UnixNano()).UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousHash           string
+		previousFailedAttempts int
+		// Postgres Repo Args
+		userID            string
+		maxFailedAttempts int
+		// Expected result
+		expectedResponse *api.UserCredentials
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousHash:      "hash",
+			userID:            "UserID",
+			maxFailedAttempts: 3,
+			expectedResponse: &api.UserCredentials{
+				UserID:         "UserID",
+				Hash:           "hash",
+				FailedAttempts: 1,
+			},
+		},
+		"OkCaseLockedOut": {
+			previousHash:           "hash",
+			previousFailedAttempts: 2,
+			userID:                 "UserID",
+			maxFailedAttempts:      3,
+			expectedResponse: &api.UserCredentials{
+				UserID:      "UserID",
+				Hash:        "hash",
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"ErrorCaseAdminNotExist": {
+			userID:            "UserID",
+			maxFailedAttempts: 3,
+			expectedError: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean admin database
+		cleanAdminTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetAdminCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+			if err := repoDB.UpdateAdminCredentialsAttempts(test.userID, test.previousFailedAttempts, nil); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to increment failed attempts
+		credentials, err := repoDB.IncrementAdminCredentialsAttempts(test.userID, test.maxFailedAttempts, lockedUntil)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(credentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			storedCredentials, err := repoDB.GetAdminCredentials(test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(storedCredentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different stored credentials (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateAdminCredentialsAttempts(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousHash string
+		// Postgres Repo Args
+		userID         string
+		failedAttempts int
+		// Expected result
+		expectedError *database.Error
+	}{
+		"OkCase": {
+			previousHash:   "hash",
+			userID:         "UserID",
+			failedAttempts: 3,
+		},
+		"ErrorCaseAdminNotExist": {
+			userID: "UserID",
+			expectedError: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean admin database
+		cleanAdminTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetAdminCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to update failed attempts
+		err := repoDB.UpdateAdminCredentialsAttempts(test.userID, test.failedAttempts, nil)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			credentials, err := repoDB.GetAdminCredentials(test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+				continue
+			}
+			if credentials.FailedAttempts != test.failedAttempts {
+				t.Errorf("Test %v failed. Received different failed attempts: %v", n, credentials.FailedAttempts)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAdmins(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUsers map[string]string
+		deletedUsers  []string
+		adminUsers    []string
+		// Expected result
+		expectedResponse []string
+	}{
+		"OkCase": {
+			previousUsers: map[string]string{
+				"UserID1": "user1",
+				"UserID2": "user2",
+				"UserID3": "user3",
+			},
+			deletedUsers:     []string{"UserID3"},
+			adminUsers:       []string{"UserID2", "UserID1", "UserID3"},
+			expectedResponse: []string{"user1", "user2"},
+		},
+		"OkCaseWithoutAdmins": {
+			previousUsers: map[string]string{
+				"UserID1": "user1",
+			},
+			expectedResponse: []string{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user and admin database
+		cleanUserTable()
+		cleanAdminTable()
+
+		// Insert previous data
+		for id, externalID := range test.previousUsers {
+			if err := insertUser(id, externalID, "/path/", now.UnixNano(), "urn:iws:iam::user/path/"+externalID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.deletedUsers {
+			if err := markAsDeleted(User{}.TableName(), id, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error deleting previous users: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.adminUsers {
+			if err := repoDB.SetAdminCredentials(id, "hash"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous admins: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get admins
+		users, err := repoDB.GetAdmins()
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		externalIDs := []string{}
+		for _, user := range users {
+			externalIDs = append(externalIDs, user.ExternalID)
+		}
+		// Check response
+		if diff := pretty.Compare(externalIDs, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveAdminCredentials(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousHash string
+		// Postgres Repo Args
+		userID string
+		// Expected result
+		expectedError *database.Error
+	}{
+		"OkCase": {
+			previousHash: "hash",
+			userID:       "UserID",
+		},
+		"ErrorCaseAdminNotExist": {
+			userID: "UserID",
+			expectedError: &database.Error{
+				Code:    database.ADMIN_NOT_FOUND,
+				Message: "Admin credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean admin database
+		cleanAdminTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetAdminCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove admin credentials
+		err := repoDB.RemoveAdminCredentials(test.userID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check database
+			adminNumber, err := getAdminsCountFiltered("")
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting admins: %v", n, err)
+				continue
+			}
+			if adminNumber != 0 {
+				t.Errorf("Test %v failed. Received different admin number: %v", n, adminNumber)
+				continue
+			}
+		}
+	}
+}
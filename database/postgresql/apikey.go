@@ -0,0 +1,166 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// API KEY REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddApiKey(key api.ApiKey, hash string) (*api.ApiKey, error) {
+
+	// Create API key model
+	keyDB := &ApiKey{
+		ID:       key.ID,
+		UserID:   key.UserID,
+		Name:     key.Name,
+		Prefix:   key.Prefix,
+		Hash:     hash,
+		ExpireAt: apiOptionalTimeToDBTime(key.ExpireAt),
+		CreateAt: key.CreateAt.UnixNano(),
+		UpdateAt: key.UpdateAt.UnixNano(),
+	}
+
+	// Store API key
+	err := r.Dbmap.Create(keyDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbApiKeyToAPIApiKey(keyDB), nil
+}
+
+func (r PostgresRepo) GetApiKeysByUserID(userID string) ([]api.ApiKey, error) {
+	keys := []ApiKey{}
+
+	// Error handling
+	if err := r.Dbmap.Where("user_id like ?", userID).Order("create_at").Find(&keys).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform API keys for API
+	if keys != nil {
+		apiKeys := make([]api.ApiKey, len(keys), cap(keys))
+		for i, key := range keys {
+			apiKeys[i] = *dbApiKeyToAPIApiKey(&key)
+		}
+		return apiKeys, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (r PostgresRepo) GetApiKeyByID(id string) (*api.ApiKey, error) {
+	key := &ApiKey{}
+	query := r.Dbmap.Where("id like ?", id).First(key)
+
+	// Check if API key exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.API_KEY_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbApiKeyToAPIApiKey(key), nil
+}
+
+func (r PostgresRepo) GetApiKeyByHash(hash string) (*api.ApiKey, error) {
+	key := &ApiKey{}
+	query := r.Dbmap.Where("hash = ?", hash).First(key)
+
+	// Check if API key exists, hash isn't included in the message
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.API_KEY_NOT_FOUND,
+			Message: "API key not found",
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbApiKeyToAPIApiKey(key), nil
+}
+
+func (r PostgresRepo) UpdateApiKey(key api.ApiKey, hash string) (*api.ApiKey, error) {
+	update := map[string]interface{}{
+		"prefix":    key.Prefix,
+		"hash":      hash,
+		"expire_at": apiOptionalTimeToDBTime(key.ExpireAt),
+		"update_at": key.UpdateAt.UTC().UnixNano(),
+	}
+
+	// Update API key
+	query := r.Dbmap.Model(&ApiKey{}).Where("id like ?", key.ID).Updates(update)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if API key was revoked meanwhile
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.API_KEY_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found", key.ID),
+		}
+	}
+
+	return r.GetApiKeyByID(key.ID)
+}
+
+func (r PostgresRepo) RemoveApiKey(id string) error {
+	// Delete API key
+	if err := r.Dbmap.Where("id like ?", id).Delete(&ApiKey{}).Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an API key retrieved from db into an API key for API
+func dbApiKeyToAPIApiKey(keydb *ApiKey) *api.ApiKey {
+	return &api.ApiKey{
+		ID:       keydb.ID,
+		Name:     keydb.Name,
+		Prefix:   keydb.Prefix,
+		UserID:   keydb.UserID,
+		ExpireAt: dbOptionalTimeToAPITime(keydb.ExpireAt),
+		CreateAt: time.Unix(0, keydb.CreateAt).UTC(),
+		UpdateAt: time.Unix(0, keydb.UpdateAt).UTC(),
+	}
+}
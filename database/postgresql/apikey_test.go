@@ -0,0 +1,417 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	expireAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousApiKey *api.ApiKey
+		// Postgres Repo Args
+		keyToCreate *api.ApiKey
+		hash        string
+		// Expected result
+		expectedResponse *api.ApiKey
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			keyToCreate: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				ExpireAt: &expireAt,
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			hash: "hash",
+			expectedResponse: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				ExpireAt: &expireAt,
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+		"ErrorCaseHashAlreadyExist": {
+			previousApiKey: &api.ApiKey{
+				ID:       "OtherKeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			keyToCreate: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			hash: "hash",
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"api_keys_hash_key\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean API key database
+		cleanApiKeyTable()
+
+		// Insert previous data
+		if test.previousApiKey != nil {
+			if _, err := repoDB.AddApiKey(*test.previousApiKey, test.hash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store API key
+		receivedApiKey, err := repoDB.AddApiKey(*test.keyToCreate, test.hash)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedApiKey, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			keyNumber, err := getApiKeysCountFiltered(test.keyToCreate.UserID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting API keys: %v", n, err)
+				continue
+			}
+			if keyNumber != 1 {
+				t.Errorf("Test %v failed. Received different API key number: %v", n, keyNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetApiKeysByUserID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousApiKeys map[string]api.ApiKey
+		// Postgres Repo Args
+		userID string
+		// Expected result
+		expectedResponse []api.ApiKey
+	}{
+		"OkCase": {
+			previousApiKeys: map[string]api.ApiKey{
+				"hash1": {
+					ID:       "KeyID1",
+					Name:     "deploy",
+					Prefix:   "fk_11111111",
+					UserID:   "UserID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				"hash2": {
+					ID:       "KeyID2",
+					Name:     "deploy",
+					Prefix:   "fk_22222222",
+					UserID:   "OtherUserID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			userID: "UserID",
+			expectedResponse: []api.ApiKey{
+				{
+					ID:       "KeyID1",
+					Name:     "deploy",
+					Prefix:   "fk_11111111",
+					UserID:   "UserID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+		},
+		"OkCaseWithoutKeys": {
+			userID:           "UserID",
+			expectedResponse: []api.ApiKey{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean API key database
+		cleanApiKeyTable()
+
+		// Insert previous data
+		for hash, key := range test.previousApiKeys {
+			if _, err := repoDB.AddApiKey(key, hash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get API keys
+		receivedApiKeys, err := repoDB.GetApiKeysByUserID(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedApiKeys, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetApiKeyByHash(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousApiKey *api.ApiKey
+		// Postgres Repo Args
+		hash string
+		// Expected result
+		expectedResponse *api.ApiKey
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousApiKey: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			hash: "hash",
+			expectedResponse: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+		"ErrorCaseApiKeyNotExist": {
+			hash: "hash",
+			expectedError: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "API key not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean API key database
+		cleanApiKeyTable()
+
+		// Insert previous data
+		if test.previousApiKey != nil {
+			if _, err := repoDB.AddApiKey(*test.previousApiKey, test.hash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get API key
+		receivedApiKey, err := repoDB.GetApiKeyByHash(test.hash)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedApiKey, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousApiKey *api.ApiKey
+		// Postgres Repo Args
+		keyToUpdate *api.ApiKey
+		hash        string
+		// Expected result
+		expectedResponse *api.ApiKey
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousApiKey: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			keyToUpdate: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_87654321",
+				UserID:   "UserID",
+				ExpireAt: &later,
+				CreateAt: now,
+				UpdateAt: later,
+			},
+			hash: "newHash",
+			expectedResponse: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_87654321",
+				UserID:   "UserID",
+				ExpireAt: &later,
+				CreateAt: now,
+				UpdateAt: later,
+			},
+		},
+		"ErrorCaseApiKeyNotExist": {
+			keyToUpdate: &api.ApiKey{
+				ID:       "KeyID",
+				UpdateAt: later,
+			},
+			hash: "newHash",
+			expectedError: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "API key with id KeyID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean API key database
+		cleanApiKeyTable()
+
+		// Insert previous data
+		if test.previousApiKey != nil {
+			if _, err := repoDB.AddApiKey(*test.previousApiKey, "hash"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to update API key
+		receivedApiKey, err := repoDB.UpdateApiKey(*test.keyToUpdate, test.hash)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedApiKey, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check that previous secret key doesn't work anymore
+			if _, err := repoDB.GetApiKeyByHash("hash"); err == nil {
+				t.Errorf("Test %v failed. Previous hash is still stored", n)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveApiKey(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousApiKey *api.ApiKey
+		// Postgres Repo Args
+		id string
+	}{
+		"OkCase": {
+			previousApiKey: &api.ApiKey{
+				ID:       "KeyID",
+				Name:     "deploy",
+				Prefix:   "fk_12345678",
+				UserID:   "UserID",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			id: "KeyID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean API key database
+		cleanApiKeyTable()
+
+		// Insert previous data
+		if test.previousApiKey != nil {
+			if _, err := repoDB.AddApiKey(*test.previousApiKey, "hash"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove API key
+		if err := repoDB.RemoveApiKey(test.id); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		keyNumber, err := getApiKeysCountFiltered("")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting API keys: %v", n, err)
+			continue
+		}
+		if keyNumber != 0 {
+			t.Errorf("Test %v failed. Received different API key number: %v", n, keyNumber)
+			continue
+		}
+	}
+}
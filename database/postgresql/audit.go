@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// AUDIT REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddAuditEvent(event api.AuditEvent) (*api.AuditEvent, error) {
+
+	// Create audit event model
+	eventDB := &AuditEvent{
+		ID:         event.ID,
+		RequestID:  event.RequestID,
+		Identifier: event.User,
+		Action:     event.Action,
+		Urn:        event.Urn,
+		Request:    string(event.Request),
+		Outcome:    event.Outcome,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		CreateAt:   event.CreateAt.UnixNano(),
+	}
+	if event.Error != nil {
+		eventDB.ErrorCode = event.Error.Code
+		eventDB.ErrorMessage = event.Error.Message
+	}
+
+	// Store audit event
+	err := r.Dbmap.Create(eventDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAuditEventToAPIAuditEvent(eventDB), nil
+}
+
+func (r PostgresRepo) GetAuditEventsFiltered(filter api.AuditFilter) ([]api.AuditEvent, error) {
+	events := []AuditEvent{}
+	query := r.Dbmap
+	if len(filter.User) > 0 {
+		query = query.Where("identifier = ?", filter.User)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if len(filter.UrnPrefix) > 0 {
+		query = query.Where("urn like ?", filter.UrnPrefix+"%")
+	}
+	if filter.From != nil {
+		query = query.Where("create_at >= ?", filter.From.UnixNano())
+	}
+	if filter.To != nil {
+		query = query.Where("create_at <= ?", filter.To.UnixNano())
+	}
+
+	// Error handling
+	if err := query.Order("create_at").Find(&events).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform audit events for API
+	if events != nil {
+		apiEvents := make([]api.AuditEvent, len(events), cap(events))
+		for i, event := range events {
+			apiEvents[i] = *dbAuditEventToAPIAuditEvent(&event)
+		}
+		return apiEvents, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an audit event retrieved from db into an audit event for API
+func dbAuditEventToAPIAuditEvent(eventdb *AuditEvent) *api.AuditEvent {
+	event := &api.AuditEvent{
+		ID:        eventdb.ID,
+		RequestID: eventdb.RequestID,
+		User:      eventdb.Identifier,
+		Action:    eventdb.Action,
+		Urn:       eventdb.Urn,
+		Request:   dbJSONToAPIJSON(eventdb.Request),
+		Outcome:   eventdb.Outcome,
+		Before:    dbJSONToAPIJSON(eventdb.Before),
+		After:     dbJSONToAPIJSON(eventdb.After),
+		CreateAt:  time.Unix(0, eventdb.CreateAt).UTC(),
+	}
+	if len(eventdb.ErrorCode) > 0 {
+		event.Error = &api.Error{
+			Code:    eventdb.ErrorCode,
+			Message: eventdb.ErrorMessage,
+		}
+	}
+	return event
+}
+
+// Transform JSON text retrieved from db into raw JSON for API, empty text is nil
+func dbJSONToAPIJSON(text string) json.RawMessage {
+	if len(text) == 0 {
+		return nil
+	}
+	return json.RawMessage(text)
+}
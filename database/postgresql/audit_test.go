@@ -0,0 +1,245 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddAuditEvent(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousEvent *api.AuditEvent
+		// Postgres Repo Args
+		eventToCreate *api.AuditEvent
+		// Expected result
+		expectedResponse *api.AuditEvent
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			eventToCreate: &api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				User:      "User",
+				Action:    api.USER_ACTION_UPDATE_USER,
+				Urn:       "urn:iws:iam::user/path/User",
+				Request:   json.RawMessage(`{"path":"/path/"}`),
+				Outcome:   api.AUDIT_OUTCOME_SUCCESS,
+				Before:    json.RawMessage(`{"path":"/"}`),
+				After:     json.RawMessage(`{"path":"/path/"}`),
+				CreateAt:  now,
+			},
+			expectedResponse: &api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				User:      "User",
+				Action:    api.USER_ACTION_UPDATE_USER,
+				Urn:       "urn:iws:iam::user/path/User",
+				Request:   json.RawMessage(`{"path":"/path/"}`),
+				Outcome:   api.AUDIT_OUTCOME_SUCCESS,
+				Before:    json.RawMessage(`{"path":"/"}`),
+				After:     json.RawMessage(`{"path":"/path/"}`),
+				CreateAt:  now,
+			},
+		},
+		"OkCaseFailure": {
+			eventToCreate: &api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				User:      "User",
+				Action:    api.USER_ACTION_DELETE_USER,
+				Outcome:   api.AUDIT_OUTCOME_FAILURE,
+				Error: &api.Error{
+					Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+					Message: "User not found",
+				},
+				CreateAt: now,
+			},
+			expectedResponse: &api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				User:      "User",
+				Action:    api.USER_ACTION_DELETE_USER,
+				Outcome:   api.AUDIT_OUTCOME_FAILURE,
+				Error: &api.Error{
+					Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+					Message: "User not found",
+				},
+				CreateAt: now,
+			},
+		},
+		"ErrorCaseAuditEventAlreadyExist": {
+			previousEvent: &api.AuditEvent{
+				ID:       "EventID",
+				User:     "User",
+				Action:   api.USER_ACTION_DELETE_USER,
+				Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+				CreateAt: now,
+			},
+			eventToCreate: &api.AuditEvent{
+				ID:       "EventID",
+				User:     "User",
+				Action:   api.USER_ACTION_DELETE_USER,
+				Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+				CreateAt: now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"audit_events_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean audit event database
+		cleanAuditEventTable()
+
+		// Insert previous data
+		if test.previousEvent != nil {
+			if err := insertAuditEvent(*test.previousEvent); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store audit event
+		receivedEvent, err := repoDB.AddAuditEvent(*test.eventToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedEvent, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			eventNumber, err := getAuditEventsCountFiltered(test.eventToCreate.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting audit events: %v", n, err)
+				continue
+			}
+			if eventNumber != 1 {
+				t.Errorf("Test %v failed. Received different audit event number: %v", n, eventNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAuditEventsFiltered(t *testing.T) {
+	first := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	third := second.Add(time.Hour)
+	events := []api.AuditEvent{
+		{
+			ID:       "Event1",
+			User:     "User1",
+			Action:   api.GROUP_ACTION_CREATE_GROUP,
+			Urn:      "urn:iws:iam:org1:group/path/group1",
+			Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+			After:    json.RawMessage(`{"name":"group1"}`),
+			CreateAt: first,
+		},
+		{
+			ID:       "Event2",
+			User:     "User2",
+			Action:   api.GROUP_ACTION_CREATE_GROUP,
+			Urn:      "urn:iws:iam:org2:group/path/group2",
+			Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+			After:    json.RawMessage(`{"name":"group2"}`),
+			CreateAt: second,
+		},
+		{
+			ID:       "Event3",
+			User:     "User1",
+			Action:   api.GROUP_ACTION_DELETE_GROUP,
+			Urn:      "urn:iws:iam:org1:group/path/group1",
+			Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+			Before:   json.RawMessage(`{"name":"group1"}`),
+			CreateAt: third,
+		},
+	}
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		filter api.AuditFilter
+		// Expected result
+		expectedResponse []api.AuditEvent
+	}{
+		"OkCaseAll": {
+			expectedResponse: events,
+		},
+		"OkCaseUser": {
+			filter: api.AuditFilter{
+				User: "User1",
+			},
+			expectedResponse: []api.AuditEvent{events[0], events[2]},
+		},
+		"OkCaseAction": {
+			filter: api.AuditFilter{
+				Action: api.GROUP_ACTION_CREATE_GROUP,
+			},
+			expectedResponse: []api.AuditEvent{events[0], events[1]},
+		},
+		"OkCaseUrnPrefix": {
+			filter: api.AuditFilter{
+				UrnPrefix: "urn:iws:iam:org2:",
+			},
+			expectedResponse: []api.AuditEvent{events[1]},
+		},
+		"OkCaseTimeRange": {
+			filter: api.AuditFilter{
+				From: &second,
+				To:   &third,
+			},
+			expectedResponse: []api.AuditEvent{events[1], events[2]},
+		},
+		"OkCaseNoResults": {
+			filter: api.AuditFilter{
+				User: "User3",
+			},
+			expectedResponse: []api.AuditEvent{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean audit event database
+		cleanAuditEventTable()
+
+		// Insert previous data
+		for _, event := range events {
+			if err := insertAuditEvent(event); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get audit events
+		receivedEvents, err := repoDB.GetAuditEventsFiltered(test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedEvents, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
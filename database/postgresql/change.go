@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// CHANGE REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddChange(change api.Change) (*api.Change, error) {
+	// Create change model
+	changeDB := &Change{
+		Type:     change.Type,
+		Urn:      change.Urn,
+		Before:   string(change.Before),
+		After:    string(change.After),
+		CreateAt: change.CreateAt.UnixNano(),
+	}
+
+	// Serialize appends, so changes are committed in the order of their sequence numbers and
+	// clients reading since a sequence number don't skip uncommitted ones
+	transaction := r.Dbmap.Begin()
+	if err := transaction.Exec("LOCK TABLE " + Change{}.TableName() + " IN EXCLUSIVE MODE").Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Store change
+	if err := transaction.Create(changeDB).Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return dbChangeToAPIChange(changeDB), nil
+}
+
+func (r PostgresRepo) GetChanges(since int64, limit int) ([]api.Change, error) {
+	changes := []Change{}
+
+	// Error handling
+	if err := r.Dbmap.Where("seq > ?", since).Order("seq").Limit(limit).Find(&changes).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform changes for API
+	if changes != nil {
+		apiChanges := make([]api.Change, len(changes), cap(changes))
+		for i, change := range changes {
+			apiChanges[i] = *dbChangeToAPIChange(&change)
+		}
+		return apiChanges, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform a change retrieved from db into a change for API
+func dbChangeToAPIChange(changedb *Change) *api.Change {
+	return &api.Change{
+		Seq:      changedb.Seq,
+		Type:     changedb.Type,
+		Urn:      changedb.Urn,
+		Before:   dbJSONToAPIJSON(changedb.Before),
+		After:    dbJSONToAPIJSON(changedb.After),
+		CreateAt: time.Unix(0, changedb.CreateAt).UTC(),
+	}
+}
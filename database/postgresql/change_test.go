@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestPostgresRepo_Changes(t *testing.T) {
+	now := time.Now().UTC()
+	changesToCreate := []api.Change{
+		{
+			Type:     api.USER_ACTION_CREATE_USER,
+			Urn:      api.CreateUrn("", api.RESOURCE_USER, "/path/", "123"),
+			After:    json.RawMessage(`{"externalId":"123"}`),
+			CreateAt: now,
+		},
+		{
+			Type:     api.USER_ACTION_UPDATE_USER,
+			Urn:      api.CreateUrn("", api.RESOURCE_USER, "/path2/", "123"),
+			Before:   json.RawMessage(`{"externalId":"123"}`),
+			After:    json.RawMessage(`{"externalId":"123","path":"/path2/"}`),
+			CreateAt: now,
+		},
+		{
+			Type:     api.USER_ACTION_DELETE_USER,
+			Urn:      api.CreateUrn("", api.RESOURCE_USER, "/path2/", "123"),
+			Before:   json.RawMessage(`{"externalId":"123","path":"/path2/"}`),
+			CreateAt: now,
+		},
+	}
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		since int
+		limit int
+		// Expected result, indexes of created changes
+		expectedChanges []int
+	}{
+		"OkCaseAll": {
+			since:           -1,
+			limit:           10,
+			expectedChanges: []int{0, 1, 2},
+		},
+		"OkCaseSince": {
+			since:           0,
+			limit:           10,
+			expectedChanges: []int{1, 2},
+		},
+		"OkCaseLimit": {
+			since:           -1,
+			limit:           2,
+			expectedChanges: []int{0, 1},
+		},
+		"OkCaseNoChanges": {
+			since:           2,
+			limit:           10,
+			expectedChanges: []int{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean change database
+		cleanChangeTable()
+
+		// Insert previous data, sequence numbers must increase
+		createdChanges := []api.Change{}
+		for _, change := range changesToCreate {
+			created, err := repoDB.AddChange(change)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				break
+			}
+			if len(createdChanges) > 0 && created.Seq <= createdChanges[len(createdChanges)-1].Seq {
+				t.Errorf("Test %v failed. Sequence number %v isn't greater than previous one", n, created.Seq)
+				break
+			}
+			createdChanges = append(createdChanges, *created)
+		}
+		if len(createdChanges) != len(changesToCreate) {
+			continue
+		}
+
+		// Call to repository to get changes
+		since := int64(0)
+		if test.since >= 0 {
+			since = createdChanges[test.since].Seq
+		}
+		receivedChanges, err := repoDB.GetChanges(since, test.limit)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check response
+		expectedChanges := []api.Change{}
+		for _, i := range test.expectedChanges {
+			expectedChanges = append(expectedChanges, createdChanges[i])
+		}
+		if diff := pretty.Compare(receivedChanges, expectedChanges); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"encoding/json"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// DECISION SINK IMPLEMENTATION
+
+func (r PostgresRepo) AddAuthzDecision(decision api.AuthzDecision) error {
+	requested, err := json.Marshal(decision.Requested)
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	allowed, err := json.Marshal(decision.Allowed)
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	statements, err := json.Marshal(decision.Statements)
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Create authorization decision model
+	decisionDB := &AuthzDecision{
+		ID:          decision.ID,
+		RequestID:   decision.RequestID,
+		Identifier:  decision.User,
+		Admin:       decision.Admin,
+		Action:      decision.Action,
+		ResourceUrn: decision.ResourceUrn,
+		Requested:   string(requested),
+		Allowed:     string(allowed),
+		Statements:  string(statements),
+		Latency:     decision.Latency.Nanoseconds(),
+		CreateAt:    decision.CreateAt.UnixNano(),
+	}
+
+	// Store authorization decision
+	if err := r.Dbmap.Create(decisionDB).Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddAuthzDecision(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDecision *api.AuthzDecision
+		// Postgres Repo Args
+		decisionToCreate *api.AuthzDecision
+		// Expected result
+		expectedRequested string
+		expectedAllowed   string
+		expectedError     *database.Error
+	}{
+		"OkCase": {
+			decisionToCreate: &api.AuthzDecision{
+				ID:          "DecisionID",
+				RequestID:   "RequestID",
+				User:        "User",
+				Action:      api.GROUP_ACTION_GET_GROUP,
+				ResourceUrn: "urn:iws:iam:org1:group/*",
+				Requested:   []string{"urn:iws:iam:org1:group/group1", "urn:iws:iam:org1:group/group2"},
+				Allowed:     []string{"urn:iws:iam:org1:group/group1"},
+				Statements: []api.Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{api.GROUP_ACTION_GET_GROUP},
+						Resources: []string{"urn:iws:iam:org1:group/group1"},
+					},
+				},
+				Latency:  time.Millisecond,
+				CreateAt: now,
+			},
+			expectedRequested: `["urn:iws:iam:org1:group/group1","urn:iws:iam:org1:group/group2"]`,
+			expectedAllowed:   `["urn:iws:iam:org1:group/group1"]`,
+		},
+		"ErrorCaseAuthzDecisionAlreadyExist": {
+			previousDecision: &api.AuthzDecision{
+				ID:       "DecisionID",
+				User:     "User",
+				Action:   api.GROUP_ACTION_GET_GROUP,
+				CreateAt: now,
+			},
+			decisionToCreate: &api.AuthzDecision{
+				ID:       "DecisionID",
+				User:     "User",
+				Action:   api.GROUP_ACTION_GET_GROUP,
+				CreateAt: now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"authz_decisions_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean authz decision database
+		cleanAuthzDecisionTable()
+
+		// Insert previous data
+		if test.previousDecision != nil {
+			if err := repoDB.AddAuthzDecision(*test.previousDecision); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store authz decision
+		err := repoDB.AddAuthzDecision(*test.decisionToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check database
+			decisionNumber, err := getAuthzDecisionsCountFiltered(test.decisionToCreate.ID, test.expectedRequested, test.expectedAllowed)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting authz decisions: %v", n, err)
+				continue
+			}
+			if decisionNumber != 1 {
+				t.Errorf("Test %v failed. Received different authz decision number: %v", n, decisionNumber)
+				continue
+			}
+		}
+	}
+}
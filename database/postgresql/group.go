@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/tecsisa/foulkon/api"
 	"github.com/tecsisa/foulkon/database"
 )
@@ -14,31 +15,50 @@ func (g PostgresRepo) AddGroup(group api.Group) (*api.Group, error) {
 
 	// Create group model
 	groupDB := &Group{
-		ID:       group.ID,
-		Name:     group.Name,
-		Path:     group.Path,
-		CreateAt: group.CreateAt.UnixNano(),
-		Urn:      group.Urn,
-		Org:      group.Org,
+		ID:          group.ID,
+		Name:        group.Name,
+		Path:        group.Path,
+		DisplayName: group.DisplayName,
+		Description: group.Description,
+		CreateAt:    group.CreateAt.UnixNano(),
+		UpdateAt:    group.UpdateAt.UnixNano(),
+		CreatedBy:   group.CreatedBy,
+		UpdatedBy:   group.UpdatedBy,
+		Urn:         group.Urn,
+		Org:         group.Org,
+		Version:     1,
+	}
+
+	transaction := g.Dbmap.Begin()
+
+	// Create organization of group if it's new
+	if err := addMissingOrganization(transaction, group.Org); err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
 	}
 
 	// Store group
-	err := g.Dbmap.Create(groupDB).Error
+	err := transaction.Create(groupDB).Error
 
 	// Error handling
 	if err != nil {
+		transaction.Rollback()
 		return nil, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
+	transaction.Commit()
 	return dbGroupToAPIGroup(groupDB), nil
 }
 
 func (g PostgresRepo) GetGroupByName(org string, name string) (*api.Group, error) {
 	group := &Group{}
-	query := g.Dbmap.Where("org like ? AND name like ?", org, name).First(group)
+	query := g.Dbmap.Where("org like ? AND name like ? AND delete_at = 0", org, name).First(group)
 
 	// Check if group exists
 	if query.RecordNotFound() {
@@ -61,7 +81,7 @@ func (g PostgresRepo) GetGroupByName(org string, name string) (*api.Group, error
 
 func (g PostgresRepo) GetGroupById(id string) (*api.Group, error) {
 	group := &Group{}
-	query := g.Dbmap.Where("id like ?", id).First(group)
+	query := g.Dbmap.Where("id like ? AND delete_at = 0", id).First(group)
 
 	// Check if group exists
 	if query.RecordNotFound() {
@@ -84,7 +104,7 @@ func (g PostgresRepo) GetGroupById(id string) (*api.Group, error) {
 
 func (g PostgresRepo) GetGroupsFiltered(org string, pathPrefix string) ([]api.Group, error) {
 	groups := []Group{}
-	query := g.Dbmap
+	query := g.Dbmap.Where("delete_at = 0")
 	if len(org) > 0 {
 		query = query.Where("org like ? ", org)
 	}
@@ -112,26 +132,35 @@ func (g PostgresRepo) GetGroupsFiltered(org string, pathPrefix string) ([]api.Gr
 	return nil, nil
 }
 
-func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath string, urn string) (*api.Group, error) {
-
-	// Create new group
-	updatedGroup := Group{
-		Name: newName,
-		Path: newPath,
-		Urn:  urn,
-	}
+func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath string, urn string, newDisplayName string,
+	newDescription string, updatedBy string) (*api.Group, error) {
 
 	groupDB := Group{
-		ID:       group.ID,
-		Name:     group.Name,
-		Path:     group.Path,
-		CreateAt: group.CreateAt.UTC().UnixNano(),
-		Urn:      group.Urn,
-		Org:      group.Org,
+		ID:          group.ID,
+		Name:        newName,
+		Path:        newPath,
+		DisplayName: newDisplayName,
+		Description: newDescription,
+		CreateAt:    group.CreateAt.UTC().UnixNano(),
+		UpdateAt:    time.Now().UTC().UnixNano(),
+		CreatedBy:   group.CreatedBy,
+		UpdatedBy:   updatedBy,
+		Urn:         urn,
+		Org:         group.Org,
+		Version:     group.Version + 1,
 	}
 
-	// Update group
-	query := g.Dbmap.Model(&groupDB).Update(updatedGroup)
+	// Update group only if it's still in the same version. A map is used to store empty values too
+	query := g.Dbmap.Model(&Group{ID: group.ID}).Where("version = ?", group.Version).Updates(map[string]interface{}{
+		"name":         groupDB.Name,
+		"path":         groupDB.Path,
+		"display_name": groupDB.DisplayName,
+		"description":  groupDB.Description,
+		"update_at":    groupDB.UpdateAt,
+		"updated_by":   groupDB.UpdatedBy,
+		"urn":          groupDB.Urn,
+		"version":      groupDB.Version,
+	})
 
 	// Check if group exist
 	if query.RecordNotFound() {
@@ -149,49 +178,93 @@ func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath strin
 		}
 	}
 
+	// Check if group was modified meanwhile
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("Group with organization %v and name %v isn't in version %v", group.Org, group.Name, group.Version),
+		}
+	}
+
 	return dbGroupToAPIGroup(&groupDB), nil
 }
 
-func (g PostgresRepo) RemoveGroup(id string) error {
-	transaction := g.Dbmap.Begin()
-	// Delete group
-	transaction.Where("id like ?", id).Delete(&Group{})
+func (g PostgresRepo) RemoveGroup(id string, version int64) error {
+	// Mark group as deleted only if it's still in the same version, user and policy relations are kept to restore them
+	query := g.Dbmap.Model(&Group{}).Where("id like ? AND version = ? AND delete_at = 0", id, version).
+		UpdateColumn("delete_at", time.Now().UTC().UnixNano())
 
 	// Error handling
-	if err := transaction.Error; err != nil {
-		transaction.Rollback()
+	if err := query.Error; err != nil {
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
-	// Delete all group relations
-	transaction.Where("group_id like ?", id).Delete(&GroupUserRelation{})
-
-	// Error handling
-	if err := transaction.Error; err != nil {
-		transaction.Rollback()
+	// Check if group was modified or deleted meanwhile
+	if query.RowsAffected == 0 {
 		return &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("Group with id %v isn't in version %v", id, version),
+		}
+	}
+
+	return nil
+}
+
+func (g PostgresRepo) GetDeletedGroupByName(org string, name string) (*api.Group, error) {
+	group := &Group{}
+	query := g.Dbmap.Where("org like ? AND name like ? AND delete_at > 0", org, name).First(group)
+
+	// Check if deleted group exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.GROUP_NOT_FOUND,
+			Message: fmt.Sprintf("Deleted group with organization %v and name %v not found", org, name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
-	transaction.Commit()
-	return nil
+	return dbGroupToAPIGroup(group), nil
 }
 
-func (g PostgresRepo) AddMember(userID string, groupID string) error {
+func (g PostgresRepo) GetDeletedGroups(org string, pathPrefix string) ([]api.Group, error) {
+	groups := []Group{}
+	query := g.Dbmap.Where("delete_at > 0")
+	if len(org) > 0 {
+		query = query.Where("org like ? ", org)
+	}
+	if len(pathPrefix) > 0 {
+		query = query.Where("path like ? ", pathPrefix+"%")
+	}
+	// Error handling
+	if err := query.Find(&groups).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
-	// Create relation
-	relation := &GroupUserRelation{
-		UserID:  userID,
-		GroupID: groupID,
+	// Transform groups for API
+	apiGroups := make([]api.Group, len(groups), cap(groups))
+	for i, g := range groups {
+		apiGroups[i] = *dbGroupToAPIGroup(&g)
 	}
 
-	// Store relation
-	err := g.Dbmap.Create(relation).Error
+	return apiGroups, nil
+}
+
+func (g PostgresRepo) RestoreGroup(id string) error {
+	// Clear deletion mark, so retained relations are taken into account again
+	err := g.Dbmap.Model(&Group{}).Where("id like ? AND delete_at > 0", id).UpdateColumn("delete_at", 0).Error
 
 	// Error handling
 	if err != nil {
@@ -204,6 +277,68 @@ func (g PostgresRepo) AddMember(userID string, groupID string) error {
 	return nil
 }
 
+func (g PostgresRepo) PurgeGroups(deletedBefore time.Time) (int64, error) {
+	transaction := g.Dbmap.Begin()
+	deleted := "SELECT id FROM groups WHERE delete_at > 0 AND delete_at < ?"
+	before := deletedBefore.UTC().UnixNano()
+
+	// Delete user relations of purged groups
+	if err := transaction.Where("group_id IN ("+deleted+")", before).Delete(&GroupUserRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete policy relations of purged groups
+	if err := transaction.Where("group_id IN ("+deleted+")", before).Delete(&GroupPolicyRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete access requests of purged groups
+	if err := transaction.Where("group_id IN ("+deleted+")", before).Delete(&AccessRequest{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete groups
+	query := transaction.Where("delete_at > 0 AND delete_at < ?", before).Delete(&Group{})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return query.RowsAffected, nil
+}
+
+func (g PostgresRepo) AddMember(userID string, groupID string, expireAt *time.Time) error {
+	return g.addMember(&GroupUserRelation{
+		UserID:   userID,
+		GroupID:  groupID,
+		ExpireAt: apiOptionalTimeToDBTime(expireAt),
+	})
+}
+
+func (g PostgresRepo) AddMappedMember(userID string, groupID string) error {
+	return g.addMember(&GroupUserRelation{
+		UserID:  userID,
+		GroupID: groupID,
+		Mapped:  true,
+	})
+}
+
 func (g PostgresRepo) RemoveMember(userID string, groupID string) error {
 	err := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error
 
@@ -217,9 +352,64 @@ func (g PostgresRepo) RemoveMember(userID string, groupID string) error {
 	return nil
 }
 
+func (g PostgresRepo) AddMembers(userIDs []string, groupID string) error {
+	transaction := g.Dbmap.Begin()
+
+	// Create relations
+	for _, userID := range userIDs {
+		// Remove expired relation that isn't swept yet
+		if err := removeExpiredMember(transaction, userID, groupID); err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+
+		relation := &GroupUserRelation{
+			UserID:  userID,
+			GroupID: groupID,
+		}
+
+		// Store relation
+		if err := transaction.Create(relation).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (g PostgresRepo) RemoveMembers(userIDs []string, groupID string) error {
+	transaction := g.Dbmap.Begin()
+
+	// Remove relations
+	for _, userID := range userIDs {
+		err := transaction.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error
+
+		// Error handling
+		if err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
 func (g PostgresRepo) IsMemberOfGroup(userID string, groupID string) (bool, error) {
 	relation := GroupUserRelation{}
-	query := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).First(&relation)
+	query := g.Dbmap.Where("user_id like ? AND group_id like ? AND (expire_at = 0 OR expire_at > ?)",
+		userID, groupID, time.Now().UTC().UnixNano()).First(&relation)
 
 	// Check if relation exists
 	if query.RecordNotFound() {
@@ -237,9 +427,39 @@ func (g PostgresRepo) IsMemberOfGroup(userID string, groupID string) (bool, erro
 	return true, nil
 }
 
-func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
+func (g PostgresRepo) GetMappedGroupsByUserID(userID string) ([]api.Group, error) {
+	relations := []GroupUserRelation{}
+	query := g.Dbmap.Where("user_id = ? AND mapped = ? AND group_id NOT IN (SELECT id FROM groups WHERE delete_at > 0)",
+		userID, true).Find(&relations)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform relations to API domain
+	apiGroups := make([]api.Group, 0, len(relations))
+	for _, r := range relations {
+		group, err := g.GetGroupById(r.GroupID)
+		if err != nil {
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		apiGroups = append(apiGroups, *group)
+	}
+
+	return apiGroups, nil
+}
+
+func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.GroupMember, error) {
 	members := []GroupUserRelation{}
-	query := g.Dbmap.Where("group_id like ?", groupID)
+	query := g.Dbmap.Where("group_id like ? AND (expire_at = 0 OR expire_at > ?) AND user_id NOT IN (SELECT id FROM users WHERE delete_at > 0)",
+		groupID, time.Now().UTC().UnixNano())
 
 	// Error handling
 	if err := query.Find(&members).Error; err != nil {
@@ -249,10 +469,10 @@ func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
 		}
 	}
 
-	var apiUsers []api.User
+	var apiMembers []api.GroupMember
 	// Transform relations to API domain
 	if members != nil {
-		apiUsers = make([]api.User, len(members), cap(members))
+		apiMembers = make([]api.GroupMember, len(members), cap(members))
 		for i, m := range members {
 			user, err := g.GetUserByID(m.UserID)
 			// Error handling
@@ -263,31 +483,98 @@ func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
 				}
 			}
 
-			apiUsers[i] = *user
+			apiMembers[i] = api.GroupMember{
+				User:     *user,
+				ExpireAt: dbOptionalTimeToAPITime(m.ExpireAt),
+			}
 		}
 	}
 
-	return apiUsers, nil
+	return apiMembers, nil
 }
 
-func (g PostgresRepo) AttachPolicy(groupID string, policyID string) error {
+func (g PostgresRepo) RemoveExpiredMembers(expiredBefore time.Time) ([]api.ExpiredMember, error) {
+	transaction := g.Dbmap.Begin()
+	before := expiredBefore.UTC().UnixNano()
+
+	// Retrieve expired relations with their user and group identifiers
+	relations := []struct {
+		ExternalID string
+		Org        string
+		Name       string
+		ExpireAt   int64
+	}{}
+	err := transaction.Raw("SELECT u.external_id, g.org, g.name, r.expire_at FROM group_user_relations r "+
+		"JOIN users u ON u.id = r.user_id JOIN groups g ON g.id = r.group_id "+
+		"WHERE r.expire_at > 0 AND r.expire_at <= ?", before).Scan(&relations).Error
+	if err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	expiredMembers := make([]api.ExpiredMember, len(relations), cap(relations))
+	for i, r := range relations {
+		expiredMembers[i] = api.ExpiredMember{
+			User:     r.ExternalID,
+			Org:      r.Org,
+			Group:    r.Name,
+			ExpireAt: time.Unix(0, r.ExpireAt).UTC(),
+		}
+	}
+
+	// Delete expired relations
+	if err := transaction.Where("expire_at > 0 AND expire_at <= ?", before).Delete(&GroupUserRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return expiredMembers, nil
+}
+
+func (g PostgresRepo) AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error {
 	// Create relation
 	relation := &GroupPolicyRelation{
 		GroupID:  groupID,
 		PolicyID: policyID,
 	}
+	if notBefore != nil {
+		relation.NotBefore = notBefore.UTC().UnixNano()
+	}
+	if notAfter != nil {
+		relation.NotAfter = notAfter.UTC().UnixNano()
+	}
+
+	transaction := g.Dbmap.Begin()
+
+	// Remove expired relation, that isn't attached anymore
+	if err := removeExpiredAttachment(transaction, groupID, policyID); err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
 	// Store relation
-	err := g.Dbmap.Create(relation).Error
+	err := transaction.Create(relation).Error
 
 	// Error handling
 	if err != nil {
+		transaction.Rollback()
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
+	transaction.Commit()
 	return nil
 }
 
@@ -306,9 +593,64 @@ func (g PostgresRepo) DetachPolicy(groupID string, policyID string) error {
 	return nil
 }
 
+func (g PostgresRepo) AttachPolicies(groupID string, policyIDs []string) error {
+	transaction := g.Dbmap.Begin()
+
+	// Create relations
+	for _, policyID := range policyIDs {
+		// Remove expired relation, that isn't attached anymore
+		if err := removeExpiredAttachment(transaction, groupID, policyID); err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+
+		relation := &GroupPolicyRelation{
+			GroupID:  groupID,
+			PolicyID: policyID,
+		}
+
+		// Store relation
+		if err := transaction.Create(relation).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (g PostgresRepo) DetachPolicies(groupID string, policyIDs []string) error {
+	transaction := g.Dbmap.Begin()
+
+	// Remove relations
+	for _, policyID := range policyIDs {
+		err := transaction.Where("group_id like ? AND policy_id like ?", groupID, policyID).Delete(&GroupPolicyRelation{}).Error
+
+		// Error handling
+		if err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
 func (g PostgresRepo) IsAttachedToGroup(groupID string, policyID string) (bool, error) {
 	relation := GroupPolicyRelation{}
-	query := g.Dbmap.Where("group_id like ? AND policy_id like ?", groupID, policyID).First(&relation)
+	query := g.Dbmap.Where("group_id like ? AND policy_id like ? AND (not_after = 0 OR not_after > ?)",
+		groupID, policyID, time.Now().UTC().UnixNano()).First(&relation)
 
 	// Check if relation exists
 	if query.RecordNotFound() {
@@ -326,9 +668,9 @@ func (g PostgresRepo) IsAttachedToGroup(groupID string, policyID string) (bool,
 	return true, nil
 }
 
-func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error) {
+func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.GroupPolicy, error) {
 	relations := []GroupPolicyRelation{}
-	query := g.Dbmap.Where("group_id like ?", groupID).Find(&relations)
+	query := g.Dbmap.Where("group_id like ? AND policy_id NOT IN (SELECT id FROM policies WHERE delete_at > 0)", groupID).Find(&relations)
 
 	// Error Handling
 	if err := query.Error; err != nil {
@@ -337,10 +679,10 @@ func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error)
 			Message: err.Error(),
 		}
 	}
-	var apiPolicies []api.Policy
+	var apiPolicies []api.GroupPolicy
 	// Transform relations to API domain
 	if relations != nil {
-		apiPolicies = make([]api.Policy, len(relations), cap(relations))
+		apiPolicies = make([]api.GroupPolicy, len(relations), cap(relations))
 		for i, r := range relations {
 			policy, err := g.GetPolicyById(r.PolicyID)
 			// Error handling
@@ -351,7 +693,11 @@ func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error)
 				}
 			}
 
-			apiPolicies[i] = *policy
+			apiPolicies[i] = api.GroupPolicy{
+				Policy:    *policy,
+				NotBefore: dbOptionalTimeToAPITime(r.NotBefore),
+				NotAfter:  dbOptionalTimeToAPITime(r.NotAfter),
+			}
 		}
 	}
 
@@ -360,14 +706,87 @@ func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error)
 
 // PRIVATE HELPER METHODS
 
+// Store relation of a new member removing its expired relation that isn't swept yet
+func (g PostgresRepo) addMember(relation *GroupUserRelation) error {
+	transaction := g.Dbmap.Begin()
+
+	// Remove expired relation that isn't swept yet
+	if err := removeExpiredMember(transaction, relation.UserID, relation.GroupID); err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Store relation
+	err := transaction.Create(relation).Error
+
+	// Error handling
+	if err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+// Remove relation between user and group if it's expired, so it can be created again
+func removeExpiredMember(transaction *gorm.DB, userID string, groupID string) error {
+	return transaction.Where("user_id like ? AND group_id like ? AND expire_at > 0 AND expire_at <= ?",
+		userID, groupID, time.Now().UTC().UnixNano()).Delete(&GroupUserRelation{}).Error
+}
+
+// Remove relation between group and policy if its attachment is expired, so it can be attached again
+func removeExpiredAttachment(transaction *gorm.DB, groupID string, policyID string) error {
+	return transaction.Where("group_id like ? AND policy_id like ? AND not_after > 0 AND not_after <= ?",
+		groupID, policyID, time.Now().UTC().UnixNano()).Delete(&GroupPolicyRelation{}).Error
+}
+
+// Transform an optional time retrieved from db, like an expiration time, into a time for API, nil if it isn't set
+func dbOptionalTimeToAPITime(dbTime int64) *time.Time {
+	if dbTime == 0 {
+		return nil
+	}
+	apiTime := time.Unix(0, dbTime).UTC()
+	return &apiTime
+}
+
+// Transform an optional time for API into a time for db, 0 if it isn't set
+func apiOptionalTimeToDBTime(apiTime *time.Time) int64 {
+	if apiTime == nil {
+		return 0
+	}
+	return apiTime.UTC().UnixNano()
+}
+
+// Transform an update time retrieved from db into a time for API. Rows stored before the update time was
+// tracked fall back to their creation time
+func dbUpdateTimeToAPITime(updateAt int64, createAt int64) time.Time {
+	if updateAt == 0 {
+		return time.Unix(0, createAt).UTC()
+	}
+	return time.Unix(0, updateAt).UTC()
+}
+
 // Transform a Group retrieved from db into a group for API
 func dbGroupToAPIGroup(groupdb *Group) *api.Group {
 	return &api.Group{
-		ID:       groupdb.ID,
-		Name:     groupdb.Name,
-		Path:     groupdb.Path,
-		CreateAt: time.Unix(0, groupdb.CreateAt).UTC(),
-		Urn:      groupdb.Urn,
-		Org:      groupdb.Org,
+		ID:          groupdb.ID,
+		Name:        groupdb.Name,
+		Path:        groupdb.Path,
+		DisplayName: groupdb.DisplayName,
+		Description: groupdb.Description,
+		CreateAt:    time.Unix(0, groupdb.CreateAt).UTC(),
+		UpdateAt:    dbUpdateTimeToAPITime(groupdb.UpdateAt, groupdb.CreateAt),
+		CreatedBy:   groupdb.CreatedBy,
+		UpdatedBy:   groupdb.UpdatedBy,
+		Urn:         groupdb.Urn,
+		Org:         groupdb.Org,
+		Version:     groupdb.Version,
 	}
 }
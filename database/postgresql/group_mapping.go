@@ -0,0 +1,119 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// GROUP MAPPING REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddGroupMapping(mapping api.GroupMapping) (*api.GroupMapping, error) {
+
+	// Create group mapping model
+	mappingDB := &GroupMapping{
+		ID:        mapping.ID,
+		Issuer:    mapping.Issuer,
+		Claim:     mapping.Claim,
+		Value:     mapping.Value,
+		Org:       mapping.Org,
+		GroupName: mapping.Group,
+		Urn:       mapping.Urn,
+		CreateAt:  mapping.CreateAt.UnixNano(),
+		CreatedBy: mapping.CreatedBy,
+	}
+
+	// Store group mapping
+	err := r.Dbmap.Create(mappingDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbGroupMappingToAPIGroupMapping(mappingDB), nil
+}
+
+func (r PostgresRepo) GetGroupMappingByID(id string) (*api.GroupMapping, error) {
+	mapping := &GroupMapping{}
+	query := r.Dbmap.Where("id like ?", id).First(mapping)
+
+	// Check if group mapping exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.GROUP_MAPPING_NOT_FOUND,
+			Message: fmt.Sprintf("Group mapping with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbGroupMappingToAPIGroupMapping(mapping), nil
+}
+
+func (r PostgresRepo) GetGroupMappings(org string) ([]api.GroupMapping, error) {
+	mappings := []GroupMapping{}
+	query := r.Dbmap
+	if len(org) > 0 {
+		query = query.Where("org like ?", org)
+	}
+
+	// Error handling
+	if err := query.Order("create_at").Find(&mappings).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform group mappings for API
+	apiMappings := make([]api.GroupMapping, len(mappings), cap(mappings))
+	for i, mapping := range mappings {
+		apiMappings[i] = *dbGroupMappingToAPIGroupMapping(&mapping)
+	}
+
+	return apiMappings, nil
+}
+
+func (r PostgresRepo) RemoveGroupMapping(id string) error {
+	// Delete group mapping
+	err := r.Dbmap.Where("id like ?", id).Delete(&GroupMapping{}).Error
+
+	// Error Handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform a group mapping retrieved from db into a group mapping for API
+func dbGroupMappingToAPIGroupMapping(mappingdb *GroupMapping) *api.GroupMapping {
+	return &api.GroupMapping{
+		ID:        mappingdb.ID,
+		Issuer:    mappingdb.Issuer,
+		Claim:     mappingdb.Claim,
+		Value:     mappingdb.Value,
+		Org:       mappingdb.Org,
+		Group:     mappingdb.GroupName,
+		Urn:       mappingdb.Urn,
+		CreateAt:  time.Unix(0, mappingdb.CreateAt).UTC(),
+		CreatedBy: mappingdb.CreatedBy,
+	}
+}
@@ -0,0 +1,351 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddGroupMapping(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousMapping *api.GroupMapping
+		// Postgres Repo Args
+		mappingToCreate *api.GroupMapping
+		// Expected result
+		expectedResponse *api.GroupMapping
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			mappingToCreate: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+			expectedResponse: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+		},
+		"ErrorCaseGroupMappingAlreadyExist": {
+			previousMapping: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+			mappingToCreate: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"group_mappings_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group mapping database
+		cleanGroupMappingTable()
+
+		// Insert previous data
+		if test.previousMapping != nil {
+			if _, err := repoDB.AddGroupMapping(*test.previousMapping); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store group mapping
+		receivedMapping, err := repoDB.AddGroupMapping(*test.mappingToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedMapping, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			mappingNumber, err := getGroupMappingsCountFiltered(test.mappingToCreate.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting group mappings: %v", n, err)
+				continue
+			}
+			if mappingNumber != 1 {
+				t.Errorf("Test %v failed. Received different group mapping number: %v", n, mappingNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetGroupMappingByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousMapping *api.GroupMapping
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.GroupMapping
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousMapping: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+			id: "MappingID",
+			expectedResponse: &api.GroupMapping{
+				ID:        "MappingID",
+				Issuer:    "https://accounts.example.com",
+				Claim:     "groups",
+				Value:     "developers",
+				Org:       "org1",
+				Group:     "group1",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt:  now,
+				CreatedBy: "admin",
+			},
+		},
+		"ErrorCaseGroupMappingNotExist": {
+			id: "MappingID",
+			expectedError: &database.Error{
+				Code:    database.GROUP_MAPPING_NOT_FOUND,
+				Message: "Group mapping with id MappingID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group mapping database
+		cleanGroupMappingTable()
+
+		// Insert previous data
+		if test.previousMapping != nil {
+			if _, err := repoDB.AddGroupMapping(*test.previousMapping); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get group mapping
+		receivedMapping, err := repoDB.GetGroupMappingByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedMapping, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetGroupMappings(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousMappings []api.GroupMapping
+		// Postgres Repo Args
+		org string
+		// Expected result
+		expectedResponse []string
+	}{
+		"OkCase": {
+			previousMappings: []api.GroupMapping{
+				{
+					ID:       "MappingID1",
+					Claim:    "groups",
+					Value:    "developers",
+					Org:      "org1",
+					Group:    "group1",
+					Urn:      api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID1"),
+					CreateAt: now,
+				},
+				{
+					ID:       "MappingID2",
+					Claim:    "groups",
+					Value:    "admins",
+					Org:      "org2",
+					Group:    "group2",
+					Urn:      api.CreateUrn("org2", api.RESOURCE_GROUP_MAPPING, "/", "MappingID2"),
+					CreateAt: now.Add(time.Second),
+				},
+			},
+			expectedResponse: []string{"MappingID1", "MappingID2"},
+		},
+		"OkCaseFilteredByOrg": {
+			previousMappings: []api.GroupMapping{
+				{
+					ID:       "MappingID1",
+					Claim:    "groups",
+					Value:    "developers",
+					Org:      "org1",
+					Group:    "group1",
+					Urn:      api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID1"),
+					CreateAt: now,
+				},
+				{
+					ID:       "MappingID2",
+					Claim:    "groups",
+					Value:    "admins",
+					Org:      "org2",
+					Group:    "group2",
+					Urn:      api.CreateUrn("org2", api.RESOURCE_GROUP_MAPPING, "/", "MappingID2"),
+					CreateAt: now.Add(time.Second),
+				},
+			},
+			org:              "org2",
+			expectedResponse: []string{"MappingID2"},
+		},
+		"OkCaseWithoutMappings": {
+			expectedResponse: []string{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group mapping database
+		cleanGroupMappingTable()
+
+		// Insert previous data
+		for _, mapping := range test.previousMappings {
+			if _, err := repoDB.AddGroupMapping(mapping); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get group mappings
+		mappings, err := repoDB.GetGroupMappings(test.org)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		ids := []string{}
+		for _, mapping := range mappings {
+			ids = append(ids, mapping.ID)
+		}
+		// Check response
+		if diff := pretty.Compare(ids, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveGroupMapping(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousMapping *api.GroupMapping
+		// Postgres Repo Args
+		id string
+	}{
+		"OkCase": {
+			previousMapping: &api.GroupMapping{
+				ID:       "MappingID",
+				Claim:    "groups",
+				Value:    "developers",
+				Org:      "org1",
+				Group:    "group1",
+				Urn:      api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MappingID"),
+				CreateAt: now,
+			},
+			id: "MappingID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group mapping database
+		cleanGroupMappingTable()
+
+		// Insert previous data
+		if test.previousMapping != nil {
+			if _, err := repoDB.AddGroupMapping(*test.previousMapping); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove group mapping
+		if err := repoDB.RemoveGroupMapping(test.id); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		mappingNumber, err := getGroupMappingsCountFiltered(test.id)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting group mappings: %v", n, err)
+			continue
+		}
+		if mappingNumber != 0 {
+			t.Errorf("Test %v failed. Received different group mapping number: %v", n, mappingNumber)
+			continue
+		}
+	}
+}
@@ -27,6 +27,8 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			expectedResponse: &api.Group{
@@ -35,6 +37,8 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 		},
@@ -45,6 +49,8 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			groupToCreate: &api.Group{
@@ -53,6 +59,8 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			expectedError: &database.Error{
@@ -65,6 +73,7 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 	for n, test := range testcases {
 		// Clean user database
 		cleanGroupTable()
+		cleanOrganizationTable()
 
 		// Insert previous data
 		if test.previousGroup != nil {
@@ -108,6 +117,16 @@ func TestPostgresRepo_AddGroup(t *testing.T) {
 				t.Errorf("Test %v failed. Received different group number: %v", n, groupNumber)
 				continue
 			}
+			// Check organization of group
+			orgNumber, err := getOrganizationsCountFiltered("", test.groupToCreate.Org)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != 1 {
+				t.Errorf("Test %v failed. Received different organization number: %v", n, orgNumber)
+				continue
+			}
 		}
 	}
 }
@@ -131,6 +150,8 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			org:  "Org",
@@ -141,6 +162,8 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 		},
@@ -151,6 +174,8 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			org:  "Org",
@@ -216,6 +241,8 @@ func TestPostgresRepo_GetGroupById(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			groupID: "GroupID",
@@ -225,6 +252,8 @@ func TestPostgresRepo_GetGroupById(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 		},
@@ -235,6 +264,8 @@ func TestPostgresRepo_GetGroupById(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			groupID: "NotExist",
@@ -300,6 +331,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -308,6 +341,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -319,6 +354,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -327,6 +364,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -339,6 +378,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -347,6 +388,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -358,6 +401,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 			},
@@ -370,6 +415,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -378,6 +425,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -392,6 +441,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -400,6 +451,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -411,6 +464,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 			},
@@ -423,6 +478,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -431,6 +488,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -443,6 +502,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 			},
@@ -455,6 +516,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -463,6 +526,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -473,6 +538,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path123",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org1",
 				},
 				{
@@ -481,6 +548,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Path:     "Path456",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -521,10 +590,13 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 		// Previous data
 		previousGroups []api.Group
 		// Postgres Repo Args
-		groupToUpdate *api.Group
-		newName       string
-		newPath       string
-		newUrn        string
+		groupToUpdate  *api.Group
+		newName        string
+		newPath        string
+		newUrn         string
+		newDisplayName string
+		newDescription string
+		updatedBy      string
 		// Expected result
 		expectedResponse *api.Group
 		expectedError    *database.Error
@@ -537,6 +609,8 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 					Path:     "Path",
 					Urn:      "Urn",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org",
 				},
 			},
@@ -546,18 +620,27 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
-			newName: "NewName",
-			newPath: "NewPath",
-			newUrn:  "NewUrn",
+			newName:        "NewName",
+			newPath:        "NewPath",
+			newUrn:         "NewUrn",
+			newDisplayName: "DisplayName",
+			newDescription: "Description",
+			updatedBy:      "UpdaterID",
 			expectedResponse: &api.Group{
-				ID:       "GroupID",
-				Name:     "NewName",
-				Path:     "NewPath",
-				Urn:      "NewUrn",
-				CreateAt: now,
-				Org:      "Org",
+				ID:          "GroupID",
+				Name:        "NewName",
+				Path:        "NewPath",
+				DisplayName: "DisplayName",
+				Description: "Description",
+				Urn:         "NewUrn",
+				CreateAt:    now,
+				UpdatedBy:   "UpdaterID",
+				Version:     2,
+				Org:         "Org",
 			},
 		},
 		"ErrorCaseDuplicateUrn": {
@@ -568,6 +651,8 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 					Path:     "Path",
 					Urn:      "Urn",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org",
 				},
 				{
@@ -576,6 +661,8 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 					Path:     "Path2",
 					Urn:      "Fail",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org2",
 				},
 			},
@@ -585,6 +672,8 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			newName: "NewName",
@@ -595,6 +684,36 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 				Message: "pq: duplicate key value violates unique constraint \"groups_urn_key\"",
 			},
 		},
+		"ErrorCaseVersionMismatch": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "Urn",
+					CreateAt: now,
+					UpdateAt: now,
+					Org:      "Org",
+				},
+			},
+			groupToUpdate: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  2,
+				Org:      "Org",
+			},
+			newName: "NewName",
+			newPath: "NewPath",
+			newUrn:  "NewUrn",
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Group with organization Org and name Name isn't in version 2",
+			},
+		},
 	}
 
 	for n, test := range testcases {
@@ -614,7 +733,8 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 		}
 
 		// Call to repository to update group
-		updatedGroup, err := repoDB.UpdateGroup(*test.groupToUpdate, test.newName, test.newPath, test.newUrn)
+		updatedGroup, err := repoDB.UpdateGroup(*test.groupToUpdate, test.newName, test.newPath, test.newUrn,
+			test.newDisplayName, test.newDescription, test.updatedBy)
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -630,6 +750,12 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 				continue
 			}
+			// Update time is set by the repository
+			if updatedGroup.UpdateAt.Before(now) {
+				t.Errorf("Test %v failed. Received update time %v before %v", n, updatedGroup.UpdateAt, now)
+				continue
+			}
+			test.expectedResponse.UpdateAt = updatedGroup.UpdateAt
 			// Check response
 			if diff := pretty.Compare(updatedGroup, test.expectedResponse); diff != "" {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -662,6 +788,9 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 		}
 		// Postgres Repo Args
 		groupToDelete string
+		version       int64
+		// Expected result
+		expectedError *database.Error
 	}{
 		"OkCase": {
 			previousGroup: &api.Group{
@@ -670,6 +799,8 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "Urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			relation: &struct {
@@ -681,6 +812,23 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 				group_ids: []string{"GroupID"},
 			},
 			groupToDelete: "GroupID",
+			version:       1,
+		},
+		"ErrorCaseVersionMismatch": {
+			previousGroup: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				Org:      "Org",
+			},
+			groupToDelete: "GroupID",
+			version:       2,
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Group with id GroupID isn't in version 2",
+			},
 		},
 	}
 
@@ -705,17 +853,40 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 			}
 		}
 		// Call to repository to remove group
-		err := repoDB.RemoveGroup(test.groupToDelete)
+		err := repoDB.RemoveGroup(test.groupToDelete, test.version)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database, group isn't marked as deleted
+			if _, err := repoDB.GetGroupById(test.groupToDelete); err != nil {
+				t.Errorf("Test %v failed. Group was deleted: %v", n, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
 
-		// Check database
-		groupNumber, err := getGroupsCountFiltered(test.groupToDelete, "", "",
-			0, "", "")
+		// Check database, group is kept marked as deleted
+		deleteAt, err := getDeleteAt(Group{}.TableName(), test.groupToDelete)
 		if err != nil {
-			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			t.Errorf("Test %v failed. Unexpected error retrieving group: %v", n, err)
 			continue
 		}
-		if groupNumber != 0 {
-			t.Errorf("Test %v failed. Received different group number: %v", n, groupNumber)
+		if deleteAt == 0 {
+			t.Errorf("Test %v failed. Group isn't marked as deleted", n)
+			continue
+		}
+		if _, err := repoDB.GetGroupById(test.groupToDelete); err == nil {
+			t.Errorf("Test %v failed. Deleted group is still retrieved", n)
 			continue
 		}
 
@@ -724,7 +895,7 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
 			continue
 		}
-		if relations != 0 {
+		if relations != len(test.relation.group_ids) {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 			continue
 		}
@@ -732,10 +903,14 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 }
 
 func TestPostgresRepo_AddMember(t *testing.T) {
+	expireAt := time.Unix(0, time.Now().UTC().Add(time.Hour).UnixNano()).UTC()
 	testcases := map[string]struct {
+		// Previous data
+		previousExpireAt int64
 		// Postgres Repo Args
-		userID  string
-		groupID string
+		userID   string
+		groupID  string
+		expireAt *time.Time
 		// Expected result
 		expectedError *database.Error
 	}{
@@ -743,6 +918,16 @@ func TestPostgresRepo_AddMember(t *testing.T) {
 			userID:  "UserID",
 			groupID: "GroupID",
 		},
+		"OkCaseExpireAt": {
+			userID:   "UserID",
+			groupID:  "GroupID",
+			expireAt: &expireAt,
+		},
+		"OkCaseExpiredRelation": {
+			previousExpireAt: time.Now().UTC().Add(-time.Hour).UnixNano(),
+			userID:           "UserID",
+			groupID:          "GroupID",
+		},
 		"ErrorCaseInternalError": {
 			groupID: "GroupID",
 			expectedError: &database.Error{
@@ -756,8 +941,16 @@ func TestPostgresRepo_AddMember(t *testing.T) {
 		// Clean GroupUserRelation database
 		cleanGroupUserRelationTable()
 
+		// Insert previous data
+		if test.previousExpireAt != 0 {
+			if err := insertExpiringGroupUserRelation(test.userID, test.groupID, test.previousExpireAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+
 		// Call to repository to store member
-		err := repoDB.AddMember(test.userID, test.groupID)
+		err := repoDB.AddMember(test.userID, test.groupID, test.expireAt)
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -780,6 +973,19 @@ func TestPostgresRepo_AddMember(t *testing.T) {
 				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 				continue
 			}
+			storedExpireAt, err := getGroupUserRelationExpireAt(test.groupID, test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving relation: %v", n, err)
+				continue
+			}
+			var wantExpireAt int64
+			if test.expireAt != nil {
+				wantExpireAt = test.expireAt.UnixNano()
+			}
+			if storedExpireAt != wantExpireAt {
+				t.Errorf("Test %v failed. Received different expiration (wanted:%v / received:%v)", n, wantExpireAt, storedExpireAt)
+				continue
+			}
 		}
 	}
 }
@@ -843,6 +1049,116 @@ func TestPostgresRepo_RemoveMember(t *testing.T) {
 	}
 }
 
+func TestPostgresRepo_AddMembers(t *testing.T) {
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		userIDs []string
+		groupID string
+		// Expected result
+		expectedRelations int
+		expectedError     *database.Error
+	}{
+		"OkCase": {
+			userIDs:           []string{"UserID1", "UserID2"},
+			groupID:           "GroupID",
+			expectedRelations: 1,
+		},
+		"ErrorCaseInternalError": {
+			userIDs: []string{"UserID1", "UserID2"},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: null value in column group_id violates not-null constraint",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupUserRelation database
+		cleanGroupUserRelationTable()
+
+		// Call to repository to store members
+		err := repoDB.AddMembers(test.userIDs, test.groupID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		for _, userID := range test.userIDs {
+			relations, err := getGroupUserRelations(test.groupID, userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			if relations != test.expectedRelations {
+				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveMembers(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousUserIDs []string
+		// Postgres Repo Args
+		userIDs []string
+		groupID string
+	}{
+		"OkCase": {
+			previousUserIDs: []string{"UserID1", "UserID2", "UserID3"},
+			userIDs:         []string{"UserID1", "UserID2"},
+			groupID:         "GroupID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupUserRelation database
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		for _, userID := range test.previousUserIDs {
+			if err := insertGroupUserRelation(userID, test.groupID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove members
+		err := repoDB.RemoveMembers(test.userIDs, test.groupID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		for _, userID := range test.previousUserIDs {
+			relations, err := getGroupUserRelations(test.groupID, userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			expectedRelations := 1
+			for _, removedID := range test.userIDs {
+				if removedID == userID {
+					expectedRelations = 0
+				}
+			}
+			if relations != expectedRelations {
+				t.Errorf("Test %v failed. Received different relations number for user %v: %v", n, userID, relations)
+				continue
+			}
+		}
+	}
+}
+
 func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// Previous data
@@ -850,6 +1166,7 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 			user_id  string
 			group_id string
 		}
+		expireAt int64
 		// Postgres Repo Args
 		group  string
 		member string
@@ -868,6 +1185,19 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 			member:   "UserID",
 			isMember: true,
 		},
+		"OkCaseExpiredMember": {
+			relation: &struct {
+				user_id  string
+				group_id string
+			}{
+				user_id:  "UserID",
+				group_id: "GroupID",
+			},
+			expireAt: time.Now().UTC().Add(-time.Hour).UnixNano(),
+			group:    "GroupID",
+			member:   "UserID",
+			isMember: false,
+		},
 		"OkCaseIsNotMember": {
 			group:    "GroupID",
 			member:   "UserID",
@@ -880,7 +1210,7 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 
 		// Insert previous data
 		if test.relation != nil {
-			if err := insertGroupUserRelation(test.relation.user_id, test.relation.group_id); err != nil {
+			if err := insertExpiringGroupUserRelation(test.relation.user_id, test.relation.group_id, test.expireAt); err != nil {
 				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
 				continue
 			}
@@ -900,24 +1230,93 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 	}
 }
 
+func TestPostgresRepo_GetMappedGroupsByUserID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousMappedGroups []string
+		previousOtherGroups  []string
+		// Postgres Repo Args
+		userID string
+		// Expected result
+		expectedGroupIDs []string
+	}{
+		"OkCase": {
+			previousMappedGroups: []string{"MappedGroupID"},
+			previousOtherGroups:  []string{"GroupID"},
+			userID:               "UserID",
+			expectedGroupIDs:     []string{"MappedGroupID"},
+		},
+		"OkCaseWithoutMappedGroups": {
+			previousOtherGroups: []string{"GroupID"},
+			userID:              "UserID",
+			expectedGroupIDs:    []string{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group and GroupUserRelation database
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		for _, id := range append(test.previousMappedGroups, test.previousOtherGroups...) {
+			if err := insertGroup(id, id, "Path", now.UnixNano(), "urn:"+id, "Org"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous groups: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.previousMappedGroups {
+			if err := repoDB.AddMappedMember(test.userID, id); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous mapped members: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.previousOtherGroups {
+			if err := insertGroupUserRelation(test.userID, id); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get groups of memberships added by group mappings
+		groups, err := repoDB.GetMappedGroupsByUserID(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		groupIDs := []string{}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		if diff := pretty.Compare(groupIDs, test.expectedGroupIDs); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
 func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 	now := time.Now().UTC()
+	expireAt := time.Unix(0, now.Add(time.Hour).UnixNano()).UTC()
 	testcases := map[string]struct {
 		// Previous data
 		relations *struct {
 			users        []api.User
+			expireAt     map[string]int64
 			group_id     string
 			userNotFound bool
 		}
 		// Postgres Repo Args
 		groupID string
 		// Expected result
-		expectedResponse []api.User
+		expectedResponse []api.GroupMember
 		expectedError    *database.Error
 	}{
 		"OkCase": {
 			relations: &struct {
 				users        []api.User
+				expireAt     map[string]int64
 				group_id     string
 				userNotFound bool
 			}{
@@ -926,40 +1325,72 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 						ID:         "UserID1",
 						ExternalID: "ExternalID1",
 						Path:       "Path",
+						Status:     api.USER_STATUS_ACTIVE,
 						Urn:        "urn1",
 						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
 					},
 					{
 						ID:         "UserID2",
 						ExternalID: "ExternalID2",
 						Path:       "Path",
+						Status:     api.USER_STATUS_ACTIVE,
 						Urn:        "urn2",
 						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+					},
+					{
+						ID:         "UserID3",
+						ExternalID: "ExternalID3",
+						Path:       "Path",
+						Status:     api.USER_STATUS_ACTIVE,
+						Urn:        "urn3",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
 					},
 				},
+				expireAt: map[string]int64{
+					"UserID2": expireAt.UnixNano(),
+					"UserID3": now.Add(-time.Hour).UnixNano(),
+				},
 				group_id: "GroupID",
 			},
 			groupID: "GroupID",
-			expectedResponse: []api.User{
+			expectedResponse: []api.GroupMember{
 				{
-					ID:         "UserID1",
-					ExternalID: "ExternalID1",
-					Path:       "Path",
-					Urn:        "urn1",
-					CreateAt:   now,
+					User: api.User{
+						ID:         "UserID1",
+						ExternalID: "ExternalID1",
+						Path:       "Path",
+						Status:     api.USER_STATUS_ACTIVE,
+						Urn:        "urn1",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+					},
 				},
 				{
-					ID:         "UserID2",
-					ExternalID: "ExternalID2",
-					Path:       "Path",
-					Urn:        "urn2",
-					CreateAt:   now,
+					User: api.User{
+						ID:         "UserID2",
+						ExternalID: "ExternalID2",
+						Path:       "Path",
+						Status:     api.USER_STATUS_ACTIVE,
+						Urn:        "urn2",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+					},
+					ExpireAt: &expireAt,
 				},
 			},
 		},
 		"ErrorCase": {
 			relations: &struct {
 				users        []api.User
+				expireAt     map[string]int64
 				group_id     string
 				userNotFound bool
 			}{
@@ -986,7 +1417,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 		// Insert previous data
 		if test.relations != nil {
 			for _, user := range test.relations.users {
-				if err := insertGroupUserRelation(user.ID, test.relations.group_id); err != nil {
+				if err := insertExpiringGroupUserRelation(user.ID, test.relations.group_id, test.relations.expireAt[user.ID]); err != nil {
 					t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
 					continue
 				}
@@ -1001,7 +1432,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 
 		}
 
-		receivedUsers, err := repoDB.GetGroupMembers(test.groupID)
+		receivedMembers, err := repoDB.GetGroupMembers(test.groupID)
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -1018,7 +1449,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 				continue
 			}
 			// Check response
-			if diff := pretty.Compare(receivedUsers, test.expectedResponse); diff != "" {
+			if diff := pretty.Compare(receivedMembers, test.expectedResponse); diff != "" {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
@@ -1027,10 +1458,16 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 }
 
 func TestPostgresRepo_AttachPolicy(t *testing.T) {
+	notBefore := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
+		// Previous data
+		previousNotAfter int64
 		// Postgres Repo Args
-		policyID string
-		groupID  string
+		policyID  string
+		groupID   string
+		notBefore *time.Time
+		notAfter  *time.Time
 		// Expected result
 		expectedError *database.Error
 	}{
@@ -1038,6 +1475,18 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 			policyID: "PolicyID",
 			groupID:  "GroupID",
 		},
+		"OkCaseWindow": {
+			policyID:  "PolicyID",
+			groupID:   "GroupID",
+			notBefore: &notBefore,
+			notAfter:  &notAfter,
+		},
+		"OkCaseExpiredRelation": {
+			previousNotAfter: time.Now().UTC().Add(-time.Hour).UnixNano(),
+			policyID:         "PolicyID",
+			groupID:          "GroupID",
+			notAfter:         &notAfter,
+		},
 		"ErrorCaseInternalError": {
 			expectedError: &database.Error{
 				Code:    database.INTERNAL_ERROR,
@@ -1050,11 +1499,19 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 		// Clean GroupPolicyRelation database
 		cleanGroupPolicyRelationTable()
 
-		// Call to repository to attach policy
-		err := repoDB.AttachPolicy(test.groupID, test.policyID)
-		if test.expectedError != nil {
-			dbError, ok := err.(*database.Error)
-			if !ok || dbError == nil {
+		// Insert previous data
+		if test.previousNotAfter != 0 {
+			if err := insertScheduledGroupPolicyRelation(test.groupID, test.policyID, 0, test.previousNotAfter); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to attach policy
+		err := repoDB.AttachPolicy(test.groupID, test.policyID, test.notBefore, test.notAfter)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
 				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
 				continue
 			}
@@ -1074,6 +1531,25 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 				continue
 			}
+
+			// Check window
+			storedNotBefore, storedNotAfter, err := getGroupPolicyRelationWindow(test.groupID, test.policyID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving relation: %v", n, err)
+				continue
+			}
+			var wantNotBefore, wantNotAfter int64
+			if test.notBefore != nil {
+				wantNotBefore = test.notBefore.UnixNano()
+			}
+			if test.notAfter != nil {
+				wantNotAfter = test.notAfter.UnixNano()
+			}
+			if storedNotBefore != wantNotBefore || storedNotAfter != wantNotAfter {
+				t.Errorf("Test %v failed. Received different window (wanted:%v-%v / received:%v-%v)", n,
+					wantNotBefore, wantNotAfter, storedNotBefore, storedNotAfter)
+				continue
+			}
 		}
 	}
 }
@@ -1137,6 +1613,133 @@ func TestPostgresRepo_DetachPolicy(t *testing.T) {
 	}
 }
 
+func TestPostgresRepo_AttachPolicies(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousExpiredPolicyIDs []string
+		// Postgres Repo Args
+		policyIDs []string
+		groupID   string
+		// Expected result
+		expectedRelations int
+		expectedError     *database.Error
+	}{
+		"OkCase": {
+			policyIDs:         []string{"PolicyID1", "PolicyID2"},
+			groupID:           "GroupID",
+			expectedRelations: 1,
+		},
+		"OkCaseExpiredRelation": {
+			previousExpiredPolicyIDs: []string{"PolicyID2"},
+			policyIDs:                []string{"PolicyID1", "PolicyID2"},
+			groupID:                  "GroupID",
+			expectedRelations:        1,
+		},
+		"ErrorCaseInternalError": {
+			policyIDs: []string{"PolicyID1", "PolicyID2"},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: null value in column group_id violates not-null constraint",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupPolicyRelation database
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		for _, policyID := range test.previousExpiredPolicyIDs {
+			if err := insertScheduledGroupPolicyRelation(test.groupID, policyID, 0,
+				time.Now().UTC().Add(-time.Hour).UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store policy relations
+		err := repoDB.AttachPolicies(test.groupID, test.policyIDs)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		for _, policyID := range test.policyIDs {
+			relations, err := getGroupPolicyRelationCount(policyID, test.groupID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			if relations != test.expectedRelations {
+				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_DetachPolicies(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousPolicyIDs []string
+		// Postgres Repo Args
+		policyIDs []string
+		groupID   string
+	}{
+		"OkCase": {
+			previousPolicyIDs: []string{"PolicyID1", "PolicyID2", "PolicyID3"},
+			policyIDs:         []string{"PolicyID1", "PolicyID2"},
+			groupID:           "GroupID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupPolicyRelation database
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		for _, policyID := range test.previousPolicyIDs {
+			if err := insertGroupPolicyRelation(test.groupID, policyID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove policy relations
+		err := repoDB.DetachPolicies(test.groupID, test.policyIDs)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		for _, policyID := range test.previousPolicyIDs {
+			relations, err := getGroupPolicyRelationCount(policyID, test.groupID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			expectedRelations := 1
+			for _, detachedID := range test.policyIDs {
+				if detachedID == policyID {
+					expectedRelations = 0
+				}
+			}
+			if relations != expectedRelations {
+				t.Errorf("Test %v failed. Received different relations number for policy %v: %v", n, policyID, relations)
+				continue
+			}
+		}
+	}
+}
+
 func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// Previous data
@@ -1144,6 +1747,7 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 			group_id  string
 			policy_id string
 		}
+		notAfter int64
 		// Postgres Repo Args
 		groupID  string
 		policyID string
@@ -1174,6 +1778,32 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 			policyID:       "PolicyIDXXXXXXX",
 			expectedResult: false,
 		},
+		"OkCaseAttachmentNotExpiredYet": {
+			relation: &struct {
+				group_id  string
+				policy_id string
+			}{
+				group_id:  "GroupID",
+				policy_id: "PolicyID",
+			},
+			notAfter:       time.Now().UTC().Add(time.Hour).UnixNano(),
+			groupID:        "GroupID",
+			policyID:       "PolicyID",
+			expectedResult: true,
+		},
+		"OkCaseExpiredAttachment": {
+			relation: &struct {
+				group_id  string
+				policy_id string
+			}{
+				group_id:  "GroupID",
+				policy_id: "PolicyID",
+			},
+			notAfter:       time.Now().UTC().Add(-time.Hour).UnixNano(),
+			groupID:        "GroupID",
+			policyID:       "PolicyID",
+			expectedResult: false,
+		},
 	}
 
 	for n, test := range testcases {
@@ -1182,7 +1812,7 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 
 		// Insert previous data
 		if test.relation != nil {
-			if err := insertGroupPolicyRelation(test.relation.group_id, test.relation.policy_id); err != nil {
+			if err := insertScheduledGroupPolicyRelation(test.relation.group_id, test.relation.policy_id, 0, test.notAfter); err != nil {
 				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
 				continue
 			}
@@ -1205,18 +1835,22 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 
 func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 	now := time.Now().UTC()
+	notBefore := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
 		// Previous data
 		relations *struct {
 			policies       []api.Policy
 			group_id       string
 			policyNotFound bool
+			notBefore      int64
+			notAfter       int64
 		}
 		statements []Statement
 		// Postgres Repo Args
 		groupID string
 		// Expected result
-		expectedResponse []api.Policy
+		expectedResponse []api.GroupPolicy
 		expectedError    *database.Error
 	}{
 		"OkCase": {
@@ -1224,6 +1858,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 				policies       []api.Policy
 				group_id       string
 				policyNotFound bool
+				notBefore      int64
+				notAfter       int64
 			}{
 				policies: []api.Policy{
 					{
@@ -1232,6 +1868,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 						Org:      "org1",
 						Path:     "/path/",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Urn:      "Urn1",
 					},
 					{
@@ -1240,6 +1878,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 						Org:      "org1",
 						Path:     "/path/",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Urn:      "Urn2",
 					},
 				},
@@ -1247,24 +1887,101 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 			},
 			statements: []Statement{},
 			groupID:    "GroupID",
-			expectedResponse: []api.Policy{
+			expectedResponse: []api.GroupPolicy{
 				{
-					ID:         "PolicyID1",
-					Name:       "Name1",
-					Org:        "org1",
-					Path:       "/path/",
-					CreateAt:   now,
-					Urn:        "Urn1",
-					Statements: &[]api.Statement{},
+					Policy: api.Policy{
+						ID:         "PolicyID1",
+						Name:       "Name1",
+						Org:        "org1",
+						Path:       "/path/",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+						Urn:        "Urn1",
+						Statements: &[]api.Statement{},
+					},
 				},
 				{
-					ID:         "PolicyID2",
-					Name:       "Name2",
-					Org:        "org1",
-					Path:       "/path/",
-					CreateAt:   now,
-					Urn:        "Urn2",
-					Statements: &[]api.Statement{},
+					Policy: api.Policy{
+						ID:         "PolicyID2",
+						Name:       "Name2",
+						Org:        "org1",
+						Path:       "/path/",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+						Urn:        "Urn2",
+						Statements: &[]api.Statement{},
+					},
+				},
+			},
+		},
+		"OkCaseWindow": {
+			relations: &struct {
+				policies       []api.Policy
+				group_id       string
+				policyNotFound bool
+				notBefore      int64
+				notAfter       int64
+			}{
+				policies: []api.Policy{
+					{
+						ID:       "PolicyID1",
+						Name:     "Name1",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Urn:      "Urn1",
+					},
+					{
+						ID:       "PolicyID2",
+						Name:     "Name2",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Urn:      "Urn2",
+					},
+				},
+				group_id:  "GroupID",
+				notBefore: notBefore.UnixNano(),
+				notAfter:  notAfter.UnixNano(),
+			},
+			statements: []Statement{},
+			groupID:    "GroupID",
+			expectedResponse: []api.GroupPolicy{
+				{
+					Policy: api.Policy{
+						ID:         "PolicyID1",
+						Name:       "Name1",
+						Org:        "org1",
+						Path:       "/path/",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+						Urn:        "Urn1",
+						Statements: &[]api.Statement{},
+					},
+					NotBefore: &notBefore,
+					NotAfter:  &notAfter,
+				},
+				{
+					Policy: api.Policy{
+						ID:         "PolicyID2",
+						Name:       "Name2",
+						Org:        "org1",
+						Path:       "/path/",
+						CreateAt:   now,
+						UpdateAt:   now,
+						Version:    1,
+						Urn:        "Urn2",
+						Statements: &[]api.Statement{},
+					},
+					NotBefore: &notBefore,
+					NotAfter:  &notAfter,
 				},
 			},
 		},
@@ -1273,6 +1990,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 				policies       []api.Policy
 				group_id       string
 				policyNotFound bool
+				notBefore      int64
+				notAfter       int64
 			}{
 				policies: []api.Policy{
 					{
@@ -1281,6 +2000,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 						Org:      "org1",
 						Path:     "/path/",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Urn:      "Urn1",
 					},
 					{
@@ -1289,6 +2010,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 						Org:      "org1",
 						Path:     "/path/",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Urn:      "Urn2",
 					},
 				},
@@ -1311,7 +2034,8 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 		// Insert previous data
 		if test.relations != nil {
 			for _, policy := range test.relations.policies {
-				if err := insertGroupPolicyRelation(test.relations.group_id, policy.ID); err != nil {
+				if err := insertScheduledGroupPolicyRelation(test.relations.group_id, policy.ID,
+					test.relations.notBefore, test.relations.notAfter); err != nil {
 					t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
 					continue
 				}
@@ -1350,3 +2074,532 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 		}
 	}
 }
+
+func TestPostgresRepo_GetDeletedGroupByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousGroup *api.Group
+		deleted       bool
+		// Postgres Repo Args
+		org  string
+		name string
+		// Expected result
+		expectedResponse *api.Group
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousGroup: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "Org",
+			},
+			deleted: true,
+			org:     "Org",
+			name:    "Name",
+			expectedResponse: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "Org",
+			},
+		},
+		"ErrorCaseGroupNotDeleted": {
+			previousGroup: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "Org",
+			},
+			org:  "Org",
+			name: "Name",
+			expectedError: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Deleted group with organization Org and name Name not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group database
+		cleanGroupTable()
+
+		// Insert previous data
+		if err := insertGroup(test.previousGroup.ID, test.previousGroup.Name, test.previousGroup.Path,
+			test.previousGroup.CreateAt.UnixNano(), test.previousGroup.Urn, test.previousGroup.Org); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		if test.deleted {
+			if err := markAsDeleted(Group{}.TableName(), test.previousGroup.ID, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking group as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get deleted group
+		receivedGroup, err := repoDB.GetDeletedGroupByName(test.org, test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedGroup, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetDeletedGroups(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousGroups  []api.Group
+		deletedGroupIDs []string
+		// Postgres Repo Args
+		org        string
+		pathPrefix string
+		// Expected result
+		expectedResponse []api.Group
+	}{
+		"OkCase": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path123",
+					Urn:      "urn1",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+				{
+					ID:       "GroupID3",
+					Name:     "Name3",
+					Path:     "Path456",
+					Urn:      "urn3",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "OtherOrg",
+				},
+			},
+			deletedGroupIDs: []string{"GroupID2", "GroupID3"},
+			org:             "Org",
+			pathPrefix:      "Path",
+			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean group database
+		cleanGroupTable()
+
+		// Insert previous data
+		for _, g := range test.previousGroups {
+			if err := insertGroup(g.ID, g.Name, g.Path, g.CreateAt.UnixNano(), g.Urn, g.Org); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous groups: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.deletedGroupIDs {
+			if err := markAsDeleted(Group{}.TableName(), id, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking group as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get deleted groups
+		receivedGroups, err := repoDB.GetDeletedGroups(test.org, test.pathPrefix)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedGroups, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RestoreGroup(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousGroup *api.Group
+		userIDs       []string
+		policyIDs     []string
+		// Postgres Repo Args
+		groupToRestore string
+	}{
+		"OkCase": {
+			previousGroup: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "Org",
+			},
+			userIDs:        []string{"UserID1", "UserID2"},
+			policyIDs:      []string{"PolicyID"},
+			groupToRestore: "GroupID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		if err := insertGroup(test.previousGroup.ID, test.previousGroup.Name, test.previousGroup.Path,
+			test.previousGroup.CreateAt.UnixNano(), test.previousGroup.Urn, test.previousGroup.Org); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+			continue
+		}
+		for _, id := range test.userIDs {
+			if err := insertGroupUserRelation(id, test.previousGroup.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.policyIDs {
+			if err := insertGroupPolicyRelation(test.previousGroup.ID, id); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+		if err := markAsDeleted(Group{}.TableName(), test.previousGroup.ID, now.UnixNano()); err != nil {
+			t.Errorf("Test %v failed. Unexpected error marking group as deleted: %v", n, err)
+			continue
+		}
+
+		// Call to repository to restore group
+		if err := repoDB.RestoreGroup(test.groupToRestore); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		deleteAt, err := getDeleteAt(Group{}.TableName(), test.groupToRestore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving group: %v", n, err)
+			continue
+		}
+		if deleteAt != 0 {
+			t.Errorf("Test %v failed. Group is still marked as deleted: %v", n, deleteAt)
+			continue
+		}
+		members, err := getGroupUserRelations(test.groupToRestore, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if members != len(test.userIDs) {
+			t.Errorf("Test %v failed. Received different members number: %v", n, members)
+			continue
+		}
+		attachments, err := getGroupPolicyRelationCount("", test.groupToRestore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if attachments != len(test.policyIDs) {
+			t.Errorf("Test %v failed. Received different attachments number: %v", n, attachments)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_PurgeGroups(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousGroups []api.Group
+		deleteAt       map[string]int64
+		// Postgres Repo Args
+		deletedBefore time.Time
+		// Expected result
+		expectedPurged    int64
+		expectedRelations int
+	}{
+		"OkCase": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path",
+					Urn:      "urn1",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path",
+					Urn:      "urn2",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+			},
+			deleteAt: map[string]int64{
+				"GroupID1": now.Add(-2 * time.Hour).UnixNano(),
+				"GroupID2": now.UnixNano(),
+			},
+			deletedBefore:     now.Add(-time.Hour),
+			expectedPurged:    1,
+			expectedRelations: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		for _, g := range test.previousGroups {
+			if err := insertGroup(g.ID, g.Name, g.Path, g.CreateAt.UnixNano(), g.Urn, g.Org); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous groups: %v", n, err)
+				continue
+			}
+			if err := insertGroupUserRelation("UserID", g.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+			if err := insertGroupPolicyRelation(g.ID, "PolicyID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+		for id, deleteAt := range test.deleteAt {
+			if err := markAsDeleted(Group{}.TableName(), id, deleteAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking group as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to purge groups
+		purged, err := repoDB.PurgeGroups(test.deletedBefore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if purged != test.expectedPurged {
+			t.Errorf("Test %v failed. Received different purged number: %v", n, purged)
+			continue
+		}
+
+		// Check database
+		groupNumber, err := getGroupsCountFiltered("", "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			continue
+		}
+		if groupNumber != len(test.previousGroups)-int(test.expectedPurged) {
+			t.Errorf("Test %v failed. Received different group number: %v", n, groupNumber)
+			continue
+		}
+		members, err := getGroupUserRelations("", "UserID")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if members != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different members number: %v", n, members)
+			continue
+		}
+		attachments, err := getGroupPolicyRelationCount("PolicyID", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if attachments != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different attachments number: %v", n, attachments)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveExpiredMembers(t *testing.T) {
+	now := time.Now().UTC()
+	expiredAt := now.Add(-time.Hour).UnixNano()
+	testcases := map[string]struct {
+		// Previous data
+		previousUsers []api.User
+		previousGroup api.Group
+		expireAt      map[string]int64
+		// Postgres Repo Args
+		expiredBefore time.Time
+		// Expected result
+		expectedResponse  []api.ExpiredMember
+		expectedRelations map[string]int
+	}{
+		"OkCase": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn1",
+					CreateAt:   now,
+					UpdateAt:   now,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn2",
+					CreateAt:   now,
+					UpdateAt:   now,
+				},
+				{
+					ID:         "UserID3",
+					ExternalID: "ExternalID3",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn3",
+					CreateAt:   now,
+					UpdateAt:   now,
+				},
+			},
+			previousGroup: api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				UpdateAt: now,
+				Org:      "Org",
+			},
+			expireAt: map[string]int64{
+				"UserID1": expiredAt,
+				"UserID2": now.Add(time.Hour).UnixNano(),
+			},
+			expiredBefore: now,
+			expectedResponse: []api.ExpiredMember{
+				{
+					User:     "ExternalID1",
+					Org:      "Org",
+					Group:    "Name",
+					ExpireAt: time.Unix(0, expiredAt).UTC(),
+				},
+			},
+			expectedRelations: map[string]int{
+				"UserID1": 0,
+				"UserID2": 1,
+				"UserID3": 1,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		group := test.previousGroup
+		if err := insertGroup(group.ID, group.Name, group.Path, group.CreateAt.UnixNano(), group.Urn, group.Org); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		for _, user := range test.previousUsers {
+			if err := insertUser(user.ID, user.ExternalID, user.Path, user.CreateAt.UnixNano(), user.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+			if err := insertExpiringGroupUserRelation(user.ID, group.ID, test.expireAt[user.ID]); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove expired members
+		expiredMembers, err := repoDB.RemoveExpiredMembers(test.expiredBefore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check response
+		if diff := pretty.Compare(expiredMembers, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// Check database
+		for userID, expectedRelations := range test.expectedRelations {
+			relations, err := getGroupUserRelations(group.ID, userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			if relations != expectedRelations {
+				t.Errorf("Test %v failed. Received different relations number for user %v: %v", n, userID, relations)
+				continue
+			}
+		}
+	}
+}
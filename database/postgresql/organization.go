@@ -0,0 +1,204 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ORGANIZATION REPOSITORY IMPLEMENTATION
+
+func (o PostgresRepo) AddOrganization(org api.Organization) (*api.Organization, error) {
+
+	// Create organization model
+	orgDB := &Organization{
+		ID:       org.ID,
+		Name:     org.Name,
+		CreateAt: org.CreateAt.UnixNano(),
+		Urn:      org.Urn,
+	}
+
+	// Store organization
+	err := o.Dbmap.Create(orgDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbOrganizationToAPIOrganization(orgDB), nil
+}
+
+func (o PostgresRepo) GetOrganizationByName(name string) (*api.Organization, error) {
+	org := &Organization{}
+	query := o.Dbmap.Where("name = ?", name).First(org)
+
+	// Check if organization exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ORGANIZATION_NOT_FOUND,
+			Message: fmt.Sprintf("Organization with name %v not found", name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbOrganizationToAPIOrganization(org), nil
+}
+
+func (o PostgresRepo) GetOrganizations() ([]api.Organization, error) {
+	orgs := []Organization{}
+
+	// Error handling
+	if err := o.Dbmap.Find(&orgs).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform organizations for API
+	if orgs != nil {
+		apiOrgs := make([]api.Organization, len(orgs), cap(orgs))
+		for i, org := range orgs {
+			apiOrgs[i] = *dbOrganizationToAPIOrganization(&org)
+		}
+		return apiOrgs, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (o PostgresRepo) RemoveOrganization(name string) error {
+	transaction := o.Dbmap.Begin()
+
+	// Delete relations and access requests of organization groups and policies
+	groupsOfOrg := "SELECT id FROM " + Group{}.TableName() + " WHERE org = ?"
+	policiesOfOrg := "SELECT id FROM " + Policy{}.TableName() + " WHERE org = ?"
+	if err := transaction.Where("group_id IN ("+groupsOfOrg+")", name).Delete(&GroupUserRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if err := transaction.Where("group_id IN ("+groupsOfOrg+") OR policy_id IN ("+policiesOfOrg+")", name, name).Delete(&GroupPolicyRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	if err := transaction.Where("group_id IN ("+groupsOfOrg+")", name).Delete(&AccessRequest{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete policy statements
+	if err := transaction.Where("policy_id IN ("+policiesOfOrg+")", name).Delete(&Statement{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete groups and policies
+	if err := transaction.Where("org = ?", name).Delete(&Group{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if err := transaction.Where("org = ?", name).Delete(&Policy{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete group mappings
+	if err := transaction.Where("org = ?", name).Delete(&GroupMapping{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete organization
+	if err := transaction.Where("name = ?", name).Delete(&Organization{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Create organization with name if it doesn't exist yet, so organizations of groups and policies are always stored
+// Create the organization of a group or policy if it doesn't exist yet
+func addMissingOrganization(db *gorm.DB, name string) error {
+	return db.Exec("INSERT INTO "+Organization{}.TableName()+" (id, name, create_at, urn) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		uuid.NewV4().String(), name, time.Now().UTC().UnixNano(), api.CreateUrn(name, api.RESOURCE_ORGANIZATION, "/", name)).Error
+}
+
+// Create organizations of groups and policies stored before organizations were added
+func addMissingOrganizations(db *gorm.DB) error {
+	rows, err := db.Raw("SELECT org FROM " + Group{}.TableName() + " UNION SELECT org FROM " + Policy{}.TableName()).Rows()
+	if err != nil {
+		return err
+	}
+	orgs := []string{}
+	for rows.Next() {
+		var org string
+		if err := rows.Scan(&org); err != nil {
+			rows.Close()
+			return err
+		}
+		orgs = append(orgs, org)
+	}
+	rows.Close()
+
+	for _, org := range orgs {
+		if err := addMissingOrganization(db, org); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transform an Organization retrieved from db into an organization for API
+func dbOrganizationToAPIOrganization(orgdb *Organization) *api.Organization {
+	return &api.Organization{
+		ID:       orgdb.ID,
+		Name:     orgdb.Name,
+		CreateAt: time.Unix(0, orgdb.CreateAt).UTC(),
+		Urn:      orgdb.Urn,
+	}
+}
@@ -0,0 +1,519 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrg *api.Organization
+		// Postgres Repo Args
+		orgToCreate *api.Organization
+		// Expected result
+		expectedResponse *api.Organization
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			orgToCreate: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			expectedResponse: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+		},
+		"ErrorCaseOrganizationAlreadyExist": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			orgToCreate: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"organizations_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if test.previousOrg != nil {
+			if err := insertOrganization(test.previousOrg.ID, test.previousOrg.Name,
+				test.previousOrg.CreateAt.UnixNano(), test.previousOrg.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store organization
+		receivedOrg, err := repoDB.AddOrganization(*test.orgToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedOrg, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			orgNumber, err := getOrganizationsCountFiltered(test.orgToCreate.ID, test.orgToCreate.Name)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != 1 {
+				t.Errorf("Test %v failed. Received different organization number: %v", n, orgNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetOrganizationByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrg *api.Organization
+		// Postgres Repo Args
+		name string
+		// Expected result
+		expectedResponse *api.Organization
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			name: "Name",
+			expectedResponse: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+		},
+		"ErrorCaseOrganizationNotExist": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Name",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			name: "NotExist",
+			expectedError: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name NotExist not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if test.previousOrg != nil {
+			if err := insertOrganization(test.previousOrg.ID, test.previousOrg.Name,
+				test.previousOrg.CreateAt.UnixNano(), test.previousOrg.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get organization
+		receivedOrg, err := repoDB.GetOrganizationByName(test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedOrg, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrg      *api.Organization
+		previousOtherOrg *api.Organization
+		previousGroups   []api.Group
+		previousPolicy   *api.Policy
+		// Postgres Repo Args
+		orgToDelete string
+		// Expected result
+		expectedGroupsInOtherOrgs int
+	}{
+		"OkCase": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Org",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "GroupUrn",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+				{
+					ID:       "OtherGroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "OtherGroupUrn",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "OtherOrg",
+				},
+			},
+			previousPolicy: &api.Policy{
+				ID:       "PolicyID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "PolicyUrn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "Org",
+			},
+			orgToDelete:               "Org",
+			expectedGroupsInOtherOrgs: 1,
+		},
+		"OkCaseOrgNameWithWildcard": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "a_b",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			previousOtherOrg: &api.Organization{
+				ID:       "OtherOrgID",
+				Name:     "aXb",
+				Urn:      "OtherUrn",
+				CreateAt: now,
+			},
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "GroupUrn",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "a_b",
+				},
+				{
+					ID:       "OtherGroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "OtherGroupUrn",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "aXb",
+				},
+			},
+			previousPolicy: &api.Policy{
+				ID:       "PolicyID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "PolicyUrn",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Org:      "a_b",
+			},
+			orgToDelete:               "a_b",
+			expectedGroupsInOtherOrgs: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		cleanOrganizationTable()
+		cleanGroupTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		if err := insertOrganization(test.previousOrg.ID, test.previousOrg.Name,
+			test.previousOrg.CreateAt.UnixNano(), test.previousOrg.Urn); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous organization: %v", n, err)
+			continue
+		}
+		if test.previousOtherOrg != nil {
+			if err := insertOrganization(test.previousOtherOrg.ID, test.previousOtherOrg.Name,
+				test.previousOtherOrg.CreateAt.UnixNano(), test.previousOtherOrg.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous organization: %v", n, err)
+				continue
+			}
+		}
+		for _, g := range test.previousGroups {
+			if err := insertGroup(g.ID, g.Name, g.Path, g.CreateAt.UnixNano(), g.Urn, g.Org); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+				continue
+			}
+			if err := insertGroupUserRelation("UserID", g.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relation: %v", n, err)
+				continue
+			}
+		}
+		statements := []Statement{
+			{
+				ID:        "StatementID",
+				PolicyID:  test.previousPolicy.ID,
+				Effect:    "allow",
+				Actions:   "iam:*",
+				Resources: "urn:everything:*",
+			},
+		}
+		if err := insertPolicy(test.previousPolicy.ID, test.previousPolicy.Name, test.previousPolicy.Org, test.previousPolicy.Path,
+			test.previousPolicy.CreateAt.UnixNano(), test.previousPolicy.Urn, statements); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous policy: %v", n, err)
+			continue
+		}
+		if err := insertGroupPolicyRelation("GroupID", test.previousPolicy.ID); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group policy relation: %v", n, err)
+			continue
+		}
+
+		// Call to repository to remove organization
+		if err := repoDB.RemoveOrganization(test.orgToDelete); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		orgNumber, err := getOrganizationsCountFiltered("", test.orgToDelete)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+			continue
+		}
+		if orgNumber != 0 {
+			t.Errorf("Test %v failed. Received different organization number: %v", n, orgNumber)
+			continue
+		}
+		if test.previousOtherOrg != nil {
+			orgNumber, err = getOrganizationsCountFiltered(test.previousOtherOrg.ID, test.previousOtherOrg.Name)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != 1 {
+				t.Errorf("Test %v failed. Received different number of other organizations: %v", n, orgNumber)
+				continue
+			}
+		}
+		groupNumber, err := getGroupsCountFiltered("", "", "", 0, "", test.orgToDelete)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			continue
+		}
+		if groupNumber != 0 {
+			t.Errorf("Test %v failed. Received different group number: %v", n, groupNumber)
+			continue
+		}
+		groupNumber, err = getGroupsCountFiltered("", "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			continue
+		}
+		if groupNumber != test.expectedGroupsInOtherOrgs {
+			t.Errorf("Test %v failed. Received different group number in other organizations: %v", n, groupNumber)
+			continue
+		}
+		policyNumber, err := getPoliciesCountFiltered("", test.orgToDelete, "", "", 0, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting policies: %v", n, err)
+			continue
+		}
+		if policyNumber != 0 {
+			t.Errorf("Test %v failed. Received different policy number: %v", n, policyNumber)
+			continue
+		}
+		statementNumber, err := getStatementsCountFiltered("", test.previousPolicy.ID, "", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
+			continue
+		}
+		if statementNumber != 0 {
+			t.Errorf("Test %v failed. Received different statement number: %v", n, statementNumber)
+			continue
+		}
+		relations, err := getGroupUserRelations("", "UserID")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != test.expectedGroupsInOtherOrgs {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+		attachments, err := getGroupPolicyRelationCount(test.previousPolicy.ID, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting attachments: %v", n, err)
+			continue
+		}
+		if attachments != 0 {
+			t.Errorf("Test %v failed. Received different attachments number: %v", n, attachments)
+			continue
+		}
+	}
+}
+
+func TestAddMissingOrganizations(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrg       *api.Organization
+		previousGroupOrg  string
+		previousPolicyOrg string
+		// Expected result
+		expectedOrgs map[string]int
+	}{
+		"OkCase": {
+			previousGroupOrg:  "GroupOrg",
+			previousPolicyOrg: "PolicyOrg",
+			expectedOrgs: map[string]int{
+				"GroupOrg":  1,
+				"PolicyOrg": 1,
+			},
+		},
+		"OkCaseSameOrganization": {
+			previousGroupOrg:  "Org",
+			previousPolicyOrg: "Org",
+			expectedOrgs: map[string]int{
+				"Org": 1,
+			},
+		},
+		"OkCaseExistingOrganization": {
+			previousOrg: &api.Organization{
+				ID:       "OrgID",
+				Name:     "Org",
+				Urn:      "Urn",
+				CreateAt: now,
+			},
+			previousGroupOrg:  "Org",
+			previousPolicyOrg: "PolicyOrg",
+			expectedOrgs: map[string]int{
+				"Org":       1,
+				"PolicyOrg": 1,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		cleanOrganizationTable()
+		cleanGroupTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+
+		// Insert previous data
+		if test.previousOrg != nil {
+			if err := insertOrganization(test.previousOrg.ID, test.previousOrg.Name,
+				test.previousOrg.CreateAt.UnixNano(), test.previousOrg.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous organization: %v", n, err)
+				continue
+			}
+		}
+		if err := insertGroup("GroupID", "Name", "Path", now.UnixNano(), "GroupUrn", test.previousGroupOrg); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+			continue
+		}
+		if err := insertPolicy("PolicyID", "Name", test.previousPolicyOrg, "Path", now.UnixNano(), "PolicyUrn", []Statement{}); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous policy: %v", n, err)
+			continue
+		}
+
+		// Call to create missing organizations
+		if err := addMissingOrganizations(repoDB.Dbmap); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		for org, expected := range test.expectedOrgs {
+			orgNumber, err := getOrganizationsCountFiltered("", org)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != expected {
+				t.Errorf("Test %v failed. Received different organization number for %v: %v", n, org, orgNumber)
+				continue
+			}
+		}
+		if test.previousOrg != nil {
+			orgNumber, err := getOrganizationsCountFiltered(test.previousOrg.ID, test.previousOrg.Name)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != 1 {
+				t.Errorf("Test %v failed. Previous organization was replaced: %v", n, orgNumber)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// PASSWORD REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) SetUserCredentials(userID string, hash string) error {
+	now := time.Now().UTC().UnixNano()
+	transaction := r.Dbmap.Begin()
+
+	// Replace previous credentials clearing failed attempts
+	query := transaction.Model(&UserCredentials{}).Where("user_id like ?", userID).Updates(map[string]interface{}{
+		"hash":            hash,
+		"failed_attempts": 0,
+		"locked_until":    0,
+		"update_at":       now,
+	})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Create credentials if user didn't have them
+	if query.RowsAffected == 0 {
+		credentialsDB := &UserCredentials{
+			UserID:   userID,
+			Hash:     hash,
+			CreateAt: now,
+			UpdateAt: now,
+		}
+		if err := transaction.Create(credentialsDB).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	// Sessions of previous password stop working
+	if err := transaction.Where("user_id like ?", userID).Delete(&Session{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r PostgresRepo) GetUserCredentials(userID string) (*api.UserCredentials, error) {
+	credentials := &UserCredentials{}
+	query := r.Dbmap.Where("user_id like ?", userID).First(credentials)
+
+	// Check if credentials exist
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.USER_CREDENTIALS_NOT_FOUND,
+			Message: fmt.Sprintf("Credentials of user with id %v not found", userID),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return &api.UserCredentials{
+		UserID:         credentials.UserID,
+		Hash:           credentials.Hash,
+		FailedAttempts: credentials.FailedAttempts,
+		LockedUntil:    dbOptionalTimeToAPITime(credentials.LockedUntil),
+	}, nil
+}
+
+func (r PostgresRepo) UpdateUserCredentialsAttempts(userID string, failedAttempts int, lockedUntil *time.Time) error {
+	update := map[string]interface{}{
+		"failed_attempts": failedAttempts,
+		"locked_until":    apiOptionalTimeToDBTime(lockedUntil),
+	}
+
+	// Update credentials
+	query := r.Dbmap.Model(&UserCredentials{}).Where("user_id like ?", userID).Updates(update)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if credentials existed
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.USER_CREDENTIALS_NOT_FOUND,
+			Message: fmt.Sprintf("Credentials of user with id %v not found", userID),
+		}
+	}
+
+	return nil
+}
+
+func (r PostgresRepo) IncrementUserCredentialsAttempts(userID string, maxFailedAttempts int, lockedUntil time.Time) (*api.UserCredentials, error) {
+	credentials, err := incrementFailedAttempts(r, UserCredentials{}.TableName(), userID, maxFailedAttempts, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		return nil, &database.Error{
+			Code:    database.USER_CREDENTIALS_NOT_FOUND,
+			Message: fmt.Sprintf("Credentials of user with id %v not found", userID),
+		}
+	}
+	return credentials, nil
+}
+
+func (r PostgresRepo) AddSession(session api.Session, hash string) (*api.Session, error) {
+
+	// Create session model
+	sessionDB := &Session{
+		ID:       session.ID,
+		UserID:   session.UserID,
+		Hash:     hash,
+		ExpireAt: session.ExpireAt.UTC().UnixNano(),
+		CreateAt: session.CreateAt.UTC().UnixNano(),
+	}
+
+	// Store session
+	err := r.Dbmap.Create(sessionDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbSessionToAPISession(sessionDB), nil
+}
+
+func (r PostgresRepo) GetSessionByHash(hash string) (*api.Session, error) {
+	session := &Session{}
+	query := r.Dbmap.Where("hash = ?", hash).First(session)
+
+	// Check if session exists, hash isn't included in the message
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.SESSION_NOT_FOUND,
+			Message: "Session not found",
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbSessionToAPISession(session), nil
+}
+
+func (r PostgresRepo) RemoveSession(id string) error {
+	// Delete session
+	query := r.Dbmap.Where("id like ?", id).Delete(&Session{})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if session existed
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.SESSION_NOT_FOUND,
+			Message: fmt.Sprintf("Session with id %v not found", id),
+		}
+	}
+
+	return nil
+}
+
+func (r PostgresRepo) RemoveExpiredSessions(expiredBefore time.Time) (int64, error) {
+	// Delete expired sessions
+	query := r.Dbmap.Where("expire_at < ?", expiredBefore.UTC().UnixNano()).Delete(&Session{})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return query.RowsAffected, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Increase failed attempts of credentials stored in table in a single update, so concurrent attempts aren't lost.
+// When they reach the maximum, credentials are locked until lockedUntil and failed attempts are cleared. It returns
+// nil credentials if they don't exist.
+func incrementFailedAttempts(r PostgresRepo, table string, userID string, maxFailedAttempts int,
+	lockedUntil time.Time) (*api.UserCredentials, error) {
+	credentials := UserCredentials{}
+	query := r.Dbmap.Raw("UPDATE "+table+" SET "+
+		"failed_attempts = CASE WHEN ? > 0 AND failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END, "+
+		"locked_until = CASE WHEN ? > 0 AND failed_attempts + 1 >= ? THEN ? ELSE locked_until END "+
+		"WHERE user_id = ? RETURNING user_id, hash, failed_attempts, locked_until",
+		maxFailedAttempts, maxFailedAttempts, maxFailedAttempts, maxFailedAttempts, lockedUntil.UTC().UnixNano(), userID).
+		Scan(&credentials)
+
+	// Check if credentials exist
+	if query.RecordNotFound() {
+		return nil, nil
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return &api.UserCredentials{
+		UserID:         credentials.UserID,
+		Hash:           credentials.Hash,
+		FailedAttempts: credentials.FailedAttempts,
+		LockedUntil:    dbOptionalTimeToAPITime(credentials.LockedUntil),
+	}, nil
+}
+
+// Transform a session retrieved from db into a session for API
+func dbSessionToAPISession(sessiondb *Session) *api.Session {
+	return &api.Session{
+		ID:       sessiondb.ID,
+		UserID:   sessiondb.UserID,
+		ExpireAt: time.Unix(0, sessiondb.ExpireAt).UTC(),
+		CreateAt: time.Unix(0, sessiondb.CreateAt).UTC(),
+	}
+}
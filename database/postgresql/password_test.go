@@ -0,0 +1,432 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_SetUserCredentials(t *testing.T) {
+	lockedUntil := time.Now().UTC().Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousHash     string
+		previousSessions []api.Session
+		// Postgres Repo Args
+		userID string
+		hash   string
+		// Expected result
+		expectedResponse *api.UserCredentials
+	}{
+		"OkCase": {
+			userID: "UserID",
+			hash:   "hash",
+			expectedResponse: &api.UserCredentials{
+				UserID: "UserID",
+				Hash:   "hash",
+			},
+		},
+		"OkCaseReplaceCredentialsAndRemoveSessions": {
+			previousHash: "oldHash",
+			previousSessions: []api.Session{
+				{
+					ID:       "SessionID",
+					UserID:   "UserID",
+					ExpireAt: time.Now().UTC().Add(time.Hour),
+					CreateAt: time.Now().UTC(),
+				},
+			},
+			userID: "UserID",
+			hash:   "hash",
+			expectedResponse: &api.UserCredentials{
+				UserID: "UserID",
+				Hash:   "hash",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean password database
+		cleanUserCredentialsTable()
+		cleanSessionTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetUserCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+			if err := repoDB.UpdateUserCredentialsAttempts(test.userID, 2, &lockedUntil); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous attempts: %v", n, err)
+				continue
+			}
+		}
+		for _, session := range test.previousSessions {
+			if _, err := repoDB.AddSession(session, session.ID+"Hash"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous sessions: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store credentials
+		if err := repoDB.SetUserCredentials(test.userID, test.hash); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		credentials, err := repoDB.GetUserCredentials(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(credentials, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		sessionNumber, err := getSessionsCountFiltered(test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting sessions: %v", n, err)
+			continue
+		}
+		if sessionNumber != 0 {
+			t.Errorf("Test %v failed. Received different session number: %v", n, sessionNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateUserCredentialsAttempts(t *testing.T) {
+	lockedUntil := time.Unix(0, time.Now().UTC().Add(time.Hour).UnixNano()).UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousHash string
+		// Postgres Repo Args
+		userID         string
+		failedAttempts int
+		lockedUntil    *time.Time
+		// Expected result
+		expectedResponse *api.UserCredentials
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousHash:   "hash",
+			userID:         "UserID",
+			failedAttempts: 3,
+			expectedResponse: &api.UserCredentials{
+				UserID:         "UserID",
+				Hash:           "hash",
+				FailedAttempts: 3,
+			},
+		},
+		"OkCaseLockedOut": {
+			previousHash: "hash",
+			userID:       "UserID",
+			lockedUntil:  &lockedUntil,
+			expectedResponse: &api.UserCredentials{
+				UserID:      "UserID",
+				Hash:        "hash",
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"ErrorCaseCredentialsNotExist": {
+			userID:         "UserID",
+			failedAttempts: 1,
+			expectedError: &database.Error{
+				Code:    database.USER_CREDENTIALS_NOT_FOUND,
+				Message: "Credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean password database
+		cleanUserCredentialsTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetUserCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to update failed attempts
+		err := repoDB.UpdateUserCredentialsAttempts(test.userID, test.failedAttempts, test.lockedUntil)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			credentials, err := repoDB.GetUserCredentials(test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(credentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_IncrementUserCredentialsAttempts(t *testing.T) {
+	lockedUntil := time.Unix(0, time.Now().UTC().Add(time.Hour).UnixNano()).UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousHash           string
+		previousFailedAttempts int
+		// Postgres Repo Args
+		userID            string
+		maxFailedAttempts int
+		// Expected result
+		expectedResponse *api.UserCredentials
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousHash:           "hash",
+			previousFailedAttempts: 1,
+			userID:                 "UserID",
+			maxFailedAttempts:      3,
+			expectedResponse: &api.UserCredentials{
+				UserID:         "UserID",
+				Hash:           "hash",
+				FailedAttempts: 2,
+			},
+		},
+		"OkCaseLockedOut": {
+			previousHash:           "hash",
+			previousFailedAttempts: 2,
+			userID:                 "UserID",
+			maxFailedAttempts:      3,
+			expectedResponse: &api.UserCredentials{
+				UserID:      "UserID",
+				Hash:        "hash",
+				LockedUntil: &lockedUntil,
+			},
+		},
+		"OkCaseLockoutDisabled": {
+			previousHash:           "hash",
+			previousFailedAttempts: 5,
+			userID:                 "UserID",
+			expectedResponse: &api.UserCredentials{
+				UserID:         "UserID",
+				Hash:           "hash",
+				FailedAttempts: 6,
+			},
+		},
+		"ErrorCaseCredentialsNotExist": {
+			userID:            "UserID",
+			maxFailedAttempts: 3,
+			expectedError: &database.Error{
+				Code:    database.USER_CREDENTIALS_NOT_FOUND,
+				Message: "Credentials of user with id UserID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean password database
+		cleanUserCredentialsTable()
+
+		// Insert previous data
+		if test.previousHash != "" {
+			if err := repoDB.SetUserCredentials(test.userID, test.previousHash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+			if err := repoDB.UpdateUserCredentialsAttempts(test.userID, test.previousFailedAttempts, nil); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to increment failed attempts
+		credentials, err := repoDB.IncrementUserCredentialsAttempts(test.userID, test.maxFailedAttempts, lockedUntil)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(credentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			storedCredentials, err := repoDB.GetUserCredentials(test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving credentials: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(storedCredentials, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different stored credentials (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetSessionByHash(t *testing.T) {
+	now := time.Unix(0, time.Now().UTC().UnixNano()).UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousSession *api.Session
+		// Postgres Repo Args
+		hash string
+		// Expected result
+		expectedResponse *api.Session
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousSession: &api.Session{
+				ID:       "SessionID",
+				UserID:   "UserID",
+				ExpireAt: now.Add(time.Hour),
+				CreateAt: now,
+			},
+			hash: "hash",
+			expectedResponse: &api.Session{
+				ID:       "SessionID",
+				UserID:   "UserID",
+				ExpireAt: now.Add(time.Hour),
+				CreateAt: now,
+			},
+		},
+		"ErrorCaseSessionNotExist": {
+			hash: "hash",
+			expectedError: &database.Error{
+				Code:    database.SESSION_NOT_FOUND,
+				Message: "Session not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean session database
+		cleanSessionTable()
+
+		// Insert previous data
+		if test.previousSession != nil {
+			if _, err := repoDB.AddSession(*test.previousSession, test.hash); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get session
+		session, err := repoDB.GetSessionByHash(test.hash)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(session, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveExpiredSessions(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousSessions []api.Session
+		// Expected result
+		expectedRemoved  int64
+		expectedSessions int
+	}{
+		"OkCase": {
+			previousSessions: []api.Session{
+				{
+					ID:       "ExpiredSessionID",
+					UserID:   "UserID",
+					ExpireAt: now.Add(-time.Hour),
+					CreateAt: now.Add(-2 * time.Hour),
+				},
+				{
+					ID:       "SessionID",
+					UserID:   "UserID",
+					ExpireAt: now.Add(time.Hour),
+					CreateAt: now,
+				},
+			},
+			expectedRemoved:  1,
+			expectedSessions: 1,
+		},
+		"OkCaseWithoutSessions": {
+			expectedRemoved:  0,
+			expectedSessions: 0,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean session database
+		cleanSessionTable()
+
+		// Insert previous data
+		for _, session := range test.previousSessions {
+			if _, err := repoDB.AddSession(session, session.ID+"Hash"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove expired sessions
+		removed, err := repoDB.RemoveExpiredSessions(now)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if removed != test.expectedRemoved {
+			t.Errorf("Test %v failed. Received different removed sessions: %v", n, removed)
+			continue
+		}
+
+		// Check database
+		sessionNumber, err := getSessionsCountFiltered("")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting sessions: %v", n, err)
+			continue
+		}
+		if sessionNumber != test.expectedSessions {
+			t.Errorf("Test %v failed. Received different session number: %v", n, sessionNumber)
+			continue
+		}
+	}
+}
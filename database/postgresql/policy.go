@@ -15,16 +15,31 @@ import (
 func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 	// Create policy model
 	policyDB := &Policy{
-		ID:       policy.ID,
-		Name:     policy.Name,
-		Path:     policy.Path,
-		CreateAt: policy.CreateAt.UnixNano(),
-		Urn:      policy.Urn,
-		Org:      policy.Org,
+		ID:          policy.ID,
+		Name:        policy.Name,
+		Path:        policy.Path,
+		DisplayName: policy.DisplayName,
+		Description: policy.Description,
+		CreateAt:    policy.CreateAt.UnixNano(),
+		UpdateAt:    policy.UpdateAt.UnixNano(),
+		CreatedBy:   policy.CreatedBy,
+		UpdatedBy:   policy.UpdatedBy,
+		Urn:         policy.Urn,
+		Org:         policy.Org,
+		Version:     1,
 	}
 
 	transaction := p.Dbmap.Begin()
 
+	// Create organization of policy if it's new
+	if err := addMissingOrganization(transaction, policy.Org); err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
 	// Create policy
 	if err := transaction.Create(policyDB).Error; err != nil {
 		transaction.Rollback()
@@ -64,7 +79,7 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 
 func (p PostgresRepo) GetPolicyByName(org string, name string) (*api.Policy, error) {
 	policy := &Policy{}
-	query := p.Dbmap.Where("org like ? AND name like ?", org, name).First(policy)
+	query := p.Dbmap.Where("org like ? AND name like ? AND delete_at = 0", org, name).First(policy)
 
 	// Check if policy exists
 	if query.RecordNotFound() {
@@ -102,7 +117,7 @@ func (p PostgresRepo) GetPolicyByName(org string, name string) (*api.Policy, err
 
 func (p PostgresRepo) GetPolicyById(id string) (*api.Policy, error) {
 	policy := &Policy{}
-	query := p.Dbmap.Where("id like ?", id).First(&policy)
+	query := p.Dbmap.Where("id like ? AND delete_at = 0", id).First(&policy)
 
 	// Check if policy exists
 	if query.RecordNotFound() {
@@ -141,7 +156,7 @@ func (p PostgresRepo) GetPolicyById(id string) (*api.Policy, error) {
 func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.Policy, error) {
 	policies := []Policy{}
 	var apiPolicies []api.Policy
-	query := p.Dbmap
+	query := p.Dbmap.Where("delete_at = 0")
 	if len(org) > 0 {
 		query = query.Where("org like ?", org)
 	}
@@ -186,27 +201,37 @@ func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.
 	return apiPolicies, nil
 }
 
-func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement) (*api.Policy, error) {
-	// Create policy to update
-	policyUpdated := Policy{
-		Name: name,
-		Path: path,
-		Urn:  urn,
-	}
-
+func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, displayName string,
+	description string, updatedBy string, statements []api.Statement) (*api.Policy, error) {
 	policyDB := Policy{
-		ID:       policy.ID,
-		Name:     policy.Name,
-		Path:     policy.Path,
-		CreateAt: policy.CreateAt.UTC().UnixNano(),
-		Urn:      policy.Urn,
-		Org:      policy.Org,
+		ID:          policy.ID,
+		Name:        name,
+		Path:        path,
+		DisplayName: displayName,
+		Description: description,
+		CreateAt:    policy.CreateAt.UTC().UnixNano(),
+		UpdateAt:    time.Now().UTC().UnixNano(),
+		CreatedBy:   policy.CreatedBy,
+		UpdatedBy:   updatedBy,
+		Urn:         urn,
+		Org:         policy.Org,
+		Version:     policy.Version + 1,
 	}
 
 	transaction := p.Dbmap.Begin()
 
-	// Update policy
-	if err := transaction.Model(&policyDB).Update(policyUpdated).Error; err != nil {
+	// Update policy only if it's still in the same version. A map is used to store empty values too
+	query := transaction.Model(&Policy{ID: policy.ID}).Where("version = ?", policy.Version).Updates(map[string]interface{}{
+		"name":         policyDB.Name,
+		"path":         policyDB.Path,
+		"display_name": policyDB.DisplayName,
+		"description":  policyDB.Description,
+		"update_at":    policyDB.UpdateAt,
+		"updated_by":   policyDB.UpdatedBy,
+		"urn":          policyDB.Urn,
+		"version":      policyDB.Version,
+	})
+	if err := query.Error; err != nil {
 		transaction.Rollback()
 		return nil, &database.Error{
 			Code:    database.INTERNAL_ERROR,
@@ -214,6 +239,15 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 		}
 	}
 
+	// Check if policy was modified meanwhile
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("Policy with organization %v and name %v isn't in version %v", policy.Org, policy.Name, policy.Version),
+		}
+	}
+
 	// Clear old statements
 	if err := transaction.Where("policy_id like ?", policy.ID).Delete(Statement{}).Error; err != nil {
 		transaction.Rollback()
@@ -250,45 +284,150 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 	return policyApi, nil
 }
 
-func (p PostgresRepo) RemovePolicy(id string) error {
+func (p PostgresRepo) RemovePolicy(id string, version int64) error {
+	// Mark policy as deleted only if it's still in the same version, statements and group relations are kept to restore them
+	query := p.Dbmap.Model(&Policy{}).Where("id like ? AND version = ? AND delete_at = 0", id, version).
+		UpdateColumn("delete_at", time.Now().UTC().UnixNano())
+
+	// Error handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if policy was modified or deleted meanwhile
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("Policy with id %v isn't in version %v", id, version),
+		}
+	}
+
+	return nil
+}
+
+func (p PostgresRepo) GetDeletedPolicyByName(org string, name string) (*api.Policy, error) {
+	policy := &Policy{}
+	query := p.Dbmap.Where("org like ? AND name like ? AND delete_at > 0", org, name).First(policy)
+
+	// Check if deleted policy exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.POLICY_NOT_FOUND,
+			Message: fmt.Sprintf("Deleted policy with organization %v and name %v not found", org, name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
+	// Retrieve associated statements
+	statements := []Statement{}
+	query = p.Dbmap.Where("policy_id like ?", policy.ID).Find(&statements)
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Create API policy
+	policyApi := dbPolicyToAPIPolicy(policy)
+	policyApi.Statements = dbStatementsToAPIStatements(statements)
+
+	return policyApi, nil
+}
+
+func (p PostgresRepo) GetDeletedPolicies(org string, pathPrefix string) ([]api.Policy, error) {
+	policies := []Policy{}
+	query := p.Dbmap.Where("delete_at > 0")
+	if len(org) > 0 {
+		query = query.Where("org like ?", org)
+	}
+	if len(pathPrefix) > 0 {
+		query = query.Where("path like ?", pathPrefix+"%")
+	}
+
+	// Error handling
+	if err := query.Find(&policies).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform policies for API, statements aren't needed to identify them
+	apiPolicies := make([]api.Policy, len(policies), cap(policies))
+	for i, pol := range policies {
+		apiPolicies[i] = *dbPolicyToAPIPolicy(&pol)
+	}
+
+	return apiPolicies, nil
+}
+
+func (p PostgresRepo) RestorePolicy(id string) error {
+	// Clear deletion mark, so retained group relations are taken into account again
+	err := p.Dbmap.Model(&Policy{}).Where("id like ? AND delete_at > 0", id).UpdateColumn("delete_at", 0).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (p PostgresRepo) PurgePolicies(deletedBefore time.Time) (int64, error) {
 	transaction := p.Dbmap.Begin()
+	deleted := "SELECT id FROM policies WHERE delete_at > 0 AND delete_at < ?"
+	before := deletedBefore.UTC().UnixNano()
 
-	// Delete policy relations (group)
-	transaction.Where("policy_id like ?", id).Delete(&GroupPolicyRelation{})
-	if err := transaction.Error; err != nil {
+	// Delete group relations of purged policies
+	if err := transaction.Where("policy_id IN ("+deleted+")", before).Delete(&GroupPolicyRelation{}).Error; err != nil {
 		transaction.Rollback()
-		return &database.Error{
+		return 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
-	// Delete policy statements
-	transaction.Where("policy_id like ?", id).Delete(&Statement{})
-	if err := transaction.Error; err != nil {
+
+	// Delete statements of purged policies
+	if err := transaction.Where("policy_id IN ("+deleted+")", before).Delete(&Statement{}).Error; err != nil {
 		transaction.Rollback()
-		return &database.Error{
+		return 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
-	//  Delete policy
-	transaction.Where("id like ?", id).Delete(&Policy{})
-	if err := transaction.Error; err != nil {
+
+	// Delete policies
+	query := transaction.Where("delete_at > 0 AND delete_at < ?", before).Delete(&Policy{})
+	if err := query.Error; err != nil {
 		transaction.Rollback()
-		return &database.Error{
+		return 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
 	transaction.Commit()
-	return nil
+	return query.RowsAffected, nil
 }
 
 func (p PostgresRepo) GetAttachedGroups(policyID string) ([]api.Group, error) {
 	relations := []GroupPolicyRelation{}
-	query := p.Dbmap.Where("policy_id like ?", policyID).Find(&relations)
+	query := p.Dbmap.Where("policy_id like ? AND group_id NOT IN (SELECT id FROM groups WHERE delete_at > 0)", policyID).Find(&relations)
 	var groups []api.Group
 	// Error Handling
 	if err := query.Error; err != nil {
@@ -323,12 +462,18 @@ func (p PostgresRepo) GetAttachedGroups(policyID string) ([]api.Group, error) {
 // Transform a policy retrieved from db into a policy for API
 func dbPolicyToAPIPolicy(policydb *Policy) *api.Policy {
 	return &api.Policy{
-		ID:       policydb.ID,
-		Name:     policydb.Name,
-		Path:     policydb.Path,
-		CreateAt: time.Unix(0, policydb.CreateAt).UTC(),
-		Urn:      policydb.Urn,
-		Org:      policydb.Org,
+		ID:          policydb.ID,
+		Name:        policydb.Name,
+		Path:        policydb.Path,
+		DisplayName: policydb.DisplayName,
+		Description: policydb.Description,
+		CreateAt:    time.Unix(0, policydb.CreateAt).UTC(),
+		UpdateAt:    dbUpdateTimeToAPITime(policydb.UpdateAt, policydb.CreateAt),
+		CreatedBy:   policydb.CreatedBy,
+		UpdatedBy:   policydb.UpdatedBy,
+		Urn:         policydb.Urn,
+		Org:         policydb.Org,
+		Version:     policydb.Version,
 	}
 }
 
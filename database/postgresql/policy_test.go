@@ -25,6 +25,8 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -44,6 +46,8 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -65,6 +69,8 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -84,6 +90,8 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -108,6 +116,7 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 		// Clean policy database
 		cleanPolicyTable()
 		cleanStatementTable()
+		cleanOrganizationTable()
 
 		// Call to repository to add a policy
 		if test.previousPolicy != nil {
@@ -148,6 +157,16 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 				t.Errorf("Test %v failed. Received different policies number: %v", n, policyNumber)
 				continue
 			}
+			// Check organization of policy
+			orgNumber, err := getOrganizationsCountFiltered("", test.policy.Org)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if orgNumber != 1 {
+				t.Errorf("Test %v failed. Received different organization number: %v", n, orgNumber)
+				continue
+			}
 			for _, statement := range *test.policy.Statements {
 				statementNumber, err := getStatementsCountFiltered(
 					"",
@@ -206,6 +225,8 @@ func TestPostgresRepo_GetPolicyByName(t *testing.T) {
 				Org:      "org1",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -304,6 +325,8 @@ func TestPostgresRepo_GetPolicyById(t *testing.T) {
 				Org:      "org1",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -404,6 +427,8 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 					Org:      "org1",
 					Path:     "/path/",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 					Statements: &[]api.Statement{
 						{
@@ -460,9 +485,13 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 		name           string
 		path           string
 		urn            string
+		displayName    string
+		description    string
+		updatedBy      string
 		statements     []api.Statement
 		// Expected result
 		expectedResponse *api.Policy
+		expectedError    *database.Error
 	}{
 		"OkCase": {
 			previousPolicy: &api.Policy{
@@ -471,6 +500,8 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -490,6 +521,8 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -503,9 +536,12 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 					},
 				},
 			},
-			name: "newName",
-			path: "/newPath/",
-			urn:  api.CreateUrn("123", api.RESOURCE_POLICY, "/newPath/", "newName"),
+			name:        "newName",
+			path:        "/newPath/",
+			urn:         api.CreateUrn("123", api.RESOURCE_POLICY, "/newPath/", "newName"),
+			displayName: "DisplayName",
+			description: "Description",
+			updatedBy:   "UpdaterID",
 			statements: []api.Statement{
 				{
 					Effect: "allow",
@@ -518,12 +554,59 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 				},
 			},
 			expectedResponse: &api.Policy{
+				ID:          "test1",
+				Name:        "newName",
+				Org:         "123",
+				Path:        "/newPath/",
+				DisplayName: "DisplayName",
+				Description: "Description",
+				CreateAt:    now,
+				UpdatedBy:   "UpdaterID",
+				Version:     2,
+				Urn:         api.CreateUrn("123", api.RESOURCE_POLICY, "/newPath/", "newName"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("123", api.RESOURCE_USER, "/newPath/"),
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			previousPolicy: &api.Policy{
+				ID:       "test1",
+				Name:     "test",
+				Org:      "123",
+				Path:     "/path/",
+				CreateAt: now,
+				UpdateAt: now,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			policy: api.Policy{
 				ID:       "test1",
-				Name:     "newName",
+				Name:     "test",
 				Org:      "123",
-				Path:     "/newPath/",
+				Path:     "/path/",
 				CreateAt: now,
-				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/newPath/", "newName"),
+				UpdateAt: now,
+				Version:  2,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
 						Effect: "allow",
@@ -531,11 +614,29 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 							api.USER_ACTION_GET_USER,
 						},
 						Resources: []string{
-							api.GetUrnPrefix("123", api.RESOURCE_USER, "/newPath/"),
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
 						},
 					},
 				},
 			},
+			name: "newName",
+			path: "/newPath/",
+			urn:  api.CreateUrn("123", api.RESOURCE_POLICY, "/newPath/", "newName"),
+			statements: []api.Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						api.USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						api.GetUrnPrefix("123", api.RESOURCE_USER, "/newPath/"),
+					},
+				},
+			},
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Policy with organization 123 and name test isn't in version 2",
+			},
 		},
 	}
 
@@ -552,11 +653,30 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 				continue
 			}
 		}
-		receivedPolicy, err := repoDB.UpdatePolicy(test.policy, test.name, test.path, test.urn, test.statements)
+		receivedPolicy, err := repoDB.UpdatePolicy(test.policy, test.name, test.path, test.urn, test.displayName,
+			test.description, test.updatedBy, test.statements)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			continue
+		}
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
+		// Update time is set by the repository
+		if receivedPolicy.UpdateAt.Before(now) {
+			t.Errorf("Test %v failed. Received update time %v before %v", n, receivedPolicy.UpdateAt, now)
+			continue
+		}
+		test.expectedResponse.UpdateAt = receivedPolicy.UpdateAt
 		// Check response
 		if diff := pretty.Compare(receivedPolicy, test.expectedResponse); diff != "" {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -570,7 +690,10 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 	testcases := map[string]struct {
 		previousPolicy *api.Policy
 		id             string
+		version        int64
 		group          *api.Group
+		// Expected result
+		expectedError *database.Error
 	}{
 		"OkCase": {
 			previousPolicy: &api.Policy{
@@ -579,6 +702,8 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -592,16 +717,37 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 					},
 				},
 			},
-			id: "test1",
+			id:      "test1",
+			version: 1,
 			group: &api.Group{
 				ID:       "GroupID",
 				Name:     "Name",
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 		},
+		"ErrorCaseVersionMismatch": {
+			previousPolicy: &api.Policy{
+				ID:         "test1",
+				Name:       "test",
+				Org:        "123",
+				Path:       "/path/",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Urn:        api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{},
+			},
+			id:      "test1",
+			version: 2,
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "Policy with id test1 isn't in version 2",
+			},
+		},
 	}
 
 	for n, test := range testcases {
@@ -632,19 +778,39 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 				continue
 			}
 		}
-		err := repoDB.RemovePolicy(test.id)
+		err := repoDB.RemovePolicy(test.id, test.version)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database, policy isn't marked as deleted
+			if _, err := repoDB.GetPolicyById(test.id); err != nil {
+				t.Errorf("Test %v failed. Policy was deleted: %v", n, err)
+			}
+			continue
+		}
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
-		// Check database
-		policyNumber, err := getPoliciesCountFiltered(test.id, "", "", "", 0, "")
+		// Check database, policy is kept marked as deleted
+		deleteAt, err := getDeleteAt(Policy{}.TableName(), test.id)
 		if err != nil {
-			t.Errorf("Test %v failed. Unexpected error counting policies: %v", n, err)
+			t.Errorf("Test %v failed. Unexpected error retrieving policy: %v", n, err)
+			continue
+		}
+		if deleteAt == 0 {
+			t.Errorf("Test %v failed. Policy isn't marked as deleted", n)
 			continue
 		}
-		if policyNumber != 0 {
-			t.Errorf("Test %v failed. Received different policies number: %v", n, policyNumber)
+		if _, err := repoDB.GetPolicyById(test.id); err == nil {
+			t.Errorf("Test %v failed. Deleted policy is still retrieved", n)
 			continue
 		}
 
@@ -658,7 +824,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
 			continue
 		}
-		if statementNumber != 0 {
+		if statementNumber != len(*test.previousPolicy.Statements) {
 			t.Errorf("Test %v failed. Received different statements number: %v", n, statementNumber)
 			continue
 		}
@@ -668,7 +834,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
 			continue
 		}
-		if groupPolicyRelationNumber != 0 {
+		if groupPolicyRelationNumber != 1 {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, groupPolicyRelationNumber)
 			continue
 		}
@@ -689,6 +855,8 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -708,6 +876,8 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Org:      "Org",
 			},
 			expectedResponse: []api.Group{
@@ -717,6 +887,8 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 					Path:     "Path",
 					Urn:      "urn",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org",
 				},
 			},
@@ -784,6 +956,8 @@ func Test_dbPolicyToAPIPolicy(t *testing.T) {
 				Org:      "123",
 				Path:     "/path/",
 				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
 				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
 			},
 		},
@@ -907,3 +1081,420 @@ func Test_stringArrayToString(t *testing.T) {
 		}
 	}
 }
+
+func TestPostgresRepo_GetDeletedPolicyByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		policy     *Policy
+		statements []Statement
+		deleted    bool
+		// Postgres Repo Args
+		org  string
+		name string
+		// Expected result
+		expectedResponse *api.Policy
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			org:  "org1",
+			name: "test",
+			policy: &Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			statements: []Statement{
+				{
+					ID:        "0123",
+					Effect:    "allow",
+					PolicyID:  "1234",
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			},
+			deleted: true,
+			expectedResponse: &api.Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now,
+				UpdateAt: now,
+				Version:  1,
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+		},
+		"ErrorCasePolicyNotDeleted": {
+			org:  "org1",
+			name: "test",
+			policy: &Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			expectedError: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Deleted policy with organization org1 and name test not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean policy database
+		cleanPolicyTable()
+		cleanStatementTable()
+
+		// Insert previous data
+		if test.policy != nil {
+			err := insertPolicy(test.policy.ID, test.policy.Name, test.policy.Org, test.policy.Path, test.policy.CreateAt, test.policy.Urn, test.statements)
+			if err != nil {
+				t.Errorf("Test %v failed. Error inserting policy/statements: %v", n, err)
+				continue
+			}
+			if test.deleted {
+				if err := markAsDeleted(Policy{}.TableName(), test.policy.ID, now.UnixNano()); err != nil {
+					t.Errorf("Test %v failed. Unexpected error marking policy as deleted: %v", n, err)
+					continue
+				}
+			}
+		}
+		// Call to repository to get a deleted policy
+		receivedPolicy, err := repoDB.GetDeletedPolicyByName(test.org, test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedPolicy, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetDeletedPolicies(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousPolicies []Policy
+		deletedPolicyIDs []string
+		// Postgres Repo Args
+		org        string
+		pathPrefix string
+		// Expected result
+		expectedResponse []api.Policy
+	}{
+		"OkCase": {
+			previousPolicies: []Policy{
+				{
+					ID:       "1",
+					Name:     "test1",
+					Org:      "org1",
+					Path:     "/path1/",
+					CreateAt: now.UnixNano(),
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path1/", "test1"),
+				},
+				{
+					ID:       "2",
+					Name:     "test2",
+					Org:      "org1",
+					Path:     "/path2/",
+					CreateAt: now.UnixNano(),
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path2/", "test2"),
+				},
+				{
+					ID:       "3",
+					Name:     "test3",
+					Org:      "org2",
+					Path:     "/path3/",
+					CreateAt: now.UnixNano(),
+					Urn:      api.CreateUrn("org2", api.RESOURCE_POLICY, "/path3/", "test3"),
+				},
+			},
+			deletedPolicyIDs: []string{"2", "3"},
+			org:              "org1",
+			pathPrefix:       "/path",
+			expectedResponse: []api.Policy{
+				{
+					ID:       "2",
+					Name:     "test2",
+					Org:      "org1",
+					Path:     "/path2/",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path2/", "test2"),
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean policy database
+		cleanPolicyTable()
+		cleanStatementTable()
+
+		// Insert previous data
+		for _, p := range test.previousPolicies {
+			if err := insertPolicy(p.ID, p.Name, p.Org, p.Path, p.CreateAt, p.Urn, nil); err != nil {
+				t.Errorf("Test %v failed. Error inserting policy: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.deletedPolicyIDs {
+			if err := markAsDeleted(Policy{}.TableName(), id, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking policy as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get deleted policies
+		receivedPolicies, err := repoDB.GetDeletedPolicies(test.org, test.pathPrefix)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedPolicies, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RestorePolicy(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		policy     *Policy
+		statements []Statement
+		groupIDs   []string
+		// Postgres Repo Args
+		policyToRestore string
+	}{
+		"OkCase": {
+			policy: &Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			statements: []Statement{
+				{
+					ID:        "0123",
+					Effect:    "allow",
+					PolicyID:  "1234",
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			},
+			groupIDs:        []string{"GroupID1", "GroupID2"},
+			policyToRestore: "1234",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		err := insertPolicy(test.policy.ID, test.policy.Name, test.policy.Org, test.policy.Path, test.policy.CreateAt, test.policy.Urn, test.statements)
+		if err != nil {
+			t.Errorf("Test %v failed. Error inserting policy/statements: %v", n, err)
+			continue
+		}
+		for _, id := range test.groupIDs {
+			if err := insertGroupPolicyRelation(id, test.policy.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+		if err := markAsDeleted(Policy{}.TableName(), test.policy.ID, now.UnixNano()); err != nil {
+			t.Errorf("Test %v failed. Unexpected error marking policy as deleted: %v", n, err)
+			continue
+		}
+
+		// Call to repository to restore policy
+		if err := repoDB.RestorePolicy(test.policyToRestore); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		deleteAt, err := getDeleteAt(Policy{}.TableName(), test.policyToRestore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving policy: %v", n, err)
+			continue
+		}
+		if deleteAt != 0 {
+			t.Errorf("Test %v failed. Policy is still marked as deleted: %v", n, deleteAt)
+			continue
+		}
+		statementNumber, err := getStatementsCountFiltered("", test.policyToRestore, "", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
+			continue
+		}
+		if statementNumber != len(test.statements) {
+			t.Errorf("Test %v failed. Received different statements number: %v", n, statementNumber)
+			continue
+		}
+		relations, err := getGroupPolicyRelationCount(test.policyToRestore, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != len(test.groupIDs) {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_PurgePolicies(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousPolicies []Policy
+		deleteAt         map[string]int64
+		// Postgres Repo Args
+		deletedBefore time.Time
+		// Expected result
+		expectedPurged int64
+	}{
+		"OkCase": {
+			previousPolicies: []Policy{
+				{
+					ID:       "1",
+					Name:     "test1",
+					Org:      "org1",
+					Path:     "/path/",
+					CreateAt: now.UnixNano(),
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test1"),
+				},
+				{
+					ID:       "2",
+					Name:     "test2",
+					Org:      "org1",
+					Path:     "/path/",
+					CreateAt: now.UnixNano(),
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test2"),
+				},
+			},
+			deleteAt: map[string]int64{
+				"1": now.Add(-2 * time.Hour).UnixNano(),
+				"2": now.UnixNano(),
+			},
+			deletedBefore:  now.Add(-time.Hour),
+			expectedPurged: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		for _, p := range test.previousPolicies {
+			statements := []Statement{
+				{
+					ID:        "Statement" + p.ID,
+					Effect:    "allow",
+					PolicyID:  p.ID,
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			}
+			if err := insertPolicy(p.ID, p.Name, p.Org, p.Path, p.CreateAt, p.Urn, statements); err != nil {
+				t.Errorf("Test %v failed. Error inserting policy/statements: %v", n, err)
+				continue
+			}
+			if err := insertGroupPolicyRelation("GroupID", p.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+		for id, deleteAt := range test.deleteAt {
+			if err := markAsDeleted(Policy{}.TableName(), id, deleteAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking policy as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to purge policies
+		purged, err := repoDB.PurgePolicies(test.deletedBefore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if purged != test.expectedPurged {
+			t.Errorf("Test %v failed. Received different purged number: %v", n, purged)
+			continue
+		}
+
+		// Check database
+		remaining := len(test.previousPolicies) - int(test.expectedPurged)
+		policyNumber, err := getPoliciesCountFiltered("", "", "", "", 0, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting policies: %v", n, err)
+			continue
+		}
+		if policyNumber != remaining {
+			t.Errorf("Test %v failed. Received different policy number: %v", n, policyNumber)
+			continue
+		}
+		statementNumber, err := getStatementsCountFiltered("", "", "", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
+			continue
+		}
+		if statementNumber != remaining {
+			t.Errorf("Test %v failed. Received different statements number: %v", n, statementNumber)
+			continue
+		}
+		relations, err := getGroupPolicyRelationCount("", "GroupID")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != remaining {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
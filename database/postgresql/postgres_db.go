@@ -47,10 +47,15 @@ func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTT
 	}
 
 	// Create tables if not exist =
-	err = db.AutoMigrate(&User{}, &Group{}, &Policy{}, &Statement{}, &GroupUserRelation{}, &GroupPolicyRelation{}).Error
+	err = db.AutoMigrate(&User{}, &Group{}, &Policy{}, &Statement{}, &GroupUserRelation{}, &GroupPolicyRelation{}, &Organization{},
+		&AccessRequest{}, &AuditEvent{}, &AuthzDecision{}, &Webhook{}, &WebhookDelivery{}, &Change{},
+		&ApiKey{}, &Admin{}, &GroupMapping{}, &UserCredentials{}, &Session{}).Error
 	if err != nil {
 		return nil, err
 	}
+	if err := addMissingOrganizations(db); err != nil {
+		return nil, err
+	}
 
 	// TODO:
 	// Activate sql logger
@@ -61,11 +66,20 @@ func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTT
 
 // User table
 type User struct {
-	ID         string `gorm:"primary_key"`
-	ExternalID string `gorm:"not null;unique"`
-	Path       string `gorm:"not null"`
-	CreateAt   int64  `gorm:"not null"`
-	Urn        string `gorm:"not null;unique"`
+	ID             string `gorm:"primary_key"`
+	ExternalID     string `gorm:"not null;unique"`
+	Path           string `gorm:"not null"`
+	DisplayName    string `gorm:"not null;default:''"`
+	Description    string `gorm:"not null;default:''"`
+	ServiceAccount bool   `gorm:"not null;default:false"`
+	Status         string `gorm:"not null;default:'active'"`
+	CreateAt       int64  `gorm:"not null"`
+	UpdateAt       int64  `gorm:"not null;default:0"`
+	CreatedBy      string `gorm:"not null;default:''"`
+	UpdatedBy      string `gorm:"not null;default:''"`
+	Urn            string `gorm:"not null;unique"`
+	DeleteAt       int64  `gorm:"not null;default:0"`
+	Version        int64  `gorm:"not null;default:1"`
 }
 
 // User's table name
@@ -75,12 +89,19 @@ func (User) TableName() string {
 
 // Group table
 type Group struct {
-	ID       string `gorm:"primary_key"`
-	Name     string `gorm:"not null"`
-	Path     string `gorm:"not null"`
-	Org      string `gorm:"not null"`
-	CreateAt int64  `gorm:"not null"`
-	Urn      string `gorm:"not null;unique"`
+	ID          string `gorm:"primary_key"`
+	Name        string `gorm:"not null"`
+	Path        string `gorm:"not null"`
+	Org         string `gorm:"not null"`
+	DisplayName string `gorm:"not null;default:''"`
+	Description string `gorm:"not null;default:''"`
+	CreateAt    int64  `gorm:"not null"`
+	UpdateAt    int64  `gorm:"not null;default:0"`
+	CreatedBy   string `gorm:"not null;default:''"`
+	UpdatedBy   string `gorm:"not null;default:''"`
+	Urn         string `gorm:"not null;unique"`
+	DeleteAt    int64  `gorm:"not null;default:0"`
+	Version     int64  `gorm:"not null;default:1"`
 }
 
 // Group's table name
@@ -90,12 +111,19 @@ func (Group) TableName() string {
 
 // Policy table
 type Policy struct {
-	ID       string `gorm:"primary_key"`
-	Name     string `gorm:"not null"`
-	Path     string `gorm:"not null"`
-	Org      string `gorm:"not null"`
-	CreateAt int64  `gorm:"not null"`
-	Urn      string `gorm:"not null;unique"`
+	ID          string `gorm:"primary_key"`
+	Name        string `gorm:"not null"`
+	Path        string `gorm:"not null"`
+	Org         string `gorm:"not null"`
+	DisplayName string `gorm:"not null;default:''"`
+	Description string `gorm:"not null;default:''"`
+	CreateAt    int64  `gorm:"not null"`
+	UpdateAt    int64  `gorm:"not null;default:0"`
+	CreatedBy   string `gorm:"not null;default:''"`
+	UpdatedBy   string `gorm:"not null;default:''"`
+	Urn         string `gorm:"not null;unique"`
+	DeleteAt    int64  `gorm:"not null;default:0"`
+	Version     int64  `gorm:"not null;default:1"`
 }
 
 // Policy's table name
@@ -119,8 +147,10 @@ func (Statement) TableName() string {
 
 // Group-Users Relationship
 type GroupUserRelation struct {
-	UserID  string `gorm:"primary_key"`
-	GroupID string `gorm:"primary_key"`
+	UserID   string `gorm:"primary_key"`
+	GroupID  string `gorm:"primary_key"`
+	ExpireAt int64  `gorm:"not null;default:0"`
+	Mapped   bool   `gorm:"not null;default:false"`
 }
 
 // GroupUserRelation's table name
@@ -130,11 +160,222 @@ func (GroupUserRelation) TableName() string {
 
 // Group Policy table
 type GroupPolicyRelation struct {
-	GroupID  string `gorm:"primary_key"`
-	PolicyID string `gorm:"primary_key"`
+	GroupID   string `gorm:"primary_key"`
+	PolicyID  string `gorm:"primary_key"`
+	NotBefore int64  `gorm:"not null;default:0"`
+	NotAfter  int64  `gorm:"not null;default:0"`
 }
 
 // GroupPolicyRelation's table name
 func (GroupPolicyRelation) TableName() string {
 	return "group_policy_relations"
 }
+
+// Organization table
+type Organization struct {
+	ID       string `gorm:"primary_key"`
+	Name     string `gorm:"not null;unique"`
+	CreateAt int64  `gorm:"not null"`
+	Urn      string `gorm:"not null;unique"`
+}
+
+// Organization's table name
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// Access request table
+type AccessRequest struct {
+	ID            string `gorm:"primary_key"`
+	UserID        string `gorm:"not null"`
+	GroupID       string `gorm:"not null"`
+	Requester     string `gorm:"not null"`
+	Org           string `gorm:"not null"`
+	GroupName     string `gorm:"not null"`
+	Justification string `gorm:"not null"`
+	Duration      int64  `gorm:"not null"`
+	Status        string `gorm:"not null"`
+	Reviewer      string `gorm:"not null;default:''"`
+	CreateAt      int64  `gorm:"not null"`
+	ReviewAt      int64  `gorm:"not null;default:0"`
+	ExpireAt      int64  `gorm:"not null;default:0"`
+}
+
+// AccessRequest's table name
+func (AccessRequest) TableName() string {
+	return "access_requests"
+}
+
+// Audit event table. Request and snapshots are stored as JSON text.
+type AuditEvent struct {
+	ID           string `gorm:"primary_key"`
+	RequestID    string `gorm:"not null"`
+	Identifier   string `gorm:"not null"`
+	Action       string `gorm:"not null"`
+	Urn          string `gorm:"not null"`
+	Request      string `gorm:"not null"`
+	Outcome      string `gorm:"not null"`
+	ErrorCode    string `gorm:"not null"`
+	ErrorMessage string `gorm:"not null"`
+	Before       string `gorm:"not null"`
+	After        string `gorm:"not null"`
+	CreateAt     int64  `gorm:"not null"`
+}
+
+// AuditEvent's table name
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// Authorization decision table. Resources and statements are stored as JSON text.
+type AuthzDecision struct {
+	ID          string `gorm:"primary_key"`
+	RequestID   string `gorm:"not null"`
+	Identifier  string `gorm:"not null"`
+	Admin       bool   `gorm:"not null"`
+	Action      string `gorm:"not null"`
+	ResourceUrn string `gorm:"not null"`
+	Requested   string `gorm:"not null"`
+	Allowed     string `gorm:"not null"`
+	Statements  string `gorm:"not null"`
+	Latency     int64  `gorm:"not null"`
+	CreateAt    int64  `gorm:"not null"`
+}
+
+// AuthzDecision's table name
+func (AuthzDecision) TableName() string {
+	return "authz_decisions"
+}
+
+// Webhook table. Event types are separated by ';'.
+type Webhook struct {
+	ID         string `gorm:"primary_key"`
+	Url        string `gorm:"not null"`
+	EventTypes string `gorm:"not null"`
+	Secret     string `gorm:"not null"`
+	Urn        string `gorm:"not null;unique"`
+	CreatedBy  string `gorm:"not null;default:''"`
+	CreateAt   int64  `gorm:"not null"`
+	UpdateAt   int64  `gorm:"not null"`
+}
+
+// Webhook's table name
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Webhook delivery table. Event is stored as JSON text.
+type WebhookDelivery struct {
+	ID             string `gorm:"primary_key"`
+	WebhookID      string `gorm:"not null"`
+	EventID        string `gorm:"not null"`
+	EventType      string `gorm:"not null"`
+	Event          string `gorm:"not null"`
+	Status         string `gorm:"not null"`
+	Attempts       int    `gorm:"not null"`
+	LastStatusCode int    `gorm:"not null"`
+	LastError      string `gorm:"not null"`
+	NextAttemptAt  int64  `gorm:"not null"`
+	CreateAt       int64  `gorm:"not null"`
+	UpdateAt       int64  `gorm:"not null"`
+}
+
+// WebhookDelivery's table name
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Change log table. Seq is assigned by database.
+type Change struct {
+	Seq      int64  `gorm:"primary_key;AUTO_INCREMENT"`
+	Type     string `gorm:"not null"`
+	Urn      string `gorm:"not null"`
+	Before   string `gorm:"not null"`
+	After    string `gorm:"not null"`
+	CreateAt int64  `gorm:"not null"`
+}
+
+// Change's table name
+func (Change) TableName() string {
+	return "changes"
+}
+
+// Api key table. Hash is the hex SHA-256 of the secret key and ExpireAt is 0 if the key never expires.
+type ApiKey struct {
+	ID       string `gorm:"primary_key"`
+	UserID   string `gorm:"not null;index"`
+	Name     string `gorm:"not null"`
+	Prefix   string `gorm:"not null"`
+	Hash     string `gorm:"not null;unique"`
+	ExpireAt int64  `gorm:"not null;default:0"`
+	CreateAt int64  `gorm:"not null"`
+	UpdateAt int64  `gorm:"not null"`
+}
+
+// ApiKey's table name
+func (ApiKey) TableName() string {
+	return "api_keys"
+}
+
+// Admin credentials table. Hash is the salted hash of the admin password of the user.
+type Admin struct {
+	UserID         string `gorm:"primary_key"`
+	Hash           string `gorm:"not null"`
+	FailedAttempts int    `gorm:"not null;default:0"`
+	LockedUntil    int64  `gorm:"not null;default:0"`
+	CreateAt       int64  `gorm:"not null"`
+	UpdateAt       int64  `gorm:"not null"`
+}
+
+// Admin's table name
+func (Admin) TableName() string {
+	return "admins"
+}
+
+// Local credentials table. Hash is the salted hash of the local password of the user, LockedUntil
+// is 0 if the user isn't locked out.
+type UserCredentials struct {
+	UserID         string `gorm:"primary_key"`
+	Hash           string `gorm:"not null"`
+	FailedAttempts int    `gorm:"not null;default:0"`
+	LockedUntil    int64  `gorm:"not null;default:0"`
+	CreateAt       int64  `gorm:"not null"`
+	UpdateAt       int64  `gorm:"not null"`
+}
+
+// UserCredentials's table name
+func (UserCredentials) TableName() string {
+	return "user_credentials"
+}
+
+// Session table. Hash is the hash of the session token.
+type Session struct {
+	ID       string `gorm:"primary_key"`
+	UserID   string `gorm:"not null;index"`
+	Hash     string `gorm:"not null;unique"`
+	ExpireAt int64  `gorm:"not null"`
+	CreateAt int64  `gorm:"not null"`
+}
+
+// Session's table name
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// Group mapping table. GroupName is the name of the mapped group in the organization.
+type GroupMapping struct {
+	ID        string `gorm:"primary_key"`
+	Issuer    string `gorm:"not null;default:''"`
+	Claim     string `gorm:"not null"`
+	Value     string `gorm:"not null"`
+	Org       string `gorm:"not null;index"`
+	GroupName string `gorm:"not null"`
+	Urn       string `gorm:"not null;unique"`
+	CreateAt  int64  `gorm:"not null"`
+	CreatedBy string `gorm:"not null"`
+}
+
+// GroupMapping's table name
+func (GroupMapping) TableName() string {
+	return "group_mappings"
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
 	"github.com/tecsisa/foulkon/database"
 )
 
@@ -86,6 +87,20 @@ func insertUser(id string, externalID string, path string, createAt int64, urn s
 	return nil
 }
 
+func insertServiceAccount(id string, externalID string, path string, createAt int64, urn string) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.users (id, external_id, path, create_at, urn, service_account) VALUES (?, ?, ?, ?, ?, ?)",
+		id, externalID, path, createAt, urn, true).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func insertGroupUserRelation(userID string, groupID string) error {
 	err := repoDB.Dbmap.Exec("INSERT INTO public.group_user_relations (user_id, group_id) VALUES (?, ?)",
 		userID, groupID).Error
@@ -100,6 +115,20 @@ func insertGroupUserRelation(userID string, groupID string) error {
 	return nil
 }
 
+func insertExpiringGroupUserRelation(userID string, groupID string, expireAt int64) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.group_user_relations (user_id, group_id, expire_at) VALUES (?, ?, ?)",
+		userID, groupID, expireAt).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func getUsersCountFiltered(id string, externalID string, path string, createAt int64, urn string, pathPrefix string) (int, error) {
 	query := repoDB.Dbmap.Table(User{}.TableName())
 	if id != "" {
@@ -203,6 +232,15 @@ func getGroupUserRelations(groupID string, userID string) (int, error) {
 	return number, nil
 }
 
+func getGroupUserRelationExpireAt(groupID string, userID string) (int64, error) {
+	relation := GroupUserRelation{}
+	if err := repoDB.Dbmap.Where("group_id = ? AND user_id = ?", groupID, userID).First(&relation).Error; err != nil {
+		return 0, err
+	}
+
+	return relation.ExpireAt, nil
+}
+
 func cleanGroupTable() error {
 	if err := repoDB.Dbmap.Delete(&Group{}).Error; err != nil {
 		return err
@@ -332,6 +370,29 @@ func insertGroupPolicyRelation(groupID string, policyID string) error {
 	return nil
 }
 
+func insertScheduledGroupPolicyRelation(groupID string, policyID string, notBefore int64, notAfter int64) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.group_policy_relations (group_id, policy_id, not_before, not_after) VALUES (?, ?, ?, ?)",
+		groupID, policyID, notBefore, notAfter).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getGroupPolicyRelationWindow(groupID string, policyID string) (int64, int64, error) {
+	relation := GroupPolicyRelation{}
+	if err := repoDB.Dbmap.Where("group_id = ? AND policy_id = ?", groupID, policyID).First(&relation).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return relation.NotBefore, relation.NotAfter, nil
+}
+
 func getStatementsCountFiltered(id string, policyId string, effect string, actions string, resources string) (int, error) {
 	query := repoDB.Dbmap.Table(Statement{}.TableName())
 	if id != "" {
@@ -356,3 +417,343 @@ func getStatementsCountFiltered(id string, policyId string, effect string, actio
 
 	return number, nil
 }
+
+// ORGANIZATION
+
+func insertOrganization(id string, name string, createAt int64, urn string) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.organizations (id, name, create_at, urn) VALUES (?, ?, ?, ?)",
+		id, name, createAt, urn).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getOrganizationsCountFiltered(id string, name string) (int, error) {
+	query := repoDB.Dbmap.Table(Organization{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanOrganizationTable() error {
+	if err := repoDB.Dbmap.Delete(&Organization{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func markAsDeleted(table string, id string, deleteAt int64) error {
+	return repoDB.Dbmap.Table(table).Where("id = ?", id).UpdateColumn("delete_at", deleteAt).Error
+}
+
+func getDeleteAt(table string, id string) (int64, error) {
+	deleteAt := []int64{}
+	if err := repoDB.Dbmap.Table(table).Where("id = ?", id).Pluck("delete_at", &deleteAt).Error; err != nil {
+		return 0, err
+	}
+	if len(deleteAt) != 1 {
+		return 0, fmt.Errorf("Found %v rows with id %v in table %v", len(deleteAt), id, table)
+	}
+
+	return deleteAt[0], nil
+}
+
+// ACCESS REQUEST
+
+func insertAccessRequest(request api.AccessRequest) error {
+	requestDB := &AccessRequest{
+		ID:            request.ID,
+		UserID:        request.UserID,
+		GroupID:       request.GroupID,
+		Requester:     request.Requester,
+		Org:           request.Org,
+		GroupName:     request.Group,
+		Justification: request.Justification,
+		Duration:      request.Duration,
+		Status:        request.Status,
+		Reviewer:      request.Reviewer,
+		CreateAt:      request.CreateAt.UnixNano(),
+	}
+	if request.ReviewAt != nil {
+		requestDB.ReviewAt = request.ReviewAt.UnixNano()
+	}
+	if request.ExpireAt != nil {
+		requestDB.ExpireAt = request.ExpireAt.UnixNano()
+	}
+	err := repoDB.Dbmap.Create(requestDB).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getAccessRequestsCountFiltered(id string, status string) (int, error) {
+	query := repoDB.Dbmap.Table(AccessRequest{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAccessRequestTable() error {
+	if err := repoDB.Dbmap.Delete(&AccessRequest{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// AUDIT EVENT
+
+func insertAuditEvent(event api.AuditEvent) error {
+	eventDB := &AuditEvent{
+		ID:         event.ID,
+		RequestID:  event.RequestID,
+		Identifier: event.User,
+		Action:     event.Action,
+		Urn:        event.Urn,
+		Request:    string(event.Request),
+		Outcome:    event.Outcome,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		CreateAt:   event.CreateAt.UnixNano(),
+	}
+	if event.Error != nil {
+		eventDB.ErrorCode = event.Error.Code
+		eventDB.ErrorMessage = event.Error.Message
+	}
+	err := repoDB.Dbmap.Create(eventDB).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getAuditEventsCountFiltered(id string) (int, error) {
+	query := repoDB.Dbmap.Table(AuditEvent{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAuditEventTable() error {
+	if err := repoDB.Dbmap.Delete(&AuditEvent{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// AUTHZ DECISION
+
+func getAuthzDecisionsCountFiltered(id string, requested string, allowed string) (int, error) {
+	query := repoDB.Dbmap.Table(AuthzDecision{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if requested != "" {
+		query = query.Where("requested = ?", requested)
+	}
+	if allowed != "" {
+		query = query.Where("allowed = ?", allowed)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAuthzDecisionTable() error {
+	if err := repoDB.Dbmap.Delete(&AuthzDecision{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// WEBHOOK
+
+func getWebhooksCountFiltered(id string) (int, error) {
+	query := repoDB.Dbmap.Table(Webhook{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func getWebhookDeliveriesCountFiltered(webhookID string, status string) (int, error) {
+	query := repoDB.Dbmap.Table(WebhookDelivery{}.TableName())
+	if webhookID != "" {
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanWebhookTable() error {
+	if err := repoDB.Dbmap.Delete(&Webhook{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func cleanWebhookDeliveryTable() error {
+	if err := repoDB.Dbmap.Delete(&WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CHANGE
+
+func cleanChangeTable() error {
+	if err := repoDB.Dbmap.Delete(&Change{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// API KEY
+
+func getApiKeysCountFiltered(userID string) (int, error) {
+	query := repoDB.Dbmap.Table(ApiKey{}.TableName())
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanApiKeyTable() error {
+	if err := repoDB.Dbmap.Delete(&ApiKey{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ADMIN
+
+func getAdminsCountFiltered(userID string) (int, error) {
+	query := repoDB.Dbmap.Table(Admin{}.TableName())
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAdminTable() error {
+	if err := repoDB.Dbmap.Delete(&Admin{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GROUP MAPPING
+
+func getGroupMappingsCountFiltered(id string) (int, error) {
+	query := repoDB.Dbmap.Table(GroupMapping{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanGroupMappingTable() error {
+	if err := repoDB.Dbmap.Delete(&GroupMapping{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// PASSWORD
+
+func getSessionsCountFiltered(userID string) (int, error) {
+	query := repoDB.Dbmap.Table(Session{}.TableName())
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanUserCredentialsTable() error {
+	if err := repoDB.Dbmap.Delete(&UserCredentials{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func cleanSessionTable() error {
+	if err := repoDB.Dbmap.Delete(&Session{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// SYNC REPOSITORY IMPLEMENTATION
+
+func (s PostgresRepo) ApplySyncPlan(changes []api.SyncChange) error {
+	transaction := s.Dbmap.Begin()
+
+	for _, change := range changes {
+		var err error
+		switch change.Entity {
+		case api.RESOURCE_GROUP:
+			err = applyGroupChange(transaction, change.Operation, *change.Group)
+		case api.RESOURCE_POLICY:
+			err = applyPolicyChange(transaction, change.Operation, *change.Policy)
+		case api.SYNC_ENTITY_MEMBER:
+			err = applyMemberChange(transaction, change.Operation, change.Group.ID, change.User.ID)
+		case api.SYNC_ENTITY_ATTACHMENT:
+			err = applyAttachmentChange(transaction, change.Operation, change.Group.ID, change.Policy.ID)
+		default:
+			err = fmt.Errorf("Unknown entity %v", change.Entity)
+		}
+
+		// Error handling
+		if err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: fmt.Sprintf("Error applying change %v: %v", change, err.Error()),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+func applyGroupChange(transaction *gorm.DB, operation string, group api.Group) error {
+	switch operation {
+	case api.SYNC_OPERATION_CREATE:
+		groupDB := &Group{
+			ID:          group.ID,
+			Name:        group.Name,
+			Path:        group.Path,
+			DisplayName: group.DisplayName,
+			Description: group.Description,
+			CreateAt:    group.CreateAt.UnixNano(),
+			UpdateAt:    group.UpdateAt.UnixNano(),
+			CreatedBy:   group.CreatedBy,
+			UpdatedBy:   group.UpdatedBy,
+			Urn:         group.Urn,
+			Org:         group.Org,
+			Version:     1,
+		}
+		if err := addMissingOrganization(transaction, group.Org); err != nil {
+			return err
+		}
+		return transaction.Create(groupDB).Error
+	case api.SYNC_OPERATION_UPDATE:
+		return transaction.Model(&Group{ID: group.ID}).Updates(map[string]interface{}{
+			"path":       group.Path,
+			"urn":        group.Urn,
+			"update_at":  group.UpdateAt.UnixNano(),
+			"updated_by": group.UpdatedBy,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+	case api.SYNC_OPERATION_DELETE:
+		// Mark group as deleted like RemoveGroup, relations are kept until the group is purged
+		return transaction.Model(&Group{}).Where("id like ? AND delete_at = 0", group.ID).
+			UpdateColumn("delete_at", time.Now().UTC().UnixNano()).Error
+	}
+	return fmt.Errorf("Unknown operation %v", operation)
+}
+
+func applyPolicyChange(transaction *gorm.DB, operation string, policy api.Policy) error {
+	switch operation {
+	case api.SYNC_OPERATION_CREATE:
+		policyDB := &Policy{
+			ID:          policy.ID,
+			Name:        policy.Name,
+			Path:        policy.Path,
+			DisplayName: policy.DisplayName,
+			Description: policy.Description,
+			CreateAt:    policy.CreateAt.UnixNano(),
+			UpdateAt:    policy.UpdateAt.UnixNano(),
+			CreatedBy:   policy.CreatedBy,
+			UpdatedBy:   policy.UpdatedBy,
+			Urn:         policy.Urn,
+			Org:         policy.Org,
+			Version:     1,
+		}
+		if err := addMissingOrganization(transaction, policy.Org); err != nil {
+			return err
+		}
+		if err := transaction.Create(policyDB).Error; err != nil {
+			return err
+		}
+		return createStatements(transaction, policy.ID, *policy.Statements)
+	case api.SYNC_OPERATION_UPDATE:
+		if err := transaction.Model(&Policy{ID: policy.ID}).Updates(map[string]interface{}{
+			"path":       policy.Path,
+			"urn":        policy.Urn,
+			"update_at":  policy.UpdateAt.UnixNano(),
+			"updated_by": policy.UpdatedBy,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		// Override old statements
+		if err := transaction.Where("policy_id like ?", policy.ID).Delete(&Statement{}).Error; err != nil {
+			return err
+		}
+		return createStatements(transaction, policy.ID, *policy.Statements)
+	case api.SYNC_OPERATION_DELETE:
+		// Mark policy as deleted like RemovePolicy, statements and relations are kept until the policy is purged
+		return transaction.Model(&Policy{}).Where("id like ? AND delete_at = 0", policy.ID).
+			UpdateColumn("delete_at", time.Now().UTC().UnixNano()).Error
+	}
+	return fmt.Errorf("Unknown operation %v", operation)
+}
+
+func applyMemberChange(transaction *gorm.DB, operation string, groupID string, userID string) error {
+	switch operation {
+	case api.SYNC_OPERATION_CREATE:
+		if err := removeExpiredMember(transaction, userID, groupID); err != nil {
+			return err
+		}
+		return transaction.Create(&GroupUserRelation{
+			UserID:  userID,
+			GroupID: groupID,
+		}).Error
+	case api.SYNC_OPERATION_DELETE:
+		return transaction.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error
+	}
+	return fmt.Errorf("Unknown operation %v", operation)
+}
+
+func applyAttachmentChange(transaction *gorm.DB, operation string, groupID string, policyID string) error {
+	switch operation {
+	case api.SYNC_OPERATION_CREATE:
+		return transaction.Create(&GroupPolicyRelation{
+			GroupID:  groupID,
+			PolicyID: policyID,
+		}).Error
+	case api.SYNC_OPERATION_DELETE:
+		return transaction.Where("group_id like ? AND policy_id like ?", groupID, policyID).Delete(&GroupPolicyRelation{}).Error
+	}
+	return fmt.Errorf("Unknown operation %v", operation)
+}
+
+func createStatements(transaction *gorm.DB, policyID string, statements []api.Statement) error {
+	for _, s := range statements {
+		statementDB := &Statement{
+			ID:        uuid.NewV4().String(),
+			PolicyID:  policyID,
+			Effect:    s.Effect,
+			Actions:   stringArrayToString(s.Actions),
+			Resources: stringArrayToString(s.Resources),
+		}
+		if err := transaction.Create(statementDB).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
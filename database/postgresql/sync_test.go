@@ -0,0 +1,293 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestPostgresRepo_ApplySyncPlan(t *testing.T) {
+	now := time.Now().UTC()
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+		},
+	}
+	newGroup := api.Group{
+		ID:       "NewGroupID",
+		Name:     "NewGroup",
+		Path:     "/path/",
+		Urn:      "NewGroupUrn",
+		CreateAt: now,
+		UpdateAt: now,
+		Version:  1,
+		Org:      "Org",
+	}
+	oldGroup := api.Group{
+		ID:       "OldGroupID",
+		Name:     "OldGroup",
+		Path:     "/path/",
+		Urn:      "OldGroupUrn",
+		CreateAt: now,
+		UpdateAt: now,
+		Version:  1,
+		Org:      "Org",
+	}
+	newPolicy := api.Policy{
+		ID:         "NewPolicyID",
+		Name:       "NewPolicy",
+		Path:       "/path/",
+		Urn:        "NewPolicyUrn",
+		CreateAt:   now,
+		UpdateAt:   now,
+		Version:    1,
+		Org:        "Org",
+		Statements: &statements,
+	}
+	oldPolicy := api.Policy{
+		ID:         "OldPolicyID",
+		Name:       "OldPolicy",
+		Path:       "/path/",
+		Urn:        "OldPolicyUrn",
+		CreateAt:   now,
+		UpdateAt:   now,
+		Version:    1,
+		Org:        "Org",
+		Statements: &statements,
+	}
+	user := api.User{
+		ID:         "UserID",
+		ExternalID: "ExternalID",
+		Path:       "/path/",
+		Status:     api.USER_STATUS_ACTIVE,
+		Urn:        "UserUrn",
+		CreateAt:   now,
+		UpdateAt:   now,
+		Version:    1,
+	}
+	testcases := map[string]struct {
+		changes []api.SyncChange
+		// Expected result
+		expectedNewGroups        int
+		expectedOldGroups        int
+		expectedOldDeleted       bool
+		expectedOldPolicyDeleted bool
+		expectedNewPolicies      int
+		expectedMembers          int
+		expectedAttachments      int
+		expectedStatements       int
+		expectedOrgs             int
+	}{
+		"OkCase": {
+			changes: []api.SyncChange{
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.RESOURCE_POLICY,
+					Policy:    &newPolicy,
+				},
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &newGroup,
+				},
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.SYNC_ENTITY_MEMBER,
+					Group:     &newGroup,
+					User:      &user,
+				},
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.SYNC_ENTITY_ATTACHMENT,
+					Group:     &newGroup,
+					Policy:    &newPolicy,
+				},
+				{
+					Operation: api.SYNC_OPERATION_DELETE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &oldGroup,
+				},
+				{
+					Operation: api.SYNC_OPERATION_DELETE,
+					Entity:    api.RESOURCE_POLICY,
+					Policy:    &oldPolicy,
+				},
+			},
+			expectedNewGroups:        1,
+			expectedOldGroups:        1,
+			expectedOldDeleted:       true,
+			expectedOldPolicyDeleted: true,
+			expectedNewPolicies:      1,
+			expectedMembers:          1,
+			expectedAttachments:      1,
+			expectedStatements:       1,
+			expectedOrgs:             1,
+		},
+		"ErrorCaseRollback": {
+			changes: []api.SyncChange{
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &newGroup,
+				},
+				{
+					Operation: api.SYNC_OPERATION_DELETE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &oldGroup,
+				},
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &oldGroup,
+				},
+				{
+					Operation: api.SYNC_OPERATION_CREATE,
+					Entity:    api.RESOURCE_GROUP,
+					Group:     &oldGroup,
+				},
+			},
+			expectedNewGroups: 0,
+			expectedOldGroups: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		cleanGroupTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if err := insertGroup(oldGroup.ID, oldGroup.Name, oldGroup.Path, oldGroup.CreateAt.UnixNano(), oldGroup.Urn, oldGroup.Org); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+			continue
+		}
+		oldStatements := []Statement{
+			{
+				ID:        "OldStatementID",
+				PolicyID:  oldPolicy.ID,
+				Effect:    "allow",
+				Actions:   "iam:*",
+				Resources: "urn:everything:*",
+			},
+		}
+		if err := insertPolicy(oldPolicy.ID, oldPolicy.Name, oldPolicy.Org, oldPolicy.Path, oldPolicy.CreateAt.UnixNano(), oldPolicy.Urn, oldStatements); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous policy: %v", n, err)
+			continue
+		}
+		if err := insertGroupPolicyRelation(oldGroup.ID, oldPolicy.ID); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group policy relation: %v", n, err)
+			continue
+		}
+
+		// Call to repository to apply plan
+		repoDB.ApplySyncPlan(test.changes)
+
+		// Check database
+		groupNumber, err := getGroupsCountFiltered(newGroup.ID, "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			continue
+		}
+		if groupNumber != test.expectedNewGroups {
+			t.Errorf("Test %v failed. Received different new group number: %v", n, groupNumber)
+			continue
+		}
+		groupNumber, err = getGroupsCountFiltered(oldGroup.ID, "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting groups: %v", n, err)
+			continue
+		}
+		if groupNumber != test.expectedOldGroups {
+			t.Errorf("Test %v failed. Received different old group number: %v", n, groupNumber)
+			continue
+		}
+		deleteAt, err := getDeleteAt(Group{}.TableName(), oldGroup.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error getting delete time: %v", n, err)
+			continue
+		}
+		if (deleteAt > 0) != test.expectedOldDeleted {
+			t.Errorf("Test %v failed. Received different delete time of old group: %v", n, deleteAt)
+			continue
+		}
+		deleteAt, err = getDeleteAt(Policy{}.TableName(), oldPolicy.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error getting delete time: %v", n, err)
+			continue
+		}
+		if (deleteAt > 0) != test.expectedOldPolicyDeleted {
+			t.Errorf("Test %v failed. Received different delete time of old policy: %v", n, deleteAt)
+			continue
+		}
+		// Relations and statements of deleted entities are kept until they are purged
+		statementNumber, err := getStatementsCountFiltered("", oldPolicy.ID, "", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
+			continue
+		}
+		if statementNumber != 1 {
+			t.Errorf("Test %v failed. Received different statement number of old policy: %v", n, statementNumber)
+			continue
+		}
+		attachments, err := getGroupPolicyRelationCount(oldPolicy.ID, oldGroup.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting attachments: %v", n, err)
+			continue
+		}
+		if attachments != 1 {
+			t.Errorf("Test %v failed. Received different attachment number of old group: %v", n, attachments)
+			continue
+		}
+		policyNumber, err := getPoliciesCountFiltered(newPolicy.ID, "", "", "", 0, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting policies: %v", n, err)
+			continue
+		}
+		if policyNumber != test.expectedNewPolicies {
+			t.Errorf("Test %v failed. Received different policy number: %v", n, policyNumber)
+			continue
+		}
+		statementNumber, err = getStatementsCountFiltered("", newPolicy.ID, "", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting statements: %v", n, err)
+			continue
+		}
+		if statementNumber != test.expectedStatements {
+			t.Errorf("Test %v failed. Received different statement number: %v", n, statementNumber)
+			continue
+		}
+		members, err := getGroupUserRelations(newGroup.ID, user.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting members: %v", n, err)
+			continue
+		}
+		if members != test.expectedMembers {
+			t.Errorf("Test %v failed. Received different member number: %v", n, members)
+			continue
+		}
+		attachments, err = getGroupPolicyRelationCount(newPolicy.ID, newGroup.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting attachments: %v", n, err)
+			continue
+		}
+		if attachments != test.expectedAttachments {
+			t.Errorf("Test %v failed. Received different attachment number: %v", n, attachments)
+			continue
+		}
+		orgs, err := getOrganizationsCountFiltered("", newGroup.Org)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+			continue
+		}
+		if orgs != test.expectedOrgs {
+			t.Errorf("Test %v failed. Received different organization number: %v", n, orgs)
+			continue
+		}
+	}
+}
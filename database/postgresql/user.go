@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/tecsisa/foulkon/api"
 	"github.com/tecsisa/foulkon/database"
 )
@@ -14,11 +15,19 @@ func (u PostgresRepo) AddUser(user api.User) (*api.User, error) {
 
 	// Create user model
 	userDB := &User{
-		ID:         user.ID,
-		ExternalID: user.ExternalID,
-		Path:       user.Path,
-		CreateAt:   user.CreateAt.UnixNano(),
-		Urn:        user.Urn,
+		ID:             user.ID,
+		ExternalID:     user.ExternalID,
+		Path:           user.Path,
+		DisplayName:    user.DisplayName,
+		Description:    user.Description,
+		ServiceAccount: user.ServiceAccount,
+		Status:         user.Status,
+		CreateAt:       user.CreateAt.UnixNano(),
+		UpdateAt:       user.UpdateAt.UnixNano(),
+		CreatedBy:      user.CreatedBy,
+		UpdatedBy:      user.UpdatedBy,
+		Urn:            user.Urn,
+		Version:        1,
 	}
 
 	// Store user
@@ -37,7 +46,7 @@ func (u PostgresRepo) AddUser(user api.User) (*api.User, error) {
 
 func (u PostgresRepo) GetUserByExternalID(id string) (*api.User, error) {
 	user := &User{}
-	query := u.Dbmap.Where("external_id like ?", id).First(user)
+	query := u.Dbmap.Where("external_id like ? AND delete_at = 0", id).First(user)
 
 	// Check if user exists
 	if query.RecordNotFound() {
@@ -60,7 +69,7 @@ func (u PostgresRepo) GetUserByExternalID(id string) (*api.User, error) {
 
 func (u PostgresRepo) GetUserByID(id string) (*api.User, error) {
 	user := &User{}
-	query := u.Dbmap.Where("id like ?", id).First(user)
+	query := u.Dbmap.Where("id like ? AND delete_at = 0", id).First(user)
 
 	// Check if user exists
 	if query.RecordNotFound() {
@@ -83,7 +92,7 @@ func (u PostgresRepo) GetUserByID(id string) (*api.User, error) {
 
 func (u PostgresRepo) GetUsersFiltered(pathPrefix string) ([]api.User, error) {
 	users := []User{}
-	query := u.Dbmap
+	query := u.Dbmap.Where("delete_at = 0")
 
 	// Check if path is filled, else it doesn't use it to filter
 	if len(pathPrefix) > 0 {
@@ -110,24 +119,35 @@ func (u PostgresRepo) GetUsersFiltered(pathPrefix string) ([]api.User, error) {
 	return nil, nil
 }
 
-func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string) (*api.User, error) {
-
-	// Create new user
-	userUpdated := User{
-		Path: newPath,
-		Urn:  newUrn,
-	}
+func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string, newDisplayName string, newDescription string,
+	updatedBy string) (*api.User, error) {
 
 	userDB := User{
-		ID:         user.ID,
-		ExternalID: user.ExternalID,
-		Path:       user.Path,
-		CreateAt:   user.CreateAt.UnixNano(),
-		Urn:        user.Urn,
+		ID:             user.ID,
+		ExternalID:     user.ExternalID,
+		Path:           newPath,
+		DisplayName:    newDisplayName,
+		Description:    newDescription,
+		ServiceAccount: user.ServiceAccount,
+		Status:         user.Status,
+		CreateAt:       user.CreateAt.UnixNano(),
+		UpdateAt:       time.Now().UTC().UnixNano(),
+		CreatedBy:      user.CreatedBy,
+		UpdatedBy:      updatedBy,
+		Urn:            newUrn,
+		Version:        user.Version + 1,
 	}
 
-	// Update user
-	query := u.Dbmap.Model(&userDB).Update(userUpdated)
+	// Update user only if it's still in the same version. A map is used to store empty values too
+	query := u.Dbmap.Model(&User{ID: user.ID}).Where("version = ?", user.Version).Updates(map[string]interface{}{
+		"path":         userDB.Path,
+		"display_name": userDB.DisplayName,
+		"description":  userDB.Description,
+		"update_at":    userDB.UpdateAt,
+		"updated_by":   userDB.UpdatedBy,
+		"urn":          userDB.Urn,
+		"version":      userDB.Version,
+	})
 
 	// Error Handling
 	if err := query.Error; err != nil {
@@ -137,42 +157,180 @@ func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string) (
 		}
 	}
 
+	// Check if user was modified meanwhile
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("User with externalId %v isn't in version %v", user.ExternalID, user.Version),
+		}
+	}
+
 	return dbUserToAPIUser(&userDB), nil
 }
 
-func (u PostgresRepo) RemoveUser(id string) error {
-	transaction := u.Dbmap.Begin()
-	// Delete user
-	transaction.Where("id like ?", id).Delete(&User{})
+func (u PostgresRepo) RemoveUser(id string, version int64) error {
+	// Mark user as deleted only if it's still in the same version, group relations are kept to restore them
+	query := u.Dbmap.Model(&User{}).Where("id like ? AND version = ? AND delete_at = 0", id, version).
+		UpdateColumn("delete_at", time.Now().UTC().UnixNano())
 
 	// Error handling
-	if err := transaction.Error; err != nil {
-		transaction.Rollback()
+	if err := query.Error; err != nil {
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
-	//  delete all user relations
-	transaction.Where("user_id like ?", id).Delete(&GroupUserRelation{})
+	// Check if user was modified or deleted meanwhile
+	if query.RowsAffected == 0 {
+		return &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("User with id %v isn't in version %v", id, version),
+		}
+	}
+
+	return nil
+}
+
+func (u PostgresRepo) GetDeletedUserByExternalID(id string) (*api.User, error) {
+	user := &User{}
+	query := u.Dbmap.Where("external_id like ? AND delete_at > 0", id).First(user)
+
+	// Check if deleted user exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.USER_NOT_FOUND,
+			Message: fmt.Sprintf("Deleted user with externalId %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbUserToAPIUser(user), nil
+}
+
+func (u PostgresRepo) GetDeletedUsers(pathPrefix string) ([]api.User, error) {
+	users := []User{}
+	query := u.Dbmap.Where("delete_at > 0")
+
+	// Check if path is filled, else it doesn't use it to filter
+	if len(pathPrefix) > 0 {
+		query = query.Where("path like ?", pathPrefix+"%")
+	}
 
 	// Error handling
-	if err := transaction.Error; err != nil {
-		transaction.Rollback()
+	if err := query.Find(&users).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform users for API
+	apiusers := make([]api.User, len(users), cap(users))
+	for i, u := range users {
+		apiusers[i] = *dbUserToAPIUser(&u)
+	}
+
+	return apiusers, nil
+}
+
+func (u PostgresRepo) RestoreUser(id string) error {
+	// Clear deletion mark, so retained group relations are taken into account again
+	err := u.Dbmap.Model(&User{}).Where("id like ? AND delete_at > 0", id).UpdateColumn("delete_at", 0).Error
+
+	// Error handling
+	if err != nil {
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
-	transaction.Commit()
 	return nil
 }
 
+func (u PostgresRepo) PurgeUsers(deletedBefore time.Time) (int64, error) {
+	transaction := u.Dbmap.Begin()
+	deleted := "SELECT id FROM users WHERE delete_at > 0 AND delete_at < ?"
+	before := deletedBefore.UTC().UnixNano()
+
+	// Delete group relations of purged users
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&GroupUserRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete access requests of purged users
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&AccessRequest{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete API keys of purged users
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&ApiKey{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete admin credentials of purged users
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&Admin{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete local credentials and sessions of purged users
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&UserCredentials{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if err := transaction.Where("user_id IN ("+deleted+")", before).Delete(&Session{}).Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete users
+	query := transaction.Where("delete_at > 0 AND delete_at < ?", before).Delete(&User{})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return query.RowsAffected, nil
+}
+
 func (u PostgresRepo) GetGroupsByUserID(id string) ([]api.Group, error) {
 	relations := []GroupUserRelation{}
-	query := u.Dbmap.Where("user_id like ?", id).Find(&relations)
+	query := u.Dbmap.Where("user_id like ? AND (expire_at = 0 OR expire_at > ?) AND group_id NOT IN (SELECT id FROM groups WHERE delete_at > 0)",
+		id, time.Now().UTC().UnixNano()).Find(&relations)
 
 	// Error Handling
 	if err := query.Error; err != nil {
@@ -202,15 +360,213 @@ func (u PostgresRepo) GetGroupsByUserID(id string) ([]api.Group, error) {
 	return apiGroups, nil
 }
 
+func (u PostgresRepo) RenameUser(user api.User, newExternalId string, newUrn string, updatedBy string) (*api.User, error) {
+	transaction := u.Dbmap.Begin()
+
+	userDB := User{
+		ID:             user.ID,
+		ExternalID:     newExternalId,
+		Path:           user.Path,
+		DisplayName:    user.DisplayName,
+		Description:    user.Description,
+		ServiceAccount: user.ServiceAccount,
+		Status:         user.Status,
+		CreateAt:       user.CreateAt.UnixNano(),
+		UpdateAt:       time.Now().UTC().UnixNano(),
+		CreatedBy:      user.CreatedBy,
+		UpdatedBy:      updatedBy,
+		Urn:            newUrn,
+		Version:        user.Version + 1,
+	}
+
+	// Update user only if it's still in the same version
+	query := transaction.Model(&User{ID: user.ID}).Where("version = ?", user.Version).Updates(map[string]interface{}{
+		"external_id": userDB.ExternalID,
+		"update_at":   userDB.UpdateAt,
+		"updated_by":  userDB.UpdatedBy,
+		"urn":         userDB.Urn,
+		"version":     userDB.Version,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if user was modified meanwhile
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("User with externalId %v isn't in version %v", user.ExternalID, user.Version),
+		}
+	}
+
+	// Access requests keep the externalId of requester and reviewer
+	if err := u.renameAccessRequestUsers(transaction, user.ID, user.ExternalID, newExternalId); err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	transaction.Commit()
+	return dbUserToAPIUser(&userDB), nil
+}
+
+func (u PostgresRepo) MergeUsers(source api.User, target api.User) error {
+	transaction := u.Dbmap.Begin()
+
+	// Keep the longest membership in groups where both users are members, 0 means no expiration
+	err := transaction.Exec("UPDATE group_user_relations t SET expire_at = CASE WHEN t.expire_at = 0 OR s.expire_at = 0 "+
+		"THEN 0 ELSE GREATEST(t.expire_at, s.expire_at) END FROM group_user_relations s "+
+		"WHERE t.user_id = ? AND s.user_id = ? AND t.group_id = s.group_id", target.ID, source.ID).Error
+	if err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	err = transaction.Where("user_id like ? AND group_id IN (SELECT group_id FROM group_user_relations WHERE user_id like ?)",
+		source.ID, target.ID).Delete(&GroupUserRelation{}).Error
+	if err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Move the rest of memberships
+	err = transaction.Model(&GroupUserRelation{}).Where("user_id like ?", source.ID).UpdateColumn("user_id", target.ID).Error
+	if err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Move access requests
+	err = transaction.Model(&AccessRequest{}).Where("user_id like ?", source.ID).UpdateColumn("user_id", target.ID).Error
+	if err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if err := u.renameAccessRequestUsers(transaction, target.ID, source.ExternalID, target.ExternalID); err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	// Delete API keys, admin and local credentials, sessions and source user, credentials aren't moved
+	// because they belong to source user
+	for _, credentials := range []interface{}{&ApiKey{}, &Admin{}, &UserCredentials{}, &Session{}} {
+		if err := transaction.Where("user_id like ?", source.ID).Delete(credentials).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+	if err := transaction.Where("id like ?", source.ID).Delete(&User{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (u PostgresRepo) UpdateUserStatus(user api.User, status string, updatedBy string) (*api.User, error) {
+	userDB := User{
+		ID:             user.ID,
+		ExternalID:     user.ExternalID,
+		Path:           user.Path,
+		DisplayName:    user.DisplayName,
+		Description:    user.Description,
+		ServiceAccount: user.ServiceAccount,
+		Status:         status,
+		CreateAt:       user.CreateAt.UnixNano(),
+		UpdateAt:       time.Now().UTC().UnixNano(),
+		CreatedBy:      user.CreatedBy,
+		UpdatedBy:      updatedBy,
+		Urn:            user.Urn,
+		Version:        user.Version + 1,
+	}
+
+	// Update user only if it's still in the same version
+	query := u.Dbmap.Model(&User{ID: user.ID}).Where("version = ?", user.Version).Updates(map[string]interface{}{
+		"status":     userDB.Status,
+		"update_at":  userDB.UpdateAt,
+		"updated_by": userDB.UpdatedBy,
+		"version":    userDB.Version,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if user was modified meanwhile
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.VERSION_MISMATCH,
+			Message: fmt.Sprintf("User with externalId %v isn't in version %v", user.ExternalID, user.Version),
+		}
+	}
+
+	return dbUserToAPIUser(&userDB), nil
+}
+
 // PRIVATE HELPER METHODS
 
+// Replace externalId of user in its access requests and in the requests reviewed by it
+func (u PostgresRepo) renameAccessRequestUsers(transaction *gorm.DB, userID string, externalId string, newExternalId string) error {
+	err := transaction.Model(&AccessRequest{}).Where("user_id like ?", userID).UpdateColumn("requester", newExternalId).Error
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	err = transaction.Model(&AccessRequest{}).Where("reviewer like ?", externalId).UpdateColumn("reviewer", newExternalId).Error
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 // Transform a user retrieved from db into a user for API
 func dbUserToAPIUser(userdb *User) *api.User {
 	return &api.User{
-		ID:         userdb.ID,
-		ExternalID: userdb.ExternalID,
-		Path:       userdb.Path,
-		CreateAt:   time.Unix(0, userdb.CreateAt).UTC(),
-		Urn:        userdb.Urn,
+		ID:             userdb.ID,
+		ExternalID:     userdb.ExternalID,
+		Path:           userdb.Path,
+		DisplayName:    userdb.DisplayName,
+		Description:    userdb.Description,
+		ServiceAccount: userdb.ServiceAccount,
+		Status:         userdb.Status,
+		CreateAt:       time.Unix(0, userdb.CreateAt).UTC(),
+		UpdateAt:       dbUpdateTimeToAPITime(userdb.UpdateAt, userdb.CreateAt),
+		CreatedBy:      userdb.CreatedBy,
+		UpdatedBy:      userdb.UpdatedBy,
+		Urn:            userdb.Urn,
+		Version:        userdb.Version,
 	}
 }
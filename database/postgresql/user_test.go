@@ -25,15 +25,21 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			expectedResponse: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 		},
 		"ErrorCaseUserAlreadyExist": {
@@ -41,15 +47,21 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			userToCreate: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			expectedError: &database.Error{
 				Code:    database.INTERNAL_ERROR,
@@ -125,16 +137,22 @@ func TestPostgresRepo_GetUserByExternalID(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			externalID: "ExternalID",
 			expectedResponse: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -142,8 +160,11 @@ func TestPostgresRepo_GetUserByExternalID(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			externalID: "NotExist",
 			expectedError: &database.Error{
@@ -208,16 +229,22 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			userID: "UserID",
 			expectedResponse: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -225,8 +252,11 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "urn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			userID: "NotExist",
 			expectedError: &database.Error{
@@ -292,15 +322,21 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					ID:         "UserID1",
 					ExternalID: "ExternalID1",
 					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn1",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 				{
 					ID:         "UserID2",
 					ExternalID: "ExternalID2",
 					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn2",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 			},
 			pathPrefix: "Path",
@@ -309,15 +345,21 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					ID:         "UserID1",
 					ExternalID: "ExternalID1",
 					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn1",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 				{
 					ID:         "UserID2",
 					ExternalID: "ExternalID2",
 					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn2",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 			},
 		},
@@ -327,15 +369,21 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					ID:         "UserID1",
 					ExternalID: "ExternalID1",
 					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn1",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 				{
 					ID:         "UserID2",
 					ExternalID: "ExternalID2",
 					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn2",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 			},
 			pathPrefix: "Path123",
@@ -344,8 +392,11 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					ID:         "UserID1",
 					ExternalID: "ExternalID1",
 					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn1",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 			},
 		},
@@ -355,15 +406,21 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					ID:         "UserID1",
 					ExternalID: "ExternalID1",
 					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn1",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 				{
 					ID:         "UserID2",
 					ExternalID: "ExternalID2",
 					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
 					Urn:        "urn2",
 					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
 				},
 			},
 			pathPrefix:       "NoPath",
@@ -406,35 +463,118 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 		// Previous data
 		previousUser *api.User
 		// Postgres Repo Args
-		userToUpdate *api.User
-		newPath      string
-		newUrn       string
+		userToUpdate   *api.User
+		newPath        string
+		newUrn         string
+		newDisplayName string
+		newDescription string
+		updatedBy      string
 		// Expected result
 		expectedResponse *api.User
+		expectedError    *database.Error
 	}{
 		"OkCase": {
 			previousUser: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "OldPath",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			userToUpdate: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "OldPath",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
-			newPath: "NewPath",
-			newUrn:  "NewUrn",
+			newPath:        "NewPath",
+			newUrn:         "NewUrn",
+			newDisplayName: "DisplayName",
+			newDescription: "Description",
+			updatedBy:      "UpdaterID",
+			expectedResponse: &api.User{
+				ID:          "UserID",
+				ExternalID:  "ExternalID",
+				Path:        "NewPath",
+				Status:      api.USER_STATUS_ACTIVE,
+				DisplayName: "DisplayName",
+				Description: "Description",
+				Urn:         "NewUrn",
+				CreateAt:    now,
+				UpdatedBy:   "UpdaterID",
+				Version:     2,
+			},
+		},
+		"OkCaseServiceAccount": {
+			previousUser: &api.User{
+				ID:             "UserID",
+				ExternalID:     "ExternalID",
+				Path:           "OldPath",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "Oldurn",
+				CreateAt:       now,
+				UpdateAt:       now,
+				Version:        1,
+			},
+			userToUpdate: &api.User{
+				ID:             "UserID",
+				ExternalID:     "ExternalID",
+				Path:           "OldPath",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "Oldurn",
+				CreateAt:       now,
+				UpdateAt:       now,
+				Version:        1,
+			},
+			newPath:   "NewPath",
+			newUrn:    "NewUrn",
+			updatedBy: "UpdaterID",
 			expectedResponse: &api.User{
+				ID:             "UserID",
+				ExternalID:     "ExternalID",
+				Path:           "NewPath",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "NewUrn",
+				CreateAt:       now,
+				UpdatedBy:      "UpdaterID",
+				Version:        2,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			previousUser: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
-				Path:       "NewPath",
-				Urn:        "NewUrn",
+				Path:       "OldPath",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "Oldurn",
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "OldPath",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "Oldurn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    2,
+			},
+			newPath: "NewPath",
+			newUrn:  "NewUrn",
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with externalId ExternalID isn't in version 2",
 			},
 		},
 	}
@@ -445,19 +585,42 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 
 		// Insert previous data
 		if test.previousUser != nil {
-			if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+			insert := insertUser
+			if test.previousUser.ServiceAccount {
+				insert = insertServiceAccount
+			}
+			if err := insert(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
 				test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
 				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
 				continue
 			}
 		}
 		// Call to repository to update an user
-		updatedUser, err := repoDB.UpdateUser(*test.userToUpdate, test.newPath, test.newUrn)
+		updatedUser, err := repoDB.UpdateUser(*test.userToUpdate, test.newPath, test.newUrn, test.newDisplayName,
+			test.newDescription, test.updatedBy)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			continue
+		}
 
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
+		// Update time is set by the repository
+		if updatedUser.UpdateAt.Before(now) {
+			t.Errorf("Test %v failed. Received update time %v before %v", n, updatedUser.UpdateAt, now)
+			continue
+		}
+		test.expectedResponse.UpdateAt = updatedUser.UpdateAt
 		// Check response
 		if diff := pretty.Compare(updatedUser, test.expectedResponse); diff != "" {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -474,6 +637,15 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 			t.Fatalf("Test %v failed. Received different user number: %v", n, userNumber)
 			continue
 		}
+		storedUser, err := repoDB.GetUserByID(test.expectedResponse.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if storedUser.ServiceAccount != test.expectedResponse.ServiceAccount {
+			t.Errorf("Test %v failed. Received different service account: %v", n, storedUser.ServiceAccount)
+			continue
+		}
 
 	}
 }
@@ -490,14 +662,20 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 		}
 		// Postgres Repo Args
 		userToDelete string
+		version      int64
+		// Expected result
+		expectedError *database.Error
 	}{
 		"OkCase": {
 			previousUser: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
 				Path:       "OldPath",
+				Status:     api.USER_STATUS_ACTIVE,
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
 			},
 			relation: &struct {
 				user_id       string
@@ -508,6 +686,22 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 				group_ids: []string{"GroupID1", "GroupID2"},
 			},
 			userToDelete: "UserID",
+			version:      1,
+		},
+		"ErrorCaseVersionMismatch": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "OldPath",
+				Urn:        "Oldurn",
+				CreateAt:   now,
+			},
+			userToDelete: "UserID",
+			version:      2,
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with id UserID isn't in version 2",
+			},
 		},
 	}
 
@@ -533,17 +727,40 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 			}
 		}
 		// Call to repository to remove user
-		err := repoDB.RemoveUser(test.userToDelete)
+		err := repoDB.RemoveUser(test.userToDelete, test.version)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database, user isn't marked as deleted
+			if _, err := repoDB.GetUserByID(test.userToDelete); err != nil {
+				t.Errorf("Test %v failed. User was deleted: %v", n, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
 
-		// Check database
-		userNumber, err := getUsersCountFiltered(test.userToDelete, "", "",
-			0, "", "")
+		// Check database, user is kept marked as deleted
+		deleteAt, err := getDeleteAt(User{}.TableName(), test.userToDelete)
 		if err != nil {
-			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
 			continue
 		}
-		if userNumber != 0 {
-			t.Errorf("Test %v failed. Received different user number: %v", n, userNumber)
+		if deleteAt == 0 {
+			t.Errorf("Test %v failed. User isn't marked as deleted", n)
+			continue
+		}
+		if _, err := repoDB.GetUserByID(test.userToDelete); err == nil {
+			t.Errorf("Test %v failed. Deleted user is still retrieved", n)
 			continue
 		}
 
@@ -552,7 +769,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
 			continue
 		}
-		if relations != 0 {
+		if relations != len(test.relation.group_ids) {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 			continue
 		}
@@ -569,6 +786,8 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 			groups        []api.Group
 			groupNotFound bool
 		}
+		deletedGroupIDs []string
+		expiredGroupIDs []string
 		// Postgres Repo Args
 		userID string
 		// Expected result
@@ -589,6 +808,8 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 						Path:     "Path1",
 						Urn:      "urn1",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Org:      "Org",
 					},
 					{
@@ -597,6 +818,8 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 						Path:     "Path2",
 						Urn:      "urn2",
 						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
 						Org:      "Org",
 					},
 				},
@@ -609,6 +832,8 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 					Path:     "Path1",
 					Urn:      "urn1",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org",
 				},
 				{
@@ -617,6 +842,98 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 					Path:     "Path2",
 					Urn:      "urn2",
 					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+			},
+		},
+		"OkCaseDeletedGroupIgnored": {
+			relation: &struct {
+				user_id       string
+				groups        []api.Group
+				groupNotFound bool
+			}{
+				user_id: "UserID",
+				groups: []api.Group{
+					{
+						ID:       "GroupID1",
+						Name:     "Name1",
+						Path:     "Path1",
+						Urn:      "urn1",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Org:      "Org",
+					},
+					{
+						ID:       "GroupID2",
+						Name:     "Name2",
+						Path:     "Path2",
+						Urn:      "urn2",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Org:      "Org",
+					},
+				},
+			},
+			deletedGroupIDs: []string{"GroupID2"},
+			userID:          "UserID",
+			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path1",
+					Urn:      "urn1",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
+					Org:      "Org",
+				},
+			},
+		},
+		"OkCaseExpiredMembershipIgnored": {
+			relation: &struct {
+				user_id       string
+				groups        []api.Group
+				groupNotFound bool
+			}{
+				user_id: "UserID",
+				groups: []api.Group{
+					{
+						ID:       "GroupID1",
+						Name:     "Name1",
+						Path:     "Path1",
+						Urn:      "urn1",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Org:      "Org",
+					},
+					{
+						ID:       "GroupID2",
+						Name:     "Name2",
+						Path:     "Path2",
+						Urn:      "urn2",
+						CreateAt: now,
+						UpdateAt: now,
+						Version:  1,
+						Org:      "Org",
+					},
+				},
+			},
+			expiredGroupIDs: []string{"GroupID2"},
+			userID:          "UserID",
+			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path1",
+					Urn:      "urn1",
+					CreateAt: now,
+					UpdateAt: now,
+					Version:  1,
 					Org:      "Org",
 				},
 			},
@@ -655,7 +972,13 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 		// Insert previous data
 		if test.relation != nil {
 			for _, group := range test.relation.groups {
-				if err := insertGroupUserRelation(test.relation.user_id, group.ID); err != nil {
+				var expireAt int64
+				for _, id := range test.expiredGroupIDs {
+					if id == group.ID {
+						expireAt = now.Add(-time.Hour).UnixNano()
+					}
+				}
+				if err := insertExpiringGroupUserRelation(test.relation.user_id, group.ID, expireAt); err != nil {
 					t.Errorf("Test %v failed. Unexpected error inserting prevoius group user relations: %v", n, err)
 					continue
 				}
@@ -668,6 +991,12 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 				}
 			}
 		}
+		for _, id := range test.deletedGroupIDs {
+			if err := markAsDeleted(Group{}.TableName(), id, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking group as deleted: %v", n, err)
+				continue
+			}
+		}
 		// Call to repository to get groups associated
 		receivedUsers, err := repoDB.GetGroupsByUserID(test.userID)
 		if test.expectedError != nil {
@@ -694,3 +1023,885 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 
 	}
 }
+
+func TestPostgresRepo_GetDeletedUserByExternalID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser *api.User
+		deleted      bool
+		// Postgres Repo Args
+		externalID string
+		// Expected result
+		expectedResponse *api.User
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			deleted:    true,
+			externalID: "ExternalID",
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+		},
+		"ErrorCaseUserNotDeleted": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			externalID: "ExternalID",
+			expectedError: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "Deleted user with externalId ExternalID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user database
+		cleanUserTable()
+
+		// Insert previous data
+		if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+			test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		if test.deleted {
+			if err := markAsDeleted(User{}.TableName(), test.previousUser.ID, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking user as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get deleted user
+		receivedUser, err := repoDB.GetDeletedUserByExternalID(test.externalID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedUser, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetDeletedUsers(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUsers  []api.User
+		deletedUserIDs []string
+		// Postgres Repo Args
+		pathPrefix string
+		// Expected result
+		expectedResponse []api.User
+	}{
+		"OkCase": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn1",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn2",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+			},
+			deletedUserIDs: []string{"UserID2"},
+			pathPrefix:     "Path",
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn2",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+			},
+		},
+		"OkCaseNoDeletedUsers": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn1",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+			},
+			pathPrefix:       "Path",
+			expectedResponse: []api.User{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user database
+		cleanUserTable()
+
+		// Insert previous data
+		for _, u := range test.previousUsers {
+			if err := insertUser(u.ID, u.ExternalID, u.Path, u.CreateAt.UnixNano(), u.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		for _, id := range test.deletedUserIDs {
+			if err := markAsDeleted(User{}.TableName(), id, now.UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking user as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get deleted users
+		receivedUsers, err := repoDB.GetDeletedUsers(test.pathPrefix)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedUsers, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RestoreUser(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser *api.User
+		groupIDs     []string
+		// Postgres Repo Args
+		userToRestore string
+	}{
+		"OkCase": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			groupIDs:      []string{"GroupID1", "GroupID2"},
+			userToRestore: "UserID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+			test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+			continue
+		}
+		for _, id := range test.groupIDs {
+			if err := insertGroupUserRelation(test.previousUser.ID, id); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+		if err := markAsDeleted(User{}.TableName(), test.previousUser.ID, now.UnixNano()); err != nil {
+			t.Errorf("Test %v failed. Unexpected error marking user as deleted: %v", n, err)
+			continue
+		}
+
+		// Call to repository to restore user
+		if err := repoDB.RestoreUser(test.userToRestore); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		deleteAt, err := getDeleteAt(User{}.TableName(), test.userToRestore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if deleteAt != 0 {
+			t.Errorf("Test %v failed. User is still marked as deleted: %v", n, deleteAt)
+			continue
+		}
+		relations, err := getGroupUserRelations("", test.userToRestore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != len(test.groupIDs) {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_PurgeUsers(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUsers []api.User
+		deleteAt      map[string]int64
+		// Postgres Repo Args
+		deletedBefore time.Time
+		// Expected result
+		expectedPurged    int64
+		expectedRelations int
+	}{
+		"OkCase": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn1",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn2",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+				{
+					ID:         "UserID3",
+					ExternalID: "ExternalID3",
+					Path:       "Path",
+					Status:     api.USER_STATUS_ACTIVE,
+					Urn:        "urn3",
+					CreateAt:   now,
+					UpdateAt:   now,
+					Version:    1,
+				},
+			},
+			deleteAt: map[string]int64{
+				"UserID1": now.Add(-2 * time.Hour).UnixNano(),
+				"UserID2": now.UnixNano(),
+			},
+			deletedBefore:     now.Add(-time.Hour),
+			expectedPurged:    1,
+			expectedRelations: 2,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		for _, u := range test.previousUsers {
+			if err := insertUser(u.ID, u.ExternalID, u.Path, u.CreateAt.UnixNano(), u.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+			if err := insertGroupUserRelation(u.ID, "GroupID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+		for id, deleteAt := range test.deleteAt {
+			if err := markAsDeleted(User{}.TableName(), id, deleteAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error marking user as deleted: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to purge users
+		purged, err := repoDB.PurgeUsers(test.deletedBefore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if purged != test.expectedPurged {
+			t.Errorf("Test %v failed. Received different purged number: %v", n, purged)
+			continue
+		}
+
+		// Check database
+		userNumber, err := getUsersCountFiltered("", "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			continue
+		}
+		if userNumber != len(test.previousUsers)-int(test.expectedPurged) {
+			t.Errorf("Test %v failed. Received different user number: %v", n, userNumber)
+			continue
+		}
+		relations, err := getGroupUserRelations("GroupID", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RenameUser(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser           *api.User
+		previousAccessRequests []api.AccessRequest
+		// Postgres Repo Args
+		userToRename  *api.User
+		newExternalID string
+		newUrn        string
+		updatedBy     string
+		// Expected result
+		expectedResponse   *api.User
+		expectedRequesters map[string]string
+		expectedReviewers  map[string]string
+		expectedError      *database.Error
+	}{
+		"OkCase": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			previousAccessRequests: []api.AccessRequest{
+				{
+					ID:        "RequestID1",
+					UserID:    "UserID",
+					GroupID:   "GroupID",
+					Requester: "ExternalID",
+					Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt:  now,
+				},
+				{
+					ID:        "RequestID2",
+					UserID:    "OtherUserID",
+					GroupID:   "GroupID",
+					Requester: "OtherExternalID",
+					Status:    api.ACCESS_REQUEST_STATUS_APPROVED,
+					Reviewer:  "ExternalID",
+					CreateAt:  now,
+				},
+			},
+			userToRename: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			newExternalID: "NewExternalID",
+			newUrn:        "NewUrn",
+			updatedBy:     "UpdaterID",
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "NewExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "NewUrn",
+				CreateAt:   now,
+				UpdatedBy:  "UpdaterID",
+				Version:    2,
+			},
+			expectedRequesters: map[string]string{
+				"RequestID1": "NewExternalID",
+				"RequestID2": "OtherExternalID",
+			},
+			expectedReviewers: map[string]string{
+				"RequestID1": "",
+				"RequestID2": "NewExternalID",
+			},
+		},
+		"OkCaseServiceAccount": {
+			previousUser: &api.User{
+				ID:             "UserID",
+				ExternalID:     "ExternalID",
+				Path:           "Path",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "urn",
+				CreateAt:       now,
+				UpdateAt:       now,
+				Version:        1,
+			},
+			userToRename: &api.User{
+				ID:             "UserID",
+				ExternalID:     "ExternalID",
+				Path:           "Path",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "urn",
+				CreateAt:       now,
+				UpdateAt:       now,
+				Version:        1,
+			},
+			newExternalID: "NewExternalID",
+			newUrn:        "NewUrn",
+			updatedBy:     "UpdaterID",
+			expectedResponse: &api.User{
+				ID:             "UserID",
+				ExternalID:     "NewExternalID",
+				Path:           "Path",
+				ServiceAccount: true,
+				Status:         api.USER_STATUS_ACTIVE,
+				Urn:            "NewUrn",
+				CreateAt:       now,
+				UpdatedBy:      "UpdaterID",
+				Version:        2,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    1,
+			},
+			userToRename: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdateAt:   now,
+				Version:    2,
+			},
+			newExternalID: "NewExternalID",
+			newUrn:        "NewUrn",
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with externalId ExternalID isn't in version 2",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousUser != nil {
+			insert := insertUser
+			if test.previousUser.ServiceAccount {
+				insert = insertServiceAccount
+			}
+			if err := insert(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+				test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous user: %v", n, err)
+				continue
+			}
+		}
+		for _, r := range test.previousAccessRequests {
+			if err := insertAccessRequest(r); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous access requests: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to rename user
+		renamedUser, err := repoDB.RenameUser(*test.userToRename, test.newExternalID, test.newUrn, test.updatedBy)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Update time is set by the repository
+		if renamedUser.UpdateAt.Before(now) {
+			t.Errorf("Test %v failed. Received update time %v before %v", n, renamedUser.UpdateAt, now)
+			continue
+		}
+		test.expectedResponse.UpdateAt = renamedUser.UpdateAt
+
+		// Check response
+		if diff := pretty.Compare(renamedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// Check database
+		userNumber, err := getUsersCountFiltered(test.expectedResponse.ID, test.expectedResponse.ExternalID, test.expectedResponse.Path,
+			test.expectedResponse.CreateAt.UnixNano(), test.expectedResponse.Urn, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			continue
+		}
+		if userNumber != 1 {
+			t.Errorf("Test %v failed. Received different user number: %v", n, userNumber)
+			continue
+		}
+		storedUser, err := repoDB.GetUserByID(test.expectedResponse.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if storedUser.ServiceAccount != test.expectedResponse.ServiceAccount {
+			t.Errorf("Test %v failed. Received different service account: %v", n, storedUser.ServiceAccount)
+			continue
+		}
+		for id, requester := range test.expectedRequesters {
+			request, err := repoDB.GetAccessRequestByID(id)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving access request %v: %v", n, id, err)
+				continue
+			}
+			if request.Requester != requester || request.Reviewer != test.expectedReviewers[id] {
+				t.Errorf("Test %v failed. Received different requester/reviewer for access request %v: %v/%v",
+					n, id, request.Requester, request.Reviewer)
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_MergeUsers(t *testing.T) {
+	now := time.Now().UTC()
+	source := api.User{
+		ID:         "SourceID",
+		ExternalID: "SourceExternalID",
+		Path:       "Path",
+		Status:     api.USER_STATUS_ACTIVE,
+		Urn:        "urn1",
+		CreateAt:   now,
+		UpdateAt:   now,
+		Version:    1,
+	}
+	target := api.User{
+		ID:         "TargetID",
+		ExternalID: "TargetExternalID",
+		Path:       "Path",
+		Status:     api.USER_STATUS_ACTIVE,
+		Urn:        "urn2",
+		CreateAt:   now,
+		UpdateAt:   now,
+		Version:    1,
+	}
+	testcases := map[string]struct {
+		// Previous data
+		sourceRelations        map[string]int64
+		targetRelations        map[string]int64
+		previousAccessRequests []api.AccessRequest
+		// Expected result
+		expectedRelations  map[string]int64
+		expectedRequesters map[string]string
+	}{
+		"OkCase": {
+			sourceRelations: map[string]int64{
+				"GroupID1": 0,
+				"GroupID2": 0,
+				"GroupID3": now.Add(2 * time.Hour).UnixNano(),
+			},
+			targetRelations: map[string]int64{
+				"GroupID2": now.Add(time.Hour).UnixNano(),
+				"GroupID3": now.Add(time.Hour).UnixNano(),
+				"GroupID4": 0,
+			},
+			previousAccessRequests: []api.AccessRequest{
+				{
+					ID:        "RequestID1",
+					UserID:    "SourceID",
+					GroupID:   "GroupID5",
+					Requester: "SourceExternalID",
+					Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt:  now,
+				},
+			},
+			expectedRelations: map[string]int64{
+				"GroupID1": 0,
+				"GroupID2": 0,
+				"GroupID3": now.Add(2 * time.Hour).UnixNano(),
+				"GroupID4": 0,
+			},
+			expectedRequesters: map[string]string{
+				"RequestID1": "TargetExternalID",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupUserRelationTable()
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		for _, u := range []api.User{source, target} {
+			if err := insertUser(u.ID, u.ExternalID, u.Path, u.CreateAt.UnixNano(), u.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		for groupID, expireAt := range test.sourceRelations {
+			if err := insertExpiringGroupUserRelation(source.ID, groupID, expireAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+		for groupID, expireAt := range test.targetRelations {
+			if err := insertExpiringGroupUserRelation(target.ID, groupID, expireAt); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+		for _, r := range test.previousAccessRequests {
+			if err := insertAccessRequest(r); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous access requests: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to merge users
+		if err := repoDB.MergeUsers(source, target); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		userNumber, err := getUsersCountFiltered(source.ID, "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			continue
+		}
+		if userNumber != 0 {
+			t.Errorf("Test %v failed. Source user wasn't removed", n)
+			continue
+		}
+		relations, err := getGroupUserRelations("", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != len(test.expectedRelations) {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+		for groupID, expireAt := range test.expectedRelations {
+			relationExpireAt, err := getGroupUserRelationExpireAt(groupID, target.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving relation with group %v: %v", n, groupID, err)
+				continue
+			}
+			if relationExpireAt != expireAt {
+				t.Errorf("Test %v failed. Received different expiration for group %v: %v", n, groupID, relationExpireAt)
+			}
+		}
+		for id, requester := range test.expectedRequesters {
+			request, err := repoDB.GetAccessRequestByID(id)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving access request %v: %v", n, id, err)
+				continue
+			}
+			if request.Requester != requester || request.UserID != target.ID {
+				t.Errorf("Test %v failed. Access request %v wasn't moved to target user: %v", n, id, request)
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateUserStatus(t *testing.T) {
+	now := time.Unix(0, time.Now().UTC().UnixNano()).UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser *api.User
+		// Postgres Repo Args
+		userToUpdate *api.User
+		status       string
+		updatedBy    string
+		// Expected result
+		expectedResponse *api.User
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				Version:    1,
+			},
+			status:    api.USER_STATUS_SUSPENDED,
+			updatedBy: "UpdaterID",
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_SUSPENDED,
+				Urn:        "urn",
+				CreateAt:   now,
+				UpdatedBy:  "UpdaterID",
+				Version:    2,
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+				CreateAt:   now,
+				Version:    2,
+			},
+			status: api.USER_STATUS_SUSPENDED,
+			expectedError: &database.Error{
+				Code:    database.VERSION_MISMATCH,
+				Message: "User with externalId ExternalID isn't in version 2",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user database
+		cleanUserTable()
+
+		// Insert previous data
+		if test.previousUser != nil {
+			if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+				test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to update status of user
+		updatedUser, err := repoDB.UpdateUserStatus(*test.userToUpdate, test.status, test.updatedBy)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		test.expectedResponse.UpdateAt = updatedUser.UpdateAt
+		// Check response
+		if diff := pretty.Compare(updatedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		storedUser, err := repoDB.GetUserByID(test.userToUpdate.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different stored user (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// WEBHOOK REPOSITORY IMPLEMENTATION
+
+func (r PostgresRepo) AddWebhook(webhook api.Webhook) (*api.Webhook, error) {
+
+	// Create webhook model
+	webhookDB := &Webhook{
+		ID:         webhook.ID,
+		Url:        webhook.Url,
+		EventTypes: strings.Join(webhook.EventTypes, ";"),
+		Secret:     webhook.Secret,
+		Urn:        webhook.Urn,
+		CreatedBy:  webhook.CreatedBy,
+		CreateAt:   webhook.CreateAt.UnixNano(),
+		UpdateAt:   webhook.UpdateAt.UnixNano(),
+	}
+
+	// Store webhook
+	err := r.Dbmap.Create(webhookDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookToAPIWebhook(webhookDB), nil
+}
+
+func (r PostgresRepo) GetWebhookByID(id string) (*api.Webhook, error) {
+	webhook := &Webhook{}
+	query := r.Dbmap.Where("id like ?", id).First(webhook)
+
+	// Check if webhook exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.WEBHOOK_NOT_FOUND,
+			Message: fmt.Sprintf("Webhook with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookToAPIWebhook(webhook), nil
+}
+
+func (r PostgresRepo) GetWebhooks() ([]api.Webhook, error) {
+	webhooks := []Webhook{}
+
+	// Error handling
+	if err := r.Dbmap.Order("create_at").Find(&webhooks).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform webhooks for API
+	if webhooks != nil {
+		apiWebhooks := make([]api.Webhook, len(webhooks), cap(webhooks))
+		for i, webhook := range webhooks {
+			apiWebhooks[i] = *dbWebhookToAPIWebhook(&webhook)
+		}
+		return apiWebhooks, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (r PostgresRepo) UpdateWebhook(webhook api.Webhook) (*api.Webhook, error) {
+	update := map[string]interface{}{
+		"url":         webhook.Url,
+		"event_types": strings.Join(webhook.EventTypes, ";"),
+		"secret":      webhook.Secret,
+		"update_at":   webhook.UpdateAt.UTC().UnixNano(),
+	}
+
+	// Update webhook
+	query := r.Dbmap.Model(&Webhook{}).Where("id like ?", webhook.ID).Updates(update)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Check if webhook was removed meanwhile
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.WEBHOOK_NOT_FOUND,
+			Message: fmt.Sprintf("Webhook with id %v not found", webhook.ID),
+		}
+	}
+
+	return r.GetWebhookByID(webhook.ID)
+}
+
+func (r PostgresRepo) RemoveWebhook(id string) error {
+	transaction := r.Dbmap.Begin()
+
+	// Delete deliveries
+	if err := transaction.Where("webhook_id like ?", id).Delete(&WebhookDelivery{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete webhook
+	if err := transaction.Where("id like ?", id).Delete(&Webhook{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r PostgresRepo) AddWebhookDeliveries(deliveries []api.WebhookDelivery) error {
+	transaction := r.Dbmap.Begin()
+
+	for _, delivery := range deliveries {
+		event, err := json.Marshal(delivery.Event)
+		if err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+
+		// Create webhook delivery model
+		deliveryDB := &WebhookDelivery{
+			ID:             delivery.ID,
+			WebhookID:      delivery.WebhookID,
+			EventID:        delivery.Event.ID,
+			EventType:      delivery.Event.Type,
+			Event:          string(event),
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			NextAttemptAt:  delivery.NextAttemptAt.UnixNano(),
+			CreateAt:       delivery.CreateAt.UnixNano(),
+			UpdateAt:       delivery.UpdateAt.UnixNano(),
+		}
+
+		// Store webhook delivery
+		if err := transaction.Create(deliveryDB).Error; err != nil {
+			transaction.Rollback()
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r PostgresRepo) GetWebhookDeliveries(webhookID string, status string) ([]api.WebhookDelivery, error) {
+	query := r.Dbmap.Where("webhook_id like ?", webhookID)
+	if len(status) > 0 {
+		query = query.Where("status = ?", status)
+	}
+
+	return findWebhookDeliveries(query)
+}
+
+func (r PostgresRepo) GetPendingWebhookDeliveries(dueBefore time.Time) ([]api.WebhookDelivery, error) {
+	query := r.Dbmap.Where("status = ? AND next_attempt_at <= ?", api.WEBHOOK_DELIVERY_STATUS_PENDING, dueBefore.UTC().UnixNano())
+
+	return findWebhookDeliveries(query)
+}
+
+func (r PostgresRepo) UpdateWebhookDelivery(delivery api.WebhookDelivery) (*api.WebhookDelivery, error) {
+	update := map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"next_attempt_at":  delivery.NextAttemptAt.UTC().UnixNano(),
+		"update_at":        delivery.UpdateAt.UTC().UnixNano(),
+	}
+
+	// Update webhook delivery
+	if err := r.Dbmap.Model(&WebhookDelivery{}).Where("id like ?", delivery.ID).Updates(update).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return &delivery, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve webhook deliveries of a query sorted by creation time
+func findWebhookDeliveries(query *gorm.DB) ([]api.WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+
+	// Error handling
+	if err := query.Order("create_at").Find(&deliveries).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform webhook deliveries for API
+	if deliveries != nil {
+		apiDeliveries := make([]api.WebhookDelivery, len(deliveries), cap(deliveries))
+		for i, delivery := range deliveries {
+			apiDelivery, err := dbWebhookDeliveryToAPIWebhookDelivery(&delivery)
+			if err != nil {
+				return nil, &database.Error{
+					Code:    database.INTERNAL_ERROR,
+					Message: err.Error(),
+				}
+			}
+			apiDeliveries[i] = *apiDelivery
+		}
+		return apiDeliveries, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+// Transform a webhook retrieved from db into a webhook for API
+func dbWebhookToAPIWebhook(webhookdb *Webhook) *api.Webhook {
+	return &api.Webhook{
+		ID:         webhookdb.ID,
+		Url:        webhookdb.Url,
+		EventTypes: strings.Split(webhookdb.EventTypes, ";"),
+		Secret:     webhookdb.Secret,
+		Urn:        webhookdb.Urn,
+		CreatedBy:  webhookdb.CreatedBy,
+		CreateAt:   time.Unix(0, webhookdb.CreateAt).UTC(),
+		UpdateAt:   time.Unix(0, webhookdb.UpdateAt).UTC(),
+	}
+}
+
+// Transform a webhook delivery retrieved from db into a webhook delivery for API
+func dbWebhookDeliveryToAPIWebhookDelivery(deliverydb *WebhookDelivery) (*api.WebhookDelivery, error) {
+	event := api.WebhookEvent{}
+	if err := json.Unmarshal([]byte(deliverydb.Event), &event); err != nil {
+		return nil, err
+	}
+	return &api.WebhookDelivery{
+		ID:             deliverydb.ID,
+		WebhookID:      deliverydb.WebhookID,
+		Event:          event,
+		Status:         deliverydb.Status,
+		Attempts:       deliverydb.Attempts,
+		LastStatusCode: deliverydb.LastStatusCode,
+		LastError:      deliverydb.LastError,
+		NextAttemptAt:  time.Unix(0, deliverydb.NextAttemptAt).UTC(),
+		CreateAt:       time.Unix(0, deliverydb.CreateAt).UTC(),
+		UpdateAt:       time.Unix(0, deliverydb.UpdateAt).UTC(),
+	}, nil
+}
@@ -0,0 +1,530 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook *api.Webhook
+		// Postgres Repo Args
+		webhookToCreate *api.Webhook
+		// Expected result
+		expectedResponse *api.Webhook
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			webhookToCreate: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER, api.USER_ACTION_DELETE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreatedBy:  "admin",
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			expectedResponse: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER, api.USER_ACTION_DELETE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreatedBy:  "admin",
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+		},
+		"ErrorCaseWebhookAlreadyExist": {
+			previousWebhook: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			webhookToCreate: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"webhooks_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		if test.previousWebhook != nil {
+			if _, err := repoDB.AddWebhook(*test.previousWebhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to store webhook
+		receivedWebhook, err := repoDB.AddWebhook(*test.webhookToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedWebhook, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			webhookNumber, err := getWebhooksCountFiltered(test.webhookToCreate.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting webhooks: %v", n, err)
+				continue
+			}
+			if webhookNumber != 1 {
+				t.Errorf("Test %v failed. Received different webhook number: %v", n, webhookNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetWebhookByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook *api.Webhook
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.Webhook
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousWebhook: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			id: "WebhookID",
+			expectedResponse: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+		},
+		"ErrorCaseWebhookNotExist": {
+			id: "WebhookID",
+			expectedError: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WebhookID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		if test.previousWebhook != nil {
+			if _, err := repoDB.AddWebhook(*test.previousWebhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to get webhook
+		receivedWebhook, err := repoDB.GetWebhookByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedWebhook, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook *api.Webhook
+		// Postgres Repo Args
+		webhookToUpdate *api.Webhook
+		// Expected result
+		expectedResponse *api.Webhook
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousWebhook: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			webhookToUpdate: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{api.USER_ACTION_DELETE_USER, api.GROUP_ACTION_ADD_MEMBER},
+				Secret:     "newsecret",
+				UpdateAt:   later,
+			},
+			expectedResponse: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{api.USER_ACTION_DELETE_USER, api.GROUP_ACTION_ADD_MEMBER},
+				Secret:     "newsecret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   later,
+			},
+		},
+		"ErrorCaseWebhookNotExist": {
+			webhookToUpdate: &api.Webhook{
+				ID:       "WebhookID",
+				UpdateAt: later,
+			},
+			expectedError: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WebhookID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		if test.previousWebhook != nil {
+			if _, err := repoDB.AddWebhook(*test.previousWebhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to update webhook
+		receivedWebhook, err := repoDB.UpdateWebhook(*test.webhookToUpdate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedWebhook, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook    *api.Webhook
+		previousDeliveries []api.WebhookDelivery
+		// Postgres Repo Args
+		id string
+	}{
+		"OkCase": {
+			previousWebhook: &api.Webhook{
+				ID:         "WebhookID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WebhookID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			previousDeliveries: []api.WebhookDelivery{
+				{
+					ID:        "DeliveryID",
+					WebhookID: "WebhookID",
+					Event: api.WebhookEvent{
+						ID:       "EventID",
+						Type:     api.USER_ACTION_CREATE_USER,
+						CreateAt: now,
+					},
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					NextAttemptAt: now,
+					CreateAt:      now,
+					UpdateAt:      now,
+				},
+			},
+			id: "WebhookID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		if test.previousWebhook != nil {
+			if _, err := repoDB.AddWebhook(*test.previousWebhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		if err := repoDB.AddWebhookDeliveries(test.previousDeliveries); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous deliveries: %v", n, err)
+			continue
+		}
+
+		// Call to repository to remove webhook
+		if err := repoDB.RemoveWebhook(test.id); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		webhookNumber, err := getWebhooksCountFiltered(test.id)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhooks: %v", n, err)
+			continue
+		}
+		deliveryNumber, err := getWebhookDeliveriesCountFiltered(test.id, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhook deliveries: %v", n, err)
+			continue
+		}
+		if webhookNumber != 0 || deliveryNumber != 0 {
+			t.Errorf("Test %v failed. Webhook or deliveries not removed: %v/%v", n, webhookNumber, deliveryNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetWebhookDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	event := api.WebhookEvent{
+		ID:       "EventID",
+		Type:     api.USER_ACTION_CREATE_USER,
+		Urn:      api.CreateUrn("", api.RESOURCE_USER, "/path/", "123"),
+		After:    json.RawMessage(`{"externalId":"123"}`),
+		CreateAt: now,
+	}
+	deliveries := []api.WebhookDelivery{
+		{
+			ID:            "DeliveryID1",
+			WebhookID:     "WebhookID",
+			Event:         event,
+			Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+			NextAttemptAt: now,
+			CreateAt:      now,
+			UpdateAt:      now,
+		},
+		{
+			ID:             "DeliveryID2",
+			WebhookID:      "WebhookID",
+			Event:          event,
+			Status:         api.WEBHOOK_DELIVERY_STATUS_DEAD,
+			Attempts:       5,
+			LastStatusCode: 500,
+			LastError:      "Unexpected response status 500",
+			NextAttemptAt:  now,
+			CreateAt:       now.Add(time.Second),
+			UpdateAt:       now,
+		},
+		{
+			ID:            "DeliveryID3",
+			WebhookID:     "OtherWebhookID",
+			Event:         event,
+			Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+			NextAttemptAt: now.Add(time.Hour),
+			CreateAt:      now,
+			UpdateAt:      now,
+		},
+	}
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		webhookID string
+		status    string
+		dueBefore *time.Time
+		// Expected result
+		expectedResponse []api.WebhookDelivery
+	}{
+		"OkCaseAll": {
+			webhookID:        "WebhookID",
+			expectedResponse: []api.WebhookDelivery{deliveries[0], deliveries[1]},
+		},
+		"OkCaseStatus": {
+			webhookID:        "WebhookID",
+			status:           api.WEBHOOK_DELIVERY_STATUS_DEAD,
+			expectedResponse: []api.WebhookDelivery{deliveries[1]},
+		},
+		"OkCasePending": {
+			dueBefore:        &now,
+			expectedResponse: []api.WebhookDelivery{deliveries[0]},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		if err := repoDB.AddWebhookDeliveries(deliveries); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+
+		// Call to repository to get webhook deliveries
+		var receivedDeliveries []api.WebhookDelivery
+		var err error
+		if test.dueBefore != nil {
+			receivedDeliveries, err = repoDB.GetPendingWebhookDeliveries(*test.dueBefore)
+		} else {
+			receivedDeliveries, err = repoDB.GetWebhookDeliveries(test.webhookID, test.status)
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check response
+		if diff := pretty.Compare(receivedDeliveries, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateWebhookDelivery(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDelivery api.WebhookDelivery
+		// Postgres Repo Args
+		deliveryToUpdate api.WebhookDelivery
+	}{
+		"OkCase": {
+			previousDelivery: api.WebhookDelivery{
+				ID:        "DeliveryID",
+				WebhookID: "WebhookID",
+				Event: api.WebhookEvent{
+					ID:       "EventID",
+					Type:     api.USER_ACTION_CREATE_USER,
+					CreateAt: now,
+				},
+				Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+				NextAttemptAt: now,
+				CreateAt:      now,
+				UpdateAt:      now,
+			},
+			deliveryToUpdate: api.WebhookDelivery{
+				ID:        "DeliveryID",
+				WebhookID: "WebhookID",
+				Event: api.WebhookEvent{
+					ID:       "EventID",
+					Type:     api.USER_ACTION_CREATE_USER,
+					CreateAt: now,
+				},
+				Status:         api.WEBHOOK_DELIVERY_STATUS_DEAD,
+				Attempts:       5,
+				LastStatusCode: 500,
+				LastError:      "Unexpected response status 500",
+				NextAttemptAt:  now,
+				CreateAt:       now,
+				UpdateAt:       now.Add(time.Hour),
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		if err := repoDB.AddWebhookDeliveries([]api.WebhookDelivery{test.previousDelivery}); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+
+		// Call to repository to update webhook delivery
+		if _, err := repoDB.UpdateWebhookDelivery(test.deliveryToUpdate); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		receivedDeliveries, err := repoDB.GetWebhookDeliveries(test.deliveryToUpdate.WebhookID, test.deliveryToUpdate.Status)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(receivedDeliveries, []api.WebhookDelivery{test.deliveryToUpdate}); diff != "" {
+			t.Errorf("Test %v failed. Received different deliveries (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
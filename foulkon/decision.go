@@ -0,0 +1,167 @@
+package foulkon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Create the authorization decision log configured with its sinks, nil if there aren't sinks.
+// Postgres sink is the database repository, nil if database isn't postgres.
+func newDecisionLog(config *toml.TomlTree, postgresSink api.DecisionSink) (*api.DecisionLog, error) {
+	sinks := getDefaultValue(config, "decisionlog.sinks", "")
+	if len(strings.TrimSpace(sinks)) < 1 {
+		return nil, nil
+	}
+
+	decisionSampling := getDefaultValue(config, "decisionlog.sampling", "1")
+	sampling, err := strconv.ParseFloat(decisionSampling, 64)
+	if err != nil || sampling < 0 || sampling > 1 {
+		return nil, errors.New(fmt.Sprintf("Invalid decision log sampling param: %v", decisionSampling))
+	}
+
+	decisionLog := &api.DecisionLog{
+		Sampling: sampling,
+	}
+	for _, sink := range strings.Split(sinks, ";") {
+		switch strings.TrimSpace(sink) {
+		case "stdout":
+			decisionLog.Sinks = append(decisionLog.Sinks, &writerDecisionSink{out: os.Stdout})
+		case "file":
+			decisionFileSize := getDefaultValue(config, "decisionlog.file.maxsize", "100")
+			maxSize, err := strconv.Atoi(decisionFileSize)
+			if err != nil || maxSize < 1 {
+				return nil, errors.New(fmt.Sprintf("Invalid decision log file maxsize param: %v", decisionFileSize))
+			}
+			decisionFileBackups := getDefaultValue(config, "decisionlog.file.maxbackups", "5")
+			maxBackups, err := strconv.Atoi(decisionFileBackups)
+			if err != nil || maxBackups < 0 {
+				return nil, errors.New(fmt.Sprintf("Invalid decision log file maxbackups param: %v", decisionFileBackups))
+			}
+			decision_logfile, err = openRotatingFile(getDefaultValue(config, "decisionlog.file.path", "/tmp/foulkon_decisions.log"),
+				int64(maxSize)*1024*1024, maxBackups)
+			if err != nil {
+				return nil, err
+			}
+			decisionLog.Sinks = append(decisionLog.Sinks, &writerDecisionSink{out: decision_logfile})
+		case "postgres":
+			if postgresSink == nil {
+				return nil, errors.New("Decision log postgres sink needs a postgres database")
+			}
+			decisionLog.Sinks = append(decisionLog.Sinks, postgresSink)
+		default:
+			return nil, errors.New(fmt.Sprintf("Unexpected decision log sink %v in configuration file", sink))
+		}
+	}
+
+	return decisionLog, nil
+}
+
+// Decision sink that writes decisions as JSON lines
+type writerDecisionSink struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+func (s *writerDecisionSink) AddAuthzDecision(decision api.AuthzDecision) error {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
+
+// File that is rotated when it reaches its max size. Rotated files are renamed with a numeric suffix,
+// path.1 being the newest one, and only maxBackups of them are kept.
+type rotatingFile struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.file.Close()
+}
+
+// Open file to append, keeping its current size
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Close current file, shift the backups removing the oldest one and open a new empty file
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if r.maxBackups < 1 {
+		if err := os.Remove(r.path); err != nil {
+			return err
+		}
+		return r.open()
+	}
+	os.Remove(fmt.Sprintf("%v.%v", r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i > 0; i-- {
+		backup := fmt.Sprintf("%v.%v", r.path, i)
+		if _, err := os.Stat(backup); err == nil {
+			if err := os.Rename(backup, fmt.Sprintf("%v.%v", r.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
@@ -0,0 +1,333 @@
+package foulkon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/pelletier/go-toml"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Aux decision sink used as postgres sink
+type testDecisionSink struct{}
+
+func (s testDecisionSink) AddAuthzDecision(decision api.AuthzDecision) error {
+	return nil
+}
+
+func TestRotatingFile_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-decisions")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testcases := map[string]struct {
+		// Previous data
+		previousContent string
+		// Rotating file args
+		maxSize    int64
+		maxBackups int
+		lines      []string
+		// Expected result, content of files by suffix of their path
+		expectedFiles   map[string]string
+		expectedMissing []string
+	}{
+		"OkCaseUnderMaxSize": {
+			maxSize:    100,
+			maxBackups: 2,
+			lines:      []string{"line1\n", "line2\n", "line3\n"},
+			expectedFiles: map[string]string{
+				"": "line1\nline2\nline3\n",
+			},
+			expectedMissing: []string{".1"},
+		},
+		"OkCaseRotatedBySize": {
+			maxSize:    12,
+			maxBackups: 2,
+			lines:      []string{"line1\n", "line2\n", "line3\n"},
+			expectedFiles: map[string]string{
+				"":   "line3\n",
+				".1": "line1\nline2\n",
+			},
+			expectedMissing: []string{".2"},
+		},
+		"OkCaseOldestBackupsPruned": {
+			maxSize:    10,
+			maxBackups: 2,
+			lines:      []string{"line1\n", "line2\n", "line3\n", "line4\n"},
+			expectedFiles: map[string]string{
+				"":   "line4\n",
+				".1": "line3\n",
+				".2": "line2\n",
+			},
+			expectedMissing: []string{".3"},
+		},
+		"OkCaseWithoutBackups": {
+			maxSize:    10,
+			maxBackups: 0,
+			lines:      []string{"line1\n", "line2\n", "line3\n"},
+			expectedFiles: map[string]string{
+				"": "line3\n",
+			},
+			expectedMissing: []string{".1"},
+		},
+		"OkCaseLineBiggerThanMaxSize": {
+			maxSize:    4,
+			maxBackups: 1,
+			lines:      []string{"line1\n", "line2\n"},
+			expectedFiles: map[string]string{
+				"":   "line2\n",
+				".1": "line1\n",
+			},
+		},
+		"OkCaseReopenKeepsSize": {
+			previousContent: "line0\n",
+			maxSize:         10,
+			maxBackups:      1,
+			lines:           []string{"line1\n"},
+			expectedFiles: map[string]string{
+				"":   "line1\n",
+				".1": "line0\n",
+			},
+		},
+		"OkCaseReopenAppends": {
+			previousContent: "line0\n",
+			maxSize:         100,
+			maxBackups:      1,
+			lines:           []string{"line1\n"},
+			expectedFiles: map[string]string{
+				"": "line0\nline1\n",
+			},
+			expectedMissing: []string{".1"},
+		},
+	}
+
+	for n, test := range testcases {
+		path := filepath.Join(dir, n+".log")
+		if test.previousContent != "" {
+			if err := ioutil.WriteFile(path, []byte(test.previousContent), 0600); err != nil {
+				t.Errorf("Test %v failed. Unexpected error writing previous content: %v", n, err)
+				continue
+			}
+		}
+
+		file, err := openRotatingFile(path, test.maxSize, test.maxBackups)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error opening file: %v", n, err)
+			continue
+		}
+		for _, line := range test.lines {
+			if written, err := file.Write([]byte(line)); err != nil || written != len(line) {
+				t.Errorf("Test %v failed. Unexpected result writing line %v: %v %v", n, line, written, err)
+			}
+		}
+		if err := file.Close(); err != nil {
+			t.Errorf("Test %v failed. Unexpected error closing file: %v", n, err)
+			continue
+		}
+
+		// Check files
+		for suffix, expectedContent := range test.expectedFiles {
+			content, err := ioutil.ReadFile(path + suffix)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error reading file %v: %v", n, path+suffix, err)
+				continue
+			}
+			if string(content) != expectedContent {
+				t.Errorf("Test %v failed. Received different content in file %v (wanted:%q / received:%q)",
+					n, path+suffix, expectedContent, string(content))
+			}
+		}
+		for _, suffix := range test.expectedMissing {
+			if _, err := os.Stat(path + suffix); !os.IsNotExist(err) {
+				t.Errorf("Test %v failed. File %v wasn't expected: %v", n, path+suffix, err)
+			}
+		}
+	}
+}
+
+func TestWriterDecisionSink_AddAuthzDecision(t *testing.T) {
+	decisions := []api.AuthzDecision{
+		{ID: "ID1", User: "user1", Action: api.USER_ACTION_GET_USER, Allowed: []string{"urn1"}},
+		{ID: "ID2", User: "user2", Admin: true, Action: api.USER_ACTION_LIST_USERS},
+	}
+	out := &bytes.Buffer{}
+	sink := &writerDecisionSink{out: out}
+	for _, decision := range decisions {
+		if err := sink.AddAuthzDecision(decision); err != nil {
+			t.Fatalf("Unexpected error adding decision: %v", err)
+		}
+	}
+
+	// Check JSON lines
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(decisions) {
+		t.Fatalf("Received different number of lines: %v", len(lines))
+	}
+	for i, line := range lines {
+		decision := api.AuthzDecision{}
+		if err := json.Unmarshal([]byte(line), &decision); err != nil {
+			t.Errorf("Unexpected error decoding line %v: %v", line, err)
+			continue
+		}
+		if diff := pretty.Compare(decision, decisions[i]); diff != "" {
+			t.Errorf("Received different decision (received/wanted) %v", diff)
+		}
+	}
+}
+
+func TestNewDecisionLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-decisions")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "decisions.log")
+
+	testcases := map[string]struct {
+		// Config
+		config       string
+		postgresSink api.DecisionSink
+		// Expected result
+		expectedNil        bool
+		expectedSinks      int
+		expectedSampling   float64
+		expectedMaxSize    int64
+		expectedMaxBackups int
+		expectedError      string
+	}{
+		"OkCaseWithoutSinks": {
+			config:      "[decisionlog]\nsinks = \"\"",
+			expectedNil: true,
+		},
+		"OkCaseWithoutConfig": {
+			config:      "",
+			expectedNil: true,
+		},
+		"OkCaseStdout": {
+			config:           "[decisionlog]\nsinks = \"stdout\"",
+			expectedSinks:    1,
+			expectedSampling: 1,
+		},
+		"OkCaseSampling": {
+			config:           "[decisionlog]\nsinks = \"stdout\"\nsampling = \"0.25\"",
+			expectedSinks:    1,
+			expectedSampling: 0.25,
+		},
+		"OkCaseFile": {
+			config: "[decisionlog]\nsinks = \"file\"\n[decisionlog.file]\npath = \"" + path +
+				"\"\nmaxsize = \"2\"\nmaxbackups = \"3\"",
+			expectedSinks:      1,
+			expectedSampling:   1,
+			expectedMaxSize:    2 * 1024 * 1024,
+			expectedMaxBackups: 3,
+		},
+		"OkCaseFileDefaults": {
+			config:             "[decisionlog]\nsinks = \"file\"\n[decisionlog.file]\npath = \"" + path + "\"",
+			expectedSinks:      1,
+			expectedSampling:   1,
+			expectedMaxSize:    100 * 1024 * 1024,
+			expectedMaxBackups: 5,
+		},
+		"OkCaseSeveralSinks": {
+			config:           "[decisionlog]\nsinks = \"stdout; postgres\"",
+			postgresSink:     testDecisionSink{},
+			expectedSinks:    2,
+			expectedSampling: 1,
+		},
+		"ErrorCaseInvalidSampling": {
+			config:        "[decisionlog]\nsinks = \"stdout\"\nsampling = \"2\"",
+			expectedError: "Invalid decision log sampling param: 2",
+		},
+		"ErrorCaseNotNumericSampling": {
+			config:        "[decisionlog]\nsinks = \"stdout\"\nsampling = \"all\"",
+			expectedError: "Invalid decision log sampling param: all",
+		},
+		"ErrorCaseInvalidFileMaxSize": {
+			config:        "[decisionlog]\nsinks = \"file\"\n[decisionlog.file]\npath = \"" + path + "\"\nmaxsize = \"0\"",
+			expectedError: "Invalid decision log file maxsize param: 0",
+		},
+		"ErrorCaseInvalidFileMaxBackups": {
+			config:        "[decisionlog]\nsinks = \"file\"\n[decisionlog.file]\npath = \"" + path + "\"\nmaxbackups = \"-1\"",
+			expectedError: "Invalid decision log file maxbackups param: -1",
+		},
+		"ErrorCaseFileInMissingDir": {
+			config:        "[decisionlog]\nsinks = \"file\"\n[decisionlog.file]\npath = \"" + filepath.Join(dir, "missing", "decisions.log") + "\"",
+			expectedError: fmt.Sprintf("open %v: no such file or directory", filepath.Join(dir, "missing", "decisions.log")),
+		},
+		"ErrorCasePostgresWithoutDatabase": {
+			config:        "[decisionlog]\nsinks = \"postgres\"",
+			expectedError: "Decision log postgres sink needs a postgres database",
+		},
+		"ErrorCaseUnexpectedSink": {
+			config:        "[decisionlog]\nsinks = \"kafka\"",
+			expectedError: "Unexpected decision log sink kafka in configuration file",
+		},
+	}
+
+	for n, test := range testcases {
+		decision_logfile = nil
+		config, err := toml.Load(test.config)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error loading config: %v", n, err)
+			continue
+		}
+		decisionLog, err := newDecisionLog(config, test.postgresSink)
+		if decision_logfile != nil {
+			decision_logfile.Close()
+		}
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if test.expectedNil {
+			if decisionLog != nil {
+				t.Errorf("Test %v failed. Received unexpected decision log %v", n, decisionLog)
+			}
+			continue
+		}
+		if decisionLog == nil {
+			t.Errorf("Test %v failed. Received nil decision log", n)
+			continue
+		}
+		if len(decisionLog.Sinks) != test.expectedSinks {
+			t.Errorf("Test %v failed. Received different number of sinks: %v", n, len(decisionLog.Sinks))
+			continue
+		}
+		if decisionLog.Sampling != test.expectedSampling {
+			t.Errorf("Test %v failed. Received different sampling: %v", n, decisionLog.Sampling)
+			continue
+		}
+		if test.expectedMaxSize > 0 {
+			if decision_logfile == nil {
+				t.Errorf("Test %v failed. Decision log file wasn't opened", n)
+				continue
+			}
+			if decision_logfile.path != path || decision_logfile.maxSize != test.expectedMaxSize ||
+				decision_logfile.maxBackups != test.expectedMaxBackups {
+				t.Errorf("Test %v failed. Received different file settings: %v %v %v", n,
+					decision_logfile.path, decision_logfile.maxSize, decision_logfile.maxBackups)
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("Test %v failed. Decision log file wasn't created: %v", n, err)
+				continue
+			}
+		}
+	}
+	decision_logfile = nil
+}
@@ -0,0 +1,24 @@
+package foulkon
+
+import (
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Start a background job that removes, every interval, the group memberships and sessions already expired.
+// Returned ticker must be stopped to finish the job.
+func startExpirationJob(authApi api.AuthAPI, interval time.Duration) *time.Ticker {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := authApi.RemoveExpiredMembers(time.Now().UTC()); err != nil {
+				logger.Errorf("Couldn't remove expired members: %v", err)
+			}
+			if err := authApi.RemoveExpiredSessions(time.Now().UTC()); err != nil {
+				logger.Errorf("Couldn't remove expired sessions: %v", err)
+			}
+		}
+	}()
+	return ticker
+}
@@ -0,0 +1,21 @@
+package foulkon
+
+import (
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Start a background job that removes permanently, every interval, the users, groups and policies
+// deleted before the retention period. Returned ticker must be stopped to finish the job.
+func startPurgeJob(authApi api.AuthAPI, retention time.Duration, interval time.Duration) *time.Ticker {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := authApi.PurgeDeleted(time.Now().UTC().Add(-retention)); err != nil {
+				logger.Errorf("Couldn't purge deleted entities: %v", err)
+			}
+		}
+	}()
+	return ticker
+}
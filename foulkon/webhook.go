@@ -0,0 +1,22 @@
+package foulkon
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Start a background job that attempts, every interval, the webhook deliveries already due. Failed deliveries
+// are retried with exponential backoff until maxAttempts. Returned ticker must be stopped to finish the job.
+func startWebhookJob(authApi api.AuthAPI, client *http.Client, maxAttempts int, backoff time.Duration, interval time.Duration) *time.Ticker {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := authApi.DeliverWebhooks(client, maxAttempts, backoff, time.Now().UTC()); err != nil {
+				logger.Errorf("Couldn't deliver webhooks: %v", err)
+			}
+		}
+	}()
+	return ticker
+}
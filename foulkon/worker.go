@@ -1,12 +1,18 @@
 package foulkon
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"regexp"
 
 	"errors"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"fmt"
 
@@ -23,7 +29,11 @@ import (
 var rEnvVar, _ = regexp.Compile(`^\$\{(\w+)\}$`)
 var db *sql.DB
 var worker_logfile *os.File
+var decision_logfile *rotatingFile
 var logger *log.Logger
+var purgeTicker *time.Ticker
+var expirationTicker *time.Ticker
+var webhookTicker *time.Ticker
 
 // Worker is the Authorization server.
 type Worker struct {
@@ -32,14 +42,25 @@ type Worker struct {
 	Port string
 
 	// TLS configuration
-	CertFile string
-	KeyFile  string
+	CertFile  string
+	KeyFile   string
+	TLSConfig *tls.Config
 
 	// APIs
-	UserApi   api.UserAPI
-	GroupApi  api.GroupAPI
-	PolicyApi api.PolicyAPI
-	AuthzApi  api.AuthzAPI
+	UserApi          api.UserAPI
+	GroupApi         api.GroupAPI
+	PolicyApi        api.PolicyAPI
+	OrganizationApi  api.OrganizationAPI
+	AccessRequestApi api.AccessRequestAPI
+	AuthzApi         api.AuthzAPI
+	SyncApi          api.SyncAPI
+	AuditApi         api.AuditAPI
+	WebhookApi       api.WebhookAPI
+	ChangeApi        api.ChangeAPI
+	ApiKeyApi        api.ApiKeyAPI
+	AdminApi         api.AdminAPI
+	GroupMappingApi  api.GroupMappingAPI
+	PasswordApi      api.PasswordAPI
 
 	// Logger
 	Logger *log.Logger
@@ -80,6 +101,7 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 
 	// Start DB with API
 	var authApi api.AuthAPI
+	var postgresSink api.DecisionSink
 
 	dbType, err := getMandatoryValue(config, "database.type")
 	if err != nil {
@@ -111,10 +133,21 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 			Dbmap: gormDB,
 		}
 		authApi = api.AuthAPI{
-			GroupRepo:  repoDB,
-			UserRepo:   repoDB,
-			PolicyRepo: repoDB,
+			GroupRepo:         repoDB,
+			UserRepo:          repoDB,
+			PolicyRepo:        repoDB,
+			OrganizationRepo:  repoDB,
+			SyncRepo:          repoDB,
+			AccessRequestRepo: repoDB,
+			AuditRepo:         repoDB,
+			WebhookRepo:       repoDB,
+			ChangeRepo:        repoDB,
+			ApiKeyRepo:        repoDB,
+			AdminRepo:         repoDB,
+			GroupMappingRepo:  repoDB,
+			PasswordRepo:      repoDB,
 		}
+		postgresSink = repoDB
 
 	default:
 		err := errors.New("Unexpected db_type value in configuration file (Maybe it is empty)")
@@ -124,53 +157,148 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 
 	authApi.Logger = logger
 
-	// Instantiate Auth Connector
-	var authConnector auth.AuthConnector
-	authType, err := getMandatoryValue(config, "authenticator.type")
+	// Create authorization decision log
+	decisionLog, err := newDecisionLog(config, postgresSink)
 	if err != nil {
+		logger.Error(err)
 		return nil, err
 	}
-	switch authType {
-	case "oidc":
-		issuer, err := getMandatoryValue(config, "authenticator.oidc.issuer")
-		if err != nil {
-			return nil, err
-		}
-		clientsids, err := getMandatoryValue(config, "authenticator.oidc.clientids")
-		if err != nil {
-			return nil, err
-		}
-		authOidcConnector, err := auth.InitOIDCConnector(logger, issuer, strings.Split(clientsids, ";"))
-		if err != nil {
-			logger.Error(err)
-			return nil, err
-		}
-		authConnector = authOidcConnector
-		logger.Infof("OIDC connector configured for issuer %v", issuer)
-	default:
-		err := errors.New("Unexpected auth_connector_type value in configuration file (Maybe it is empty)")
+	if decisionLog != nil {
+		authApi.DecisionLog = decisionLog
+		logger.Infof("Authorization decision log configured with %v sinks and sampling %v", len(decisionLog.Sinks), decisionLog.Sampling)
+	}
+
+	// Lockout and session settings of local passwords, durations in seconds
+	passwordConfig, err := getPasswordConfig(config)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	authApi.PasswordConfig = *passwordConfig
+
+	// Start purge of deleted users, groups and policies. Retention in hours, 0 disables purge
+	purgeRetention := getDefaultValue(config, "database.purge.retention", "720")
+	retention, err := strconv.Atoi(purgeRetention)
+	if err != nil || retention < 0 {
+		err := errors.New(fmt.Sprintf("Invalid purge retention param: %v", purgeRetention))
+		logger.Error(err)
+		return nil, err
+	}
+	purgeInterval := getDefaultValue(config, "database.purge.interval", "3600")
+	interval, err := strconv.Atoi(purgeInterval)
+	if err != nil || interval < 1 {
+		err := errors.New(fmt.Sprintf("Invalid purge interval param: %v", purgeInterval))
+		logger.Error(err)
+		return nil, err
+	}
+	if retention > 0 {
+		purgeTicker = startPurgeJob(authApi, time.Duration(retention)*time.Hour, time.Duration(interval)*time.Second)
+		logger.Infof("Purge of deleted entities configured with retention %vh every %vs", retention, interval)
+	}
+
+	// Start removal of expired group memberships. Interval in seconds, 0 disables removal
+	expirationInterval := getDefaultValue(config, "database.expiration.interval", "60")
+	expiration, err := strconv.Atoi(expirationInterval)
+	if err != nil || expiration < 0 {
+		err := errors.New(fmt.Sprintf("Invalid expiration interval param: %v", expirationInterval))
 		logger.Error(err)
 		return nil, err
 	}
+	if expiration > 0 {
+		expirationTicker = startExpirationJob(authApi, time.Duration(expiration)*time.Second)
+		logger.Infof("Removal of expired group members and sessions configured every %vs", expiration)
+	}
 
-	adminUser, err := getMandatoryValue(config, "admin.username")
+	// Start delivery of webhook events. Interval, backoff and timeout in seconds, 0 interval disables delivery
+	webhookInterval := getDefaultValue(config, "webhooks.interval", "10")
+	webhookDelivery, err := strconv.Atoi(webhookInterval)
+	if err != nil || webhookDelivery < 0 {
+		err := errors.New(fmt.Sprintf("Invalid webhooks interval param: %v", webhookInterval))
+		logger.Error(err)
+		return nil, err
+	}
+	webhookMaxAttempts := getDefaultValue(config, "webhooks.maxattempts", "5")
+	maxAttempts, err := strconv.Atoi(webhookMaxAttempts)
+	if err != nil || maxAttempts < 1 {
+		err := errors.New(fmt.Sprintf("Invalid webhooks maxattempts param: %v", webhookMaxAttempts))
+		logger.Error(err)
+		return nil, err
+	}
+	webhookBackoff := getDefaultValue(config, "webhooks.backoff", "30")
+	backoff, err := strconv.Atoi(webhookBackoff)
+	if err != nil || backoff < 1 {
+		err := errors.New(fmt.Sprintf("Invalid webhooks backoff param: %v", webhookBackoff))
+		logger.Error(err)
+		return nil, err
+	}
+	webhookTimeout := getDefaultValue(config, "webhooks.timeout", "10")
+	timeout, err := strconv.Atoi(webhookTimeout)
+	if err != nil || timeout < 1 {
+		err := errors.New(fmt.Sprintf("Invalid webhooks timeout param: %v", webhookTimeout))
+		logger.Error(err)
+		return nil, err
+	}
+	if webhookDelivery > 0 {
+		client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+		webhookTicker = startWebhookJob(authApi, client, maxAttempts, time.Duration(backoff)*time.Second,
+			time.Duration(webhookDelivery)*time.Second)
+		logger.Infof("Webhook delivery configured every %vs with %v attempts", webhookDelivery, maxAttempts)
+	}
+
+	// Client certificates are verified by the TLS listener with the client CA bundle
+	certFile := getDefaultValue(config, "server.certfile", "")
+	keyFile := getDefaultValue(config, "server.keyfile", "")
+	tlsConfig, err := getTLSConfig(config, certFile != "" && keyFile != "")
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
-	adminPassword, err := getMandatoryValue(config, "admin.password")
+
+	// Instantiate Auth Connectors, that are tried in order
+	connectorTypes, err := getMandatoryArrayValue(config, "authenticator.type")
 	if err != nil {
+		return nil, err
+	}
+	// API keys of service accounts are checked before the configured connectors
+	apiKeysParam := getDefaultValue(config, "authenticator.apikeys.enabled", "false")
+	apiKeysEnabled, err := strconv.ParseBool(apiKeysParam)
+	if err != nil {
+		err := errors.New(fmt.Sprintf("Invalid authenticator apikeys enabled param: %v", apiKeysParam))
 		logger.Error(err)
 		return nil, err
 	}
-	if len(strings.TrimSpace(adminUser)) < 1 || len(strings.TrimSpace(adminPassword)) < 1 {
-		err := errors.New(fmt.Sprintf("Admin user config unexpected adminUser:%v, adminpassword:%v", adminUser, adminPassword))
+	if apiKeysEnabled && !isContained("apikeys", connectorTypes) {
+		connectorTypes = append([]string{"apikeys"}, connectorTypes...)
+	}
+	connectors := []auth.ChainedConnector{}
+	for _, connectorType := range connectorTypes {
+		if isContained(connectorType, connectorTypes[:len(connectors)]) {
+			err := errors.New(fmt.Sprintf("Duplicated authenticator type value %v in configuration file", connectorType))
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector, err := getAuthConnector(config, connectorType, authApi, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, auth.ChainedConnector{
+			Name:      connectorType,
+			Connector: authConnector,
+		})
+	}
+
+	// Admin credentials are only used to create the first admin
+	adminUser := strings.TrimSpace(getDefaultValue(config, "admin.username", ""))
+	adminPassword := getDefaultValue(config, "admin.password", "")
+	adminOrg := getDefaultValue(config, "admin.org", "foulkon")
+	adminGroup := getDefaultValue(config, "admin.group", "admins")
+	if err := authApi.BootstrapAdmin(adminOrg, adminGroup, adminUser, adminPassword); err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	authenticator := auth.NewAuthenticator(authConnector, adminUser, adminPassword)
-	logger.Infof("Created authenticator with admin username %v", adminUser)
+	authenticator := auth.NewAuthenticator(connectors, authApi)
+	logger.Infof("Created authenticator with connectors %v and admins stored in database", connectorTypes)
 
 	host, err := getMandatoryValue(config, "server.host")
 	if err != nil {
@@ -184,25 +312,51 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 	}
 
 	return &Worker{
-		Host:          host,
-		Port:          port,
-		CertFile:      getDefaultValue(config, "server.certfile", ""),
-		KeyFile:       getDefaultValue(config, "server.keyfile", ""),
-		Logger:        logger,
-		Authenticator: authenticator,
-		UserApi:       authApi,
-		GroupApi:      authApi,
-		PolicyApi:     authApi,
-		AuthzApi:      authApi,
+		Host:             host,
+		Port:             port,
+		CertFile:         certFile,
+		KeyFile:          keyFile,
+		TLSConfig:        tlsConfig,
+		Logger:           logger,
+		Authenticator:    authenticator,
+		UserApi:          authApi,
+		GroupApi:         authApi,
+		PolicyApi:        authApi,
+		OrganizationApi:  authApi,
+		AccessRequestApi: authApi,
+		AuthzApi:         authApi,
+		SyncApi:          authApi,
+		AuditApi:         authApi,
+		WebhookApi:       authApi,
+		ChangeApi:        authApi,
+		ApiKeyApi:        authApi,
+		AdminApi:         authApi,
+		GroupMappingApi:  authApi,
+		PasswordApi:      authApi,
 	}, nil
 }
 
 func CloseWorker() int {
 	status := 0
+	if purgeTicker != nil {
+		purgeTicker.Stop()
+	}
+	if expirationTicker != nil {
+		expirationTicker.Stop()
+	}
+	if webhookTicker != nil {
+		webhookTicker.Stop()
+	}
 	if err := db.Close(); err != nil {
 		logger.Errorf("Couldn't close DB connection: %v", err)
 		status = 1
 	}
+	if decision_logfile != nil {
+		if err := decision_logfile.Close(); err != nil {
+			logger.Errorf("Couldn't close decision log file: %v", err)
+			status = 1
+		}
+	}
 	if err := worker_logfile.Close(); err != nil {
 		fmt.Fprintf(os.Stderr, "Couldn't close logfile: %v", err)
 		status = 1
@@ -210,6 +364,312 @@ func CloseWorker() int {
 	return status
 }
 
+// This aux method returns the auth connector of a type of authenticator config
+func getAuthConnector(config *toml.TomlTree, connectorType string, authApi api.AuthAPI, tlsConfig *tls.Config) (auth.AuthConnector, error) {
+	var authConnector auth.AuthConnector
+	switch connectorType {
+	case "oidc":
+		issuers, err := getOIDCIssuers(config)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		provisioning, err := getOIDCProvisioning(config, authApi)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		groupMappingsParam := getDefaultValue(config, "authenticator.oidc.groupmappings.enabled", "false")
+		groupMappingsEnabled, err := strconv.ParseBool(groupMappingsParam)
+		if err != nil {
+			err := errors.New(fmt.Sprintf("Invalid authenticator oidc groupmappings enabled param: %v", groupMappingsParam))
+			logger.Error(err)
+			return nil, err
+		}
+		var groupMapper auth.GroupMapper
+		if groupMappingsEnabled {
+			groupMapper = authApi
+		}
+		authOidcConnector, err := auth.InitOIDCConnector(logger, issuers, provisioning, groupMapper)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authOidcConnector
+		for _, issuer := range issuers {
+			logger.Infof("OIDC connector configured for issuer %v", issuer.Issuer)
+		}
+		if provisioning != nil {
+			logger.Infof("OIDC connector provisions new users with path %v", provisioning.PathTemplate)
+		}
+		if groupMappingsEnabled {
+			logger.Info("OIDC connector applies group mappings to users")
+		}
+	case "jwt":
+		issuer, err := getMandatoryValue(config, "authenticator.jwt.issuer")
+		if err != nil {
+			return nil, err
+		}
+		audiences, err := getMandatoryArrayValue(config, "authenticator.jwt.audiences")
+		if err != nil {
+			return nil, err
+		}
+		keyFiles, err := getMandatoryArrayValue(config, "authenticator.jwt.keyfiles")
+		if err != nil {
+			return nil, err
+		}
+		userClaim := getDefaultValue(config, "authenticator.jwt.userclaim", "sub")
+		prefix := getDefaultValue(config, "authenticator.jwt.prefix", auth.DefaultExternalIDPrefix("jwt", issuer))
+		authJwtConnector, err := auth.InitJWTConnector(logger, issuer, audiences, userClaim, prefix, keyFiles)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authJwtConnector
+		logger.Infof("JWT connector configured for issuer %v with keys %v and prefix %v", issuer, keyFiles, prefix)
+	case "mtls":
+		if tlsConfig == nil {
+			err := errors.New("Authenticator mtls needs server clientcafile param")
+			logger.Error(err)
+			return nil, err
+		}
+		rules, err := getCertificateRules(config)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		prefix := getDefaultValue(config, "authenticator.mtls.prefix", auth.DefaultExternalIDPrefix("mtls", ""))
+		authMtlsConnector, err := auth.InitMTLSConnector(logger, prefix, rules)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authMtlsConnector
+		logger.Infof("mTLS connector configured with %v rules and prefix %v", len(rules), prefix)
+	case "introspection":
+		endpoint, err := getMandatoryValue(config, "authenticator.introspection.endpoint")
+		if err != nil {
+			return nil, err
+		}
+		clientID, err := getMandatoryValue(config, "authenticator.introspection.clientid")
+		if err != nil {
+			return nil, err
+		}
+		clientSecret := getDefaultValue(config, "authenticator.introspection.clientsecret", "")
+		userField := getDefaultValue(config, "authenticator.introspection.userfield", auth.INTROSPECTION_USER_FIELD_SUB)
+		timeoutParam := getDefaultValue(config, "authenticator.introspection.timeout", "10")
+		timeout, err := strconv.Atoi(timeoutParam)
+		if err != nil || timeout < 1 {
+			err := errors.New(fmt.Sprintf("Invalid authenticator introspection timeout param: %v", timeoutParam))
+			logger.Error(err)
+			return nil, err
+		}
+		client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+		prefix := getDefaultValue(config, "authenticator.introspection.prefix", auth.DefaultExternalIDPrefix("introspection", endpoint))
+		authIntrospectionConnector, err := auth.InitIntrospectionConnector(logger, client, endpoint, clientID, clientSecret,
+			userField, prefix)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authIntrospectionConnector
+		logger.Infof("Introspection connector configured for endpoint %v with prefix %v", endpoint, prefix)
+	case "local":
+		authLocalConnector, err := auth.InitLocalConnector(logger, authApi)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authLocalConnector
+		logger.Infof("Local connector configured with lockout after %v failed attempts and sessions of %v",
+			authApi.PasswordConfig.MaxFailedAttempts, authApi.PasswordConfig.SessionDuration)
+	case "apikeys":
+		authApiKeyConnector, err := auth.InitApiKeyConnector(logger, authApi)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		authConnector = authApiKeyConnector
+		logger.Info("API key connector configured for service accounts")
+	default:
+		err := errors.New(fmt.Sprintf("Unexpected authenticator type value %v in configuration file (Maybe it is empty)", connectorType))
+		logger.Error(err)
+		return nil, err
+	}
+	return authConnector, nil
+}
+
+// This aux method returns issuers of OIDC connector. Issuer, clientids and prefix params are strings for a single
+// issuer, or arrays with an element per issuer. Each element of clientids has the client IDs separated by ';'.
+func getOIDCIssuers(config *toml.TomlTree) ([]auth.OIDCIssuer, error) {
+	issuers, err := getMandatoryArrayValue(config, "authenticator.oidc.issuer")
+	if err != nil {
+		return nil, err
+	}
+	clientids, err := getMandatoryArrayValue(config, "authenticator.oidc.clientids")
+	if err != nil {
+		return nil, err
+	}
+	if len(clientids) != len(issuers) {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc clientids param: %v, expected %v elements",
+			clientids, len(issuers)))
+	}
+	prefixes := make([]string, len(issuers))
+	if config.Has("authenticator.oidc.prefix") {
+		prefixes, err = getMandatoryArrayValue(config, "authenticator.oidc.prefix")
+		if err != nil {
+			return nil, err
+		}
+		if len(prefixes) != len(issuers) {
+			return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc prefix param: %v, expected %v elements",
+				prefixes, len(issuers)))
+		}
+	}
+
+	oidcIssuers := []auth.OIDCIssuer{}
+	for i, issuer := range issuers {
+		if issuer == "" || clientids[i] == "" {
+			return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc issuer %v without client IDs", issuer))
+		}
+		if prefixes[i] != "" && !api.IsValidUserExternalID(prefixes[i]) {
+			return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc prefix param: %v", prefixes[i]))
+		}
+		oidcIssuers = append(oidcIssuers, auth.OIDCIssuer{
+			Issuer:    issuer,
+			ClientIDs: strings.Split(clientids[i], ";"),
+			Prefix:    prefixes[i],
+		})
+	}
+	return oidcIssuers, nil
+}
+
+// This aux method returns lockout and session settings of local passwords
+func getPasswordConfig(config *toml.TomlTree) (*api.PasswordConfig, error) {
+	maxAttemptsParam := getDefaultValue(config, "authenticator.local.maxattempts", strconv.Itoa(api.DEFAULT_MAX_FAILED_ATTEMPTS))
+	maxAttempts, err := strconv.Atoi(maxAttemptsParam)
+	if err != nil || maxAttempts < 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator local maxattempts param: %v", maxAttemptsParam))
+	}
+	lockoutParam := getDefaultValue(config, "authenticator.local.lockout",
+		strconv.Itoa(int(api.DEFAULT_LOCKOUT_DURATION/time.Second)))
+	lockout, err := strconv.Atoi(lockoutParam)
+	if err != nil || lockout < 1 {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator local lockout param: %v", lockoutParam))
+	}
+	sessionParam := getDefaultValue(config, "authenticator.local.session",
+		strconv.Itoa(int(api.DEFAULT_SESSION_DURATION/time.Second)))
+	session, err := strconv.Atoi(sessionParam)
+	if err != nil || session < 1 {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator local session param: %v", sessionParam))
+	}
+	return &api.PasswordConfig{
+		MaxFailedAttempts: maxAttempts,
+		LockoutDuration:   time.Duration(lockout) * time.Second,
+		SessionDuration:   time.Duration(session) * time.Second,
+	}, nil
+}
+
+// This aux method returns TLS configuration that verifies client certificates, nil if client CA bundle isn't configured
+func getTLSConfig(config *toml.TomlTree, tlsEnabled bool) (*tls.Config, error) {
+	clientCAFile := getDefaultValue(config, "server.clientcafile", "")
+	if clientCAFile == "" {
+		return nil, nil
+	}
+	if !tlsEnabled {
+		return nil, errors.New("Server clientcafile param needs certfile and keyfile params")
+	}
+
+	var clientAuth tls.ClientAuthType
+	switch clientAuthParam := getDefaultValue(config, "server.clientauth", "require"); clientAuthParam {
+	case "", "require":
+		clientAuth = tls.RequireAndVerifyClientCert
+	case "optional":
+		clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid server clientauth param: %v", clientAuthParam))
+	}
+
+	bundle, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(bundle) {
+		return nil, errors.New(fmt.Sprintf("Invalid server clientcafile param, without PEM certificates: %v", clientCAFile))
+	}
+	return &tls.Config{
+		ClientCAs:  clientCAs,
+		ClientAuth: clientAuth,
+	}, nil
+}
+
+// This aux method returns rules of mTLS connector. Fields, patterns and externalids params are strings for
+// a single rule, or arrays with an element per rule.
+func getCertificateRules(config *toml.TomlTree) ([]auth.CertificateRule, error) {
+	fields, err := getMandatoryArrayValue(config, "authenticator.mtls.fields")
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := getMandatoryArrayValue(config, "authenticator.mtls.patterns")
+	if err != nil {
+		return nil, err
+	}
+	externalIDs, err := getMandatoryArrayValue(config, "authenticator.mtls.externalids")
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) != len(fields) || len(externalIDs) != len(fields) {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator mtls params, expected %v patterns and externalids",
+			len(fields)))
+	}
+
+	rules := []auth.CertificateRule{}
+	for i, field := range fields {
+		pattern, err := regexp.Compile(patterns[i])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid authenticator mtls pattern param: %v", patterns[i]))
+		}
+		rules = append(rules, auth.CertificateRule{
+			Field:      field,
+			Pattern:    pattern,
+			ExternalID: externalIDs[i],
+		})
+	}
+	return rules, nil
+}
+
+// This aux method returns provisioning configuration of OIDC connector, nil if it's disabled
+func getOIDCProvisioning(config *toml.TomlTree, provisioner auth.UserProvisioner) (*auth.OIDCProvisioning, error) {
+	enabledParam := getDefaultValue(config, "authenticator.oidc.provisioning.enabled", "false")
+	enabled, err := strconv.ParseBool(enabledParam)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc provisioning enabled param: %v", enabledParam))
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	provisioning := &auth.OIDCProvisioning{
+		Provisioner:  provisioner,
+		PathTemplate: getDefaultValue(config, "authenticator.oidc.provisioning.path", "/"),
+		Issuers:      []string{},
+		Claims:       map[string]string{},
+	}
+	if issuers := getDefaultValue(config, "authenticator.oidc.provisioning.issuers", ""); issuers != "" {
+		provisioning.Issuers = strings.Split(issuers, ";")
+	}
+	if claims := getDefaultValue(config, "authenticator.oidc.provisioning.claims", ""); claims != "" {
+		for _, claim := range strings.Split(claims, ";") {
+			keyValue := strings.SplitN(claim, "=", 2)
+			if len(keyValue) != 2 || keyValue[0] == "" {
+				return nil, errors.New(fmt.Sprintf("Invalid authenticator oidc provisioning claims param: %v", claims))
+			}
+			provisioning.Claims[keyValue[0]] = keyValue[1]
+		}
+	}
+	return provisioning, nil
+}
+
 // This aux method returns mandatory config value or any error occurred
 func getMandatoryValue(config *toml.TomlTree, key string) (string, error) {
 	if !config.Has(key) {
@@ -235,11 +695,41 @@ func getDefaultValue(config *toml.TomlTree, key string, def string) string {
 	return value
 }
 
+// This aux method returns mandatory config value that can be a string or an array of strings, as an array
+func getMandatoryArrayValue(config *toml.TomlTree, key string) ([]string, error) {
+	if !config.Has(key) {
+		return nil, errors.New(fmt.Sprintf("Cannot retrieve configuration value %v", key))
+	}
+	values := []string{}
+	switch value := config.Get(key).(type) {
+	case string:
+		values = append(values, resolveVar(value))
+	case []interface{}:
+		for _, element := range value {
+			elementValue, ok := element.(string)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid configuration value %v, expected array of strings", key))
+			}
+			values = append(values, resolveVar(elementValue))
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid configuration value %v, expected string or array of strings", key))
+	}
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return nil, errors.New(fmt.Sprintf("Cannot retrieve configuration value %v", key))
+	}
+	return values, nil
+}
+
 // Check variables in TOML file.
 // If the value of a key is '${SOME_KEY}', we will search the value in the OS ENV vars
 // If the value of a key is 'something_else', returns that as the value
 func getVar(config *toml.TomlTree, key string) string {
-	value := config.Get(key).(string)
+	return resolveVar(config.Get(key).(string))
+}
+
+// Resolve a value of TOML file that can be an OS ENV var
+func resolveVar(value string) string {
 	match := rEnvVar.FindStringSubmatch(value)
 	if match != nil && len(match) > 1 {
 		if match[1] != "" {
@@ -248,3 +738,13 @@ func getVar(config *toml.TomlTree, key string) string {
 	}
 	return value
 }
+
+// Returns true if value is contained in values
+func isContained(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
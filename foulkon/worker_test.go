@@ -0,0 +1,353 @@
+package foulkon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/pelletier/go-toml"
+	"github.com/tecsisa/foulkon/auth"
+)
+
+// Aux certificate with its key
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Aux method that generates a certificate signed by parent, or self-signed if parent is nil
+func generateTestCertificate(t *testing.T, commonName string, isCA bool, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error generating key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	}
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Unexpected error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing certificate: %v", err)
+	}
+	return &testCertificate{cert: cert, key: key}
+}
+
+func (c testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.cert.Raw},
+		PrivateKey:  c.key,
+	}
+}
+
+// Aux method that writes a PEM bundle with the certificates
+func writeCertificatesFile(t *testing.T, file string, certs ...*testCertificate) {
+	bundle := []byte{}
+	for _, c := range certs {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})...)
+	}
+	if err := ioutil.WriteFile(file, bundle, 0600); err != nil {
+		t.Fatalf("Unexpected error writing certificates file: %v", err)
+	}
+}
+
+func TestGetTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-tls")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "clients.pem")
+	writeCertificatesFile(t, caFile, generateTestCertificate(t, "Client CA", true, nil))
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalidFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Unexpected error writing file: %v", err)
+	}
+
+	testcases := map[string]struct {
+		// Config args
+		config     string
+		tlsEnabled bool
+		// Expected result
+		expectedNil        bool
+		expectedClientAuth tls.ClientAuthType
+		expectedError      string
+	}{
+		"OkCaseWithoutClientCA": {
+			config:      "[server]\nport = \"8000\"",
+			tlsEnabled:  true,
+			expectedNil: true,
+		},
+		"OkCaseRequireByDefault": {
+			config:             "[server]\nclientcafile = \"" + caFile + "\"",
+			tlsEnabled:         true,
+			expectedClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		"OkCaseRequire": {
+			config:             "[server]\nclientcafile = \"" + caFile + "\"\nclientauth = \"require\"",
+			tlsEnabled:         true,
+			expectedClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		"OkCaseOptional": {
+			config:             "[server]\nclientcafile = \"" + caFile + "\"\nclientauth = \"optional\"",
+			tlsEnabled:         true,
+			expectedClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		"ErrorCaseTLSDisabled": {
+			config:        "[server]\nclientcafile = \"" + caFile + "\"",
+			expectedError: "Server clientcafile param needs certfile and keyfile params",
+		},
+		"ErrorCaseInvalidClientAuth": {
+			config:        "[server]\nclientcafile = \"" + caFile + "\"\nclientauth = \"request\"",
+			tlsEnabled:    true,
+			expectedError: "Invalid server clientauth param: request",
+		},
+		"ErrorCaseClientCAFileNotFound": {
+			config:        "[server]\nclientcafile = \"" + filepath.Join(dir, "notfound.pem") + "\"",
+			tlsEnabled:    true,
+			expectedError: "open " + filepath.Join(dir, "notfound.pem") + ": no such file or directory",
+		},
+		"ErrorCaseClientCAFileWithoutCertificates": {
+			config:        "[server]\nclientcafile = \"" + invalidFile + "\"",
+			tlsEnabled:    true,
+			expectedError: "Invalid server clientcafile param, without PEM certificates: " + invalidFile,
+		},
+	}
+
+	for n, test := range testcases {
+		config, err := toml.Load(test.config)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error loading config: %v", n, err)
+			continue
+		}
+		tlsConfig, err := getTLSConfig(config, test.tlsEnabled)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if test.expectedNil {
+			if tlsConfig != nil {
+				t.Errorf("Test %v failed. Received unexpected TLS config %v", n, tlsConfig)
+			}
+			continue
+		}
+		if tlsConfig == nil || tlsConfig.ClientCAs == nil || tlsConfig.ClientAuth != test.expectedClientAuth {
+			t.Errorf("Test %v failed. Received different TLS config %v", n, tlsConfig)
+			continue
+		}
+	}
+}
+
+func TestGetTLSConfig_ClientCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-tls")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Clients with a certificate of the client CA and with a certificate of another CA
+	clientCA := generateTestCertificate(t, "Client CA", true, nil)
+	otherCA := generateTestCertificate(t, "Other CA", true, nil)
+	validClient := generateTestCertificate(t, "service1.svc.example.com", false, clientCA)
+	untrustedClient := generateTestCertificate(t, "service2.svc.example.com", false, otherCA)
+	caFile := filepath.Join(dir, "clients.pem")
+	writeCertificatesFile(t, caFile, clientCA)
+
+	testcases := map[string]struct {
+		// Config args
+		clientAuth string
+		// Request args
+		clientCert *testCertificate
+		// Expected result
+		expectedHandshakeError bool
+		expectedStatusCode     int
+		expectedUserID         string
+	}{
+		"OkCaseRequireValidCertificate": {
+			clientAuth:         "require",
+			clientCert:         validClient,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.svc.service1",
+		},
+		"OkCaseOptionalValidCertificate": {
+			clientAuth:         "optional",
+			clientCert:         validClient,
+			expectedStatusCode: http.StatusOK,
+			expectedUserID:     "mtls.svc.service1",
+		},
+		"ErrorCaseRequireWithoutCertificate": {
+			clientAuth:             "require",
+			expectedHandshakeError: true,
+		},
+		"ErrorCaseRequireUntrustedCertificate": {
+			clientAuth:             "require",
+			clientCert:             untrustedClient,
+			expectedHandshakeError: true,
+		},
+		"ErrorCaseOptionalUntrustedCertificate": {
+			clientAuth:             "optional",
+			clientCert:             untrustedClient,
+			expectedHandshakeError: true,
+		},
+		"ErrorCaseOptionalWithoutCertificate": {
+			clientAuth:         "optional",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	connector, err := auth.InitMTLSConnector(log.New(), "mtls.", []auth.CertificateRule{
+		{Field: auth.CERT_FIELD_COMMON_NAME, Pattern: regexp.MustCompile(`^(\w+)\.svc\.example\.com$`), ExternalID: "svc.$1"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for n, test := range testcases {
+		config, err := toml.Load("[server]\nclientcafile = \"" + caFile + "\"\nclientauth = \"" + test.clientAuth + "\"")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error loading config: %v", n, err)
+			continue
+		}
+		tlsConfig, err := getTLSConfig(config, true)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		var userID string
+		server := httptest.NewUnstartedServer(connector.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID = connector.RetrieveUserID(*r)
+		})))
+		server.TLS = tlsConfig
+		server.StartTLS()
+
+		client := server.Client()
+		if test.clientCert != nil {
+			// Certificate is always sent, even if its issuer isn't one of the CAs requested by server
+			clientCert := test.clientCert.tlsCertificate()
+			client.Transport.(*http.Transport).TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &clientCert, nil
+			}
+		}
+		res, err := client.Get(server.URL)
+		server.Close()
+
+		// Check result
+		if test.expectedHandshakeError {
+			if err == nil {
+				res.Body.Close()
+				t.Errorf("Test %v failed. Expected handshake error, received http status code %v", n, res.StatusCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != test.expectedStatusCode {
+			t.Errorf("Test %v failed. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+		if userID != test.expectedUserID {
+			t.Errorf("Test %v failed. Received different user (wanted:%v / received:%v)", n, test.expectedUserID, userID)
+			continue
+		}
+	}
+}
+
+func TestGetCertificateRules(t *testing.T) {
+	testcases := map[string]struct {
+		// Config args
+		config string
+		// Expected result
+		expectedRules []string
+		expectedError string
+	}{
+		"OkCaseSingleRule": {
+			config:        "[authenticator.mtls]\nfields = \"cn\"\npatterns = \"^(\\\\w+)$\"\nexternalids = \"svc.$1\"",
+			expectedRules: []string{`cn ^(\w+)$ svc.$1`},
+		},
+		"OkCaseRuleArrays": {
+			config: "[authenticator.mtls]\nfields = [\"cn\", \"san.dns\"]\npatterns = [\"^a$\", \"^b$\"]\n" +
+				"externalids = [\"a\", \"b\"]",
+			expectedRules: []string{`cn ^a$ a`, `san.dns ^b$ b`},
+		},
+		"ErrorCaseWithoutFields": {
+			config:        "[authenticator.mtls]\npatterns = \"^a$\"\nexternalids = \"a\"",
+			expectedError: "Cannot retrieve configuration value authenticator.mtls.fields",
+		},
+		"ErrorCaseDifferentLengths": {
+			config:        "[authenticator.mtls]\nfields = [\"cn\", \"san.dns\"]\npatterns = \"^a$\"\nexternalids = [\"a\", \"b\"]",
+			expectedError: "Invalid authenticator mtls params, expected 2 patterns and externalids",
+		},
+		"ErrorCaseInvalidPattern": {
+			config:        "[authenticator.mtls]\nfields = \"cn\"\npatterns = \"^(a$\"\nexternalids = \"a\"",
+			expectedError: "Invalid authenticator mtls pattern param: ^(a$",
+		},
+	}
+
+	for n, test := range testcases {
+		config, err := toml.Load(test.config)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error loading config: %v", n, err)
+			continue
+		}
+		rules, err := getCertificateRules(config)
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test %v failed. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		received := []string{}
+		for _, rule := range rules {
+			received = append(received, rule.Field+" "+rule.Pattern.String()+" "+rule.ExternalID)
+		}
+		if diff := pretty.Compare(received, test.expectedRules); diff != "" {
+			t.Errorf("Test %v failed. Received different rules (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
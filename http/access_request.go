@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateAccessRequestRequest struct {
+	Justification string `json:"justification, omitempty"`
+	Duration      int64  `json:"duration, omitempty"`
+}
+
+// RESPONSES
+
+type ListAccessRequestsResponse struct {
+	AccessRequests []api.AccessRequest `json:"accessRequests, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group and org from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+
+	// Decode request
+	request := CreateAccessRequestRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call access request API to create an access request
+	response, err := h.worker.AccessRequestApi.AddAccessRequest(requestInfo, org, groupName, request.Justification, request.Duration)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.USER_IS_ALREADY_A_MEMBER_OF_GROUP, api.ACCESS_REQUEST_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write access request to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAccessRequests(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group and org from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+
+	// Retrieve query param if exists
+	status := r.URL.Query().Get("Status")
+
+	// Call access request API to retrieve access requests
+	result, err := h.worker.AccessRequestApi.ListAccessRequests(requestInfo, org, groupName, status)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAccessRequestsResponse{
+		AccessRequests: result,
+	}
+
+	// Return access requests
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleApproveAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group, org and access request from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+	id := ps.ByName(ACCESS_REQUEST_ID)
+
+	// Call access request API to approve access request
+	response, err := h.worker.AccessRequestApi.ApproveAccessRequest(requestInfo, org, groupName, id)
+	if err != nil {
+		h.respondAccessRequestReviewError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write access request to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRejectAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group, org and access request from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+	id := ps.ByName(ACCESS_REQUEST_ID)
+
+	// Call access request API to reject access request
+	response, err := h.worker.AccessRequestApi.RejectAccessRequest(requestInfo, org, groupName, id)
+	if err != nil {
+		h.respondAccessRequestReviewError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write access request to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// Private Helper Methods
+
+// Write error of an access request review
+func (h *WorkerHandler) respondAccessRequestReviewError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.ACCESS_REQUEST_BY_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.ACCESS_REQUEST_ALREADY_REVIEWED, api.USER_IS_ALREADY_A_MEMBER_OF_GROUP:
+		h.RespondConflict(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,632 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddAccessRequest(t *testing.T) {
+	createAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		groupName string
+		request   interface{}
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AccessRequest
+		expectedError      api.Error
+		// Manager Results
+		addAccessRequestResult *api.AccessRequest
+		// Manager Errors
+		addAccessRequestErr error
+	}{
+		"OkCase": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.AccessRequest{
+				ID:            "REQUEST-ID",
+				Requester:     "userID",
+				Org:           "org1",
+				Group:         "group1",
+				Justification: "Incident 42",
+				Duration:      3600,
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      createAt,
+			},
+			addAccessRequestResult: &api.AccessRequest{
+				ID:            "REQUEST-ID",
+				Requester:     "userID",
+				Org:           "org1",
+				Group:         "group1",
+				Justification: "Incident 42",
+				Duration:      3600,
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      createAt,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			groupName:          "group1",
+			request:            "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "json: cannot unmarshal string into Go value of type http.CreateAccessRequestRequest",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Duration: 3600,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUserIsAlreadyMember": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User is already a member of group",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User is already a member of group",
+			},
+		},
+		"ErrorCaseAccessRequestAlreadyExist": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_EXIST,
+				Message: "Access request already exist",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_EXIST,
+				Message: "Access request already exist",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:       "org1",
+			groupName: "group1",
+			request: &CreateAccessRequestRequest{
+				Justification: "Incident 42",
+				Duration:      3600,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addAccessRequestErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddAccessRequestMethod][0] = test.addAccessRequestResult
+		testApi.ArgsOut[AddAccessRequestMethod][1] = test.addAccessRequestErr
+
+		var body *bytes.Buffer
+		jsonObject, err := json.Marshal(test.request)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+		body = bytes.NewBuffer(jsonObject)
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/access-requests", test.org, test.groupName)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if request, ok := test.request.(*CreateAccessRequestRequest); ok {
+			// Check received parameters
+			if testApi.ArgsIn[AddAccessRequestMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddAccessRequestMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddAccessRequestMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddAccessRequestMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AddAccessRequestMethod][3] != request.Justification {
+				t.Errorf("Test case %v. Received different Justification (wanted:%v / received:%v)", n, request.Justification, testApi.ArgsIn[AddAccessRequestMethod][3])
+				continue
+			}
+			if testApi.ArgsIn[AddAccessRequestMethod][4] != request.Duration {
+				t.Errorf("Test case %v. Received different Duration (wanted:%v / received:%v)", n, request.Duration, testApi.ArgsIn[AddAccessRequestMethod][4])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			accessRequest := &api.AccessRequest{}
+			err = json.NewDecoder(res.Body).Decode(accessRequest)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(accessRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAccessRequests(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		groupName string
+		status    string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListAccessRequestsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAccessRequestsResult []api.AccessRequest
+		// Manager Errors
+		listAccessRequestsErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			groupName:          "group1",
+			status:             api.ACCESS_REQUEST_STATUS_PENDING,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAccessRequestsResponse{
+				AccessRequests: []api.AccessRequest{
+					{
+						ID:        "REQUEST-ID",
+						Requester: "userID",
+						Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+					},
+				},
+			},
+			listAccessRequestsResult: []api.AccessRequest{
+				{
+					ID:        "REQUEST-ID",
+					Requester: "userID",
+					Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				},
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			org:                "org1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			listAccessRequestsErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			org:                "org1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			listAccessRequestsErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "org1",
+			groupName:          "group1",
+			status:             "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			listAccessRequestsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listAccessRequestsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusInternalServerError,
+			listAccessRequestsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAccessRequestsMethod][0] = test.listAccessRequestsResult
+		testApi.ArgsOut[ListAccessRequestsMethod][1] = test.listAccessRequestsErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/access-requests?Status=%v", test.org, test.groupName, test.status)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListAccessRequestsMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAccessRequestsMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[ListAccessRequestsMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[ListAccessRequestsMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[ListAccessRequestsMethod][3] != test.status {
+			t.Errorf("Test case %v. Received different Status (wanted:%v / received:%v)", n, test.status, testApi.ArgsIn[ListAccessRequestsMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listAccessRequestsResponse := ListAccessRequestsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listAccessRequestsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listAccessRequestsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleReviewAccessRequest(t *testing.T) {
+	reviewAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expireAt := reviewAt.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		groupName string
+		id        string
+		review    string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AccessRequest
+		expectedError      api.Error
+		// Manager Results
+		reviewAccessRequestResult *api.AccessRequest
+		// Manager Errors
+		reviewAccessRequestErr error
+	}{
+		"OkCaseApprove": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "approve",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AccessRequest{
+				ID:       "REQUEST-ID",
+				Status:   api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer: "userID",
+				ReviewAt: &reviewAt,
+				ExpireAt: &expireAt,
+			},
+			reviewAccessRequestResult: &api.AccessRequest{
+				ID:       "REQUEST-ID",
+				Status:   api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer: "userID",
+				ReviewAt: &reviewAt,
+				ExpireAt: &expireAt,
+			},
+		},
+		"OkCaseReject": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "reject",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AccessRequest{
+				ID:       "REQUEST-ID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "userID",
+				ReviewAt: &reviewAt,
+			},
+			reviewAccessRequestResult: &api.AccessRequest{
+				ID:       "REQUEST-ID",
+				Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer: "userID",
+				ReviewAt: &reviewAt,
+			},
+		},
+		"ErrorCaseAccessRequestNotFound": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "approve",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_BY_ID_NOT_FOUND,
+				Message: "Access request not found",
+			},
+			reviewAccessRequestErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_BY_ID_NOT_FOUND,
+				Message: "Access request not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "reject",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			reviewAccessRequestErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "approve",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request already reviewed",
+			},
+			reviewAccessRequestErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request already reviewed",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			groupName:          "group1",
+			id:                 "REQUEST-ID",
+			review:             "reject",
+			expectedStatusCode: http.StatusInternalServerError,
+			reviewAccessRequestErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		method := ApproveAccessRequestMethod
+		if test.review == "reject" {
+			method = RejectAccessRequestMethod
+		}
+		testApi.ArgsOut[method][0] = test.reviewAccessRequestResult
+		testApi.ArgsOut[method][1] = test.reviewAccessRequestErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/access-requests/%v/%v", test.org, test.groupName, test.id, test.review)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[method][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[method][1])
+			continue
+		}
+		if testApi.ArgsIn[method][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[method][2])
+			continue
+		}
+		if testApi.ArgsIn[method][3] != test.id {
+			t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[method][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			accessRequest := &api.AccessRequest{}
+			err = json.NewDecoder(res.Body).Decode(accessRequest)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(accessRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type AddAdminRequest struct {
+	Password string `json:"password, omitempty"`
+}
+
+// RESPONSES
+
+type ListAdminsResponse struct {
+	Admins []string `json:"admins, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddAdmin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Decode request
+	request := AddAdminRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call admin API to store admin credentials
+	response, err := h.worker.AdminApi.AddAdmin(requestInfo, userID, request.Password)
+	if err != nil {
+		h.respondAdminError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAdmins(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Call admin API to retrieve admins
+	result, err := h.worker.AdminApi.ListAdmins(requestInfo)
+	if err != nil {
+		h.respondAdminError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListAdminsResponse{
+		Admins: result,
+	}
+
+	// Return admins
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveAdmin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Call admin API to remove admin credentials
+	err := h.worker.AdminApi.RemoveAdmin(requestInfo, userID)
+	if err != nil {
+		h.respondAdminError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+// Private Helper Methods
+
+// Write error of an admin operation
+func (h *WorkerHandler) respondAdminError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,371 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddAdmin(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID  string
+		request *AddAdminRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		addAdminResult *api.User
+		// Manager Errors
+		addAdminErr error
+	}{
+		"OkCase": {
+			userID: "user",
+			request: &AddAdminRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        "urn",
+			},
+			addAdminResult: &api.User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        "urn",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			userID:             "user",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID: "user",
+			request: &AddAdminRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			addAdminErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			userID: "user",
+			request: &AddAdminRequest{
+				Password: "",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addAdminErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			userID: "user",
+			request: &AddAdminRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addAdminErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID: "user",
+			request: &AddAdminRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addAdminErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddAuditEventMethod][0] = nil
+		testApi.ArgsOut[AddAdminMethod][0] = test.addAdminResult
+		testApi.ArgsOut[AddAdminMethod][1] = test.addAdminErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPut, server.URL+ADMIN_ROOT_URL+"/"+test.userID, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[AddAdminMethod][1] != test.userID || testApi.ArgsIn[AddAdminMethod][2] != test.request.Password {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[AddAdminMethod])
+				continue
+			}
+		}
+
+		// Check password isn't audited
+		if event, ok := testApi.ArgsIn[AddAuditEventMethod][0].(api.AuditEvent); ok && test.request != nil &&
+			test.request.Password != "" && strings.Contains(string(event.Request), test.request.Password) {
+			t.Errorf("Test case %v. Password stored in audit event %v", n, string(event.Request))
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := &api.User{}
+			err = json.NewDecoder(res.Body).Decode(userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAdmins(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *ListAdminsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAdminsResult []string
+		// Manager Errors
+		listAdminsErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &ListAdminsResponse{
+				Admins: []string{"admin", "user"},
+			},
+			listAdminsResult: []string{"admin", "user"},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listAdminsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listAdminsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAdminsMethod][0] = test.listAdminsResult
+		testApi.ArgsOut[ListAdminsMethod][1] = test.listAdminsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ADMIN_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			adminsResponse := &ListAdminsResponse{}
+			err = json.NewDecoder(res.Body).Decode(adminsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(adminsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveAdmin(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeAdminErr error
+	}{
+		"OkCase": {
+			userID:             "user",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseAdminNotFound": {
+			userID:             "user",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Admin not found",
+			},
+			removeAdminErr: &api.Error{
+				Code:    api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Admin not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID:             "user",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeAdminErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveAdminMethod][0] = test.removeAdminErr
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL+ADMIN_ROOT_URL+"/"+test.userID, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveAdminMethod][1] != test.userID {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[RemoveAdminMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
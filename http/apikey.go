@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateApiKeyRequest struct {
+	Name     string     `json:"name, omitempty"`
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+type RotateApiKeyRequest struct {
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+// RESPONSES
+
+type ListApiKeysResponse struct {
+	ApiKeys []api.ApiKey `json:"apiKeys, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddApiKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Decode request
+	request := CreateApiKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call API key API to create an API key
+	response, err := h.worker.ApiKeyApi.AddApiKey(requestInfo, userID, request.Name, request.ExpireAt)
+	if err != nil {
+		h.respondApiKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write API key with its secret key to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListApiKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Call API key API to retrieve API keys
+	result, err := h.worker.ApiKeyApi.ListApiKeys(requestInfo, userID)
+	if err != nil {
+		h.respondApiKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListApiKeysResponse{
+		ApiKeys: result,
+	}
+
+	// Return API keys
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRotateApiKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user and API key from path
+	userID := ps.ByName(USER_ID)
+	id := ps.ByName(API_KEY_ID)
+
+	// Decode request, body is optional
+	request := RotateApiKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && err != io.EOF {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call API key API to rotate API key
+	response, err := h.worker.ApiKeyApi.RotateApiKey(requestInfo, userID, id, request.ExpireAt)
+	if err != nil {
+		h.respondApiKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write API key with its new secret key to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRevokeApiKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user and API key from path
+	userID := ps.ByName(USER_ID)
+	id := ps.ByName(API_KEY_ID)
+
+	// Call API key API to revoke API key
+	err := h.worker.ApiKeyApi.RevokeApiKey(requestInfo, userID, id)
+	if err != nil {
+		h.respondApiKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+// Private Helper Methods
+
+// Write error of an API key operation
+func (h *WorkerHandler) respondApiKeyError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.API_KEY_BY_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,565 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddApiKey(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expireAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		userID  string
+		request *CreateApiKeyRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.ApiKeySecret
+		expectedError      api.Error
+		// Manager Results
+		addApiKeyResult *api.ApiKeySecret
+		// Manager Errors
+		addApiKeyErr error
+	}{
+		"OkCase": {
+			userID: "service",
+			request: &CreateApiKeyRequest{
+				Name:     "deploy",
+				ExpireAt: &expireAt,
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.ApiKeySecret{
+				ApiKey: api.ApiKey{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_12345678",
+					ExpireAt: &expireAt,
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				Key: "fk_1234567890",
+			},
+			addApiKeyResult: &api.ApiKeySecret{
+				ApiKey: api.ApiKey{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_12345678",
+					UserID:   "USER-ID",
+					ExpireAt: &expireAt,
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				Key: "fk_1234567890",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			userID:             "service",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID: "service",
+			request: &CreateApiKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			addApiKeyErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			userID: "service",
+			request: &CreateApiKeyRequest{
+				Name: "*deploy",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addApiKeyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			userID: "service",
+			request: &CreateApiKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addApiKeyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID: "service",
+			request: &CreateApiKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addApiKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddAuditEventMethod][0] = nil
+		testApi.ArgsOut[AddApiKeyMethod][0] = test.addApiKeyResult
+		testApi.ArgsOut[AddApiKeyMethod][1] = test.addApiKeyErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/"+test.userID+"/api-keys", body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[AddApiKeyMethod][1] != test.userID || testApi.ArgsIn[AddApiKeyMethod][2] != test.request.Name {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[AddApiKeyMethod])
+				continue
+			}
+		}
+
+		// Check secret key isn't audited
+		if event, ok := testApi.ArgsIn[AddAuditEventMethod][0].(api.AuditEvent); ok && test.addApiKeyResult != nil &&
+			strings.Contains(string(event.Request), test.addApiKeyResult.Key) {
+			t.Errorf("Test case %v. Secret key stored in audit event %v", n, string(event.Request))
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			keyResponse := &api.ApiKeySecret{}
+			err = json.NewDecoder(res.Body).Decode(keyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(keyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListApiKeys(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		userID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *ListApiKeysResponse
+		expectedError      api.Error
+		// Manager Results
+		listApiKeysResult []api.ApiKey
+		// Manager Errors
+		listApiKeysErr error
+	}{
+		"OkCase": {
+			userID:             "service",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &ListApiKeysResponse{
+				ApiKeys: []api.ApiKey{
+					{
+						ID:       "KEY-ID",
+						Name:     "deploy",
+						Prefix:   "fk_12345678",
+						CreateAt: now,
+						UpdateAt: now,
+					},
+				},
+			},
+			listApiKeysResult: []api.ApiKey{
+				{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_12345678",
+					UserID:   "USER-ID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID:             "service",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			listApiKeysErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID:             "service",
+			expectedStatusCode: http.StatusInternalServerError,
+			listApiKeysErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListApiKeysMethod][0] = test.listApiKeysResult
+		testApi.ArgsOut[ListApiKeysMethod][1] = test.listApiKeysErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+USER_ROOT_URL+"/"+test.userID+"/api-keys", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListApiKeysMethod][1] != test.userID {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[ListApiKeysMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			keysResponse := &ListApiKeysResponse{}
+			err = json.NewDecoder(res.Body).Decode(keysResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(keysResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRotateApiKey(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		userID  string
+		id      string
+		request *RotateApiKeyRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.ApiKeySecret
+		expectedError      api.Error
+		// Manager Results
+		rotateApiKeyResult *api.ApiKeySecret
+		// Manager Errors
+		rotateApiKeyErr error
+	}{
+		"OkCaseWithoutBody": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.ApiKeySecret{
+				ApiKey: api.ApiKey{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_87654321",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				Key: "fk_8765432100",
+			},
+			rotateApiKeyResult: &api.ApiKeySecret{
+				ApiKey: api.ApiKey{
+					ID:       "KEY-ID",
+					Name:     "deploy",
+					Prefix:   "fk_87654321",
+					UserID:   "USER-ID",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				Key: "fk_8765432100",
+			},
+		},
+		"ErrorCaseApiKeyNotFound": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			request:            &RotateApiKeyRequest{},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.API_KEY_BY_ID_NOT_FOUND,
+				Message: "API key not found",
+			},
+			rotateApiKeyErr: &api.Error{
+				Code:    api.API_KEY_BY_ID_NOT_FOUND,
+				Message: "API key not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			request:            &RotateApiKeyRequest{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			rotateApiKeyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RotateApiKeyMethod][0] = test.rotateApiKeyResult
+		testApi.ArgsOut[RotateApiKeyMethod][1] = test.rotateApiKeyErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/"+test.userID+"/api-keys/"+test.id+"/rotate", body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RotateApiKeyMethod][1] != test.userID || testApi.ArgsIn[RotateApiKeyMethod][2] != test.id {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[RotateApiKeyMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			keyResponse := &api.ApiKeySecret{}
+			err = json.NewDecoder(res.Body).Decode(keyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(keyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRevokeApiKey(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID string
+		id     string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		revokeApiKeyErr error
+	}{
+		"OkCase": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseApiKeyNotFound": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.API_KEY_BY_ID_NOT_FOUND,
+				Message: "API key not found",
+			},
+			revokeApiKeyErr: &api.Error{
+				Code:    api.API_KEY_BY_ID_NOT_FOUND,
+				Message: "API key not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID:             "service",
+			id:                 "KEY-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			revokeApiKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RevokeApiKeyMethod][0] = test.revokeApiKeyErr
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL+USER_ROOT_URL+"/"+test.userID+"/api-keys/"+test.id, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RevokeApiKeyMethod][1] != test.userID || testApi.ArgsIn[RevokeApiKeyMethod][2] != test.id {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[RevokeApiKeyMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// RESPONSES
+
+type ListAuditEventsResponse struct {
+	Events []api.AuditEvent `json:"events, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListAuditEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve filters
+	filter := api.AuditFilter{
+		User:      r.URL.Query().Get("User"),
+		Action:    r.URL.Query().Get("Action"),
+		UrnPrefix: r.URL.Query().Get("UrnPrefix"),
+	}
+	var err error
+	if filter.From, err = getTimeQueryParam(r, "From"); err == nil {
+		filter.To, err = getTimeQueryParam(r, "To")
+	}
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call audit API to retrieve audit events
+	result, err := h.worker.AuditApi.ListAuditEvents(requestInfo, filter)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAuditEventsResponse{
+		Events: result,
+	}
+
+	// Return audit events
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// AUDIT
+
+type auditContextKey struct{}
+
+// Fields of request bodies that aren't stored in audit events
+var auditRedactedFields = []string{"secret", "password"}
+
+// Audit event of a request being handled. Discarded events aren't stored.
+type auditRecord struct {
+	event     *api.AuditEvent
+	discarded bool
+}
+
+// Response writer that keeps status code and body of error responses
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (aw *auditResponseWriter) WriteHeader(status int) {
+	aw.status = status
+	aw.ResponseWriter.WriteHeader(status)
+}
+
+func (aw *auditResponseWriter) Write(b []byte) (int, error) {
+	if aw.status >= http.StatusBadRequest {
+		aw.body.Write(b)
+	}
+	return aw.ResponseWriter.Write(b)
+}
+
+// Wrap handler of a mutation to store its audit event whatever its outcome. Target of the event is worked out
+// from the request before it's handled, so failed mutations keep it too. API replaces it with the urn of the
+// target when it's found and fills snapshots of the event when the mutation is done.
+func (h *WorkerHandler) audited(action string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		userID, _ := h.worker.Authenticator.GetAuthenticatedUser(r)
+		record := &auditRecord{
+			event: &api.AuditEvent{
+				RequestID: r.Header.Get(REQUEST_ID_HEADER),
+				User:      userID,
+				Action:    action,
+				CreateAt:  time.Now().UTC(),
+			},
+		}
+
+		// Keep request body, only JSON bodies are stored
+		var body []byte
+		if r.Body != nil {
+			var err error
+			if body, err = ioutil.ReadAll(r.Body); err == nil && json.Valid(body) {
+				record.event.Request = redactRequestBody(body)
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		record.event.Urn = auditTargetUrn(action, ps, body)
+
+		aw := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		handle(aw, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, record)), ps)
+		if record.discarded {
+			return
+		}
+
+		// Set outcome
+		record.event.Outcome = api.AUDIT_OUTCOME_SUCCESS
+		if aw.status >= http.StatusBadRequest {
+			record.event.Outcome = api.AUDIT_OUTCOME_FAILURE
+			apiError := &api.Error{}
+			if err := json.Unmarshal(aw.body.Bytes(), apiError); err != nil || len(apiError.Code) == 0 {
+				apiError = &api.Error{
+					Code:    api.UNKNOWN_API_ERROR,
+					Message: fmt.Sprintf("Response with status %v", aw.status),
+				}
+			}
+			record.event.Error = apiError
+		}
+
+		if _, err := h.worker.AuditApi.AddAuditEvent(*record.event); err != nil {
+			api.LogErrorMessage(h.worker.Logger, h.GetRequestInfo(r), err.(*api.Error))
+		}
+	}
+}
+
+// Private Helper Methods
+
+// Retrieve audit event of the request, nil if it isn't audited
+func getAuditEvent(r *http.Request) *api.AuditEvent {
+	if record, ok := r.Context().Value(auditContextKey{}).(*auditRecord); ok {
+		return record.event
+	}
+	return nil
+}
+
+// Work out urn of the target of an audited mutation from route params and from name and path of the created
+// resource in the body. Paths of existing resources aren't in the route, so their root path is used until API
+// finds the target. Empty if the target isn't known before the mutation.
+func auditTargetUrn(action string, ps httprouter.Params, body []byte) string {
+	request := struct {
+		Name       string `json:"name"`
+		ExternalID string `json:"externalId"`
+		Path       string `json:"path"`
+	}{}
+	json.Unmarshal(body, &request)
+	path := request.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	org := ps.ByName(ORG_NAME)
+	switch {
+	case len(ps.ByName(GROUP_NAME)) > 0:
+		return api.CreateUrn(org, api.RESOURCE_GROUP, path, ps.ByName(GROUP_NAME))
+	case len(ps.ByName(POLICY_NAME)) > 0:
+		return api.CreateUrn(org, api.RESOURCE_POLICY, path, ps.ByName(POLICY_NAME))
+	case len(ps.ByName(USER_ID)) > 0:
+		return api.CreateUrn("", api.RESOURCE_USER, path, ps.ByName(USER_ID))
+	case len(ps.ByName(WEBHOOK_ID)) > 0:
+		return api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", ps.ByName(WEBHOOK_ID))
+	}
+
+	switch action {
+	case api.ORGANIZATION_ACTION_CREATE_ORGANIZATION:
+		return api.CreateUrn(request.Name, api.RESOURCE_ORGANIZATION, "/", request.Name)
+	case api.ORGANIZATION_ACTION_DELETE_ORGANIZATION:
+		return api.CreateUrn(org, api.RESOURCE_ORGANIZATION, "/", org)
+	case api.GROUP_ACTION_CREATE_GROUP:
+		return api.CreateUrn(org, api.RESOURCE_GROUP, path, request.Name)
+	case api.POLICY_ACTION_CREATE_POLICY:
+		return api.CreateUrn(org, api.RESOURCE_POLICY, path, request.Name)
+	case api.USER_ACTION_CREATE_USER:
+		return api.CreateUrn("", api.RESOURCE_USER, path, request.ExternalID)
+	}
+	return ""
+}
+
+// Replace secret fields of a JSON object body, other bodies are kept as they are
+func redactRequestBody(body []byte) []byte {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	redacted := false
+	for _, field := range auditRedactedFields {
+		if _, ok := fields[field]; ok {
+			fields[field] = json.RawMessage(`"REDACTED"`)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return body
+	}
+	redactedBody, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return redactedBody
+}
+
+// Discard audit event of a request that doesn't mutate anything
+func discardAuditEvent(r *http.Request) {
+	if record, ok := r.Context().Value(auditContextKey{}).(*auditRecord); ok {
+		record.discarded = true
+	}
+}
+
+// Retrieve optional RFC3339 time from query param
+func getTimeQueryParam(r *http.Request, param string) (*time.Time, error) {
+	value := r.URL.Query().Get(param)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v, it must be a RFC3339 time", param, value),
+		}
+	}
+	return &t, nil
+}
@@ -0,0 +1,319 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleListAuditEvents(t *testing.T) {
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		user      string
+		action    string
+		urnPrefix string
+		from      string
+		to        string
+		// Expected result
+		expectedStatusCode int
+		expectedFilter     api.AuditFilter
+		expectedResponse   ListAuditEventsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAuditEventsResult []api.AuditEvent
+		// Manager Errors
+		listAuditEventsErr error
+	}{
+		"OkCase": {
+			user:               "123",
+			action:             api.USER_ACTION_CREATE_USER,
+			urnPrefix:          "urn:iws:iam::user/",
+			from:               from.Format(time.RFC3339),
+			to:                 to.Format(time.RFC3339),
+			expectedStatusCode: http.StatusOK,
+			expectedFilter: api.AuditFilter{
+				User:      "123",
+				Action:    api.USER_ACTION_CREATE_USER,
+				UrnPrefix: "urn:iws:iam::user/",
+				From:      &from,
+				To:        &to,
+			},
+			expectedResponse: ListAuditEventsResponse{
+				Events: []api.AuditEvent{
+					{
+						ID:       "EVENT-ID",
+						User:     "123",
+						Action:   api.USER_ACTION_CREATE_USER,
+						Urn:      "urn:iws:iam::user/path/456",
+						Request:  json.RawMessage("null"),
+						Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+						Before:   json.RawMessage("null"),
+						After:    json.RawMessage(`{"externalId":"456"}`),
+						CreateAt: from,
+					},
+				},
+			},
+			listAuditEventsResult: []api.AuditEvent{
+				{
+					ID:       "EVENT-ID",
+					User:     "123",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:iws:iam::user/path/456",
+					Outcome:  api.AUDIT_OUTCOME_SUCCESS,
+					After:    json.RawMessage(`{"externalId":"456"}`),
+					CreateAt: from,
+				},
+			},
+		},
+		"ErrorCaseInvalidFrom": {
+			from:               "yesterday",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: From yesterday, it must be a RFC3339 time",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			action:             "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedFilter: api.AuditFilter{
+				Action: "invalid",
+			},
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			listAuditEventsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listAuditEventsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listAuditEventsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[ListAuditEventsMethod][1] = api.AuditFilter{}
+		testApi.ArgsOut[ListAuditEventsMethod][0] = test.listAuditEventsResult
+		testApi.ArgsOut[ListAuditEventsMethod][1] = test.listAuditEventsErr
+
+		query := url.Values{}
+		query.Set("User", test.user)
+		query.Set("Action", test.action)
+		query.Set("UrnPrefix", test.urnPrefix)
+		query.Set("From", test.from)
+		query.Set("To", test.to)
+		req, err := http.NewRequest(http.MethodGet, server.URL+AUDIT_URL+"?"+query.Encode(), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if diff := pretty.Compare(testApi.ArgsIn[ListAuditEventsMethod][1], test.expectedFilter); diff != "" {
+			t.Errorf("Test %v failed. Received different filter (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listAuditEventsResponse := ListAuditEventsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listAuditEventsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listAuditEventsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_audited(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID    string
+		groupBody string
+		// Expected result
+		expectedStatusCode int
+		expectedEvent      api.AuditEvent
+		// Manager Errors
+		removeUserErr error
+		addGroupErr   error
+	}{
+		"OkCase": {
+			userID:             "123",
+			expectedStatusCode: http.StatusNoContent,
+			expectedEvent: api.AuditEvent{
+				User:    "userID",
+				Action:  api.USER_ACTION_DELETE_USER,
+				Urn:     api.CreateUrn("", api.RESOURCE_USER, "/", "123"),
+				Outcome: api.AUDIT_OUTCOME_SUCCESS,
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID:             "123",
+			expectedStatusCode: http.StatusNotFound,
+			expectedEvent: api.AuditEvent{
+				User:    "userID",
+				Action:  api.USER_ACTION_DELETE_USER,
+				Urn:     api.CreateUrn("", api.RESOURCE_USER, "/", "123"),
+				Outcome: api.AUDIT_OUTCOME_FAILURE,
+				Error: &api.Error{
+					Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+					Message: "User not found",
+				},
+			},
+			removeUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID:             "123",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedEvent: api.AuditEvent{
+				User:    "userID",
+				Action:  api.USER_ACTION_DELETE_USER,
+				Urn:     api.CreateUrn("", api.RESOURCE_USER, "/", "123"),
+				Outcome: api.AUDIT_OUTCOME_FAILURE,
+				Error: &api.Error{
+					Code:    api.UNKNOWN_API_ERROR,
+					Message: "Response with status 500",
+				},
+			},
+			removeUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseCreateGroupUnauthorized": {
+			groupBody:          `{"name":"group1","path":"/path/"}`,
+			expectedStatusCode: http.StatusForbidden,
+			expectedEvent: api.AuditEvent{
+				User:    "userID",
+				Action:  api.GROUP_ACTION_CREATE_GROUP,
+				Urn:     api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group1"),
+				Request: json.RawMessage(`{"name":"group1","path":"/path/"}`),
+				Outcome: api.AUDIT_OUTCOME_FAILURE,
+				Error: &api.Error{
+					Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+					Message: "Unauthorized",
+				},
+			},
+			addGroupErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddAuditEventMethod][0] = nil
+		testApi.ArgsOut[RemoveUserMethod][0] = test.removeUserErr
+		testApi.ArgsOut[AddGroupMethod][1] = test.addGroupErr
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v", test.userID), nil)
+		if len(test.groupBody) > 0 {
+			req, err = http.NewRequest(http.MethodPost, server.URL+API_VERSION_1+"/organizations/org1/groups",
+				strings.NewReader(test.groupBody))
+		}
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		// Check stored audit event
+		event, ok := testApi.ArgsIn[AddAuditEventMethod][0].(api.AuditEvent)
+		if !ok {
+			t.Errorf("Test case %v. Audit event not stored", n)
+			continue
+		}
+		if event.CreateAt.IsZero() {
+			t.Errorf("Test case %v. Audit event without creation time", n)
+			continue
+		}
+		if event.RequestID != res.Header.Get(REQUEST_ID_HEADER) {
+			t.Errorf("Test case %v. Received different RequestID (wanted:%v / received:%v)", n, res.Header.Get(REQUEST_ID_HEADER), event.RequestID)
+			continue
+		}
+		event.CreateAt = time.Time{}
+		event.RequestID = ""
+		if diff := pretty.Compare(event, test.expectedEvent); diff != "" {
+			t.Errorf("Test %v failed. Received different audit event (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
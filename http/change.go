@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+const (
+	// Change feed defaults and limits
+	DEFAULT_CHANGES_LIMIT = 100
+	MAX_CHANGES_WAIT      = 60
+
+	// Server-Sent Events
+	EVENT_STREAM_CONTENT_TYPE = "text/event-stream"
+	LAST_EVENT_ID_HEADER      = "Last-Event-ID"
+)
+
+// Interval between checks of new changes while clients wait for them, and between keepalive comments
+// of event streams. They are variables so tests can shorten them.
+var changesPollInterval = time.Second
+var changesKeepAliveInterval = 15 * time.Second
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListChanges(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve query params
+	since, err := getIntQueryParam(r, "since", 0)
+	if err == nil && r.Header.Get(LAST_EVENT_ID_HEADER) != "" {
+		// Reconnecting event streams resume from their last event
+		since, err = strconv.ParseInt(r.Header.Get(LAST_EVENT_ID_HEADER), 10, 64)
+		if err != nil {
+			err = &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: %v %v", LAST_EVENT_ID_HEADER, r.Header.Get(LAST_EVENT_ID_HEADER)),
+			}
+		}
+	}
+	var limit, wait int64
+	if err == nil {
+		limit, err = getIntQueryParam(r, "limit", DEFAULT_CHANGES_LIMIT)
+	}
+	if err == nil {
+		wait, err = getIntQueryParam(r, "wait", 0)
+		if err == nil && (wait < 0 || wait > MAX_CHANGES_WAIT) {
+			err = &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: wait %v, it must be between 0 and %v seconds", wait, MAX_CHANGES_WAIT),
+			}
+		}
+	}
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call change API to retrieve first changes, so errors are returned before streaming
+	feed, err := h.worker.ChangeApi.ListChanges(requestInfo, since, int(limit))
+	if err != nil {
+		h.respondChangeError(r, requestInfo, w, err)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), EVENT_STREAM_CONTENT_TYPE) {
+		h.streamChanges(w, r, requestInfo, feed, int(limit))
+		return
+	}
+
+	// Long polling, wait until there are new changes or wait time is over
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for feed.LastSeq == since && time.Now().Before(deadline) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(changesPollInterval):
+		}
+		feed, err = h.worker.ChangeApi.ListChanges(requestInfo, since, int(limit))
+		if err != nil {
+			h.respondChangeError(r, requestInfo, w, err)
+			return
+		}
+	}
+
+	// Return changes
+	h.RespondOk(r, requestInfo, w, feed)
+}
+
+// Private Helper Methods
+
+// Send changes as Server-Sent Events until client disconnects. Event id is the sequence number of the change,
+// so reconnecting clients resume from it with Last-Event-ID header.
+func (h *WorkerHandler) streamChanges(w http.ResponseWriter, r *http.Request, requestInfo api.RequestInfo, feed *api.ChangeFeed, limit int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", EVENT_STREAM_CONTENT_TYPE)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.Now().Add(changesKeepAliveInterval)
+	for {
+		for _, change := range feed.Changes {
+			data, err := json.Marshal(change)
+			if err != nil {
+				h.worker.Logger.Errorf("Unable to serialize change %v: %v", change, err)
+				return
+			}
+			fmt.Fprintf(w, "id: %v\nevent: change\ndata: %s\n\n", change.Seq, data)
+		}
+		if len(feed.Changes) > 0 {
+			flusher.Flush()
+			keepAlive = time.Now().Add(changesKeepAliveInterval)
+		} else if time.Now().After(keepAlive) {
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+			keepAlive = time.Now().Add(changesKeepAliveInterval)
+		}
+
+		// Read next changes, waiting for new ones if all were read
+		since := feed.LastSeq
+		if len(feed.Changes) < limit {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(changesPollInterval):
+			}
+		}
+		var err error
+		feed, err = h.worker.ChangeApi.ListChanges(requestInfo, since, limit)
+		if err != nil {
+			api.LogErrorMessage(h.worker.Logger, requestInfo, err.(*api.Error))
+			return
+		}
+	}
+}
+
+// Write error of a change feed operation
+func (h *WorkerHandler) respondChangeError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
+
+// Retrieve optional integer from query param, defaultValue if it's empty
+func getIntQueryParam(r *http.Request, param string, defaultValue int64) (int64, error) {
+	value := r.URL.Query().Get(param)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v, it must be an integer", param, value),
+		}
+	}
+	return i, nil
+}
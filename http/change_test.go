@@ -0,0 +1,302 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleListChanges(t *testing.T) {
+	changesPollInterval = 10 * time.Millisecond
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		query string
+		// Expected result
+		expectedStatusCode int
+		expectedSince      int64
+		expectedLimit      int
+		expectedResponse   *api.ChangeFeed
+		expectedError      api.Error
+		// Manager Results
+		listChangesResult *api.ChangeFeed
+		// Manager Errors
+		listChangesErr error
+	}{
+		"OkCase": {
+			query:              "since=10&limit=50",
+			expectedStatusCode: http.StatusOK,
+			expectedSince:      10,
+			expectedLimit:      50,
+			expectedResponse: &api.ChangeFeed{
+				Changes: []api.Change{
+					{
+						Seq:      11,
+						Type:     api.USER_ACTION_CREATE_USER,
+						Urn:      "urn:iws:iam::user/path/123",
+						Before:   json.RawMessage("null"),
+						After:    json.RawMessage(`{"externalId":"123"}`),
+						CreateAt: now,
+					},
+				},
+				LastSeq: 11,
+			},
+			listChangesResult: &api.ChangeFeed{
+				Changes: []api.Change{
+					{
+						Seq:      11,
+						Type:     api.USER_ACTION_CREATE_USER,
+						Urn:      "urn:iws:iam::user/path/123",
+						After:    json.RawMessage(`{"externalId":"123"}`),
+						CreateAt: now,
+					},
+				},
+				LastSeq: 11,
+			},
+		},
+		"OkCaseLongPollingTimeout": {
+			query:              "since=11&wait=1",
+			expectedStatusCode: http.StatusOK,
+			expectedSince:      11,
+			expectedLimit:      DEFAULT_CHANGES_LIMIT,
+			expectedResponse: &api.ChangeFeed{
+				Changes: []api.Change{},
+				LastSeq: 11,
+			},
+			listChangesResult: &api.ChangeFeed{
+				Changes: []api.Change{},
+				LastSeq: 11,
+			},
+		},
+		"ErrorCaseInvalidSince": {
+			query:              "since=last",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: since last, it must be an integer",
+			},
+		},
+		"ErrorCaseInvalidWait": {
+			query:              "wait=61",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: wait 61, it must be between 0 and 60 seconds",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			query:              "since=-1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedSince:      -1,
+			expectedLimit:      DEFAULT_CHANGES_LIMIT,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			listChangesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedLimit:      DEFAULT_CHANGES_LIMIT,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listChangesErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedLimit:      DEFAULT_CHANGES_LIMIT,
+			listChangesErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[ListChangesMethod][1] = int64(0)
+		testApi.ArgsIn[ListChangesMethod][2] = 0
+		testApi.ArgsOut[ListChangesMethod][0] = test.listChangesResult
+		testApi.ArgsOut[ListChangesMethod][1] = test.listChangesErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+CHANGES_URL+"?"+test.query, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListChangesMethod][1] != test.expectedSince || testApi.ArgsIn[ListChangesMethod][2] != test.expectedLimit {
+			t.Errorf("Test case %v. Received different parameters (wanted:%v,%v / received:%v,%v)", n, test.expectedSince,
+				test.expectedLimit, testApi.ArgsIn[ListChangesMethod][1], testApi.ArgsIn[ListChangesMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			changeFeedResponse := &api.ChangeFeed{}
+			err = json.NewDecoder(res.Body).Decode(changeFeedResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(changeFeedResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListChangesEventStream(t *testing.T) {
+	changesPollInterval = 10 * time.Millisecond
+	testcases := map[string]struct {
+		// API method args
+		query       string
+		lastEventID string
+		// Expected result
+		expectedNextSince int64
+		expectedEvent     []string
+		// Manager Results
+		listChangesResult *api.ChangeFeed
+	}{
+		"OkCase": {
+			query:             "since=10",
+			expectedNextSince: 11,
+			expectedEvent: []string{
+				"id: 11",
+				"event: change",
+				`data: {"seq":11,"type":"iam:CreateUser","urn":"urn:iws:iam::user/path/123","before":null,"after":null,"createAt":"0001-01-01T00:00:00Z"}`,
+			},
+			listChangesResult: &api.ChangeFeed{
+				Changes: []api.Change{
+					{
+						Seq:  11,
+						Type: api.USER_ACTION_CREATE_USER,
+						Urn:  "urn:iws:iam::user/path/123",
+					},
+				},
+				LastSeq: 11,
+			},
+		},
+		"OkCaseLastEventID": {
+			query:             "since=1",
+			lastEventID:       "20",
+			expectedNextSince: 21,
+			expectedEvent: []string{
+				"id: 21",
+				"event: change",
+				`data: {"seq":21,"type":"iam:DeleteUser","urn":"urn:iws:iam::user/path/123","before":null,"after":null,"createAt":"0001-01-01T00:00:00Z"}`,
+			},
+			listChangesResult: &api.ChangeFeed{
+				Changes: []api.Change{
+					{
+						Seq:  21,
+						Type: api.USER_ACTION_DELETE_USER,
+						Urn:  "urn:iws:iam::user/path/123",
+					},
+				},
+				LastSeq: 21,
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListChangesMethod][0] = test.listChangesResult
+		testApi.ArgsOut[ListChangesMethod][1] = nil
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+CHANGES_URL+"?"+test.query, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.Header.Set("Accept", EVENT_STREAM_CONTENT_TYPE)
+		if test.lastEventID != "" {
+			req.Header.Set(LAST_EVENT_ID_HEADER, test.lastEventID)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check headers
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != EVENT_STREAM_CONTENT_TYPE {
+			t.Errorf("Test case %v. Received unexpected response %v %v", n, res.StatusCode, res.Header.Get("Content-Type"))
+			res.Body.Close()
+			continue
+		}
+
+		// Read first event
+		reader := bufio.NewReader(res.Body)
+		event := []string{}
+		for len(event) < len(test.expectedEvent) {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error reading event %v", n, err)
+				break
+			}
+			event = append(event, strings.TrimSuffix(line, "\n"))
+		}
+		res.Body.Close()
+		// Let server notice the disconnection before changing the mocks
+		time.Sleep(5 * changesPollInterval)
+
+		// Stream keeps polling from the last delivered sequence
+		if testApi.ArgsIn[ListChangesMethod][1] != test.expectedNextSince {
+			t.Errorf("Test case %v. Received different since (wanted:%v / received:%v)", n, test.expectedNextSince, testApi.ArgsIn[ListChangesMethod][1])
+			continue
+		}
+		if diff := pretty.Compare(event, test.expectedEvent); diff != "" {
+			t.Errorf("Test %v failed. Received different events (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/tecsisa/foulkon/api"
@@ -11,17 +13,42 @@ import (
 // REQUESTS
 
 type CreateGroupRequest struct {
-	Name string `json:"name, omitempty"`
-	Path string `json:"path, omitempty"`
+	Name        string `json:"name, omitempty"`
+	Path        string `json:"path, omitempty"`
+	DisplayName string `json:"displayName, omitempty"`
+	Description string `json:"description, omitempty"`
 }
 
 type UpdateGroupRequest struct {
-	Name string `json:"name, omitempty"`
-	Path string `json:"path, omitempty"`
+	Name        string `json:"name, omitempty"`
+	Path        string `json:"path, omitempty"`
+	DisplayName string `json:"displayName, omitempty"`
+	Description string `json:"description, omitempty"`
+}
+
+type AddMemberRequest struct {
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+type AttachPolicyRequest struct {
+	NotBefore *time.Time `json:"notBefore, omitempty"`
+	NotAfter  *time.Time `json:"notAfter, omitempty"`
+}
+
+type GroupMembersRequest struct {
+	Members []string `json:"members, omitempty"`
+}
+
+type GroupPoliciesRequest struct {
+	Policies []string `json:"policies, omitempty"`
 }
 
 // RESPONSES
 
+type BulkOperationResponse struct {
+	Results []api.BulkItemResult `json:"results, omitempty"`
+}
+
 type ListGroupsResponse struct {
 	Groups []string `json:"groups, omitempty"`
 }
@@ -31,11 +58,11 @@ type ListAllGroupsResponse struct {
 }
 
 type ListMembersResponse struct {
-	Members []string `json:"members, omitempty"`
+	Members []api.GroupMemberIdentity `json:"members, omitempty"`
 }
 
 type ListAttachedGroupPoliciesResponse struct {
-	AttachedPolicies []string `json:"policies, omitempty"`
+	AttachedPolicies []api.GroupPolicyIdentity `json:"policies, omitempty"`
 }
 
 // HANDLERS
@@ -57,7 +84,8 @@ func (h *WorkerHandler) HandleAddGroup(w http.ResponseWriter, r *http.Request, p
 
 	org := ps.ByName(ORG_NAME)
 	// Call group API to create a group
-	response, err := h.worker.GroupApi.AddGroup(requestInfo, org, request.Name, request.Path)
+	response, err := h.worker.GroupApi.AddGroup(requestInfo, org, request.Name, request.Path, request.DisplayName,
+		request.Description)
 
 	// Error handling
 	if err != nil {
@@ -109,6 +137,7 @@ func (h *WorkerHandler) HandleGetGroupByName(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Write group to response
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -201,8 +230,18 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 	org := ps.ByName(ORG_NAME)
 	groupName := ps.ByName(GROUP_NAME)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call group API to update group
-	response, err := h.worker.GroupApi.UpdateGroup(requestInfo, org, groupName, request.Name, request.Path)
+	response, err := h.worker.GroupApi.UpdateGroup(requestInfo, org, groupName, request.Name, request.Path,
+		request.DisplayName, request.Description, version)
 
 	// Check errors
 	if err != nil {
@@ -212,6 +251,8 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 		switch apiError.Code {
 		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.GROUP_ALREADY_EXIST:
 			h.RespondConflict(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
@@ -225,6 +266,7 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 	}
 
 	// Write group to response
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -234,8 +276,17 @@ func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request
 	org := ps.ByName(ORG_NAME)
 	name := ps.ByName(GROUP_NAME)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call user API to delete group
-	err := h.worker.GroupApi.RemoveGroup(requestInfo, org, name)
+	err = h.worker.GroupApi.RemoveGroup(requestInfo, org, name, version)
 
 	// Check if there were errors
 	if err != nil {
@@ -245,6 +296,8 @@ func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request
 		switch apiError.Code {
 		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
@@ -258,6 +311,77 @@ func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleListDeletedGroups(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Retrieve query param if exists
+	pathPrefix := r.URL.Query().Get("PathPrefix")
+
+	// Call group API to retrieve deleted groups
+	result, err := h.worker.GroupApi.ListDeletedGroups(requestInfo, org, pathPrefix)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	groups := []string{}
+	for _, group := range result {
+		groups = append(groups, group.Name)
+	}
+
+	// Create response
+	response := &ListGroupsResponse{
+		Groups: groups,
+	}
+
+	// Return deleted groups
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRestoreGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and group name from path
+	org := ps.ByName(ORG_NAME)
+	name := ps.ByName(GROUP_NAME)
+
+	// Call group API to restore group
+	response, err := h.worker.GroupApi.RestoreGroup(requestInfo, org, name)
+
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.GROUP_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write restored group to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
 func (h *WorkerHandler) HandleAddMember(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve group, org and user from path
@@ -265,8 +389,21 @@ func (h *WorkerHandler) HandleAddMember(w http.ResponseWriter, r *http.Request,
 	user := ps.ByName(USER_ID)
 	group := ps.ByName(GROUP_NAME)
 
+	// Decode request, body is optional
+	request := AddMemberRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && err != io.EOF {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call group API to create an group
-	err := h.worker.GroupApi.AddMember(requestInfo, user, group, org)
+	err = h.worker.GroupApi.AddMember(requestInfo, user, group, org, request.ExpireAt)
 	// Error handling
 	if err != nil {
 		// Transform to API errors
@@ -320,6 +457,46 @@ func (h *WorkerHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Reques
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleAddMembers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := GroupMembersRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to add members to group
+	results, err := h.worker.GroupApi.AddMembers(requestInfo, ps.ByName(ORG_NAME), ps.ByName(GROUP_NAME), request.Members)
+	h.respondBulkOperation(r, requestInfo, w, results, err)
+}
+
+func (h *WorkerHandler) HandleRemoveMembers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := GroupMembersRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to remove members from group
+	results, err := h.worker.GroupApi.RemoveMembers(requestInfo, ps.ByName(ORG_NAME), ps.ByName(GROUP_NAME), request.Members)
+	h.respondBulkOperation(r, requestInfo, w, results, err)
+}
+
 func (h *WorkerHandler) HandleListMembers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve group, org
@@ -363,8 +540,21 @@ func (h *WorkerHandler) HandleAttachPolicyToGroup(w http.ResponseWriter, r *http
 	groupName := ps.ByName(GROUP_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
+	// Decode request, body is optional
+	request := AttachPolicyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && err != io.EOF {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call group API to attach policy to group
-	err := h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName)
+	err = h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName, request.NotBefore, request.NotAfter)
 
 	// Error handling
 	if err != nil {
@@ -422,6 +612,46 @@ func (h *WorkerHandler) HandleDetachPolicyToGroup(w http.ResponseWriter, r *http
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleAttachPoliciesToGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := GroupPoliciesRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to attach policies to group
+	results, err := h.worker.GroupApi.AttachPoliciesToGroup(requestInfo, ps.ByName(ORG_NAME), ps.ByName(GROUP_NAME), request.Policies)
+	h.respondBulkOperation(r, requestInfo, w, results, err)
+}
+
+func (h *WorkerHandler) HandleDetachPoliciesToGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := GroupPoliciesRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to detach policies from group
+	results, err := h.worker.GroupApi.DetachPoliciesToGroup(requestInfo, ps.ByName(ORG_NAME), ps.ByName(GROUP_NAME), request.Policies)
+	h.respondBulkOperation(r, requestInfo, w, results, err)
+}
+
 func (h *WorkerHandler) HandleListAttachedGroupPolicies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve group, org from path
@@ -455,3 +685,32 @@ func (h *WorkerHandler) HandleListAttachedGroupPolicies(w http.ResponseWriter, r
 	// Return group policies
 	h.RespondOk(r, requestInfo, w, response)
 }
+
+// Write the result of each item of a bulk operation over group relationships
+func (h *WorkerHandler) respondBulkOperation(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter,
+	results []api.BulkItemResult, err error) {
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	response := &BulkOperationResponse{
+		Results: results,
+	}
+
+	// Write result of each item to response
+	h.RespondOk(r, requestInfo, w, response)
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateGroupMappingRequest struct {
+	Issuer string `json:"issuer, omitempty"`
+	Claim  string `json:"claim, omitempty"`
+	Value  string `json:"value, omitempty"`
+	Org    string `json:"org, omitempty"`
+	Group  string `json:"group, omitempty"`
+}
+
+// RESPONSES
+
+type ListGroupMappingsResponse struct {
+	GroupMappings []api.GroupMapping `json:"groupMappings, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddGroupMapping(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateGroupMappingRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group mapping API to create a group mapping
+	response, err := h.worker.GroupMappingApi.AddGroupMapping(requestInfo, request.Issuer, request.Claim, request.Value, request.Org,
+		request.Group)
+	if err != nil {
+		h.respondGroupMappingError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write group mapping to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetGroupMappingByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group mapping from path
+	id := ps.ByName(GROUP_MAPPING_ID)
+
+	// Call group mapping API to retrieve group mapping
+	response, err := h.worker.GroupMappingApi.GetGroupMappingByID(requestInfo, id)
+	if err != nil {
+		h.respondGroupMappingError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write group mapping to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListGroupMappings(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Retrieve query param if exists
+	org := r.URL.Query().Get("Org")
+
+	// Call group mapping API to retrieve group mappings
+	result, err := h.worker.GroupMappingApi.ListGroupMappings(requestInfo, org)
+	if err != nil {
+		h.respondGroupMappingError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListGroupMappingsResponse{
+		GroupMappings: result,
+	}
+
+	// Return group mappings
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveGroupMapping(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group mapping from path
+	id := ps.ByName(GROUP_MAPPING_ID)
+
+	// Call group mapping API to delete group mapping
+	err := h.worker.GroupMappingApi.RemoveGroupMapping(requestInfo, id)
+	if err != nil {
+		h.respondGroupMappingError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+// Private Helper Methods
+
+// Write error of a group mapping operation
+func (h *WorkerHandler) respondGroupMappingError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.GROUP_MAPPING_BY_ID_NOT_FOUND, api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.GROUP_MAPPING_ALREADY_EXIST:
+		h.RespondConflict(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,562 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddGroupMapping(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		request *CreateGroupMappingRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.GroupMapping
+		expectedError      api.Error
+		// Manager Results
+		addGroupMappingResult *api.GroupMapping
+		// Manager Errors
+		addGroupMappingErr error
+	}{
+		"OkCase": {
+			request: &CreateGroupMappingRequest{
+				Issuer: "https://issuer1",
+				Claim:  "groups",
+				Value:  "developers",
+				Org:    "org1",
+				Group:  "group1",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.GroupMapping{
+				ID:       "MAPPING-ID",
+				Issuer:   "https://issuer1",
+				Claim:    "groups",
+				Value:    "developers",
+				Org:      "org1",
+				Group:    "group1",
+				Urn:      api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				CreateAt: now,
+			},
+			addGroupMappingResult: &api.GroupMapping{
+				ID:       "MAPPING-ID",
+				Issuer:   "https://issuer1",
+				Claim:    "groups",
+				Value:    "developers",
+				Org:      "org1",
+				Group:    "group1",
+				Urn:      api.CreateUrn("org1", api.RESOURCE_GROUP_MAPPING, "/", "MAPPING-ID"),
+				CreateAt: now,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			request: &CreateGroupMappingRequest{
+				Issuer: "https://issuer1",
+				Claim:  "groups",
+				Value:  "developers",
+				Org:    "org1",
+				Group:  "group1",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			addGroupMappingErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseGroupMappingAlreadyExist": {
+			request: &CreateGroupMappingRequest{
+				Issuer: "https://issuer1",
+				Claim:  "groups",
+				Value:  "developers",
+				Org:    "org1",
+				Group:  "group1",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.GROUP_MAPPING_ALREADY_EXIST,
+				Message: "Group mapping already exist",
+			},
+			addGroupMappingErr: &api.Error{
+				Code:    api.GROUP_MAPPING_ALREADY_EXIST,
+				Message: "Group mapping already exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateGroupMappingRequest{
+				Value: "developers",
+				Org:   "org1",
+				Group: "group1",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addGroupMappingErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &CreateGroupMappingRequest{
+				Issuer: "https://issuer1",
+				Claim:  "groups",
+				Value:  "developers",
+				Org:    "org1",
+				Group:  "group1",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addGroupMappingErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateGroupMappingRequest{
+				Issuer: "https://issuer1",
+				Claim:  "groups",
+				Value:  "developers",
+				Org:    "org1",
+				Group:  "group1",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addGroupMappingErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddGroupMappingMethod][0] = test.addGroupMappingResult
+		testApi.ArgsOut[AddGroupMappingMethod][1] = test.addGroupMappingErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+GROUP_MAPPING_ROOT_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[AddGroupMappingMethod][1] != test.request.Issuer || testApi.ArgsIn[AddGroupMappingMethod][2] != test.request.Claim ||
+				testApi.ArgsIn[AddGroupMappingMethod][3] != test.request.Value || testApi.ArgsIn[AddGroupMappingMethod][4] != test.request.Org ||
+				testApi.ArgsIn[AddGroupMappingMethod][5] != test.request.Group {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[AddGroupMappingMethod])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			mappingResponse := &api.GroupMapping{}
+			err = json.NewDecoder(res.Body).Decode(mappingResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(mappingResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetGroupMappingByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.GroupMapping
+		expectedError      api.Error
+		// Manager Results
+		getGroupMappingByIDResult *api.GroupMapping
+		// Manager Errors
+		getGroupMappingByIDErr error
+	}{
+		"OkCase": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.GroupMapping{
+				ID:    "MAPPING-ID",
+				Claim: "groups",
+				Value: "developers",
+			},
+			getGroupMappingByIDResult: &api.GroupMapping{
+				ID:    "MAPPING-ID",
+				Claim: "groups",
+				Value: "developers",
+			},
+		},
+		"ErrorCaseGroupMappingNotFound": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping not found",
+			},
+			getGroupMappingByIDErr: &api.Error{
+				Code:    api.GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getGroupMappingByIDErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			getGroupMappingByIDErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetGroupMappingByIDMethod][0] = test.getGroupMappingByIDResult
+		testApi.ArgsOut[GetGroupMappingByIDMethod][1] = test.getGroupMappingByIDErr
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(server.URL+GROUP_MAPPING_ROOT_URL+"/%v", test.id), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetGroupMappingByIDMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[GetGroupMappingByIDMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			mappingResponse := &api.GroupMapping{}
+			err = json.NewDecoder(res.Body).Decode(mappingResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(mappingResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListGroupMappings(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListGroupMappingsResponse
+		expectedError      api.Error
+		// Manager Results
+		listGroupMappingsResult []api.GroupMapping
+		// Manager Errors
+		listGroupMappingsErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListGroupMappingsResponse{
+				GroupMappings: []api.GroupMapping{
+					{
+						ID:  "MAPPING-ID",
+						Org: "org1",
+					},
+				},
+			},
+			listGroupMappingsResult: []api.GroupMapping{
+				{
+					ID:  "MAPPING-ID",
+					Org: "org1",
+				},
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "*org",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			listGroupMappingsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listGroupMappingsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListGroupMappingsMethod][0] = test.listGroupMappingsResult
+		testApi.ArgsOut[ListGroupMappingsMethod][1] = test.listGroupMappingsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+GROUP_MAPPING_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		q.Add("Org", test.org)
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListGroupMappingsMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListGroupMappingsMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listGroupMappingsResponse := ListGroupMappingsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listGroupMappingsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listGroupMappingsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveGroupMapping(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeGroupMappingErr error
+	}{
+		"OkCase": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupMappingNotFound": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping not found",
+			},
+			removeGroupMappingErr: &api.Error{
+				Code:    api.GROUP_MAPPING_BY_ID_NOT_FOUND,
+				Message: "Group mapping not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeGroupMappingErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "MAPPING-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeGroupMappingErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveGroupMappingMethod][0] = test.removeGroupMappingErr
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(server.URL+GROUP_MAPPING_ROOT_URL+"/%v", test.id), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveGroupMappingMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[RemoveGroupMappingMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -234,6 +234,7 @@ func TestWorkerHandler_HandleGetGroupByName(t *testing.T) {
 				Urn:      "Urn",
 				Org:      "Org",
 				CreateAt: now,
+				Version:  3,
 			},
 			getGroupByNameResult: &api.Group{
 				ID:       "groupID",
@@ -242,6 +243,7 @@ func TestWorkerHandler_HandleGetGroupByName(t *testing.T) {
 				Urn:      "Urn",
 				Org:      "Org",
 				CreateAt: now,
+				Version:  3,
 			},
 		},
 		"ErrorCaseGroupNotFound": {
@@ -328,6 +330,11 @@ func TestWorkerHandler_HandleGetGroupByName(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.Group{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -631,6 +638,8 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 	now := time.Now()
 	testcases := map[string]struct {
 		// API method args
+		ifMatch string
+		version int64
 		org     string
 		request *UpdateGroupRequest
 		// Expected result
@@ -655,6 +664,33 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				Version:  4,
+			},
+			updateGroupResult: &api.Group{
+				ID:       "GroupID",
+				Name:     "group1",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				Version:  4,
+			},
+		},
+		"OkCaseIfMatch": {
+			ifMatch: "\"3\"",
+			version: 3,
+			org:     "org1",
+			request: &UpdateGroupRequest{
+				Name: "newName",
+				Path: "NewPath",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Group{
+				ID:       "GroupID",
+				Name:     "group1",
+				Path:     "Path",
+				Urn:      "urn",
+				CreateAt: now,
+				Version:  4,
 			},
 			updateGroupResult: &api.Group{
 				ID:       "GroupID",
@@ -662,6 +698,7 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				Path:     "Path",
 				Urn:      "urn",
 				CreateAt: now,
+				Version:  4,
 			},
 		},
 		"ErrorCaseMalformedRequest": {
@@ -742,6 +779,37 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				Message: "Error",
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			org: "org1",
+			request: &UpdateGroupRequest{
+				Name: "newName",
+				Path: "NewPath",
+			},
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			org: "org1",
+			request: &UpdateGroupRequest{
+				Name: "newName",
+				Path: "NewPath",
+			},
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Group with organization org1 and name group1 is in version 3, not in expected version 2",
+			},
+			updateGroupErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Group with organization org1 and name group1 is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -770,6 +838,7 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -777,7 +846,7 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 			continue
 		}
 
-		if test.request != nil {
+		if test.request != nil && test.expectedStatusCode != http.StatusPreconditionFailed {
 			// Check received parameters
 			if testApi.ArgsIn[UpdateGroupMethod][1] != test.org {
 				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[UpdateGroupMethod][1])
@@ -796,6 +865,10 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				continue
 			}
 
+			if testApi.ArgsIn[UpdateGroupMethod][5] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[UpdateGroupMethod][5])
+				continue
+			}
 		}
 
 		// check status code
@@ -806,6 +879,11 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.Group{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -838,8 +916,10 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org  string
-		name string
+		ifMatch string
+		version int64
+		org     string
+		name    string
 		// Expected result
 		expectedStatusCode int
 		expectedError      api.Error
@@ -851,6 +931,19 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			name:               "group1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseIfMatch": {
+			ifMatch:            "\"3\"",
+			version:            3,
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"OkCaseIfMatchAny": {
+			ifMatch:            "*",
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusNoContent,
+		},
 		"ErrorCaseGroupNotFound": {
 			org:                "org1",
 			name:               "group1",
@@ -899,6 +992,41 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 				Message: "Error",
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			org:                "org1",
+			name:               "group1",
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseWeakIfMatch": {
+			org:                "org1",
+			name:               "group1",
+			ifMatch:            "W/\"3\"",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: W/\"3\"",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			org:                "org1",
+			name:               "group1",
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Group with organization org1 and name group1 is in version 3, not in expected version 2",
+			},
+			removeGroupErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Group with organization org1 and name group1 is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -913,6 +1041,7 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -920,14 +1049,20 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemoveGroupMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[RemoveGroupMethod][2] != test.name {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveGroupMethod][2])
-			continue
+		// Check received parameters when API is called
+		if test.expectedStatusCode != http.StatusPreconditionFailed {
+			if testApi.ArgsIn[RemoveGroupMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemoveGroupMethod][2] != test.name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveGroupMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[RemoveGroupMethod][3] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[RemoveGroupMethod][3])
+				continue
+			}
 		}
 
 		// check status code
@@ -959,11 +1094,13 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 }
 
 func TestWorkerHandler_HandleAddMember(t *testing.T) {
+	expireAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
 		// API method args
 		org       string
 		userID    string
 		groupName string
+		request   interface{}
 		// Expected result
 		expectedStatusCode int
 		expectedError      api.Error
@@ -976,6 +1113,26 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 			groupName:          "group1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseExpireAt": {
+			org:       "org1",
+			userID:    "user1",
+			groupName: "group1",
+			request: &AddMemberRequest{
+				ExpireAt: &expireAt,
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			request:            "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "json: cannot unmarshal string into Go value of type http.AddMemberRequest",
+			},
+		},
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
 			userID:             "user1",
@@ -1065,7 +1222,16 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 		testApi.ArgsOut[AddMemberMethod][0] = test.addMemberErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users/%v", test.org, test.groupName, test.userID)
-		req, err := http.NewRequest(http.MethodPost, url, nil)
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1077,18 +1243,28 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[AddMemberMethod][1] != test.userID {
-			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[AddMemberMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[AddMemberMethod][2] != test.groupName {
-			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddMemberMethod][2])
-			continue
-		}
-		if testApi.ArgsIn[AddMemberMethod][3] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddMemberMethod][3])
-			continue
+		if _, malformed := test.request.(string); !malformed {
+			// Check received parameters
+			if testApi.ArgsIn[AddMemberMethod][1] != test.userID {
+				t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[AddMemberMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddMemberMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddMemberMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AddMemberMethod][3] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddMemberMethod][3])
+				continue
+			}
+			var wantExpireAt *time.Time
+			if request, ok := test.request.(*AddMemberRequest); ok {
+				wantExpireAt = request.ExpireAt
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AddMemberMethod][4], wantExpireAt); diff != "" {
+				t.Errorf("Test %v failed. Received different expiration (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -1280,71 +1456,122 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleListMembers(t *testing.T) {
+func TestWorkerHandler_HandleAddMembers(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org  string
-		name string
+		org       string
+		groupName string
+		request   *GroupMembersRequest
 		// Expected result
 		expectedStatusCode int
-		expectedResponse   ListMembersResponse
+		expectedResponse   BulkOperationResponse
 		expectedError      api.Error
 		// Manager Results
-		getListMembersResult []string
+		addMembersResult []api.BulkItemResult
 		// Manager Errors
-		getListMembersErr error
+		addMembersErr error
 	}{
 		"OkCase": {
-			org:                "org1",
-			name:               "group1",
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1", "user2"},
+			},
 			expectedStatusCode: http.StatusOK,
-			expectedResponse: ListMembersResponse{
-				Members: []string{"member1", "member2"},
+			expectedResponse: BulkOperationResponse{
+				Results: []api.BulkItemResult{
+					{
+						Item: "user1",
+						Done: true,
+					},
+					{
+						Item: "user2",
+						Error: &api.Error{
+							Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+							Message: "Item error",
+						},
+					},
+				},
+			},
+			addMembersResult: []api.BulkItemResult{
+				{
+					Item: "user1",
+					Done: true,
+				},
+				{
+					Item: "user2",
+					Error: &api.Error{
+						Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+						Message: "Item error",
+					},
+				},
 			},
-			getListMembersResult: []string{"member1", "member2"},
 		},
-		"ErrorCaseGroupNotFoundErr": {
+		"ErrorCaseMalformedRequest": {
 			org:                "org1",
-			name:               "group1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
-			getListMembersErr: &api.Error{
+			addMembersErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
 		},
 		"ErrorCaseInvalidParameterErr": {
-			org:                "org1",
-			name:               "group1",
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{},
+			},
 			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
-			getListMembersErr: &api.Error{
+			addMembersErr: &api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
 		},
-		"ErrorCaseUnauthorizedError": {
-			org:                "org1",
-			name:               "group1",
+		"ErrorCaseUnauthorizedResourcesErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
 			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			getListMembersErr: &api.Error{
+			addMembersErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
 		},
-		"ErrorCaseUnknownApiError": {
+		"ErrorCaseUnknownApiErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
 			expectedStatusCode: http.StatusInternalServerError,
-			getListMembersErr: &api.Error{
+			addMembersErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1355,11 +1582,24 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[ListMembersMethod][0] = test.getListMembersResult
-		testApi.ArgsOut[ListMembersMethod][1] = test.getListMembersErr
+		testApi.ArgsOut[AddMembersMethod][0] = test.addMembersResult
+		testApi.ArgsOut[AddMembersMethod][1] = test.addMembersErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.name)
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.groupName)
+		req, err := http.NewRequest(http.MethodPost, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1371,14 +1611,20 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListMembersMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListMembersMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListMembersMethod][2] != test.name {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListMembersMethod][2])
-			continue
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[AddMembersMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddMembersMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddMembersMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddMembersMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AddMembersMethod][3], test.request.Members); diff != "" {
+				t.Errorf("Test %v failed. Received different members (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -1389,14 +1635,14 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
-			getGroupMembersResponse := ListMembersResponse{}
-			err = json.NewDecoder(res.Body).Decode(&getGroupMembersResponse)
+			response := BulkOperationResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
 				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
 				continue
 			}
 			// Check result
-			if diff := pretty.Compare(getGroupMembersResponse, test.expectedResponse); diff != "" {
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
@@ -1418,100 +1664,855 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
+func TestWorkerHandler_HandleRemoveMembers(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org        string
-		groupName  string
-		policyName string
+		org       string
+		groupName string
+		request   *GroupMembersRequest
 		// Expected result
 		expectedStatusCode int
+		expectedResponse   BulkOperationResponse
 		expectedError      api.Error
+		// Manager Results
+		removeMembersResult []api.BulkItemResult
 		// Manager Errors
-		attachGroupPolicyErr error
+		removeMembersErr error
 	}{
 		"OkCase": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1", "user2"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: BulkOperationResponse{
+				Results: []api.BulkItemResult{
+					{
+						Item: "user1",
+						Done: true,
+					},
+					{
+						Item: "user2",
+						Error: &api.Error{
+							Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
+							Message: "Item error",
+						},
+					},
+				},
+			},
+			removeMembersResult: []api.BulkItemResult{
+				{
+					Item: "user1",
+					Done: true,
+				},
+				{
+					Item: "user2",
+					Error: &api.Error{
+						Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
+						Message: "Item error",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
 			org:                "org1",
 			groupName:          "group1",
-			policyName:         "policy1",
-			expectedStatusCode: http.StatusNoContent,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
 		},
 		"ErrorCaseGroupNotFoundErr": {
-			org:                "org1",
-			groupName:          "Invalid Group",
-			policyName:         "policy1",
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
-			attachGroupPolicyErr: &api.Error{
+			removeMembersErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
 		},
-		"ErrorCasePolicyNotFoundErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "Invalid Policy",
-			expectedStatusCode: http.StatusNotFound,
+		"ErrorCaseInvalidParameterErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{},
+			},
+			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
-				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
-				Message: "User Not Found",
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
 			},
-			attachGroupPolicyErr: &api.Error{
-				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			removeMembersErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeMembersErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupMembersRequest{
+				Members: []string{"user1"},
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			removeMembersErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveMembersMethod][0] = test.removeMembersResult
+		testApi.ArgsOut[RemoveMembersMethod][1] = test.removeMembersErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.groupName)
+		req, err := http.NewRequest(http.MethodDelete, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[RemoveMembersMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveMembersMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemoveMembersMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[RemoveMembersMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[RemoveMembersMethod][3], test.request.Members); diff != "" {
+				t.Errorf("Test %v failed. Received different members (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := BulkOperationResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListMembers(t *testing.T) {
+	expireAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		org  string
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListMembersResponse
+		expectedError      api.Error
+		// Manager Results
+		getListMembersResult []api.GroupMemberIdentity
+		// Manager Errors
+		getListMembersErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListMembersResponse{
+				Members: []api.GroupMemberIdentity{
+					{
+						User: "member1",
+					},
+					{
+						User:     "member2",
+						ExpireAt: &expireAt,
+					},
+				},
+			},
+			getListMembersResult: []api.GroupMemberIdentity{
+				{
+					User: "member1",
+				},
+				{
+					User:     "member2",
+					ExpireAt: &expireAt,
+				},
+			},
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			getListMembersErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListMembersMethod][0] = test.getListMembersResult
+		testApi.ArgsOut[ListMembersMethod][1] = test.getListMembersErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.name)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameter
+		if testApi.ArgsIn[ListMembersMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListMembersMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[ListMembersMethod][2] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListMembersMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			getGroupMembersResponse := ListMembersResponse{}
+			err = json.NewDecoder(res.Body).Decode(&getGroupMembersResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(getGroupMembersResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
+	notBefore := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		groupName  string
+		policyName string
+		request    interface{}
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		attachGroupPolicyErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"OkCaseWindow": {
+			org:        "org1",
+			groupName:  "group1",
+			policyName: "policy1",
+			request: &AttachPolicyRequest{
+				NotBefore: &notBefore,
+				NotAfter:  &notAfter,
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			request:            "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "json: cannot unmarshal string into Go value of type http.AttachPolicyRequest",
+			},
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			groupName:          "Invalid Group",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCasePolicyNotFoundErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCasePolicyIsAlreadyAttachedErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+				Message: "Policy is already attached to group",
+			},
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+				Message: "Policy is already attached to group",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusInternalServerError,
+			attachGroupPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AttachPolicyToGroupMethod][0] = test.attachGroupPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies/%v", test.org, test.groupName, test.policyName)
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if _, malformed := test.request.(string); !malformed {
+			// Check received parameters
+			if testApi.ArgsIn[AttachPolicyToGroupMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToGroupMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AttachPolicyToGroupMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AttachPolicyToGroupMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AttachPolicyToGroupMethod][3] != test.policyName {
+				t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToGroupMethod][3])
+				continue
+			}
+			var wantNotBefore, wantNotAfter *time.Time
+			if request, ok := test.request.(*AttachPolicyRequest); ok {
+				wantNotBefore = request.NotBefore
+				wantNotAfter = request.NotAfter
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AttachPolicyToGroupMethod][4], wantNotBefore); diff != "" {
+				t.Errorf("Test %v failed. Received different notBefore (received/wanted) %v", n, diff)
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AttachPolicyToGroupMethod][5], wantNotAfter); diff != "" {
+				t.Errorf("Test %v failed. Received different notAfter (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		groupName  string
+		policyName string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		detachGroupPolicyErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			groupName:          "Invalid Group",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCasePolicyNotFoundErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "User Not Found",
 			},
 		},
-		"ErrorCaseUnauthorizedError": {
+		"ErrorCasePolicyIsNotAttachedErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Policy is not attached to group",
+			},
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Policy is not attached to group",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusInternalServerError,
+			detachGroupPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[DetachPolicyToGroupMethod][0] = test.detachGroupPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies/%v", test.org, test.groupName, test.policyName)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[DetachPolicyToGroupMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToGroupMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[DetachPolicyToGroupMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AttachPolicyToGroupMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[DetachPolicyToGroupMethod][3] != test.policyName {
+			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToGroupMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleAttachPoliciesToGroup(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		groupName string
+		request   *GroupPoliciesRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   BulkOperationResponse
+		expectedError      api.Error
+		// Manager Results
+		attachPoliciesToGroupResult []api.BulkItemResult
+		// Manager Errors
+		attachPoliciesToGroupErr error
+	}{
+		"OkCase": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1", "policy2"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: BulkOperationResponse{
+				Results: []api.BulkItemResult{
+					{
+						Item: "policy1",
+						Done: true,
+					},
+					{
+						Item: "policy2",
+						Error: &api.Error{
+							Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+							Message: "Item error",
+						},
+					},
+				},
+			},
+			attachPoliciesToGroupResult: []api.BulkItemResult{
+				{
+					Item: "policy1",
+					Done: true,
+				},
+				{
+					Item: "policy2",
+					Error: &api.Error{
+						Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+						Message: "Item error",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
 			org:                "org1",
 			groupName:          "group1",
-			policyName:         "policy1",
-			expectedStatusCode: http.StatusForbidden,
+			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
-				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "Unauthorized",
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
 			},
-			attachGroupPolicyErr: &api.Error{
-				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "Unauthorized",
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			attachPoliciesToGroupErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
 			},
 		},
 		"ErrorCaseInvalidParameterErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{},
+			},
 			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
-			attachGroupPolicyErr: &api.Error{
+			attachPoliciesToGroupErr: &api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
 		},
-		"ErrorCasePolicyIsAlreadyAttachedErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
-			expectedStatusCode: http.StatusConflict,
+		"ErrorCaseUnauthorizedResourcesErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
+			},
+			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
-				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
-				Message: "Policy is already attached to group",
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
 			},
-			attachGroupPolicyErr: &api.Error{
-				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
-				Message: "Policy is already attached to group",
+			attachPoliciesToGroupErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
 			},
 		},
-		"ErrorCaseUnknownApiError": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
+		"ErrorCaseUnknownApiErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
+			},
 			expectedStatusCode: http.StatusInternalServerError,
-			attachGroupPolicyErr: &api.Error{
+			attachPoliciesToGroupErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1522,10 +2523,24 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[AttachPolicyToGroupMethod][0] = test.attachGroupPolicyErr
+		testApi.ArgsOut[AttachPoliciesToGroupMethod][0] = test.attachPoliciesToGroupResult
+		testApi.ArgsOut[AttachPoliciesToGroupMethod][1] = test.attachPoliciesToGroupErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies/%v", test.org, test.groupName, test.policyName)
-		req, err := http.NewRequest(http.MethodPost, url, nil)
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies", test.org, test.groupName)
+		req, err := http.NewRequest(http.MethodPost, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1537,18 +2552,20 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[AttachPolicyToGroupMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToGroupMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[AttachPolicyToGroupMethod][2] != test.groupName {
-			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AttachPolicyToGroupMethod][2])
-			continue
-		}
-		if testApi.ArgsIn[AttachPolicyToGroupMethod][3] != test.policyName {
-			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToGroupMethod][3])
-			continue
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[AttachPoliciesToGroupMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPoliciesToGroupMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AttachPoliciesToGroupMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AttachPoliciesToGroupMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AttachPoliciesToGroupMethod][3], test.request.Policies); diff != "" {
+				t.Errorf("Test %v failed. Received different policies (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -1558,9 +2575,18 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 		}
 
 		switch res.StatusCode {
-		case http.StatusNoContent:
-			// No message expected
-			continue
+		case http.StatusOK:
+			response := BulkOperationResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -1579,100 +2605,122 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
+func TestWorkerHandler_HandleDetachPoliciesToGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org        string
-		groupName  string
-		policyName string
+		org       string
+		groupName string
+		request   *GroupPoliciesRequest
 		// Expected result
 		expectedStatusCode int
+		expectedResponse   BulkOperationResponse
 		expectedError      api.Error
+		// Manager Results
+		detachPoliciesToGroupResult []api.BulkItemResult
 		// Manager Errors
-		detachGroupPolicyErr error
+		detachPoliciesToGroupErr error
 	}{
 		"OkCase": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1", "policy2"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: BulkOperationResponse{
+				Results: []api.BulkItemResult{
+					{
+						Item: "policy1",
+						Done: true,
+					},
+					{
+						Item: "policy2",
+						Error: &api.Error{
+							Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+							Message: "Item error",
+						},
+					},
+				},
+			},
+			detachPoliciesToGroupResult: []api.BulkItemResult{
+				{
+					Item: "policy1",
+					Done: true,
+				},
+				{
+					Item: "policy2",
+					Error: &api.Error{
+						Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+						Message: "Item error",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
 			org:                "org1",
 			groupName:          "group1",
-			policyName:         "policy1",
-			expectedStatusCode: http.StatusNoContent,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
 		},
 		"ErrorCaseGroupNotFoundErr": {
-			org:                "org1",
-			groupName:          "Invalid Group",
-			policyName:         "policy1",
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
+			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
-			detachGroupPolicyErr: &api.Error{
+			detachPoliciesToGroupErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
 		},
-		"ErrorCasePolicyNotFoundErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "Invalid Policy",
-			expectedStatusCode: http.StatusNotFound,
-			expectedError: api.Error{
-				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
-				Message: "User Not Found",
-			},
-			detachGroupPolicyErr: &api.Error{
-				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
-				Message: "User Not Found",
+		"ErrorCaseInvalidParameterErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{},
 			},
-		},
-		"ErrorCasePolicyIsNotAttachedErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "Invalid Policy",
-			expectedStatusCode: http.StatusNotFound,
+			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
-				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
-				Message: "Policy is not attached to group",
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
 			},
-			detachGroupPolicyErr: &api.Error{
-				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
-				Message: "Policy is not attached to group",
+			detachPoliciesToGroupErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
 			},
 		},
-		"ErrorCaseUnauthorizedError": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
+		"ErrorCaseUnauthorizedResourcesErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
+			},
 			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			detachGroupPolicyErr: &api.Error{
+			detachPoliciesToGroupErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
 		},
-		"ErrorCaseInvalidParameterErr": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
-			expectedStatusCode: http.StatusBadRequest,
-			expectedError: api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid Parameter",
-			},
-			detachGroupPolicyErr: &api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid Parameter",
+		"ErrorCaseUnknownApiErr": {
+			org:       "org1",
+			groupName: "group1",
+			request: &GroupPoliciesRequest{
+				Policies: []string{"policy1"},
 			},
-		},
-		"ErrorCaseUnknownApiError": {
-			org:                "org1",
-			groupName:          "group1",
-			policyName:         "policy1",
 			expectedStatusCode: http.StatusInternalServerError,
-			detachGroupPolicyErr: &api.Error{
+			detachPoliciesToGroupErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1683,10 +2731,24 @@ func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[DetachPolicyToGroupMethod][0] = test.detachGroupPolicyErr
+		testApi.ArgsOut[DetachPoliciesToGroupMethod][0] = test.detachPoliciesToGroupResult
+		testApi.ArgsOut[DetachPoliciesToGroupMethod][1] = test.detachPoliciesToGroupErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies/%v", test.org, test.groupName, test.policyName)
-		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies", test.org, test.groupName)
+		req, err := http.NewRequest(http.MethodDelete, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1698,18 +2760,20 @@ func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[DetachPolicyToGroupMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToGroupMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[DetachPolicyToGroupMethod][2] != test.groupName {
-			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AttachPolicyToGroupMethod][2])
-			continue
-		}
-		if testApi.ArgsIn[DetachPolicyToGroupMethod][3] != test.policyName {
-			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToGroupMethod][3])
-			continue
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[DetachPoliciesToGroupMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[DetachPoliciesToGroupMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[DetachPoliciesToGroupMethod][2] != test.groupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[DetachPoliciesToGroupMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[DetachPoliciesToGroupMethod][3], test.request.Policies); diff != "" {
+				t.Errorf("Test %v failed. Received different policies (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -1719,9 +2783,18 @@ func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
 		}
 
 		switch res.StatusCode {
-		case http.StatusNoContent:
-			// No message expected
-			continue
+		case http.StatusOK:
+			response := BulkOperationResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -1741,6 +2814,8 @@ func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
 }
 
 func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
+	notBefore := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
 		// API method args
 		org  string
@@ -1750,7 +2825,7 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 		expectedResponse   ListAttachedGroupPoliciesResponse
 		expectedError      api.Error
 		// Manager Results
-		getListAttachedGroupPoliciesResult []string
+		getListAttachedGroupPoliciesResult []api.GroupPolicyIdentity
 		// Manager Errors
 		getListAttachedGroupPoliciesErr error
 	}{
@@ -1759,9 +2834,27 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 			name:               "group1",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListAttachedGroupPoliciesResponse{
-				AttachedPolicies: []string{"policy1", "policy2"},
+				AttachedPolicies: []api.GroupPolicyIdentity{
+					{
+						Policy: "policy1",
+					},
+					{
+						Policy:    "policy2",
+						NotBefore: &notBefore,
+						NotAfter:  &notAfter,
+					},
+				},
+			},
+			getListAttachedGroupPoliciesResult: []api.GroupPolicyIdentity{
+				{
+					Policy: "policy1",
+				},
+				{
+					Policy:    "policy2",
+					NotBefore: &notBefore,
+					NotAfter:  &notAfter,
+				},
 			},
-			getListAttachedGroupPoliciesResult: []string{"policy1", "policy2"},
 		},
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
@@ -2,12 +2,16 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/julienschmidt/httprouter"
 	"github.com/satori/go.uuid"
 	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/auth"
 	"github.com/tecsisa/foulkon/foulkon"
 )
 
@@ -18,6 +22,11 @@ const (
 	POLICY_NAME = "policyname"
 	ORG_NAME    = "orgname"
 
+	ACCESS_REQUEST_ID = "accessrequestid"
+	WEBHOOK_ID        = "webhookid"
+	API_KEY_ID        = "apikeyid"
+	GROUP_MAPPING_ID  = "groupmappingid"
+
 	// URI Path param prefix
 	URI_PATH_PREFIX = "/:"
 
@@ -28,10 +37,34 @@ const (
 	// Organization API ROOT
 	ORG_ROOT = "/organizations/:" + ORG_NAME
 
+	// Organization API urls
+	ORGANIZATION_ROOT_URL = API_VERSION_1 + "/organizations"
+	ORGANIZATION_ID_URL   = API_VERSION_1 + ORG_ROOT
+
 	// User API urls
-	USER_ROOT_URL      = API_VERSION_1 + "/users"
-	USER_ID_URL        = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
-	USER_ID_GROUPS_URL = USER_ID_URL + "/groups"
+	USER_ROOT_URL          = API_VERSION_1 + "/users"
+	USER_ID_URL            = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
+	USER_ID_GROUPS_URL     = USER_ID_URL + "/groups"
+	USER_ID_RENAME_URL     = USER_ID_URL + "/rename"
+	USER_ID_MERGE_URL      = USER_ID_URL + "/merge"
+	USER_ID_SUSPEND_URL    = USER_ID_URL + "/suspend"
+	USER_ID_REACTIVATE_URL = USER_ID_URL + "/reactivate"
+
+	// Local password and session URLs
+	USER_ID_PASSWORD_URL       = USER_ID_URL + "/password"
+	USER_ID_PASSWORD_RESET_URL = USER_ID_PASSWORD_URL + "/reset"
+	LOGIN_URL                  = API_VERSION_1 + "/login"
+	LOGOUT_URL                 = API_VERSION_1 + "/logout"
+
+	// Service account and API key URLs
+	SERVICE_ACCOUNT_ROOT_URL = API_VERSION_1 + "/service-accounts"
+	API_KEY_ROOT_URL         = USER_ID_URL + "/api-keys"
+	API_KEY_ID_URL           = API_KEY_ROOT_URL + URI_PATH_PREFIX + API_KEY_ID
+	API_KEY_ROTATE_URL       = API_KEY_ID_URL + "/rotate"
+
+	// Admin API urls
+	ADMIN_ROOT_URL = API_VERSION_1 + "/admins"
+	ADMIN_ID_URL   = ADMIN_ROOT_URL + URI_PATH_PREFIX + USER_ID
 
 	// Group organization API urls
 	GROUP_ORG_ROOT_URL       = API_VERSION_1 + ORG_ROOT + "/groups"
@@ -41,6 +74,12 @@ const (
 	GROUP_ID_POLICIES_URL    = GROUP_ID_URL + "/policies"
 	GROUP_ID_POLICIES_ID_URL = GROUP_ID_POLICIES_URL + URI_PATH_PREFIX + POLICY_NAME
 
+	// Access request API urls
+	ACCESS_REQUEST_ROOT_URL    = GROUP_ID_URL + "/access-requests"
+	ACCESS_REQUEST_ID_URL      = ACCESS_REQUEST_ROOT_URL + URI_PATH_PREFIX + ACCESS_REQUEST_ID
+	ACCESS_REQUEST_APPROVE_URL = ACCESS_REQUEST_ID_URL + "/approve"
+	ACCESS_REQUEST_REJECT_URL  = ACCESS_REQUEST_ID_URL + "/reject"
+
 	// Policy API urls
 	POLICY_ROOT_URL      = API_VERSION_1 + ORG_ROOT + "/policies"
 	POLICY_ID_URL        = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME
@@ -49,8 +88,36 @@ const (
 	// Authorization URLs
 	RESOURCE_URL = API_VERSION_1 + "/resource"
 
+	// Deleted entities API urls
+	USER_DELETED_URL           = API_VERSION_1 + "/deleted/users"
+	USER_DELETED_RESTORE_URL   = USER_DELETED_URL + URI_PATH_PREFIX + USER_ID + "/restore"
+	GROUP_DELETED_URL          = API_VERSION_1 + ORG_ROOT + "/deleted/groups"
+	GROUP_DELETED_RESTORE_URL  = GROUP_DELETED_URL + URI_PATH_PREFIX + GROUP_NAME + "/restore"
+	POLICY_DELETED_URL         = API_VERSION_1 + ORG_ROOT + "/deleted/policies"
+	POLICY_DELETED_RESTORE_URL = POLICY_DELETED_URL + URI_PATH_PREFIX + POLICY_NAME + "/restore"
+
+	// Sync URLs
+	SYNC_URL = API_VERSION_1 + "/sync"
+
+	// Audit URLs
+	AUDIT_URL = API_VERSION_1 + "/audit"
+
+	// Change URLs
+	CHANGES_URL = API_VERSION_1 + "/changes"
+
+	// Webhook URLs
+	WEBHOOK_ROOT_URL          = API_VERSION_1 + "/webhooks"
+	WEBHOOK_ID_URL            = WEBHOOK_ROOT_URL + URI_PATH_PREFIX + WEBHOOK_ID
+	WEBHOOK_ID_DELIVERIES_URL = WEBHOOK_ID_URL + "/deliveries"
+
+	// Group mapping URLs
+	GROUP_MAPPING_ROOT_URL = API_VERSION_1 + "/group-mappings"
+	GROUP_MAPPING_ID_URL   = GROUP_MAPPING_ROOT_URL + URI_PATH_PREFIX + GROUP_MAPPING_ID
+
 	// HTTP Header
 	REQUEST_ID_HEADER = "Request-ID"
+	ETAG_HEADER       = "ETag"
+	IF_MATCH_HEADER   = "If-Match"
 )
 
 // WORKER
@@ -59,7 +126,7 @@ type WorkerHandler struct {
 	worker *foulkon.Worker
 }
 
-func (a *WorkerHandler) TransactionLog(r *http.Request, requestID string, userID string, msg string) {
+func (a *WorkerHandler) TransactionLog(r *http.Request, requestID string, userID string, connector string, msg string) {
 
 	// TODO: X-Forwarded headers?
 	//for header, _ := range r.Header {
@@ -72,6 +139,7 @@ func (a *WorkerHandler) TransactionLog(r *http.Request, requestID string, userID
 		"URI":       r.RequestURI,
 		"address":   r.RemoteAddr,
 		"user":      userID,
+		"connector": connector,
 	}).Info(msg)
 }
 
@@ -82,45 +150,86 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 
 	workerHandler := WorkerHandler{worker: worker}
 
+	// Organization api
+	router.GET(ORGANIZATION_ROOT_URL, workerHandler.HandleListOrganizations)
+	router.POST(ORGANIZATION_ROOT_URL, workerHandler.audited(api.ORGANIZATION_ACTION_CREATE_ORGANIZATION, workerHandler.HandleAddOrganization))
+
+	router.GET(ORGANIZATION_ID_URL, workerHandler.HandleGetOrganizationByName)
+	router.DELETE(ORGANIZATION_ID_URL, workerHandler.audited(api.ORGANIZATION_ACTION_DELETE_ORGANIZATION, workerHandler.HandleRemoveOrganization))
+
 	// User api
 	router.GET(USER_ROOT_URL, workerHandler.HandleListUsers)
-	router.POST(USER_ROOT_URL, workerHandler.HandleAddUser)
+	router.POST(USER_ROOT_URL, workerHandler.audited(api.USER_ACTION_CREATE_USER, workerHandler.HandleAddUser))
 
 	router.GET(USER_ID_URL, workerHandler.HandleGetUserByExternalID)
-	router.PUT(USER_ID_URL, workerHandler.HandleUpdateUser)
-	router.DELETE(USER_ID_URL, workerHandler.HandleRemoveUser)
+	router.PUT(USER_ID_URL, workerHandler.audited(api.USER_ACTION_UPDATE_USER, workerHandler.HandleUpdateUser))
+	router.DELETE(USER_ID_URL, workerHandler.audited(api.USER_ACTION_DELETE_USER, workerHandler.HandleRemoveUser))
 
 	router.GET(USER_ID_GROUPS_URL, workerHandler.HandleListGroupsByUser)
+	router.POST(USER_ID_RENAME_URL, workerHandler.audited(api.USER_ACTION_RENAME_USER, workerHandler.HandleRenameUser))
+	router.POST(USER_ID_MERGE_URL, workerHandler.audited(api.USER_ACTION_MERGE_USERS, workerHandler.HandleMergeUser))
+	router.POST(USER_ID_SUSPEND_URL, workerHandler.audited(api.USER_ACTION_SUSPEND_USER, workerHandler.HandleSuspendUser))
+	router.POST(USER_ID_REACTIVATE_URL, workerHandler.audited(api.USER_ACTION_REACTIVATE_USER, workerHandler.HandleReactivateUser))
+
+	// Service account and API key resources
+	router.POST(SERVICE_ACCOUNT_ROOT_URL, workerHandler.audited(api.USER_ACTION_CREATE_USER, workerHandler.HandleAddServiceAccount))
+
+	router.GET(API_KEY_ROOT_URL, workerHandler.HandleListApiKeys)
+	router.POST(API_KEY_ROOT_URL, workerHandler.audited(api.USER_ACTION_CREATE_API_KEY, workerHandler.HandleAddApiKey))
+	router.DELETE(API_KEY_ID_URL, workerHandler.audited(api.USER_ACTION_REVOKE_API_KEY, workerHandler.HandleRevokeApiKey))
+	router.POST(API_KEY_ROTATE_URL, workerHandler.audited(api.USER_ACTION_ROTATE_API_KEY, workerHandler.HandleRotateApiKey))
+
+	// Admin resources
+	router.GET(ADMIN_ROOT_URL, workerHandler.HandleListAdmins)
+	router.PUT(ADMIN_ID_URL, workerHandler.audited(api.USER_ACTION_ADD_ADMIN, workerHandler.HandleAddAdmin))
+	router.DELETE(ADMIN_ID_URL, workerHandler.audited(api.USER_ACTION_REMOVE_ADMIN, workerHandler.HandleRemoveAdmin))
+
+	// Local password routes, login and logout are authenticated by the credentials they receive
+	router.PUT(USER_ID_PASSWORD_URL, workerHandler.audited(api.USER_ACTION_SET_USER_PASSWORD, workerHandler.HandleSetUserPassword))
+	router.POST(USER_ID_PASSWORD_RESET_URL, workerHandler.audited(api.USER_ACTION_RESET_USER_PASSWORD, workerHandler.HandleResetUserPassword))
+	router.POST(LOGIN_URL, workerHandler.HandleLogin)
+	router.POST(LOGOUT_URL, workerHandler.HandleLogout)
 
 	// Group api
-	router.POST(GROUP_ORG_ROOT_URL, workerHandler.HandleAddGroup)
+	router.POST(GROUP_ORG_ROOT_URL, workerHandler.audited(api.GROUP_ACTION_CREATE_GROUP, workerHandler.HandleAddGroup))
 	router.GET(GROUP_ORG_ROOT_URL, workerHandler.HandleListGroups)
 
-	router.DELETE(GROUP_ID_URL, workerHandler.HandleRemoveGroup)
+	router.DELETE(GROUP_ID_URL, workerHandler.audited(api.GROUP_ACTION_DELETE_GROUP, workerHandler.HandleRemoveGroup))
 	router.GET(GROUP_ID_URL, workerHandler.HandleGetGroupByName)
-	router.PUT(GROUP_ID_URL, workerHandler.HandleUpdateGroup)
+	router.PUT(GROUP_ID_URL, workerHandler.audited(api.GROUP_ACTION_UPDATE_GROUP, workerHandler.HandleUpdateGroup))
 
 	router.GET(GROUP_ID_USERS_URL, workerHandler.HandleListMembers)
 
-	router.POST(GROUP_ID_USERS_ID_URL, workerHandler.HandleAddMember)
-	router.DELETE(GROUP_ID_USERS_ID_URL, workerHandler.HandleRemoveMember)
+	router.POST(GROUP_ID_USERS_URL, workerHandler.audited(api.GROUP_ACTION_ADD_MEMBER, workerHandler.HandleAddMembers))
+	router.DELETE(GROUP_ID_USERS_URL, workerHandler.audited(api.GROUP_ACTION_REMOVE_MEMBER, workerHandler.HandleRemoveMembers))
+	router.POST(GROUP_ID_USERS_ID_URL, workerHandler.audited(api.GROUP_ACTION_ADD_MEMBER, workerHandler.HandleAddMember))
+	router.DELETE(GROUP_ID_USERS_ID_URL, workerHandler.audited(api.GROUP_ACTION_REMOVE_MEMBER, workerHandler.HandleRemoveMember))
 
 	router.GET(GROUP_ID_POLICIES_URL, workerHandler.HandleListAttachedGroupPolicies)
 
-	router.POST(GROUP_ID_POLICIES_ID_URL, workerHandler.HandleAttachPolicyToGroup)
-	router.DELETE(GROUP_ID_POLICIES_ID_URL, workerHandler.HandleDetachPolicyToGroup)
+	router.POST(GROUP_ID_POLICIES_URL, workerHandler.audited(api.GROUP_ACTION_ATTACH_GROUP_POLICY, workerHandler.HandleAttachPoliciesToGroup))
+	router.DELETE(GROUP_ID_POLICIES_URL, workerHandler.audited(api.GROUP_ACTION_DETACH_GROUP_POLICY, workerHandler.HandleDetachPoliciesToGroup))
+	router.POST(GROUP_ID_POLICIES_ID_URL, workerHandler.audited(api.GROUP_ACTION_ATTACH_GROUP_POLICY, workerHandler.HandleAttachPolicyToGroup))
+	router.DELETE(GROUP_ID_POLICIES_ID_URL, workerHandler.audited(api.GROUP_ACTION_DETACH_GROUP_POLICY, workerHandler.HandleDetachPolicyToGroup))
+
+	// Access request api
+	router.GET(ACCESS_REQUEST_ROOT_URL, workerHandler.HandleListAccessRequests)
+	router.POST(ACCESS_REQUEST_ROOT_URL, workerHandler.audited(api.ACCESS_REQUEST_ACTION_CREATE_ACCESS_REQUEST, workerHandler.HandleAddAccessRequest))
+
+	router.POST(ACCESS_REQUEST_APPROVE_URL, workerHandler.audited(api.ACCESS_REQUEST_ACTION_APPROVE_ACCESS_REQUEST, workerHandler.HandleApproveAccessRequest))
+	router.POST(ACCESS_REQUEST_REJECT_URL, workerHandler.audited(api.ACCESS_REQUEST_ACTION_REJECT_ACCESS_REQUEST, workerHandler.HandleRejectAccessRequest))
 
 	// Special endpoint without organization URI for groups
 	router.GET(API_VERSION_1+"/groups", workerHandler.HandleListAllGroups)
 
 	// Policy api
 	router.GET(POLICY_ROOT_URL, workerHandler.HandleListPolicies)
-	router.POST(POLICY_ROOT_URL, workerHandler.HandleAddPolicy)
+	router.POST(POLICY_ROOT_URL, workerHandler.audited(api.POLICY_ACTION_CREATE_POLICY, workerHandler.HandleAddPolicy))
 
-	router.DELETE(POLICY_ID_URL, workerHandler.HandleRemovePolicy)
+	router.DELETE(POLICY_ID_URL, workerHandler.audited(api.POLICY_ACTION_DELETE_POLICY, workerHandler.HandleRemovePolicy))
 
 	router.GET(POLICY_ID_URL, workerHandler.HandleGetPolicyByName)
-	router.PUT(POLICY_ID_URL, workerHandler.HandleUpdatePolicy)
+	router.PUT(POLICY_ID_URL, workerHandler.audited(api.POLICY_ACTION_UPDATE_POLICY, workerHandler.HandleUpdatePolicy))
 
 	router.GET(POLICY_ID_GROUPS_URL, workerHandler.HandleListAttachedGroups)
 
@@ -130,14 +239,58 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 	// Resources authorized endpoint
 	router.POST(RESOURCE_URL, workerHandler.HandleGetAuthorizedExternalResources)
 
+	// Deleted entities api
+	router.GET(USER_DELETED_URL, workerHandler.HandleListDeletedUsers)
+	router.POST(USER_DELETED_RESTORE_URL, workerHandler.audited(api.USER_ACTION_RESTORE_USER, workerHandler.HandleRestoreUser))
+
+	router.GET(GROUP_DELETED_URL, workerHandler.HandleListDeletedGroups)
+	router.POST(GROUP_DELETED_RESTORE_URL, workerHandler.audited(api.GROUP_ACTION_RESTORE_GROUP, workerHandler.HandleRestoreGroup))
+
+	router.GET(POLICY_DELETED_URL, workerHandler.HandleListDeletedPolicies)
+	router.POST(POLICY_DELETED_RESTORE_URL, workerHandler.audited(api.POLICY_ACTION_RESTORE_POLICY, workerHandler.HandleRestorePolicy))
+
+	// Sync api
+	router.POST(SYNC_URL, workerHandler.audited(api.SYNC_ACTION_APPLY_SYNC, workerHandler.HandleSync))
+
+	// Audit api
+	router.GET(AUDIT_URL, workerHandler.HandleListAuditEvents)
+
+	// Change api
+	router.GET(CHANGES_URL, workerHandler.HandleListChanges)
+
+	// Webhook api
+	router.GET(WEBHOOK_ROOT_URL, workerHandler.HandleListWebhooks)
+	router.POST(WEBHOOK_ROOT_URL, workerHandler.audited(api.WEBHOOK_ACTION_CREATE_WEBHOOK, workerHandler.HandleAddWebhook))
+
+	router.GET(WEBHOOK_ID_URL, workerHandler.HandleGetWebhookByID)
+	router.PUT(WEBHOOK_ID_URL, workerHandler.audited(api.WEBHOOK_ACTION_UPDATE_WEBHOOK, workerHandler.HandleUpdateWebhook))
+	router.DELETE(WEBHOOK_ID_URL, workerHandler.audited(api.WEBHOOK_ACTION_DELETE_WEBHOOK, workerHandler.HandleRemoveWebhook))
+
+	router.GET(WEBHOOK_ID_DELIVERIES_URL, workerHandler.HandleListWebhookDeliveries)
+
+	// Group mapping api
+	router.GET(GROUP_MAPPING_ROOT_URL, workerHandler.HandleListGroupMappings)
+	router.POST(GROUP_MAPPING_ROOT_URL, workerHandler.audited(api.GROUP_MAPPING_ACTION_CREATE_GROUP_MAPPING, workerHandler.HandleAddGroupMapping))
+
+	router.GET(GROUP_MAPPING_ID_URL, workerHandler.HandleGetGroupMappingByID)
+	router.DELETE(GROUP_MAPPING_ID_URL, workerHandler.audited(api.GROUP_MAPPING_ACTION_DELETE_GROUP_MAPPING, workerHandler.HandleRemoveGroupMapping))
+
 	// Return handler with request logging
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := uuid.NewV4().String()
 		r.Header.Set(REQUEST_ID_HEADER, requestID)
 		w.Header().Add(REQUEST_ID_HEADER, requestID)
+		if r.URL.Path == LOGIN_URL || r.URL.Path == LOGOUT_URL {
+			// Headers are removed, so they can't be forged by clients
+			r.Header.Del(auth.USER_ID_HEADER)
+			r.Header.Del(auth.CONNECTOR_HEADER)
+			router.ServeHTTP(w, r)
+			workerHandler.TransactionLog(r, requestID, "", "", "")
+			return
+		}
 		worker.Authenticator.Authenticate(router).ServeHTTP(w, r)
-		userID, _ := worker.Authenticator.GetAuthenticatedUser(r)
-		workerHandler.TransactionLog(r, requestID, userID, "")
+		userID, connector := worker.Authenticator.GetAuthenticatedUser(r)
+		workerHandler.TransactionLog(r, requestID, userID, connector, "")
 	})
 }
 
@@ -190,6 +343,14 @@ func (a *WorkerHandler) RespondBadRequest(r *http.Request, requestInfo api.Reque
 	}
 }
 
+func (a *WorkerHandler) RespondUnauthorized(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, apiError *api.Error) {
+	w, err := writeErrorWithStatus(w, apiError, http.StatusUnauthorized)
+	if err != nil {
+		a.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+}
+
 func (a *WorkerHandler) RespondConflict(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, apiError *api.Error) {
 	w, err := writeErrorWithStatus(w, apiError, http.StatusConflict)
 	if err != nil {
@@ -206,6 +367,14 @@ func (a *WorkerHandler) RespondForbidden(r *http.Request, requestInfo api.Reques
 	}
 }
 
+func (a *WorkerHandler) RespondPreconditionFailed(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, apiError *api.Error) {
+	w, err := writeErrorWithStatus(w, apiError, http.StatusPreconditionFailed)
+	if err != nil {
+		a.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+}
+
 // 5xx RESPONSES
 
 func (a *WorkerHandler) RespondInternalServerError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter) {
@@ -223,11 +392,11 @@ func (a *WorkerHandler) RespondInternalServerError(r *http.Request, requestInfo
 // Worker Aux method
 
 func (w *WorkerHandler) GetRequestInfo(r *http.Request) api.RequestInfo {
-	userID, admin := w.worker.Authenticator.GetAuthenticatedUser(r)
+	userID, _ := w.worker.Authenticator.GetAuthenticatedUser(r)
 	return api.RequestInfo{
 		Identifier: userID,
-		Admin:      admin,
 		RequestID:  r.Header.Get(REQUEST_ID_HEADER),
+		Audit:      getAuditEvent(r),
 	}
 }
 
@@ -318,6 +487,36 @@ func ProxyHandlerRouter(proxy *foulkon.Proxy) http.Handler {
 }
 
 // Private Helper Methods
+
+// Write version of a resource as its entity tag
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set(ETAG_HEADER, fmt.Sprintf("\"%v\"", version))
+}
+
+// Retrieve version expected by If-Match header. It returns 0 if header is missing or it matches any version.
+// If-Match uses strong comparison (RFC 7232), so weak entity tags like W/"3" never match and they're rejected.
+func getIfMatchVersion(r *http.Request) (int64, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get(IF_MATCH_HEADER))
+	if len(ifMatch) == 0 || ifMatch == "*" {
+		return 0, nil
+	}
+
+	var version int64
+	var err error
+	if len(ifMatch) > 2 && strings.HasPrefix(ifMatch, "\"") && strings.HasSuffix(ifMatch, "\"") {
+		version, err = strconv.ParseInt(ifMatch[1:len(ifMatch)-1], 10, 64)
+	} else {
+		err = fmt.Errorf("Entity tag %v isn't a strong entity tag", ifMatch)
+	}
+	if err != nil || version < 1 {
+		return 0, &api.Error{
+			Code:    api.VERSION_MISMATCH_ERROR,
+			Message: fmt.Sprintf("Invalid %v header: %v", IF_MATCH_HEADER, ifMatch),
+		}
+	}
+	return version, nil
+}
+
 func writeErrorWithStatus(w http.ResponseWriter, apiError *api.Error, statusCode int) (http.ResponseWriter, error) {
 	b, err := json.Marshal(apiError)
 	if err != nil {
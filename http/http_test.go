@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"bytes"
 	log "github.com/Sirupsen/logrus"
@@ -21,6 +22,14 @@ const (
 	UpdateUserMethod          = "UpdateUser"
 	RemoveUserMethod          = "RemoveUser"
 	ListGroupsByUserMethod    = "ListGroupsByUser"
+	ListDeletedUsersMethod    = "ListDeletedUsers"
+	RestoreUserMethod         = "RestoreUser"
+	RenameUserMethod          = "RenameUser"
+	MergeUsersMethod          = "MergeUsers"
+	AddServiceAccountMethod   = "AddServiceAccount"
+	ProvisionUserMethod       = "ProvisionUser"
+	SuspendUserMethod         = "SuspendUser"
+	ReactivateUserMethod      = "ReactivateUser"
 
 	// GROUP API METHODS
 	AddGroupMethod                  = "AddGroup"
@@ -30,24 +39,87 @@ const (
 	RemoveGroupMethod               = "RemoveGroup"
 	AddMemberMethod                 = "AddMember"
 	RemoveMemberMethod              = "RemoveMember"
+	AddMembersMethod                = "AddMembers"
+	RemoveMembersMethod             = "RemoveMembers"
 	ListMembersMethod               = "ListMembers"
 	AttachPolicyToGroupMethod       = "AttachPolicyToGroup"
 	DetachPolicyToGroupMethod       = "DetachPolicyToGroup"
+	AttachPoliciesToGroupMethod     = "AttachPoliciesToGroup"
+	DetachPoliciesToGroupMethod     = "DetachPoliciesToGroup"
 	ListAttachedGroupPoliciesMethod = "ListAttachedGroupPolicies"
+	ListDeletedGroupsMethod         = "ListDeletedGroups"
+	RestoreGroupMethod              = "RestoreGroup"
 
 	// POLICY API METHODS
-	AddPolicyMethod          = "AddPolicy"
-	GetPolicyByNameMethod    = "GetPolicyByName"
-	ListPoliciesMethod       = "ListPolicies"
-	UpdatePolicyMethod       = "UpdatePolicy"
-	RemovePolicyMethod       = "RemovePolicy"
-	ListAttachedGroupsMethod = "ListAttachedGroups"
+	AddPolicyMethod           = "AddPolicy"
+	GetPolicyByNameMethod     = "GetPolicyByName"
+	ListPoliciesMethod        = "ListPolicies"
+	UpdatePolicyMethod        = "UpdatePolicy"
+	RemovePolicyMethod        = "RemovePolicy"
+	ListAttachedGroupsMethod  = "ListAttachedGroups"
+	ListDeletedPoliciesMethod = "ListDeletedPolicies"
+	RestorePolicyMethod       = "RestorePolicy"
+
+	// ORGANIZATION API METHODS
+	AddOrganizationMethod       = "AddOrganization"
+	GetOrganizationByNameMethod = "GetOrganizationByName"
+	ListOrganizationsMethod     = "ListOrganizations"
+	RemoveOrganizationMethod    = "RemoveOrganization"
 
 	// AUTHZ API
 	GetAuthorizedUsersMethod             = "GetAuthorizedUsers"
 	GetAuthorizedGroupsMethod            = "GetAuthorizedGroups"
 	GetAuthorizedPoliciesMethod          = "GetAuthorizedPolicies"
 	GetAuthorizedExternalResourcesMethod = "GetAuthorizedExternalResources"
+
+	// SYNC API
+	PlanSyncMethod  = "PlanSync"
+	ApplySyncMethod = "ApplySync"
+
+	// ACCESS REQUEST API
+	AddAccessRequestMethod     = "AddAccessRequest"
+	ListAccessRequestsMethod   = "ListAccessRequests"
+	ApproveAccessRequestMethod = "ApproveAccessRequest"
+	RejectAccessRequestMethod  = "RejectAccessRequest"
+
+	// AUDIT API
+	AddAuditEventMethod   = "AddAuditEvent"
+	ListAuditEventsMethod = "ListAuditEvents"
+
+	// WEBHOOK API
+	AddWebhookMethod            = "AddWebhook"
+	GetWebhookByIDMethod        = "GetWebhookByID"
+	ListWebhooksMethod          = "ListWebhooks"
+	UpdateWebhookMethod         = "UpdateWebhook"
+	RemoveWebhookMethod         = "RemoveWebhook"
+	ListWebhookDeliveriesMethod = "ListWebhookDeliveries"
+
+	// CHANGE API
+	ListChangesMethod = "ListChanges"
+
+	// API KEY API
+	AddApiKeyMethod    = "AddApiKey"
+	ListApiKeysMethod  = "ListApiKeys"
+	RotateApiKeyMethod = "RotateApiKey"
+	RevokeApiKeyMethod = "RevokeApiKey"
+
+	// ADMIN API
+	AddAdminMethod    = "AddAdmin"
+	ListAdminsMethod  = "ListAdmins"
+	RemoveAdminMethod = "RemoveAdmin"
+
+	// PASSWORD API
+	SetUserPasswordMethod   = "SetUserPassword"
+	ResetUserPasswordMethod = "ResetUserPassword"
+	LoginMethod             = "Login"
+	LogoutMethod            = "Logout"
+
+	// GROUP MAPPING API
+	AddGroupMappingMethod     = "AddGroupMapping"
+	GetGroupMappingByIDMethod = "GetGroupMappingByID"
+	ListGroupMappingsMethod   = "ListGroupMappings"
+	RemoveGroupMappingMethod  = "RemoveGroupMapping"
+	ApplyGroupMappingsMethod  = "ApplyGroupMappings"
 )
 
 // Test server used to test handlers
@@ -103,20 +175,32 @@ func TestMain(m *testing.M) {
 		unauthenticated: false,
 	}
 
-	adminUser := "admin"
-	adminPassword := "admin"
-
 	// Create authenticator
-	authenticator := auth.NewAuthenticator(authConnector, adminUser, adminPassword)
+	authenticator := auth.NewAuthenticator([]auth.ChainedConnector{
+		{
+			Name:      "test",
+			Connector: authConnector,
+		},
+	}, testApi)
 
 	// Return created core
 	worker := &foulkon.Worker{
-		Logger:        logger,
-		Authenticator: authenticator,
-		UserApi:       testApi,
-		GroupApi:      testApi,
-		PolicyApi:     testApi,
-		AuthzApi:      testApi,
+		Logger:           logger,
+		Authenticator:    authenticator,
+		UserApi:          testApi,
+		GroupApi:         testApi,
+		PolicyApi:        testApi,
+		OrganizationApi:  testApi,
+		AuthzApi:         testApi,
+		SyncApi:          testApi,
+		AccessRequestApi: testApi,
+		AuditApi:         testApi,
+		WebhookApi:       testApi,
+		ChangeApi:        testApi,
+		ApiKeyApi:        testApi,
+		AdminApi:         testApi,
+		GroupMappingApi:  testApi,
+		PasswordApi:      testApi,
 	}
 
 	server = httptest.NewServer(WorkerHandlerRouter(worker))
@@ -193,43 +277,112 @@ func makeTestApi() *TestAPI {
 		SpecialFuncs: make(map[string]interface{}),
 	}
 
-	testApi.ArgsIn[AddUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AddUserMethod] = make([]interface{}, 5)
 	testApi.ArgsIn[GetUserByExternalIdMethod] = make([]interface{}, 2)
 	testApi.ArgsIn[ListUsersMethod] = make([]interface{}, 2)
-	testApi.ArgsIn[UpdateUserMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[RemoveUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[UpdateUserMethod] = make([]interface{}, 6)
+	testApi.ArgsIn[RemoveUserMethod] = make([]interface{}, 3)
 	testApi.ArgsIn[ListGroupsByUserMethod] = make([]interface{}, 2)
-
-	testApi.ArgsIn[AddGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListDeletedUsersMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RestoreUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RenameUserMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[MergeUsersMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AddServiceAccountMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[ProvisionUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[SuspendUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ReactivateUserMethod] = make([]interface{}, 2)
+
+	testApi.ArgsIn[AddGroupMethod] = make([]interface{}, 6)
 	testApi.ArgsIn[GetGroupByNameMethod] = make([]interface{}, 3)
 	testApi.ArgsIn[ListGroupsMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[UpdateGroupMethod] = make([]interface{}, 5)
-	testApi.ArgsIn[RemoveGroupMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[AddMemberMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[UpdateGroupMethod] = make([]interface{}, 8)
+	testApi.ArgsIn[RemoveGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AddMemberMethod] = make([]interface{}, 5)
 	testApi.ArgsIn[RemoveMemberMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[ListMembersMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[AttachPolicyToGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AttachPolicyToGroupMethod] = make([]interface{}, 6)
 	testApi.ArgsIn[DetachPolicyToGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AddMembersMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RemoveMembersMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AttachPoliciesToGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[DetachPoliciesToGroupMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[ListAttachedGroupPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListDeletedGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[RestoreGroupMethod] = make([]interface{}, 3)
 
-	testApi.ArgsIn[AddPolicyMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[AddPolicyMethod] = make([]interface{}, 7)
 	testApi.ArgsIn[GetPolicyByNameMethod] = make([]interface{}, 3)
 	testApi.ArgsIn[ListPoliciesMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 6)
-	testApi.ArgsIn[RemovePolicyMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 9)
+	testApi.ArgsIn[RemovePolicyMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[ListAttachedGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListDeletedPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[RestorePolicyMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[AddOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[GetOrganizationByNameMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListOrganizationsMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[RemoveOrganizationMethod] = make([]interface{}, 2)
 
 	testApi.ArgsIn[GetAuthorizedUsersMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedGroupsMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedPoliciesMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 3)
 
+	testApi.ArgsIn[PlanSyncMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ApplySyncMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[AddAccessRequestMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[ListAccessRequestsMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ApproveAccessRequestMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RejectAccessRequestMethod] = make([]interface{}, 4)
+
+	testApi.ArgsIn[AddAuditEventMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[ListAuditEventsMethod] = make([]interface{}, 2)
+
+	testApi.ArgsIn[AddWebhookMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListWebhooksMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[UpdateWebhookMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RemoveWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListWebhookDeliveriesMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[ListChangesMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[AddApiKeyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListApiKeysMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RotateApiKeyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RevokeApiKeyMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[AddAdminMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListAdminsMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[RemoveAdminMethod] = make([]interface{}, 2)
+
+	testApi.ArgsIn[SetUserPasswordMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ResetUserPasswordMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[LoginMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[LogoutMethod] = make([]interface{}, 1)
+
+	testApi.ArgsIn[AddGroupMappingMethod] = make([]interface{}, 6)
+	testApi.ArgsIn[GetGroupMappingByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListGroupMappingsMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RemoveGroupMappingMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ApplyGroupMappingsMethod] = make([]interface{}, 4)
+
 	testApi.ArgsOut[AddUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetUserByExternalIdMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[ListUsersMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[UpdateUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[RemoveUserMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[ListGroupsByUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListDeletedUsersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RestoreUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RenameUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[MergeUsersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AddServiceAccountMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ProvisionUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[SuspendUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ReactivateUserMethod] = make([]interface{}, 2)
 
 	testApi.ArgsOut[AddGroupMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetGroupByNameMethod] = make([]interface{}, 2)
@@ -241,7 +394,13 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsOut[ListMembersMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[AttachPolicyToGroupMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[DetachPolicyToGroupMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[AddMembersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveMembersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AttachPoliciesToGroupMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[DetachPoliciesToGroupMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[ListAttachedGroupPoliciesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListDeletedGroupsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RestoreGroupMethod] = make([]interface{}, 2)
 
 	testApi.ArgsOut[AddPolicyMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetPolicyByNameMethod] = make([]interface{}, 2)
@@ -249,21 +408,71 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[ListAttachedGroupsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListDeletedPoliciesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RestorePolicyMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[AddOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetOrganizationByNameMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListOrganizationsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveOrganizationMethod] = make([]interface{}, 1)
 
 	testApi.ArgsOut[GetAuthorizedUsersMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedGroupsMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedPoliciesMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 2)
 
+	testApi.ArgsOut[PlanSyncMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ApplySyncMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[AddAccessRequestMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAccessRequestsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ApproveAccessRequestMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RejectAccessRequestMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[AddAuditEventMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAuditEventsMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[AddWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListWebhooksMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[UpdateWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveWebhookMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListWebhookDeliveriesMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[ListChangesMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[AddApiKeyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListApiKeysMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RotateApiKeyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RevokeApiKeyMethod] = make([]interface{}, 1)
+
+	testApi.ArgsOut[AddAdminMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAdminsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveAdminMethod] = make([]interface{}, 1)
+
+	testApi.ArgsOut[SetUserPasswordMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ResetUserPasswordMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[LoginMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[LogoutMethod] = make([]interface{}, 1)
+
+	testApi.ArgsOut[AddGroupMappingMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetGroupMappingByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListGroupMappingsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveGroupMappingMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ApplyGroupMappingsMethod] = make([]interface{}, 1)
+
 	return testApi
 }
 
 // USER API
 
-func (t TestAPI) AddUser(authenticatedUser api.RequestInfo, externalID string, path string) (*api.User, error) {
+func (t TestAPI) AddUser(authenticatedUser api.RequestInfo, externalID string, path string, displayName string,
+	description string) (*api.User, error) {
 	t.ArgsIn[AddUserMethod][0] = authenticatedUser
 	t.ArgsIn[AddUserMethod][1] = externalID
 	t.ArgsIn[AddUserMethod][2] = path
+	t.ArgsIn[AddUserMethod][3] = displayName
+	t.ArgsIn[AddUserMethod][4] = description
 	var user *api.User
 	if t.ArgsOut[AddUserMethod][0] != nil {
 		user = t.ArgsOut[AddUserMethod][0].(*api.User)
@@ -303,10 +512,14 @@ func (t TestAPI) ListUsers(authenticatedUser api.RequestInfo, pathPrefix string)
 	return externalIDs, err
 }
 
-func (t TestAPI) UpdateUser(authenticatedUser api.RequestInfo, externalID string, newPath string) (*api.User, error) {
+func (t TestAPI) UpdateUser(authenticatedUser api.RequestInfo, externalID string, newPath string, newDisplayName string,
+	newDescription string, version int64) (*api.User, error) {
 	t.ArgsIn[UpdateUserMethod][0] = authenticatedUser
 	t.ArgsIn[UpdateUserMethod][1] = externalID
 	t.ArgsIn[UpdateUserMethod][2] = newPath
+	t.ArgsIn[UpdateUserMethod][3] = version
+	t.ArgsIn[UpdateUserMethod][4] = newDisplayName
+	t.ArgsIn[UpdateUserMethod][5] = newDescription
 	var user *api.User
 	if t.ArgsOut[UpdateUserMethod][0] != nil {
 		user = t.ArgsOut[UpdateUserMethod][0].(*api.User)
@@ -318,9 +531,10 @@ func (t TestAPI) UpdateUser(authenticatedUser api.RequestInfo, externalID string
 	return user, err
 }
 
-func (t TestAPI) RemoveUser(authenticatedUser api.RequestInfo, id string) error {
+func (t TestAPI) RemoveUser(authenticatedUser api.RequestInfo, id string, version int64) error {
 	t.ArgsIn[RemoveUserMethod][0] = authenticatedUser
 	t.ArgsIn[RemoveUserMethod][1] = id
+	t.ArgsIn[RemoveUserMethod][2] = version
 	var err error
 	if t.ArgsOut[RemoveUserMethod][0] != nil {
 		err = t.ArgsOut[RemoveUserMethod][0].(error)
@@ -342,13 +556,136 @@ func (t TestAPI) ListGroupsByUser(authenticatedUser api.RequestInfo, id string)
 	return groups, err
 }
 
+func (t TestAPI) ListDeletedUsers(authenticatedUser api.RequestInfo, pathPrefix string) ([]string, error) {
+	t.ArgsIn[ListDeletedUsersMethod][0] = authenticatedUser
+	t.ArgsIn[ListDeletedUsersMethod][1] = pathPrefix
+	var externalIDs []string
+	if t.ArgsOut[ListDeletedUsersMethod][0] != nil {
+		externalIDs = t.ArgsOut[ListDeletedUsersMethod][0].([]string)
+	}
+	var err error
+	if t.ArgsOut[ListDeletedUsersMethod][1] != nil {
+		err = t.ArgsOut[ListDeletedUsersMethod][1].(error)
+	}
+	return externalIDs, err
+}
+
+func (t TestAPI) RestoreUser(authenticatedUser api.RequestInfo, id string) (*api.User, error) {
+	t.ArgsIn[RestoreUserMethod][0] = authenticatedUser
+	t.ArgsIn[RestoreUserMethod][1] = id
+	var user *api.User
+	if t.ArgsOut[RestoreUserMethod][0] != nil {
+		user = t.ArgsOut[RestoreUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[RestoreUserMethod][1] != nil {
+		err = t.ArgsOut[RestoreUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) RenameUser(authenticatedUser api.RequestInfo, id string, newId string, version int64) (*api.User, error) {
+	t.ArgsIn[RenameUserMethod][0] = authenticatedUser
+	t.ArgsIn[RenameUserMethod][1] = id
+	t.ArgsIn[RenameUserMethod][2] = newId
+	t.ArgsIn[RenameUserMethod][3] = version
+	var user *api.User
+	if t.ArgsOut[RenameUserMethod][0] != nil {
+		user = t.ArgsOut[RenameUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[RenameUserMethod][1] != nil {
+		err = t.ArgsOut[RenameUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) MergeUsers(authenticatedUser api.RequestInfo, sourceId string, targetId string) (*api.User, error) {
+	t.ArgsIn[MergeUsersMethod][0] = authenticatedUser
+	t.ArgsIn[MergeUsersMethod][1] = sourceId
+	t.ArgsIn[MergeUsersMethod][2] = targetId
+	var user *api.User
+	if t.ArgsOut[MergeUsersMethod][0] != nil {
+		user = t.ArgsOut[MergeUsersMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[MergeUsersMethod][1] != nil {
+		err = t.ArgsOut[MergeUsersMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) AddServiceAccount(authenticatedUser api.RequestInfo, externalID string, path string, displayName string,
+	description string) (*api.User, error) {
+	t.ArgsIn[AddServiceAccountMethod][0] = authenticatedUser
+	t.ArgsIn[AddServiceAccountMethod][1] = externalID
+	t.ArgsIn[AddServiceAccountMethod][2] = path
+	t.ArgsIn[AddServiceAccountMethod][3] = displayName
+	t.ArgsIn[AddServiceAccountMethod][4] = description
+	var user *api.User
+	if t.ArgsOut[AddServiceAccountMethod][0] != nil {
+		user = t.ArgsOut[AddServiceAccountMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[AddServiceAccountMethod][1] != nil {
+		err = t.ArgsOut[AddServiceAccountMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ProvisionUser(requestID string, externalID string, path string) (*api.User, error) {
+	t.ArgsIn[ProvisionUserMethod][0] = requestID
+	t.ArgsIn[ProvisionUserMethod][1] = externalID
+	t.ArgsIn[ProvisionUserMethod][2] = path
+	var user *api.User
+	if t.ArgsOut[ProvisionUserMethod][0] != nil {
+		user = t.ArgsOut[ProvisionUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[ProvisionUserMethod][1] != nil {
+		err = t.ArgsOut[ProvisionUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) SuspendUser(authenticatedUser api.RequestInfo, externalID string) (*api.User, error) {
+	t.ArgsIn[SuspendUserMethod][0] = authenticatedUser
+	t.ArgsIn[SuspendUserMethod][1] = externalID
+	var user *api.User
+	if t.ArgsOut[SuspendUserMethod][0] != nil {
+		user = t.ArgsOut[SuspendUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[SuspendUserMethod][1] != nil {
+		err = t.ArgsOut[SuspendUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ReactivateUser(authenticatedUser api.RequestInfo, externalID string) (*api.User, error) {
+	t.ArgsIn[ReactivateUserMethod][0] = authenticatedUser
+	t.ArgsIn[ReactivateUserMethod][1] = externalID
+	var user *api.User
+	if t.ArgsOut[ReactivateUserMethod][0] != nil {
+		user = t.ArgsOut[ReactivateUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[ReactivateUserMethod][1] != nil {
+		err = t.ArgsOut[ReactivateUserMethod][1].(error)
+	}
+	return user, err
+}
+
 // GROUP API
 
-func (t TestAPI) AddGroup(authenticatedUser api.RequestInfo, org string, name string, path string) (*api.Group, error) {
+func (t TestAPI) AddGroup(authenticatedUser api.RequestInfo, org string, name string, path string, displayName string,
+	description string) (*api.Group, error) {
 	t.ArgsIn[AddGroupMethod][0] = authenticatedUser
 	t.ArgsIn[AddGroupMethod][1] = org
 	t.ArgsIn[AddGroupMethod][2] = name
 	t.ArgsIn[AddGroupMethod][3] = path
+	t.ArgsIn[AddGroupMethod][4] = displayName
+	t.ArgsIn[AddGroupMethod][5] = description
 	var group *api.Group
 	if t.ArgsOut[AddGroupMethod][0] != nil {
 		group = t.ArgsOut[AddGroupMethod][0].(*api.Group)
@@ -390,12 +727,16 @@ func (t TestAPI) ListGroups(authenticatedUser api.RequestInfo, org string, pathP
 	return groups, err
 }
 
-func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, groupName string, newName string, newPath string) (*api.Group, error) {
+func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, groupName string, newName string, newPath string,
+	newDisplayName string, newDescription string, version int64) (*api.Group, error) {
 	t.ArgsIn[UpdateGroupMethod][0] = authenticatedUser
 	t.ArgsIn[UpdateGroupMethod][1] = org
 	t.ArgsIn[UpdateGroupMethod][2] = groupName
 	t.ArgsIn[UpdateGroupMethod][3] = newName
 	t.ArgsIn[UpdateGroupMethod][4] = newPath
+	t.ArgsIn[UpdateGroupMethod][5] = version
+	t.ArgsIn[UpdateGroupMethod][6] = newDisplayName
+	t.ArgsIn[UpdateGroupMethod][7] = newDescription
 	var group *api.Group
 	if t.ArgsOut[UpdateGroupMethod][0] != nil {
 		group = t.ArgsOut[UpdateGroupMethod][0].(*api.Group)
@@ -407,10 +748,11 @@ func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, grou
 	return group, err
 }
 
-func (t TestAPI) RemoveGroup(authenticatedUser api.RequestInfo, org string, name string) error {
+func (t TestAPI) RemoveGroup(authenticatedUser api.RequestInfo, org string, name string, version int64) error {
 	t.ArgsIn[RemoveGroupMethod][0] = authenticatedUser
 	t.ArgsIn[RemoveGroupMethod][1] = org
 	t.ArgsIn[RemoveGroupMethod][2] = name
+	t.ArgsIn[RemoveGroupMethod][3] = version
 	var err error
 	if t.ArgsOut[RemoveGroupMethod][0] != nil {
 		err = t.ArgsOut[RemoveGroupMethod][0].(error)
@@ -418,11 +760,12 @@ func (t TestAPI) RemoveGroup(authenticatedUser api.RequestInfo, org string, name
 	return err
 }
 
-func (t TestAPI) AddMember(authenticatedUser api.RequestInfo, userID string, groupName string, org string) error {
+func (t TestAPI) AddMember(authenticatedUser api.RequestInfo, userID string, groupName string, org string, expireAt *time.Time) error {
 	t.ArgsIn[AddMemberMethod][0] = authenticatedUser
 	t.ArgsIn[AddMemberMethod][1] = userID
 	t.ArgsIn[AddMemberMethod][2] = groupName
 	t.ArgsIn[AddMemberMethod][3] = org
+	t.ArgsIn[AddMemberMethod][4] = expireAt
 	var err error
 	if t.ArgsOut[AddMemberMethod][0] != nil {
 		err = t.ArgsOut[AddMemberMethod][0].(error)
@@ -442,26 +785,29 @@ func (t TestAPI) RemoveMember(authenticatedUser api.RequestInfo, userID string,
 	return err
 }
 
-func (t TestAPI) ListMembers(authenticatedUser api.RequestInfo, org string, groupName string) ([]string, error) {
+func (t TestAPI) ListMembers(authenticatedUser api.RequestInfo, org string, groupName string) ([]api.GroupMemberIdentity, error) {
 	t.ArgsIn[ListMembersMethod][0] = authenticatedUser
 	t.ArgsIn[ListMembersMethod][1] = org
 	t.ArgsIn[ListMembersMethod][2] = groupName
-	var externalIDs []string
+	var members []api.GroupMemberIdentity
 	if t.ArgsOut[ListMembersMethod][0] != nil {
-		externalIDs = t.ArgsOut[ListMembersMethod][0].([]string)
+		members = t.ArgsOut[ListMembersMethod][0].([]api.GroupMemberIdentity)
 	}
 	var err error
 	if t.ArgsOut[ListMembersMethod][1] != nil {
 		err = t.ArgsOut[ListMembersMethod][1].(error)
 	}
-	return externalIDs, err
+	return members, err
 }
 
-func (t TestAPI) AttachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string) error {
+func (t TestAPI) AttachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string,
+	notBefore *time.Time, notAfter *time.Time) error {
 	t.ArgsIn[AttachPolicyToGroupMethod][0] = authenticatedUser
 	t.ArgsIn[AttachPolicyToGroupMethod][1] = org
 	t.ArgsIn[AttachPolicyToGroupMethod][2] = groupName
 	t.ArgsIn[AttachPolicyToGroupMethod][3] = policyName
+	t.ArgsIn[AttachPolicyToGroupMethod][4] = notBefore
+	t.ArgsIn[AttachPolicyToGroupMethod][5] = notAfter
 	var err error
 	if t.ArgsOut[AttachPolicyToGroupMethod][0] != nil {
 		err = t.ArgsOut[AttachPolicyToGroupMethod][0].(error)
@@ -481,13 +827,77 @@ func (t TestAPI) DetachPolicyToGroup(authenticatedUser api.RequestInfo, org stri
 	return err
 }
 
-func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, org string, groupName string) ([]string, error) {
+func (t TestAPI) AddMembers(authenticatedUser api.RequestInfo, org string, groupName string, externalIds []string) ([]api.BulkItemResult, error) {
+	t.ArgsIn[AddMembersMethod][0] = authenticatedUser
+	t.ArgsIn[AddMembersMethod][1] = org
+	t.ArgsIn[AddMembersMethod][2] = groupName
+	t.ArgsIn[AddMembersMethod][3] = externalIds
+	var results []api.BulkItemResult
+	if t.ArgsOut[AddMembersMethod][0] != nil {
+		results = t.ArgsOut[AddMembersMethod][0].([]api.BulkItemResult)
+	}
+	var err error
+	if t.ArgsOut[AddMembersMethod][1] != nil {
+		err = t.ArgsOut[AddMembersMethod][1].(error)
+	}
+	return results, err
+}
+
+func (t TestAPI) RemoveMembers(authenticatedUser api.RequestInfo, org string, groupName string, externalIds []string) ([]api.BulkItemResult, error) {
+	t.ArgsIn[RemoveMembersMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveMembersMethod][1] = org
+	t.ArgsIn[RemoveMembersMethod][2] = groupName
+	t.ArgsIn[RemoveMembersMethod][3] = externalIds
+	var results []api.BulkItemResult
+	if t.ArgsOut[RemoveMembersMethod][0] != nil {
+		results = t.ArgsOut[RemoveMembersMethod][0].([]api.BulkItemResult)
+	}
+	var err error
+	if t.ArgsOut[RemoveMembersMethod][1] != nil {
+		err = t.ArgsOut[RemoveMembersMethod][1].(error)
+	}
+	return results, err
+}
+
+func (t TestAPI) AttachPoliciesToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyNames []string) ([]api.BulkItemResult, error) {
+	t.ArgsIn[AttachPoliciesToGroupMethod][0] = authenticatedUser
+	t.ArgsIn[AttachPoliciesToGroupMethod][1] = org
+	t.ArgsIn[AttachPoliciesToGroupMethod][2] = groupName
+	t.ArgsIn[AttachPoliciesToGroupMethod][3] = policyNames
+	var results []api.BulkItemResult
+	if t.ArgsOut[AttachPoliciesToGroupMethod][0] != nil {
+		results = t.ArgsOut[AttachPoliciesToGroupMethod][0].([]api.BulkItemResult)
+	}
+	var err error
+	if t.ArgsOut[AttachPoliciesToGroupMethod][1] != nil {
+		err = t.ArgsOut[AttachPoliciesToGroupMethod][1].(error)
+	}
+	return results, err
+}
+
+func (t TestAPI) DetachPoliciesToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyNames []string) ([]api.BulkItemResult, error) {
+	t.ArgsIn[DetachPoliciesToGroupMethod][0] = authenticatedUser
+	t.ArgsIn[DetachPoliciesToGroupMethod][1] = org
+	t.ArgsIn[DetachPoliciesToGroupMethod][2] = groupName
+	t.ArgsIn[DetachPoliciesToGroupMethod][3] = policyNames
+	var results []api.BulkItemResult
+	if t.ArgsOut[DetachPoliciesToGroupMethod][0] != nil {
+		results = t.ArgsOut[DetachPoliciesToGroupMethod][0].([]api.BulkItemResult)
+	}
+	var err error
+	if t.ArgsOut[DetachPoliciesToGroupMethod][1] != nil {
+		err = t.ArgsOut[DetachPoliciesToGroupMethod][1].(error)
+	}
+	return results, err
+}
+
+func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, org string, groupName string) ([]api.GroupPolicyIdentity, error) {
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][0] = authenticatedUser
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][1] = org
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][2] = groupName
-	var policies []string
+	var policies []api.GroupPolicyIdentity
 	if t.ArgsOut[ListAttachedGroupPoliciesMethod][0] != nil {
-		policies = t.ArgsOut[ListAttachedGroupPoliciesMethod][0].([]string)
+		policies = t.ArgsOut[ListAttachedGroupPoliciesMethod][0].([]api.GroupPolicyIdentity)
 	}
 	var err error
 	if t.ArgsOut[ListAttachedGroupPoliciesMethod][1] != nil {
@@ -496,14 +906,47 @@ func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, or
 	return policies, err
 }
 
+func (t TestAPI) ListDeletedGroups(authenticatedUser api.RequestInfo, org string, pathPrefix string) ([]api.GroupIdentity, error) {
+	t.ArgsIn[ListDeletedGroupsMethod][0] = authenticatedUser
+	t.ArgsIn[ListDeletedGroupsMethod][1] = org
+	t.ArgsIn[ListDeletedGroupsMethod][2] = pathPrefix
+	var groups []api.GroupIdentity
+	if t.ArgsOut[ListDeletedGroupsMethod][0] != nil {
+		groups = t.ArgsOut[ListDeletedGroupsMethod][0].([]api.GroupIdentity)
+	}
+	var err error
+	if t.ArgsOut[ListDeletedGroupsMethod][1] != nil {
+		err = t.ArgsOut[ListDeletedGroupsMethod][1].(error)
+	}
+	return groups, err
+}
+
+func (t TestAPI) RestoreGroup(authenticatedUser api.RequestInfo, org string, name string) (*api.Group, error) {
+	t.ArgsIn[RestoreGroupMethod][0] = authenticatedUser
+	t.ArgsIn[RestoreGroupMethod][1] = org
+	t.ArgsIn[RestoreGroupMethod][2] = name
+	var group *api.Group
+	if t.ArgsOut[RestoreGroupMethod][0] != nil {
+		group = t.ArgsOut[RestoreGroupMethod][0].(*api.Group)
+	}
+	var err error
+	if t.ArgsOut[RestoreGroupMethod][1] != nil {
+		err = t.ArgsOut[RestoreGroupMethod][1].(error)
+	}
+	return group, err
+}
+
 // POLICY API
 
-func (t TestAPI) AddPolicy(authenticatedUser api.RequestInfo, name string, path string, org string, statements []api.Statement) (*api.Policy, error) {
+func (t TestAPI) AddPolicy(authenticatedUser api.RequestInfo, name string, path string, org string, displayName string,
+	description string, statements []api.Statement) (*api.Policy, error) {
 	t.ArgsIn[AddPolicyMethod][0] = authenticatedUser
 	t.ArgsIn[AddPolicyMethod][1] = name
 	t.ArgsIn[AddPolicyMethod][2] = path
 	t.ArgsIn[AddPolicyMethod][3] = org
 	t.ArgsIn[AddPolicyMethod][4] = statements
+	t.ArgsIn[AddPolicyMethod][5] = displayName
+	t.ArgsIn[AddPolicyMethod][6] = description
 	var policy *api.Policy
 	if t.ArgsOut[AddPolicyMethod][0] != nil {
 		policy = t.ArgsOut[AddPolicyMethod][0].(*api.Policy)
@@ -546,13 +989,16 @@ func (t TestAPI) ListPolicies(authenticatedUser api.RequestInfo, org string, pat
 }
 
 func (t TestAPI) UpdatePolicy(authenticatedUser api.RequestInfo, org string, policyName string, newName string, newPath string,
-	newStatements []api.Statement) (*api.Policy, error) {
+	newDisplayName string, newDescription string, newStatements []api.Statement, version int64) (*api.Policy, error) {
 	t.ArgsIn[UpdatePolicyMethod][0] = authenticatedUser
 	t.ArgsIn[UpdatePolicyMethod][1] = org
 	t.ArgsIn[UpdatePolicyMethod][2] = policyName
 	t.ArgsIn[UpdatePolicyMethod][3] = newName
 	t.ArgsIn[UpdatePolicyMethod][4] = newPath
 	t.ArgsIn[UpdatePolicyMethod][5] = newStatements
+	t.ArgsIn[UpdatePolicyMethod][6] = version
+	t.ArgsIn[UpdatePolicyMethod][7] = newDisplayName
+	t.ArgsIn[UpdatePolicyMethod][8] = newDescription
 
 	var policy *api.Policy
 	if t.ArgsOut[UpdatePolicyMethod][0] != nil {
@@ -565,10 +1011,11 @@ func (t TestAPI) UpdatePolicy(authenticatedUser api.RequestInfo, org string, pol
 	return policy, err
 }
 
-func (t TestAPI) RemovePolicy(authenticatedUser api.RequestInfo, org string, name string) error {
+func (t TestAPI) RemovePolicy(authenticatedUser api.RequestInfo, org string, name string, version int64) error {
 	t.ArgsIn[RemovePolicyMethod][0] = authenticatedUser
 	t.ArgsIn[RemovePolicyMethod][1] = org
 	t.ArgsIn[RemovePolicyMethod][2] = name
+	t.ArgsIn[RemovePolicyMethod][3] = version
 	var err error
 	if t.ArgsOut[RemovePolicyMethod][0] != nil {
 		err = t.ArgsOut[RemovePolicyMethod][0].(error)
@@ -591,6 +1038,89 @@ func (t TestAPI) ListAttachedGroups(authenticatedUser api.RequestInfo, org strin
 	return groups, err
 }
 
+func (t TestAPI) ListDeletedPolicies(authenticatedUser api.RequestInfo, org string, pathPrefix string) ([]api.PolicyIdentity, error) {
+	t.ArgsIn[ListDeletedPoliciesMethod][0] = authenticatedUser
+	t.ArgsIn[ListDeletedPoliciesMethod][1] = org
+	t.ArgsIn[ListDeletedPoliciesMethod][2] = pathPrefix
+	var policies []api.PolicyIdentity
+	if t.ArgsOut[ListDeletedPoliciesMethod][0] != nil {
+		policies = t.ArgsOut[ListDeletedPoliciesMethod][0].([]api.PolicyIdentity)
+	}
+	var err error
+	if t.ArgsOut[ListDeletedPoliciesMethod][1] != nil {
+		err = t.ArgsOut[ListDeletedPoliciesMethod][1].(error)
+	}
+	return policies, err
+}
+
+func (t TestAPI) RestorePolicy(authenticatedUser api.RequestInfo, org string, name string) (*api.Policy, error) {
+	t.ArgsIn[RestorePolicyMethod][0] = authenticatedUser
+	t.ArgsIn[RestorePolicyMethod][1] = org
+	t.ArgsIn[RestorePolicyMethod][2] = name
+	var policy *api.Policy
+	if t.ArgsOut[RestorePolicyMethod][0] != nil {
+		policy = t.ArgsOut[RestorePolicyMethod][0].(*api.Policy)
+	}
+	var err error
+	if t.ArgsOut[RestorePolicyMethod][1] != nil {
+		err = t.ArgsOut[RestorePolicyMethod][1].(error)
+	}
+	return policy, err
+}
+
+// ORGANIZATION API
+
+func (t TestAPI) AddOrganization(authenticatedUser api.RequestInfo, name string) (*api.Organization, error) {
+	t.ArgsIn[AddOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[AddOrganizationMethod][1] = name
+	var org *api.Organization
+	if t.ArgsOut[AddOrganizationMethod][0] != nil {
+		org = t.ArgsOut[AddOrganizationMethod][0].(*api.Organization)
+	}
+	var err error
+	if t.ArgsOut[AddOrganizationMethod][1] != nil {
+		err = t.ArgsOut[AddOrganizationMethod][1].(error)
+	}
+	return org, err
+}
+
+func (t TestAPI) GetOrganizationByName(authenticatedUser api.RequestInfo, name string) (*api.Organization, error) {
+	t.ArgsIn[GetOrganizationByNameMethod][0] = authenticatedUser
+	t.ArgsIn[GetOrganizationByNameMethod][1] = name
+	var org *api.Organization
+	if t.ArgsOut[GetOrganizationByNameMethod][0] != nil {
+		org = t.ArgsOut[GetOrganizationByNameMethod][0].(*api.Organization)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationByNameMethod][1] != nil {
+		err = t.ArgsOut[GetOrganizationByNameMethod][1].(error)
+	}
+	return org, err
+}
+
+func (t TestAPI) ListOrganizations(authenticatedUser api.RequestInfo) ([]string, error) {
+	t.ArgsIn[ListOrganizationsMethod][0] = authenticatedUser
+	var orgs []string
+	if t.ArgsOut[ListOrganizationsMethod][0] != nil {
+		orgs = t.ArgsOut[ListOrganizationsMethod][0].([]string)
+	}
+	var err error
+	if t.ArgsOut[ListOrganizationsMethod][1] != nil {
+		err = t.ArgsOut[ListOrganizationsMethod][1].(error)
+	}
+	return orgs, err
+}
+
+func (t TestAPI) RemoveOrganization(authenticatedUser api.RequestInfo, name string) error {
+	t.ArgsIn[RemoveOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveOrganizationMethod][1] = name
+	var err error
+	if t.ArgsOut[RemoveOrganizationMethod][0] != nil {
+		err = t.ArgsOut[RemoveOrganizationMethod][0].(error)
+	}
+	return err
+}
+
 // AUTHZ API
 
 func (t TestAPI) GetAuthorizedUsers(authenticatedUser api.RequestInfo, resourceUrn string, action string, users []api.User) ([]api.User, error) {
@@ -605,6 +1135,10 @@ func (t TestAPI) GetAuthorizedPolicies(authenticatedUser api.RequestInfo, resour
 	return nil, nil
 }
 
+func (t TestAPI) GetAuthorizedOrganizations(authenticatedUser api.RequestInfo, resourceUrn string, action string, orgs []api.Organization) ([]api.Organization, error) {
+	return nil, nil
+}
+
 func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInfo, action string, resources []string) ([]string, error) {
 	t.ArgsIn[GetAuthorizedExternalResourcesMethod][0] = authenticatedUser
 	t.ArgsIn[GetAuthorizedExternalResourcesMethod][1] = action
@@ -619,3 +1153,494 @@ func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInf
 	}
 	return resourcesToReturn, err
 }
+
+// SYNC API
+
+func (t TestAPI) PlanSync(authenticatedUser api.RequestInfo, state api.DesiredState, keepUnmanaged bool) (*api.SyncPlan, error) {
+	t.ArgsIn[PlanSyncMethod][0] = authenticatedUser
+	t.ArgsIn[PlanSyncMethod][1] = state
+	t.ArgsIn[PlanSyncMethod][2] = keepUnmanaged
+	var plan *api.SyncPlan
+	if t.ArgsOut[PlanSyncMethod][0] != nil {
+		plan = t.ArgsOut[PlanSyncMethod][0].(*api.SyncPlan)
+	}
+	var err error
+	if t.ArgsOut[PlanSyncMethod][1] != nil {
+		err = t.ArgsOut[PlanSyncMethod][1].(error)
+	}
+	return plan, err
+}
+
+func (t TestAPI) ApplySync(authenticatedUser api.RequestInfo, state api.DesiredState, keepUnmanaged bool) (*api.SyncPlan, error) {
+	t.ArgsIn[ApplySyncMethod][0] = authenticatedUser
+	t.ArgsIn[ApplySyncMethod][1] = state
+	t.ArgsIn[ApplySyncMethod][2] = keepUnmanaged
+	var plan *api.SyncPlan
+	if t.ArgsOut[ApplySyncMethod][0] != nil {
+		plan = t.ArgsOut[ApplySyncMethod][0].(*api.SyncPlan)
+	}
+	var err error
+	if t.ArgsOut[ApplySyncMethod][1] != nil {
+		err = t.ArgsOut[ApplySyncMethod][1].(error)
+	}
+	return plan, err
+}
+
+// ACCESS REQUEST API
+
+func (t TestAPI) AddAccessRequest(authenticatedUser api.RequestInfo, org string, groupName string, justification string,
+	duration int64) (*api.AccessRequest, error) {
+	t.ArgsIn[AddAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[AddAccessRequestMethod][1] = org
+	t.ArgsIn[AddAccessRequestMethod][2] = groupName
+	t.ArgsIn[AddAccessRequestMethod][3] = justification
+	t.ArgsIn[AddAccessRequestMethod][4] = duration
+	var request *api.AccessRequest
+	if t.ArgsOut[AddAccessRequestMethod][0] != nil {
+		request = t.ArgsOut[AddAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[AddAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[AddAccessRequestMethod][1].(error)
+	}
+	return request, err
+}
+
+func (t TestAPI) ListAccessRequests(authenticatedUser api.RequestInfo, org string, groupName string, status string) ([]api.AccessRequest, error) {
+	t.ArgsIn[ListAccessRequestsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAccessRequestsMethod][1] = org
+	t.ArgsIn[ListAccessRequestsMethod][2] = groupName
+	t.ArgsIn[ListAccessRequestsMethod][3] = status
+	var requests []api.AccessRequest
+	if t.ArgsOut[ListAccessRequestsMethod][0] != nil {
+		requests = t.ArgsOut[ListAccessRequestsMethod][0].([]api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[ListAccessRequestsMethod][1] != nil {
+		err = t.ArgsOut[ListAccessRequestsMethod][1].(error)
+	}
+	return requests, err
+}
+
+func (t TestAPI) ApproveAccessRequest(authenticatedUser api.RequestInfo, org string, groupName string, id string) (*api.AccessRequest, error) {
+	t.ArgsIn[ApproveAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[ApproveAccessRequestMethod][1] = org
+	t.ArgsIn[ApproveAccessRequestMethod][2] = groupName
+	t.ArgsIn[ApproveAccessRequestMethod][3] = id
+	var request *api.AccessRequest
+	if t.ArgsOut[ApproveAccessRequestMethod][0] != nil {
+		request = t.ArgsOut[ApproveAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[ApproveAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[ApproveAccessRequestMethod][1].(error)
+	}
+	return request, err
+}
+
+func (t TestAPI) RejectAccessRequest(authenticatedUser api.RequestInfo, org string, groupName string, id string) (*api.AccessRequest, error) {
+	t.ArgsIn[RejectAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[RejectAccessRequestMethod][1] = org
+	t.ArgsIn[RejectAccessRequestMethod][2] = groupName
+	t.ArgsIn[RejectAccessRequestMethod][3] = id
+	var request *api.AccessRequest
+	if t.ArgsOut[RejectAccessRequestMethod][0] != nil {
+		request = t.ArgsOut[RejectAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[RejectAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[RejectAccessRequestMethod][1].(error)
+	}
+	return request, err
+}
+
+// AUDIT API
+
+func (t TestAPI) AddAuditEvent(event api.AuditEvent) (*api.AuditEvent, error) {
+	t.ArgsIn[AddAuditEventMethod][0] = event
+	var created *api.AuditEvent
+	if t.ArgsOut[AddAuditEventMethod][0] != nil {
+		created = t.ArgsOut[AddAuditEventMethod][0].(*api.AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[AddAuditEventMethod][1] != nil {
+		err = t.ArgsOut[AddAuditEventMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestAPI) ListAuditEvents(authenticatedUser api.RequestInfo, filter api.AuditFilter) ([]api.AuditEvent, error) {
+	t.ArgsIn[ListAuditEventsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAuditEventsMethod][1] = filter
+	var events []api.AuditEvent
+	if t.ArgsOut[ListAuditEventsMethod][0] != nil {
+		events = t.ArgsOut[ListAuditEventsMethod][0].([]api.AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[ListAuditEventsMethod][1] != nil {
+		err = t.ArgsOut[ListAuditEventsMethod][1].(error)
+	}
+	return events, err
+}
+
+// WEBHOOK API
+
+func (t TestAPI) AddWebhook(authenticatedUser api.RequestInfo, webhookUrl string, eventTypes []string, secret string) (*api.Webhook, error) {
+	t.ArgsIn[AddWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[AddWebhookMethod][1] = webhookUrl
+	t.ArgsIn[AddWebhookMethod][2] = eventTypes
+	t.ArgsIn[AddWebhookMethod][3] = secret
+	var webhook *api.Webhook
+	if t.ArgsOut[AddWebhookMethod][0] != nil {
+		webhook = t.ArgsOut[AddWebhookMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[AddWebhookMethod][1] != nil {
+		err = t.ArgsOut[AddWebhookMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) GetWebhookByID(authenticatedUser api.RequestInfo, id string) (*api.Webhook, error) {
+	t.ArgsIn[GetWebhookByIDMethod][0] = authenticatedUser
+	t.ArgsIn[GetWebhookByIDMethod][1] = id
+	var webhook *api.Webhook
+	if t.ArgsOut[GetWebhookByIDMethod][0] != nil {
+		webhook = t.ArgsOut[GetWebhookByIDMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookByIDMethod][1] != nil {
+		err = t.ArgsOut[GetWebhookByIDMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) ListWebhooks(authenticatedUser api.RequestInfo) ([]api.Webhook, error) {
+	t.ArgsIn[ListWebhooksMethod][0] = authenticatedUser
+	var webhooks []api.Webhook
+	if t.ArgsOut[ListWebhooksMethod][0] != nil {
+		webhooks = t.ArgsOut[ListWebhooksMethod][0].([]api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[ListWebhooksMethod][1] != nil {
+		err = t.ArgsOut[ListWebhooksMethod][1].(error)
+	}
+	return webhooks, err
+}
+
+func (t TestAPI) UpdateWebhook(authenticatedUser api.RequestInfo, id string, webhookUrl string, eventTypes []string, secret string) (*api.Webhook, error) {
+	t.ArgsIn[UpdateWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[UpdateWebhookMethod][1] = id
+	t.ArgsIn[UpdateWebhookMethod][2] = webhookUrl
+	t.ArgsIn[UpdateWebhookMethod][3] = eventTypes
+	t.ArgsIn[UpdateWebhookMethod][4] = secret
+	var webhook *api.Webhook
+	if t.ArgsOut[UpdateWebhookMethod][0] != nil {
+		webhook = t.ArgsOut[UpdateWebhookMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) RemoveWebhook(authenticatedUser api.RequestInfo, id string) error {
+	t.ArgsIn[RemoveWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveWebhookMethod][1] = id
+	var err error
+	if t.ArgsOut[RemoveWebhookMethod][0] != nil {
+		err = t.ArgsOut[RemoveWebhookMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ListWebhookDeliveries(authenticatedUser api.RequestInfo, id string, status string) ([]api.WebhookDelivery, error) {
+	t.ArgsIn[ListWebhookDeliveriesMethod][0] = authenticatedUser
+	t.ArgsIn[ListWebhookDeliveriesMethod][1] = id
+	t.ArgsIn[ListWebhookDeliveriesMethod][2] = status
+	var deliveries []api.WebhookDelivery
+	if t.ArgsOut[ListWebhookDeliveriesMethod][0] != nil {
+		deliveries = t.ArgsOut[ListWebhookDeliveriesMethod][0].([]api.WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[ListWebhookDeliveriesMethod][1] != nil {
+		err = t.ArgsOut[ListWebhookDeliveriesMethod][1].(error)
+	}
+	return deliveries, err
+}
+
+// CHANGE API
+
+func (t TestAPI) ListChanges(authenticatedUser api.RequestInfo, since int64, limit int) (*api.ChangeFeed, error) {
+	t.ArgsIn[ListChangesMethod][0] = authenticatedUser
+	t.ArgsIn[ListChangesMethod][1] = since
+	t.ArgsIn[ListChangesMethod][2] = limit
+	var feed *api.ChangeFeed
+	if t.ArgsOut[ListChangesMethod][0] != nil {
+		feed = t.ArgsOut[ListChangesMethod][0].(*api.ChangeFeed)
+	}
+	var err error
+	if t.ArgsOut[ListChangesMethod][1] != nil {
+		err = t.ArgsOut[ListChangesMethod][1].(error)
+	}
+	return feed, err
+}
+
+// API KEY API
+
+func (t TestAPI) AddApiKey(authenticatedUser api.RequestInfo, externalID string, name string, expireAt *time.Time) (*api.ApiKeySecret, error) {
+	t.ArgsIn[AddApiKeyMethod][0] = authenticatedUser
+	t.ArgsIn[AddApiKeyMethod][1] = externalID
+	t.ArgsIn[AddApiKeyMethod][2] = name
+	t.ArgsIn[AddApiKeyMethod][3] = expireAt
+	var key *api.ApiKeySecret
+	if t.ArgsOut[AddApiKeyMethod][0] != nil {
+		key = t.ArgsOut[AddApiKeyMethod][0].(*api.ApiKeySecret)
+	}
+	var err error
+	if t.ArgsOut[AddApiKeyMethod][1] != nil {
+		err = t.ArgsOut[AddApiKeyMethod][1].(error)
+	}
+	return key, err
+}
+
+func (t TestAPI) ListApiKeys(authenticatedUser api.RequestInfo, externalID string) ([]api.ApiKey, error) {
+	t.ArgsIn[ListApiKeysMethod][0] = authenticatedUser
+	t.ArgsIn[ListApiKeysMethod][1] = externalID
+	var keys []api.ApiKey
+	if t.ArgsOut[ListApiKeysMethod][0] != nil {
+		keys = t.ArgsOut[ListApiKeysMethod][0].([]api.ApiKey)
+	}
+	var err error
+	if t.ArgsOut[ListApiKeysMethod][1] != nil {
+		err = t.ArgsOut[ListApiKeysMethod][1].(error)
+	}
+	return keys, err
+}
+
+func (t TestAPI) RotateApiKey(authenticatedUser api.RequestInfo, externalID string, id string, expireAt *time.Time) (*api.ApiKeySecret, error) {
+	t.ArgsIn[RotateApiKeyMethod][0] = authenticatedUser
+	t.ArgsIn[RotateApiKeyMethod][1] = externalID
+	t.ArgsIn[RotateApiKeyMethod][2] = id
+	t.ArgsIn[RotateApiKeyMethod][3] = expireAt
+	var key *api.ApiKeySecret
+	if t.ArgsOut[RotateApiKeyMethod][0] != nil {
+		key = t.ArgsOut[RotateApiKeyMethod][0].(*api.ApiKeySecret)
+	}
+	var err error
+	if t.ArgsOut[RotateApiKeyMethod][1] != nil {
+		err = t.ArgsOut[RotateApiKeyMethod][1].(error)
+	}
+	return key, err
+}
+
+func (t TestAPI) RevokeApiKey(authenticatedUser api.RequestInfo, externalID string, id string) error {
+	t.ArgsIn[RevokeApiKeyMethod][0] = authenticatedUser
+	t.ArgsIn[RevokeApiKeyMethod][1] = externalID
+	t.ArgsIn[RevokeApiKeyMethod][2] = id
+	var err error
+	if t.ArgsOut[RevokeApiKeyMethod][0] != nil {
+		err = t.ArgsOut[RevokeApiKeyMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) AuthenticateApiKey(key string) (*api.User, error) {
+	return nil, &api.Error{
+		Code:    api.INVALID_API_KEY,
+		Message: "Invalid API key",
+	}
+}
+
+// ADMIN API
+
+func (t TestAPI) AddAdmin(authenticatedUser api.RequestInfo, externalID string, password string) (*api.User, error) {
+	t.ArgsIn[AddAdminMethod][0] = authenticatedUser
+	t.ArgsIn[AddAdminMethod][1] = externalID
+	t.ArgsIn[AddAdminMethod][2] = password
+	var user *api.User
+	if t.ArgsOut[AddAdminMethod][0] != nil {
+		user = t.ArgsOut[AddAdminMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[AddAdminMethod][1] != nil {
+		err = t.ArgsOut[AddAdminMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ListAdmins(authenticatedUser api.RequestInfo) ([]string, error) {
+	t.ArgsIn[ListAdminsMethod][0] = authenticatedUser
+	var admins []string
+	if t.ArgsOut[ListAdminsMethod][0] != nil {
+		admins = t.ArgsOut[ListAdminsMethod][0].([]string)
+	}
+	var err error
+	if t.ArgsOut[ListAdminsMethod][1] != nil {
+		err = t.ArgsOut[ListAdminsMethod][1].(error)
+	}
+	return admins, err
+}
+
+func (t TestAPI) RemoveAdmin(authenticatedUser api.RequestInfo, externalID string) error {
+	t.ArgsIn[RemoveAdminMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveAdminMethod][1] = externalID
+	var err error
+	if t.ArgsOut[RemoveAdminMethod][0] != nil {
+		err = t.ArgsOut[RemoveAdminMethod][0].(error)
+	}
+	return err
+}
+
+// Admin credentials of tests are any user with password admin, other credentials aren't of admins
+func (t TestAPI) AuthenticateAdmin(username string, password string) (*api.User, error) {
+	if password != "admin" {
+		return nil, &api.Error{
+			Code:    api.ADMIN_BY_EXTERNAL_ID_NOT_FOUND,
+			Message: "Invalid admin credentials",
+		}
+	}
+	return &api.User{ExternalID: username}, nil
+}
+
+// PASSWORD API
+
+func (t TestAPI) SetUserPassword(authenticatedUser api.RequestInfo, externalID string, password string) (*api.User, error) {
+	t.ArgsIn[SetUserPasswordMethod][0] = authenticatedUser
+	t.ArgsIn[SetUserPasswordMethod][1] = externalID
+	t.ArgsIn[SetUserPasswordMethod][2] = password
+	var user *api.User
+	if t.ArgsOut[SetUserPasswordMethod][0] != nil {
+		user = t.ArgsOut[SetUserPasswordMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[SetUserPasswordMethod][1] != nil {
+		err = t.ArgsOut[SetUserPasswordMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ResetUserPassword(authenticatedUser api.RequestInfo, externalID string) (*api.TemporaryPassword, error) {
+	t.ArgsIn[ResetUserPasswordMethod][0] = authenticatedUser
+	t.ArgsIn[ResetUserPasswordMethod][1] = externalID
+	var password *api.TemporaryPassword
+	if t.ArgsOut[ResetUserPasswordMethod][0] != nil {
+		password = t.ArgsOut[ResetUserPasswordMethod][0].(*api.TemporaryPassword)
+	}
+	var err error
+	if t.ArgsOut[ResetUserPasswordMethod][1] != nil {
+		err = t.ArgsOut[ResetUserPasswordMethod][1].(error)
+	}
+	return password, err
+}
+
+// Local passwords aren't used to authenticate requests in tests
+func (t TestAPI) AuthenticateUserPassword(username string, password string) (*api.User, error) {
+	return nil, &api.Error{
+		Code:    api.INVALID_USER_CREDENTIALS,
+		Message: "Invalid user credentials",
+	}
+}
+
+func (t TestAPI) Login(username string, password string) (*api.SessionToken, error) {
+	t.ArgsIn[LoginMethod][0] = username
+	t.ArgsIn[LoginMethod][1] = password
+	var session *api.SessionToken
+	if t.ArgsOut[LoginMethod][0] != nil {
+		session = t.ArgsOut[LoginMethod][0].(*api.SessionToken)
+	}
+	var err error
+	if t.ArgsOut[LoginMethod][1] != nil {
+		err = t.ArgsOut[LoginMethod][1].(error)
+	}
+	return session, err
+}
+
+// Session tokens aren't used to authenticate requests in tests
+func (t TestAPI) AuthenticateSession(token string) (*api.User, error) {
+	return nil, &api.Error{
+		Code:    api.INVALID_SESSION_TOKEN,
+		Message: "Invalid session token",
+	}
+}
+
+func (t TestAPI) Logout(token string) error {
+	t.ArgsIn[LogoutMethod][0] = token
+	var err error
+	if t.ArgsOut[LogoutMethod][0] != nil {
+		err = t.ArgsOut[LogoutMethod][0].(error)
+	}
+	return err
+}
+
+// GROUP MAPPING API
+
+func (t TestAPI) AddGroupMapping(authenticatedUser api.RequestInfo, issuer string, claim string, value string, org string,
+	groupName string) (*api.GroupMapping, error) {
+	t.ArgsIn[AddGroupMappingMethod][0] = authenticatedUser
+	t.ArgsIn[AddGroupMappingMethod][1] = issuer
+	t.ArgsIn[AddGroupMappingMethod][2] = claim
+	t.ArgsIn[AddGroupMappingMethod][3] = value
+	t.ArgsIn[AddGroupMappingMethod][4] = org
+	t.ArgsIn[AddGroupMappingMethod][5] = groupName
+	var mapping *api.GroupMapping
+	if t.ArgsOut[AddGroupMappingMethod][0] != nil {
+		mapping = t.ArgsOut[AddGroupMappingMethod][0].(*api.GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[AddGroupMappingMethod][1] != nil {
+		err = t.ArgsOut[AddGroupMappingMethod][1].(error)
+	}
+	return mapping, err
+}
+
+func (t TestAPI) GetGroupMappingByID(authenticatedUser api.RequestInfo, id string) (*api.GroupMapping, error) {
+	t.ArgsIn[GetGroupMappingByIDMethod][0] = authenticatedUser
+	t.ArgsIn[GetGroupMappingByIDMethod][1] = id
+	var mapping *api.GroupMapping
+	if t.ArgsOut[GetGroupMappingByIDMethod][0] != nil {
+		mapping = t.ArgsOut[GetGroupMappingByIDMethod][0].(*api.GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[GetGroupMappingByIDMethod][1] != nil {
+		err = t.ArgsOut[GetGroupMappingByIDMethod][1].(error)
+	}
+	return mapping, err
+}
+
+func (t TestAPI) ListGroupMappings(authenticatedUser api.RequestInfo, org string) ([]api.GroupMapping, error) {
+	t.ArgsIn[ListGroupMappingsMethod][0] = authenticatedUser
+	t.ArgsIn[ListGroupMappingsMethod][1] = org
+	var mappings []api.GroupMapping
+	if t.ArgsOut[ListGroupMappingsMethod][0] != nil {
+		mappings = t.ArgsOut[ListGroupMappingsMethod][0].([]api.GroupMapping)
+	}
+	var err error
+	if t.ArgsOut[ListGroupMappingsMethod][1] != nil {
+		err = t.ArgsOut[ListGroupMappingsMethod][1].(error)
+	}
+	return mappings, err
+}
+
+func (t TestAPI) RemoveGroupMapping(authenticatedUser api.RequestInfo, id string) error {
+	t.ArgsIn[RemoveGroupMappingMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveGroupMappingMethod][1] = id
+	var err error
+	if t.ArgsOut[RemoveGroupMappingMethod][0] != nil {
+		err = t.ArgsOut[RemoveGroupMappingMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ApplyGroupMappings(requestID string, externalID string, issuer string, claims map[string][]string) error {
+	t.ArgsIn[ApplyGroupMappingsMethod][0] = requestID
+	t.ArgsIn[ApplyGroupMappingsMethod][1] = externalID
+	t.ArgsIn[ApplyGroupMappingsMethod][2] = issuer
+	t.ArgsIn[ApplyGroupMappingsMethod][3] = claims
+	var err error
+	if t.ArgsOut[ApplyGroupMappingsMethod][0] != nil {
+		err = t.ArgsOut[ApplyGroupMappingsMethod][0].(error)
+	}
+	return err
+}
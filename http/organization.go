@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name, omitempty"`
+}
+
+// RESPONSES
+
+type ListOrganizationsResponse struct {
+	Organizations []string `json:"organizations, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddOrganization(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call organization API to create an organization
+	response, err := h.worker.OrganizationApi.AddOrganization(requestInfo, request.Name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write organization to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetOrganizationByName(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve organization name from path
+	name := ps.ByName(ORG_NAME)
+
+	// Call organization API to retrieve organization
+	response, err := h.worker.OrganizationApi.GetOrganizationByName(requestInfo, name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write organization to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListOrganizations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Call organization API to retrieve organizations
+	result, err := h.worker.OrganizationApi.ListOrganizations(requestInfo)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListOrganizationsResponse{
+		Organizations: result,
+	}
+
+	// Return organizations
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve organization name from path
+	name := ps.ByName(ORG_NAME)
+
+	// Call organization API to delete organization
+	err := h.worker.OrganizationApi.RemoveOrganization(requestInfo, name)
+
+	// Check if there were errors
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
@@ -0,0 +1,508 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *CreateOrganizationRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Organization
+		expectedError      api.Error
+		// Manager Results
+		addOrganizationResult *api.Organization
+		// Manager Errors
+		addOrganizationErr error
+	}{
+		"OkCase": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  api.CreateUrn("org1", api.RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			addOrganizationResult: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  api.CreateUrn("org1", api.RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseOrganizationAlreadyExist": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_ALREADY_EXIST,
+				Message: "Organization already exist",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_ALREADY_EXIST,
+				Message: "Organization already exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateOrganizationRequest{
+				Name: "*%~#@|",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddOrganizationMethod][1] = nil
+		testApi.ArgsOut[AddOrganizationMethod][0] = test.addOrganizationResult
+		testApi.ArgsOut[AddOrganizationMethod][1] = test.addOrganizationErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+ORGANIZATION_ROOT_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameter
+		if test.request != nil && testApi.ArgsIn[AddOrganizationMethod][1] != test.request.Name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.request.Name, testApi.ArgsIn[AddOrganizationMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			orgResponse := &api.Organization{}
+			err = json.NewDecoder(res.Body).Decode(&orgResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(orgResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetOrganizationByName(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Organization
+		expectedError      api.Error
+		// Manager Results
+		getOrganizationByNameResult *api.Organization
+		// Manager Errors
+		getOrganizationByNameErr error
+	}{
+		"OkCase": {
+			name:               "org1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  api.CreateUrn("org1", api.RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getOrganizationByNameResult: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  api.CreateUrn("org1", api.RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseOrganizationNotFound": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization not found",
+			},
+			getOrganizationByNameErr: &api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization not found",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getOrganizationByNameErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusInternalServerError,
+			getOrganizationByNameErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetOrganizationByNameMethod][0] = test.getOrganizationByNameResult
+		testApi.ArgsOut[GetOrganizationByNameMethod][1] = test.getOrganizationByNameErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v", test.name)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameter
+		if testApi.ArgsIn[GetOrganizationByNameMethod][1] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[GetOrganizationByNameMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			orgResponse := &api.Organization{}
+			err = json.NewDecoder(res.Body).Decode(&orgResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(orgResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListOrganizations(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListOrganizationsResponse
+		expectedError      api.Error
+		// Manager Results
+		listOrganizationsResult []string
+		// Manager Errors
+		listOrganizationsErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListOrganizationsResponse{
+				Organizations: []string{"org1", "org2"},
+			},
+			listOrganizationsResult: []string{"org1", "org2"},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listOrganizationsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listOrganizationsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListOrganizationsMethod][0] = test.listOrganizationsResult
+		testApi.ArgsOut[ListOrganizationsMethod][1] = test.listOrganizationsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ORGANIZATION_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listOrganizationsResponse := ListOrganizationsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listOrganizationsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listOrganizationsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeOrganizationErr error
+	}{
+		"OkCase": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseOrganizationNotFound": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization not found",
+			},
+			removeOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			name:               "InvalidID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			removeOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			name:               "UnauthorizedID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			name:               "ExceptionID",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveOrganizationMethod][0] = test.removeOrganizationErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v", test.name)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameter
+		if testApi.ArgsIn[RemoveOrganizationMethod][1] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveOrganizationMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
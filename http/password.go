@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type SetUserPasswordRequest struct {
+	Password string `json:"password, omitempty"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username, omitempty"`
+	Password string `json:"password, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleSetUserPassword(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Decode request
+	request := SetUserPasswordRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call password API to store password
+	response, err := h.worker.PasswordApi.SetUserPassword(requestInfo, userID, request.Password)
+	if err != nil {
+		h.respondPasswordError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleResetUserPassword(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	userID := ps.ByName(USER_ID)
+
+	// Call password API to reset password
+	response, err := h.worker.PasswordApi.ResetUserPassword(requestInfo, userID)
+	if err != nil {
+		h.respondPasswordError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write temporary password to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleLogin(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Decode request
+	request := LoginRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call password API to create session
+	response, err := h.worker.PasswordApi.Login(request.Username, request.Password)
+	if err != nil {
+		h.respondPasswordError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write session token to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleLogout(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Retrieve session token from bearer Authorization header
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	// Call password API to remove session
+	if err := h.worker.PasswordApi.Logout(token); err != nil {
+		h.respondPasswordError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+// Private Helper Methods
+
+// Write error of a password operation
+func (h *WorkerHandler) respondPasswordError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.INVALID_USER_CREDENTIALS, api.USER_LOCKED_OUT, api.INVALID_SESSION_TOKEN:
+		h.RespondUnauthorized(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,561 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleSetUserPassword(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID  string
+		request *SetUserPasswordRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		setUserPasswordResult *api.User
+		// Manager Errors
+		setUserPasswordErr error
+	}{
+		"OkCase": {
+			userID: "user",
+			request: &SetUserPasswordRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        "urn",
+			},
+			setUserPasswordResult: &api.User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				Urn:        "urn",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			userID:             "user",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID: "user",
+			request: &SetUserPasswordRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			setUserPasswordErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			userID: "user",
+			request: &SetUserPasswordRequest{
+				Password: "short",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			setUserPasswordErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			userID: "user",
+			request: &SetUserPasswordRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			setUserPasswordErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID: "user",
+			request: &SetUserPasswordRequest{
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			setUserPasswordErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddAuditEventMethod][0] = nil
+		testApi.ArgsOut[SetUserPasswordMethod][0] = test.setUserPasswordResult
+		testApi.ArgsOut[SetUserPasswordMethod][1] = test.setUserPasswordErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPut, server.URL+USER_ROOT_URL+"/"+test.userID+"/password", body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[SetUserPasswordMethod][1] != test.userID || testApi.ArgsIn[SetUserPasswordMethod][2] != test.request.Password {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[SetUserPasswordMethod])
+				continue
+			}
+		}
+
+		// Check password isn't audited
+		if event, ok := testApi.ArgsIn[AddAuditEventMethod][0].(api.AuditEvent); ok && test.request != nil &&
+			strings.Contains(string(event.Request), test.request.Password) {
+			t.Errorf("Test case %v. Password stored in audit event %v", n, string(event.Request))
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := &api.User{}
+			err = json.NewDecoder(res.Body).Decode(userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleResetUserPassword(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		userID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.TemporaryPassword
+		expectedError      api.Error
+		// Manager Results
+		resetUserPasswordResult *api.TemporaryPassword
+		// Manager Errors
+		resetUserPasswordErr error
+	}{
+		"OkCase": {
+			userID:             "user",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.TemporaryPassword{
+				User:     "user",
+				Password: "temporary",
+			},
+			resetUserPasswordResult: &api.TemporaryPassword{
+				User:     "user",
+				Password: "temporary",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			userID:             "user",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			resetUserPasswordErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseServiceAccount": {
+			userID:             "service",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId service, user is a service account",
+			},
+			resetUserPasswordErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId service, user is a service account",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			userID:             "user",
+			expectedStatusCode: http.StatusInternalServerError,
+			resetUserPasswordErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ResetUserPasswordMethod][0] = test.resetUserPasswordResult
+		testApi.ArgsOut[ResetUserPasswordMethod][1] = test.resetUserPasswordErr
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/"+test.userID+"/password/reset", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ResetUserPasswordMethod][1] != test.userID {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[ResetUserPasswordMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			passwordResponse := &api.TemporaryPassword{}
+			err = json.NewDecoder(res.Body).Decode(passwordResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(passwordResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleLogin(t *testing.T) {
+	now := time.Unix(0, time.Now().UTC().UnixNano()).UTC()
+	testcases := map[string]struct {
+		// API method args
+		request *LoginRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.SessionToken
+		expectedError      api.Error
+		// Manager Results
+		loginResult *api.SessionToken
+		// Manager Errors
+		loginErr error
+	}{
+		"OkCase": {
+			request: &LoginRequest{
+				Username: "user",
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.SessionToken{
+				Session: api.Session{
+					ID:       "SESSION-ID",
+					ExpireAt: now.Add(time.Hour),
+					CreateAt: now,
+				},
+				Token: api.SESSION_TOKEN_PREFIX + "token",
+			},
+			loginResult: &api.SessionToken{
+				Session: api.Session{
+					ID:       "SESSION-ID",
+					UserID:   "USER-ID",
+					ExpireAt: now.Add(time.Hour),
+					CreateAt: now,
+				},
+				Token: api.SESSION_TOKEN_PREFIX + "token",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidCredentials": {
+			request: &LoginRequest{
+				Username: "user",
+				Password: "invalid",
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedError: api.Error{
+				Code:    api.INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+			loginErr: &api.Error{
+				Code:    api.INVALID_USER_CREDENTIALS,
+				Message: "Invalid user credentials",
+			},
+		},
+		"ErrorCaseLockedOut": {
+			request: &LoginRequest{
+				Username: "user",
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedError: api.Error{
+				Code:    api.USER_LOCKED_OUT,
+				Message: "User user is locked out",
+			},
+			loginErr: &api.Error{
+				Code:    api.USER_LOCKED_OUT,
+				Message: "User user is locked out",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &LoginRequest{
+				Username: "user",
+				Password: "secretPassword",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			loginErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[LoginMethod][0] = test.loginResult
+		testApi.ArgsOut[LoginMethod][1] = test.loginErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+LOGIN_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[LoginMethod][0] != test.request.Username || testApi.ArgsIn[LoginMethod][1] != test.request.Password {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[LoginMethod])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			sessionResponse := &api.SessionToken{}
+			err = json.NewDecoder(res.Body).Decode(sessionResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(sessionResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleLogout(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		token string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		logoutErr error
+	}{
+		"OkCase": {
+			token:              api.SESSION_TOKEN_PREFIX + "token",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseInvalidSessionToken": {
+			token:              api.SESSION_TOKEN_PREFIX + "invalid",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedError: api.Error{
+				Code:    api.INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			},
+			logoutErr: &api.Error{
+				Code:    api.INVALID_SESSION_TOKEN,
+				Message: "Invalid session token",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			token:              api.SESSION_TOKEN_PREFIX + "token",
+			expectedStatusCode: http.StatusInternalServerError,
+			logoutErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[LogoutMethod][0] = test.logoutErr
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+LOGOUT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.Header.Set("Authorization", "Bearer "+test.token)
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[LogoutMethod][0] != test.token {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[LogoutMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -11,15 +11,19 @@ import (
 // REQUESTS
 
 type CreatePolicyRequest struct {
-	Name       string          `json:"name, omitempty"`
-	Path       string          `json:"path, omitempty"`
-	Statements []api.Statement `json:"statements, omitempty"`
+	Name        string          `json:"name, omitempty"`
+	Path        string          `json:"path, omitempty"`
+	DisplayName string          `json:"displayName, omitempty"`
+	Description string          `json:"description, omitempty"`
+	Statements  []api.Statement `json:"statements, omitempty"`
 }
 
 type UpdatePolicyRequest struct {
-	Name       string          `json:"name, omitempty"`
-	Path       string          `json:"path, omitempty"`
-	Statements []api.Statement `json:"statements, omitempty"`
+	Name        string          `json:"name, omitempty"`
+	Path        string          `json:"path, omitempty"`
+	DisplayName string          `json:"displayName, omitempty"`
+	Description string          `json:"description, omitempty"`
+	Statements  []api.Statement `json:"statements, omitempty"`
 }
 
 // RESPONSES
@@ -57,7 +61,8 @@ func (h *WorkerHandler) HandleAddPolicy(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Store this policy
-	response, err := h.worker.PolicyApi.AddPolicy(requestInfo, request.Name, request.Path, org, request.Statements)
+	response, err := h.worker.PolicyApi.AddPolicy(requestInfo, request.Name, request.Path, org, request.DisplayName,
+		request.Description, request.Statements)
 
 	// Error handling
 	if err != nil {
@@ -109,6 +114,7 @@ func (h *WorkerHandler) HandleGetPolicyByName(w http.ResponseWriter, r *http.Req
 	}
 
 	// Return policy
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -200,8 +206,18 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 	org := ps.ByName(ORG_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call policy API to update policy
-	response, err := h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements)
+	response, err := h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path,
+		request.DisplayName, request.Description, request.Statements, version)
 
 	// Check errors
 	if err != nil {
@@ -211,6 +227,8 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 		switch apiError.Code {
 		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.POLICY_ALREADY_EXIST:
 			h.RespondConflict(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
@@ -224,6 +242,7 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Write policy to response
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -233,8 +252,17 @@ func (h *WorkerHandler) HandleRemovePolicy(w http.ResponseWriter, r *http.Reques
 	orgId := ps.ByName(ORG_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call API to delete policy
-	err := h.worker.PolicyApi.RemovePolicy(requestInfo, orgId, policyName)
+	err = h.worker.PolicyApi.RemovePolicy(requestInfo, orgId, policyName, version)
 
 	if err != nil {
 		// Transform to API errors
@@ -243,6 +271,8 @@ func (h *WorkerHandler) HandleRemovePolicy(w http.ResponseWriter, r *http.Reques
 		switch apiError.Code {
 		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
@@ -256,6 +286,77 @@ func (h *WorkerHandler) HandleRemovePolicy(w http.ResponseWriter, r *http.Reques
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleListDeletedPolicies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve policy org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Retrieve query param if exists
+	pathPrefix := r.URL.Query().Get("PathPrefix")
+
+	// Call policy API to retrieve deleted policies
+	result, err := h.worker.PolicyApi.ListDeletedPolicies(requestInfo, org, pathPrefix)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	policies := []string{}
+	for _, policy := range result {
+		policies = append(policies, policy.Name)
+	}
+
+	// Create response
+	response := &ListPoliciesResponse{
+		Policies: policies,
+	}
+
+	// Return deleted policies
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRestorePolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and policy name from request path
+	orgId := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Call API to restore policy
+	response, err := h.worker.PolicyApi.RestorePolicy(requestInfo, orgId, policyName)
+
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.POLICY_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write restored policy to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
 func (h *WorkerHandler) HandleListAttachedGroups(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve org and policy name from request path
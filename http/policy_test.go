@@ -310,6 +310,7 @@ func TestWorkerHandler_HandleGetPolicy(t *testing.T) {
 				Org:      "org1",
 				Path:     "/path/",
 				CreateAt: now,
+				Version:  3,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -329,6 +330,7 @@ func TestWorkerHandler_HandleGetPolicy(t *testing.T) {
 				Org:      "org1",
 				Path:     "/path/",
 				CreateAt: now,
+				Version:  3,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -427,6 +429,11 @@ func TestWorkerHandler_HandleGetPolicy(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.Policy{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -755,6 +762,8 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 	now := time.Now()
 	testcases := map[string]struct {
 		// API method args
+		ifMatch string
+		version int64
 		org     string
 		request *UpdatePolicyRequest
 		// Expected result
@@ -790,6 +799,7 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 				Path:     "/path/",
 				Org:      "org1",
 				CreateAt: now,
+				Version:  4,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -809,6 +819,68 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 				Path:     "/path/",
 				Org:      "org1",
 				CreateAt: now,
+				Version:  4,
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+		},
+		"OkCaseIfMatch": {
+			ifMatch: "\"3\"",
+			version: 3,
+			org:     "org1",
+			request: &UpdatePolicyRequest{
+				Name: "policy1",
+				Path: "path1",
+				Statements: []api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Policy{
+				ID:       "test1",
+				Name:     "policy1",
+				Path:     "/path/",
+				Org:      "org1",
+				CreateAt: now,
+				Version:  4,
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			updatePolicyResult: &api.Policy{
+				ID:       "test1",
+				Name:     "policy1",
+				Path:     "/path/",
+				Org:      "org1",
+				CreateAt: now,
+				Version:  4,
 				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
 				Statements: &[]api.Statement{
 					{
@@ -947,6 +1019,59 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 				Code: api.UNKNOWN_API_ERROR,
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			org: "org1",
+			request: &UpdatePolicyRequest{
+				Name: "policy1",
+				Path: "path1",
+				Statements: []api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			org: "org1",
+			request: &UpdatePolicyRequest{
+				Name: "policy1",
+				Path: "path1",
+				Statements: []api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization org1 and name policy1 is in version 3, not in expected version 2",
+			},
+			updatePolicyErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization org1 and name policy1 is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -975,6 +1100,7 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -982,7 +1108,7 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 			continue
 		}
 
-		if test.request != nil {
+		if test.request != nil && test.expectedStatusCode != http.StatusPreconditionFailed {
 			// Check received parameters
 			if testApi.ArgsIn[UpdatePolicyMethod][1] != test.org {
 				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[UpdatePolicyMethod][1])
@@ -1005,6 +1131,10 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 					n, diff)
 				continue
 			}
+			if testApi.ArgsIn[UpdatePolicyMethod][6] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[UpdatePolicyMethod][6])
+				continue
+			}
 		}
 
 		// check status code
@@ -1015,6 +1145,11 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.Policy{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -1047,6 +1182,8 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
+		ifMatch    string
+		version    int64
 		org        string
 		policyName string
 		// Expected result
@@ -1060,6 +1197,19 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			policyName:         "p1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseIfMatch": {
+			ifMatch:            "\"3\"",
+			version:            3,
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"OkCaseIfMatchAny": {
+			ifMatch:            "*",
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusNoContent,
+		},
 		"ErrorCasePolicyNotFound": {
 			org:        "org1",
 			policyName: "p1",
@@ -1104,6 +1254,41 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 				Code: api.UNKNOWN_API_ERROR,
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			org:                "org1",
+			policyName:         "p1",
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseWeakIfMatch": {
+			org:                "org1",
+			policyName:         "p1",
+			ifMatch:            "W/\"3\"",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: W/\"3\"",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			org:                "org1",
+			policyName:         "p1",
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization org1 and name p1 is in version 3, not in expected version 2",
+			},
+			deletePolicyErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Policy with organization org1 and name p1 is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -1118,6 +1303,7 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -1125,14 +1311,20 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemovePolicyMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemovePolicyMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[RemovePolicyMethod][2] != test.policyName {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[RemovePolicyMethod][2])
-			continue
+		// Check received parameters when API is called
+		if test.expectedStatusCode != http.StatusPreconditionFailed {
+			if testApi.ArgsIn[RemovePolicyMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemovePolicyMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemovePolicyMethod][2] != test.policyName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[RemovePolicyMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[RemovePolicyMethod][3] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[RemovePolicyMethod][3])
+				continue
+			}
 		}
 
 		// check status code
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleSync(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := api.DesiredState{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve optional flags
+	dryRun := r.URL.Query().Get("DryRun") == "true"
+	keepUnmanaged := r.URL.Query().Get("KeepUnmanaged") == "true"
+
+	// Call sync API to compute (and apply) the plan
+	var response *api.SyncPlan
+	if dryRun {
+		discardAuditEvent(r)
+		response, err = h.worker.SyncApi.PlanSync(requestInfo, request, keepUnmanaged)
+	} else {
+		response, err = h.worker.SyncApi.ApplySync(requestInfo, request, keepUnmanaged)
+	}
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write plan to response
+	h.RespondOk(r, requestInfo, w, response)
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleSync(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request       *api.DesiredState
+		dryRun        bool
+		keepUnmanaged bool
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   api.SyncPlan
+		expectedError      api.Error
+		// Manager Results
+		syncResult *api.SyncPlan
+		// Manager Errors
+		syncErr error
+	}{
+		"OkCaseApply": {
+			request: &api.DesiredState{
+				Organizations: []api.OrganizationState{
+					{
+						Org: "org1",
+						Groups: []api.GroupState{
+							{
+								Name:    "group1",
+								Path:    "/path/",
+								Members: []string{"user1"},
+							},
+						},
+					},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: api.SyncPlan{
+				Changes: []api.SyncChange{
+					{
+						Operation: api.SYNC_OPERATION_CREATE,
+						Entity:    api.RESOURCE_GROUP,
+						Org:       "org1",
+						Name:      "group1",
+					},
+					{
+						Operation: api.SYNC_OPERATION_CREATE,
+						Entity:    api.SYNC_ENTITY_MEMBER,
+						Org:       "org1",
+						Name:      "group1",
+						Target:    "user1",
+					},
+				},
+				Applied: true,
+			},
+			syncResult: &api.SyncPlan{
+				Changes: []api.SyncChange{
+					{
+						Operation: api.SYNC_OPERATION_CREATE,
+						Entity:    api.RESOURCE_GROUP,
+						Org:       "org1",
+						Name:      "group1",
+					},
+					{
+						Operation: api.SYNC_OPERATION_CREATE,
+						Entity:    api.SYNC_ENTITY_MEMBER,
+						Org:       "org1",
+						Name:      "group1",
+						Target:    "user1",
+					},
+				},
+				Applied: true,
+			},
+		},
+		"OkCaseDryRunKeepUnmanaged": {
+			request: &api.DesiredState{
+				Organizations: []api.OrganizationState{
+					{
+						Org: "org1",
+					},
+				},
+			},
+			dryRun:             true,
+			keepUnmanaged:      true,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: api.SyncPlan{
+				Changes: []api.SyncChange{},
+				Applied: false,
+			},
+			syncResult: &api.SyncPlan{
+				Changes: []api.SyncChange{},
+				Applied: false,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request: &api.DesiredState{
+				Organizations: []api.OrganizationState{
+					{
+						Org: "*%~#@|",
+					},
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org *%~#@|",
+			},
+			syncErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org *%~#@|",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &api.DesiredState{
+				Organizations: []api.OrganizationState{
+					{
+						Org: "org1",
+					},
+				},
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			syncErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &api.DesiredState{
+				Organizations: []api.OrganizationState{
+					{
+						Org: "org1",
+					},
+				},
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			syncErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		method := ApplySyncMethod
+		if test.dryRun {
+			method = PlanSyncMethod
+		}
+		testApi.ArgsIn[method][2] = nil
+		testApi.ArgsOut[method][0] = test.syncResult
+		testApi.ArgsOut[method][1] = test.syncErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+SYNC_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		q := req.URL.Query()
+		if test.dryRun {
+			q.Add("DryRun", "true")
+		}
+		if test.keepUnmanaged {
+			q.Add("KeepUnmanaged", "true")
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if diff := pretty.Compare(testApi.ArgsIn[method][1], *test.request); diff != "" {
+				t.Errorf("Test %v failed. Received different desired states (received/wanted) %v", n, diff)
+				continue
+			}
+			if testApi.ArgsIn[method][2] != test.keepUnmanaged {
+				t.Errorf("Test case %v. Received different KeepUnmanaged (wanted:%v / received:%v)", n, test.keepUnmanaged, testApi.ArgsIn[method][2])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			syncResponse := api.SyncPlan{}
+			err = json.NewDecoder(res.Body).Decode(&syncResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(syncResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -11,12 +11,24 @@ import (
 // REQUESTS
 
 type CreateUserRequest struct {
-	ExternalID string `json:"externalId, omitempty"`
-	Path       string `json:"path, omitempty"`
+	ExternalID  string `json:"externalId, omitempty"`
+	Path        string `json:"path, omitempty"`
+	DisplayName string `json:"displayName, omitempty"`
+	Description string `json:"description, omitempty"`
 }
 
 type UpdateUserRequest struct {
-	Path string `json:"path, omitempty"`
+	Path        string `json:"path, omitempty"`
+	DisplayName string `json:"displayName, omitempty"`
+	Description string `json:"description, omitempty"`
+}
+
+type RenameUserRequest struct {
+	ExternalID string `json:"externalId, omitempty"`
+}
+
+type MergeUserRequest struct {
+	TargetExternalID string `json:"targetExternalId, omitempty"`
 }
 
 // RESPONSES
@@ -47,7 +59,7 @@ func (h *WorkerHandler) HandleAddUser(w http.ResponseWriter, r *http.Request, _
 	}
 
 	// Call user API to create an user
-	response, err := h.worker.UserApi.AddUser(requestInfo, request.ExternalID, request.Path)
+	response, err := h.worker.UserApi.AddUser(requestInfo, request.ExternalID, request.Path, request.DisplayName, request.Description)
 
 	// Error handling
 	if err != nil {
@@ -71,6 +83,46 @@ func (h *WorkerHandler) HandleAddUser(w http.ResponseWriter, r *http.Request, _
 	h.RespondCreated(r, requestInfo, w, response)
 }
 
+func (h *WorkerHandler) HandleAddServiceAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateUserRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call user API to create a service account
+	response, err := h.worker.UserApi.AddServiceAccount(requestInfo, request.ExternalID, request.Path, request.DisplayName, request.Description)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write service account to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
 func (h *WorkerHandler) HandleGetUserByExternalID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve user id from path
@@ -98,6 +150,7 @@ func (h *WorkerHandler) HandleGetUserByExternalID(w http.ResponseWriter, r *http
 	}
 
 	// Write user to response
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -147,8 +200,17 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 	// Retrieve user id from path
 	id := ps.ByName(USER_ID)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call user API to update user
-	response, err := h.worker.UserApi.UpdateUser(requestInfo, id, request.Path)
+	response, err := h.worker.UserApi.UpdateUser(requestInfo, id, request.Path, request.DisplayName, request.Description, version)
 
 	// Error handling
 	if err != nil {
@@ -158,6 +220,8 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 		switch apiError.Code {
 		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
@@ -169,6 +233,7 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Write user to response
+	setETag(w, response.Version)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -177,8 +242,17 @@ func (h *WorkerHandler) HandleRemoveUser(w http.ResponseWriter, r *http.Request,
 	// Retrieve user id from path
 	id := ps.ByName(USER_ID)
 
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call user API to delete user
-	err := h.worker.UserApi.RemoveUser(requestInfo, id)
+	err = h.worker.UserApi.RemoveUser(requestInfo, id, version)
 
 	if err != nil {
 		// Transform to API errors
@@ -187,6 +261,8 @@ func (h *WorkerHandler) HandleRemoveUser(w http.ResponseWriter, r *http.Request,
 		switch apiError.Code {
 		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
 			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
@@ -200,6 +276,65 @@ func (h *WorkerHandler) HandleRemoveUser(w http.ResponseWriter, r *http.Request,
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleListDeletedUsers(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve PathPrefix
+	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Call user API
+	result, err := h.worker.UserApi.ListDeletedUsers(requestInfo, pathPrefix)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &GetUserExternalIDsResponse{
+		ExternalIDs: result,
+	}
+
+	// Return deleted users
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRestoreUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to restore user
+	response, err := h.worker.UserApi.RestoreUser(requestInfo, id)
+
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write restored user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
 func (h *WorkerHandler) HandleListGroupsByUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve users using path
@@ -231,3 +366,154 @@ func (h *WorkerHandler) HandleListGroupsByUser(w http.ResponseWriter, r *http.Re
 	// Write user to response
 	h.RespondOk(r, requestInfo, w, response)
 }
+
+func (h *WorkerHandler) HandleRenameUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := RenameUserRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Retrieve expected version from If-Match header
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call user API to rename user
+	response, err := h.worker.UserApi.RenameUser(requestInfo, id, request.ExternalID, version)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.USER_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.VERSION_MISMATCH_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write user to response
+	setETag(w, response.Version)
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleMergeUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := MergeUserRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve source user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to merge users
+	response, err := h.worker.UserApi.MergeUsers(requestInfo, id, request.TargetExternalID)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write target user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleSuspendUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to suspend user
+	response, err := h.worker.UserApi.SuspendUser(requestInfo, id)
+	if err != nil {
+		h.respondUserStatusError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write suspended user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleReactivateUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to reactivate user
+	response, err := h.worker.UserApi.ReactivateUser(requestInfo, id)
+	if err != nil {
+		h.respondUserStatusError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write reactivated user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// Write error of a change of user status
+func (h *WorkerHandler) respondUserStatusError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.VERSION_MISMATCH_ERROR:
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -221,6 +221,7 @@ func TestWorkerHandler_HandleGetUserByExternalID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Version:    3,
 			},
 			getUserByExternalIdResult: &api.User{
 				ID:         "UserID",
@@ -228,6 +229,7 @@ func TestWorkerHandler_HandleGetUserByExternalID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Version:    3,
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -310,6 +312,11 @@ func TestWorkerHandler_HandleGetUserByExternalID(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.User{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -458,6 +465,8 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 	now := time.Now()
 	testcases := map[string]struct {
 		// API method args
+		ifMatch string
+		version int64
 		request *UpdateUserRequest
 		// Expected result
 		expectedStatusCode int
@@ -479,6 +488,31 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Version:    4,
+			},
+			updateUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+				Version:    4,
+			},
+		},
+		"OkCaseIfMatch": {
+			ifMatch: "\"3\"",
+			version: 3,
+			request: &UpdateUserRequest{
+				Path: "NewPath",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+				Version:    4,
 			},
 			updateUserResult: &api.User{
 				ID:         "UserID",
@@ -486,6 +520,7 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Version:    4,
 			},
 		},
 		"ErrorCaseMalformedRequest": {
@@ -547,6 +582,33 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				Message: "Error",
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			request: &UpdateUserRequest{
+				Path: "NewPath",
+			},
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			request: &UpdateUserRequest{
+				Path: "NewPath",
+			},
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId userid is in version 3, not in expected version 2",
+			},
+			updateUserErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId userid is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -575,6 +637,7 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -582,7 +645,7 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 			continue
 		}
 
-		if test.request != nil {
+		if test.request != nil && test.expectedStatusCode != http.StatusPreconditionFailed {
 			// Check received parameters
 			if testApi.ArgsIn[UpdateUserMethod][1] != "userid" {
 				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, "userid", testApi.ArgsIn[UpdateUserMethod][1])
@@ -592,6 +655,10 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				t.Errorf("Test case %v. Received different Path (wanted:%v / received:%v)", n, test.request.Path, testApi.ArgsIn[UpdateUserMethod][2])
 				continue
 			}
+			if testApi.ArgsIn[UpdateUserMethod][3] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[UpdateUserMethod][3])
+				continue
+			}
 		}
 
 		// check status code
@@ -602,6 +669,11 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
 			response := api.User{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -636,6 +708,8 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
+		ifMatch    string
+		version    int64
 		externalID string
 		// Expected result
 		expectedStatusCode int
@@ -647,6 +721,17 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 			externalID:         "UserID",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseIfMatch": {
+			ifMatch:            "\"3\"",
+			version:            3,
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"OkCaseIfMatchAny": {
+			ifMatch:            "*",
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNoContent,
+		},
 		"ErrorCaseUserNotExist": {
 			externalID:         "UserID",
 			expectedStatusCode: http.StatusNotFound,
@@ -691,6 +776,38 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 				Message: "Error",
 			},
 		},
+		"ErrorCaseInvalidIfMatch": {
+			externalID:         "UserID",
+			ifMatch:            "abc",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: abc",
+			},
+		},
+		"ErrorCaseWeakIfMatch": {
+			externalID:         "UserID",
+			ifMatch:            "W/\"3\"",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: W/\"3\"",
+			},
+		},
+		"ErrorCaseVersionMismatchError": {
+			externalID:         "UserID",
+			ifMatch:            "\"2\"",
+			version:            2,
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId UserID is in version 3, not in expected version 2",
+			},
+			removeUserByIdErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId UserID is in version 3, not in expected version 2",
+			},
+		},
 	}
 
 	client := http.DefaultClient
@@ -705,6 +822,7 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -712,10 +830,16 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemoveUserMethod][1] != test.externalID {
-			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[RemoveUserMethod][1])
-			continue
+		// Check received parameters when API is called
+		if test.expectedStatusCode != http.StatusPreconditionFailed {
+			if testApi.ArgsIn[RemoveUserMethod][1] != test.externalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[RemoveUserMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemoveUserMethod][2] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[RemoveUserMethod][2])
+				continue
+			}
 		}
 
 		// check status code
@@ -896,3 +1020,698 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkerHandler_HandleRenameUser(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		ifMatch string
+		version int64
+		request *RenameUserRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		renameUserResult *api.User
+		// Manager Errors
+		renameUserErr error
+	}{
+		"OkCase": {
+			ifMatch: "\"3\"",
+			version: 3,
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "newid",
+				Path:       "/path/",
+				Urn:        "urn:iws:iam::user/path/newid",
+				CreateAt:   now,
+				Version:    4,
+			},
+			renameUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "newid",
+				Path:       "/path/",
+				Urn:        "urn:iws:iam::user/path/newid",
+				CreateAt:   now,
+				Version:    4,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidIfMatch": {
+			ifMatch: "invalid",
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Invalid If-Match header: invalid",
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseUserAlreadyExist": {
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "User already exist",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "User already exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &RenameUserRequest{
+				ExternalID: "*%~#@|",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			ifMatch: "\"2\"",
+			version: 2,
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId userid is in version 3, not in expected version 2",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "User with externalId userid is in version 3, not in expected version 2",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &RenameUserRequest{
+				ExternalID: "newid",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			renameUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RenameUserMethod][0] = test.renameUserResult
+		testApi.ArgsOut[RenameUserMethod][1] = test.renameUserErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL + USER_ROOT_URL + "/userid/rename")
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil && test.ifMatch != "invalid" {
+			// Check received parameters
+			if testApi.ArgsIn[RenameUserMethod][1] != "userid" {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, "userid", testApi.ArgsIn[RenameUserMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RenameUserMethod][2] != test.request.ExternalID {
+				t.Errorf("Test case %v. Received different new ExternalID (wanted:%v / received:%v)", n, test.request.ExternalID, testApi.ArgsIn[RenameUserMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[RenameUserMethod][3] != test.version {
+				t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.version, testApi.ArgsIn[RenameUserMethod][3])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != fmt.Sprintf("\"%v\"", test.expectedResponse.Version) {
+				t.Errorf("Test case %v. Received different ETag (wanted:%v / received:%v)", n, test.expectedResponse.Version, etag)
+				continue
+			}
+			response := api.User{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleMergeUser(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		request *MergeUserRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		mergeUsersResult *api.User
+		// Manager Errors
+		mergeUsersErr error
+	}{
+		"OkCase": {
+			request: &MergeUserRequest{
+				TargetExternalID: "target",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "target",
+				Path:       "/path/",
+				Urn:        "urn:iws:iam::user/path/target",
+				CreateAt:   now,
+				Version:    1,
+			},
+			mergeUsersResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "target",
+				Path:       "/path/",
+				Urn:        "urn:iws:iam::user/path/target",
+				CreateAt:   now,
+				Version:    1,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			request: &MergeUserRequest{
+				TargetExternalID: "target",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			mergeUsersErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &MergeUserRequest{
+				TargetExternalID: "userid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			mergeUsersErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &MergeUserRequest{
+				TargetExternalID: "target",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			mergeUsersErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &MergeUserRequest{
+				TargetExternalID: "target",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			mergeUsersErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[MergeUsersMethod][0] = test.mergeUsersResult
+		testApi.ArgsOut[MergeUsersMethod][1] = test.mergeUsersErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL + USER_ROOT_URL + "/userid/merge")
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[MergeUsersMethod][1] != "userid" {
+				t.Errorf("Test case %v. Received different source ExternalID (wanted:%v / received:%v)", n, "userid", testApi.ArgsIn[MergeUsersMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[MergeUsersMethod][2] != test.request.TargetExternalID {
+				t.Errorf("Test case %v. Received different target ExternalID (wanted:%v / received:%v)", n, test.request.TargetExternalID, testApi.ArgsIn[MergeUsersMethod][2])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := api.User{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleSuspendUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		suspendUserResult *api.User
+		// Manager Errors
+		suspendUserErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_SUSPENDED,
+				Urn:        "urn",
+			},
+			suspendUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_SUSPENDED,
+				Urn:        "urn",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseSuspendItself": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId UserID, users can't suspend themselves",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId UserID, users can't suspend themselves",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseVersionMismatch": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Version mismatch",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.VERSION_MISMATCH_ERROR,
+				Message: "Version mismatch",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusInternalServerError,
+			suspendUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SuspendUserMethod][0] = test.suspendUserResult
+		testApi.ArgsOut[SuspendUserMethod][1] = test.suspendUserErr
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/"+test.externalID+"/suspend", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[SuspendUserMethod][1] != test.externalID {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[SuspendUserMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := &api.User{}
+			err = json.NewDecoder(res.Body).Decode(userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleReactivateUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		reactivateUserResult *api.User
+		// Manager Errors
+		reactivateUserErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+			},
+			reactivateUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Status:     api.USER_STATUS_ACTIVE,
+				Urn:        "urn",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			reactivateUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			reactivateUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusInternalServerError,
+			reactivateUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ReactivateUserMethod][0] = test.reactivateUserResult
+		testApi.ArgsOut[ReactivateUserMethod][1] = test.reactivateUserErr
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/"+test.externalID+"/reactivate", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ReactivateUserMethod][1] != test.externalID {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[ReactivateUserMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := &api.User{}
+			err = json.NewDecoder(res.Body).Decode(userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
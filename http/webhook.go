@@ -0,0 +1,195 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateWebhookRequest struct {
+	Url        string   `json:"url, omitempty"`
+	EventTypes []string `json:"eventTypes, omitempty"`
+	Secret     string   `json:"secret, omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	Url        string   `json:"url, omitempty"`
+	EventTypes []string `json:"eventTypes, omitempty"`
+	Secret     string   `json:"secret, omitempty"`
+}
+
+// RESPONSES
+
+type ListWebhooksResponse struct {
+	Webhooks []api.Webhook `json:"webhooks, omitempty"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []api.WebhookDelivery `json:"deliveries, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddWebhook(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateWebhookRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call webhook API to create a webhook
+	response, err := h.worker.WebhookApi.AddWebhook(requestInfo, request.Url, request.EventTypes, request.Secret)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write webhook to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetWebhookByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve webhook from path
+	id := ps.ByName(WEBHOOK_ID)
+
+	// Call webhook API to retrieve webhook
+	response, err := h.worker.WebhookApi.GetWebhookByID(requestInfo, id)
+	if err != nil {
+		h.respondWebhookError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write webhook to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListWebhooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Call webhook API to retrieve webhooks
+	result, err := h.worker.WebhookApi.ListWebhooks(requestInfo)
+	if err != nil {
+		h.respondWebhookError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListWebhooksResponse{
+		Webhooks: result,
+	}
+
+	// Return webhooks
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleUpdateWebhook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve webhook from path
+	id := ps.ByName(WEBHOOK_ID)
+
+	// Decode request
+	request := UpdateWebhookRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call webhook API to update webhook
+	response, err := h.worker.WebhookApi.UpdateWebhook(requestInfo, id, request.Url, request.EventTypes, request.Secret)
+	if err != nil {
+		h.respondWebhookError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write webhook to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveWebhook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve webhook from path
+	id := ps.ByName(WEBHOOK_ID)
+
+	// Call webhook API to delete webhook
+	err := h.worker.WebhookApi.RemoveWebhook(requestInfo, id)
+	if err != nil {
+		h.respondWebhookError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleListWebhookDeliveries(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve webhook from path
+	id := ps.ByName(WEBHOOK_ID)
+
+	// Retrieve query param if exists
+	status := r.URL.Query().Get("Status")
+
+	// Call webhook API to retrieve deliveries
+	result, err := h.worker.WebhookApi.ListWebhookDeliveries(requestInfo, id, status)
+	if err != nil {
+		h.respondWebhookError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListWebhookDeliveriesResponse{
+		Deliveries: result,
+	}
+
+	// Return deliveries
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// Private Helper Methods
+
+// Write error of a webhook operation
+func (h *WorkerHandler) respondWebhookError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.WEBHOOK_BY_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,789 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddWebhook(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		request *CreateWebhookRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		addWebhookResult *api.Webhook
+		// Manager Errors
+		addWebhookErr error
+	}{
+		"OkCase": {
+			request: &CreateWebhookRequest{
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "webhook-secret",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+			addWebhookResult: &api.Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/hook",
+				EventTypes: []string{api.USER_ACTION_CREATE_USER},
+				Secret:     "secret",
+				Urn:        api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+				CreateAt:   now,
+				UpdateAt:   now,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateWebhookRequest{
+				Url: "invalid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addWebhookErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &CreateWebhookRequest{
+				Url: "https://example.com/hook",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addWebhookErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateWebhookRequest{
+				Url: "https://example.com/hook",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsIn[AddAuditEventMethod][0] = nil
+		testApi.ArgsOut[AddWebhookMethod][0] = test.addWebhookResult
+		testApi.ArgsOut[AddWebhookMethod][1] = test.addWebhookErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+WEBHOOK_ROOT_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			if testApi.ArgsIn[AddWebhookMethod][1] != test.request.Url || testApi.ArgsIn[AddWebhookMethod][3] != test.request.Secret {
+				t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[AddWebhookMethod])
+				continue
+			}
+		}
+
+		// Check secret isn't audited
+		if event, ok := testApi.ArgsIn[AddAuditEventMethod][0].(api.AuditEvent); ok && test.request != nil &&
+			len(test.request.Secret) > 0 && strings.Contains(string(event.Request), test.request.Secret) {
+			t.Errorf("Test case %v. Secret stored in audit event %v", n, string(event.Request))
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			webhookResponse := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(webhookResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(webhookResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetWebhookByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		getWebhookByIDResult *api.Webhook
+		// Manager Errors
+		getWebhookByIDErr error
+	}{
+		"OkCase": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Webhook{
+				ID:  "WEBHOOK-ID",
+				Url: "https://example.com/hook",
+				Urn: api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+			getWebhookByIDResult: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Urn:    api.CreateUrn("", api.RESOURCE_WEBHOOK, "/", "WEBHOOK-ID"),
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+			getWebhookByIDErr: &api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getWebhookByIDErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			getWebhookByIDErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetWebhookByIDMethod][0] = test.getWebhookByIDResult
+		testApi.ArgsOut[GetWebhookByIDMethod][1] = test.getWebhookByIDErr
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(server.URL+WEBHOOK_ROOT_URL+"/%v", test.id), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetWebhookByIDMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[GetWebhookByIDMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			webhookResponse := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(webhookResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result, secret must not be returned
+			if diff := pretty.Compare(webhookResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListWebhooks(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListWebhooksResponse
+		expectedError      api.Error
+		// Manager Results
+		listWebhooksResult []api.Webhook
+		// Manager Errors
+		listWebhooksErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListWebhooksResponse{
+				Webhooks: []api.Webhook{
+					{
+						ID:  "WEBHOOK-ID",
+						Url: "https://example.com/hook",
+					},
+				},
+			},
+			listWebhooksResult: []api.Webhook{
+				{
+					ID:  "WEBHOOK-ID",
+					Url: "https://example.com/hook",
+				},
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listWebhooksErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listWebhooksErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListWebhooksMethod][0] = test.listWebhooksResult
+		testApi.ArgsOut[ListWebhooksMethod][1] = test.listWebhooksErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+WEBHOOK_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listWebhooksResponse := ListWebhooksResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listWebhooksResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listWebhooksResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleUpdateWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id      string
+		request *UpdateWebhookRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		updateWebhookResult *api.Webhook
+		// Manager Errors
+		updateWebhookErr error
+	}{
+		"OkCase": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url:        "https://example.com/new",
+				EventTypes: []string{api.USER_ACTION_DELETE_USER},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{api.USER_ACTION_DELETE_USER},
+			},
+			updateWebhookResult: &api.Webhook{
+				ID:         "WEBHOOK-ID",
+				Url:        "https://example.com/new",
+				EventTypes: []string{api.USER_ACTION_DELETE_USER},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "https://example.com/new",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+			updateWebhookErr: &api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "invalid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			updateWebhookErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "https://example.com/new",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			updateWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[UpdateWebhookMethod][0] = test.updateWebhookResult
+		testApi.ArgsOut[UpdateWebhookMethod][1] = test.updateWebhookErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(server.URL+WEBHOOK_ROOT_URL+"/%v", test.id), body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if testApi.ArgsIn[UpdateWebhookMethod][1] != test.id {
+				t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[UpdateWebhookMethod][1])
+				continue
+			}
+			webhookResponse := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(webhookResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(webhookResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeWebhookErr error
+	}{
+		"OkCase": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+			removeWebhookErr: &api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveWebhookMethod][0] = test.removeWebhookErr
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(server.URL+WEBHOOK_ROOT_URL+"/%v", test.id), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveWebhookMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[RemoveWebhookMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListWebhookDeliveries(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id     string
+		status string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListWebhookDeliveriesResponse
+		expectedError      api.Error
+		// Manager Results
+		listWebhookDeliveriesResult []api.WebhookDelivery
+		// Manager Errors
+		listWebhookDeliveriesErr error
+	}{
+		"OkCase": {
+			id:                 "WEBHOOK-ID",
+			status:             api.WEBHOOK_DELIVERY_STATUS_DEAD,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListWebhookDeliveriesResponse{
+				Deliveries: []api.WebhookDelivery{
+					{
+						ID:        "DELIVERY-ID",
+						WebhookID: "WEBHOOK-ID",
+						Event: api.WebhookEvent{
+							Before: json.RawMessage("null"),
+							After:  json.RawMessage("null"),
+						},
+						Status:   api.WEBHOOK_DELIVERY_STATUS_DEAD,
+						Attempts: 5,
+					},
+				},
+			},
+			listWebhookDeliveriesResult: []api.WebhookDelivery{
+				{
+					ID:        "DELIVERY-ID",
+					WebhookID: "WEBHOOK-ID",
+					Status:    api.WEBHOOK_DELIVERY_STATUS_DEAD,
+					Attempts:  5,
+				},
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			id:                 "WEBHOOK-ID",
+			status:             "failed",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.WEBHOOK_BY_ID_NOT_FOUND,
+				Message: "Webhook not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListWebhookDeliveriesMethod][0] = test.listWebhookDeliveriesResult
+		testApi.ArgsOut[ListWebhookDeliveriesMethod][1] = test.listWebhookDeliveriesErr
+
+		req, err := http.NewRequest(http.MethodGet,
+			fmt.Sprintf(server.URL+WEBHOOK_ROOT_URL+"/%v/deliveries?Status=%v", test.id, test.status), nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListWebhookDeliveriesMethod][1] != test.id || testApi.ArgsIn[ListWebhookDeliveriesMethod][2] != test.status {
+			t.Errorf("Test case %v. Received different parameters %v", n, testApi.ArgsIn[ListWebhookDeliveriesMethod])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listWebhookDeliveriesResponse := ListWebhookDeliveriesResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listWebhookDeliveriesResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listWebhookDeliveriesResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}